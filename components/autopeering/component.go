@@ -51,6 +51,7 @@ type dependencies struct {
 	AutopeeringRunAsEntryNode bool           `name:"autopeeringRunAsEntryNode"`
 	PeeringManager            *p2p.Manager   `optional:"true"`
 	AutopeeringManager        *autopeering.Manager
+	BanList                   *p2p.BanList
 }
 
 func initConfigParams(c *dig.Container) error {
@@ -136,7 +137,7 @@ func configure() error {
 	// only enable peer selection when the peering plugin is enabled
 	initSelection := deps.PeeringManager != nil
 
-	deps.AutopeeringManager.Init(localPeerContainer, initSelection)
+	deps.AutopeeringManager.Init(localPeerContainer, initSelection, isAllowedNeighbor)
 
 	return nil
 }
@@ -153,6 +154,25 @@ func run() error {
 	return nil
 }
 
+// isAllowedNeighbor excludes banned peers from the autopeering selection.
+func isAllowedNeighbor(addrInfo *libp2p.AddrInfo) bool {
+	if deps.BanList.IsPeerBanned(addrInfo.ID) {
+		return false
+	}
+
+	for _, addr := range addrInfo.Addrs {
+		if deps.BanList.IsAddrBanned(addr) {
+			return false
+		}
+	}
+
+	if deps.PeeringManager != nil && deps.PeeringManager.Reputation().IsBanned(addrInfo.ID) {
+		return false
+	}
+
+	return true
+}
+
 // handles a peer gotten from the autopeering selection according to its existing relation.
 // if the peer is not yet part of the peering manager, the given noRelationFunc is called.
 func handleSelection(ev *selection.PeeringEvent, addrInfo *libp2p.AddrInfo, noRelationFunc func()) {
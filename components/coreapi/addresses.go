@@ -0,0 +1,146 @@
+package coreapi
+
+import (
+	"math/big"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hornet/v2/pkg/model/utxo"
+	"github.com/iotaledger/hornet/v2/pkg/restapi"
+	"github.com/iotaledger/inx-app/pkg/httpserver"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+const (
+	// addressBalanceMaxOutputs is the maximum amount of unspent outputs in the index of an address the balance is computed for.
+	// The balance is computed while the ledger is locked, so addresses with more outputs need to be queried via the outputs route.
+	addressBalanceMaxOutputs = 10_000
+)
+
+func addressOutputIDs(c echo.Context) (*addressOutputsResponse, error) {
+	address, err := restapi.ParseBech32AddressParam(c, deps.ProtocolManager.Current().Bech32HRP)
+	if err != nil {
+		return nil, err
+	}
+
+	var startOutputID *iotago.OutputID
+	var pageSize int
+	if len(c.QueryParam(restapi.QueryParameterCursor)) > 0 {
+		key, cursorPageSize, err := restapi.ParseCursorQueryParam(c, iotago.OutputIDLength, deps.RestAPILimitsMaxResults)
+		if err != nil {
+			return nil, err
+		}
+		startOutputID = &iotago.OutputID{}
+		copy(startOutputID[:], key)
+		pageSize = cursorPageSize
+	} else {
+		pageSize, err = restapi.ParsePageSizeQueryParam(c, deps.RestAPILimitsMaxResults)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// we need to lock the ledger here to have the correct ledger index for the outputs.
+	deps.UTXOManager.ReadLockLedger()
+	defer deps.UTXOManager.ReadUnlockLedger()
+
+	ledgerIndex, err := deps.UTXOManager.ReadLedgerIndexWithoutLocking()
+	if err != nil {
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading ledger index failed, error: %s", err)
+	}
+
+	var cursor string
+	outputIDs := make([]string, 0)
+	if err := deps.UTXOManager.ForEachUnspentOutputIDByIndexKey(utxo.OutputIndexKeyAddress(address), startOutputID, func(outputID iotago.OutputID) bool {
+		if len(outputIDs) >= pageSize {
+			// there are further outputs, the next page starts at this output
+			cursor = restapi.EncodeCursor(outputID[:], pageSize)
+
+			return false
+		}
+
+		outputIDs = append(outputIDs, outputID.ToHex())
+
+		return true
+	}, utxo.ReadLockLedger(false)); err != nil {
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading outputs failed, error: %s", err)
+	}
+
+	return &addressOutputsResponse{
+		LedgerIndex: ledgerIndex,
+		PageSize:    pageSize,
+		Cursor:      cursor,
+		OutputIDs:   outputIDs,
+	}, nil
+}
+
+func addressBalance(c echo.Context) (*addressBalanceResponse, error) {
+	address, err := restapi.ParseBech32AddressParam(c, deps.ProtocolManager.Current().Bech32HRP)
+	if err != nil {
+		return nil, err
+	}
+
+	// we need to lock the ledger here to have the correct ledger index for the balance.
+	deps.UTXOManager.ReadLockLedger()
+	defer deps.UTXOManager.ReadUnlockLedger()
+
+	ledgerIndex, err := deps.UTXOManager.ReadLedgerIndexWithoutLocking()
+	if err != nil {
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading ledger index failed, error: %s", err)
+	}
+
+	// the expiration unlock conditions are evaluated against the milestone of the ledger index
+	msTimestamp, err := deps.Storage.MilestoneTimestampUnixByIndex(ledgerIndex)
+	if err != nil {
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading milestone timestamp failed, error: %s", err)
+	}
+
+	var balance uint64
+	var outputCount int
+	var indexedOutputCount int
+	nativeTokens := make(map[iotago.NativeTokenID]*big.Int)
+
+	if err := deps.UTXOManager.ForEachUnspentOutputByIndexKey(utxo.OutputIndexKeyAddress(address), nil, func(output *utxo.Output) bool {
+		indexedOutputCount++
+		if indexedOutputCount > addressBalanceMaxOutputs {
+			return false
+		}
+
+		// the index also contains the outputs the address is only able to unlock in some way (e.g. as governor),
+		// but only the outputs that are owned by the address are part of its balance
+		if owner := utxo.OwnerAddress(output.Output(), msTimestamp); owner == nil || !owner.Equal(address) {
+			return true
+		}
+
+		outputCount++
+		balance += output.Deposit()
+
+		for _, nativeToken := range output.Output().NativeTokenList() {
+			if _, exists := nativeTokens[nativeToken.ID]; !exists {
+				nativeTokens[nativeToken.ID] = new(big.Int)
+			}
+			nativeTokens[nativeToken.ID].Add(nativeTokens[nativeToken.ID], nativeToken.Amount)
+		}
+
+		return true
+	}, utxo.ReadLockLedger(false)); err != nil {
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading outputs failed, error: %s", err)
+	}
+
+	if indexedOutputCount > addressBalanceMaxOutputs {
+		return nil, errors.WithMessagef(httpserver.ErrInvalidParameter, "the address has more than %d unspent outputs, please use the outputs route instead", addressBalanceMaxOutputs)
+	}
+
+	nativeTokenBalances := make(map[string]string, len(nativeTokens))
+	for nativeTokenID, amount := range nativeTokens {
+		nativeTokenBalances[nativeTokenID.ToHex()] = iotago.EncodeUint256(amount)
+	}
+
+	return &addressBalanceResponse{
+		LedgerIndex:  ledgerIndex,
+		Balance:      iotago.EncodeUint64(balance),
+		NativeTokens: nativeTokenBalances,
+		OutputCount:  outputCount,
+	}, nil
+}
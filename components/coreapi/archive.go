@@ -0,0 +1,113 @@
+package coreapi
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hornet/v2/pkg/model/storage"
+	"github.com/iotaledger/hornet/v2/pkg/model/utxo"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+// isPrunedMilestoneIndex returns whether the data of the given milestone was already pruned from the database.
+func isPrunedMilestoneIndex(msIndex iotago.MilestoneIndex) bool {
+	snapshotInfo := deps.Storage.SnapshotInfo()
+	if snapshotInfo == nil {
+		return false
+	}
+
+	return msIndex <= snapshotInfo.PruningIndex()
+}
+
+// archivedBlock returns the block from the archive if the node runs in archive mode.
+func archivedBlock(blockID iotago.BlockID) (*storage.Block, error) {
+	if deps.Archive == nil {
+		return nil, errors.WithMessagef(echo.ErrNotFound, "block not found: %s", blockID.ToHex())
+	}
+
+	block, err := deps.Archive.Block(blockID)
+	if err != nil {
+		if errors.Is(err, kvstore.ErrKeyNotFound) {
+			return nil, errors.WithMessagef(echo.ErrNotFound, "block not found: %s", blockID.ToHex())
+		}
+
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading archived block failed: %s, error: %s", blockID.ToHex(), err)
+	}
+
+	return block, nil
+}
+
+// archivedMilestoneByIndex returns the milestone from the archive if the node runs in archive mode.
+// Only milestones older than the pruning index are looked up in the archive.
+func archivedMilestoneByIndex(msIndex iotago.MilestoneIndex) (*storage.Milestone, error) {
+	if deps.Archive == nil || !isPrunedMilestoneIndex(msIndex) {
+		return nil, errors.WithMessagef(echo.ErrNotFound, "milestone index not found: %d", msIndex)
+	}
+
+	milestone, err := deps.Archive.MilestoneByIndex(msIndex)
+	if err != nil {
+		if errors.Is(err, kvstore.ErrKeyNotFound) {
+			return nil, errors.WithMessagef(echo.ErrNotFound, "milestone index not found: %d", msIndex)
+		}
+
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading archived milestone failed: %d, error: %s", msIndex, err)
+	}
+
+	return milestone, nil
+}
+
+// archivedMilestoneByID returns the milestone from the archive if the node runs in archive mode.
+func archivedMilestoneByID(milestoneID iotago.MilestoneID) (*storage.Milestone, error) {
+	if deps.Archive == nil {
+		return nil, errors.WithMessagef(echo.ErrNotFound, "milestone not found: %s", iotago.EncodeHex(milestoneID[:]))
+	}
+
+	milestone, err := deps.Archive.Milestone(milestoneID)
+	if err != nil {
+		if errors.Is(err, kvstore.ErrKeyNotFound) {
+			return nil, errors.WithMessagef(echo.ErrNotFound, "milestone not found: %s", iotago.EncodeHex(milestoneID[:]))
+		}
+
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading archived milestone failed: %s, error: %s", iotago.EncodeHex(milestoneID[:]), err)
+	}
+
+	return milestone, nil
+}
+
+// archivedMilestoneDiff returns the milestone diff from the archive if the node runs in archive mode.
+// Only milestones older than the pruning index are looked up in the archive.
+func archivedMilestoneDiff(msIndex iotago.MilestoneIndex) (*utxo.MilestoneDiff, error) {
+	if deps.Archive == nil || !isPrunedMilestoneIndex(msIndex) {
+		return nil, kvstore.ErrKeyNotFound
+	}
+
+	return deps.Archive.UTXOManager().MilestoneDiffWithoutLocking(msIndex)
+}
+
+// archivedOutput returns the output from the archive if the node runs in archive mode.
+func archivedOutput(outputID iotago.OutputID) (*utxo.Output, error) {
+	if deps.Archive == nil {
+		return nil, kvstore.ErrKeyNotFound
+	}
+
+	return deps.Archive.UTXOManager().ReadOutputByOutputIDWithoutLocking(outputID)
+}
+
+// archivedRawOutputBytes returns the raw output bytes from the archive if the node runs in archive mode.
+func archivedRawOutputBytes(outputID iotago.OutputID) ([]byte, error) {
+	if deps.Archive == nil {
+		return nil, kvstore.ErrKeyNotFound
+	}
+
+	return deps.Archive.UTXOManager().ReadRawOutputBytesByOutputIDWithoutLocking(outputID)
+}
+
+// archivedSpent returns the spent output from the archive if the node runs in archive mode.
+func archivedSpent(outputID iotago.OutputID) (*utxo.Spent, error) {
+	if deps.Archive == nil {
+		return nil, kvstore.ErrKeyNotFound
+	}
+
+	return deps.Archive.UTXOManager().ReadSpentForOutputIDWithoutLocking(outputID)
+}
@@ -104,7 +104,8 @@ func blockMetadataByBlockID(blockID iotago.BlockID) (*blockMetadataResponse, err
 func storageBlockByBlockID(blockID iotago.BlockID) (*storage.Block, error) {
 	cachedBlock := deps.Storage.CachedBlockOrNil(blockID) // block +1
 	if cachedBlock == nil {
-		return nil, errors.WithMessagef(echo.ErrNotFound, "block not found: %s", blockID.ToHex())
+		// the block might have been moved to the archive
+		return archivedBlock(blockID)
 	}
 	defer cachedBlock.Release(true) // block -1
 
@@ -148,7 +149,8 @@ func blockMetadataByID(c echo.Context) (*blockMetadataResponse, error) {
 	return blockMetadataByBlockID(blockID)
 }
 
-func sendBlock(c echo.Context) (*blockCreatedResponse, error) {
+// parseBlockFromRequest parses the block from the request body based on the given "Content-Type" header.
+func parseBlockFromRequest(c echo.Context) (*iotago.Block, error) {
 	mimeType, err := httpserver.GetRequestContentType(c, httpserver.MIMEApplicationVendorIOTASerializerV1, echo.MIMEApplicationJSON)
 	if err != nil {
 		return nil, err
@@ -182,6 +184,15 @@ func sendBlock(c echo.Context) (*blockCreatedResponse, error) {
 		return nil, echo.ErrUnsupportedMediaType
 	}
 
+	return iotaBlock, nil
+}
+
+func sendBlock(c echo.Context) (*blockCreatedResponse, error) {
+	iotaBlock, err := parseBlockFromRequest(c)
+	if err != nil {
+		return nil, err
+	}
+
 	mergedCtx, mergedCtxCancel := contextutils.MergeContexts(c.Request().Context(), Component.Daemon().ContextStopped())
 	defer mergedCtxCancel()
 
@@ -206,3 +217,42 @@ func sendBlock(c echo.Context) (*blockCreatedResponse, error) {
 		BlockID: blockID.ToHex(),
 	}, nil
 }
+
+func validateBlock(c echo.Context) (*blockValidationResponse, error) {
+	iotaBlock, err := parseBlockFromRequest(c)
+	if err != nil {
+		return nil, err
+	}
+
+	mergedCtx, mergedCtxCancel := contextutils.MergeContexts(c.Request().Context(), Component.Daemon().ContextStopped())
+	defer mergedCtxCancel()
+
+	failures, err := attacher.ValidateBlock(mergedCtx, iotaBlock)
+	if err != nil {
+		if errors.Is(err, tangle.ErrBlockAttacherAttachingNotPossible) {
+			return nil, errors.WithMessagef(echo.ErrServiceUnavailable, "failed to validate block: %s", err.Error())
+		}
+
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "failed to validate block: %s", err.Error())
+	}
+
+	validationFailures := make([]*blockValidationFailure, 0, len(failures))
+	for _, failure := range failures {
+		var conflictReason *storage.Conflict
+		if failure.Conflict != storage.ConflictNone {
+			conflict := failure.Conflict
+			conflictReason = &conflict
+		}
+
+		validationFailures = append(validationFailures, &blockValidationFailure{
+			Check:          string(failure.Check),
+			ConflictReason: conflictReason,
+			Error:          failure.Err.Error(),
+		})
+	}
+
+	return &blockValidationResponse{
+		Valid:    len(validationFailures) == 0,
+		Failures: validationFailures,
+	}, nil
+}
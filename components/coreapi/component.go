@@ -11,6 +11,8 @@ import (
 	"github.com/iotaledger/hive.go/app"
 	"github.com/iotaledger/hornet/v2/components/protocfg"
 	"github.com/iotaledger/hornet/v2/components/restapi"
+	"github.com/iotaledger/hornet/v2/pkg/archive"
+	"github.com/iotaledger/hornet/v2/pkg/backup"
 	"github.com/iotaledger/hornet/v2/pkg/components"
 	"github.com/iotaledger/hornet/v2/pkg/metrics"
 	"github.com/iotaledger/hornet/v2/pkg/model/storage"
@@ -54,6 +56,14 @@ const (
 	// MIMEVendorIOTASerializer => bytes.
 	RouteBlocks = "/blocks"
 
+	// RouteBlocksValidate is the route for validating blocks without attaching them.
+	// POST validates a single block against the current ledger state and returns all failed checks.
+	// The block is neither stored nor gossiped.
+	// The block is parsed based on the given type in the request "Content-Type" header.
+	// MIMEApplicationJSON => json.
+	// MIMEVendorIOTASerializer => bytes.
+	RouteBlocksValidate = "/blocks/validate"
+
 	// RouteTransactionsIncludedBlock is the route for getting the block that was included in the ledger for a given transaction ID.
 	// GET returns the block based on the given type in the request "Accept" header.
 	// MIMEApplicationJSON => json.
@@ -64,6 +74,11 @@ const (
 	// GET returns block metadata (including info about "promotion/reattachment needed").
 	RouteTransactionsIncludedBlockMetadata = "/transactions/:" + restapipkg.ParameterTransactionID + "/included-block/metadata"
 
+	// RouteMilestones is the route for getting a range of milestones.
+	// GET returns the milestones ordered by their index.
+	// Query parameters: "from", "to", "pageSize" and "cursor".
+	RouteMilestones = "/milestones"
+
 	// RouteMilestoneByID is the route for getting a milestone by its ID.
 	// GET returns the milestone.
 	// MIMEApplicationJSON => json.
@@ -84,16 +99,40 @@ const (
 	// GET returns the output IDs of all UTXO changes.
 	RouteMilestoneByIndexUTXOChanges = "/milestones/by-index/:" + restapipkg.ParameterMilestoneIndex + "/utxo-changes"
 
+	// RouteMilestoneByIndexReferencedBlocks is the route for getting all blocks referenced by a milestone by its milestoneIndex.
+	// GET returns the block IDs of the milestone cone in white flag order.
+	// Query parameters: "pageSize" and "cursor".
+	RouteMilestoneByIndexReferencedBlocks = "/milestones/by-index/:" + restapipkg.ParameterMilestoneIndex + "/referenced-blocks"
+
 	// RouteOutput is the route for getting an output by its outputID (transactionHash + outputIndex).
 	// GET returns the output based on the given type in the request "Accept" header.
 	// MIMEApplicationJSON => json.
 	// MIMEVendorIOTASerializer => bytes.
+	// Query parameter: "atMilestone" returns the state of the output at the given milestone index (json only).
 	RouteOutput = "/outputs/:" + restapipkg.ParameterOutputID
 
 	// RouteOutputMetadata is the route for getting output metadata by its outputID (transactionHash + outputIndex) without getting the data again.
 	// GET returns the output metadata.
+	// Query parameter: "atMilestone" returns the metadata of the output at the given milestone index.
 	RouteOutputMetadata = "/outputs/:" + restapipkg.ParameterOutputID + "/metadata"
 
+	// RouteAddressOutputs is the route for getting the IDs of the unspent outputs that can be unlocked by an address.
+	// GET returns the output IDs (paginated). Only available if the output index is enabled.
+	RouteAddressOutputs = "/addresses/:" + restapipkg.ParameterBech32Address + "/outputs"
+
+	// RouteAddressBalance is the route for getting the balance of an address.
+	// GET returns the sum of the base tokens and native tokens of all unspent outputs that are owned by the address.
+	// Outputs the address can only unlock in another role (e.g. as governor) and outputs owned by aliases or NFTs
+	// that are controlled by the address are not counted.
+	// Addresses with more than 10000 unspent outputs are rejected, their balance needs to be computed via the outputs route.
+	// Only available if the output index is enabled.
+	RouteAddressBalance = "/addresses/:" + restapipkg.ParameterBech32Address + "/balance"
+
+	// RouteLedgerAtIndex is the route for getting the unspent outputs of the ledger at a given milestone index.
+	// GET returns the outputs that were unspent at the milestone. Only available for milestones that were not pruned yet.
+	// Query parameters: "pageSize" and "cursor".
+	RouteLedgerAtIndex = "/ledger/at/:" + restapipkg.ParameterMilestoneIndex
+
 	// RouteTreasury is the route for getting the current treasury output.
 	// GET returns the treasury.
 	RouteTreasury = "/treasury"
@@ -120,13 +159,43 @@ const (
 	// POST adds a new peer.
 	RoutePeers = "/peers"
 
+	// RoutePeersBans is the route to manage the banned peer IDs, IP ranges and multiaddresses.
+	// GET returns a list of all active bans, including the bans of peers due to their reputation.
+	// POST adds or updates a ban.
+	// DELETE lifts the ban of the target given by the query parameter.
+	RoutePeersBans = "/peers/bans"
+
 	// RouteControlDatabasePrune is the control route to manually prune the database.
 	// POST prunes the database.
 	RouteControlDatabasePrune = "/control/database/prune"
 
+	// RouteControlPruningHolds is the control route to manage the holds that prevent pruning of milestones.
+	// INX extensions can manage the holds via the pruning holds service of the INX server.
+	// GET returns all active pruning holds.
+	// POST adds or updates a pruning hold.
+	RouteControlPruningHolds = "/control/pruning/holds"
+
+	// RouteControlPruningHold is the control route to release a pruning hold.
+	// DELETE releases the pruning hold.
+	RouteControlPruningHold = "/control/pruning/holds/:" + restapipkg.ParameterHoldID
+
+	// RouteControlDatabaseBackup is the control route to manually create a backup of the database.
+	// POST creates a backup.
+	RouteControlDatabaseBackup = "/control/database/backup"
+
 	// RouteControlSnapshotsCreate is the control route to manually create a snapshot files.
 	// POST creates a full snapshot.
 	RouteControlSnapshotsCreate = "/control/snapshots/create"
+
+	// RouteControlSnapshotsHistory is the control route to list the snapshot files in the snapshot history.
+	// GET returns the manifest of the snapshot history.
+	RouteControlSnapshotsHistory = "/control/snapshots/history"
+
+	// RouteControlSnapshotsHistoryFile is the control route to download a snapshot file from the snapshot history.
+	// The latest snapshot files can be downloaded as "latest-full_snapshot.bin" and "latest-delta_snapshot.bin",
+	// the hashes of all files as "sha256sums.txt".
+	// GET returns the snapshot file (range requests are supported).
+	RouteControlSnapshotsHistoryFile = "/control/snapshots/history/:" + restapipkg.ParameterFileName
 )
 
 func init() {
@@ -161,13 +230,16 @@ type dependencies struct {
 	PoWHandler              *pow.Handler
 	SnapshotManager         *snapshot.Manager
 	PruningManager          *pruning.Manager
+	BackupManager           *backup.Manager
 	AppInfo                 *app.Info
 	PeeringConfigManager    *p2p.ConfigManager
+	BanList                 *p2p.BanList
 	ProtocolManager         *protocol.Manager
 	BaseToken               *protocfg.BaseToken
 	RestAPILimitsMaxResults int                       `name:"restAPILimitsMaxResults"`
 	SnapshotsFullPath       string                    `name:"snapshotsFullPath"`
 	SnapshotsDeltaPath      string                    `name:"snapshotsDeltaPath"`
+	Archive                 *archive.Archive          `optional:"true"`
 	TipSelector             *tipselect.TipSelector    `optional:"true"`
 	RestRouteManager        *restapi.RestRouteManager `optional:"true"`
 	RestAPIMetrics          *metrics.RestAPIMetrics
@@ -263,6 +335,15 @@ func configure() error {
 		return httpserver.JSONResponse(c, http.StatusCreated, resp)
 	}, checkNodeAlmostSynced(), checkUpcomingUnsupportedProtocolVersion())
 
+	routeGroup.POST(RouteBlocksValidate, func(c echo.Context) error {
+		resp, err := validateBlock(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	}, checkNodeAlmostSynced(), checkUpcomingUnsupportedProtocolVersion())
+
 	routeGroup.GET(RouteTransactionsIncludedBlock, func(c echo.Context) error {
 		mimeType, err := httpserver.GetAcceptHeaderContentType(c, httpserver.MIMEApplicationVendorIOTASerializerV1, echo.MIMEApplicationJSON)
 		if err != nil && err != httpserver.ErrNotAcceptable {
@@ -298,6 +379,15 @@ func configure() error {
 		return httpserver.JSONResponse(c, http.StatusOK, resp)
 	}, checkNodeAlmostSynced())
 
+	routeGroup.GET(RouteMilestones, func(c echo.Context) error {
+		resp, err := milestones(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	})
+
 	routeGroup.GET(RouteMilestoneByID, func(c echo.Context) error {
 		mimeType, err := httpserver.GetAcceptHeaderContentType(c, httpserver.MIMEApplicationVendorIOTASerializerV1, echo.MIMEApplicationJSON)
 		if err != nil && err != httpserver.ErrNotAcceptable {
@@ -368,6 +458,15 @@ func configure() error {
 		return httpserver.JSONResponse(c, http.StatusOK, resp)
 	})
 
+	routeGroup.GET(RouteMilestoneByIndexReferencedBlocks, func(c echo.Context) error {
+		resp, err := milestoneReferencedBlocksByIndex(Component.Daemon().ContextStopped(), c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	})
+
 	routeGroup.GET(RouteOutput, func(c echo.Context) error {
 		mimeType, err := httpserver.GetAcceptHeaderContentType(c, httpserver.MIMEApplicationVendorIOTASerializerV1, echo.MIMEApplicationJSON)
 		if err != nil && err != httpserver.ErrNotAcceptable {
@@ -403,6 +502,35 @@ func configure() error {
 		return httpserver.JSONResponse(c, http.StatusOK, resp)
 	})
 
+	if deps.UTXOManager.OutputIndexEnabled() {
+		routeGroup.GET(RouteAddressOutputs, func(c echo.Context) error {
+			resp, err := addressOutputIDs(c)
+			if err != nil {
+				return err
+			}
+
+			return httpserver.JSONResponse(c, http.StatusOK, resp)
+		})
+
+		routeGroup.GET(RouteAddressBalance, func(c echo.Context) error {
+			resp, err := addressBalance(c)
+			if err != nil {
+				return err
+			}
+
+			return httpserver.JSONResponse(c, http.StatusOK, resp)
+		})
+	}
+
+	routeGroup.GET(RouteLedgerAtIndex, func(c echo.Context) error {
+		resp, err := ledgerAtMilestone(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	})
+
 	routeGroup.GET(RouteTreasury, func(c echo.Context) error {
 		resp, err := treasury(c)
 		if err != nil {
@@ -465,6 +593,32 @@ func configure() error {
 		return httpserver.JSONResponse(c, http.StatusOK, resp)
 	})
 
+	routeGroup.GET(RoutePeersBans, func(c echo.Context) error {
+		resp, err := peerBans(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	})
+
+	routeGroup.POST(RoutePeersBans, func(c echo.Context) error {
+		resp, err := addPeerBan(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	})
+
+	routeGroup.DELETE(RoutePeersBans, func(c echo.Context) error {
+		if err := removePeerBan(c); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	})
+
 	routeGroup.POST(RouteComputeWhiteFlagMutations, func(c echo.Context) error {
 		resp, err := computeWhiteFlagMutations(c)
 		if err != nil {
@@ -483,6 +637,41 @@ func configure() error {
 		return httpserver.JSONResponse(c, http.StatusOK, resp)
 	})
 
+	routeGroup.GET(RouteControlPruningHolds, func(c echo.Context) error {
+		resp, err := pruningHolds(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	})
+
+	routeGroup.POST(RouteControlPruningHolds, func(c echo.Context) error {
+		resp, err := addPruningHold(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	})
+
+	routeGroup.DELETE(RouteControlPruningHold, func(c echo.Context) error {
+		if err := removePruningHold(c); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	routeGroup.POST(RouteControlDatabaseBackup, func(c echo.Context) error {
+		resp, err := backupDatabase(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	})
+
 	routeGroup.POST(RouteControlSnapshotsCreate, func(c echo.Context) error {
 		resp, err := createSnapshots(c)
 		if err != nil {
@@ -492,6 +681,24 @@ func configure() error {
 		return httpserver.JSONResponse(c, http.StatusOK, resp)
 	})
 
+	routeGroup.GET(RouteControlSnapshotsHistory, func(c echo.Context) error {
+		resp, err := snapshotHistory(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	})
+
+	routeGroup.GET(RouteControlSnapshotsHistoryFile, func(c echo.Context) error {
+		filePath, err := snapshotHistoryFilePath(c)
+		if err != nil {
+			return err
+		}
+
+		return c.File(filePath)
+	})
+
 	return nil
 }
 
@@ -3,11 +3,16 @@ package coreapi
 import (
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/gommon/bytes"
 	"github.com/pkg/errors"
 
+	"github.com/iotaledger/hornet/v2/pkg/backup"
+	"github.com/iotaledger/hornet/v2/pkg/pruning"
+	"github.com/iotaledger/hornet/v2/pkg/restapi"
+	"github.com/iotaledger/hornet/v2/pkg/snapshot"
 	"github.com/iotaledger/inx-app/pkg/httpserver"
 	iotago "github.com/iotaledger/iota.go/v3"
 )
@@ -23,11 +28,18 @@ func pruneDatabase(c echo.Context) (*pruneDatabaseResponse, error) {
 		return nil, errors.WithMessagef(httpserver.ErrInvalidParameter, "invalid request, error: %s", err)
 	}
 
-	if (request.Index == nil && request.Depth == nil && request.TargetDatabaseSize == nil) ||
-		(request.Index != nil && request.Depth != nil) ||
-		(request.Index != nil && request.TargetDatabaseSize != nil) ||
-		(request.Depth != nil && request.TargetDatabaseSize != nil) {
-		return nil, errors.WithMessage(httpserver.ErrInvalidParameter, "either index, depth or size has to be specified")
+	specified := 0
+	for _, isSet := range []bool{request.Index != nil, request.Depth != nil, request.TargetDatabaseSize != nil, request.RetentionPeriod != nil} {
+		if isSet {
+			specified++
+		}
+	}
+	if specified != 1 {
+		return nil, errors.WithMessage(httpserver.ErrInvalidParameter, "either index, depth, size or retention period has to be specified")
+	}
+
+	if request.DryRun {
+		return pruneDatabaseDryRun(request)
 	}
 
 	var err error
@@ -59,11 +71,190 @@ func pruneDatabase(c echo.Context) (*pruneDatabaseResponse, error) {
 		}
 	}
 
+	if request.RetentionPeriod != nil {
+		retentionPeriod, err := time.ParseDuration(*request.RetentionPeriod)
+		if err != nil {
+			return nil, errors.WithMessagef(httpserver.ErrInvalidParameter, "invalid retention period, error: %s", err)
+		}
+
+		targetIndex, err = deps.PruningManager.PruneDatabaseByRetentionPeriod(Component.Daemon().ContextStopped(), retentionPeriod)
+		if err != nil {
+			return nil, errors.WithMessagef(echo.ErrInternalServerError, "pruning database failed: %s", err)
+		}
+	}
+
 	return &pruneDatabaseResponse{
 		Index: targetIndex,
 	}, nil
 }
 
+func pruneDatabaseDryRun(request *pruneDatabaseRequest) (*pruneDatabaseResponse, error) {
+
+	var err error
+	var report *pruning.Report
+
+	if request.Index != nil {
+		report, err = deps.PruningManager.DryRunByTargetIndex(Component.Daemon().ContextStopped(), *request.Index)
+		if err != nil {
+			return nil, errors.WithMessagef(echo.ErrInternalServerError, "pruning dry run failed: %s", err)
+		}
+	}
+
+	if request.Depth != nil {
+		report, err = deps.PruningManager.DryRunByDepth(Component.Daemon().ContextStopped(), *request.Depth)
+		if err != nil {
+			return nil, errors.WithMessagef(echo.ErrInternalServerError, "pruning dry run failed: %s", err)
+		}
+	}
+
+	if request.TargetDatabaseSize != nil {
+		pruningTargetDatabaseSizeBytes, err := bytes.Parse(*request.TargetDatabaseSize)
+		if err != nil {
+			return nil, errors.WithMessagef(echo.ErrInternalServerError, "pruning dry run failed: %s", err)
+		}
+
+		report, err = deps.PruningManager.DryRunBySize(Component.Daemon().ContextStopped(), pruningTargetDatabaseSizeBytes)
+		if err != nil {
+			return nil, errors.WithMessagef(echo.ErrInternalServerError, "pruning dry run failed: %s", err)
+		}
+	}
+
+	if request.RetentionPeriod != nil {
+		retentionPeriod, err := time.ParseDuration(*request.RetentionPeriod)
+		if err != nil {
+			return nil, errors.WithMessagef(httpserver.ErrInvalidParameter, "invalid retention period, error: %s", err)
+		}
+
+		report, err = deps.PruningManager.DryRunByRetentionPeriod(Component.Daemon().ContextStopped(), retentionPeriod)
+		if err != nil {
+			return nil, errors.WithMessagef(echo.ErrInternalServerError, "pruning dry run failed: %s", err)
+		}
+	}
+
+	solidEntryPoints := make([]*solidEntryPointResponse, 0, len(report.SolidEntryPoints))
+	for _, sep := range report.SolidEntryPoints {
+		solidEntryPoints = append(solidEntryPoints, &solidEntryPointResponse{
+			BlockID: sep.BlockID.ToHex(),
+			Index:   sep.Index,
+		})
+	}
+
+	return &pruneDatabaseResponse{
+		Index: report.TargetIndex,
+		Report: &pruningReportResponse{
+			PruningIndex:           report.PruningIndex,
+			TargetIndex:            report.TargetIndex,
+			Milestones:             report.Milestones,
+			Blocks:                 report.Blocks,
+			UnreferencedBlocks:     report.UnreferencedBlocks,
+			MilestoneDiffs:         report.MilestoneDiffs,
+			Outputs:                report.Outputs,
+			Spents:                 report.Spents,
+			Receipts:               report.Receipts,
+			EstimatedBytes:         report.EstimatedBytes,
+			DatabaseSizeBytes:      report.DatabaseSizeBytes,
+			ExpectedReclaimedBytes: report.ExpectedReclaimedBytes,
+			SolidEntryPoints:       solidEntryPoints,
+		},
+	}, nil
+}
+
+func newPruningHoldResponse(hold *pruning.Hold) *pruningHoldResponse {
+	response := &pruningHoldResponse{
+		ID:        hold.ID,
+		Index:     hold.Index,
+		CreatedAt: hold.CreatedAt.Unix(),
+	}
+	if !hold.ExpiresAt.IsZero() {
+		response.ExpiresAt = hold.ExpiresAt.Unix()
+	}
+
+	return response
+}
+
+//nolint:unparam // even if the error is never used, the structure of all routes should be the same
+func pruningHolds(_ echo.Context) (*pruningHoldsResponse, error) {
+	holds := deps.PruningManager.Holds()
+
+	response := &pruningHoldsResponse{
+		Holds: make([]*pruningHoldResponse, len(holds)),
+	}
+	for i, hold := range holds {
+		response.Holds[i] = newPruningHoldResponse(hold)
+	}
+
+	return response, nil
+}
+
+func addPruningHold(c echo.Context) (*pruningHoldResponse, error) {
+
+	request := &addPruningHoldRequest{}
+	if err := c.Bind(request); err != nil {
+		return nil, errors.WithMessagef(httpserver.ErrInvalidParameter, "invalid request, error: %s", err)
+	}
+
+	if request.ID == "" {
+		return nil, errors.WithMessage(httpserver.ErrInvalidParameter, "id has to be specified")
+	}
+
+	var ttl time.Duration
+	if request.TTL != "" {
+		var err error
+		ttl, err = time.ParseDuration(request.TTL)
+		if err != nil || ttl < 0 {
+			return nil, errors.WithMessagef(httpserver.ErrInvalidParameter, "invalid ttl: %s", request.TTL)
+		}
+	}
+
+	hold, err := deps.PruningManager.AddHold(request.ID, request.Index, ttl)
+	if err != nil {
+		if errors.Is(err, pruning.ErrHoldIndexAlreadyPruned) || errors.Is(err, pruning.ErrHoldIndexNotConfirmed) {
+			return nil, errors.WithMessagef(httpserver.ErrInvalidParameter, "adding pruning hold failed: %s", err)
+		}
+
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "adding pruning hold failed: %s", err)
+	}
+
+	return newPruningHoldResponse(hold), nil
+}
+
+func removePruningHold(c echo.Context) error {
+	holdID := c.Param(restapi.ParameterHoldID)
+
+	if err := deps.PruningManager.RemoveHold(holdID); err != nil {
+		if errors.Is(err, pruning.ErrHoldNotFound) {
+			return errors.WithMessagef(echo.ErrNotFound, "pruning hold not found: %s", holdID)
+		}
+
+		return errors.WithMessagef(echo.ErrInternalServerError, "releasing pruning hold failed: %s", err)
+	}
+
+	return nil
+}
+
+func backupDatabase(_ echo.Context) (*backupDatabaseResponse, error) {
+
+	if deps.SnapshotManager.IsSnapshotting() || deps.PruningManager.IsPruning() || deps.BackupManager.IsBackingUp() {
+		return nil, errors.WithMessage(echo.ErrServiceUnavailable, "node is already creating a snapshot, pruning or a backup is running")
+	}
+
+	info, err := deps.BackupManager.CreateBackup()
+	if err != nil {
+		if errors.Is(err, backup.ErrBackupRunning) || errors.Is(err, backup.ErrBackupAlreadyExists) {
+			return nil, errors.WithMessagef(echo.ErrServiceUnavailable, "creating backup failed: %s", err)
+		}
+
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "creating backup failed: %s", err)
+	}
+
+	return &backupDatabaseResponse{
+		Name:        info.Name,
+		Path:        info.Path,
+		LedgerIndex: info.LedgerIndex,
+		Quiesced:    info.Quiesced,
+	}, nil
+}
+
 func createSnapshots(c echo.Context) (*createSnapshotsResponse, error) {
 
 	if deps.SnapshotManager.IsSnapshotting() || deps.PruningManager.IsPruning() {
@@ -89,3 +280,62 @@ func createSnapshots(c echo.Context) (*createSnapshotsResponse, error) {
 		FilePath: filePath,
 	}, nil
 }
+
+func snapshotHistoryOrError() (*snapshot.History, error) {
+	history := deps.SnapshotManager.History()
+	if history == nil {
+		return nil, errors.WithMessage(echo.ErrServiceUnavailable, "snapshot history is disabled")
+	}
+
+	return history, nil
+}
+
+func snapshotHistory(_ echo.Context) (*snapshotHistoryResponse, error) {
+	history, err := snapshotHistoryOrError()
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := history.Manifest()
+
+	response := &snapshotHistoryResponse{
+		LatestFull:  manifest.LatestFull,
+		LatestDelta: manifest.LatestDelta,
+		Files:       make([]*snapshotHistoryFileResponse, len(manifest.Files)),
+	}
+	for i, file := range manifest.Files {
+		response.Files[i] = &snapshotHistoryFileResponse{
+			FileName:                      file.FileName,
+			Type:                          file.Type,
+			TargetMilestoneIndex:          file.TargetMilestoneIndex,
+			TargetMilestoneTimestamp:      file.TargetMilestoneTimestamp,
+			TargetMilestoneID:             file.TargetMilestoneID,
+			LedgerMilestoneIndex:          file.LedgerMilestoneIndex,
+			FullSnapshotTargetMilestoneID: file.FullSnapshotTargetMilestoneID,
+			Size:                          file.Size,
+			SHA256:                        file.SHA256,
+			CreatedAt:                     file.CreatedAt,
+		}
+	}
+
+	return response, nil
+}
+
+func snapshotHistoryFilePath(c echo.Context) (string, error) {
+	history, err := snapshotHistoryOrError()
+	if err != nil {
+		return "", err
+	}
+
+	fileName := c.Param(restapi.ParameterFileName)
+	if fileName == snapshot.HistoryHashManifestFileName {
+		return filepath.Join(history.Directory(), snapshot.HistoryHashManifestFileName), nil
+	}
+
+	filePath, err := history.FilePath(fileName)
+	if err != nil {
+		return "", errors.WithMessagef(echo.ErrNotFound, "snapshot file not found: %s", fileName)
+	}
+
+	return filePath, nil
+}
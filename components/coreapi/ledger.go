@@ -0,0 +1,99 @@
+package coreapi
+
+import (
+	"encoding/binary"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/serializer/v2"
+	"github.com/iotaledger/hornet/v2/pkg/model/utxo"
+	"github.com/iotaledger/hornet/v2/pkg/restapi"
+	"github.com/iotaledger/inx-app/pkg/httpserver"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+const (
+	// ledgerCursorLength is the length of the key of a ledger cursor (milestone index + output ID of the next output).
+	ledgerCursorLength = serializer.UInt32ByteSize + iotago.OutputIDLength
+)
+
+func ledgerAtMilestone(c echo.Context) (*ledgerOutputsResponse, error) {
+	msIndex, err := httpserver.ParseMilestoneIndexParam(c, restapi.ParameterMilestoneIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	var startOutputID *iotago.OutputID
+	var pageSize int
+	if len(c.QueryParam(restapi.QueryParameterCursor)) > 0 {
+		key, cursorPageSize, err := restapi.ParseCursorQueryParam(c, ledgerCursorLength, deps.RestAPILimitsMaxResults)
+		if err != nil {
+			return nil, err
+		}
+
+		if cursorIndex := binary.BigEndian.Uint32(key[:serializer.UInt32ByteSize]); cursorIndex != msIndex {
+			return nil, errors.WithMessagef(httpserver.ErrInvalidParameter, "invalid cursor: cursor of milestone %d used for milestone %d", cursorIndex, msIndex)
+		}
+
+		startOutputID = &iotago.OutputID{}
+		copy(startOutputID[:], key[serializer.UInt32ByteSize:])
+		pageSize = cursorPageSize
+	} else {
+		pageSize, err = restapi.ParsePageSizeQueryParam(c, deps.RestAPILimitsMaxResults)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var cursor string
+	outputs := make([]*OutputResponse, 0)
+
+	var innerErr error
+	if err := deps.UTXOManager.ForEachUnspentOutputIDAtMilestone(msIndex, startOutputID, func(outputID iotago.OutputID) bool {
+		if len(outputs) >= pageSize {
+			// there are further outputs, the next page starts at this output of the milestone
+			key := make([]byte, ledgerCursorLength)
+			binary.BigEndian.PutUint32(key[:serializer.UInt32ByteSize], msIndex)
+			copy(key[serializer.UInt32ByteSize:], outputID[:])
+			cursor = restapi.EncodeCursor(key, pageSize)
+
+			return false
+		}
+
+		output, err := deps.UTXOManager.ReadOutputByOutputIDWithoutLocking(outputID)
+		if err != nil {
+			innerErr = errors.WithMessagef(echo.ErrInternalServerError, "reading output failed: %s, error: %s", outputID.ToHex(), err)
+
+			return false
+		}
+
+		// the outputs were unspent at the milestone, even if they were spent afterwards
+		outputResponse, err := NewOutputResponse(output, msIndex)
+		if err != nil {
+			innerErr = err
+
+			return false
+		}
+		outputs = append(outputs, outputResponse)
+
+		return true
+	}, utxo.MaxResultCount(pageSize+1)); err != nil {
+		if errors.Is(err, utxo.ErrHistoricLedgerStateUnavailable) {
+			return nil, errors.WithMessagef(echo.ErrNotFound, "ledger state at milestone %d not available: %s", msIndex, err)
+		}
+
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading ledger state at milestone %d failed, error: %s", msIndex, err)
+	}
+
+	if innerErr != nil {
+		return nil, innerErr
+	}
+
+	return &ledgerOutputsResponse{
+		LedgerIndex: msIndex,
+		PageSize:    pageSize,
+		Cursor:      cursor,
+		Outputs:     outputs,
+	}, nil
+}
@@ -1,10 +1,16 @@
 package coreapi
 
 import (
+	"context"
+	"encoding/binary"
+
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
 
 	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/serializer/v2"
+	"github.com/iotaledger/hornet/v2/pkg/common"
+	"github.com/iotaledger/hornet/v2/pkg/dag"
 	"github.com/iotaledger/hornet/v2/pkg/model/storage"
 	"github.com/iotaledger/hornet/v2/pkg/restapi"
 	"github.com/iotaledger/inx-app/pkg/httpserver"
@@ -20,7 +26,8 @@ func storageMilestoneByIndex(c echo.Context) (*storage.Milestone, error) {
 
 	cachedMilestone := deps.Storage.CachedMilestoneByIndexOrNil(msIndex) // milestone +1
 	if cachedMilestone == nil {
-		return nil, errors.WithMessagef(echo.ErrNotFound, "milestone index not found: %d", msIndex)
+		// the milestone might have been moved to the archive
+		return archivedMilestoneByIndex(msIndex)
 	}
 	defer cachedMilestone.Release(true) // milestone -1
 
@@ -36,7 +43,8 @@ func storageMilestoneByID(c echo.Context) (*storage.Milestone, error) {
 
 	cachedMilestone := deps.Storage.CachedMilestoneOrNil(*milestoneID) // milestone +1
 	if cachedMilestone == nil {
-		return nil, errors.WithMessagef(echo.ErrNotFound, "milestone not found: %s", iotago.EncodeHex((*milestoneID)[:]))
+		// the milestone might have been moved to the archive
+		return archivedMilestoneByID(*milestoneID)
 	}
 	defer cachedMilestone.Release(true) // milestone -1
 
@@ -81,6 +89,10 @@ func milestoneBytesByID(c echo.Context) ([]byte, error) {
 
 func milestoneUTXOChanges(msIndex iotago.MilestoneIndex) (*milestoneUTXOChangesResponse, error) {
 	diff, err := deps.UTXOManager.MilestoneDiffWithoutLocking(msIndex)
+	if err != nil && errors.Is(err, kvstore.ErrKeyNotFound) {
+		// the milestone diff might have been moved to the archive
+		diff, err = archivedMilestoneDiff(msIndex)
+	}
 	if err != nil {
 		if errors.Is(err, kvstore.ErrKeyNotFound) {
 			return nil, errors.WithMessagef(echo.ErrNotFound, "can't load milestone diff for index: %d, error: %s", msIndex, err)
@@ -124,3 +136,172 @@ func milestoneUTXOChangesByID(c echo.Context) (*milestoneUTXOChangesResponse, er
 
 	return milestoneUTXOChanges(ms.Index())
 }
+
+const (
+	// milestonesCursorLength is the length of the key of a milestones cursor (next index + range end).
+	milestonesCursorLength = 2 * serializer.UInt32ByteSize
+	// referencedBlocksCursorLength is the length of the key of a referenced blocks cursor (white flag index of the next block).
+	referencedBlocksCursorLength = serializer.UInt32ByteSize
+)
+
+// milestonesRange returns the requested range of milestone indexes and the page size.
+// If a cursor is given, the range and page size are taken from the cursor.
+func milestonesRange(c echo.Context) (iotago.MilestoneIndex, iotago.MilestoneIndex, int, error) {
+	if len(c.QueryParam(restapi.QueryParameterCursor)) > 0 {
+		key, pageSize, err := restapi.ParseCursorQueryParam(c, milestonesCursorLength, deps.RestAPILimitsMaxResults)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+
+		return binary.BigEndian.Uint32(key[:serializer.UInt32ByteSize]), binary.BigEndian.Uint32(key[serializer.UInt32ByteSize:]), pageSize, nil
+	}
+
+	pageSize, err := restapi.ParsePageSizeQueryParam(c, deps.RestAPILimitsMaxResults)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	var from iotago.MilestoneIndex
+	if snapshotInfo := deps.Storage.SnapshotInfo(); snapshotInfo != nil {
+		// the pruning index itself is already pruned
+		from = snapshotInfo.PruningIndex() + 1
+	}
+
+	if len(c.QueryParam(restapi.QueryParameterFrom)) > 0 {
+		queryFrom, err := httpserver.ParseUint32QueryParam(c, restapi.QueryParameterFrom)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+
+		if queryFrom > from {
+			from = queryFrom
+		}
+	}
+
+	to := deps.SyncManager.ConfirmedMilestoneIndex()
+	if len(c.QueryParam(restapi.QueryParameterTo)) > 0 {
+		queryTo, err := httpserver.ParseUint32QueryParam(c, restapi.QueryParameterTo)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+
+		if queryTo < to {
+			to = queryTo
+		}
+	}
+
+	return from, to, pageSize, nil
+}
+
+func milestones(c echo.Context) (*milestonesResponse, error) {
+	from, to, pageSize, err := milestonesRange(c)
+	if err != nil {
+		return nil, err
+	}
+
+	milestones := make([]*iotago.Milestone, 0)
+
+	msIndex := from
+	for ; msIndex <= to && len(milestones) < pageSize; msIndex++ {
+		cachedMilestone := deps.Storage.CachedMilestoneByIndexOrNil(msIndex) // milestone +1
+		if cachedMilestone == nil {
+			// milestone was pruned or is missing
+			continue
+		}
+		milestones = append(milestones, cachedMilestone.Milestone().Milestone())
+		cachedMilestone.Release(true) // milestone -1
+	}
+
+	var cursor string
+	if msIndex <= to {
+		key := make([]byte, milestonesCursorLength)
+		binary.BigEndian.PutUint32(key[:serializer.UInt32ByteSize], msIndex)
+		binary.BigEndian.PutUint32(key[serializer.UInt32ByteSize:], to)
+		cursor = restapi.EncodeCursor(key, pageSize)
+	}
+
+	return &milestonesResponse{
+		PageSize:   pageSize,
+		Cursor:     cursor,
+		Milestones: milestones,
+	}, nil
+}
+
+func milestoneReferencedBlocksByIndex(ctx context.Context, c echo.Context) (*milestoneReferencedBlocksResponse, error) {
+	ms, err := storageMilestoneByIndex(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if ms.Index() > deps.SyncManager.ConfirmedMilestoneIndex() {
+		return nil, errors.WithMessagef(echo.ErrNotFound, "milestone %d not confirmed yet", ms.Index())
+	}
+
+	if isPrunedMilestoneIndex(ms.Index()) {
+		// the milestone cone can't be traversed anymore, even if the milestone was archived
+		return nil, errors.WithMessagef(echo.ErrNotFound, "milestone cone %d was already pruned", ms.Index())
+	}
+
+	var whiteFlagIndex uint32
+	var pageSize int
+	if len(c.QueryParam(restapi.QueryParameterCursor)) > 0 {
+		key, cursorPageSize, err := restapi.ParseCursorQueryParam(c, referencedBlocksCursorLength, deps.RestAPILimitsMaxResults)
+		if err != nil {
+			return nil, err
+		}
+		whiteFlagIndex = binary.BigEndian.Uint32(key)
+		pageSize = cursorPageSize
+	} else {
+		pageSize, err = restapi.ParsePageSizeQueryParam(c, deps.RestAPILimitsMaxResults)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the traversal is stopped after the page is full and we know if there are further blocks
+	errPageFull := errors.New("page full")
+
+	var cursor string
+	blockIDs := make([]string, 0)
+
+	memcachedTraverserStorage := dag.NewMemcachedTraverserStorage(deps.Storage, storage.NewMetadataMemcache(deps.Storage.CachedBlockMetadata))
+	defer memcachedTraverserStorage.Cleanup(true)
+
+	// the blocks of the previous pages are not traversed again
+	if err := dag.TraverseMilestoneConeFromWhiteFlagIndex(
+		ctx,
+		memcachedTraverserStorage,
+		ms.Index(),
+		ms.Parents(),
+		whiteFlagIndex,
+		func(cachedBlockMeta *storage.CachedMetadata) error { // meta +1
+			defer cachedBlockMeta.Release(true) // meta -1
+
+			if len(blockIDs) >= pageSize {
+				_, _, wfIndex := cachedBlockMeta.Metadata().ReferencedWithIndexAndWhiteFlagIndex()
+
+				key := make([]byte, referencedBlocksCursorLength)
+				binary.BigEndian.PutUint32(key, wfIndex)
+				cursor = restapi.EncodeCursor(key, pageSize)
+
+				return errPageFull
+			}
+
+			blockIDs = append(blockIDs, cachedBlockMeta.Metadata().BlockID().ToHex())
+
+			return nil
+		}); err != nil && !errors.Is(err, errPageFull) {
+		if errors.Is(err, common.ErrOperationAborted) {
+			return nil, errors.WithMessagef(echo.ErrServiceUnavailable, "traverse parents failed, error: %s", err)
+		}
+
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "traverse parents failed, error: %s", err)
+	}
+
+	return &milestoneReferencedBlocksResponse{
+		Index:    ms.Index(),
+		PageSize: pageSize,
+		Cursor:   cursor,
+		BlockIDs: blockIDs,
+	}, nil
+}
@@ -0,0 +1,99 @@
+//nolint:forcetypeassert,varnamelen,revive,exhaustruct // we don't care about these linters in test cases
+package coreapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hornet/v2/pkg/restapi"
+	"github.com/iotaledger/hornet/v2/pkg/testsuite"
+	"github.com/iotaledger/hornet/v2/pkg/whiteflag"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+const (
+	ProtocolVersion = 2
+	BelowMaxDepth   = 5
+	MinPoWScore     = 10
+)
+
+func referencedBlocksRequest(t *testing.T, msIndex iotago.MilestoneIndex, query string) *milestoneReferencedBlocksResponse {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/?"+query, nil)
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+	c.SetParamNames(restapi.ParameterMilestoneIndex)
+	c.SetParamValues(fmt.Sprintf("%d", msIndex))
+
+	resp, err := milestoneReferencedBlocksByIndex(context.Background(), c)
+	require.NoError(t, err)
+
+	return resp
+}
+
+func TestMilestoneReferencedBlocksPagination(t *testing.T) {
+	te := testsuite.SetupTestEnvironment(t, &iotago.Ed25519Address{}, 0, ProtocolVersion, BelowMaxDepth, MinPoWScore, false)
+	defer te.CleanupTestEnvironment(true)
+
+	var lastConfirmation *whiteflag.Confirmation
+	_, _ = te.BuildTangle(10, BelowMaxDepth, 3, 20, 40,
+		nil,
+		func(blockIDs iotago.BlockIDs, blockIDsPerMilestones []iotago.BlockIDs) iotago.BlockIDs {
+			// reference the last blocks directly (the coordinator adds the previous milestone as parent),
+			// so the milestone cone is big enough for several pages
+			return blockIDs[len(blockIDs)-(iotago.BlockMaxParents-1):].RemoveDupsAndSort()
+		},
+		func(_ iotago.MilestoneIndex, _ iotago.BlockIDs, conf *whiteflag.Confirmation, _ *whiteflag.ConfirmedMilestoneStats) {
+			lastConfirmation = conf
+		},
+	)
+	require.NotNil(t, lastConfirmation)
+
+	deps = dependencies{
+		Storage:                 te.Storage(),
+		SyncManager:             te.SyncManager(),
+		RestAPILimitsMaxResults: 1000,
+	}
+
+	expected := make([]string, 0, len(lastConfirmation.Mutations.ReferencedBlocks))
+	for _, blockID := range lastConfirmation.Mutations.ReferencedBlocks.BlockIDs() {
+		expected = append(expected, blockID.ToHex())
+	}
+
+	// use a page size that results in at least three pages with a partially filled last page
+	pageSize := (len(expected) - 1) / 3
+	require.Greater(t, pageSize, 0)
+
+	// first page
+	resp := referencedBlocksRequest(t, lastConfirmation.MilestoneIndex, fmt.Sprintf("pageSize=%d", pageSize))
+	require.Equal(t, lastConfirmation.MilestoneIndex, resp.Index)
+	require.Equal(t, pageSize, resp.PageSize)
+	require.Equal(t, expected[:pageSize], resp.BlockIDs)
+	require.NotEmpty(t, resp.Cursor)
+
+	// middle page
+	resp = referencedBlocksRequest(t, lastConfirmation.MilestoneIndex, "cursor="+resp.Cursor)
+	require.Equal(t, pageSize, resp.PageSize)
+	require.Equal(t, expected[pageSize:2*pageSize], resp.BlockIDs)
+	require.NotEmpty(t, resp.Cursor)
+
+	// skip to the last page
+	received := 2 * pageSize
+	for received+pageSize < len(expected) {
+		resp = referencedBlocksRequest(t, lastConfirmation.MilestoneIndex, "cursor="+resp.Cursor)
+		require.Equal(t, expected[received:received+pageSize], resp.BlockIDs)
+		require.NotEmpty(t, resp.Cursor)
+		received += pageSize
+	}
+
+	// last page
+	resp = referencedBlocksRequest(t, lastConfirmation.MilestoneIndex, "cursor="+resp.Cursor)
+	require.Equal(t, expected[received:], resp.BlockIDs)
+	require.Empty(t, resp.Cursor)
+}
@@ -1,6 +1,9 @@
 package coreapi
 
 import (
+	"sort"
+	"time"
+
 	"github.com/labstack/echo/v4"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/multiformats/go-multiaddr"
@@ -39,6 +42,7 @@ func WrapInfoSnapshot(info *p2p.PeerInfoSnapshot) *PeerResponse {
 		Relation:       info.Relation,
 		Connected:      info.Connected,
 		Gossip:         gossipInfo,
+		Reputation:     info.Reputation,
 	}
 }
 
@@ -117,3 +121,116 @@ func addPeer(c echo.Context, logger *logger.Logger) (*PeerResponse, error) {
 
 	return WrapInfoSnapshot(info), nil
 }
+
+const (
+	// peerBanSourceManual is the source of bans that were added to the ban list.
+	peerBanSourceManual = "manual"
+	// peerBanSourceReputation is the source of bans of peers due to their reputation.
+	peerBanSourceReputation = "reputation"
+)
+
+func newPeerBanResponse(ban *p2p.Ban) *peerBanResponse {
+	response := &peerBanResponse{
+		Source:   peerBanSourceManual,
+		Target:   ban.Target,
+		Reason:   ban.Reason,
+		BannedAt: ban.BannedAt.Unix(),
+	}
+	if ban.ExpiresAt != nil {
+		response.ExpiresAt = ban.ExpiresAt.Unix()
+	}
+
+	return response
+}
+
+func newReputationBanResponse(ban *p2p.ReputationBan) *peerBanResponse {
+	return &peerBanResponse{
+		Source:    peerBanSourceReputation,
+		Target:    ban.PeerID.String(),
+		Reason:    "bad reputation",
+		BannedAt:  ban.BannedAt.Unix(),
+		ExpiresAt: ban.BannedUntil.Unix(),
+	}
+}
+
+//nolint:unparam // even if the error is never used, the structure of all routes should be the same
+func peerBans(_ echo.Context) (*peerBansResponse, error) {
+	bans := deps.BanList.Bans()
+	reputationBans := deps.PeeringManager.Reputation().Bans()
+
+	response := &peerBansResponse{
+		Bans: make([]*peerBanResponse, 0, len(bans)+len(reputationBans)),
+	}
+	for _, ban := range bans {
+		response.Bans = append(response.Bans, newPeerBanResponse(ban))
+	}
+	for _, ban := range reputationBans {
+		response.Bans = append(response.Bans, newReputationBanResponse(ban))
+	}
+
+	// merge the manual and the reputation bans by the time they were added
+	sort.SliceStable(response.Bans, func(i, j int) bool {
+		return response.Bans[i].BannedAt < response.Bans[j].BannedAt
+	})
+
+	return response, nil
+}
+
+func addPeerBan(c echo.Context) (*peerBanResponse, error) {
+
+	request := &addPeerBanRequest{}
+	if err := c.Bind(request); err != nil {
+		return nil, errors.WithMessagef(httpserver.ErrInvalidParameter, "invalid request, error: %s", err)
+	}
+
+	var duration time.Duration
+	if request.Duration != "" {
+		var err error
+		duration, err = time.ParseDuration(request.Duration)
+		if err != nil || duration < 0 {
+			return nil, errors.WithMessagef(httpserver.ErrInvalidParameter, "invalid duration: %s", request.Duration)
+		}
+	}
+
+	// connected peers matching the ban are disconnected by the p2p component
+	ban, err := deps.BanList.Add(request.Target, request.Reason, duration)
+	if err != nil {
+		if errors.Is(err, p2p.ErrInvalidBanTarget) {
+			return nil, errors.WithMessagef(httpserver.ErrInvalidParameter, "adding ban failed: %s", err)
+		}
+
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "adding ban failed: %s", err)
+	}
+
+	return newPeerBanResponse(ban), nil
+}
+
+func removePeerBan(c echo.Context) error {
+	target := c.QueryParam(restapi.QueryParameterTarget)
+	if target == "" {
+		return errors.WithMessagef(httpserver.ErrInvalidParameter, "query parameter \"%s\" has to be specified", restapi.QueryParameterTarget)
+	}
+
+	// bans of peers due to their reputation are lifted as well
+	var reputationBanLifted bool
+	if peerID, err := peer.Decode(target); err == nil && deps.PeeringManager.Reputation().IsBanned(peerID) {
+		if err := deps.PeeringManager.Reputation().Unban(peerID); err != nil {
+			return errors.WithMessagef(echo.ErrInternalServerError, "lifting ban failed: %s", err)
+		}
+		reputationBanLifted = true
+	}
+
+	if err := deps.BanList.Remove(target); err != nil {
+		if errors.Is(err, p2p.ErrBanNotFound) {
+			if reputationBanLifted {
+				return nil
+			}
+
+			return errors.WithMessagef(echo.ErrNotFound, "ban not found: %s", target)
+		}
+
+		return errors.WithMessagef(echo.ErrInternalServerError, "lifting ban failed: %s", err)
+	}
+
+	return nil
+}
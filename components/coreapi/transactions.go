@@ -22,6 +22,10 @@ func blockIDByTransactionID(c echo.Context) (iotago.BlockID, error) {
 	copy(outputID[:], transactionID[:])
 
 	output, err := deps.UTXOManager.ReadOutputByOutputIDWithoutLocking(outputID)
+	if err != nil && errors.Is(err, kvstore.ErrKeyNotFound) {
+		// the output might have been moved to the archive
+		output, err = archivedOutput(outputID)
+	}
 	if err != nil {
 		if errors.Is(err, kvstore.ErrKeyNotFound) {
 			return iotago.BlockID{}, errors.WithMessagef(echo.ErrNotFound, "output for transaction not found: %s", transactionID.ToHex())
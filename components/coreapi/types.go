@@ -6,6 +6,7 @@ import (
 	"github.com/iotaledger/hornet/v2/components/protocfg"
 	"github.com/iotaledger/hornet/v2/pkg/model/storage"
 	"github.com/iotaledger/hornet/v2/pkg/model/utxo"
+	"github.com/iotaledger/hornet/v2/pkg/p2p"
 	"github.com/iotaledger/hornet/v2/pkg/protocol"
 	"github.com/iotaledger/hornet/v2/pkg/protocol/gossip"
 	iotago "github.com/iotaledger/iota.go/v3"
@@ -108,6 +109,24 @@ type blockCreatedResponse struct {
 	BlockID string `json:"blockId"`
 }
 
+// blockValidationFailure defines a single failed check of a block validation.
+type blockValidationFailure struct {
+	// The name of the check that failed.
+	Check string `json:"check"`
+	// The reason why the transaction of the block would be conflicting.
+	ConflictReason *storage.Conflict `json:"conflictReason,omitempty"`
+	// The error message of the failed check.
+	Error string `json:"error"`
+}
+
+// blockValidationResponse defines the response of a POST blocks validate REST API call.
+type blockValidationResponse struct {
+	// Whether the block passed all checks.
+	Valid bool `json:"valid"`
+	// The failed checks of the block.
+	Failures []*blockValidationFailure `json:"failures"`
+}
+
 // milestoneUTXOChangesResponse defines the response of a GET milestone UTXO changes REST API call.
 type milestoneUTXOChangesResponse struct {
 	// The index of the milestone.
@@ -118,6 +137,64 @@ type milestoneUTXOChangesResponse struct {
 	ConsumedOutputs []string `json:"consumedOutputs"`
 }
 
+// milestonesResponse defines the response of a GET milestones REST API call.
+type milestonesResponse struct {
+	// The maximum number of milestones in the response.
+	PageSize int `json:"pageSize"`
+	// The cursor to request the next page, omitted if there are no further milestones.
+	Cursor string `json:"cursor,omitempty"`
+	// The milestone payloads in ascending order of their index.
+	Milestones []*iotago.Milestone `json:"milestones"`
+}
+
+// addressOutputsResponse defines the response of a GET address outputs REST API call.
+type addressOutputsResponse struct {
+	// The ledger index at which the outputs were collected.
+	LedgerIndex iotago.MilestoneIndex `json:"ledgerIndex"`
+	// The maximum number of output IDs in the response.
+	PageSize int `json:"pageSize"`
+	// The cursor to request the next page, omitted if there are no further outputs.
+	Cursor string `json:"cursor,omitempty"`
+	// The hex encoded IDs of the unspent outputs that can be unlocked by the address, ordered by output ID.
+	OutputIDs []string `json:"items"`
+}
+
+// ledgerOutputsResponse defines the response of a GET ledger at milestone REST API call.
+type ledgerOutputsResponse struct {
+	// The milestone index at which the ledger state was reconstructed.
+	LedgerIndex iotago.MilestoneIndex `json:"ledgerIndex"`
+	// The maximum number of outputs in the response.
+	PageSize int `json:"pageSize"`
+	// The cursor to request the next page, omitted if there are no further outputs.
+	Cursor string `json:"cursor,omitempty"`
+	// The outputs that were unspent at the milestone, ordered by output ID.
+	Outputs []*OutputResponse `json:"items"`
+}
+
+// addressBalanceResponse defines the response of a GET address balance REST API call.
+type addressBalanceResponse struct {
+	// The ledger index at which the balance was calculated.
+	LedgerIndex iotago.MilestoneIndex `json:"ledgerIndex"`
+	// The sum of the base tokens of all unspent outputs that are owned by the address.
+	Balance string `json:"balance"`
+	// The sums of the native tokens of all unspent outputs that are owned by the address.
+	NativeTokens map[string]string `json:"nativeTokens"`
+	// The amount of unspent outputs that are owned by the address.
+	OutputCount int `json:"outputCount"`
+}
+
+// milestoneReferencedBlocksResponse defines the response of a GET milestone referenced blocks REST API call.
+type milestoneReferencedBlocksResponse struct {
+	// The index of the milestone.
+	Index iotago.MilestoneIndex `json:"index"`
+	// The maximum number of block IDs in the response.
+	PageSize int `json:"pageSize"`
+	// The cursor to request the next page, omitted if there are no further blocks.
+	Cursor string `json:"cursor,omitempty"`
+	// The hex encoded block IDs of the blocks referenced by the milestone in white flag order.
+	BlockIDs []string `json:"blockIds"`
+}
+
 // OutputMetadataResponse defines the response of a GET outputs metadata REST API call.
 type OutputMetadataResponse struct {
 	// The hex encoded block ID of the block.
@@ -157,6 +234,36 @@ type addPeerRequest struct {
 	Alias *string `json:"alias,omitempty"`
 }
 
+// addPeerBanRequest defines the request for a POST peer ban REST API call.
+type addPeerBanRequest struct {
+	// The peer ID, IP address, IP range (CIDR notation) or multiaddress to ban.
+	Target string `json:"target"`
+	// The reason why the target is banned (optional).
+	Reason string `json:"reason,omitempty"`
+	// The duration after which the ban expires (optional, e.g. "24h"), the ban is permanent if not set.
+	Duration string `json:"duration,omitempty"`
+}
+
+// peerBanResponse defines the response of a peer ban.
+type peerBanResponse struct {
+	// The source of the ban ("manual" or "reputation").
+	Source string `json:"source"`
+	// The banned peer ID, IP address, IP range (CIDR notation) or multiaddress.
+	Target string `json:"target"`
+	// The reason why the target was banned.
+	Reason string `json:"reason,omitempty"`
+	// The unix timestamp the ban was added or updated.
+	BannedAt int64 `json:"bannedAt"`
+	// The unix timestamp the ban expires.
+	ExpiresAt int64 `json:"expiresAt,omitempty"`
+}
+
+// peerBansResponse defines the response of a GET peer bans REST API call.
+type peerBansResponse struct {
+	// The active manual and reputation bans sorted by the time they were added.
+	Bans []*peerBanResponse `json:"bans"`
+}
+
 // PeerResponse defines the response of a GET peer REST API call.
 type PeerResponse struct {
 	// The libp2p identifier of the peer.
@@ -171,6 +278,8 @@ type PeerResponse struct {
 	Connected bool `json:"connected"`
 	// The gossip protocol information of the peer.
 	Gossip *gossip.Info `json:"gossip,omitempty"`
+	// The reputation of the peer.
+	Reputation *p2p.ReputationSnapshot `json:"reputation,omitempty"`
 }
 
 // pruneDatabaseRequest defines the request of a prune database REST API call.
@@ -181,12 +290,97 @@ type pruneDatabaseRequest struct {
 	Depth *iotago.MilestoneIndex `json:"depth,omitempty"`
 	// The target size of the database.
 	TargetDatabaseSize *string `json:"targetDatabaseSize,omitempty"`
+	// The period of time the milestone cones are kept in the database (e.g. "720h").
+	RetentionPeriod *string `json:"retentionPeriod,omitempty"`
+	// Whether only the impact of the pruning should be reported, without deleting any data.
+	DryRun bool `json:"dryRun,omitempty"`
 }
 
 // pruneDatabaseResponse defines the response of a prune database REST API call.
 type pruneDatabaseResponse struct {
 	// The index of the snapshot.
 	Index iotago.MilestoneIndex `json:"index"`
+	// The impact of the pruning (only set for dry runs).
+	Report *pruningReportResponse `json:"report,omitempty"`
+}
+
+// solidEntryPointResponse defines a solid entry point.
+type solidEntryPointResponse struct {
+	// The hex encoded block ID of the solid entry point.
+	BlockID string `json:"blockId"`
+	// The milestone index the solid entry point was referenced by.
+	Index iotago.MilestoneIndex `json:"index"`
+}
+
+// pruningReportResponse defines the impact of a database pruning that was not executed.
+type pruningReportResponse struct {
+	// The current pruning index of the database.
+	PruningIndex iotago.MilestoneIndex `json:"pruningIndex"`
+	// The index the database would be pruned to.
+	TargetIndex iotago.MilestoneIndex `json:"targetIndex"`
+	// The amount of milestones that would be pruned.
+	Milestones int `json:"milestones"`
+	// The amount of blocks in the pruned milestone cones.
+	Blocks int `json:"blocks"`
+	// The amount of unreferenced blocks that would be pruned.
+	UnreferencedBlocks int `json:"unreferencedBlocks"`
+	// The amount of ledger diffs that would be pruned.
+	MilestoneDiffs int `json:"milestoneDiffs"`
+	// The amount of created outputs in the pruned ledger diffs.
+	Outputs int `json:"outputs"`
+	// The amount of spent outputs that would be pruned.
+	Spents int `json:"spents"`
+	// The amount of receipts that would be pruned.
+	Receipts int `json:"receipts"`
+	// The estimated size of the raw data that would be pruned.
+	EstimatedBytes int64 `json:"estimatedBytes"`
+	// The current size of the database.
+	DatabaseSizeBytes int64 `json:"databaseSizeBytes"`
+	// The amount of bytes pruning by size expects to reclaim.
+	ExpectedReclaimedBytes int64 `json:"expectedReclaimedBytes,omitempty"`
+	// The solid entry points of the database after pruning.
+	SolidEntryPoints []*solidEntryPointResponse `json:"solidEntryPoints"`
+}
+
+// addPruningHoldRequest defines the request of an add pruning hold REST API call.
+type addPruningHoldRequest struct {
+	// The unique identifier of the hold, an existing hold with the same ID is replaced.
+	ID string `json:"id"`
+	// The oldest milestone index that must be kept in the database.
+	Index iotago.MilestoneIndex `json:"index"`
+	// The duration after which the hold is released automatically (optional, e.g. "10m").
+	TTL string `json:"ttl,omitempty"`
+}
+
+// pruningHoldResponse defines the response of a pruning hold.
+type pruningHoldResponse struct {
+	// The unique identifier of the hold.
+	ID string `json:"id"`
+	// The oldest milestone index that must be kept in the database.
+	Index iotago.MilestoneIndex `json:"index"`
+	// The unix timestamp the hold was added or updated.
+	CreatedAt int64 `json:"createdAt"`
+	// The unix timestamp the hold is released automatically.
+	ExpiresAt int64 `json:"expiresAt,omitempty"`
+}
+
+// pruningHoldsResponse defines the response of a GET pruning holds REST API call.
+type pruningHoldsResponse struct {
+	// The active pruning holds sorted by their index.
+	Holds []*pruningHoldResponse `json:"holds"`
+}
+
+// backupDatabaseResponse defines the response of a backup database REST API call.
+type backupDatabaseResponse struct {
+	// The name of the backup.
+	Name string `json:"name"`
+	// The path to the folder of the backup.
+	Path string `json:"path"`
+	// The ledger index of the backup.
+	LedgerIndex iotago.MilestoneIndex `json:"ledgerIndex"`
+	// Whether the databases were not in use by a running node while the backup was created.
+	// Backups of a running node are never quiesced and need to be revalidated at startup.
+	Quiesced bool `json:"quiesced"`
 }
 
 // createSnapshotsRequest defines the request of a create snapshots REST API call.
@@ -203,6 +397,40 @@ type createSnapshotsResponse struct {
 	FilePath string `json:"filePath"`
 }
 
+// snapshotHistoryFileResponse defines the response of a snapshot file in the snapshot history.
+type snapshotHistoryFileResponse struct {
+	// The name of the snapshot file.
+	FileName string `json:"fileName"`
+	// The type of the snapshot ("full" or "delta").
+	Type string `json:"type"`
+	// The index of the milestone of which the SEPs within the snapshot are from.
+	TargetMilestoneIndex iotago.MilestoneIndex `json:"targetMilestoneIndex"`
+	// The timestamp of the milestone of which the SEPs within the snapshot are from.
+	TargetMilestoneTimestamp uint32 `json:"targetMilestoneTimestamp"`
+	// The ID of the milestone of which the SEPs within the full snapshot are from.
+	TargetMilestoneID string `json:"targetMilestoneId,omitempty"`
+	// The index of the milestone of which the ledger within the full snapshot is from.
+	LedgerMilestoneIndex iotago.MilestoneIndex `json:"ledgerMilestoneIndex,omitempty"`
+	// The target milestone ID of the full snapshot the delta snapshot builds up from.
+	FullSnapshotTargetMilestoneID string `json:"fullSnapshotTargetMilestoneId,omitempty"`
+	// The size of the snapshot file in bytes.
+	Size int64 `json:"size"`
+	// The hex encoded SHA-256 hash of the snapshot file.
+	SHA256 string `json:"sha256"`
+	// The unix timestamp the snapshot file was added to the history.
+	CreatedAt int64 `json:"createdAt"`
+}
+
+// snapshotHistoryResponse defines the response of a GET snapshot history REST API call.
+type snapshotHistoryResponse struct {
+	// The file name of the latest full snapshot file.
+	LatestFull string `json:"latestFull,omitempty"`
+	// The file name of the latest delta snapshot file that fits the latest full snapshot file.
+	LatestDelta string `json:"latestDelta,omitempty"`
+	// The snapshot files in the history sorted by their target milestone index.
+	Files []*snapshotHistoryFileResponse `json:"files"`
+}
+
 // ComputeWhiteFlagMutationsRequest defines the request for a POST debugComputeWhiteFlagMutations REST API call.
 type ComputeWhiteFlagMutationsRequest struct {
 	// The index of the milestone.
@@ -69,12 +69,77 @@ func NewSpentResponse(spent *utxo.Spent, ledgerIndex iotago.MilestoneIndex) (*Ou
 	}, nil
 }
 
+// parseAtMilestoneQueryParam returns the milestone index given by the "atMilestone" query parameter
+// and whether the parameter was given at all.
+func parseAtMilestoneQueryParam(c echo.Context) (iotago.MilestoneIndex, bool, error) {
+	if len(c.QueryParam(restapi.QueryParameterAtMilestone)) == 0 {
+		return 0, false, nil
+	}
+
+	msIndex, err := httpserver.ParseUint32QueryParam(c, restapi.QueryParameterAtMilestone)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return msIndex, true, nil
+}
+
+// outputAtMilestone returns the output and its spent status at the given milestone.
+// The returned spent is nil if the output was unspent at the milestone.
+func outputAtMilestone(outputID iotago.OutputID, msIndex iotago.MilestoneIndex) (*utxo.Output, *utxo.Spent, error) {
+	deps.UTXOManager.ReadLockLedger()
+	defer deps.UTXOManager.ReadUnlockLedger()
+
+	output, spent, err := deps.UTXOManager.ReadOutputAtMilestoneWithoutLocking(outputID, msIndex)
+	if err != nil {
+		if errors.Is(err, utxo.ErrHistoricLedgerStateUnavailable) {
+			return nil, nil, errors.WithMessagef(echo.ErrNotFound, "ledger state at milestone %d not available: %s", msIndex, err)
+		}
+		if errors.Is(err, kvstore.ErrKeyNotFound) {
+			return nil, nil, errors.WithMessagef(echo.ErrNotFound, "output not found at milestone %d: %s", msIndex, outputID.ToHex())
+		}
+
+		return nil, nil, errors.WithMessagef(echo.ErrInternalServerError, "reading output failed: %s, error: %s", outputID.ToHex(), err)
+	}
+
+	return output, spent, nil
+}
+
+// readSpentForOutputIDWithoutLocking returns the spent output from the ledger,
+// or from the archive if the spent was already pruned.
+func readSpentForOutputIDWithoutLocking(outputID iotago.OutputID) (*utxo.Spent, error) {
+	spent, err := deps.UTXOManager.ReadSpentForOutputIDWithoutLocking(outputID)
+	if err != nil && errors.Is(err, kvstore.ErrKeyNotFound) {
+		return archivedSpent(outputID)
+	}
+
+	return spent, err
+}
+
 func outputByID(c echo.Context) (*OutputResponse, error) {
 	outputID, err := httpserver.ParseOutputIDParam(c, restapi.ParameterOutputID)
 	if err != nil {
 		return nil, err
 	}
 
+	msIndex, atMilestone, err := parseAtMilestoneQueryParam(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if atMilestone {
+		output, spent, err := outputAtMilestone(outputID, msIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		if spent != nil {
+			return NewSpentResponse(spent, msIndex)
+		}
+
+		return NewOutputResponse(output, msIndex)
+	}
+
 	// we need to lock the ledger here to have the correct index for unspent info of the output.
 	deps.UTXOManager.ReadLockLedger()
 	defer deps.UTXOManager.ReadUnlockLedger()
@@ -102,7 +167,7 @@ func outputByID(c echo.Context) (*OutputResponse, error) {
 		return NewOutputResponse(output, ledgerIndex)
 	}
 
-	spent, err := deps.UTXOManager.ReadSpentForOutputIDWithoutLocking(outputID)
+	spent, err := readSpentForOutputIDWithoutLocking(outputID)
 	if err != nil {
 		if errors.Is(err, kvstore.ErrKeyNotFound) {
 			return nil, errors.WithMessagef(echo.ErrNotFound, "output not found: %s", outputID.ToHex())
@@ -120,6 +185,24 @@ func outputMetadataByID(c echo.Context) (*OutputMetadataResponse, error) {
 		return nil, err
 	}
 
+	msIndex, atMilestone, err := parseAtMilestoneQueryParam(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if atMilestone {
+		output, spent, err := outputAtMilestone(outputID, msIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		if spent != nil {
+			return NewSpentMetadataResponse(spent, msIndex), nil
+		}
+
+		return NewOutputMetadataResponse(output, msIndex), nil
+	}
+
 	// we need to lock the ledger here to have the correct index for unspent info of the output.
 	deps.UTXOManager.ReadLockLedger()
 	defer deps.UTXOManager.ReadUnlockLedger()
@@ -147,7 +230,7 @@ func outputMetadataByID(c echo.Context) (*OutputMetadataResponse, error) {
 		return NewOutputMetadataResponse(output, ledgerIndex), nil
 	}
 
-	spent, err := deps.UTXOManager.ReadSpentForOutputIDWithoutLocking(outputID)
+	spent, err := readSpentForOutputIDWithoutLocking(outputID)
 	if err != nil {
 		if errors.Is(err, kvstore.ErrKeyNotFound) {
 			return nil, errors.WithMessagef(echo.ErrNotFound, "output not found: %s", outputID.ToHex())
@@ -166,6 +249,10 @@ func rawOutputByID(c echo.Context) ([]byte, error) {
 	}
 
 	bytes, err := deps.UTXOManager.ReadRawOutputBytesByOutputIDWithoutLocking(outputID)
+	if err != nil && errors.Is(err, kvstore.ErrKeyNotFound) {
+		// the output might have been moved to the archive
+		bytes, err = archivedRawOutputBytes(outputID)
+	}
 	if err != nil {
 		if errors.Is(err, kvstore.ErrKeyNotFound) {
 			return nil, errors.WithMessagef(echo.ErrNotFound, "output not found: %s", outputID.ToHex())
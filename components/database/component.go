@@ -11,6 +11,8 @@ import (
 
 	"github.com/iotaledger/hive.go/app"
 	hivedb "github.com/iotaledger/hive.go/kvstore/database"
+	"github.com/iotaledger/hive.go/runtime/event"
+	"github.com/iotaledger/hornet/v2/pkg/backup"
 	"github.com/iotaledger/hornet/v2/pkg/components"
 	"github.com/iotaledger/hornet/v2/pkg/daemon"
 	"github.com/iotaledger/hornet/v2/pkg/database"
@@ -221,6 +223,28 @@ func provide(c *dig.Container) error {
 		Component.LogPanic(err)
 	}
 
+	type backupDeps struct {
+		dig.In
+		Storage        *storage.Storage
+		TangleDatabase *database.Database `name:"tangleDatabase"`
+		UTXODatabase   *database.Database `name:"utxoDatabase"`
+	}
+
+	if err := c.Provide(func(deps backupDeps) *backup.Manager {
+		return backup.NewBackupManager(
+			deps.Storage,
+			map[string]*database.Database{
+				TangleDatabaseDirectoryName: deps.TangleDatabase,
+				UTXODatabaseDirectoryName:   deps.UTXODatabase,
+			},
+			ParamsDatabase.Backup.Path,
+			ParamsDatabase.Backup.MaxBackups,
+			false,
+		)
+	}); err != nil {
+		Component.LogPanic(err)
+	}
+
 	type syncManagerDeps struct {
 		dig.In
 		UTXOManager     *utxo.Manager
@@ -273,6 +297,22 @@ func configure() error {
 		Component.LogInfof("Checking ledger state ... done. took %v", time.Since(ledgerStateCheckStart).Truncate(time.Millisecond))
 	}
 
+	if ParamsDatabase.OutputIndexEnabled {
+		Component.LogInfo("Enabling output index ...")
+		outputIndexStart := time.Now()
+		rebuilt, err := deps.Storage.UTXOManager().EnableOutputIndex()
+		if err != nil {
+			Component.LogPanicf("enabling output index failed: %s", err)
+		}
+		if rebuilt {
+			Component.LogInfof("Enabling output index ... done. index was rebuilt, took %v", time.Since(outputIndexStart).Truncate(time.Millisecond))
+		} else {
+			Component.LogInfo("Enabling output index ... done")
+		}
+	} else if err := deps.Storage.UTXOManager().DisableOutputIndex(); err != nil {
+		Component.LogPanicf("disabling output index failed: %s", err)
+	}
+
 	if err = Component.Daemon().BackgroundWorker("Close database", func(ctx context.Context) {
 		<-ctx.Done()
 
@@ -301,6 +341,23 @@ func run() error {
 			}
 		})
 		defer hook.Unhook()
+
+		hookBackupEvents := func(name string, db *database.Database) *event.Hook[func(*database.Backup)] {
+			return db.Events().Backup.Hook(func(b *database.Backup) {
+				if b.End.IsZero() {
+					Component.LogInfof("Creating checkpoint of %s database for backup %s ...", name, b.Name)
+
+					return
+				}
+				Component.LogInfof("Creating checkpoint of %s database for backup %s ... done, took %v", name, b.Name, b.End.Sub(b.Start).Truncate(time.Millisecond))
+			})
+		}
+
+		hookTangleBackup := hookBackupEvents(TangleDatabaseDirectoryName, deps.TangleDatabase)
+		defer hookTangleBackup.Unhook()
+		hookUTXOBackup := hookBackupEvents(UTXODatabaseDirectoryName, deps.UTXODatabase)
+		defer hookUTXOBackup.Unhook()
+
 		<-ctx.Done()
 	}, daemon.PriorityMetricsUpdater); err != nil {
 		Component.LogPanicf("failed to start worker: %s", err)
@@ -16,5 +16,7 @@ func newMapDB(metrics *metrics.DatabaseMetrics) *database.Database {
 		database.NewEvents(),
 		false,
 		nil,
+		nil,
+		nil,
 	)
 }
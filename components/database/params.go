@@ -16,6 +16,15 @@ type ParametersDatabase struct {
 	Debug bool `default:"false" usage:"ignore the check for corrupted databases (should only be used for debug reasons)"`
 	// CheckLedgerStateOnStartup defines whether to check if the ledger state matches the total supply on startup
 	CheckLedgerStateOnStartup bool `default:"false" usage:"whether to check if the ledger state matches the total supply on startup"`
+	// OutputIndexEnabled defines whether to maintain an index of the unspent outputs by address, alias/NFT/foundry ID and native token.
+	OutputIndexEnabled bool `default:"false" usage:"whether to maintain an index of the unspent outputs by address, alias/NFT/foundry ID and native token"`
+
+	Backup struct {
+		// Path defines the path to the folder in which the database backups are stored.
+		Path string `default:"mainnet/backups" usage:"the path to the folder in which the database backups are stored"`
+		// MaxBackups defines the maximum amount of backups to keep (0 = keep all backups).
+		MaxBackups int `default:"3" usage:"the maximum amount of backups to keep (0 = keep all backups)"`
+	}
 }
 
 var ParamsDatabase = &ParametersDatabase{}
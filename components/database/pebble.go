@@ -2,7 +2,6 @@ package database
 
 import (
 	hivedb "github.com/iotaledger/hive.go/kvstore/database"
-	"github.com/iotaledger/hive.go/kvstore/pebble"
 	"github.com/iotaledger/hornet/v2/pkg/database"
 	"github.com/iotaledger/hornet/v2/pkg/metrics"
 )
@@ -25,7 +24,7 @@ func newPebble(path string, metrics *metrics.DatabaseMetrics) *database.Database
 
 	return database.New(
 		path,
-		pebble.New(db),
+		database.NewPebbleStore(db),
 		hivedb.EnginePebble,
 		metrics,
 		dbEvents,
@@ -33,6 +32,8 @@ func newPebble(path string, metrics *metrics.DatabaseMetrics) *database.Database
 		func() bool {
 			return metrics.CompactionRunning.Load()
 		},
+		database.NewPebbleCheckpointFunc(db),
+		database.NewPebbleCompactFunc(db),
 	)
 
 }
@@ -2,7 +2,6 @@ package database
 
 import (
 	hivedb "github.com/iotaledger/hive.go/kvstore/database"
-	"github.com/iotaledger/hive.go/kvstore/rocksdb"
 	"github.com/iotaledger/hornet/v2/pkg/database"
 	"github.com/iotaledger/hornet/v2/pkg/metrics"
 )
@@ -17,7 +16,7 @@ func newRocksDB(path string, metrics *metrics.DatabaseMetrics) *database.Databas
 
 	return database.New(
 		path,
-		rocksdb.New(rocksDatabase),
+		database.NewRocksDBStore(rocksDatabase),
 		hivedb.EngineRocksDB,
 		metrics,
 		dbEvents,
@@ -39,5 +38,7 @@ func newRocksDB(path string, metrics *metrics.DatabaseMetrics) *database.Databas
 
 			return false
 		},
+		database.NewRocksDBCheckpointFunc(rocksDatabase),
+		database.NewRocksDBCompactFunc(rocksDatabase),
 	)
 }
@@ -33,10 +33,14 @@ const (
 	readBufSize = 2048
 
 	heartbeatSentInterval   = 30 * time.Second
+	heartbeatStaleTimeout   = 2 * heartbeatSentInterval
 	heartbeatReceiveTimeout = 100 * time.Second
 	checkHeartbeatsInterval = 5 * time.Second
 
-	iotaGossipProtocolIDTemplate = "/iota-gossip/%d/1.0.0"
+	// the latest version of the gossip protocol exchanges handshakes and supports batched block requests,
+	// milestone cone requests and batched block messages.
+	iotaGossipProtocolIDTemplate       = "/iota-gossip/%d/1.1.0"
+	iotaGossipLegacyProtocolIDTemplate = "/iota-gossip/%d/1.0.0"
 )
 
 func init() {
@@ -101,7 +105,8 @@ func provide(c *dig.Container) error {
 			deps.ServerMetrics,
 			deps.ProtocolManager,
 			&gossip.Options{
-				WorkUnitCacheOpts: deps.Profile.Caches.IncomingBlocksFilter,
+				WorkUnitCacheOpts:     deps.Profile.Caches.IncomingBlocksFilter,
+				SlowResponseThreshold: ParamsRequests.SlowResponseThreshold,
 			})
 		if err != nil {
 			Component.LogPanicf("MessageProcessor initialization failed: %s", err)
@@ -114,6 +119,7 @@ func provide(c *dig.Container) error {
 
 	type serviceDeps struct {
 		dig.In
+		AppInfo         *app.Info
 		Host            host.Host
 		PeeringManager  *p2p.Manager
 		Storage         *storage.Storage
@@ -128,6 +134,8 @@ func provide(c *dig.Container) error {
 			deps.PeeringManager,
 			deps.ServerMetrics,
 			gossip.WithLogger(Component.App().NewLogger("GossipService")),
+			gossip.WithLegacyProtocols(protocol.ID(fmt.Sprintf(iotaGossipLegacyProtocolIDTemplate, deps.ProtocolManager.Current().NetworkID()))),
+			gossip.WithHandshakeInfo(deps.ProtocolManager.Current().NetworkID(), deps.AppInfo.Name, deps.AppInfo.Version),
 			gossip.WithUnknownPeersLimit(ParamsGossip.UnknownPeersLimit),
 			gossip.WithStreamReadTimeout(ParamsGossip.StreamReadTimeout),
 			gossip.WithStreamWriteTimeout(ParamsGossip.StreamWriteTimeout),
@@ -150,6 +158,8 @@ func provide(c *dig.Container) error {
 			deps.RequestQueue,
 			gossip.WithRequesterDiscardRequestsOlderThan(ParamsRequests.DiscardOlderThan),
 			gossip.WithRequesterPendingRequestReEnqueueInterval(ParamsRequests.PendingReEnqueueInterval),
+			gossip.WithRequesterMaxInFlightRequestsPerPeer(ParamsRequests.MaxInFlightPerPeer),
+			gossip.WithRequesterFanOutTimeout(ParamsRequests.FanOutTimeout),
 		)
 	}); err != nil {
 		Component.LogPanic(err)
@@ -242,6 +252,7 @@ func run() error {
 // checkHeartbeats sends a heartbeat to each peer and also checks
 // whether we received heartbeats from other peers. if a peer didn't send any
 // heartbeat for a defined period of time, then the connection to it is dropped.
+// it also lowers the reputation of peers with outdated heartbeats or unanswered requests.
 func checkHeartbeats() {
 	// send a new heartbeat message to every neighbor at least every heartbeatSentInterval
 	deps.Broadcaster.BroadcastHeartbeat(func(proto *gossip.Protocol) bool {
@@ -250,6 +261,8 @@ func checkHeartbeats() {
 
 	peersToRemove := make(map[peer.ID]error)
 	peersToReconnect := make(map[peer.ID]error)
+	peersStale := make(map[peer.ID]struct{})
+	peersUnansweredRequests := make(map[peer.ID]int)
 
 	snapshotInfo := deps.Storage.SnapshotInfo()
 	if snapshotInfo == nil {
@@ -266,6 +279,10 @@ func checkHeartbeats() {
 			return true
 		}
 
+		if unansweredRequests := proto.ExpireRequests(ParamsRequests.PeerTimeout); unansweredRequests > 0 {
+			peersUnansweredRequests[proto.PeerID] = unansweredRequests
+		}
+
 		latestHeatbeat := proto.LatestHeartbeat
 		if latestHeatbeat == nil && time.Since(protoStream.Stat().Opened) <= checkHeartbeatsInterval {
 			// use a grace period before the heartbeat check is applied
@@ -294,6 +311,11 @@ func checkHeartbeats() {
 		}
 
 		if errUnhealthy == nil {
+			if latestHeatbeat != nil && time.Since(proto.HeartbeatReceivedTime) > heartbeatStaleTimeout {
+				// heartbeat is not outdated yet, but the peer should have sent a new one already
+				peersStale[proto.PeerID] = struct{}{}
+			}
+
 			// peer is healthy
 			return true
 		}
@@ -309,6 +331,18 @@ func checkHeartbeats() {
 		return true
 	})
 
+	// lower the reputation of peers which didn't answer our requests.
+	// the reputation is only lowered once per check, so a burst of requests (e.g. warp sync)
+	// to an overloaded peer does not lead to a ban immediately.
+	for p, unansweredRequests := range peersUnansweredRequests {
+		deps.PeeringManager.ReportPeer(p, p2p.ReputationEventRequestUnanswered, fmt.Errorf("peer didn't answer %d requests", unansweredRequests))
+	}
+
+	// lower the reputation of peers with stale heartbeats
+	for p := range peersStale {
+		deps.PeeringManager.ReportPeer(p, p2p.ReputationEventHeartbeatStale, errors.New("heartbeat stale"))
+	}
+
 	// drop the connection to the peers
 	for p, reason := range peersToRemove {
 		_ = deps.PeeringManager.DisconnectPeer(p, reason)
@@ -12,6 +12,14 @@ type ParametersRequests struct {
 	DiscardOlderThan time.Duration `default:"15s" usage:"the maximum time a request stays in the request queue"`
 	// Defines the interval the pending requests are re-enqueued.
 	PendingReEnqueueInterval time.Duration `default:"5s" usage:"the interval the pending requests are re-enqueued"`
+	// Defines the time after which a request sent to a peer is considered unanswered.
+	PeerTimeout time.Duration `default:"10s" usage:"the time after which a request sent to a peer is considered unanswered"`
+	// Defines the time after which the answer of a peer to a request is considered slow.
+	SlowResponseThreshold time.Duration `default:"2s" usage:"the time after which the answer of a peer to a request is considered slow"`
+	// Defines the maximum amount of requests sent to a single peer which were not answered yet.
+	MaxInFlightPerPeer int `default:"500" usage:"the maximum amount of requests sent to a single peer which were not answered yet (0 = unlimited)"`
+	// Defines the minimum time after which a pending request is additionally sent to another peer.
+	FanOutTimeout time.Duration `default:"1s" usage:"the minimum time after which a pending request is additionally sent to another peer"`
 }
 
 // ParametersGossip contains the definition of the parameters used by gossip.
@@ -68,6 +68,42 @@ func hookGossipProtocolEvents(proto *gossip.Protocol) {
 			proto.Metrics.SentMilestoneRequests.Inc()
 			deps.ServerMetrics.SentMilestoneRequests.Inc()
 		}).Unhook,
+		proto.Parser.Events.Received[gossip.MessageTypeHandshake].Hook(func(data []byte) {
+			// peers with an invalid handshake are rejected
+			if err := deps.GossipService.ProcessHandshake(proto, data); err != nil {
+				closeConnectionDueToProtocolError(err)
+			}
+		}).Unhook,
+		proto.Events.Sent[gossip.MessageTypeHandshake].Hook(func() {
+			proto.Metrics.SentPackets.Inc()
+		}).Unhook,
+		proto.Parser.Events.Received[gossip.MessageTypeBlocks].Hook(func(data []byte) {
+			// the received blocks metrics are increased for every contained block during processing
+			deps.MessageProcessor.Process(proto, gossip.MessageTypeBlocks, data)
+		}).Unhook,
+		proto.Events.Sent[gossip.MessageTypeBlocks].Hook(func() {
+			proto.Metrics.SentPackets.Inc()
+		}).Unhook,
+		proto.Parser.Events.Received[gossip.MessageTypeBlockRequests].Hook(func(data []byte) {
+			proto.Metrics.ReceivedBlockRequests.Inc()
+			deps.ServerMetrics.ReceivedBlockRequests.Inc()
+			deps.MessageProcessor.Process(proto, gossip.MessageTypeBlockRequests, data)
+		}).Unhook,
+		proto.Events.Sent[gossip.MessageTypeBlockRequests].Hook(func() {
+			proto.Metrics.SentPackets.Inc()
+			proto.Metrics.SentBlockRequests.Inc()
+			deps.ServerMetrics.SentBlockRequests.Inc()
+		}).Unhook,
+		proto.Parser.Events.Received[gossip.MessageTypeMilestoneConeRequest].Hook(func(data []byte) {
+			proto.Metrics.ReceivedMilestoneRequests.Inc()
+			deps.ServerMetrics.ReceivedMilestoneRequests.Inc()
+			deps.MessageProcessor.Process(proto, gossip.MessageTypeMilestoneConeRequest, data)
+		}).Unhook,
+		proto.Events.Sent[gossip.MessageTypeMilestoneConeRequest].Hook(func() {
+			proto.Metrics.SentPackets.Inc()
+			proto.Metrics.SentMilestoneRequests.Inc()
+			deps.ServerMetrics.SentMilestoneRequests.Inc()
+		}).Unhook,
 		proto.Parser.Events.Received[gossip.MessageTypeHeartbeat].Hook(func(data []byte) {
 			proto.Metrics.ReceivedHeartbeats.Inc()
 			deps.ServerMetrics.ReceivedHeartbeats.Inc()
@@ -0,0 +1,67 @@
+package inx
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	inx "github.com/iotaledger/inx/go"
+)
+
+const (
+	// BlockValidationServiceName is the name of the gRPC service to validate blocks.
+	// The INX service is defined in the inx protocol, so the validation is served
+	// as a separate service on the INX server.
+	BlockValidationServiceName = "hornet.inx.BlockValidation"
+
+	// BlockValidationMethodValidateBlock is the full name of the method to validate a block.
+	// Request: inx.RawBlock, Response: inx.NoParams.
+	BlockValidationMethodValidateBlock = "/" + BlockValidationServiceName + "/ValidateBlock"
+
+	// BlockValidationErrorDomain is the domain of the error details of failed block validation checks.
+	BlockValidationErrorDomain = "hornet"
+)
+
+// BlockValidationServer is the server API for the block validation service.
+type BlockValidationServer interface {
+	// ValidateBlock runs all checks on the block that would be applied if it was attached, without attaching it.
+	// If any check fails, an "InvalidArgument" error with an ErrorInfo detail per failed check is returned.
+	ValidateBlock(ctx context.Context, rawBlock *inx.RawBlock) (*inx.NoParams, error)
+}
+
+func blockValidationValidateBlockHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(inx.RawBlock)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockValidationServer).ValidateBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlockValidationMethodValidateBlock,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockValidationServer).ValidateBlock(ctx, req.(*inx.RawBlock))
+	}
+
+	return interceptor(ctx, in, info, handler)
+}
+
+// blockValidationServiceDesc is the grpc.ServiceDesc of the block validation service.
+var blockValidationServiceDesc = grpc.ServiceDesc{
+	ServiceName: BlockValidationServiceName,
+	HandlerType: (*BlockValidationServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ValidateBlock",
+			Handler:    blockValidationValidateBlockHandler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// RegisterBlockValidationServer registers the block validation service on the given gRPC server.
+func RegisterBlockValidationServer(s grpc.ServiceRegistrar, srv BlockValidationServer) {
+	s.RegisterService(&blockValidationServiceDesc, srv)
+}
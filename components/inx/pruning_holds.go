@@ -0,0 +1,124 @@
+package inx
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	inx "github.com/iotaledger/inx/go"
+)
+
+// the messages are generated from pruning_holds.proto, INX_PROTO_PATH is the directory containing inx.proto.
+//go:generate protoc --proto_path=. --proto_path=$INX_PROTO_PATH --go_out=. --go_opt=paths=source_relative pruning_holds.proto
+
+const (
+	// PruningHoldsServiceName is the name of the gRPC service to manage the holds that prevent pruning of milestones.
+	// The INX service is defined in the inx protocol, so the pruning holds are served
+	// as a separate service on the INX server.
+	PruningHoldsServiceName = "hornet.inx.PruningHolds"
+
+	// PruningHoldsMethodAddPruningHold is the full name of the method to add or update a pruning hold.
+	// Request: AddPruningHoldRequest, Response: PruningHold.
+	PruningHoldsMethodAddPruningHold = "/" + PruningHoldsServiceName + "/AddPruningHold"
+	// PruningHoldsMethodRemovePruningHold is the full name of the method to release a pruning hold.
+	// Request: PruningHoldId, Response: inx.NoParams.
+	PruningHoldsMethodRemovePruningHold = "/" + PruningHoldsServiceName + "/RemovePruningHold"
+	// PruningHoldsMethodReadPruningHolds is the full name of the method to list the active pruning holds.
+	// Request: inx.NoParams, Response: PruningHoldsResponse.
+	PruningHoldsMethodReadPruningHolds = "/" + PruningHoldsServiceName + "/ReadPruningHolds"
+)
+
+// PruningHoldsServer is the server API for the pruning holds service.
+type PruningHoldsServer interface {
+	// AddPruningHold adds a hold that prevents pruning of the given milestone index and all younger milestones.
+	// An existing hold with the same ID is replaced.
+	AddPruningHold(ctx context.Context, req *AddPruningHoldRequest) (*PruningHold, error)
+	// RemovePruningHold releases the hold with the given ID.
+	RemovePruningHold(ctx context.Context, req *PruningHoldId) (*inx.NoParams, error)
+	// ReadPruningHolds returns all active holds sorted by their index.
+	ReadPruningHolds(ctx context.Context, req *inx.NoParams) (*PruningHoldsResponse, error)
+}
+
+func pruningHoldsAddPruningHoldHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddPruningHoldRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PruningHoldsServer).AddPruningHold(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PruningHoldsMethodAddPruningHold,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PruningHoldsServer).AddPruningHold(ctx, req.(*AddPruningHoldRequest))
+	}
+
+	return interceptor(ctx, in, info, handler)
+}
+
+func pruningHoldsRemovePruningHoldHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PruningHoldId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PruningHoldsServer).RemovePruningHold(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PruningHoldsMethodRemovePruningHold,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PruningHoldsServer).RemovePruningHold(ctx, req.(*PruningHoldId))
+	}
+
+	return interceptor(ctx, in, info, handler)
+}
+
+func pruningHoldsReadPruningHoldsHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(inx.NoParams)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PruningHoldsServer).ReadPruningHolds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PruningHoldsMethodReadPruningHolds,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PruningHoldsServer).ReadPruningHolds(ctx, req.(*inx.NoParams))
+	}
+
+	return interceptor(ctx, in, info, handler)
+}
+
+// pruningHoldsServiceDesc is the grpc.ServiceDesc of the pruning holds service.
+var pruningHoldsServiceDesc = grpc.ServiceDesc{
+	ServiceName: PruningHoldsServiceName,
+	HandlerType: (*PruningHoldsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddPruningHold",
+			Handler:    pruningHoldsAddPruningHoldHandler,
+		},
+		{
+			MethodName: "RemovePruningHold",
+			Handler:    pruningHoldsRemovePruningHoldHandler,
+		},
+		{
+			MethodName: "ReadPruningHolds",
+			Handler:    pruningHoldsReadPruningHoldsHandler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pruning_holds.proto",
+}
+
+// RegisterPruningHoldsServer registers the pruning holds service on the given gRPC server.
+func RegisterPruningHoldsServer(s grpc.ServiceRegistrar, srv PruningHoldsServer) {
+	s.RegisterService(&pruningHoldsServiceDesc, srv)
+}
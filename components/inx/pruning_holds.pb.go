@@ -0,0 +1,406 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        (unknown)
+// source: pruning_holds.proto
+
+package inx
+
+import (
+	_go "github.com/iotaledger/inx/go"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AddPruningHoldRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id         string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Index      uint32 `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	TtlSeconds uint32 `protobuf:"varint,3,opt,name=ttlSeconds,proto3" json:"ttlSeconds,omitempty"`
+}
+
+func (x *AddPruningHoldRequest) Reset() {
+	*x = AddPruningHoldRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pruning_holds_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddPruningHoldRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddPruningHoldRequest) ProtoMessage() {}
+
+func (x *AddPruningHoldRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pruning_holds_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddPruningHoldRequest.ProtoReflect.Descriptor instead.
+func (*AddPruningHoldRequest) Descriptor() ([]byte, []int) {
+	return file_pruning_holds_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AddPruningHoldRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AddPruningHoldRequest) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *AddPruningHoldRequest) GetTtlSeconds() uint32 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+type PruningHoldId struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *PruningHoldId) Reset() {
+	*x = PruningHoldId{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pruning_holds_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PruningHoldId) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PruningHoldId) ProtoMessage() {}
+
+func (x *PruningHoldId) ProtoReflect() protoreflect.Message {
+	mi := &file_pruning_holds_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PruningHoldId.ProtoReflect.Descriptor instead.
+func (*PruningHoldId) Descriptor() ([]byte, []int) {
+	return file_pruning_holds_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PruningHoldId) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type PruningHold struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id        string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Index     uint32 `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	CreatedAt int64  `protobuf:"varint,3,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	ExpiresAt int64  `protobuf:"varint,4,opt,name=expiresAt,proto3" json:"expiresAt,omitempty"`
+}
+
+func (x *PruningHold) Reset() {
+	*x = PruningHold{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pruning_holds_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PruningHold) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PruningHold) ProtoMessage() {}
+
+func (x *PruningHold) ProtoReflect() protoreflect.Message {
+	mi := &file_pruning_holds_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PruningHold.ProtoReflect.Descriptor instead.
+func (*PruningHold) Descriptor() ([]byte, []int) {
+	return file_pruning_holds_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PruningHold) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PruningHold) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *PruningHold) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *PruningHold) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+type PruningHoldsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Holds []*PruningHold `protobuf:"bytes,1,rep,name=holds,proto3" json:"holds,omitempty"`
+}
+
+func (x *PruningHoldsResponse) Reset() {
+	*x = PruningHoldsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pruning_holds_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PruningHoldsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PruningHoldsResponse) ProtoMessage() {}
+
+func (x *PruningHoldsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pruning_holds_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PruningHoldsResponse.ProtoReflect.Descriptor instead.
+func (*PruningHoldsResponse) Descriptor() ([]byte, []int) {
+	return file_pruning_holds_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PruningHoldsResponse) GetHolds() []*PruningHold {
+	if x != nil {
+		return x.Holds
+	}
+	return nil
+}
+
+var File_pruning_holds_proto protoreflect.FileDescriptor
+
+var file_pruning_holds_proto_rawDesc = []byte{
+	0x0a, 0x13, 0x70, 0x72, 0x75, 0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x68, 0x6f, 0x6c, 0x64, 0x73, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0a, 0x68, 0x6f, 0x72, 0x6e, 0x65, 0x74, 0x2e, 0x69, 0x6e,
+	0x78, 0x1a, 0x09, 0x69, 0x6e, 0x78, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x5d, 0x0a, 0x15,
+	0x41, 0x64, 0x64, 0x50, 0x72, 0x75, 0x6e, 0x69, 0x6e, 0x67, 0x48, 0x6f, 0x6c, 0x64, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1e, 0x0a, 0x0a, 0x74,
+	0x74, 0x6c, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x0a, 0x74, 0x74, 0x6c, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x22, 0x1f, 0x0a, 0x0d, 0x50,
+	0x72, 0x75, 0x6e, 0x69, 0x6e, 0x67, 0x48, 0x6f, 0x6c, 0x64, 0x49, 0x64, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x6f, 0x0a, 0x0b,
+	0x50, 0x72, 0x75, 0x6e, 0x69, 0x6e, 0x67, 0x48, 0x6f, 0x6c, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x69,
+	0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65,
+	0x78, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12,
+	0x1c, 0x0a, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x22, 0x45, 0x0a,
+	0x14, 0x50, 0x72, 0x75, 0x6e, 0x69, 0x6e, 0x67, 0x48, 0x6f, 0x6c, 0x64, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2d, 0x0a, 0x05, 0x68, 0x6f, 0x6c, 0x64, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x68, 0x6f, 0x72, 0x6e, 0x65, 0x74, 0x2e, 0x69, 0x6e,
+	0x78, 0x2e, 0x50, 0x72, 0x75, 0x6e, 0x69, 0x6e, 0x67, 0x48, 0x6f, 0x6c, 0x64, 0x52, 0x05, 0x68,
+	0x6f, 0x6c, 0x64, 0x73, 0x32, 0xe0, 0x01, 0x0a, 0x0c, 0x50, 0x72, 0x75, 0x6e, 0x69, 0x6e, 0x67,
+	0x48, 0x6f, 0x6c, 0x64, 0x73, 0x12, 0x4c, 0x0a, 0x0e, 0x41, 0x64, 0x64, 0x50, 0x72, 0x75, 0x6e,
+	0x69, 0x6e, 0x67, 0x48, 0x6f, 0x6c, 0x64, 0x12, 0x21, 0x2e, 0x68, 0x6f, 0x72, 0x6e, 0x65, 0x74,
+	0x2e, 0x69, 0x6e, 0x78, 0x2e, 0x41, 0x64, 0x64, 0x50, 0x72, 0x75, 0x6e, 0x69, 0x6e, 0x67, 0x48,
+	0x6f, 0x6c, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x68, 0x6f, 0x72,
+	0x6e, 0x65, 0x74, 0x2e, 0x69, 0x6e, 0x78, 0x2e, 0x50, 0x72, 0x75, 0x6e, 0x69, 0x6e, 0x67, 0x48,
+	0x6f, 0x6c, 0x64, 0x12, 0x3d, 0x0a, 0x11, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x50, 0x72, 0x75,
+	0x6e, 0x69, 0x6e, 0x67, 0x48, 0x6f, 0x6c, 0x64, 0x12, 0x19, 0x2e, 0x68, 0x6f, 0x72, 0x6e, 0x65,
+	0x74, 0x2e, 0x69, 0x6e, 0x78, 0x2e, 0x50, 0x72, 0x75, 0x6e, 0x69, 0x6e, 0x67, 0x48, 0x6f, 0x6c,
+	0x64, 0x49, 0x64, 0x1a, 0x0d, 0x2e, 0x69, 0x6e, 0x78, 0x2e, 0x4e, 0x6f, 0x50, 0x61, 0x72, 0x61,
+	0x6d, 0x73, 0x12, 0x43, 0x0a, 0x10, 0x52, 0x65, 0x61, 0x64, 0x50, 0x72, 0x75, 0x6e, 0x69, 0x6e,
+	0x67, 0x48, 0x6f, 0x6c, 0x64, 0x73, 0x12, 0x0d, 0x2e, 0x69, 0x6e, 0x78, 0x2e, 0x4e, 0x6f, 0x50,
+	0x61, 0x72, 0x61, 0x6d, 0x73, 0x1a, 0x20, 0x2e, 0x68, 0x6f, 0x72, 0x6e, 0x65, 0x74, 0x2e, 0x69,
+	0x6e, 0x78, 0x2e, 0x50, 0x72, 0x75, 0x6e, 0x69, 0x6e, 0x67, 0x48, 0x6f, 0x6c, 0x64, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x30, 0x5a, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x69, 0x6f, 0x74, 0x61, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x72,
+	0x2f, 0x68, 0x6f, 0x72, 0x6e, 0x65, 0x74, 0x2f, 0x76, 0x32, 0x2f, 0x63, 0x6f, 0x6d, 0x70, 0x6f,
+	0x6e, 0x65, 0x6e, 0x74, 0x73, 0x2f, 0x69, 0x6e, 0x78, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
+}
+
+var (
+	file_pruning_holds_proto_rawDescOnce sync.Once
+	file_pruning_holds_proto_rawDescData = file_pruning_holds_proto_rawDesc
+)
+
+func file_pruning_holds_proto_rawDescGZIP() []byte {
+	file_pruning_holds_proto_rawDescOnce.Do(func() {
+		file_pruning_holds_proto_rawDescData = protoimpl.X.CompressGZIP(file_pruning_holds_proto_rawDescData)
+	})
+	return file_pruning_holds_proto_rawDescData
+}
+
+var file_pruning_holds_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_pruning_holds_proto_goTypes = []interface{}{
+	(*AddPruningHoldRequest)(nil), // 0: hornet.inx.AddPruningHoldRequest
+	(*PruningHoldId)(nil),         // 1: hornet.inx.PruningHoldId
+	(*PruningHold)(nil),           // 2: hornet.inx.PruningHold
+	(*PruningHoldsResponse)(nil),  // 3: hornet.inx.PruningHoldsResponse
+	(*_go.NoParams)(nil),          // 4: inx.NoParams
+}
+var file_pruning_holds_proto_depIdxs = []int32{
+	2, // 0: hornet.inx.PruningHoldsResponse.holds:type_name -> hornet.inx.PruningHold
+	0, // 1: hornet.inx.PruningHolds.AddPruningHold:input_type -> hornet.inx.AddPruningHoldRequest
+	1, // 2: hornet.inx.PruningHolds.RemovePruningHold:input_type -> hornet.inx.PruningHoldId
+	4, // 3: hornet.inx.PruningHolds.ReadPruningHolds:input_type -> inx.NoParams
+	2, // 4: hornet.inx.PruningHolds.AddPruningHold:output_type -> hornet.inx.PruningHold
+	4, // 5: hornet.inx.PruningHolds.RemovePruningHold:output_type -> inx.NoParams
+	3, // 6: hornet.inx.PruningHolds.ReadPruningHolds:output_type -> hornet.inx.PruningHoldsResponse
+	4, // [4:7] is the sub-list for method output_type
+	1, // [1:4] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_pruning_holds_proto_init() }
+func file_pruning_holds_proto_init() {
+	if File_pruning_holds_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_pruning_holds_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AddPruningHoldRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pruning_holds_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PruningHoldId); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pruning_holds_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PruningHold); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pruning_holds_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PruningHoldsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_pruning_holds_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pruning_holds_proto_goTypes,
+		DependencyIndexes: file_pruning_holds_proto_depIdxs,
+		MessageInfos:      file_pruning_holds_proto_msgTypes,
+	}.Build()
+	File_pruning_holds_proto = out.File
+	file_pruning_holds_proto_rawDesc = nil
+	file_pruning_holds_proto_goTypes = nil
+	file_pruning_holds_proto_depIdxs = nil
+}
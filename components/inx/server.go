@@ -36,6 +36,8 @@ func newServer() *Server {
 
 	s := &Server{grpcServer: grpcServer}
 	inx.RegisterINXServer(grpcServer, s)
+	RegisterBlockValidationServer(grpcServer, s)
+	RegisterPruningHoldsServer(grpcServer, s)
 
 	return s
 }
@@ -2,10 +2,14 @@ package inx
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 
 	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
 
 	"github.com/iotaledger/hive.go/lo"
 	"github.com/iotaledger/hive.go/runtime/contextutils"
@@ -305,3 +309,50 @@ func (s *Server) SubmitBlock(ctx context.Context, rawBlock *inx.RawBlock) (*inx.
 
 	return inx.NewBlockId(blockID), nil
 }
+
+func (s *Server) ValidateBlock(ctx context.Context, rawBlock *inx.RawBlock) (*inx.NoParams, error) {
+	block, err := rawBlock.UnwrapBlock(serializer.DeSeriModeNoValidation, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	mergedCtx, mergedCtxCancel := contextutils.MergeContexts(ctx, Component.Daemon().ContextStopped())
+	defer mergedCtxCancel()
+
+	failures, err := attacher.ValidateBlock(mergedCtx, block)
+	if err != nil {
+		if errors.Is(err, tangle.ErrBlockAttacherAttachingNotPossible) {
+			return nil, status.Errorf(codes.Unavailable, "failed to validate block: %s", err.Error())
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to validate block: %s", err.Error())
+	}
+
+	if len(failures) == 0 {
+		return &inx.NoParams{}, nil
+	}
+
+	// every failed check is added as a detail to the error
+	details := make([]protoiface.MessageV1, 0, len(failures))
+	for _, failure := range failures {
+		metadata := map[string]string{
+			"error": failure.Err.Error(),
+		}
+		if failure.Conflict != storage.ConflictNone {
+			metadata["conflictReason"] = strconv.Itoa(int(failure.Conflict))
+		}
+
+		details = append(details, &errdetails.ErrorInfo{
+			Reason:   string(failure.Check),
+			Domain:   BlockValidationErrorDomain,
+			Metadata: metadata,
+		})
+	}
+
+	st, err := status.New(codes.InvalidArgument, fmt.Sprintf("block validation failed: %d failed checks", len(failures))).WithDetails(details...)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to validate block: %s", err.Error())
+	}
+
+	return nil, st.Err()
+}
@@ -359,35 +359,16 @@ func milestoneCone(index iotago.MilestoneIndex, parents iotago.BlockIDs, consume
 	memcachedTraverserStorage := dag.NewMemcachedTraverserStorage(deps.Storage, storage.NewMetadataMemcache(deps.Storage.CachedBlockMetadata))
 	defer memcachedTraverserStorage.Cleanup(true)
 
-	if err := dag.TraverseParents(
+	if err := dag.TraverseMilestoneCone(
 		Component.Daemon().ContextStopped(),
 		memcachedTraverserStorage,
+		index,
 		parents,
-		// traversal stops if no more blocks pass the given condition
-		// Caution: condition func is not in DFS order
-		func(cachedBlockMeta *storage.CachedMetadata) (bool, error) { // meta +1
-			defer cachedBlockMeta.Release(true) // meta -1
-			if referenced, at := cachedBlockMeta.Metadata().ReferencedWithIndex(); referenced {
-				if at < index {
-					return false, nil
-				}
-			}
-
-			return true, nil
-		},
-		// consumer
 		func(cachedBlockMeta *storage.CachedMetadata) error { // meta +1
-			defer cachedBlockMeta.Release(true)
+			defer cachedBlockMeta.Release(true) // meta -1
 
 			return consumer(cachedBlockMeta.Metadata())
-		},
-		// called on missing parents
-		// return error on missing parents
-		nil,
-		// called on solid entry points
-		// Ignore solid entry points (snapshot milestone included)
-		nil,
-		false); err != nil {
+		}); err != nil {
 		if errors.Is(err, common.ErrOperationAborted) {
 			return status.Errorf(codes.Unavailable, "traverse parents failed, error: %s", err)
 		}
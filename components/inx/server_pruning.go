@@ -0,0 +1,64 @@
+package inx
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/iotaledger/hornet/v2/pkg/pruning"
+	inx "github.com/iotaledger/inx/go"
+)
+
+func newPruningHold(hold *pruning.Hold) *PruningHold {
+	pruningHold := &PruningHold{
+		Id:        hold.ID,
+		Index:     hold.Index,
+		CreatedAt: hold.CreatedAt.Unix(),
+	}
+	if !hold.ExpiresAt.IsZero() {
+		pruningHold.ExpiresAt = hold.ExpiresAt.Unix()
+	}
+
+	return pruningHold
+}
+
+func (s *Server) AddPruningHold(_ context.Context, req *AddPruningHoldRequest) (*PruningHold, error) {
+	hold, err := deps.PruningManager.AddHold(req.GetId(), req.GetIndex(), time.Duration(req.GetTtlSeconds())*time.Second)
+	if err != nil {
+		if errors.Is(err, pruning.ErrHoldIDEmpty) || errors.Is(err, pruning.ErrHoldIndexAlreadyPruned) || errors.Is(err, pruning.ErrHoldIndexNotConfirmed) {
+			return nil, status.Errorf(codes.InvalidArgument, "adding pruning hold failed: %s", err.Error())
+		}
+
+		return nil, status.Errorf(codes.Internal, "adding pruning hold failed: %s", err.Error())
+	}
+
+	return newPruningHold(hold), nil
+}
+
+func (s *Server) RemovePruningHold(_ context.Context, req *PruningHoldId) (*inx.NoParams, error) {
+	if err := deps.PruningManager.RemoveHold(req.GetId()); err != nil {
+		if errors.Is(err, pruning.ErrHoldNotFound) {
+			return nil, status.Errorf(codes.NotFound, "pruning hold not found: %s", req.GetId())
+		}
+
+		return nil, status.Errorf(codes.Internal, "releasing pruning hold failed: %s", err.Error())
+	}
+
+	return &inx.NoParams{}, nil
+}
+
+func (s *Server) ReadPruningHolds(_ context.Context, _ *inx.NoParams) (*PruningHoldsResponse, error) {
+	holds := deps.PruningManager.Holds()
+
+	response := &PruningHoldsResponse{
+		Holds: make([]*PruningHold, len(holds)),
+	}
+	for i, hold := range holds {
+		response.Holds[i] = newPruningHold(hold)
+	}
+
+	return response, nil
+}
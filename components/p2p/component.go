@@ -12,12 +12,14 @@ import (
 	"github.com/libp2p/go-libp2p/p2p/net/connmgr"
 	"github.com/libp2p/go-libp2p/p2p/transport/tcp"
 	"github.com/multiformats/go-multiaddr"
+	"github.com/pkg/errors"
 	"go.uber.org/dig"
 
 	"github.com/iotaledger/hive.go/app"
 	"github.com/iotaledger/hive.go/app/configuration"
 	hivep2p "github.com/iotaledger/hive.go/crypto/p2p"
 	hivedb "github.com/iotaledger/hive.go/kvstore/database"
+	"github.com/iotaledger/hive.go/runtime/timeutil"
 	"github.com/iotaledger/hornet/v2/pkg/daemon"
 	"github.com/iotaledger/hornet/v2/pkg/p2p"
 )
@@ -34,6 +36,12 @@ func init() {
 	}
 }
 
+const (
+	// the interval in which the connected peers are checked against the ban list.
+	// this is needed to disconnect peers which were banned by editing the ban list file.
+	checkBannedPeersInterval = 1 * time.Minute
+)
+
 var (
 	Component *app.Component
 	deps      dependencies
@@ -46,6 +54,7 @@ type dependencies struct {
 	PeerStoreContainer   *p2p.PeerStoreContainer
 	PeeringConfig        *configuration.Configuration `name:"peeringConfig"`
 	PeeringConfigManager *p2p.ConfigManager
+	BanList              *p2p.BanList
 }
 
 func initConfigParams(c *dig.Container) error {
@@ -82,6 +91,7 @@ func provide(c *dig.Container) error {
 		PeerStoreContainer *p2p.PeerStoreContainer
 		NodePrivateKey     crypto.PrivKey `name:"nodePrivateKey"`
 		Host               host.Host
+		BanList            *p2p.BanList
 	}
 
 	if err := c.Provide(func(deps hostDeps) p2presult {
@@ -112,6 +122,12 @@ func provide(c *dig.Container) error {
 			Component.LogInfof(`loaded existing private key for peer identity from "%s"`, privKeyFilePath)
 		}
 
+		banList, err := p2p.NewBanList(deps.P2PDatabasePath)
+		if err != nil {
+			Component.LogPanicf("unable to load ban list: %s", err)
+		}
+		res.BanList = banList
+
 		connManager, err := connmgr.NewConnManager(
 			ParamsP2P.ConnectionManager.LowWatermark,
 			ParamsP2P.ConnectionManager.HighWatermark,
@@ -126,6 +142,7 @@ func provide(c *dig.Container) error {
 			libp2p.Peerstore(peerStoreContainer.Peerstore()),
 			libp2p.Transport(tcp.NewTCPTransport),
 			libp2p.ConnectionManager(connManager),
+			libp2p.ConnectionGater(banList),
 			libp2p.NATPortMap(),
 		)
 		if err != nil {
@@ -149,6 +166,22 @@ func provide(c *dig.Container) error {
 			return p2p.NewManager(deps.Host,
 				p2p.WithManagerLogger(Component.App().NewLogger("P2P-Manager")),
 				p2p.WithManagerReconnectInterval(ParamsP2P.ReconnectInterval, 1*time.Second),
+				p2p.WithManagerReputationOptions(
+					p2p.WithReputationWeights(map[p2p.ReputationEvent]float64{
+						p2p.ReputationEventInvalidData:       ParamsP2P.Reputation.Weights.InvalidData,
+						p2p.ReputationEventInvalidRequest:    ParamsP2P.Reputation.Weights.InvalidRequest,
+						p2p.ReputationEventDuplicateData:     ParamsP2P.Reputation.Weights.DuplicateData,
+						p2p.ReputationEventNewData:           ParamsP2P.Reputation.Weights.NewData,
+						p2p.ReputationEventRequestAnswered:   ParamsP2P.Reputation.Weights.RequestAnswered,
+						p2p.ReputationEventSlowResponse:      ParamsP2P.Reputation.Weights.SlowResponse,
+						p2p.ReputationEventRequestUnanswered: ParamsP2P.Reputation.Weights.RequestUnanswered,
+						p2p.ReputationEventHeartbeatStale:    ParamsP2P.Reputation.Weights.HeartbeatStale,
+					}),
+					p2p.WithReputationDecayHalfLife(ParamsP2P.Reputation.DecayHalfLife),
+					p2p.WithReputationMaxScore(ParamsP2P.Reputation.MaxScore),
+					p2p.WithReputationThresholds(ParamsP2P.Reputation.DisconnectThreshold, ParamsP2P.Reputation.BanThreshold),
+					p2p.WithReputationBanDuration(ParamsP2P.Reputation.BanDuration),
+				),
 			)
 		}
 
@@ -281,9 +314,40 @@ func run() error {
 		Component.LogPanicf("failed to start worker: %s", err)
 	}
 
+	if err := Component.Daemon().BackgroundWorker("BanList", func(ctx context.Context) {
+		unhook := deps.BanList.Events.Added.Hook(func(ban *p2p.Ban) {
+			Component.LogInfof("banned %s", ban.Target)
+			disconnectBannedPeers()
+		}).Unhook
+		defer unhook()
+
+		ticker := timeutil.NewTicker(disconnectBannedPeers, checkBannedPeersInterval, ctx)
+		ticker.WaitForGracefulShutdown()
+	}, daemon.PriorityP2PManager); err != nil {
+		Component.LogPanicf("failed to start worker: %s", err)
+	}
+
 	return nil
 }
 
+// disconnects all connected peers which are banned.
+// new connections of banned peers are already rejected by the connection gater.
+func disconnectBannedPeers() {
+	for _, conn := range deps.Host.Network().Conns() {
+		if !deps.BanList.IsConnBanned(conn) {
+			continue
+		}
+
+		peerID := conn.RemotePeer()
+		if err := deps.PeeringManager.DisconnectPeer(peerID, errors.New("peer is banned")); err != nil {
+			Component.LogWarnf("unable to disconnect banned peer %s: %s", peerID.ShortString(), err)
+		}
+
+		// the connection is not necessarily managed by the Manager
+		_ = conn.Close()
+	}
+}
+
 // connects to the peers defined in the config.
 func connectConfigKnownPeers() {
 	for _, p := range deps.PeeringConfigManager.Peers() {
@@ -33,6 +33,38 @@ type ParametersP2P struct {
 
 	// Defines the time to wait before trying to reconnect to a disconnected peer.
 	ReconnectInterval time.Duration `default:"30s" usage:"the time to wait before trying to reconnect to a disconnected peer"`
+
+	Reputation struct {
+		// Defines the time after which the reputation score of a peer is halved.
+		DecayHalfLife time.Duration `default:"30m" usage:"the time after which the reputation score of a peer is halved"`
+		// Defines the maximum reputation score a peer can reach.
+		MaxScore float64 `default:"100.0" usage:"the maximum reputation score a peer can reach"`
+		// Defines the reputation score at which a peer gets disconnected.
+		DisconnectThreshold float64 `default:"-50.0" usage:"the reputation score at which a peer gets disconnected"`
+		// Defines the reputation score at which a peer gets banned.
+		BanThreshold float64 `default:"-100.0" usage:"the reputation score at which a peer gets banned (known peers are only disconnected)"`
+		// Defines the duration a peer gets banned for.
+		BanDuration time.Duration `default:"1h" usage:"the duration a peer gets banned for"`
+
+		Weights struct {
+			// Defines the score change if a peer sends invalid data.
+			InvalidData float64 `default:"-25.0" usage:"the score change if a peer sends invalid data"`
+			// Defines the score change if a peer sends a malformed request.
+			InvalidRequest float64 `default:"-10.0" usage:"the score change if a peer sends a malformed request"`
+			// Defines the score change if a peer sends the same block multiple times without being asked for it.
+			DuplicateData float64 `default:"-0.5" usage:"the score change if a peer sends the same block multiple times without being asked for it"`
+			// Defines the score change if a peer is the first to send a new block.
+			NewData float64 `default:"0.1" usage:"the score change if a peer is the first to send a new block"`
+			// Defines the score change if a peer answers a request in time.
+			RequestAnswered float64 `default:"0.5" usage:"the score change if a peer answers a request in time"`
+			// Defines the score change if a peer answers a request too slowly.
+			SlowResponse float64 `default:"-1.0" usage:"the score change if a peer answers a request too slowly"`
+			// Defines the score change if a peer doesn't answer a request.
+			RequestUnanswered float64 `default:"-2.0" usage:"the score change if a peer doesn't answer a request"`
+			// Defines the score change if the latest heartbeat of a peer is outdated.
+			HeartbeatStale float64 `default:"-1.0" usage:"the score change if the latest heartbeat of a peer is outdated (applied on every check)"`
+		}
+	}
 }
 
 // ParametersPeers contains the definition of the parameters used by peers.
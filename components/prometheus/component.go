@@ -65,27 +65,28 @@ var (
 
 type dependencies struct {
 	dig.In
-	AppInfo          *app.Info
-	SyncManager      *syncmanager.SyncManager
-	ServerMetrics    *metrics.ServerMetrics
-	Storage          *storage.Storage
-	StorageMetrics   *metrics.StorageMetrics
-	TangleDatabase   *database.Database      `name:"tangleDatabase"`
-	UTXODatabase     *database.Database      `name:"utxoDatabase"`
-	RestAPIMetrics   *metrics.RestAPIMetrics `optional:"true"`
-	INXMetrics       *metrics.INXMetrics     `optional:"true"`
-	GossipService    *gossip.Service
-	ReceiptService   *migrator.ReceiptService `optional:"true"`
-	Tangle           *tangle.Tangle
-	PeeringManager   *p2p.Manager
-	RequestQueue     gossip.RequestQueue
-	MessageProcessor *gossip.MessageProcessor
-	TipSelector      *tipselect.TipSelector `optional:"true"`
-	SnapshotManager  *snapshot.Manager
-	PruningManager   *pruning.Manager
-	Echo             *echo.Echo  `optional:"true"`
-	PrometheusEcho   *echo.Echo  `name:"prometheusEcho"`
-	INXServer        *inx.Server `optional:"true"`
+	AppInfo                 *app.Info
+	SyncManager             *syncmanager.SyncManager
+	ServerMetrics           *metrics.ServerMetrics
+	Storage                 *storage.Storage
+	StorageMetrics          *metrics.StorageMetrics
+	TangleDatabase          *database.Database      `name:"tangleDatabase"`
+	UTXODatabase            *database.Database      `name:"utxoDatabase"`
+	RestAPIMetrics          *metrics.RestAPIMetrics `optional:"true"`
+	INXMetrics              *metrics.INXMetrics     `optional:"true"`
+	GossipService           *gossip.Service
+	ReceiptService          *migrator.ReceiptService `optional:"true"`
+	Tangle                  *tangle.Tangle
+	PeeringManager          *p2p.Manager
+	RequestQueue            gossip.RequestQueue
+	MessageProcessor        *gossip.MessageProcessor
+	TipSelector             *tipselect.TipSelector `optional:"true"`
+	SnapshotManager         *snapshot.Manager
+	SnapshotDownloadMetrics *metrics.SnapshotDownloadMetrics `optional:"true"`
+	PruningManager          *pruning.Manager
+	Echo                    *echo.Echo  `optional:"true"`
+	PrometheusEcho          *echo.Echo  `name:"prometheusEcho"`
+	INXServer               *inx.Server `optional:"true"`
 }
 
 func provide(c *dig.Container) error {
@@ -141,6 +142,9 @@ func configure() error {
 			configureReceipts()
 		}
 	}
+	if ParamsPrometheus.SnapshotMetrics && deps.SnapshotDownloadMetrics != nil {
+		configureSnapshotDownload()
+	}
 	if ParamsPrometheus.DebugMetrics {
 		configureDebug()
 	}
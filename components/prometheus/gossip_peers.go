@@ -5,11 +5,13 @@ import (
 )
 
 var (
-	gossipPeersBlocks         *prometheus.GaugeVec
-	gossipPeersRequests       *prometheus.GaugeVec
-	gossipPeersHeartbeats     *prometheus.GaugeVec
-	gossipPeersDroppedPackets *prometheus.GaugeVec
-	gossipPeersConnected      *prometheus.GaugeVec
+	gossipPeersBlocks           *prometheus.GaugeVec
+	gossipPeersRequests         *prometheus.GaugeVec
+	gossipPeersHeartbeats       *prometheus.GaugeVec
+	gossipPeersDroppedPackets   *prometheus.GaugeVec
+	gossipPeersConnected        *prometheus.GaugeVec
+	gossipPeersReputation       *prometheus.GaugeVec
+	gossipPeersReputationEvents *prometheus.GaugeVec
 )
 
 func configureGossipPeers() {
@@ -64,11 +66,33 @@ func configureGossipPeers() {
 		[]string{"address", "alias", "id"},
 	)
 
+	gossipPeersReputation = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "iota",
+			Subsystem: "gossip_peers",
+			Name:      "reputation",
+			Help:      "Reputation score by peer.",
+		},
+		[]string{"address", "alias", "id"},
+	)
+
+	gossipPeersReputationEvents = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "iota",
+			Subsystem: "gossip_peers",
+			Name:      "reputation_events",
+			Help:      "Number of reputation events by peer.",
+		},
+		[]string{"address", "alias", "id", "type"},
+	)
+
 	registry.MustRegister(gossipPeersBlocks)
 	registry.MustRegister(gossipPeersRequests)
 	registry.MustRegister(gossipPeersHeartbeats)
 	registry.MustRegister(gossipPeersDroppedPackets)
 	registry.MustRegister(gossipPeersConnected)
+	registry.MustRegister(gossipPeersReputation)
+	registry.MustRegister(gossipPeersReputationEvents)
 
 	addCollect(collectGossipPeers)
 }
@@ -79,6 +103,8 @@ func collectGossipPeers() {
 	gossipPeersHeartbeats.Reset()
 	gossipPeersDroppedPackets.Reset()
 	gossipPeersConnected.Reset()
+	gossipPeersReputation.Reset()
+	gossipPeersReputationEvents.Reset()
 
 	for _, peer := range deps.PeeringManager.PeerInfoSnapshots() {
 
@@ -121,5 +147,12 @@ func collectGossipPeers() {
 		if peer.Connected {
 			gossipPeersConnected.With(peerLabels).Set(1)
 		}
+
+		if peer.Reputation != nil {
+			gossipPeersReputation.With(peerLabels).Set(peer.Reputation.Score)
+			for reputationEvent, count := range peer.Reputation.Events {
+				gossipPeersReputationEvents.With(getLabels(string(reputationEvent))).Set(float64(count))
+			}
+		}
 	}
 }
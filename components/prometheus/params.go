@@ -34,6 +34,8 @@ type ParametersPrometheus struct {
 	INXMetrics bool `name:"inxMetrics" default:"true" usage:"whether to include INX metrics"`
 	// MigrationMetrics defines whether to include migration metrics.
 	MigrationMetrics bool `default:"true" usage:"whether to include migration metrics"`
+	// SnapshotMetrics defines whether to include snapshot download metrics.
+	SnapshotMetrics bool `default:"true" usage:"whether to include snapshot download metrics"`
 	// DebugMetrics defines whether to include debug metrics.
 	DebugMetrics bool `default:"false" usage:"whether to include debug metrics"`
 	// GoMetrics defines whether to include go metrics.
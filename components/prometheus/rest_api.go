@@ -13,6 +13,12 @@ var (
 	restapiPoWCompletedCount prometheus.Gauge
 	restapiPoWBlockSizes     prometheus.Histogram
 	restapiPoWDurations      prometheus.Histogram
+
+	restapiEventStreamSubscribers   prometheus.Gauge
+	restapiEventStreamDroppedEvents prometheus.Gauge
+
+	restapiRateLimitedRequests          prometheus.Gauge
+	restapiRateLimitedExpensiveRequests prometheus.Gauge
 )
 
 func configureRestAPI() {
@@ -52,12 +58,54 @@ func configureRestAPI() {
 			Buckets:   powDurationBuckets,
 		})
 
+	restapiEventStreamSubscribers = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "iota",
+			Subsystem: "restapi",
+			Name:      "event_stream_subscribers",
+			Help:      "The current amount of event stream subscribers.",
+		},
+	)
+
+	restapiEventStreamDroppedEvents = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "iota",
+			Subsystem: "restapi",
+			Name:      "event_stream_dropped_events",
+			Help:      "The amount of events that were dropped for slow event stream subscribers.",
+		},
+	)
+
+	restapiRateLimitedRequests = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "iota",
+			Subsystem: "restapi",
+			Name:      "rate_limited_requests",
+			Help:      "The amount of requests that were rejected by the rate limiter.",
+		},
+	)
+
+	restapiRateLimitedExpensiveRequests = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "iota",
+			Subsystem: "restapi",
+			Name:      "rate_limited_expensive_requests",
+			Help:      "The amount of requests to expensive routes that were rejected by the rate limiter.",
+		},
+	)
+
 	registry.MustRegister(restapiHTTPErrorCount)
 
 	registry.MustRegister(restapiPoWCompletedCount)
 	registry.MustRegister(restapiPoWBlockSizes)
 	registry.MustRegister(restapiPoWDurations)
 
+	registry.MustRegister(restapiEventStreamSubscribers)
+	registry.MustRegister(restapiEventStreamDroppedEvents)
+
+	registry.MustRegister(restapiRateLimitedRequests)
+	registry.MustRegister(restapiRateLimitedExpensiveRequests)
+
 	deps.RestAPIMetrics.Events.PoWCompleted.Hook(func(blockSize int, duration time.Duration) {
 		restapiPoWBlockSizes.Observe(float64(blockSize))
 		restapiPoWDurations.Observe(duration.Seconds())
@@ -77,4 +125,8 @@ func configureRestAPI() {
 func collectRestAPI() {
 	restapiHTTPErrorCount.Set(float64(deps.RestAPIMetrics.HTTPRequestErrorCounter.Load()))
 	restapiPoWCompletedCount.Set(float64(deps.RestAPIMetrics.PoWCompletedCounter.Load()))
+	restapiEventStreamSubscribers.Set(float64(deps.RestAPIMetrics.EventStreamSubscribers.Load()))
+	restapiEventStreamDroppedEvents.Set(float64(deps.RestAPIMetrics.EventStreamDroppedCounter.Load()))
+	restapiRateLimitedRequests.Set(float64(deps.RestAPIMetrics.RateLimitedCounter.Load()))
+	restapiRateLimitedExpensiveRequests.Set(float64(deps.RestAPIMetrics.RateLimitedExpensiveCounter.Load()))
 }
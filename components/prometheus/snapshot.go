@@ -0,0 +1,75 @@
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	snapshotDownloadRunning prometheus.Gauge
+	snapshotDownloadBytes   *prometheus.GaugeVec
+)
+
+func configureSnapshotDownload() {
+	snapshotDownloadRunning = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "iota",
+			Subsystem: "snapshot_download",
+			Name:      "running",
+			Help:      "Current state of the snapshot file download.",
+		},
+	)
+
+	snapshotDownloadBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "iota",
+			Subsystem: "snapshot_download",
+			Name:      "bytes",
+			Help:      "Progress of the snapshot file download in bytes.",
+		},
+		[]string{"type"},
+	)
+
+	// the cumulative values are tracked in the download metrics, so they are exposed as counter funcs.
+	snapshotDownloadBytesResumed := prometheus.NewCounterFunc(
+		prometheus.CounterOpts{
+			Namespace: "iota",
+			Subsystem: "snapshot_download",
+			Name:      "resumed_bytes_total",
+			Help:      "Total number of bytes that were reused from partially downloaded snapshot files.",
+		},
+		func() float64 { return float64(deps.SnapshotDownloadMetrics.BytesResumed.Load()) },
+	)
+
+	newSnapshotDownloadEventsCounter := func(eventType string, value func() uint32) prometheus.CounterFunc {
+		return prometheus.NewCounterFunc(
+			prometheus.CounterOpts{
+				Namespace:   "iota",
+				Subsystem:   "snapshot_download",
+				Name:        "events_total",
+				Help:        "Total number of snapshot file download events.",
+				ConstLabels: prometheus.Labels{"type": eventType},
+			},
+			func() float64 { return float64(value()) },
+		)
+	}
+
+	registry.MustRegister(snapshotDownloadRunning)
+	registry.MustRegister(snapshotDownloadBytes)
+	registry.MustRegister(snapshotDownloadBytesResumed)
+	registry.MustRegister(newSnapshotDownloadEventsCounter("files_downloaded", deps.SnapshotDownloadMetrics.FilesDownloaded.Load))
+	registry.MustRegister(newSnapshotDownloadEventsCounter("retries", deps.SnapshotDownloadMetrics.Retries.Load))
+	registry.MustRegister(newSnapshotDownloadEventsCounter("failovers", deps.SnapshotDownloadMetrics.Failovers.Load))
+	registry.MustRegister(newSnapshotDownloadEventsCounter("verification_failures", deps.SnapshotDownloadMetrics.VerificationFailures.Load))
+
+	addCollect(collectSnapshotDownload)
+}
+
+func collectSnapshotDownload() {
+	snapshotDownloadRunning.Set(0)
+	if deps.SnapshotDownloadMetrics.DownloadRunning.Load() {
+		snapshotDownloadRunning.Set(1)
+	}
+
+	snapshotDownloadBytes.WithLabelValues("expected").Set(float64(deps.SnapshotDownloadMetrics.BytesExpected.Load()))
+	snapshotDownloadBytes.WithLabelValues("downloaded").Set(float64(deps.SnapshotDownloadMetrics.BytesDownloaded.Load()))
+}
@@ -7,6 +7,8 @@ import (
 	"go.uber.org/dig"
 
 	"github.com/iotaledger/hive.go/app"
+	hivedb "github.com/iotaledger/hive.go/kvstore/database"
+	"github.com/iotaledger/hornet/v2/pkg/archive"
 	"github.com/iotaledger/hornet/v2/pkg/components"
 	"github.com/iotaledger/hornet/v2/pkg/daemon"
 	"github.com/iotaledger/hornet/v2/pkg/database"
@@ -38,6 +40,7 @@ type dependencies struct {
 	dig.In
 	SnapshotManager *snapshot.Manager
 	PruningManager  *pruning.Manager
+	Archive         *archive.Archive `optional:"true"`
 }
 
 func initConfigParams(c *dig.Container) error {
@@ -56,6 +59,29 @@ func initConfigParams(c *dig.Container) error {
 
 func provide(c *dig.Container) error {
 
+	if ParamsPruning.Archive.Enabled {
+		if err := c.Provide(func() *archive.Archive {
+			dbEngine, err := hivedb.EngineFromStringAllowed(ParamsPruning.Archive.Engine, database.AllowedEnginesStorage)
+			if err != nil {
+				Component.LogPanic(err)
+			}
+
+			archiveDatabase, err := database.DatabaseWithDefaultSettings(ParamsPruning.Archive.Path, true, dbEngine, database.AllowedEnginesStorage...)
+			if err != nil {
+				Component.LogPanicf("archive database initialization failed: %s", err)
+			}
+
+			archiveStore, err := archive.New(archiveDatabase)
+			if err != nil {
+				Component.LogPanicf("archive initialization failed: %s", err)
+			}
+
+			return archiveStore
+		}); err != nil {
+			Component.LogPanic(err)
+		}
+	}
+
 	type pruningManagerDeps struct {
 		dig.In
 		Storage              *storage.Storage
@@ -63,7 +89,8 @@ func provide(c *dig.Container) error {
 		TangleDatabase       *database.Database `name:"tangleDatabase"`
 		UTXODatabase         *database.Database `name:"utxoDatabase"`
 		SnapshotManager      *snapshot.Manager
-		PruningPruneReceipts bool `name:"pruneReceipts"`
+		Archive              *archive.Archive `optional:"true"`
+		PruningPruneReceipts bool             `name:"pruneReceipts"`
 	}
 
 	return c.Provide(func(deps pruningManagerDeps) *pruning.Manager {
@@ -85,12 +112,18 @@ func provide(c *dig.Container) error {
 			Component.LogPanicf("%s has to be specified if %s is enabled", Component.App().Config().GetParameterPath(&(ParamsPruning.Size.TargetSize)), Component.App().Config().GetParameterPath(&(ParamsPruning.Size.Enabled)))
 		}
 
+		pruningTimeEnabled := ParamsPruning.Time.Enabled
+		if pruningTimeEnabled && ParamsPruning.Time.RetentionPeriod <= 0 {
+			Component.LogPanicf("%s has to be specified if %s is enabled", Component.App().Config().GetParameterPath(&(ParamsPruning.Time.RetentionPeriod)), Component.App().Config().GetParameterPath(&(ParamsPruning.Time.Enabled)))
+		}
+
 		return pruning.NewPruningManager(
 			Component.Logger(),
 			deps.Storage,
 			deps.SyncManager,
 			deps.TangleDatabase,
 			deps.UTXODatabase,
+			deps.Archive,
 			deps.SnapshotManager.MinimumMilestoneIndex,
 			pruningMilestonesEnabled,
 			pruningMilestonesMaxMilestonesToKeep,
@@ -98,6 +131,8 @@ func provide(c *dig.Container) error {
 			pruningTargetDatabaseSizeBytes,
 			ParamsPruning.Size.ThresholdPercentage,
 			ParamsPruning.Size.CooldownTime,
+			pruningTimeEnabled,
+			ParamsPruning.Time.RetentionPeriod,
 			deps.PruningPruneReceipts,
 		)
 	})
@@ -114,6 +149,11 @@ func run() error {
 		<-ctx.Done()
 
 		Component.LogInfo("Stopping pruning background worker ...")
+		if deps.Archive != nil {
+			if err := deps.Archive.Shutdown(); err != nil {
+				Component.LogWarnf("failed to shut down the archive: %s", err)
+			}
+		}
 		Component.LogInfo("Stopping pruning background worker ... done")
 	}, daemon.PriorityPruning); err != nil {
 		Component.LogPanicf("failed to start worker: %s", err)
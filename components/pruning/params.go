@@ -24,6 +24,20 @@ type ParametersPruning struct {
 		// CooldownTime defines the cooldown time between two pruning by database size events
 		CooldownTime time.Duration `default:"5m" usage:"cooldown time between two pruning by database size events"`
 	}
+	Time struct {
+		// Enabled defines whether to delete old block data from the database based on the age of the milestones
+		Enabled bool `default:"false" usage:"whether to delete old block data from the database based on the age of the milestones"`
+		// RetentionPeriod defines the period of time the milestone cones are kept in the database
+		RetentionPeriod time.Duration `default:"720h" usage:"the period of time the milestone cones are kept in the database"`
+	}
+	Archive struct {
+		// Enabled defines whether to move pruned milestone cones to the archive instead of deleting them
+		Enabled bool `default:"false" usage:"whether to move pruned milestone cones to the archive instead of deleting them"`
+		// Engine defines the used archive database engine (pebble/rocksdb)
+		Engine string `default:"pebble" usage:"the used archive database engine (pebble/rocksdb)"`
+		// Path defines the path to the archive database folder
+		Path string `default:"mainnet/archive" usage:"the path to the archive database folder"`
+	}
 
 	// PruneReceipts defines whether to delete old receipts data from the database
 	PruneReceipts bool `default:"false" usage:"whether to delete old receipts data from the database"`
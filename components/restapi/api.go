@@ -1,6 +1,7 @@
 package restapi
 
 import (
+	"net/http"
 	"regexp"
 	"strings"
 
@@ -36,6 +37,53 @@ func compileRoutesAsRegexes(routes []string) []*regexp.Regexp {
 	return regexes
 }
 
+// scopeForRoute returns the scope a JWT needs to grant to access the given route.
+// Routes are mapped to "<action>:<resource>", with the resource being the API group
+// (e.g. "/api/indexer/v1/..." => "indexer") and the action depending on the HTTP method.
+// Sensitive core routes are mapped to dedicated scopes.
+func scopeForRoute(method string, path string) string {
+	path = strings.ToLower(path)
+
+	switch {
+	case strings.HasPrefix(path, "/api/core/v2/control/"):
+		return jwt.ScopeAdminControl
+	case strings.HasPrefix(path, "/api/core/v2/peers"):
+		return jwt.ScopeAdminPeers
+	case path == "/api/core/v2/blocks" && method == http.MethodPost:
+		return jwt.ScopeWriteBlocks
+	case path == "/api/core/v2/blocks/validate":
+		// validating blocks does not modify anything
+		return jwt.ScopeReadCore
+	}
+
+	resource := "node"
+	if strings.HasPrefix(path, "/api/") {
+		if group, _, _ := strings.Cut(strings.TrimPrefix(path, "/api/"), "/"); group != "" && group != "routes" {
+			resource = group
+		}
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return jwt.ScopeActionRead + ":" + resource
+	default:
+		return jwt.ScopeActionWrite + ":" + resource
+	}
+}
+
+// allowClaims checks if the claims are valid for this node, not revoked and grant the required scope.
+func allowClaims(subject string, claims *jwt.AuthClaims, scope string) bool {
+	if !claims.VerifySubject(subject) {
+		return false
+	}
+
+	if jwtRevocationList.IsRevoked(claims.Id) {
+		return false
+	}
+
+	return claims.HasScope(scope)
+}
+
 func apiMiddleware() echo.MiddlewareFunc {
 
 	publicRoutesRegEx := compileRoutesAsRegexes(ParamsRestAPI.PublicRoutes)
@@ -82,10 +130,15 @@ func apiMiddleware() echo.MiddlewareFunc {
 		Component.LogPanicf("JWT auth initialization failed: %w", err)
 	}
 
+	jwtRevocationList, err = jwt.NewRevocationList(deps.P2PDatabasePath)
+	if err != nil {
+		Component.LogPanicf("JWT revocation list initialization failed: %w", err)
+	}
+
 	jwtAllow := func(c echo.Context, subject string, claims *jwt.AuthClaims) bool {
-		// Allow all JWT created for the API if the endpoints are exposed
+		// Allow JWT created for the API if the endpoints are exposed and the token grants the scope of the route
 		if matchExposed(c) {
-			return claims.VerifySubject(subject)
+			return allowClaims(subject, claims, scopeForRoute(c.Request().Method, c.Request().URL.Path))
 		}
 
 		return false
@@ -18,6 +18,7 @@ import (
 	"github.com/iotaledger/hornet/v2/pkg/daemon"
 	"github.com/iotaledger/hornet/v2/pkg/jwt"
 	"github.com/iotaledger/hornet/v2/pkg/metrics"
+	"github.com/iotaledger/hornet/v2/pkg/protocol"
 	"github.com/iotaledger/hornet/v2/pkg/tangle"
 	"github.com/iotaledger/inx-app/pkg/httpserver"
 )
@@ -42,15 +43,19 @@ var (
 	Component *app.Component
 	deps      dependencies
 	jwtAuth   *jwt.Auth
+	// jwtRevocationList contains the IDs of revoked JWTs.
+	jwtRevocationList *jwt.RevocationList
 )
 
 type dependencies struct {
 	dig.In
-	Tangle             *tangle.Tangle `optional:"true"`
+	Tangle             *tangle.Tangle    `optional:"true"`
+	ProtocolManager    *protocol.Manager `optional:"true"`
 	Echo               *echo.Echo
 	RestAPIMetrics     *metrics.RestAPIMetrics
 	Host               host.Host
 	RestAPIBindAddress string         `name:"restAPIBindAddress"`
+	P2PDatabasePath    string         `name:"p2pDatabasePath"`
 	NodePrivateKey     crypto.PrivKey `name:"nodePrivateKey"`
 	RestRouteManager   *RestRouteManager
 }
@@ -122,9 +127,20 @@ func provide(c *dig.Container) error {
 }
 
 func configure() error {
+	if ParamsRestAPI.RateLimit.Enabled {
+		rateLimit, err := rateLimitMiddleware()
+		if err != nil {
+			return err
+		}
+		deps.Echo.Use(rateLimit)
+	}
 	deps.Echo.Use(apiMiddleware())
 	setupRoutes()
 
+	if eventsEnabled() {
+		configureEvents()
+	}
+
 	return nil
 }
 
@@ -160,5 +176,13 @@ func run() error {
 		Component.LogPanicf("failed to start worker: %s", err)
 	}
 
+	if ParamsRestAPI.RateLimit.Enabled {
+		runRateLimiterCleanup()
+	}
+
+	if eventsEnabled() {
+		runEvents()
+	}
+
 	return nil
 }
@@ -0,0 +1,606 @@
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/lo"
+	"github.com/iotaledger/hive.go/runtime/event"
+	"github.com/iotaledger/hive.go/runtime/workerpool"
+	"github.com/iotaledger/hornet/v2/pkg/daemon"
+	"github.com/iotaledger/hornet/v2/pkg/jwt"
+	"github.com/iotaledger/hornet/v2/pkg/model/storage"
+	"github.com/iotaledger/hornet/v2/pkg/model/utxo"
+	restapipkg "github.com/iotaledger/hornet/v2/pkg/restapi"
+	"github.com/iotaledger/inx-app/pkg/httpserver"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+const (
+	// RouteEvents is the route to subscribe to the event stream.
+	// GET opens a WebSocket connection if an upgrade is requested, otherwise a Server-Sent Events stream.
+	// Query parameters: "topics" (comma separated) and "token" (JWT for protected topics).
+	RouteEvents = "events/v1"
+
+	// QueryParameterTopics is used to define the topics to subscribe to.
+	QueryParameterTopics = "topics"
+	// QueryParameterToken is used to pass a JWT for protected topics,
+	// since browsers can't set headers for SSE and WebSocket connections.
+	QueryParameterToken = "token"
+
+	// TopicMilestoneInfoLatest is the topic for the info of the latest milestone.
+	TopicMilestoneInfoLatest = "milestone-info/latest"
+	// TopicMilestoneInfoConfirmed is the topic for the info of the confirmed milestone.
+	TopicMilestoneInfoConfirmed = "milestone-info/confirmed"
+	// TopicMilestonesLatest is the topic for the payload of the latest milestone.
+	TopicMilestonesLatest = "milestones/latest"
+	// TopicMilestonesConfirmed is the topic for the payload of the confirmed milestone.
+	TopicMilestonesConfirmed = "milestones/confirmed"
+	// TopicBlockMetadataSolid is the topic for the metadata of all solid blocks.
+	TopicBlockMetadataSolid = "block-metadata/solid"
+	// TopicBlockMetadataReferenced is the topic for the metadata of all referenced blocks.
+	TopicBlockMetadataReferenced = "block-metadata/referenced"
+	// TopicBlockMetadata is the topic for the metadata changes of a single block.
+	TopicBlockMetadata = "block-metadata/{blockId}"
+	// TopicLedgerUpdates is the topic for the ledger changes of every confirmed milestone.
+	TopicLedgerUpdates = "ledger-updates"
+	// TopicOutputs is the topic for the ledger changes of a single output.
+	TopicOutputs = "outputs/{outputId}"
+	// TopicOutputsAddress is the topic for the ledger changes of outputs that can be unlocked by an address.
+	TopicOutputsAddress = "outputs/address/{bech32}"
+
+	eventsWorkerCount = 1
+	// the interval in which keep alive messages are sent to the clients.
+	eventsKeepAliveInterval = 30 * time.Second
+	// the time allowed to write a message to a WebSocket client.
+	eventsWriteTimeout = 10 * time.Second
+)
+
+var (
+	eventBroker *restapipkg.EventBroker
+
+	eventsUpgrader = websocket.Upgrader{
+		// the allowed origins are handled by the CORS middleware
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
+)
+
+// eventBlockMetadata is the payload of the block metadata topics.
+type eventBlockMetadata struct {
+	// The hex encoded block ID of the block.
+	BlockID string `json:"blockId"`
+	// The hex encoded block IDs of the parents the block references.
+	Parents []string `json:"parents"`
+	// Whether the block is solid.
+	Solid bool `json:"isSolid"`
+	// The milestone index that references this block.
+	ReferencedByMilestoneIndex iotago.MilestoneIndex `json:"referencedByMilestoneIndex,omitempty"`
+	// The ledger inclusion state of the transaction payload.
+	LedgerInclusionState string `json:"ledgerInclusionState,omitempty"`
+	// The reason why this block is marked as conflicting.
+	ConflictReason *storage.Conflict `json:"conflictReason,omitempty"`
+	// If this block is referenced by a milestone this returns the index of that block inside the milestone by whiteflag ordering.
+	WhiteFlagIndex *uint32 `json:"whiteFlagIndex,omitempty"`
+}
+
+// eventMilestoneInfo is the payload of the milestone info topics.
+type eventMilestoneInfo struct {
+	// The index of the milestone.
+	Index iotago.MilestoneIndex `json:"index"`
+	// The unix time of the milestone payload.
+	Timestamp uint32 `json:"timestamp"`
+	// The hex encoded ID of the milestone.
+	MilestoneID string `json:"milestoneId"`
+}
+
+// eventLedgerUpdate is the payload of the ledger updates topic.
+type eventLedgerUpdate struct {
+	// The index of the milestone.
+	Index iotago.MilestoneIndex `json:"index"`
+	// The output IDs of the newly created outputs.
+	CreatedOutputs []string `json:"createdOutputs"`
+	// The output IDs of the consumed (spent) outputs.
+	ConsumedOutputs []string `json:"consumedOutputs"`
+}
+
+// eventOutput is the payload of the output topics.
+type eventOutput struct {
+	// The hex encoded output ID.
+	OutputID string `json:"outputId"`
+	// The hex encoded block ID of the block that created the output.
+	BlockID string `json:"blockId"`
+	// The milestone index at which this output was booked into the ledger.
+	MilestoneIndexBooked iotago.MilestoneIndex `json:"milestoneIndexBooked"`
+	// Whether this output is spent.
+	Spent bool `json:"isSpent"`
+	// The milestone index at which this output was spent.
+	MilestoneIndexSpent iotago.MilestoneIndex `json:"milestoneIndexSpent,omitempty"`
+	// The hex encoded transaction ID the output was spent with.
+	TransactionIDSpent string `json:"transactionIdSpent,omitempty"`
+	// The output in its serialized form.
+	RawOutput *json.RawMessage `json:"output"`
+}
+
+// eventWebSocketMessage is the message sent to WebSocket clients.
+type eventWebSocketMessage struct {
+	// The topic of the event.
+	Topic string `json:"topic"`
+	// The payload of the event.
+	Payload *json.RawMessage `json:"payload,omitempty"`
+	// The error message, if a request of the client failed.
+	Error string `json:"error,omitempty"`
+}
+
+// eventWebSocketRequest is the message sent by WebSocket clients to change their subscriptions.
+type eventWebSocketRequest struct {
+	// The type of the request ("subscribe" or "unsubscribe").
+	Type string `json:"type"`
+	// The topics of the request.
+	Topics []string `json:"topics"`
+	// The JWT used for protected topics.
+	Token string `json:"token,omitempty"`
+}
+
+func eventsEnabled() bool {
+	return ParamsRestAPI.Events.Enabled && deps.Tangle != nil && deps.ProtocolManager != nil
+}
+
+func configureEvents() {
+	eventBroker = restapipkg.NewEventBroker(func() {
+		deps.RestAPIMetrics.EventStreamDroppedCounter.Inc()
+	})
+
+	protectedTopicsRegEx := compileRoutesAsRegexes(ParamsRestAPI.Events.ProtectedTopics)
+
+	isProtectedTopic := func(topic string) bool {
+		for _, reg := range protectedTopicsRegEx {
+			if reg.MatchString(topic) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	authorized := func(token string) bool {
+		if token == "" {
+			return false
+		}
+
+		return jwtAuth.VerifyJWT(token, func(claims *jwt.AuthClaims) bool {
+			return allowClaims(ParamsRestAPI.JWTAuth.Salt, claims, scopeForRoute(http.MethodGet, "/api/"+RouteEvents))
+		})
+	}
+
+	// checkTopics validates the topics and checks the authorization for protected topics.
+	checkTopics := func(topics []string, token string) ([]string, error) {
+		checkedTopics := make([]string, 0, len(topics))
+		for _, topic := range topics {
+			topic, err := parseEventTopic(topic)
+			if err != nil {
+				return nil, err
+			}
+
+			if isProtectedTopic(topic) && !authorized(token) {
+				return nil, errors.WithMessagef(echo.ErrUnauthorized, "topic \"%s\" needs authorization", topic)
+			}
+
+			checkedTopics = append(checkedTopics, topic)
+		}
+
+		return checkedTopics, nil
+	}
+
+	routeGroup := deps.RestRouteManager.AddRoute(RouteEvents)
+	routeGroup.GET("", func(c echo.Context) error {
+		token := c.QueryParam(QueryParameterToken)
+		if authHeader := c.Request().Header.Get(echo.HeaderAuthorization); strings.HasPrefix(authHeader, "Bearer ") {
+			token = strings.TrimPrefix(authHeader, "Bearer ")
+		}
+
+		var topics []string
+		if topicsParam := c.QueryParam(QueryParameterTopics); topicsParam != "" {
+			var err error
+			topics, err = checkTopics(strings.Split(topicsParam, ","), token)
+			if err != nil {
+				return err
+			}
+		}
+
+		if websocket.IsWebSocketUpgrade(c.Request()) {
+			return handleEventsWebSocket(c, topics, token, checkTopics)
+		}
+
+		if len(topics) == 0 {
+			return errors.WithMessagef(httpserver.ErrInvalidParameter, "parameter \"%s\" not specified", QueryParameterTopics)
+		}
+
+		return handleEventsSSE(c, topics)
+	})
+}
+
+var (
+	topicBlockMetadataRegEx  = regexp.MustCompile(`^block-metadata/(0x[0-9a-f]+)$`)
+	topicOutputsRegEx        = regexp.MustCompile(`^outputs/(0x[0-9a-f]+)$`)
+	topicOutputsAddressRegEx = regexp.MustCompile(`^outputs/address/([a-z0-9]+)$`)
+)
+
+// parseEventTopic validates the given topic and returns it in its normalized form.
+func parseEventTopic(topic string) (string, error) {
+	topic = strings.ToLower(strings.TrimSpace(topic))
+
+	switch topic {
+	case TopicMilestoneInfoLatest,
+		TopicMilestoneInfoConfirmed,
+		TopicMilestonesLatest,
+		TopicMilestonesConfirmed,
+		TopicBlockMetadataSolid,
+		TopicBlockMetadataReferenced,
+		TopicLedgerUpdates:
+		return topic, nil
+	}
+
+	if matches := topicBlockMetadataRegEx.FindStringSubmatch(topic); matches != nil {
+		if _, err := iotago.BlockIDFromHexString(matches[1]); err != nil {
+			return "", errors.WithMessagef(httpserver.ErrInvalidParameter, "invalid topic \"%s\": %s", topic, err)
+		}
+
+		return topic, nil
+	}
+
+	if matches := topicOutputsRegEx.FindStringSubmatch(topic); matches != nil {
+		if _, err := iotago.OutputIDFromHex(matches[1]); err != nil {
+			return "", errors.WithMessagef(httpserver.ErrInvalidParameter, "invalid topic \"%s\": %s", topic, err)
+		}
+
+		return topic, nil
+	}
+
+	if matches := topicOutputsAddressRegEx.FindStringSubmatch(topic); matches != nil {
+		hrp, _, err := iotago.ParseBech32(matches[1])
+		if err != nil {
+			return "", errors.WithMessagef(httpserver.ErrInvalidParameter, "invalid topic \"%s\": %s", topic, err)
+		}
+
+		if hrp != deps.ProtocolManager.Current().Bech32HRP {
+			return "", errors.WithMessagef(httpserver.ErrInvalidParameter, "invalid topic \"%s\": expected prefix %s", topic, deps.ProtocolManager.Current().Bech32HRP)
+		}
+
+		return topic, nil
+	}
+
+	return "", errors.WithMessagef(httpserver.ErrInvalidParameter, "unknown topic \"%s\"", topic)
+}
+
+func handleEventsSSE(c echo.Context, topics []string) error {
+	subscriber := eventBroker.NewSubscriber(ParamsRestAPI.Events.SubscriberBufferSize)
+	defer subscriber.Close()
+	subscriber.Subscribe(topics...)
+
+	deps.RestAPIMetrics.EventStreamSubscribers.Inc()
+	defer deps.RestAPIMetrics.EventStreamSubscribers.Dec()
+
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentType, "text/event-stream")
+	resp.Header().Set(echo.HeaderCacheControl, "no-cache")
+	resp.Header().Set(echo.HeaderConnection, "keep-alive")
+	resp.WriteHeader(http.StatusOK)
+	resp.Flush()
+
+	keepAliveTicker := time.NewTicker(eventsKeepAliveInterval)
+	defer keepAliveTicker.Stop()
+
+	ctx := c.Request().Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-Component.Daemon().ContextStopped().Done():
+			return nil
+
+		case <-keepAliveTicker.C:
+			// comments are ignored by the clients, but keep the connection open
+			if _, err := fmt.Fprint(resp, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			resp.Flush()
+
+		case event, ok := <-subscriber.Events():
+			if !ok {
+				return nil
+			}
+
+			if _, err := fmt.Fprintf(resp, "event: %s\ndata: %s\n\n", event.Topic, event.Data); err != nil {
+				return nil
+			}
+			resp.Flush()
+		}
+	}
+}
+
+func handleEventsWebSocket(c echo.Context, topics []string, token string, checkTopics func(topics []string, token string) ([]string, error)) error {
+	conn, err := eventsUpgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		// the upgrader already replied with an HTTP error
+		return nil
+	}
+	defer func() { _ = conn.Close() }()
+
+	subscriber := eventBroker.NewSubscriber(ParamsRestAPI.Events.SubscriberBufferSize)
+	defer subscriber.Close()
+	subscriber.Subscribe(topics...)
+
+	deps.RestAPIMetrics.EventStreamSubscribers.Inc()
+	defer deps.RestAPIMetrics.EventStreamSubscribers.Dec()
+
+	ctx, cancel := context.WithCancel(Component.Daemon().ContextStopped())
+	defer cancel()
+
+	// errors are only sent by the reader, the writer forwards them to the client
+	// because gorilla/websocket does not support concurrent writers.
+	requestErrors := make(chan string, 1)
+
+	sendRequestError := func(errMsg string) {
+		select {
+		case requestErrors <- errMsg:
+		case <-ctx.Done():
+		}
+	}
+
+	// reader
+	go func() {
+		defer cancel()
+
+		for {
+			var request eventWebSocketRequest
+			if err := conn.ReadJSON(&request); err != nil {
+				var syntaxErr *json.SyntaxError
+				var typeErr *json.UnmarshalTypeError
+				if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+					sendRequestError(fmt.Sprintf("invalid request: %s", err))
+
+					continue
+				}
+
+				// connection closed
+				return
+			}
+
+			if request.Token != "" {
+				token = request.Token
+			}
+
+			checkedTopics, err := checkTopics(request.Topics, token)
+			if err != nil {
+				sendRequestError(err.Error())
+
+				continue
+			}
+
+			switch request.Type {
+			case "subscribe":
+				subscriber.Subscribe(checkedTopics...)
+			case "unsubscribe":
+				subscriber.Unsubscribe(checkedTopics...)
+			default:
+				sendRequestError(fmt.Sprintf("unknown request type \"%s\"", request.Type))
+			}
+		}
+	}()
+
+	keepAliveTicker := time.NewTicker(eventsKeepAliveInterval)
+	defer keepAliveTicker.Stop()
+
+	writeJSON := func(msg *eventWebSocketMessage) error {
+		if err := conn.SetWriteDeadline(time.Now().Add(eventsWriteTimeout)); err != nil {
+			return err
+		}
+
+		return conn.WriteJSON(msg)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(eventsWriteTimeout))
+
+			return nil
+
+		case <-keepAliveTicker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(eventsWriteTimeout)); err != nil {
+				return nil
+			}
+
+		case errMsg := <-requestErrors:
+			if err := writeJSON(&eventWebSocketMessage{Error: errMsg}); err != nil {
+				return nil
+			}
+
+		case event, ok := <-subscriber.Events():
+			if !ok {
+				return nil
+			}
+
+			payload := json.RawMessage(event.Data)
+			if err := writeJSON(&eventWebSocketMessage{Topic: event.Topic, Payload: &payload}); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+func publishEvent(topic string, payloadFunc func() (any, error)) {
+	if err := eventBroker.Publish(topic, func() ([]byte, error) {
+		payload, err := payloadFunc()
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(payload)
+	}); err != nil {
+		Component.LogWarnf("failed to publish event on topic %s: %s", topic, err)
+	}
+}
+
+func publishMilestone(cachedMilestone *storage.CachedMilestone, topicInfo string, topicMilestone string) {
+	milestone := cachedMilestone.Milestone()
+
+	publishEvent(topicInfo, func() (any, error) {
+		return &eventMilestoneInfo{
+			Index:       milestone.Index(),
+			Timestamp:   milestone.TimestampUnix(),
+			MilestoneID: milestone.MilestoneIDHex(),
+		}, nil
+	})
+
+	publishEvent(topicMilestone, func() (any, error) {
+		return milestone.Milestone(), nil
+	})
+}
+
+func publishBlockMetadata(metadata *storage.BlockMetadata, topic string) {
+	payloadFunc := func() (any, error) {
+		referenced, referencedIndex, wfIndex := metadata.ReferencedWithIndexAndWhiteFlagIndex()
+
+		payload := &eventBlockMetadata{
+			BlockID:                    metadata.BlockID().ToHex(),
+			Parents:                    metadata.Parents().ToHex(),
+			Solid:                      metadata.IsSolid(),
+			ReferencedByMilestoneIndex: referencedIndex,
+		}
+
+		if referenced {
+			payload.WhiteFlagIndex = &wfIndex
+			payload.LedgerInclusionState = "noTransaction"
+
+			conflict := metadata.Conflict()
+			if conflict != storage.ConflictNone {
+				payload.LedgerInclusionState = "conflicting"
+				payload.ConflictReason = &conflict
+			} else if metadata.IsIncludedTxInLedger() {
+				payload.LedgerInclusionState = "included"
+			}
+		}
+
+		return payload, nil
+	}
+
+	publishEvent(topic, payloadFunc)
+	publishEvent(strings.Replace(TopicBlockMetadata, "{blockId}", metadata.BlockID().ToHex(), 1), payloadFunc)
+}
+
+func publishOutput(output *utxo.Output, spent *utxo.Spent) {
+	payloadFunc := func() (any, error) {
+		rawOutputJSON, err := output.Output().MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		rawOutputJSONRaw := json.RawMessage(rawOutputJSON)
+
+		payload := &eventOutput{
+			OutputID:             output.OutputID().ToHex(),
+			BlockID:              output.BlockID().ToHex(),
+			MilestoneIndexBooked: output.MilestoneIndexBooked(),
+			RawOutput:            &rawOutputJSONRaw,
+		}
+
+		if spent != nil {
+			payload.Spent = true
+			payload.MilestoneIndexSpent = spent.MilestoneIndexSpent()
+			payload.TransactionIDSpent = spent.TransactionIDSpent().ToHex()
+		}
+
+		return payload, nil
+	}
+
+	publishEvent(strings.Replace(TopicOutputs, "{outputId}", output.OutputID().ToHex(), 1), payloadFunc)
+
+	bech32HRP := deps.ProtocolManager.Current().Bech32HRP
+	for _, address := range utxo.UnlockAddresses(output.Output()) {
+		publishEvent(strings.Replace(TopicOutputsAddress, "{bech32}", address.Bech32(bech32HRP), 1), payloadFunc)
+	}
+}
+
+func publishLedgerUpdate(index iotago.MilestoneIndex, newOutputs utxo.Outputs, newSpents utxo.Spents) {
+	publishEvent(TopicLedgerUpdates, func() (any, error) {
+		createdOutputs := make([]string, len(newOutputs))
+		for i, output := range newOutputs {
+			createdOutputs[i] = output.OutputID().ToHex()
+		}
+
+		consumedOutputs := make([]string, len(newSpents))
+		for i, spent := range newSpents {
+			consumedOutputs[i] = spent.OutputID().ToHex()
+		}
+
+		return &eventLedgerUpdate{
+			Index:           index,
+			CreatedOutputs:  createdOutputs,
+			ConsumedOutputs: consumedOutputs,
+		}, nil
+	})
+
+	for _, output := range newOutputs {
+		publishOutput(output, nil)
+	}
+
+	for _, spent := range newSpents {
+		publishOutput(spent.Output(), spent)
+	}
+}
+
+func hookEvents(wp *workerpool.WorkerPool) (unhook func()) {
+	return lo.Batch(
+		deps.Tangle.Events.LatestMilestoneChanged.Hook(func(cachedMilestone *storage.CachedMilestone) {
+			defer cachedMilestone.Release(true) // milestone -1
+
+			publishMilestone(cachedMilestone, TopicMilestoneInfoLatest, TopicMilestonesLatest)
+		}, event.WithWorkerPool(wp)).Unhook,
+
+		deps.Tangle.Events.ConfirmedMilestoneChanged.Hook(func(cachedMilestone *storage.CachedMilestone) {
+			defer cachedMilestone.Release(true) // milestone -1
+
+			publishMilestone(cachedMilestone, TopicMilestoneInfoConfirmed, TopicMilestonesConfirmed)
+		}, event.WithWorkerPool(wp)).Unhook,
+
+		deps.Tangle.Events.BlockSolid.Hook(func(cachedBlockMeta *storage.CachedMetadata) {
+			defer cachedBlockMeta.Release(true) // meta -1
+
+			publishBlockMetadata(cachedBlockMeta.Metadata(), TopicBlockMetadataSolid)
+		}, event.WithWorkerPool(wp)).Unhook,
+
+		deps.Tangle.Events.BlockReferenced.Hook(func(cachedBlockMeta *storage.CachedMetadata, _ iotago.MilestoneIndex, _ uint32) {
+			defer cachedBlockMeta.Release(true) // meta -1
+
+			publishBlockMetadata(cachedBlockMeta.Metadata(), TopicBlockMetadataReferenced)
+		}, event.WithWorkerPool(wp)).Unhook,
+
+		deps.Tangle.Events.LedgerUpdated.Hook(publishLedgerUpdate, event.WithWorkerPool(wp)).Unhook,
+	)
+}
+
+func runEvents() {
+	if err := Component.Daemon().BackgroundWorker("REST-API events", func(ctx context.Context) {
+		// a single worker keeps the order of the events
+		wp := workerpool.New("RestAPIEvents", eventsWorkerCount).Start()
+
+		unhook := hookEvents(wp)
+		<-ctx.Done()
+		unhook()
+
+		wp.Shutdown()
+		wp.ShutdownComplete.Wait()
+	}, daemon.PriorityRestAPI); err != nil {
+		Component.LogPanicf("failed to start worker: %s", err)
+	}
+}
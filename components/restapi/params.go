@@ -31,12 +31,38 @@ type ParametersRestAPI struct {
 		WorkerCount int `default:"1" usage:"the amount of workers used for calculating PoW when issuing blocks via API"`
 	} `name:"pow"`
 
+	Events struct {
+		// whether the event stream (SSE and WebSocket) is enabled
+		Enabled bool `default:"true" usage:"whether the event stream (SSE and WebSocket) is enabled"`
+		// the topics of the event stream which need to be subscribed with authorization. Wildcards using * are allowed
+		ProtectedTopics []string `default:"ledger-updates" usage:"the topics of the event stream which need to be subscribed with authorization. Wildcards using * are allowed"`
+		// the maximum number of buffered events per subscriber, further events are dropped for slow subscribers
+		SubscriberBufferSize int `default:"1000" usage:"the maximum number of buffered events per subscriber, further events are dropped for slow subscribers"`
+	} `name:"events"`
+
 	Limits struct {
 		// the maximum number of characters that the body of an API call may contain
 		MaxBodyLength string `default:"1M" usage:"the maximum number of characters that the body of an API call may contain"`
 		// the maximum number of results that may be returned by an endpoint
 		MaxResults int `default:"1000" usage:"the maximum number of results that may be returned by an endpoint"`
 	}
+
+	RateLimit struct {
+		// whether the rate limiting of API requests is enabled
+		Enabled bool `default:"false" usage:"whether the rate limiting of API requests is enabled"`
+		// the IP ranges (CIDR) of trusted reverse proxies whose "X-Forwarded-For" header is used to determine the IP of a client
+		TrustedProxies []string `usage:"the IP ranges (CIDR) of trusted reverse proxies whose \"X-Forwarded-For\" header is used to determine the IP of a client"`
+		// the amount of requests per second a client (IP or JWT) may issue
+		RequestsPerSecond float64 `default:"50.0" usage:"the amount of requests per second a client (IP or JWT) may issue"`
+		// the maximum amount of requests a client may issue at once
+		Burst int `default:"100" usage:"the maximum amount of requests a client may issue at once"`
+		// the HTTP REST routes which are expensive to process and have a separate budget. Wildcards using * are allowed
+		ExpensiveRoutes []string `default:"/api/core/v2/blocks,/api/core/v2/whiteflag,/api/core/v2/ledger/at/*,/api/debug/v1/block-cones/*" usage:"the HTTP REST routes which are expensive to process and have a separate budget. Wildcards using * are allowed"`
+		// the amount of requests per second a client (IP or JWT) may issue to expensive routes
+		ExpensiveRequestsPerSecond float64 `default:"1.0" usage:"the amount of requests per second a client (IP or JWT) may issue to expensive routes"`
+		// the maximum amount of requests a client may issue at once to expensive routes
+		ExpensiveBurst int `default:"5" usage:"the maximum amount of requests a client may issue at once to expensive routes"`
+	} `name:"rateLimit"`
 }
 
 var ParamsRestAPI = &ParametersRestAPI{
@@ -49,11 +75,13 @@ var ParamsRestAPI = &ParametersRestAPI{
 		"/api/core/v2/transactions*",
 		"/api/core/v2/milestones*",
 		"/api/core/v2/outputs*",
+		"/api/core/v2/addresses*",
 		"/api/core/v2/treasury",
 		"/api/core/v2/receipts*",
 		"/api/debug/v1/*",
 		"/api/indexer/v1/*",
 		"/api/mqtt/v1",
+		"/api/events/v1*",
 		"/api/participation/v1/events*",
 		"/api/participation/v1/outputs*",
 		"/api/participation/v1/addresses*",
@@ -0,0 +1,151 @@
+package restapi
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hornet/v2/pkg/daemon"
+	"github.com/iotaledger/hornet/v2/pkg/restapi"
+)
+
+const (
+	// rateLimiterCleanupInterval is the interval in which idle clients are removed from the rate limiters.
+	rateLimiterCleanupInterval = 1 * time.Minute
+	// rateLimiterMaxIdle is the duration after which an idle client is removed from the rate limiters.
+	rateLimiterMaxIdle = 5 * time.Minute
+)
+
+var (
+	rateLimiter          *restapi.RateLimiter
+	expensiveRateLimiter *restapi.RateLimiter
+	rateLimitIPExtractor echo.IPExtractor
+)
+
+// newRateLimitIPExtractor returns the extractor of the client IP used for the rate limiting.
+// Headers containing the client IP can be set by every client to get a fresh budget,
+// so the "X-Forwarded-For" header is only used if the request was forwarded by a configured trusted proxy.
+func newRateLimitIPExtractor(trustedProxies []string) (echo.IPExtractor, error) {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	// only trust the configured proxies
+	trustOptions := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+
+	for _, trustedProxy := range trustedProxies {
+		_, ipRange, err := net.ParseCIDR(trustedProxy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid trusted proxy IP range: %s", trustedProxy)
+		}
+		trustOptions = append(trustOptions, echo.TrustIPRange(ipRange))
+	}
+
+	return echo.ExtractIPFromXFFHeader(trustOptions...), nil
+}
+
+// rateLimitKey returns the key the budget of the client is tracked with.
+// Clients with a valid and not revoked JWT are tracked by the ID of the token, all other clients by their IP.
+// All tokens of a node share the same subject, so the subject can't be used to separate the clients.
+func rateLimitKey(c echo.Context) string {
+	if authHeader := c.Request().Header.Get(echo.HeaderAuthorization); strings.HasPrefix(authHeader, "Bearer ") && jwtAuth != nil {
+		if claims, err := jwtAuth.ParseJWT(strings.TrimPrefix(authHeader, "Bearer ")); err == nil && claims.Id != "" {
+			if claims.VerifySubject(ParamsRestAPI.JWTAuth.Salt) && !jwtRevocationList.IsRevoked(claims.Id) {
+				return "jwt:" + claims.Id
+			}
+		}
+	}
+
+	return "ip:" + rateLimitIPExtractor(c.Request())
+}
+
+func rateLimitMiddleware() (echo.MiddlewareFunc, error) {
+
+	ipExtractor, err := newRateLimitIPExtractor(ParamsRestAPI.RateLimit.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	rateLimitIPExtractor = ipExtractor
+
+	expensiveRoutesRegEx := compileRoutesAsRegexes(ParamsRestAPI.RateLimit.ExpensiveRoutes)
+
+	matchExpensive := func(c echo.Context) bool {
+		loweredPath := strings.ToLower(c.Request().URL.Path)
+
+		for _, reg := range expensiveRoutesRegEx {
+			if reg.MatchString(loweredPath) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	rateLimiter = restapi.NewRateLimiter(ParamsRestAPI.RateLimit.RequestsPerSecond, ParamsRestAPI.RateLimit.Burst)
+	expensiveRateLimiter = restapi.NewRateLimiter(ParamsRestAPI.RateLimit.ExpensiveRequestsPerSecond, ParamsRestAPI.RateLimit.ExpensiveBurst)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+
+			key := rateLimitKey(c)
+
+			allowed, retryAfter := rateLimiter.Allow(key)
+			if !allowed {
+				deps.RestAPIMetrics.RateLimitedCounter.Inc()
+
+				return tooManyRequests(c, retryAfter)
+			}
+
+			if matchExpensive(c) {
+				allowed, retryAfter := expensiveRateLimiter.Allow(key)
+				if !allowed {
+					deps.RestAPIMetrics.RateLimitedCounter.Inc()
+					deps.RestAPIMetrics.RateLimitedExpensiveCounter.Inc()
+
+					return tooManyRequests(c, retryAfter)
+				}
+			}
+
+			return next(c)
+		}
+	}, nil
+}
+
+// tooManyRequests sets the "Retry-After" header and returns a 429 error.
+func tooManyRequests(c echo.Context, retryAfter time.Duration) error {
+	if retryAfter > 0 {
+		c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+
+	return echo.NewHTTPError(http.StatusTooManyRequests, "rate limit exceeded")
+}
+
+func runRateLimiterCleanup() {
+	if err := Component.Daemon().BackgroundWorker("REST-API rate limiter cleanup", func(ctx context.Context) {
+		ticker := time.NewTicker(rateLimiterCleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				rateLimiter.Cleanup(rateLimiterMaxIdle)
+				expensiveRateLimiter.Cleanup(rateLimiterMaxIdle)
+			}
+		}
+	}, daemon.PriorityRestAPI); err != nil {
+		Component.LogPanicf("failed to start worker: %s", err)
+	}
+}
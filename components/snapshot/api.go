@@ -0,0 +1,272 @@
+package snapshot
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hornet/v2/components/restapi"
+	"github.com/iotaledger/hornet/v2/pkg/snapshot"
+	"github.com/iotaledger/inx-app/pkg/httpserver"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+const (
+	// RouteSnapshotsFull is the route to download the current full snapshot file.
+	// GET returns the full snapshot file (range requests are supported).
+	RouteSnapshotsFull = "/full"
+
+	// RouteSnapshotsFullHeader is the route to get the header of the current full snapshot file.
+	// GET returns the header of the full snapshot file.
+	RouteSnapshotsFullHeader = "/full/header"
+
+	// RouteSnapshotsDelta is the route to download the current delta snapshot file.
+	// GET returns the delta snapshot file (range requests are supported).
+	RouteSnapshotsDelta = "/delta"
+
+	// RouteSnapshotsDeltaHeader is the route to get the header of the current delta snapshot file.
+	// GET returns the header of the delta snapshot file.
+	RouteSnapshotsDeltaHeader = "/delta/header"
+)
+
+// treasuryOutputResponse defines the treasury output within the header of a full snapshot file.
+type treasuryOutputResponse struct {
+	// The ID of the milestone which generated the treasury output.
+	MilestoneID string `json:"milestoneId"`
+	// The amount residing on the treasury output.
+	Amount string `json:"amount"`
+}
+
+// fullSnapshotHeaderResponse defines the response of a GET full snapshot header REST API call.
+type fullSnapshotHeaderResponse struct {
+	// The version of the snapshot file format.
+	Version byte `json:"version"`
+	// The index of the first milestone of the network.
+	GenesisMilestoneIndex iotago.MilestoneIndex `json:"genesisMilestoneIndex"`
+	// The index of the milestone of which the SEPs within the snapshot are from.
+	TargetMilestoneIndex iotago.MilestoneIndex `json:"targetMilestoneIndex"`
+	// The timestamp of the milestone of which the SEPs within the snapshot are from.
+	TargetMilestoneTimestamp uint32 `json:"targetMilestoneTimestamp"`
+	// The ID of the milestone of which the SEPs within the snapshot are from.
+	TargetMilestoneID string `json:"targetMilestoneId"`
+	// The index of the milestone of which the UTXOs within the snapshot are from.
+	LedgerMilestoneIndex iotago.MilestoneIndex `json:"ledgerMilestoneIndex"`
+	// The treasury output existing for the given ledger milestone index.
+	TreasuryOutput *treasuryOutputResponse `json:"treasuryOutput,omitempty"`
+	// The protocol parameters that are active at the target milestone index.
+	ProtocolParameters *iotago.ProtocolParameters `json:"protocol,omitempty"`
+	// The amount of UTXOs contained within the snapshot.
+	OutputCount uint64 `json:"outputCount"`
+	// The amount of milestone diffs contained within the snapshot.
+	MilestoneDiffCount uint32 `json:"milestoneDiffCount"`
+	// The amount of SEPs contained within the snapshot.
+	SEPCount uint16 `json:"sepCount"`
+	// The size of the snapshot file in bytes.
+	FileSize int64 `json:"fileSize"`
+	// Whether the snapshot file is compressed with zstd.
+	Compressed bool `json:"compressed"`
+}
+
+// deltaSnapshotHeaderResponse defines the response of a GET delta snapshot header REST API call.
+type deltaSnapshotHeaderResponse struct {
+	// The version of the snapshot file format.
+	Version byte `json:"version"`
+	// The index of the milestone of which the SEPs within the snapshot are from.
+	TargetMilestoneIndex iotago.MilestoneIndex `json:"targetMilestoneIndex"`
+	// The timestamp of the milestone of which the SEPs within the snapshot are from.
+	TargetMilestoneTimestamp uint32 `json:"targetMilestoneTimestamp"`
+	// The ID of the target milestone of the full snapshot this delta snapshot builds up from.
+	FullSnapshotTargetMilestoneID string `json:"fullSnapshotTargetMilestoneId"`
+	// The amount of milestone diffs contained within the snapshot.
+	MilestoneDiffCount uint32 `json:"milestoneDiffCount"`
+	// The amount of SEPs contained within the snapshot.
+	SEPCount uint16 `json:"sepCount"`
+	// The size of the snapshot file in bytes.
+	FileSize int64 `json:"fileSize"`
+	// Whether the snapshot file is compressed with zstd.
+	Compressed bool `json:"compressed"`
+}
+
+func configureAPI() {
+	// check if RestAPI plugin is disabled
+	if !Component.App().IsComponentEnabled(restapi.Component.Identifier()) {
+		Component.LogPanicf("RestAPI plugin needs to be enabled if '%s' is enabled", Component.App().Config().GetParameterPath(&(ParamsSnapshots.Serve.Enabled)))
+	}
+
+	routeGroup := deps.RestRouteManager.AddRoute("snapshots/v1")
+
+	serveFull := func(c echo.Context) error {
+		return serveSnapshotFile(c, snapshot.Full)
+	}
+	routeGroup.GET(RouteSnapshotsFull, serveFull)
+	routeGroup.HEAD(RouteSnapshotsFull, serveFull)
+
+	routeGroup.GET(RouteSnapshotsFullHeader, func(c echo.Context) error {
+		resp, err := fullSnapshotHeader(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	})
+
+	serveDelta := func(c echo.Context) error {
+		return serveSnapshotFile(c, snapshot.Delta)
+	}
+	routeGroup.GET(RouteSnapshotsDelta, serveDelta)
+	routeGroup.HEAD(RouteSnapshotsDelta, serveDelta)
+
+	routeGroup.GET(RouteSnapshotsDeltaHeader, func(c echo.Context) error {
+		resp, err := deltaSnapshotHeader(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	})
+}
+
+func openSnapshotFile(snapshotType snapshot.Type) (*snapshot.ServedFile, error) {
+	file, err := deps.SnapshotManager.OpenSnapshotFile(snapshotType)
+	if err != nil {
+		if errors.Is(err, snapshot.ErrSnapshotFileNotAvailable) {
+			return nil, errors.WithMessagef(echo.ErrNotFound, "%s", err)
+		}
+
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "%s", err)
+	}
+
+	return file, nil
+}
+
+// snapshotFileETag returns an entity tag that identifies the content of the snapshot file,
+// so downloads can be resumed safely with "If-Range".
+func snapshotFileETag(file *snapshot.ServedFile) (string, error) {
+	switch file.Type() {
+	case snapshot.Full:
+		fullHeader, err := file.FullHeader()
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf(`"full-%s-%d"`, fullHeader.TargetMilestoneID.ToHex(), file.Info().Size()), nil
+
+	case snapshot.Delta:
+		deltaHeader, err := file.DeltaHeader()
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf(`"delta-%s-%d-%d"`, deltaHeader.FullSnapshotTargetMilestoneID.ToHex(), deltaHeader.TargetMilestoneIndex, file.Info().Size()), nil
+
+	default:
+		return "", fmt.Errorf("unknown snapshot type: %d", file.Type())
+	}
+}
+
+func serveSnapshotFile(c echo.Context, snapshotType snapshot.Type) error {
+	file, err := openSnapshotFile(snapshotType)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	etag, err := snapshotFileETag(file)
+	if err != nil {
+		return errors.WithMessagef(echo.ErrInternalServerError, "reading snapshot header failed: %s", err)
+	}
+
+	fileName := filepath.Base(file.Name())
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, echo.MIMEOctetStream)
+	header.Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", fileName))
+	header.Set("ETag", etag)
+
+	// ServeContent handles range requests and "If-Range" preconditions
+	http.ServeContent(c.Response(), c.Request(), fileName, file.Info().ModTime(), file)
+
+	return nil
+}
+
+func fullSnapshotHeader(_ echo.Context) (*fullSnapshotHeaderResponse, error) {
+	file, err := openSnapshotFile(snapshot.Full)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	fullHeader, err := file.FullHeader()
+	if err != nil {
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading full snapshot header failed: %s", err)
+	}
+
+	compressed, err := file.IsCompressed()
+	if err != nil {
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading full snapshot file failed: %s", err)
+	}
+
+	response := &fullSnapshotHeaderResponse{
+		Version:                  fullHeader.Version,
+		GenesisMilestoneIndex:    fullHeader.GenesisMilestoneIndex,
+		TargetMilestoneIndex:     fullHeader.TargetMilestoneIndex,
+		TargetMilestoneTimestamp: fullHeader.TargetMilestoneTimestamp,
+		TargetMilestoneID:        fullHeader.TargetMilestoneID.ToHex(),
+		LedgerMilestoneIndex:     fullHeader.LedgerMilestoneIndex,
+		OutputCount:              fullHeader.OutputCount,
+		MilestoneDiffCount:       fullHeader.MilestoneDiffCount,
+		SEPCount:                 fullHeader.SEPCount,
+		FileSize:                 file.Info().Size(),
+		Compressed:               compressed,
+	}
+
+	if fullHeader.TreasuryOutput != nil {
+		response.TreasuryOutput = &treasuryOutputResponse{
+			MilestoneID: fullHeader.TreasuryOutput.MilestoneID.ToHex(),
+			Amount:      strconv.FormatUint(fullHeader.TreasuryOutput.Amount, 10),
+		}
+	}
+
+	if fullHeader.ProtocolParamsMilestoneOpt != nil {
+		protoParams, err := fullHeader.ProtocolParameters()
+		if err != nil {
+			return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading protocol parameters failed: %s", err)
+		}
+		response.ProtocolParameters = protoParams
+	}
+
+	return response, nil
+}
+
+func deltaSnapshotHeader(_ echo.Context) (*deltaSnapshotHeaderResponse, error) {
+	file, err := openSnapshotFile(snapshot.Delta)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	deltaHeader, err := file.DeltaHeader()
+	if err != nil {
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading delta snapshot header failed: %s", err)
+	}
+
+	compressed, err := file.IsCompressed()
+	if err != nil {
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading delta snapshot file failed: %s", err)
+	}
+
+	return &deltaSnapshotHeaderResponse{
+		Version:                       deltaHeader.Version,
+		TargetMilestoneIndex:          deltaHeader.TargetMilestoneIndex,
+		TargetMilestoneTimestamp:      deltaHeader.TargetMilestoneTimestamp,
+		FullSnapshotTargetMilestoneID: deltaHeader.FullSnapshotTargetMilestoneID.ToHex(),
+		MilestoneDiffCount:            deltaHeader.MilestoneDiffCount,
+		SEPCount:                      deltaHeader.SEPCount,
+		FileSize:                      file.Info().Size(),
+		Compressed:                    compressed,
+	}, nil
+}
@@ -2,6 +2,7 @@ package snapshot
 
 import (
 	"context"
+	"fmt"
 	"os"
 
 	"github.com/labstack/gommon/bytes"
@@ -9,10 +10,12 @@ import (
 	"go.uber.org/dig"
 
 	"github.com/iotaledger/hive.go/app"
+	"github.com/iotaledger/hornet/v2/components/restapi"
 	"github.com/iotaledger/hornet/v2/pkg/components"
 	"github.com/iotaledger/hornet/v2/pkg/daemon"
 	"github.com/iotaledger/hornet/v2/pkg/database"
 	"github.com/iotaledger/hornet/v2/pkg/metrics"
+	"github.com/iotaledger/hornet/v2/pkg/model/milestonemanager"
 	"github.com/iotaledger/hornet/v2/pkg/model/storage"
 	"github.com/iotaledger/hornet/v2/pkg/model/syncmanager"
 	"github.com/iotaledger/hornet/v2/pkg/model/utxo"
@@ -20,6 +23,7 @@ import (
 	"github.com/iotaledger/hornet/v2/pkg/snapshot"
 	"github.com/iotaledger/hornet/v2/pkg/tangle"
 	iotago "github.com/iotaledger/iota.go/v3"
+	"github.com/iotaledger/iota.go/v3/keymanager"
 )
 
 const (
@@ -45,6 +49,7 @@ func init() {
 		InitConfigParams: initConfigParams,
 		IsEnabled:        components.IsAutopeeringEntryNodeDisabled, // do not enable in "autopeering entry node" mode
 		Provide:          provide,
+		Configure:        configure,
 		Run:              run,
 	}
 }
@@ -61,11 +66,15 @@ type dependencies struct {
 	Storage            *storage.Storage
 	Tangle             *tangle.Tangle
 	UTXOManager        *utxo.Manager
+	SyncManager        *syncmanager.SyncManager
+	ProtocolManager    *protocol.Manager
+	MilestoneManager   *milestonemanager.MilestoneManager
 	SnapshotImporter   *snapshot.Importer
 	SnapshotManager    *snapshot.Manager
 	SnapshotsFullPath  string `name:"snapshotsFullPath"`
 	SnapshotsDeltaPath string `name:"snapshotsDeltaPath"`
 	StorageMetrics     *metrics.StorageMetrics
+	RestRouteManager   *restapi.RestRouteManager `optional:"true"`
 }
 
 func initConfigParams(c *dig.Container) error {
@@ -86,14 +95,23 @@ func initConfigParams(c *dig.Container) error {
 
 func provide(c *dig.Container) error {
 
+	if err := c.Provide(func() *metrics.SnapshotDownloadMetrics {
+		return &metrics.SnapshotDownloadMetrics{}
+	}); err != nil {
+		Component.LogPanic(err)
+	}
+
 	type snapshotImporterDeps struct {
 		dig.In
-		DeleteAllFlag        bool `name:"deleteAll"`
-		PruningPruneReceipts bool `name:"pruneReceipts"`
-		Storage              *storage.Storage
-		SnapshotsFullPath    string `name:"snapshotsFullPath"`
-		SnapshotsDeltaPath   string `name:"snapshotsDeltaPath"`
-		TargetNetworkName    string `name:"targetNetworkName"`
+		DeleteAllFlag           bool `name:"deleteAll"`
+		PruningPruneReceipts    bool `name:"pruneReceipts"`
+		Storage                 *storage.Storage
+		SnapshotsFullPath       string `name:"snapshotsFullPath"`
+		SnapshotsDeltaPath      string `name:"snapshotsDeltaPath"`
+		TargetNetworkName       string `name:"targetNetworkName"`
+		SnapshotDownloadMetrics *metrics.SnapshotDownloadMetrics
+		KeyManager              *keymanager.KeyManager
+		MilestonePublicKeyCount int `name:"milestonePublicKeyCount"`
 	}
 
 	if err := c.Provide(func(deps snapshotImporterDeps) *snapshot.Importer {
@@ -116,11 +134,25 @@ func provide(c *dig.Container) error {
 			deps.SnapshotsDeltaPath,
 			deps.TargetNetworkName,
 			ParamsSnapshots.DownloadURLs,
+			ParamsSnapshots.Download.Parallelism,
+			ParamsSnapshots.Download.MaxRetries,
+			deps.SnapshotDownloadMetrics,
 		)
 
+		if ParamsSnapshots.IncrementalSync.Enabled && ParamsSnapshots.IncrementalSync.NodeURL == "" {
+			Component.LogPanicf("parameter '%s' not specified", Component.App().Config().GetParameterPath(&(ParamsSnapshots.IncrementalSync.NodeURL)))
+		}
+
 		switch {
 		case deps.Storage.SnapshotInfo() != nil && !*forceLoadingSnapshot:
 			// snapshot already exists, no need to load it
+		case ParamsSnapshots.IncrementalSync.Enabled && !*forceLoadingSnapshot:
+			// bootstrap the empty database with the ledger state of the source node.
+			// the milestone manager is only used to verify the milestone signatures.
+			milestoneManager := milestonemanager.New(nil, nil, deps.KeyManager, deps.MilestonePublicKeyCount)
+			if err := bootstrapLedgerState(Component.Daemon().ContextStopped(), deps.Storage, milestoneManager, deps.TargetNetworkName); err != nil {
+				Component.LogErrorfAndExit("bootstrapping the database from the source node failed: %s", err)
+			}
 		default:
 			// import the initial snapshot
 			if err := importer.ImportSnapshots(Component.Daemon().ContextStopped()); err != nil {
@@ -161,6 +193,14 @@ func provide(c *dig.Container) error {
 			snapshotDepth = solidEntryPointCheckThresholdFuture
 		}
 
+		var history *snapshot.History
+		if ParamsSnapshots.History.Enabled {
+			history, err = snapshot.NewHistory(ParamsSnapshots.History.Directory, ParamsSnapshots.History.MaxFiles)
+			if err != nil {
+				Component.LogPanicf("snapshot history initialization failed: %s", err)
+			}
+		}
+
 		return snapshot.NewSnapshotManager(
 			Component.Logger(),
 			deps.Storage,
@@ -169,16 +209,36 @@ func provide(c *dig.Container) error {
 			ParamsSnapshots.Enabled,
 			deps.SnapshotsFullPath,
 			deps.SnapshotsDeltaPath,
+			ParamsSnapshots.Compression,
 			ParamsSnapshots.DeltaSizeThresholdPercentage,
 			deltaSnapshotSizeThresholdMinSizeBytes,
 			solidEntryPointCheckThresholdPast,
 			solidEntryPointCheckThresholdFuture,
 			snapshotDepth,
 			syncmanager.MilestoneIndexDelta(ParamsSnapshots.Interval),
+			history,
 		)
 	})
 }
 
+func configure() error {
+	if ParamsSnapshots.Serve.Enabled {
+		configureAPI()
+	}
+
+	if !ParamsSnapshots.IncrementalSync.Enabled {
+		return nil
+	}
+
+	// the missing milestones are synced before the node starts to gossip,
+	// so the tangle processor continues with the synced ledger state.
+	if err := incrementalSync(Component.Daemon().ContextStopped()); err != nil {
+		return fmt.Errorf("incremental sync failed: %w", err)
+	}
+
+	return nil
+}
+
 func run() error {
 	if err := Component.Daemon().BackgroundWorker("Snapshots", func(ctx context.Context) {
 		Component.LogInfo("Starting snapshot background worker ... done")
@@ -0,0 +1,523 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/serializer/v2"
+	"github.com/iotaledger/hornet/v2/pkg/common"
+	"github.com/iotaledger/hornet/v2/pkg/merger"
+	"github.com/iotaledger/hornet/v2/pkg/model/milestonemanager"
+	"github.com/iotaledger/hornet/v2/pkg/model/storage"
+	"github.com/iotaledger/hornet/v2/pkg/model/syncmanager"
+	"github.com/iotaledger/hornet/v2/pkg/model/utxo"
+	iotago "github.com/iotaledger/iota.go/v3"
+	"github.com/iotaledger/iota.go/v3/nodeclient"
+)
+
+const (
+	// incrementalSyncRequestTimeout is the timeout for a single request to the source node.
+	incrementalSyncRequestTimeout = 5 * time.Second
+
+	// incrementalSyncRouteLedgerAtIndex is the core API route of the source node to get the ledger state at a milestone.
+	incrementalSyncRouteLedgerAtIndex = "/api/core/v2/ledger/at/%d"
+)
+
+var (
+	// ErrIncrementalSyncHistoryMissing is returned if the source node already pruned the milestones that are missing in the database.
+	ErrIncrementalSyncHistoryMissing = errors.New("history of the source node is missing")
+	// ErrIncrementalSyncInvalidSourceData is returned if the data of the source node could not be verified.
+	ErrIncrementalSyncInvalidSourceData = errors.New("invalid data of the source node")
+)
+
+// incrementalSyncBootstrapped is set if the database was bootstrapped from the source node during startup.
+// In that case the remaining milestones need to be synced from the source node as well.
+var incrementalSyncBootstrapped bool
+
+// ledgerOutputsResponse defines the response of a GET ledger at milestone REST API call of the source node.
+type ledgerOutputsResponse struct {
+	// The milestone index at which the ledger state was reconstructed.
+	LedgerIndex iotago.MilestoneIndex `json:"ledgerIndex"`
+	// The cursor to request the next page, omitted if there are no further outputs.
+	Cursor string `json:"cursor,omitempty"`
+	// The outputs that were unspent at the milestone, ordered by output ID.
+	Outputs []*nodeclient.OutputResponse `json:"items"`
+}
+
+// sourceNodeInfo queries the info of the source node and checks that it operates in the same network.
+func sourceNodeInfo(ctx context.Context, client *nodeclient.Client, targetNetworkName string) (*nodeclient.InfoResponse, error) {
+	ctxInfo, cancelInfo := context.WithTimeout(ctx, incrementalSyncRequestTimeout)
+	defer cancelInfo()
+
+	info, err := client.Info(ctxInfo)
+	if err != nil {
+		return nil, fmt.Errorf("querying info of the source node failed: %w", err)
+	}
+
+	if info.Protocol.NetworkName != targetNetworkName {
+		return nil, fmt.Errorf("network name of the source node does not match (%s != %s)", info.Protocol.NetworkName, targetNetworkName)
+	}
+
+	return info, nil
+}
+
+// verifiedMilestoneViaAPI fetches the milestone with the given index from the source node and verifies its signatures.
+func verifiedMilestoneViaAPI(ctx context.Context, client *nodeclient.Client, milestoneManager *milestonemanager.MilestoneManager, msIndex iotago.MilestoneIndex) (*iotago.Milestone, error) {
+	ctxMilestone, cancelMilestone := context.WithTimeout(ctx, incrementalSyncRequestTimeout)
+	defer cancelMilestone()
+
+	milestonePayloadUnverified, err := client.MilestoneByIndex(ctxMilestone, msIndex)
+	if err != nil {
+		if errors.Is(err, nodeclient.ErrHTTPNotFound) {
+			return nil, fmt.Errorf("%w (milestone %d is not available on the source node)", ErrIncrementalSyncHistoryMissing, msIndex)
+		}
+
+		return nil, fmt.Errorf("querying milestone %d failed: %w", msIndex, err)
+	}
+
+	milestonePayload := milestoneManager.VerifyMilestonePayload(milestonePayloadUnverified)
+	if milestonePayload == nil || milestonePayload.Index != msIndex {
+		return nil, errors.Wrapf(ErrIncrementalSyncInvalidSourceData, "milestone %d not valid", msIndex)
+	}
+
+	return milestonePayload, nil
+}
+
+// bootstrapLedgerState initializes an empty database with the ledger state of the source node
+// at a milestone that is younger than the oldest milestone of the source node by the pruning safety margin.
+// The milestone is verified with the coordinator public keys and the ledger state is checked against the token supply.
+// The outputs themselves can't be verified without the history of the ledger, so the source node must be trusted.
+// The solid entry points are the blocks referenced by older milestones that are parents of blocks
+// in the cones of the following milestones, which are needed to merge those milestones afterwards.
+func bootstrapLedgerState(ctx context.Context, dbStorage *storage.Storage, milestoneManager *milestonemanager.MilestoneManager, targetNetworkName string) error {
+	client := nodeclient.New(ParamsSnapshots.IncrementalSync.NodeURL)
+
+	info, err := sourceNodeInfo(ctx, client, targetNetworkName)
+	if err != nil {
+		return err
+	}
+
+	// the pruning index itself is already pruned, and the safety margin prevents that
+	// the target milestone is pruned by the source node while the ledger state is fetched.
+	targetIndex := info.Status.PruningIndex + 1 + iotago.MilestoneIndex(ParamsSnapshots.IncrementalSync.PruningSafetyMargin)
+	solidEntryPointCheckThresholdFuture := iotago.MilestoneIndex(info.Protocol.BelowMaxDepth) + SolidEntryPointCheckAdditionalThresholdFuture
+	if info.Status.ConfirmedMilestone.Index < targetIndex+solidEntryPointCheckThresholdFuture {
+		return fmt.Errorf("%w (pruning index of source node: %d, confirmed milestone index of source node: %d), not enough milestones to calculate the solid entry points", ErrIncrementalSyncHistoryMissing, info.Status.PruningIndex, info.Status.ConfirmedMilestone.Index)
+	}
+
+	Component.LogInfof("bootstrapping the database with the ledger state at milestone %d (source: %s) ...", targetIndex, ParamsSnapshots.IncrementalSync.NodeURL)
+	ts := time.Now()
+
+	milestonePayload, err := verifiedMilestoneViaAPI(ctx, client, milestoneManager, targetIndex)
+	if err != nil {
+		return err
+	}
+
+	// mark the database as corrupted.
+	// this flag will be cleared after the ledger state was stored completely.
+	if err := dbStorage.MarkStoresCorrupted(); err != nil {
+		return err
+	}
+
+	protoParamsBytes, err := info.Protocol.Serialize(serializer.DeSeriModePerformValidation, nil)
+	if err != nil {
+		return fmt.Errorf("serializing protocol parameters of the source node failed: %w", err)
+	}
+
+	// the milestone diffs before the target index are unknown,
+	// so the current protocol parameters of the source node are used from the target index on.
+	if err := dbStorage.StoreProtocolParametersMilestoneOption(&iotago.ProtocolParamsMilestoneOpt{
+		TargetMilestoneIndex: targetIndex,
+		ProtocolVersion:      info.Protocol.Version,
+		Params:               protoParamsBytes,
+	}); err != nil {
+		return err
+	}
+
+	cachedMilestone, _ := dbStorage.StoreMilestoneIfAbsent(milestonePayload) // milestone +1
+	cachedMilestone.Release(true)                                            // milestone -1
+
+	if err := dbStorage.UTXOManager().StoreLedgerIndex(targetIndex); err != nil {
+		return err
+	}
+
+	treasuryOutput, err := treasuryAtMilestoneViaAPI(ctx, client, milestoneManager, targetIndex)
+	if err != nil {
+		return err
+	}
+
+	if err := dbStorage.UTXOManager().StoreUnspentTreasuryOutput(treasuryOutput); err != nil {
+		return err
+	}
+
+	outputCount, err := storeLedgerStateViaAPI(ctx, client, dbStorage.UTXOManager(), targetIndex)
+	if err != nil {
+		return err
+	}
+
+	if err := dbStorage.CheckLedgerState(); err != nil {
+		return errors.Wrapf(ErrIncrementalSyncInvalidSourceData, "ledger state at milestone %d: %s", targetIndex, err)
+	}
+
+	solidEntryPoints, err := solidEntryPointsViaAPI(ctx, client, milestoneManager, targetIndex, solidEntryPointCheckThresholdFuture)
+	if err != nil {
+		return err
+	}
+
+	// the ledger state pages could be inconsistent if the source node pruned the target milestone in between.
+	infoAfterFetch, err := sourceNodeInfo(ctx, client, targetNetworkName)
+	if err != nil {
+		return err
+	}
+
+	if infoAfterFetch.Status.PruningIndex >= targetIndex {
+		return fmt.Errorf("%w (milestone %d was pruned by the source node during the bootstrap, pruning index of source node: %d), please increase the pruning safety margin, delete the database folder and restart the node", ErrIncrementalSyncHistoryMissing, targetIndex, infoAfterFetch.Status.PruningIndex)
+	}
+
+	dbStorage.WriteLockSolidEntryPoints()
+	dbStorage.ResetSolidEntryPointsWithoutLocking()
+	for solidEntryPoint := range solidEntryPoints {
+		dbStorage.SolidEntryPointsAddWithoutLocking(solidEntryPoint, targetIndex)
+	}
+	err = dbStorage.StoreSolidEntryPointsWithoutLocking()
+	dbStorage.WriteUnlockSolidEntryPoints()
+	if err != nil {
+		return err
+	}
+
+	// the genesis milestone of the network is unknown, but the milestones up to the
+	// target index are not part of the database anyway.
+	if err := dbStorage.SetInitialSnapshotInfo(info.Status.PruningIndex, targetIndex, targetIndex, targetIndex, time.Unix(int64(milestonePayload.Timestamp), 0)); err != nil {
+		return fmt.Errorf("SetSnapshotMilestone failed: %w", err)
+	}
+
+	if err := dbStorage.MarkStoresHealthy(); err != nil {
+		return err
+	}
+
+	incrementalSyncBootstrapped = true
+
+	Component.LogInfof("bootstrapping the database with the ledger state at milestone %d ... done. outputs: %d, solid entry points: %d, took: %v", targetIndex, outputCount, len(solidEntryPoints), time.Since(ts).Truncate(time.Millisecond))
+
+	return nil
+}
+
+// treasuryAtMilestoneViaAPI returns the treasury output of the source node at the given milestone.
+// The treasury is only changed by receipts, so the treasury transactions of the verified milestones
+// that created the treasury outputs after the given milestone are reverted.
+func treasuryAtMilestoneViaAPI(ctx context.Context, client *nodeclient.Client, milestoneManager *milestonemanager.MilestoneManager, msIndex iotago.MilestoneIndex) (*utxo.TreasuryOutput, error) {
+	ctxTreasury, cancelTreasury := context.WithTimeout(ctx, incrementalSyncRequestTimeout)
+	defer cancelTreasury()
+
+	treasury, err := client.Treasury(ctxTreasury)
+	if err != nil {
+		return nil, fmt.Errorf("querying treasury of the source node failed: %w", err)
+	}
+
+	treasuryMilestoneID, err := iotago.DecodeHex(treasury.MilestoneID)
+	if err != nil || len(treasuryMilestoneID) != iotago.MilestoneIDLength {
+		return nil, errors.Wrapf(ErrIncrementalSyncInvalidSourceData, "invalid treasury milestone ID: %s", treasury.MilestoneID)
+	}
+
+	treasuryAmount, err := iotago.DecodeUint64(treasury.Amount)
+	if err != nil {
+		return nil, errors.Wrapf(ErrIncrementalSyncInvalidSourceData, "invalid treasury amount: %s", treasury.Amount)
+	}
+
+	treasuryOutput := &utxo.TreasuryOutput{Amount: treasuryAmount}
+	copy(treasuryOutput.MilestoneID[:], treasuryMilestoneID)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, common.ErrOperationAborted
+		}
+
+		milestonePayloadUnverified, err := func() (*iotago.Milestone, error) {
+			ctxMilestone, cancelMilestone := context.WithTimeout(ctx, incrementalSyncRequestTimeout)
+			defer cancelMilestone()
+
+			return client.MilestoneByID(ctxMilestone, treasuryOutput.MilestoneID)
+		}()
+		if err != nil {
+			if errors.Is(err, nodeclient.ErrHTTPNotFound) {
+				// the milestone that created the treasury output is older than the history of the source node,
+				// so the treasury output was not changed until the given milestone.
+				return treasuryOutput, nil
+			}
+
+			return nil, fmt.Errorf("querying milestone %s failed: %w", iotago.EncodeHex(treasuryOutput.MilestoneID[:]), err)
+		}
+
+		milestonePayload := milestoneManager.VerifyMilestonePayload(milestonePayloadUnverified)
+		if milestonePayload == nil {
+			return nil, errors.Wrapf(ErrIncrementalSyncInvalidSourceData, "milestone %s not valid", iotago.EncodeHex(treasuryOutput.MilestoneID[:]))
+		}
+
+		milestoneID, err := milestonePayload.ID()
+		if err != nil || milestoneID != treasuryOutput.MilestoneID {
+			return nil, errors.Wrapf(ErrIncrementalSyncInvalidSourceData, "milestone %s not valid", iotago.EncodeHex(treasuryOutput.MilestoneID[:]))
+		}
+
+		if milestonePayload.Index <= msIndex {
+			return treasuryOutput, nil
+		}
+
+		receipt := milestonePayload.Opts.MustSet().Receipt()
+		if receipt == nil || receipt.Transaction == nil || receipt.Transaction.Input == nil || receipt.Transaction.Output == nil || receipt.Transaction.Output.Amount != treasuryOutput.Amount {
+			return nil, errors.Wrapf(ErrIncrementalSyncInvalidSourceData, "milestone %d does not contain the treasury transaction of the treasury output", milestonePayload.Index)
+		}
+
+		// the input of the treasury transaction is the sum of the output and the migrated funds
+		previousAmount := receipt.Transaction.Output.Amount
+		for _, migratedFundsEntry := range receipt.Funds {
+			previousAmount += migratedFundsEntry.Deposit
+		}
+
+		treasuryOutput = &utxo.TreasuryOutput{
+			MilestoneID: iotago.MilestoneID(*receipt.Transaction.Input),
+			Amount:      previousAmount,
+		}
+	}
+}
+
+// storeLedgerStateViaAPI fetches the unspent outputs at the given milestone page by page from the source node and stores them.
+func storeLedgerStateViaAPI(ctx context.Context, client *nodeclient.Client, utxoManager *utxo.Manager, msIndex iotago.MilestoneIndex) (int, error) {
+	var outputCount int
+	var cursor string
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, common.ErrOperationAborted
+		}
+
+		route := fmt.Sprintf(incrementalSyncRouteLedgerAtIndex, msIndex)
+		if cursor != "" {
+			route += "?cursor=" + url.QueryEscape(cursor)
+		}
+
+		res := &ledgerOutputsResponse{}
+		if err := func() error {
+			ctxLedger, cancelLedger := context.WithTimeout(ctx, incrementalSyncRequestTimeout)
+			defer cancelLedger()
+
+			_, err := client.Do(ctxLedger, http.MethodGet, route, nil, res)
+
+			return err
+		}(); err != nil {
+			if errors.Is(err, nodeclient.ErrHTTPNotFound) {
+				return 0, fmt.Errorf("%w (ledger state at milestone %d is not available on the source node), please increase the pruning safety margin, delete the database folder and restart the node", ErrIncrementalSyncHistoryMissing, msIndex)
+			}
+
+			return 0, fmt.Errorf("querying ledger state at milestone %d failed: %w", msIndex, err)
+		}
+
+		if res.LedgerIndex != msIndex {
+			return 0, errors.Wrapf(ErrIncrementalSyncInvalidSourceData, "ledger state of milestone %d returned for milestone %d", res.LedgerIndex, msIndex)
+		}
+
+		for _, outputResponse := range res.Outputs {
+			output, err := outputFromResponse(outputResponse, msIndex)
+			if err != nil {
+				return 0, err
+			}
+
+			if err := utxoManager.AddUnspentOutput(output); err != nil {
+				return 0, err
+			}
+			outputCount++
+		}
+
+		if res.Cursor == "" {
+			return outputCount, nil
+		}
+		cursor = res.Cursor
+	}
+}
+
+// outputFromResponse creates an unspent output from the output response of the source node.
+func outputFromResponse(outputResponse *nodeclient.OutputResponse, msIndex iotago.MilestoneIndex) (*utxo.Output, error) {
+	if outputResponse.Metadata == nil || outputResponse.RawOutput == nil {
+		return nil, errors.Wrap(ErrIncrementalSyncInvalidSourceData, "incomplete output")
+	}
+	metadata := outputResponse.Metadata
+
+	if metadata.MilestoneIndexBooked > msIndex {
+		return nil, errors.Wrapf(ErrIncrementalSyncInvalidSourceData, "output booked at milestone %d is part of the ledger state at milestone %d", metadata.MilestoneIndexBooked, msIndex)
+	}
+
+	txID, err := metadata.TxID()
+	if err != nil {
+		return nil, errors.Wrap(ErrIncrementalSyncInvalidSourceData, err.Error())
+	}
+
+	blockID, err := iotago.BlockIDFromHexString(metadata.BlockID)
+	if err != nil {
+		return nil, errors.Wrap(ErrIncrementalSyncInvalidSourceData, err.Error())
+	}
+
+	output, err := outputResponse.Output()
+	if err != nil {
+		return nil, errors.Wrap(ErrIncrementalSyncInvalidSourceData, err.Error())
+	}
+
+	return utxo.CreateOutput(iotago.OutputIDFromTransactionIDAndIndex(*txID, metadata.OutputIndex), blockID, metadata.MilestoneIndexBooked, metadata.MilestoneTimestampBooked, output), nil
+}
+
+// solidEntryPointsViaAPI walks the cones of the milestones after the target index via the block metadata of the source node.
+// All blocks that were referenced by the target milestone or older ones are solid entry points.
+// Blocks that are unknown to the source node were pruned, so they were referenced before the target milestone as well.
+func solidEntryPointsViaAPI(ctx context.Context, client *nodeclient.Client, milestoneManager *milestonemanager.MilestoneManager, targetIndex iotago.MilestoneIndex, solidEntryPointCheckThresholdFuture iotago.MilestoneIndex) (map[iotago.BlockID]struct{}, error) {
+	solidEntryPoints := make(map[iotago.BlockID]struct{})
+	visited := make(map[iotago.BlockID]struct{})
+
+	var blockIDsToVisit iotago.BlockIDs
+	for msIndex := targetIndex + 1; msIndex <= targetIndex+solidEntryPointCheckThresholdFuture; msIndex++ {
+		milestonePayload, err := verifiedMilestoneViaAPI(ctx, client, milestoneManager, msIndex)
+		if err != nil {
+			return nil, err
+		}
+		blockIDsToVisit = append(blockIDsToVisit, milestonePayload.Parents...)
+	}
+
+	for len(blockIDsToVisit) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, common.ErrOperationAborted
+		}
+
+		blockID := blockIDsToVisit[len(blockIDsToVisit)-1]
+		blockIDsToVisit = blockIDsToVisit[:len(blockIDsToVisit)-1]
+
+		if _, exists := visited[blockID]; exists {
+			continue
+		}
+		visited[blockID] = struct{}{}
+
+		metadata, err := func() (*nodeclient.BlockMetadataResponse, error) {
+			ctxMetadata, cancelMetadata := context.WithTimeout(ctx, incrementalSyncRequestTimeout)
+			defer cancelMetadata()
+
+			return client.BlockMetadataByBlockID(ctxMetadata, blockID)
+		}()
+		if err != nil {
+			if errors.Is(err, nodeclient.ErrHTTPNotFound) {
+				solidEntryPoints[blockID] = struct{}{}
+
+				continue
+			}
+
+			return nil, fmt.Errorf("querying block metadata %s failed: %w", blockID.ToHex(), err)
+		}
+
+		if metadata.ReferencedByMilestoneIndex == 0 || metadata.ReferencedByMilestoneIndex > targetIndex+solidEntryPointCheckThresholdFuture {
+			return nil, errors.Wrapf(ErrIncrementalSyncInvalidSourceData, "block %s in the milestone cones is not referenced (referencedByMilestoneIndex: %d)", blockID.ToHex(), metadata.ReferencedByMilestoneIndex)
+		}
+
+		if metadata.ReferencedByMilestoneIndex <= targetIndex {
+			solidEntryPoints[blockID] = struct{}{}
+
+			continue
+		}
+
+		for _, parentHex := range metadata.Parents {
+			parentID, err := iotago.BlockIDFromHexString(parentHex)
+			if err != nil {
+				return nil, errors.Wrap(ErrIncrementalSyncInvalidSourceData, err.Error())
+			}
+			blockIDsToVisit = append(blockIDsToVisit, parentID)
+		}
+	}
+
+	return solidEntryPoints, nil
+}
+
+// incrementalSync fetches the milestone cones the database is missing from the source node,
+// and confirms them milestone by milestone. The milestone signatures are verified with the
+// coordinator public keys, and the white-flag confirmation verifies the merkle roots of every milestone.
+// The sync fails if not all milestones could be confirmed, so the node doesn't start with a partially synced database.
+func incrementalSync(ctx context.Context) error {
+
+	if deps.Storage.SnapshotInfo() == nil {
+		return common.ErrSnapshotInfoNotFound
+	}
+
+	ledgerIndex, err := deps.UTXOManager.ReadLedgerIndex()
+	if err != nil {
+		return fmt.Errorf("loading ledger index failed: %w", err)
+	}
+
+	client := nodeclient.New(ParamsSnapshots.IncrementalSync.NodeURL)
+
+	info, err := sourceNodeInfo(ctx, client, deps.ProtocolManager.Current().NetworkName)
+	if err != nil {
+		return err
+	}
+
+	sourceConfirmedIndex := info.Status.ConfirmedMilestone.Index
+	sourcePruningIndex := info.Status.PruningIndex
+
+	if sourceConfirmedIndex <= ledgerIndex || (!incrementalSyncBootstrapped && syncmanager.MilestoneIndexDelta(sourceConfirmedIndex-ledgerIndex) < syncmanager.MilestoneIndexDelta(ParamsSnapshots.IncrementalSync.MinMilestonesBehind)) {
+		Component.LogInfof("incremental sync not needed (ledger index: %d, confirmed milestone index of source node: %d)", ledgerIndex, sourceConfirmedIndex)
+
+		return nil
+	}
+
+	// the source node needs to know the cones of all milestones after the ledger index
+	if ledgerIndex < sourcePruningIndex {
+		return fmt.Errorf("%w (ledger index: %d, pruning index of source node: %d), please delete the database folder to bootstrap it from the source node", ErrIncrementalSyncHistoryMissing, ledgerIndex, sourcePruningIndex)
+	}
+
+	Component.LogInfof("starting incremental sync from milestone %d to %d (source: %s) ...", ledgerIndex+1, sourceConfirmedIndex, ParamsSnapshots.IncrementalSync.NodeURL)
+
+	// mark the database as corrupted.
+	// this flag will be cleared after all milestones were merged successfully,
+	// or the sync was stopped while the database was in a consistent state.
+	if err := deps.Storage.MarkStoresCorrupted(); err != nil {
+		return err
+	}
+
+	ts := time.Now()
+
+	for msIndex := ledgerIndex + 1; msIndex <= sourceConfirmedIndex; msIndex++ {
+		confStats, err := merger.MergeViaAPI(
+			ctx,
+			deps.ProtocolManager,
+			msIndex,
+			deps.Storage,
+			deps.MilestoneManager,
+			client,
+			ParamsSnapshots.IncrementalSync.APIParallelism,
+		)
+		if err != nil {
+			if errors.Is(err, merger.ErrMergeStoragesFailed) {
+				// do not mark the database as healthy, the ledger state was already changed
+				return err
+			}
+
+			// the ledger state of the last confirmed milestone is consistent,
+			// so the sync can be continued at the next start.
+			if errHealthy := deps.Storage.MarkStoresHealthy(); errHealthy != nil {
+				return errHealthy
+			}
+
+			return fmt.Errorf("incremental sync stopped at milestone %d: %w", msIndex, err)
+		}
+
+		if err := deps.SyncManager.SetConfirmedMilestoneIndex(confStats.MilestoneIndex, false); err != nil {
+			return err
+		}
+
+		Component.LogInfof("incremental sync confirmed milestone %d/%d, blocks: %d, took: %v", confStats.MilestoneIndex, sourceConfirmedIndex, confStats.BlocksReferenced, confStats.DurationTotal)
+	}
+
+	if err := deps.Storage.MarkStoresHealthy(); err != nil {
+		return err
+	}
+
+	Component.LogInfof("incremental sync from milestone %d to %d ... done. took: %v", ledgerIndex+1, sourceConfirmedIndex, time.Since(ts).Truncate(time.Millisecond))
+
+	return nil
+}
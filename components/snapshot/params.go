@@ -17,12 +17,52 @@ type ParametersSnapshots struct {
 	FullPath string `default:"mainnet/snapshots/full_snapshot.bin" usage:"path to the full snapshot file"`
 	// DeltaPath defines the path to the delta snapshot file
 	DeltaPath string `default:"mainnet/snapshots/delta_snapshot.bin" usage:"path to the delta snapshot file"`
+	// Compression defines whether to compress the snapshot files with zstd
+	Compression bool `default:"false" usage:"whether to compress the snapshot files with zstd"`
 	// DeltaSizeThresholdPercentage defines whether to create a full snapshot if the size of a delta snapshot reaches a certain percentage of the full snapshot
 	// (0.0 = always create delta snapshot to keep ms diff history)
 	DeltaSizeThresholdPercentage float64 `default:"50.0" usage:"create a full snapshot if the size of a delta snapshot reaches a certain percentage of the full snapshot (0.0 = always create delta snapshot to keep ms diff history)"`
 	// DeltaSizeThresholdMinSize defines the minimum size of the delta snapshot file before the threshold percentage condition is checked
 	// (below that size the delta snapshot is always created)
 	DeltaSizeThresholdMinSize string `default:"50M" usage:"the minimum size of the delta snapshot file before the threshold percentage condition is checked (below that size the delta snapshot is always created)"`
+
+	IncrementalSync struct {
+		// Enabled defines whether to fetch missing milestone cones from a source node at startup if the node is too far behind to sync via gossip (an empty database is bootstrapped with the ledger state of the source node)
+		Enabled bool `default:"false" usage:"whether to fetch missing milestone cones from a source node at startup if the node is too far behind to sync via gossip (an empty database is bootstrapped with the ledger state of the source node)"`
+		// NodeURL defines the URL of the core API of the source node (the source node must be trusted, because the ledger state of an empty database is only checked against the token supply,
+		// the route /api/core/v2/ledger/at/* needs to be public on the source node)
+		NodeURL string `default:"" usage:"the URL of the core API of the source node (the source node must be trusted, because the ledger state of an empty database is only checked against the token supply, the route /api/core/v2/ledger/at/* needs to be public on the source node)"`
+		// MinMilestonesBehind defines the minimum amount of milestones the node needs to be behind the source node to start the sync
+		// (should match the pruning window of the peers, smaller gaps are synced via gossip)
+		MinMilestonesBehind int `default:"60480" usage:"the minimum amount of milestones the node needs to be behind the source node to start the sync (should match the pruning window of the peers, smaller gaps are synced via gossip)"`
+		// PruningSafetyMargin defines the amount of milestones between the oldest milestone of the source node and the ledger state an empty database is bootstrapped with,
+		// so the ledger state is not pruned by the source node while it is fetched
+		PruningSafetyMargin int `default:"360" usage:"the amount of milestones between the oldest milestone of the source node and the ledger state an empty database is bootstrapped with, so the ledger state is not pruned by the source node while it is fetched"`
+		// APIParallelism defines the amount of concurrent API requests to the source node
+		APIParallelism int `default:"50" usage:"the amount of concurrent API requests to the source node"`
+	}
+
+	History struct {
+		// Enabled defines whether to keep older snapshot files in the history directory
+		Enabled bool `default:"false" usage:"whether to keep older snapshot files in the history directory"`
+		// Directory defines the path to the directory of the snapshot history
+		Directory string `default:"mainnet/snapshots/history" usage:"the path to the directory of the snapshot history"`
+		// MaxFiles defines the amount of full and delta snapshot files each that are kept in the history
+		MaxFiles int `default:"5" usage:"the amount of full and delta snapshot files each that are kept in the history"`
+	}
+
+	Serve struct {
+		// Enabled defines whether to serve the current snapshot files to other nodes over the REST API (/api/snapshots/v1, protected unless added to the public routes)
+		Enabled bool `default:"false" usage:"whether to serve the current snapshot files to other nodes over the REST API (/api/snapshots/v1, protected unless added to the public routes)"`
+	}
+
+	Download struct {
+		// Parallelism defines the amount of parallel range requests per snapshot file (1 = single stream)
+		Parallelism int `default:"4" usage:"the amount of parallel range requests per snapshot file (1 = single stream)"`
+		// MaxRetries defines the amount of retries of a failed range request before the download fails over to the next mirror
+		MaxRetries int `default:"3" usage:"the amount of retries of a failed range request before the download fails over to the next mirror"`
+	}
+
 	// DownloadURLs defines the URLs to load the snapshot files from.
 	DownloadURLs []*snapshot.DownloadTarget `noflag:"true" usage:"URLs to load the snapshot files from"`
 }
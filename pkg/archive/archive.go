@@ -0,0 +1,409 @@
+package archive
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/runtime/syncutils"
+	"github.com/iotaledger/hive.go/serializer/v2"
+	"github.com/iotaledger/hornet/v2/pkg/database"
+	"github.com/iotaledger/hornet/v2/pkg/model/storage"
+	"github.com/iotaledger/hornet/v2/pkg/model/utxo"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+const (
+	storePrefixBlocks           byte = 0
+	storePrefixMilestones       byte = 1
+	storePrefixMilestoneIndexes byte = 2
+	storePrefixLedger           byte = 3
+	storePrefixInfo             byte = 4
+)
+
+/*
+   Archive Database
+
+   Blocks:
+   =======
+   Key:
+       storePrefixBlocks + iotago.BlockID
+            1 byte       +    32 bytes
+
+   Value:
+       iotago.Block.Serialized()
+
+   Milestones:
+   ===========
+   Key:
+       storePrefixMilestones + iotago.MilestoneIndex
+              1 byte         +       4 bytes
+
+   Value:
+       iotago.MilestoneID + iotago.Milestone.Serialized()
+           32 bytes       +          X bytes
+
+   Milestone Indexes:
+   ==================
+   Key:
+       storePrefixMilestoneIndexes + iotago.MilestoneID
+                 1 byte            +      32 bytes
+
+   Value:
+       iotago.MilestoneIndex
+              4 bytes
+
+   Ledger:
+   =======
+   Key:
+       storePrefixLedger + same layout as the UTXO database (outputs, spents, milestone diffs, treasury outputs and receipts)
+            1 byte       +                 X bytes
+
+   Info:
+   =====
+   Key:
+       storePrefixInfo
+           1 byte
+
+   Value:
+       first archived iotago.MilestoneIndex + last archived iotago.MilestoneIndex
+                      4 bytes               +                4 bytes
+*/
+
+var (
+	// ErrArchiveClosed is returned if the archive was already shut down.
+	ErrArchiveClosed = errors.New("archive was shut down")
+)
+
+// MilestoneCone holds all the data of a confirmed milestone that gets moved to the archive.
+type MilestoneCone struct {
+	// Milestone is the milestone payload.
+	Milestone *storage.Milestone
+	// Blocks are the blocks referenced by the milestone.
+	Blocks []*storage.Block
+	// Diff contains the outputs created and spent by the milestone.
+	Diff *utxo.MilestoneDiff
+	// Receipt is the receipt contained in the milestone (optional).
+	Receipt *utxo.ReceiptTuple
+}
+
+// Archive is an append-only cold store for the milestone cones that were pruned from the database.
+// It only keeps the data needed to answer lookups (no metadata, children or unreferenced blocks),
+// and entries are never updated or deleted once they were written.
+type Archive struct {
+	database    *database.Database
+	store       kvstore.KVStore
+	blocksStore kvstore.KVStore
+	msStore     kvstore.KVStore
+	msIdxStore  kvstore.KVStore
+	utxoManager *utxo.Manager
+
+	lock       syncutils.RWMutex
+	closed     bool
+	firstIndex iotago.MilestoneIndex
+	lastIndex  iotago.MilestoneIndex
+}
+
+// New creates a new archive on top of the given database.
+func New(db *database.Database) (*Archive, error) {
+
+	store := db.KVStore()
+
+	blocksStore, err := store.WithRealm([]byte{storePrefixBlocks})
+	if err != nil {
+		return nil, err
+	}
+
+	msStore, err := store.WithRealm([]byte{storePrefixMilestones})
+	if err != nil {
+		return nil, err
+	}
+
+	msIdxStore, err := store.WithRealm([]byte{storePrefixMilestoneIndexes})
+	if err != nil {
+		return nil, err
+	}
+
+	ledgerStore, err := store.WithRealm([]byte{storePrefixLedger})
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Archive{
+		database:    db,
+		store:       store,
+		blocksStore: blocksStore,
+		msStore:     msStore,
+		msIdxStore:  msIdxStore,
+		utxoManager: utxo.New(ledgerStore),
+	}
+
+	value, err := store.Get([]byte{storePrefixInfo})
+	if err != nil {
+		if !errors.Is(err, kvstore.ErrKeyNotFound) {
+			return nil, err
+		}
+
+		// the archive is empty
+		return a, nil
+	}
+
+	if len(value) != 2*serializer.UInt32ByteSize {
+		return nil, errors.New("invalid archive info length")
+	}
+
+	a.firstIndex = binary.LittleEndian.Uint32(value[:serializer.UInt32ByteSize])
+	a.lastIndex = binary.LittleEndian.Uint32(value[serializer.UInt32ByteSize:])
+
+	return a, nil
+}
+
+// Database returns the underlying database of the archive.
+func (a *Archive) Database() *database.Database {
+	return a.database
+}
+
+// UTXOManager returns a manager to read the archived ledger data (outputs, spents, milestone diffs and receipts).
+// The archived ledger is not a complete ledger state, it only contains the data of the archived milestones.
+func (a *Archive) UTXOManager() *utxo.Manager {
+	return a.utxoManager
+}
+
+// MilestoneRange returns the first and the last archived milestone index.
+// The range contains gaps if archiving was disabled in the meantime.
+// The returned bool is false if the archive is empty.
+func (a *Archive) MilestoneRange() (iotago.MilestoneIndex, iotago.MilestoneIndex, bool) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	return a.firstIndex, a.lastIndex, a.lastIndex != 0
+}
+
+func databaseKeyForMilestoneIndex(msIndex iotago.MilestoneIndex) []byte {
+	key := make([]byte, serializer.UInt32ByteSize)
+	binary.LittleEndian.PutUint32(key, msIndex)
+
+	return key
+}
+
+// ArchiveMilestoneCone writes all the data of the given milestone cone to the archive.
+// The milestone cones have to be archived in ascending order.
+// The data is flushed to disk before the function returns,
+// so it is safe to delete it from the database afterwards.
+func (a *Archive) ArchiveMilestoneCone(cone *MilestoneCone) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.closed {
+		return ErrArchiveClosed
+	}
+
+	msIndex := cone.Milestone.Index()
+
+	if a.lastIndex != 0 && msIndex <= a.lastIndex {
+		// milestone was already archived, e.g. if the node was shut down during pruning
+		return nil
+	}
+
+	mutations, err := a.store.Batched()
+	if err != nil {
+		return err
+	}
+
+	set := func(prefix byte, key []byte, value []byte) error {
+		return mutations.Set(append([]byte{prefix}, key...), value)
+	}
+
+	if err := a.archiveMilestoneCone(cone, set); err != nil {
+		mutations.Cancel()
+
+		return err
+	}
+
+	firstIndex := a.firstIndex
+	if firstIndex == 0 {
+		firstIndex = msIndex
+	}
+
+	info := make([]byte, 2*serializer.UInt32ByteSize)
+	binary.LittleEndian.PutUint32(info[:serializer.UInt32ByteSize], firstIndex)
+	binary.LittleEndian.PutUint32(info[serializer.UInt32ByteSize:], msIndex)
+
+	if err := mutations.Set([]byte{storePrefixInfo}, info); err != nil {
+		mutations.Cancel()
+
+		return err
+	}
+
+	if err := mutations.Commit(); err != nil {
+		return err
+	}
+
+	// the data is deleted from the database after it was archived, so we need to make sure it is persisted
+	if err := a.store.Flush(); err != nil {
+		return err
+	}
+
+	a.firstIndex = firstIndex
+	a.lastIndex = msIndex
+
+	return nil
+}
+
+func (a *Archive) archiveMilestoneCone(cone *MilestoneCone, set func(prefix byte, key []byte, value []byte) error) error {
+
+	msIndex := cone.Milestone.Index()
+	milestoneID := cone.Milestone.MilestoneID()
+
+	if err := set(storePrefixMilestones, databaseKeyForMilestoneIndex(msIndex), append(milestoneID[:], cone.Milestone.Data()...)); err != nil {
+		return err
+	}
+
+	if err := set(storePrefixMilestoneIndexes, milestoneID[:], databaseKeyForMilestoneIndex(msIndex)); err != nil {
+		return err
+	}
+
+	for _, block := range cone.Blocks {
+		blockID := block.BlockID()
+		if err := set(storePrefixBlocks, blockID[:], block.Data()); err != nil {
+			return err
+		}
+	}
+
+	if cone.Diff != nil {
+		if err := set(storePrefixLedger, cone.Diff.KVStorableKey(), cone.Diff.KVStorableValue()); err != nil {
+			return err
+		}
+
+		for _, output := range cone.Diff.Outputs {
+			if err := set(storePrefixLedger, output.KVStorableKey(), output.KVStorableValue()); err != nil {
+				return err
+			}
+		}
+
+		for _, spent := range cone.Diff.Spents {
+			// the spent outputs were created by older milestones, which might not be part of the archive
+			if err := set(storePrefixLedger, spent.Output().KVStorableKey(), spent.Output().KVStorableValue()); err != nil {
+				return err
+			}
+
+			if err := set(storePrefixLedger, spent.KVStorableKey(), spent.KVStorableValue()); err != nil {
+				return err
+			}
+		}
+
+		for _, treasuryOutput := range []*utxo.TreasuryOutput{cone.Diff.TreasuryOutput, cone.Diff.SpentTreasuryOutput} {
+			if treasuryOutput == nil {
+				continue
+			}
+
+			if err := set(storePrefixLedger, treasuryOutput.KVStorableKey(), treasuryOutput.KVStorableValue()); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cone.Receipt != nil {
+		if err := set(storePrefixLedger, cone.Receipt.KVStorableKey(), cone.Receipt.KVStorableValue()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Block returns the archived block with the given block ID.
+func (a *Archive) Block(blockID iotago.BlockID) (*storage.Block, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	if a.closed {
+		return nil, ErrArchiveClosed
+	}
+
+	data, err := a.blocksStore.Get(blockID[:])
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := storage.BlockFactory(blockID[:], data)
+	if err != nil {
+		return nil, err
+	}
+
+	//nolint:forcetypeassert // we will replace that with generics anyway
+	return block.(*storage.Block), nil
+}
+
+// MilestoneByIndex returns the archived milestone with the given index.
+func (a *Archive) MilestoneByIndex(msIndex iotago.MilestoneIndex) (*storage.Milestone, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	if a.closed {
+		return nil, ErrArchiveClosed
+	}
+
+	return a.milestoneByIndex(msIndex)
+}
+
+// Milestone returns the archived milestone with the given milestone ID.
+func (a *Archive) Milestone(milestoneID iotago.MilestoneID) (*storage.Milestone, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	if a.closed {
+		return nil, ErrArchiveClosed
+	}
+
+	value, err := a.msIdxStore.Get(milestoneID[:])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(value) != serializer.UInt32ByteSize {
+		return nil, errors.Errorf("invalid archived milestone index length: %s", iotago.EncodeHex(milestoneID[:]))
+	}
+
+	return a.milestoneByIndex(binary.LittleEndian.Uint32(value))
+}
+
+func (a *Archive) milestoneByIndex(msIndex iotago.MilestoneIndex) (*storage.Milestone, error) {
+
+	value, err := a.msStore.Get(databaseKeyForMilestoneIndex(msIndex))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(value) < iotago.MilestoneIDLength {
+		return nil, errors.Errorf("invalid archived milestone length: %d", msIndex)
+	}
+
+	var milestoneID iotago.MilestoneID
+	copy(milestoneID[:], value[:iotago.MilestoneIDLength])
+
+	milestonePayload := &iotago.Milestone{}
+	if _, err := milestonePayload.Deserialize(value[iotago.MilestoneIDLength:], serializer.DeSeriModeNoValidation, nil); err != nil {
+		return nil, err
+	}
+
+	return storage.NewMilestone(milestonePayload, serializer.DeSeriModeNoValidation, milestoneID)
+}
+
+// Shutdown flushes and closes the archive.
+func (a *Archive) Shutdown() error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+
+	if err := a.store.Flush(); err != nil {
+		return err
+	}
+
+	return a.store.Close()
+}
@@ -0,0 +1,161 @@
+//nolint:forcetypeassert,varnamelen,revive,exhaustruct,gosec // we don't care about these linters in test cases
+package test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	hivedb "github.com/iotaledger/hive.go/kvstore/database"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/hornet/v2/pkg/archive"
+	"github.com/iotaledger/hornet/v2/pkg/database"
+	"github.com/iotaledger/hornet/v2/pkg/metrics"
+	"github.com/iotaledger/hornet/v2/pkg/model/storage"
+	"github.com/iotaledger/hornet/v2/pkg/testsuite"
+	"github.com/iotaledger/hornet/v2/pkg/testsuite/utils"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+const (
+	ProtocolVersion = 2
+	MinPoWScore     = 1
+	BelowMaxDepth   = 15
+)
+
+var (
+	seed1, _ = hex.DecodeString("96d9ff7a79e4b0a5f3e5848ae7867064402da92a62eabb4ebbe463f12d1f3b1aace1775488f51cb1e3a80732a03ef60b111d6833ab605aa9f8faebeb33bbe3d9")
+	seed2, _ = hex.DecodeString("b15209ddc93cbdb600137ea6a8f88cdd7c5d480d5815c9352a0fb5c4e4b86f7151dcb44c2ba635657a2df5a8fd48cb9bab674a9eceea527dbbb254ef8c9f9cd7")
+)
+
+func newArchive(t *testing.T) *archive.Archive {
+	archiveStore, err := archive.New(database.New("", mapdb.NewMapDB(), hivedb.EngineMapDB, &metrics.DatabaseMetrics{}, database.NewEvents(), false, nil, nil, nil))
+	require.NoError(t, err)
+
+	return archiveStore
+}
+
+// milestoneCone collects the archive data of the given milestone from the test environment.
+func milestoneCone(t *testing.T, te *testsuite.TestEnvironment, msIndex iotago.MilestoneIndex, blockIDs iotago.BlockIDs) *archive.MilestoneCone {
+
+	cachedMilestone := te.Storage().CachedMilestoneByIndexOrNil(msIndex) // milestone +1
+	require.NotNil(t, cachedMilestone)
+	defer cachedMilestone.Release(true) // milestone -1
+
+	blocks := make([]*storage.Block, 0, len(blockIDs))
+	for _, blockID := range blockIDs {
+		cachedBlock := te.Storage().CachedBlockOrNil(blockID) // block +1
+		require.NotNil(t, cachedBlock)
+		blocks = append(blocks, cachedBlock.Block())
+		cachedBlock.Release(true) // block -1
+	}
+
+	diff, err := te.UTXOManager().MilestoneDiff(msIndex)
+	require.NoError(t, err)
+
+	return &archive.MilestoneCone{
+		Milestone: cachedMilestone.Milestone(),
+		Blocks:    blocks,
+		Diff:      diff,
+	}
+}
+
+func TestArchiveMilestoneCone(t *testing.T) {
+
+	seed1Wallet := utils.NewHDWallet("Seed1", seed1, 0)
+	seed2Wallet := utils.NewHDWallet("Seed2", seed2, 0)
+
+	te := testsuite.SetupTestEnvironment(t, seed1Wallet.Address(), 2, ProtocolVersion, BelowMaxDepth, MinPoWScore, false)
+	defer te.CleanupTestEnvironment(true)
+
+	seed1Wallet.BookOutput(te.GenesisOutput)
+
+	blockA := te.NewBlockBuilder("A").
+		Parents(te.LastMilestoneParents()).
+		FromWallet(seed1Wallet).
+		Amount(te.ProtocolParameters().TokenSupply).
+		BuildTransactionToWallet(seed2Wallet).
+		Store().
+		BookOnWallets()
+
+	te.IssueAndConfirmMilestoneOnTips(iotago.BlockIDs{blockA.StoredBlockID()}, false)
+	msIndex := te.LastMilestoneIndex()
+
+	archiveStore := newArchive(t)
+	defer func() { require.NoError(t, archiveStore.Shutdown()) }()
+
+	_, _, ok := archiveStore.MilestoneRange()
+	require.False(t, ok)
+
+	cone := milestoneCone(t, te, msIndex, iotago.BlockIDs{blockA.StoredBlockID()})
+	require.NoError(t, archiveStore.ArchiveMilestoneCone(cone))
+
+	// archiving the same milestone again is a no-op
+	require.NoError(t, archiveStore.ArchiveMilestoneCone(cone))
+
+	first, last, ok := archiveStore.MilestoneRange()
+	require.True(t, ok)
+	require.Equal(t, msIndex, first)
+	require.Equal(t, msIndex, last)
+
+	// blocks
+	block, err := archiveStore.Block(blockA.StoredBlockID())
+	require.NoError(t, err)
+	require.Equal(t, blockA.StoredBlockID(), block.BlockID())
+	require.Equal(t, cone.Blocks[0].Data(), block.Data())
+	require.True(t, block.IsTransaction())
+
+	_, err = archiveStore.Block(iotago.EmptyBlockID())
+	require.ErrorIs(t, err, kvstore.ErrKeyNotFound)
+
+	// milestones
+	milestone, err := archiveStore.MilestoneByIndex(msIndex)
+	require.NoError(t, err)
+	require.Equal(t, cone.Milestone.MilestoneID(), milestone.MilestoneID())
+	require.Equal(t, cone.Milestone.Data(), milestone.Data())
+
+	milestone, err = archiveStore.Milestone(cone.Milestone.MilestoneID())
+	require.NoError(t, err)
+	require.Equal(t, msIndex, milestone.Index())
+
+	_, err = archiveStore.MilestoneByIndex(msIndex + 1)
+	require.ErrorIs(t, err, kvstore.ErrKeyNotFound)
+
+	// ledger
+	diff, err := archiveStore.UTXOManager().MilestoneDiff(msIndex)
+	require.NoError(t, err)
+	require.Len(t, diff.Outputs, len(cone.Diff.Outputs))
+	require.Len(t, diff.Spents, 1)
+
+	spent, err := archiveStore.UTXOManager().ReadSpentForOutputIDWithoutLocking(te.GenesisOutput.OutputID())
+	require.NoError(t, err)
+	require.Equal(t, msIndex, spent.MilestoneIndexSpent())
+	require.Equal(t, te.GenesisOutput.Deposit(), spent.Deposit())
+
+	for _, output := range cone.Diff.Outputs {
+		archivedOutput, err := archiveStore.UTXOManager().ReadOutputByOutputIDWithoutLocking(output.OutputID())
+		require.NoError(t, err)
+		require.Equal(t, output.Bytes(), archivedOutput.Bytes())
+	}
+}
+
+func TestArchiveShutdown(t *testing.T) {
+
+	seed1Wallet := utils.NewHDWallet("Seed1", seed1, 0)
+
+	te := testsuite.SetupTestEnvironment(t, seed1Wallet.Address(), 1, ProtocolVersion, BelowMaxDepth, MinPoWScore, false)
+	defer te.CleanupTestEnvironment(true)
+
+	archiveStore := newArchive(t)
+	require.NoError(t, archiveStore.Shutdown())
+
+	// shutting down twice is fine
+	require.NoError(t, archiveStore.Shutdown())
+
+	require.ErrorIs(t, archiveStore.ArchiveMilestoneCone(milestoneCone(t, te, te.LastMilestoneIndex(), nil)), archive.ErrArchiveClosed)
+
+	_, err := archiveStore.MilestoneByIndex(te.LastMilestoneIndex())
+	require.ErrorIs(t, err, archive.ErrArchiveClosed)
+}
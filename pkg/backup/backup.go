@@ -0,0 +1,279 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	hivedb "github.com/iotaledger/hive.go/kvstore/database"
+	"github.com/iotaledger/hive.go/runtime/ioutils"
+	"github.com/iotaledger/hive.go/runtime/syncutils"
+	"github.com/iotaledger/hornet/v2/pkg/common"
+	"github.com/iotaledger/hornet/v2/pkg/database"
+	"github.com/iotaledger/hornet/v2/pkg/model/storage"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+const (
+	// InfoFileName is the name of the file that holds the information about a backup.
+	InfoFileName = "backup.json"
+
+	// tempBackupSuffix is the suffix of the folder a backup is created in before it is complete.
+	tempBackupSuffix = "_tmp"
+)
+
+var (
+	// ErrBackupRunning is returned if a backup is already being created.
+	ErrBackupRunning = errors.New("backup is already running")
+	// ErrBackupAlreadyExists is returned if a backup with the same name already exists.
+	ErrBackupAlreadyExists = errors.New("backup already exists")
+)
+
+// Info holds the information about a database backup.
+type Info struct {
+	// Name is the name of the backup.
+	Name string `json:"name"`
+	// Path is the path to the folder of the backup.
+	Path string `json:"path"`
+	// CreatedAt is the unix timestamp at which the backup was created.
+	CreatedAt int64 `json:"createdAt"`
+	// Engine is the engine of the databases in the backup.
+	Engine hivedb.Engine `json:"engine"`
+	// Quiesced defines whether the databases were not in use by a running node while the backup was created.
+	// Backups of a running node may miss data that was still cached in memory,
+	// the databases of such backups are marked as corrupted and need to be revalidated at startup.
+	Quiesced bool `json:"quiesced"`
+	// LedgerIndex is the ledger index of the backup.
+	LedgerIndex iotago.MilestoneIndex `json:"ledgerIndex"`
+	// GenesisMilestoneIndex is the index of the genesis milestone of the network.
+	GenesisMilestoneIndex iotago.MilestoneIndex `json:"genesisMilestoneIndex"`
+	// SnapshotIndex is the index of the snapshot the databases were created from.
+	SnapshotIndex iotago.MilestoneIndex `json:"snapshotIndex"`
+	// EntryPointIndex is the index of the milestone of which the SEPs within the databases are from.
+	EntryPointIndex iotago.MilestoneIndex `json:"entryPointIndex"`
+	// PruningIndex is the index of the milestone before which the tangle history is pruned.
+	PruningIndex iotago.MilestoneIndex `json:"pruningIndex"`
+}
+
+// Manager creates backups of the databases.
+type Manager struct {
+	storage *storage.Storage
+	// the databases to include in the backups, mapped to their folder name within a backup.
+	databases   map[string]*database.Database
+	backupsPath string
+	maxBackups  int
+	// whether the databases are not in use by a running node.
+	quiesced bool
+
+	backupLock  syncutils.Mutex
+	statusLock  syncutils.RWMutex
+	isBackingUp bool
+}
+
+// NewBackupManager creates a new backup manager instance.
+// maxBackups defines the amount of backups that are kept, older backups are removed (0 = keep all backups).
+// quiesced defines whether the databases are not in use by a running node, e.g. if they were opened by a tool.
+func NewBackupManager(
+	storage *storage.Storage,
+	databases map[string]*database.Database,
+	backupsPath string,
+	maxBackups int,
+	quiesced bool) *Manager {
+
+	return &Manager{
+		storage:     storage,
+		databases:   databases,
+		backupsPath: backupsPath,
+		maxBackups:  maxBackups,
+		quiesced:    quiesced,
+	}
+}
+
+func (m *Manager) setIsBackingUp(value bool) {
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+
+	m.isBackingUp = value
+}
+
+// IsBackingUp returns whether a backup is being created.
+func (m *Manager) IsBackingUp() bool {
+	m.statusLock.RLock()
+	defer m.statusLock.RUnlock()
+
+	return m.isBackingUp
+}
+
+// BackupsPath returns the path to the folder in which the backups are stored.
+func (m *Manager) BackupsPath() string {
+	return m.backupsPath
+}
+
+// CreateBackup creates a checkpoint of all databases in a new backup folder
+// and removes the oldest backups afterwards if the retention limit is reached.
+func (m *Manager) CreateBackup() (*Info, error) {
+	if !m.backupLock.TryLock() {
+		return nil, ErrBackupRunning
+	}
+	defer m.backupLock.Unlock()
+
+	m.setIsBackingUp(true)
+	defer m.setIsBackingUp(false)
+
+	for _, db := range m.databases {
+		if !db.CheckpointSupported() {
+			return nil, fmt.Errorf("%w: %s", database.ErrCheckpointNotSupported, db.Engine())
+		}
+	}
+
+	createdAt := time.Now()
+	name := fmt.Sprintf("backup_%s", createdAt.UTC().Format("20060102_150405"))
+
+	backupPath := filepath.Join(m.backupsPath, name)
+	if _, err := os.Stat(backupPath); err == nil || !os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrBackupAlreadyExists, backupPath)
+	}
+
+	// the backup is created in a temporary folder first, so incomplete backups are never used.
+	tempBackupPath := backupPath + tempBackupSuffix
+	if err := os.RemoveAll(tempBackupPath); err != nil {
+		return nil, fmt.Errorf("removing incomplete backup failed: %w", err)
+	}
+
+	if err := os.MkdirAll(tempBackupPath, 0700); err != nil {
+		return nil, fmt.Errorf("creating backup folder failed: %w", err)
+	}
+
+	info, err := m.checkpointDatabases(name, tempBackupPath)
+	if err != nil {
+		_ = os.RemoveAll(tempBackupPath)
+
+		return nil, err
+	}
+	info.Path = backupPath
+	info.CreatedAt = createdAt.Unix()
+
+	if err := ioutils.WriteJSONToFile(filepath.Join(tempBackupPath, InfoFileName), info, 0600); err != nil {
+		_ = os.RemoveAll(tempBackupPath)
+
+		return nil, fmt.Errorf("storing backup info failed: %w", err)
+	}
+
+	if err := os.Rename(tempBackupPath, backupPath); err != nil {
+		_ = os.RemoveAll(tempBackupPath)
+
+		return nil, fmt.Errorf("moving backup failed: %w", err)
+	}
+
+	if err := m.applyRetentionPolicy(); err != nil {
+		return info, fmt.Errorf("removing old backups failed: %w", err)
+	}
+
+	return info, nil
+}
+
+// checkpointDatabases creates the checkpoints of all databases in the given folder.
+func (m *Manager) checkpointDatabases(name string, backupPath string) (*Info, error) {
+
+	// the ledger is locked while the checkpoints are created,
+	// so the ledger state, the protocol parameters and the snapshot info fit together.
+	// the native checkpoints of the database engines only flush the memtables and hard-link the files,
+	// so the lock is only held for a short time and is released right after the checkpoints were created.
+	m.storage.UTXOManager().ReadLockLedger()
+	defer m.storage.UTXOManager().ReadUnlockLedger()
+
+	snapshotInfo := m.storage.SnapshotInfo()
+	if snapshotInfo == nil {
+		return nil, common.ErrSnapshotInfoNotFound
+	}
+
+	ledgerIndex, err := m.storage.UTXOManager().ReadLedgerIndexWithoutLocking()
+	if err != nil {
+		return nil, err
+	}
+
+	info := &Info{
+		Name:                  name,
+		Quiesced:              m.quiesced,
+		LedgerIndex:           ledgerIndex,
+		GenesisMilestoneIndex: snapshotInfo.GenesisMilestoneIndex(),
+		SnapshotIndex:         snapshotInfo.SnapshotIndex(),
+		EntryPointIndex:       snapshotInfo.EntryPointIndex(),
+		PruningIndex:          snapshotInfo.PruningIndex(),
+	}
+
+	for directoryName, db := range m.databases {
+		info.Engine = db.Engine()
+
+		if err := db.Checkpoint(name, filepath.Join(backupPath, directoryName)); err != nil {
+			return nil, fmt.Errorf("creating checkpoint of %s database failed: %w", directoryName, err)
+		}
+	}
+
+	return info, nil
+}
+
+// Backups returns the information about all complete backups, sorted by their creation time.
+func (m *Manager) Backups() ([]*Info, error) {
+
+	entries, err := os.ReadDir(m.backupsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	backups := make([]*Info, 0)
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasSuffix(entry.Name(), tempBackupSuffix) {
+			continue
+		}
+
+		info := &Info{}
+		if err := ioutils.ReadJSONFromFile(filepath.Join(m.backupsPath, entry.Name(), InfoFileName), info); err != nil {
+			if os.IsNotExist(err) {
+				// not a backup folder
+				continue
+			}
+
+			return nil, fmt.Errorf("reading backup info failed: %w", err)
+		}
+		info.Path = filepath.Join(m.backupsPath, entry.Name())
+
+		backups = append(backups, info)
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt < backups[j].CreatedAt
+	})
+
+	return backups, nil
+}
+
+// applyRetentionPolicy removes the oldest backups if there are more than maxBackups.
+func (m *Manager) applyRetentionPolicy() error {
+	if m.maxBackups <= 0 {
+		return nil
+	}
+
+	backups, err := m.Backups()
+	if err != nil {
+		return err
+	}
+
+	for len(backups) > m.maxBackups {
+		if err := os.RemoveAll(backups[0].Path); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+
+	return nil
+}
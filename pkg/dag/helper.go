@@ -47,3 +47,48 @@ func TraverseChildren(ctx context.Context, childrenTraverserStorage ChildrenTrav
 
 	return t.Traverse(ctx, startBlockID, condition, consumer, walkAlreadyDiscovered)
 }
+
+// TraverseMilestoneCone traverses the past cone of a milestone with the given index and parents
+// and calls the consumer for every block that was referenced by that milestone.
+// The blocks are passed to the consumer in white flag (DFS) order.
+// Solid entry points and blocks referenced by older milestones are ignored.
+func TraverseMilestoneCone(ctx context.Context, parentsTraverserStorage ParentsTraverserStorage, index iotago.MilestoneIndex, parents iotago.BlockIDs, consumer Consumer) error {
+	return TraverseMilestoneConeFromWhiteFlagIndex(ctx, parentsTraverserStorage, index, parents, 0, consumer)
+}
+
+// TraverseMilestoneConeFromWhiteFlagIndex works like TraverseMilestoneCone,
+// but only passes the blocks with a white flag index equal or higher than the given one to the consumer.
+// The white flag order is the DFS order of the traversal, so the blocks before the given
+// white flag index are not walked again, which allows to continue an earlier traversal cheaply.
+func TraverseMilestoneConeFromWhiteFlagIndex(ctx context.Context, parentsTraverserStorage ParentsTraverserStorage, index iotago.MilestoneIndex, parents iotago.BlockIDs, whiteFlagIndex uint32, consumer Consumer) error {
+	return TraverseParents(
+		ctx,
+		parentsTraverserStorage,
+		parents,
+		// traversal stops if no more blocks pass the given condition
+		// Caution: condition func is not in DFS order
+		func(cachedBlockMeta *storage.CachedMetadata) (bool, error) { // meta +1
+			defer cachedBlockMeta.Release(true) // meta -1
+			if referenced, at, wfIndex := cachedBlockMeta.Metadata().ReferencedWithIndexAndWhiteFlagIndex(); referenced {
+				if at < index {
+					return false, nil
+				}
+
+				if at == index && wfIndex < whiteFlagIndex {
+					// the block and its past cone were already consumed by an earlier traversal
+					return false, nil
+				}
+			}
+
+			return true, nil
+		},
+		// consumer
+		consumer,
+		// called on missing parents
+		// return error on missing parents
+		nil,
+		// called on solid entry points
+		// Ignore solid entry points (snapshot milestone included)
+		nil,
+		false)
+}
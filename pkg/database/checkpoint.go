@@ -0,0 +1,34 @@
+package database
+
+import (
+	"fmt"
+
+	pebbleDB "github.com/cockroachdb/pebble"
+
+	hivedb "github.com/iotaledger/hive.go/kvstore/database"
+)
+
+// CheckpointFunc creates a consistent copy of a database in the given target directory.
+type CheckpointFunc func(targetDirectory string) error
+
+// NewPebbleCheckpointFunc returns a CheckpointFunc that uses the native checkpoints of pebble.
+func NewPebbleCheckpointFunc(db *pebbleDB.DB) CheckpointFunc {
+	return func(targetDirectory string) error {
+		// the write-ahead log is disabled, so the memtables need to be flushed,
+		// otherwise the latest changes would be missing in the checkpoint.
+		if err := db.Flush(); err != nil {
+			return fmt.Errorf("flushing database failed: %w", err)
+		}
+
+		if err := db.Checkpoint(targetDirectory); err != nil {
+			return fmt.Errorf("creating checkpoint failed: %w", err)
+		}
+
+		// the database info file is not part of the checkpoint
+		if _, err := CheckEngine(targetDirectory, true, hivedb.EnginePebble, hivedb.EnginePebble); err != nil {
+			return fmt.Errorf("storing database info failed: %w", err)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"bytes"
+	"fmt"
+
+	pebbleDB "github.com/cockroachdb/pebble"
+)
+
+// CompactFunc compacts the whole key range of a database to reclaim the space of deleted entries.
+type CompactFunc func() error
+
+// NewPebbleCompactFunc returns a CompactFunc that triggers a manual compaction of pebble.
+func NewPebbleCompactFunc(db *pebbleDB.DB) CompactFunc {
+	return func() error {
+		// the write-ahead log is disabled, so the memtables need to be flushed,
+		// otherwise the latest deletions would not be part of the compaction.
+		if err := db.Flush(); err != nil {
+			return fmt.Errorf("flushing database failed: %w", err)
+		}
+
+		iter := db.NewIter(nil)
+
+		if !iter.First() {
+			// the database is empty
+			return iter.Close()
+		}
+		start := bytes.Clone(iter.Key())
+
+		if !iter.Last() {
+			return iter.Close()
+		}
+		end := bytes.Clone(iter.Key())
+
+		if err := iter.Close(); err != nil {
+			return err
+		}
+
+		if bytes.Equal(start, end) {
+			// nothing to compact
+			return nil
+		}
+
+		if err := db.Compact(start, end, true); err != nil {
+			return fmt.Errorf("compacting database failed: %w", err)
+		}
+
+		return nil
+	}
+}
@@ -10,8 +10,6 @@ import (
 	"github.com/iotaledger/hive.go/kvstore"
 	hivedb "github.com/iotaledger/hive.go/kvstore/database"
 	"github.com/iotaledger/hive.go/kvstore/mapdb"
-	"github.com/iotaledger/hive.go/kvstore/pebble"
-	"github.com/iotaledger/hive.go/kvstore/rocksdb"
 	"github.com/iotaledger/hive.go/runtime/event"
 	"github.com/iotaledger/hive.go/runtime/ioutils"
 	"github.com/iotaledger/hornet/v2/pkg/metrics"
@@ -36,6 +34,10 @@ var (
 var (
 	// ErrNothingToCleanUp is returned when nothing is there to clean up in the database.
 	ErrNothingToCleanUp = errors.New("Nothing to clean up in the databases")
+	// ErrCheckpointNotSupported is returned when the database engine does not support checkpoints.
+	ErrCheckpointNotSupported = errors.New("database engine does not support checkpoints")
+	// ErrManualCompactionNotSupported is returned when the database engine does not support manual compactions.
+	ErrManualCompactionNotSupported = errors.New("database engine does not support manual compactions")
 )
 
 type Cleanup struct {
@@ -64,15 +66,48 @@ func (c *Cleanup) MarshalJSON() ([]byte, error) {
 	return json.Marshal(cleanup)
 }
 
+// Backup holds the state of a database checkpoint that is created for a backup.
+type Backup struct {
+	// Name is the name of the backup.
+	Name  string
+	Start time.Time
+	End   time.Time
+}
+
+func (b *Backup) MarshalJSON() ([]byte, error) {
+
+	backup := struct {
+		Name  string `json:"name"`
+		Start int64  `json:"start"`
+		End   int64  `json:"end"`
+	}{
+		Name:  b.Name,
+		Start: 0,
+		End:   0,
+	}
+
+	if !b.Start.IsZero() {
+		backup.Start = b.Start.Unix()
+	}
+
+	if !b.End.IsZero() {
+		backup.End = b.End.Unix()
+	}
+
+	return json.Marshal(backup)
+}
+
 type Events struct {
 	Cleanup    *event.Event1[*Cleanup]
 	Compaction *event.Event1[bool]
+	Backup     *event.Event1[*Backup]
 }
 
 func NewEvents() *Events {
 	return &Events{
 		Cleanup:    event.New1[*Cleanup](),
 		Compaction: event.New1[bool](),
+		Backup:     event.New1[*Backup](),
 	}
 }
 
@@ -85,10 +120,12 @@ type Database struct {
 	events                *Events
 	compactionSupported   bool
 	compactionRunningFunc func() bool
+	checkpointFunc        CheckpointFunc
+	compactFunc           CompactFunc
 }
 
 // New creates a new Database instance.
-func New(databaseDirectory string, kvStore kvstore.KVStore, engine hivedb.Engine, metrics *metrics.DatabaseMetrics, events *Events, compactionSupported bool, compactionRunningFunc func() bool) *Database {
+func New(databaseDirectory string, kvStore kvstore.KVStore, engine hivedb.Engine, metrics *metrics.DatabaseMetrics, events *Events, compactionSupported bool, compactionRunningFunc func() bool, checkpointFunc CheckpointFunc, compactFunc CompactFunc) *Database {
 	return &Database{
 		databaseDir:           databaseDirectory,
 		store:                 kvStore,
@@ -97,6 +134,8 @@ func New(databaseDirectory string, kvStore kvstore.KVStore, engine hivedb.Engine
 		events:                events,
 		compactionSupported:   compactionSupported,
 		compactionRunningFunc: compactionRunningFunc,
+		checkpointFunc:        checkpointFunc,
+		compactFunc:           compactFunc,
 	}
 }
 
@@ -125,6 +164,12 @@ func (db *Database) CompactionSupported() bool {
 	return db.compactionSupported
 }
 
+// SetCompactionSupported sets whether the database engine supports compaction.
+// This can be used by tools that compact the database manually after deleting entries.
+func (db *Database) SetCompactionSupported(compactionSupported bool) {
+	db.compactionSupported = compactionSupported
+}
+
 // CompactionRunning returns whether a compaction is running.
 func (db *Database) CompactionRunning() bool {
 	if db.compactionRunningFunc == nil {
@@ -134,6 +179,45 @@ func (db *Database) CompactionRunning() bool {
 	return db.compactionRunningFunc()
 }
 
+// CheckpointSupported returns whether the database engine supports checkpoints.
+func (db *Database) CheckpointSupported() bool {
+	return db.checkpointFunc != nil
+}
+
+// Checkpoint creates a consistent copy of the database in the given target directory.
+// The target directory must not exist yet.
+func (db *Database) Checkpoint(name string, targetDirectory string) error {
+	if db.checkpointFunc == nil {
+		return ErrCheckpointNotSupported
+	}
+
+	start := time.Now()
+	db.events.Backup.Trigger(&Backup{Name: name, Start: start})
+
+	if err := db.checkpointFunc(targetDirectory); err != nil {
+		return err
+	}
+
+	db.events.Backup.Trigger(&Backup{Name: name, Start: start, End: time.Now()})
+
+	return nil
+}
+
+// ManualCompactionSupported returns whether the database engine supports manual compactions.
+func (db *Database) ManualCompactionSupported() bool {
+	return db.compactFunc != nil
+}
+
+// Compact compacts the whole database to reclaim the space of deleted entries.
+// This can take a long time, so it should only be used if the database is not in use by a node.
+func (db *Database) Compact() error {
+	if db.compactFunc == nil {
+		return ErrManualCompactionNotSupported
+	}
+
+	return db.compactFunc()
+}
+
 // Size returns the size of the database.
 func (db *Database) Size() (int64, error) {
 	if db.engine == hivedb.EngineMapDB {
@@ -170,6 +254,17 @@ func CheckEngine(dbPath string, createDatabaseIfNotExists bool, dbEngine hivedb.
 // StoreWithDefaultSettings returns a kvstore with default settings.
 // It also checks if the database engine is correct.
 func StoreWithDefaultSettings(path string, createDatabaseIfNotExists bool, dbEngine hivedb.Engine, allowedEngines ...hivedb.Engine) (kvstore.KVStore, error) {
+	db, err := DatabaseWithDefaultSettings(path, createDatabaseIfNotExists, dbEngine, allowedEngines...)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.KVStore(), nil
+}
+
+// DatabaseWithDefaultSettings returns a database with default settings.
+// It also checks if the database engine is correct.
+func DatabaseWithDefaultSettings(path string, createDatabaseIfNotExists bool, dbEngine hivedb.Engine, allowedEngines ...hivedb.Engine) (*Database, error) {
 
 	tmpAllowedEngines := AllowedEnginesDefault
 	if len(allowedEngines) > 0 {
@@ -188,7 +283,7 @@ func StoreWithDefaultSettings(path string, createDatabaseIfNotExists bool, dbEng
 			return nil, err
 		}
 
-		return pebble.New(db), nil
+		return New(path, NewPebbleStore(db), hivedb.EnginePebble, &metrics.DatabaseMetrics{}, NewEvents(), false, nil, NewPebbleCheckpointFunc(db), NewPebbleCompactFunc(db)), nil
 
 	case hivedb.EngineRocksDB:
 		db, err := NewRocksDB(path)
@@ -196,10 +291,10 @@ func StoreWithDefaultSettings(path string, createDatabaseIfNotExists bool, dbEng
 			return nil, err
 		}
 
-		return rocksdb.New(db), nil
+		return New(path, NewRocksDBStore(db), hivedb.EngineRocksDB, &metrics.DatabaseMetrics{}, NewEvents(), false, nil, NewRocksDBCheckpointFunc(db), NewRocksDBCompactFunc(db)), nil
 
 	case hivedb.EngineMapDB:
-		return mapdb.NewMapDB(), nil
+		return New("", mapdb.NewMapDB(), hivedb.EngineMapDB, &metrics.DatabaseMetrics{}, NewEvents(), false, nil, nil, nil), nil
 
 	default:
 		return nil, fmt.Errorf("unknown database engine: %s, supported engines: pebble/rocksdb/mapdb", dbEngine)
@@ -1,23 +0,0 @@
-package database
-
-import (
-	"runtime"
-
-	"github.com/iotaledger/hive.go/kvstore/rocksdb"
-)
-
-// NewRocksDB creates a new RocksDB instance.
-func NewRocksDB(path string) (*rocksdb.RocksDB, error) {
-
-	opts := []rocksdb.Option{
-		rocksdb.IncreaseParallelism(runtime.NumCPU() - 1),
-		rocksdb.Custom([]string{
-			"periodic_compaction_seconds=43200",
-			"level_compaction_dynamic_level_bytes=true",
-			"keep_log_file_num=2",
-			"max_log_file_size=50000000", // 50MB per log file
-		}),
-	}
-
-	return rocksdb.CreateDB(path, opts...)
-}
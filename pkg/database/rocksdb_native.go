@@ -0,0 +1,447 @@
+//go:build rocksdb
+
+package database
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+
+	"go.uber.org/atomic"
+
+	"github.com/iotaledger/grocksdb"
+	"github.com/iotaledger/hive.go/ds/types"
+	"github.com/iotaledger/hive.go/kvstore"
+	hivedb "github.com/iotaledger/hive.go/kvstore/database"
+	"github.com/iotaledger/hive.go/kvstore/utils"
+	"github.com/iotaledger/hive.go/runtime/ioutils"
+	"github.com/iotaledger/hive.go/serializer/v2/byteutils"
+)
+
+// RocksDB holds the underlying grocksdb.DB instance and options.
+// The instance is opened and owned by this package, because the native features of RocksDB
+// like seeking, checkpoints and manual compactions are not exposed by the hive.go RocksDB.
+type RocksDB struct {
+	db *grocksdb.DB
+	ro *grocksdb.ReadOptions
+	wo *grocksdb.WriteOptions
+	fo *grocksdb.FlushOptions
+}
+
+// NewRocksDB creates a new RocksDB instance.
+func NewRocksDB(path string) (*RocksDB, error) {
+	if err := ioutils.CreateDirectory(path, 0700); err != nil {
+		return nil, fmt.Errorf("could not create directory: %w", err)
+	}
+
+	opts := grocksdb.NewDefaultOptions()
+	opts.SetCreateIfMissing(true)
+	opts.SetCompression(grocksdb.NoCompression)
+	opts.IncreaseParallelism(runtime.NumCPU() - 1)
+
+	for _, str := range []string{
+		"periodic_compaction_seconds=43200",
+		"level_compaction_dynamic_level_bytes=true",
+		"keep_log_file_num=2",
+		"max_log_file_size=50000000", // 50MB per log file
+	} {
+		var err error
+		opts, err = grocksdb.GetOptionsFromString(opts, str)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ro := grocksdb.NewDefaultReadOptions()
+	ro.SetFillCache(false)
+
+	wo := grocksdb.NewDefaultWriteOptions()
+	wo.SetSync(false)
+	wo.DisableWAL(true)
+
+	db, err := grocksdb.OpenDb(opts, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RocksDB{
+		db: db,
+		ro: ro,
+		wo: wo,
+		fo: grocksdb.NewDefaultFlushOptions(),
+	}, nil
+}
+
+// Flush the database.
+func (r *RocksDB) Flush() error {
+	return r.db.Flush(r.fo)
+}
+
+// Close the database.
+func (r *RocksDB) Close() error {
+	r.db.Close()
+
+	return nil
+}
+
+// GetIntProperty returns the value of a database property whose value is an integer.
+func (r *RocksDB) GetIntProperty(name string) (uint64, bool) {
+	return r.db.GetIntProperty(name)
+}
+
+// rocksDBStore is a RocksDB kvstore.KVStore that implements KeySeeker.
+type rocksDBStore struct {
+	instance *RocksDB
+	dbPrefix []byte
+	closed   *atomic.Bool
+}
+
+// NewRocksDBStore creates a new KVStore with the underlying RocksDB that implements KeySeeker.
+func NewRocksDBStore(db *RocksDB) kvstore.KVStore {
+	return &rocksDBStore{
+		instance: db,
+		closed:   atomic.NewBool(false),
+	}
+}
+
+func (s *rocksDBStore) WithRealm(realm kvstore.Realm) (kvstore.KVStore, error) {
+	if s.closed.Load() {
+		return nil, kvstore.ErrStoreClosed
+	}
+
+	return &rocksDBStore{
+		instance: s.instance,
+		dbPrefix: realm,
+		closed:   s.closed,
+	}, nil
+}
+
+func (s *rocksDBStore) WithExtendedRealm(realm kvstore.Realm) (kvstore.KVStore, error) {
+	return s.WithRealm(byteutils.ConcatBytes(s.Realm(), realm))
+}
+
+func (s *rocksDBStore) Realm() []byte {
+	return s.dbPrefix
+}
+
+// builds a key usable using the realm and the given prefix.
+func (s *rocksDBStore) buildKeyPrefix(prefix kvstore.KeyPrefix) kvstore.KeyPrefix {
+	return byteutils.ConcatBytes(s.dbPrefix, prefix)
+}
+
+// iterate walks over all entries with the given prefix in the given direction.
+func (s *rocksDBStore) iterate(prefix kvstore.KeyPrefix, consumerFunc func(it *grocksdb.Iterator) bool, iterDirection ...kvstore.IterDirection) error {
+	if s.closed.Load() {
+		return kvstore.ErrStoreClosed
+	}
+
+	keyPrefix := s.buildKeyPrefix(prefix)
+
+	it := s.instance.db.NewIterator(s.instance.ro)
+	defer it.Close()
+
+	startFunc := it.SeekToFirst
+	validFunc := it.Valid
+	moveFunc := it.Next
+
+	if len(keyPrefix) > 0 {
+		startFunc = func() {
+			it.Seek(keyPrefix)
+		}
+		validFunc = func() bool {
+			return it.ValidForPrefix(keyPrefix)
+		}
+	}
+
+	if kvstore.GetIterDirection(iterDirection...) == kvstore.IterDirectionBackward {
+		startFunc = it.SeekToLast
+		moveFunc = it.Prev
+
+		if len(keyPrefix) > 0 {
+			// we need to search the first item after the prefix
+			prefixUpperBound := utils.KeyPrefixUpperBound(keyPrefix)
+			if prefixUpperBound == nil {
+				return fmt.Errorf("no upper bound for prefix")
+			}
+
+			startFunc = func() {
+				it.SeekForPrev(prefixUpperBound)
+
+				// if the upper bound exists (not part of the prefix set), we need to use the next entry
+				if !validFunc() {
+					moveFunc()
+				}
+			}
+		}
+	}
+
+	for startFunc(); validFunc(); moveFunc() {
+		if !consumerFunc(it) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// iteratorKey returns a copy of the current key of the iterator without the realm.
+func (s *rocksDBStore) iteratorKey(it *grocksdb.Iterator) kvstore.Key {
+	key := it.Key()
+	defer key.Free()
+
+	return utils.CopyBytes(key.Data(), key.Size())[len(s.dbPrefix):]
+}
+
+// Iterate iterates over all keys and values with the provided prefix. You can pass kvstore.EmptyPrefix to iterate over all keys and values.
+// Optionally the direction for the iteration can be passed (default: IterDirectionForward).
+func (s *rocksDBStore) Iterate(prefix kvstore.KeyPrefix, consumerFunc kvstore.IteratorKeyValueConsumerFunc, iterDirection ...kvstore.IterDirection) error {
+	return s.iterate(prefix, func(it *grocksdb.Iterator) bool {
+		value := it.Value()
+		defer value.Free()
+
+		return consumerFunc(s.iteratorKey(it), utils.CopyBytes(value.Data(), value.Size()))
+	}, iterDirection...)
+}
+
+// IterateKeys iterates over all keys with the provided prefix. You can pass kvstore.EmptyPrefix to iterate over all keys.
+// Optionally the direction for the iteration can be passed (default: IterDirectionForward).
+func (s *rocksDBStore) IterateKeys(prefix kvstore.KeyPrefix, consumerFunc kvstore.IteratorKeyConsumerFunc, iterDirection ...kvstore.IterDirection) error {
+	return s.iterate(prefix, func(it *grocksdb.Iterator) bool {
+		return consumerFunc(s.iteratorKey(it))
+	}, iterDirection...)
+}
+
+// IterateKeysFrom iterates over all keys with the provided prefix that are equal or higher than the given start key.
+func (s *rocksDBStore) IterateKeysFrom(prefix kvstore.KeyPrefix, start kvstore.Key, consumerFunc kvstore.IteratorKeyConsumerFunc) error {
+	if s.closed.Load() {
+		return kvstore.ErrStoreClosed
+	}
+
+	lowerBound, upperBound := seekBounds(s.Realm(), prefix, start)
+
+	ro := grocksdb.NewDefaultReadOptions()
+	defer ro.Destroy()
+	ro.SetIterateUpperBound(upperBound)
+
+	it := s.instance.db.NewIterator(ro)
+	defer it.Close()
+
+	for it.Seek(lowerBound); it.Valid(); it.Next() {
+		if !consumerFunc(s.iteratorKey(it)) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (s *rocksDBStore) Clear() error {
+	return s.DeletePrefix(kvstore.EmptyPrefix)
+}
+
+func (s *rocksDBStore) Get(key kvstore.Key) (kvstore.Value, error) {
+	if s.closed.Load() {
+		return nil, kvstore.ErrStoreClosed
+	}
+
+	v, err := s.instance.db.GetBytes(s.instance.ro, byteutils.ConcatBytes(s.dbPrefix, key))
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, kvstore.ErrKeyNotFound
+	}
+
+	return v, nil
+}
+
+func (s *rocksDBStore) Set(key kvstore.Key, value kvstore.Value) error {
+	if s.closed.Load() {
+		return kvstore.ErrStoreClosed
+	}
+
+	return s.instance.db.Put(s.instance.wo, byteutils.ConcatBytes(s.dbPrefix, key), value)
+}
+
+func (s *rocksDBStore) Has(key kvstore.Key) (bool, error) {
+	if s.closed.Load() {
+		return false, kvstore.ErrStoreClosed
+	}
+
+	v, err := s.instance.db.Get(s.instance.ro, byteutils.ConcatBytes(s.dbPrefix, key))
+	if err != nil {
+		return false, err
+	}
+	defer v.Free()
+
+	return v.Exists(), nil
+}
+
+func (s *rocksDBStore) Delete(key kvstore.Key) error {
+	if s.closed.Load() {
+		return kvstore.ErrStoreClosed
+	}
+
+	return s.instance.db.Delete(s.instance.wo, byteutils.ConcatBytes(s.dbPrefix, key))
+}
+
+func (s *rocksDBStore) DeletePrefix(prefix kvstore.KeyPrefix) error {
+	if s.closed.Load() {
+		return kvstore.ErrStoreClosed
+	}
+
+	keyPrefix := s.buildKeyPrefix(prefix)
+
+	writeBatch := grocksdb.NewWriteBatch()
+	defer writeBatch.Destroy()
+
+	it := s.instance.db.NewIterator(s.instance.ro)
+	defer it.Close()
+
+	for it.Seek(keyPrefix); it.ValidForPrefix(keyPrefix); it.Next() {
+		key := it.Key()
+		writeBatch.Delete(key.Data())
+		key.Free()
+	}
+
+	return s.instance.db.Write(s.instance.wo, writeBatch)
+}
+
+func (s *rocksDBStore) Flush() error {
+	if s.closed.Load() {
+		return kvstore.ErrStoreClosed
+	}
+
+	return s.instance.Flush()
+}
+
+func (s *rocksDBStore) Close() error {
+	if s.closed.Swap(true) {
+		// was already closed
+		return nil
+	}
+
+	return s.instance.Close()
+}
+
+func (s *rocksDBStore) Batched() (kvstore.BatchedMutations, error) {
+	if s.closed.Load() {
+		return nil, kvstore.ErrStoreClosed
+	}
+
+	return &rocksDBBatchedMutations{
+		store:            s,
+		setOperations:    make(map[string]kvstore.Value),
+		deleteOperations: make(map[string]types.Empty),
+	}, nil
+}
+
+// rocksDBBatchedMutations is a wrapper around a WriteBatch of a RocksDB.
+type rocksDBBatchedMutations struct {
+	store            *rocksDBStore
+	setOperations    map[string]kvstore.Value
+	deleteOperations map[string]types.Empty
+	operationsMutex  sync.Mutex
+}
+
+func (b *rocksDBBatchedMutations) Set(key kvstore.Key, value kvstore.Value) error {
+	stringKey := byteutils.ConcatBytesToString(b.store.dbPrefix, key)
+
+	b.operationsMutex.Lock()
+	defer b.operationsMutex.Unlock()
+
+	delete(b.deleteOperations, stringKey)
+	b.setOperations[stringKey] = value
+
+	return nil
+}
+
+func (b *rocksDBBatchedMutations) Delete(key kvstore.Key) error {
+	stringKey := byteutils.ConcatBytesToString(b.store.dbPrefix, key)
+
+	b.operationsMutex.Lock()
+	defer b.operationsMutex.Unlock()
+
+	delete(b.setOperations, stringKey)
+	b.deleteOperations[stringKey] = types.Void
+
+	return nil
+}
+
+func (b *rocksDBBatchedMutations) Cancel() {
+	b.operationsMutex.Lock()
+	defer b.operationsMutex.Unlock()
+
+	b.setOperations = make(map[string]kvstore.Value)
+	b.deleteOperations = make(map[string]types.Empty)
+}
+
+func (b *rocksDBBatchedMutations) Commit() error {
+	if b.store.closed.Load() {
+		return kvstore.ErrStoreClosed
+	}
+
+	writeBatch := grocksdb.NewWriteBatch()
+	defer writeBatch.Destroy()
+
+	b.operationsMutex.Lock()
+	defer b.operationsMutex.Unlock()
+
+	for key, value := range b.setOperations {
+		writeBatch.Put([]byte(key), value)
+	}
+
+	for key := range b.deleteOperations {
+		writeBatch.Delete([]byte(key))
+	}
+
+	return b.store.instance.db.Write(b.store.instance.wo, writeBatch)
+}
+
+// NewRocksDBCheckpointFunc returns a CheckpointFunc that uses the native checkpoints of RocksDB.
+func NewRocksDBCheckpointFunc(db *RocksDB) CheckpointFunc {
+	return func(targetDirectory string) error {
+		checkpoint, err := db.db.NewCheckpoint()
+		if err != nil {
+			return fmt.Errorf("creating checkpoint failed: %w", err)
+		}
+		defer checkpoint.Destroy()
+
+		// a log size of 0 always flushes the memtables before the checkpoint is created,
+		// otherwise the latest changes would be missing in the checkpoint if the write-ahead log is disabled.
+		if err := checkpoint.CreateCheckpoint(targetDirectory, 0); err != nil {
+			return fmt.Errorf("creating checkpoint failed: %w", err)
+		}
+
+		// the database info file is not part of the checkpoint
+		if _, err := CheckEngine(targetDirectory, true, hivedb.EngineRocksDB, hivedb.EngineRocksDB); err != nil {
+			return fmt.Errorf("storing database info failed: %w", err)
+		}
+
+		return nil
+	}
+}
+
+// NewRocksDBCompactFunc returns a CompactFunc that triggers a manual compaction of RocksDB.
+func NewRocksDBCompactFunc(db *RocksDB) CompactFunc {
+	return func() error {
+		// the write-ahead log is disabled, so the memtables need to be flushed,
+		// otherwise the latest deletions would not be part of the compaction.
+		fo := grocksdb.NewDefaultFlushOptions()
+		defer fo.Destroy()
+		fo.SetWait(true)
+
+		if err := db.db.Flush(fo); err != nil {
+			return fmt.Errorf("flushing database failed: %w", err)
+		}
+
+		// an empty range compacts the whole key range
+		db.db.CompactRange(grocksdb.Range{Start: nil, Limit: nil})
+
+		return nil
+	}
+}
+
+var _ kvstore.KVStore = &rocksDBStore{}
+var _ kvstore.BatchedMutations = &rocksDBBatchedMutations{}
+var _ KeySeeker = &rocksDBStore{}
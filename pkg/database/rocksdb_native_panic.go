@@ -0,0 +1,49 @@
+//go:build !rocksdb
+
+package database
+
+import (
+	"github.com/iotaledger/hive.go/kvstore"
+)
+
+const (
+	panicMissingRocksDB = "For RocksDB support please compile with '-tags rocksdb'"
+)
+
+// RocksDB holds the underlying grocksdb.DB instance and options.
+type RocksDB struct{}
+
+// NewRocksDB creates a new RocksDB instance.
+func NewRocksDB(_ string) (*RocksDB, error) {
+	panic(panicMissingRocksDB)
+}
+
+// Flush the database.
+func (r *RocksDB) Flush() error {
+	panic(panicMissingRocksDB)
+}
+
+// Close the database.
+func (r *RocksDB) Close() error {
+	panic(panicMissingRocksDB)
+}
+
+// GetIntProperty returns the value of a database property whose value is an integer.
+func (r *RocksDB) GetIntProperty(_ string) (uint64, bool) {
+	panic(panicMissingRocksDB)
+}
+
+// NewRocksDBStore creates a new KVStore with the underlying RocksDB that implements KeySeeker.
+func NewRocksDBStore(_ *RocksDB) kvstore.KVStore {
+	panic(panicMissingRocksDB)
+}
+
+// NewRocksDBCheckpointFunc returns a CheckpointFunc that uses the native checkpoints of RocksDB.
+func NewRocksDBCheckpointFunc(_ *RocksDB) CheckpointFunc {
+	panic(panicMissingRocksDB)
+}
+
+// NewRocksDBCompactFunc returns a CompactFunc that triggers a manual compaction of RocksDB.
+func NewRocksDBCompactFunc(_ *RocksDB) CompactFunc {
+	panic(panicMissingRocksDB)
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"bytes"
+
+	pebbleDB "github.com/cockroachdb/pebble"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/kvstore/pebble"
+	"github.com/iotaledger/hive.go/kvstore/utils"
+	"github.com/iotaledger/hive.go/serializer/v2/byteutils"
+)
+
+// KeySeeker is implemented by stores that are able to start an iteration at a given key,
+// without walking over the lower keys with the same prefix.
+type KeySeeker interface {
+	// IterateKeysFrom iterates over all keys with the provided prefix that are equal or higher than the given start key.
+	IterateKeysFrom(prefix kvstore.KeyPrefix, start kvstore.Key, consumerFunc kvstore.IteratorKeyConsumerFunc) error
+}
+
+// seekBounds returns the bounds of an iteration over the keys with the given prefix starting at the given key.
+func seekBounds(realm kvstore.Realm, prefix kvstore.KeyPrefix, start kvstore.Key) ([]byte, []byte) {
+	lowerBound := byteutils.ConcatBytes(realm, prefix)
+	upperBound := utils.KeyPrefixUpperBound(lowerBound)
+
+	if startKey := byteutils.ConcatBytes(realm, start); bytes.Compare(startKey, lowerBound) > 0 {
+		lowerBound = startKey
+	}
+
+	return lowerBound, upperBound
+}
+
+// pebbleStore is a pebble kvstore.KVStore that implements KeySeeker.
+type pebbleStore struct {
+	kvstore.KVStore
+	db *pebbleDB.DB
+}
+
+// NewPebbleStore creates a new KVStore with the underlying pebble DB that implements KeySeeker.
+func NewPebbleStore(db *pebbleDB.DB) kvstore.KVStore {
+	return &pebbleStore{
+		KVStore: pebble.New(db),
+		db:      db,
+	}
+}
+
+func (s *pebbleStore) WithRealm(realm kvstore.Realm) (kvstore.KVStore, error) {
+	store, err := s.KVStore.WithRealm(realm)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pebbleStore{
+		KVStore: store,
+		db:      s.db,
+	}, nil
+}
+
+func (s *pebbleStore) WithExtendedRealm(realm kvstore.Realm) (kvstore.KVStore, error) {
+	return s.WithRealm(byteutils.ConcatBytes(s.Realm(), realm))
+}
+
+// IterateKeysFrom iterates over all keys with the provided prefix that are equal or higher than the given start key.
+func (s *pebbleStore) IterateKeysFrom(prefix kvstore.KeyPrefix, start kvstore.Key, consumerFunc kvstore.IteratorKeyConsumerFunc) error {
+	lowerBound, upperBound := seekBounds(s.Realm(), prefix, start)
+
+	it := s.db.NewIter(&pebbleDB.IterOptions{LowerBound: lowerBound, UpperBound: upperBound})
+	defer it.Close()
+
+	for it.First(); it.Valid(); it.Next() {
+		if !consumerFunc(utils.CopyBytes(it.Key())[len(s.Realm()):]) {
+			break
+		}
+	}
+
+	return nil
+}
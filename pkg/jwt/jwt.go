@@ -1,9 +1,12 @@
 package jwt
 
 import (
+	"crypto/rand"
 	"crypto/subtle"
+	"encoding/hex"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt"
@@ -15,8 +18,65 @@ import (
 
 var (
 	ErrJWTInvalidClaims = echo.NewHTTPError(http.StatusUnauthorized, "invalid jwt claims")
+	ErrInvalidScope     = errors.New("invalid scope")
 )
 
+const (
+	// ScopeAll grants access to all routes.
+	ScopeAll = "*"
+	// ScopeReadCore grants read access to the core API.
+	ScopeReadCore = "read:core"
+	// ScopeWriteBlocks grants access to submit blocks.
+	ScopeWriteBlocks = "write:blocks"
+	// ScopeAdminPeers grants access to manage the peers of the node.
+	ScopeAdminPeers = "admin:peers"
+	// ScopeAdminControl grants access to the control routes of the node (pruning, snapshots, ...).
+	ScopeAdminControl = "admin:control"
+
+	// ScopeActionRead is the scope action for reading resources.
+	ScopeActionRead = "read"
+	// ScopeActionWrite is the scope action for writing resources.
+	ScopeActionWrite = "write"
+	// ScopeActionAdmin is the scope action for administrative access to resources.
+	ScopeActionAdmin = "admin"
+
+	// tokenIDLength is the length of the random part of the token ID in bytes.
+	tokenIDLength = 16
+)
+
+// ValidateScope checks if the given scope has the format "<action>:<resource>",
+// with action being "read", "write" or "admin". The resource can be a wildcard "*".
+func ValidateScope(scope string) error {
+	if scope == ScopeAll {
+		return nil
+	}
+
+	action, resource, found := strings.Cut(scope, ":")
+	if !found || len(resource) == 0 {
+		return fmt.Errorf("%w: %s, expected format \"<action>:<resource>\"", ErrInvalidScope, scope)
+	}
+
+	switch action {
+	case ScopeActionRead, ScopeActionWrite, ScopeActionAdmin:
+	default:
+		return fmt.Errorf("%w: %s, unknown action \"%s\"", ErrInvalidScope, scope, action)
+	}
+
+	return nil
+}
+
+// scopeMatches checks if the granted scope allows access to the required scope.
+func scopeMatches(granted string, required string) bool {
+	if granted == ScopeAll || granted == required {
+		return true
+	}
+
+	grantedAction, grantedResource, _ := strings.Cut(granted, ":")
+	requiredAction, _, _ := strings.Cut(required, ":")
+
+	return grantedResource == "*" && grantedAction == requiredAction
+}
+
 type Auth struct {
 	subject        string
 	sessionTimeout time.Duration
@@ -45,6 +105,9 @@ func NewAuth(subject string, sessionTimeout time.Duration, nodeID string, secret
 
 type AuthClaims struct {
 	jwt.StandardClaims
+	// Scopes contains the scopes the token grants access to.
+	// Tokens without scopes (issued before scopes were introduced) grant access to all routes.
+	Scopes []string `json:"scopes,omitempty"`
 }
 
 func (c *AuthClaims) compare(field string, expected string) bool {
@@ -62,6 +125,22 @@ func (c *AuthClaims) VerifySubject(expected string) bool {
 	return c.compare(c.Subject, expected)
 }
 
+// HasScope checks if the claims grant access to the required scope.
+func (c *AuthClaims) HasScope(required string) bool {
+	if len(c.Scopes) == 0 {
+		// unscoped tokens grant access to all routes
+		return true
+	}
+
+	for _, scope := range c.Scopes {
+		if scopeMatches(scope, required) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (j *Auth) Middleware(skipper middleware.Skipper, allow func(c echo.Context, subject string, claims *AuthClaims) bool) echo.MiddlewareFunc {
 
 	config := middleware.JWTConfig{
@@ -119,7 +198,20 @@ func (j *Auth) Middleware(skipper middleware.Skipper, allow func(c echo.Context,
 	}
 }
 
-func (j *Auth) IssueJWT() (string, error) {
+// IssueJWT issues a new token that grants access to the given scopes.
+// If no scopes are given, the token grants access to all routes.
+func (j *Auth) IssueJWT(scopes ...string) (string, error) {
+
+	for _, scope := range scopes {
+		if err := ValidateScope(scope); err != nil {
+			return "", err
+		}
+	}
+
+	tokenID := make([]byte, tokenIDLength)
+	if _, err := rand.Read(tokenID); err != nil {
+		return "", fmt.Errorf("unable to generate token ID: %w", err)
+	}
 
 	now := time.Now()
 
@@ -128,7 +220,7 @@ func (j *Auth) IssueJWT() (string, error) {
 		Subject:   j.subject,
 		Issuer:    j.nodeID,
 		Audience:  j.nodeID,
-		Id:        fmt.Sprintf("%d", now.Unix()),
+		Id:        hex.EncodeToString(tokenID),
 		IssuedAt:  now.Unix(),
 		NotBefore: now.Unix(),
 	}
@@ -139,6 +231,7 @@ func (j *Auth) IssueJWT() (string, error) {
 
 	claims := &AuthClaims{
 		StandardClaims: stdClaims,
+		Scopes:         scopes,
 	}
 
 	// Create token
@@ -148,7 +241,8 @@ func (j *Auth) IssueJWT() (string, error) {
 	return token.SignedString(j.secret)
 }
 
-func (j *Auth) VerifyJWT(token string, allow func(claims *AuthClaims) bool) bool {
+// ParseJWT parses the given token, verifies its signature, validity and audience and returns the claims.
+func (j *Auth) ParseJWT(token string) (*AuthClaims, error) {
 
 	t, err := jwt.ParseWithClaims(token, &AuthClaims{}, func(token *jwt.Token) (interface{}, error) {
 		// validate the signing method we expect
@@ -158,20 +252,29 @@ func (j *Auth) VerifyJWT(token string, allow func(claims *AuthClaims) bool) bool
 
 		return j.secret, nil
 	})
-	if err == nil && t.Valid {
-		claims, ok := t.Claims.(*AuthClaims)
+	if err != nil {
+		return nil, err
+	}
 
-		if !ok || !claims.VerifyAudience(j.nodeID, true) {
-			return false
-		}
+	if !t.Valid {
+		return nil, ErrJWTInvalidClaims
+	}
 
-		// validate claims
-		if !allow(claims) {
-			return false
-		}
+	claims, ok := t.Claims.(*AuthClaims)
+	if !ok || !claims.VerifyAudience(j.nodeID, true) {
+		return nil, ErrJWTInvalidClaims
+	}
 
-		return true
+	return claims, nil
+}
+
+func (j *Auth) VerifyJWT(token string, allow func(claims *AuthClaims) bool) bool {
+
+	claims, err := j.ParseJWT(token)
+	if err != nil {
+		return false
 	}
 
-	return false
+	// validate claims
+	return allow(claims)
 }
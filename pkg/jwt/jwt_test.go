@@ -0,0 +1,110 @@
+//nolint:forcetypeassert,varnamelen,revive,exhaustruct // we don't care about these linters in test cases
+package jwt_test
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hornet/v2/pkg/jwt"
+)
+
+const (
+	testSubject = "HORNET"
+	testNodeID  = "12D3KooWTestNode"
+)
+
+func newTestAuth(t *testing.T, sessionTimeout time.Duration) *jwt.Auth {
+	privKey, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	require.NoError(t, err)
+
+	auth, err := jwt.NewAuth(testSubject, sessionTimeout, testNodeID, privKey)
+	require.NoError(t, err)
+
+	return auth
+}
+
+func TestValidateScope(t *testing.T) {
+	for _, scope := range []string{jwt.ScopeAll, jwt.ScopeReadCore, jwt.ScopeWriteBlocks, jwt.ScopeAdminPeers, jwt.ScopeAdminControl, "read:*", "read:indexer"} {
+		require.NoError(t, jwt.ValidateScope(scope), scope)
+	}
+
+	for _, scope := range []string{"", "read", "read:", "delete:core", "core"} {
+		require.ErrorIs(t, jwt.ValidateScope(scope), jwt.ErrInvalidScope, scope)
+	}
+}
+
+func TestScopedJWT(t *testing.T) {
+	auth := newTestAuth(t, 0)
+
+	_, err := auth.IssueJWT("invalid")
+	require.ErrorIs(t, err, jwt.ErrInvalidScope)
+
+	token, err := auth.IssueJWT(jwt.ScopeReadCore, "write:*")
+	require.NoError(t, err)
+
+	claims, err := auth.ParseJWT(token)
+	require.NoError(t, err)
+	require.True(t, claims.VerifySubject(testSubject))
+	require.NotEmpty(t, claims.Id)
+	require.Zero(t, claims.ExpiresAt)
+
+	require.True(t, claims.HasScope(jwt.ScopeReadCore))
+	require.True(t, claims.HasScope(jwt.ScopeWriteBlocks))
+	require.False(t, claims.HasScope("read:indexer"))
+	require.False(t, claims.HasScope(jwt.ScopeAdminPeers))
+	require.False(t, claims.HasScope(jwt.ScopeAdminControl))
+
+	// unscoped tokens grant access to everything
+	token, err = auth.IssueJWT()
+	require.NoError(t, err)
+
+	claims, err = auth.ParseJWT(token)
+	require.NoError(t, err)
+	require.True(t, claims.HasScope(jwt.ScopeAdminControl))
+
+	// tokens of other nodes are rejected
+	require.False(t, newTestAuth(t, 0).VerifyJWT(token, func(claims *jwt.AuthClaims) bool { return true }))
+}
+
+func TestExpiredJWT(t *testing.T) {
+	auth := newTestAuth(t, time.Second)
+
+	token, err := auth.IssueJWT(jwt.ScopeReadCore)
+	require.NoError(t, err)
+
+	claims, err := auth.ParseJWT(token)
+	require.NoError(t, err)
+	require.NotZero(t, claims.ExpiresAt)
+
+	time.Sleep(2 * time.Second)
+
+	_, err = auth.ParseJWT(token)
+	require.Error(t, err)
+}
+
+func TestRevocationList(t *testing.T) {
+	dir := t.TempDir()
+
+	revocationList, err := jwt.NewRevocationList(dir)
+	require.NoError(t, err)
+	require.False(t, revocationList.IsRevoked("abc"))
+
+	require.NoError(t, revocationList.Revoke("abc", "leaked"))
+	require.True(t, revocationList.IsRevoked("abc"))
+	require.False(t, revocationList.IsRevoked("def"))
+
+	// the list is persisted
+	loadedList, err := jwt.NewRevocationList(dir)
+	require.NoError(t, err)
+	require.True(t, loadedList.IsRevoked("abc"))
+
+	revokedTokens := loadedList.RevokedTokens()
+	require.Len(t, revokedTokens, 1)
+	require.Equal(t, "leaked", revokedTokens[0].Reason)
+
+	require.Error(t, revocationList.Revoke("", ""))
+}
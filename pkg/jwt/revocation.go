@@ -0,0 +1,186 @@
+package jwt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/runtime/ioutils"
+)
+
+const (
+	// RevocationListFileName is the name of the file the revoked tokens are persisted in.
+	RevocationListFileName = "revoked_jwts.json"
+
+	// the minimum interval between two checks whether the revocation list file changed on disk.
+	revocationListReloadInterval = 5 * time.Second
+)
+
+// RevokedToken is a token that was revoked.
+type RevokedToken struct {
+	// The ID of the revoked token.
+	ID string `json:"id"`
+	// The time the token was revoked.
+	RevokedAt time.Time `json:"revokedAt"`
+	// The reason why the token was revoked.
+	Reason string `json:"reason,omitempty"`
+}
+
+// RevocationList is a list of revoked token IDs that is persisted to disk.
+// Changes to the file (e.g. by the "jwt-revoke" tool) are picked up by running nodes.
+type RevocationList struct {
+	sync.RWMutex
+	filePath string
+	revoked  map[string]*RevokedToken
+	// the modification time of the file at the last load.
+	modTime time.Time
+	// the last time the file was checked for changes.
+	lastCheck time.Time
+}
+
+// NewRevocationList loads the revocation list from the given directory.
+// If the file does not exist, an empty list is returned.
+func NewRevocationList(directory string) (*RevocationList, error) {
+	r := &RevocationList{
+		filePath: filepath.Join(directory, RevocationListFileName),
+		revoked:  make(map[string]*RevokedToken),
+	}
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *RevocationList) load() error {
+	info, err := os.Stat(r.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			r.revoked = make(map[string]*RevokedToken)
+			r.modTime = time.Time{}
+
+			return nil
+		}
+
+		return fmt.Errorf("unable to check revocation list file (%s): %w", r.filePath, err)
+	}
+
+	if info.ModTime().Equal(r.modTime) {
+		return nil
+	}
+
+	var tokens []*RevokedToken
+	if err := ioutils.ReadJSONFromFile(r.filePath, &tokens); err != nil {
+		return fmt.Errorf("unable to read revocation list file (%s): %w", r.filePath, err)
+	}
+
+	revoked := make(map[string]*RevokedToken, len(tokens))
+	for _, token := range tokens {
+		revoked[token.ID] = token
+	}
+
+	r.revoked = revoked
+	r.modTime = info.ModTime()
+
+	return nil
+}
+
+func (r *RevocationList) store() error {
+	tokens := make([]*RevokedToken, 0, len(r.revoked))
+	for _, token := range r.revoked {
+		tokens = append(tokens, token)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].RevokedAt.Before(tokens[j].RevokedAt)
+	})
+
+	if err := ioutils.CreateDirectory(filepath.Dir(r.filePath), 0o700); err != nil {
+		return fmt.Errorf("unable to create revocation list directory: %w", err)
+	}
+
+	if err := ioutils.WriteJSONToFile(r.filePath, tokens, 0o600); err != nil {
+		return fmt.Errorf("unable to write revocation list file (%s): %w", r.filePath, err)
+	}
+
+	info, err := os.Stat(r.filePath)
+	if err != nil {
+		return fmt.Errorf("unable to check revocation list file (%s): %w", r.filePath, err)
+	}
+	r.modTime = info.ModTime()
+
+	return nil
+}
+
+// reloadIfChanged reloads the list if the file on disk was changed.
+// The file is only checked once per reload interval.
+func (r *RevocationList) reloadIfChanged() {
+	r.RLock()
+	checkNeeded := time.Since(r.lastCheck) >= revocationListReloadInterval
+	r.RUnlock()
+
+	if !checkNeeded {
+		return
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	r.lastCheck = time.Now()
+
+	// keep the current list if the file can't be read
+	_ = r.load()
+}
+
+// Revoke adds the token with the given ID to the list and persists it.
+func (r *RevocationList) Revoke(tokenID string, reason string) error {
+	if len(tokenID) == 0 {
+		return fmt.Errorf("token ID must not be empty")
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	// pick up changes done by others first
+	if err := r.load(); err != nil {
+		return err
+	}
+
+	r.revoked[tokenID] = &RevokedToken{
+		ID:        tokenID,
+		RevokedAt: time.Now(),
+		Reason:    reason,
+	}
+
+	return r.store()
+}
+
+// IsRevoked returns whether the token with the given ID was revoked.
+func (r *RevocationList) IsRevoked(tokenID string) bool {
+	r.reloadIfChanged()
+
+	r.RLock()
+	defer r.RUnlock()
+
+	_, revoked := r.revoked[tokenID]
+
+	return revoked
+}
+
+// RevokedTokens returns all revoked tokens.
+func (r *RevocationList) RevokedTokens() []*RevokedToken {
+	r.reloadIfChanged()
+
+	r.RLock()
+	defer r.RUnlock()
+
+	tokens := make([]*RevokedToken, 0, len(r.revoked))
+	for _, token := range r.revoked {
+		tokens = append(tokens, token)
+	}
+
+	return tokens
+}
@@ -0,0 +1,369 @@
+package merger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/runtime/contextutils"
+	"github.com/iotaledger/hornet/v2/pkg/common"
+	"github.com/iotaledger/hornet/v2/pkg/dag"
+	"github.com/iotaledger/hornet/v2/pkg/model/milestonemanager"
+	"github.com/iotaledger/hornet/v2/pkg/model/storage"
+	"github.com/iotaledger/hornet/v2/pkg/model/utxo"
+	"github.com/iotaledger/hornet/v2/pkg/protocol"
+	"github.com/iotaledger/hornet/v2/pkg/whiteflag"
+	iotago "github.com/iotaledger/iota.go/v3"
+	"github.com/iotaledger/iota.go/v3/nodeclient"
+)
+
+const (
+	// apiRequestTimeout is the timeout for a single request to the node API.
+	apiRequestTimeout = 5 * time.Second
+)
+
+var (
+	// ErrMergeStoragesFailed is returned if the confirmed milestone cone could not be merged with the target storage.
+	// The ledger of the target storage was already updated in that case, so the target storage is inconsistent.
+	ErrMergeStoragesFailed = errors.New("merge storages failed")
+)
+
+// GetMilestonePayloadFunc returns the milestone payload for the given index.
+type GetMilestonePayloadFunc func(msIndex iotago.MilestoneIndex) (*iotago.Milestone, error)
+
+// ConfirmationStats holds statistics about a milestone that was copied and confirmed in the target storage.
+type ConfirmationStats struct {
+	MilestoneIndex       iotago.MilestoneIndex
+	BlocksReferenced     int
+	DurationCopy         time.Duration
+	DurationConfirmation time.Duration
+	DurationMerge        time.Duration
+	DurationTotal        time.Duration
+}
+
+// CopyMilestoneCone copies all blocks of a milestone cone to the target storage.
+func CopyMilestoneCone(
+	ctx context.Context,
+	protoParams *iotago.ProtocolParameters,
+	msIndex iotago.MilestoneIndex,
+	milestonePayload *iotago.Milestone,
+	parentsTraverserInterface dag.ParentsTraverserInterface,
+	cachedBlockFuncSource storage.CachedBlockFunc,
+	storeBlockTarget StoreBlockInterface,
+	milestoneManager *milestonemanager.MilestoneManager) error {
+
+	// traversal stops if no more blocks pass the given condition
+	// Caution: condition func is not in DFS order
+	condition := func(cachedBlockMeta *storage.CachedMetadata) (bool, error) { // meta +1
+		defer cachedBlockMeta.Release(true) // meta -1
+
+		// collect all blocks that were referenced by that milestone
+		referenced, at := cachedBlockMeta.Metadata().ReferencedWithIndex()
+
+		if referenced {
+			if at > msIndex {
+				return false, fmt.Errorf("milestone cone inconsistent (msIndex: %d, referencedAt: %d)", msIndex, at)
+			}
+
+			if at < msIndex {
+				// do not traverse blocks that were referenced by an older milestonee
+				return false, nil
+			}
+		}
+		blockID := cachedBlockMeta.Metadata().BlockID()
+		cachedBlock, err := cachedBlockFuncSource(blockID) // block +1
+		if err != nil {
+			return false, err
+		}
+		if cachedBlock == nil {
+			return false, fmt.Errorf("block not found: %s", blockID.ToHex())
+		}
+		defer cachedBlock.Release(true) // block -1
+
+		// store the block in the target storage
+		cachedBlockNew, err := StoreBlock(protoParams, storeBlockTarget, milestoneManager, cachedBlock.Block().Block()) // block +1
+		if err != nil {
+			return false, err
+		}
+		defer cachedBlockNew.Release(true) // block -1
+
+		cachedBlockMetaNew := cachedBlockNew.CachedMetadata() // meta +1
+		defer cachedBlockMetaNew.Release(true)                // meta -1
+
+		// we need to mark all blocks that contain a milestone payload,
+		// but we can not trust the metadata of the parentsTraverserInterface for correct info about milestones
+		// because it could be a proxystorage, which doesn't know the correct milestones yet.
+		if cachedBlockNew.Block().IsMilestone() {
+			milestonePayload := milestoneManager.VerifyMilestonePayload(cachedBlockNew.Block().Milestone())
+			if milestonePayload != nil {
+				cachedBlockMetaNew.Metadata().SetMilestone(true)
+			}
+		}
+
+		// set the new block as solid
+		cachedBlockMetaNew.Metadata().SetSolid(true)
+
+		return true, nil
+	}
+
+	// traverse the milestone and collect all blocks that were referenced by this milestone or newer
+	if err := parentsTraverserInterface.Traverse(
+		ctx,
+		milestonePayload.Parents,
+		condition,
+		nil,
+		// called on missing parents
+		// return error on missing parents
+		nil,
+		// called on solid entry points
+		// Ignore solid entry points (snapshot milestone included)
+		nil,
+		false); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CopyAndVerifyMilestoneCone verifies the milestone, copies the milestone cone to the
+// target storage, confirms the milestone and applies the ledger changes.
+// The signatures of the milestone are verified with the keys of the milestone manager,
+// and the white-flag confirmation verifies the merkle roots of the milestone.
+func CopyAndVerifyMilestoneCone(
+	ctx context.Context,
+	protocolManager *protocol.Manager,
+	genesisMilestoneIndex iotago.MilestoneIndex,
+	msIndex iotago.MilestoneIndex,
+	getMilestonePayload GetMilestonePayloadFunc,
+	parentsTraverserInterfaceSource dag.ParentsTraverserInterface,
+	cachedBlockFuncSource storage.CachedBlockFunc,
+	cachedBlockFuncTarget storage.CachedBlockFunc,
+	utxoManagerTarget *utxo.Manager,
+	storeBlockTarget StoreBlockInterface,
+	parentsTraverserStorageTarget dag.ParentsTraverserStorage,
+	milestoneManager *milestonemanager.MilestoneManager) (*ConfirmationStats, error) {
+
+	if err := contextutils.ReturnErrIfCtxDone(ctx, common.ErrOperationAborted); err != nil {
+		return nil, err
+	}
+
+	milestonePayloadUnverified, err := getMilestonePayload(msIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	milestonePayload := milestoneManager.VerifyMilestonePayload(milestonePayloadUnverified)
+	if milestonePayload == nil {
+		return nil, fmt.Errorf("source milestone not valid! %d", msIndex)
+	}
+
+	// we need to store every milestone payload here as well,
+	// otherwise the ledger changes might be applied,
+	// but the corresponding milestone payload is unknown in the database.
+	cachedMilestone, _ := storeBlockTarget.StoreMilestoneIfAbsent(milestonePayload) // milestone +1
+	cachedMilestone.Release(true)                                                   // milestone -1
+
+	ts := time.Now()
+
+	//nolint:contextcheck // we don't want abort the copying of the blocks itself
+	if err := CopyMilestoneCone(
+		context.Background(),
+		protocolManager.Current(),
+		msIndex,
+		milestonePayload,
+		parentsTraverserInterfaceSource,
+		cachedBlockFuncSource,
+		storeBlockTarget,
+		milestoneManager); err != nil {
+		return nil, err
+	}
+
+	timeCopyMilestoneCone := time.Now()
+
+	//nolint:contextcheck // we don't pass a context here to not cancel the whiteflag computation!
+	confirmedMilestoneStats, _, err := whiteflag.ConfirmMilestone(
+		utxoManagerTarget,
+		parentsTraverserStorageTarget,
+		cachedBlockFuncTarget,
+		protocolManager.Current(),
+		genesisMilestoneIndex,
+		milestonePayload,
+		whiteflag.DefaultWhiteFlagTraversalCondition,
+		whiteflag.DefaultCheckBlockReferencedFunc,
+		whiteflag.DefaultSetBlockReferencedFunc,
+		nil,
+		// Hint: Ledger is write locked
+		nil,
+		// Hint: Ledger is write locked
+		nil,
+		// Hint: Ledger is not locked
+		nil,
+		// Hint: Ledger is not locked
+		nil,
+		// Hint: Ledger is not locked
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	timeConfirmMilestone := time.Now()
+
+	// handle protocol parameter updates
+	protocolManager.HandleConfirmedMilestone(milestonePayload)
+
+	return &ConfirmationStats{
+		MilestoneIndex:       confirmedMilestoneStats.Index,
+		BlocksReferenced:     confirmedMilestoneStats.BlocksReferenced,
+		DurationCopy:         timeCopyMilestoneCone.Sub(ts).Truncate(time.Millisecond),
+		DurationConfirmation: timeConfirmMilestone.Sub(timeCopyMilestoneCone).Truncate(time.Millisecond),
+	}, nil
+}
+
+// mergeViaProxyStorage copies a milestone to the target storage.
+// The blocks of the milestone cone are collected in a proxy storage first,
+// which is merged with the target storage after the milestone was confirmed.
+func mergeViaProxyStorage(
+	ctx context.Context,
+	protocolManager *protocol.Manager,
+	msIndex iotago.MilestoneIndex,
+	storeTarget *storage.Storage,
+	milestoneManager *milestonemanager.MilestoneManager,
+	getBlock GetBlockFunc,
+	getMilestonePayload GetMilestonePayloadFunc,
+	parentsTraverserFunc func(proxyStorage *ProxyStorage) dag.ParentsTraverserInterface,
+	cachedBlockFuncSource func(proxyStorage *ProxyStorage) storage.CachedBlockFunc) (*ConfirmationStats, error) {
+
+	snapshotInfoTarget := storeTarget.SnapshotInfo()
+	if snapshotInfoTarget == nil {
+		return nil, common.ErrSnapshotInfoNotFound
+	}
+
+	//nolint:contextcheck // false positive
+	proxyStorage, err := NewProxyStorage(protocolManager.Current(), storeTarget, milestoneManager, getBlock)
+	if err != nil {
+		return nil, err
+	}
+	defer proxyStorage.Cleanup()
+
+	ts := time.Now()
+
+	confStats, err := CopyAndVerifyMilestoneCone(
+		ctx,
+		protocolManager,
+		snapshotInfoTarget.GenesisMilestoneIndex(),
+		msIndex,
+		getMilestonePayload,
+		parentsTraverserFunc(proxyStorage),
+		cachedBlockFuncSource(proxyStorage),
+		proxyStorage.CachedBlock,
+		storeTarget.UTXOManager(),
+		proxyStorage,
+		proxyStorage,
+		milestoneManager)
+	if err != nil {
+		return nil, err
+	}
+
+	timeMergeStoragesStart := time.Now()
+
+	if err := proxyStorage.MergeStorages(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMergeStoragesFailed, err)
+	}
+
+	te := time.Now()
+
+	confStats.DurationMerge = te.Sub(timeMergeStoragesStart).Truncate(time.Millisecond)
+	confStats.DurationTotal = te.Sub(ts).Truncate(time.Millisecond)
+
+	return confStats, nil
+}
+
+// MergeViaAPI copies a milestone from a remote node to the target storage via the core API.
+func MergeViaAPI(
+	ctx context.Context,
+	protocolManager *protocol.Manager,
+	msIndex iotago.MilestoneIndex,
+	storeTarget *storage.Storage,
+	milestoneManager *milestonemanager.MilestoneManager,
+	client *nodeclient.Client,
+	apiParallelism int) (*ConfirmationStats, error) {
+
+	getBlockViaAPI := func(blockID iotago.BlockID) (*iotago.Block, error) {
+		ctxBlock, cancelBlock := context.WithTimeout(ctx, apiRequestTimeout)
+		defer cancelBlock()
+
+		block, err := client.BlockByBlockID(ctxBlock, blockID, protocolManager.Current())
+		if err != nil {
+			return nil, err
+		}
+
+		return block, nil
+	}
+
+	getMilestonePayloadViaAPI := func(msIndex iotago.MilestoneIndex) (*iotago.Milestone, error) {
+		ctxMilestone, cancelMilestone := context.WithTimeout(ctx, apiRequestTimeout)
+		defer cancelMilestone()
+
+		ms, err := client.MilestoneByIndex(ctxMilestone, msIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		return ms, nil
+	}
+
+	return mergeViaProxyStorage(
+		ctx,
+		protocolManager,
+		msIndex,
+		storeTarget,
+		milestoneManager,
+		getBlockViaAPI,
+		getMilestonePayloadViaAPI,
+		func(proxyStorage *ProxyStorage) dag.ParentsTraverserInterface {
+			return dag.NewConcurrentParentsTraverser(proxyStorage, apiParallelism)
+		},
+		func(proxyStorage *ProxyStorage) storage.CachedBlockFunc {
+			return proxyStorage.CachedBlock
+		},
+	)
+}
+
+// MergeViaStorage copies a milestone from the source storage to the target storage.
+func MergeViaStorage(
+	ctx context.Context,
+	protocolManager *protocol.Manager,
+	msIndex iotago.MilestoneIndex,
+	storeSource *storage.Storage,
+	storeTarget *storage.Storage,
+	milestoneManager *milestonemanager.MilestoneManager) (*ConfirmationStats, error) {
+
+	getMilestonePayloadFromStorage := func(msIndex iotago.MilestoneIndex) (*iotago.Milestone, error) {
+		cachedMilestone := storeSource.CachedMilestoneByIndexOrNil(msIndex) // milestone +1
+		if cachedMilestone == nil {
+			return nil, fmt.Errorf("milestone not found! %d", msIndex)
+		}
+		defer cachedMilestone.Release(true) // milestone -1
+
+		return cachedMilestone.Milestone().Milestone(), nil
+	}
+
+	return mergeViaProxyStorage(
+		ctx,
+		protocolManager,
+		msIndex,
+		storeTarget,
+		milestoneManager,
+		storeSource.Block,
+		getMilestonePayloadFromStorage,
+		func(_ *ProxyStorage) dag.ParentsTraverserInterface {
+			return dag.NewConcurrentParentsTraverser(storeSource)
+		},
+		func(_ *ProxyStorage) storage.CachedBlockFunc {
+			return storeSource.CachedBlock
+		},
+	)
+}
@@ -0,0 +1,181 @@
+package merger
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	hivedb "github.com/iotaledger/hive.go/kvstore/database"
+	"github.com/iotaledger/hive.go/serializer/v2"
+	"github.com/iotaledger/hornet/v2/pkg/database"
+	"github.com/iotaledger/hornet/v2/pkg/model/milestonemanager"
+	"github.com/iotaledger/hornet/v2/pkg/model/storage"
+	"github.com/iotaledger/inx-app/pkg/httpserver"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+const (
+	// mergeBatchSize is the amount of entries that are written to the target store in a single batch.
+	mergeBatchSize = 10000
+)
+
+type StoreBlockInterface interface {
+	StoreBlockIfAbsent(block *storage.Block) (cachedBlock *storage.CachedBlock, newlyAdded bool)
+	StoreChild(parentBlockID iotago.BlockID, childBlockID iotago.BlockID) *storage.CachedChild
+	StoreMilestoneIfAbsent(milestonePayload *iotago.Milestone) (*storage.CachedMilestone, bool)
+}
+
+// StoreBlock adds a new block to the storage,
+// including all additional information like
+// metadata, children, indexation and milestone entries.
+// block +1.
+func StoreBlock(protoParams *iotago.ProtocolParameters, dbStorage StoreBlockInterface, milestoneManager *milestonemanager.MilestoneManager, blk *iotago.Block) (*storage.CachedBlock, error) {
+
+	block, err := storage.NewBlock(blk, serializer.DeSeriModePerformValidation, protoParams)
+	if err != nil {
+		return nil, errors.WithMessagef(httpserver.ErrInvalidParameter, "invalid block, error: %s", err)
+	}
+
+	cachedBlock, isNew := dbStorage.StoreBlockIfAbsent(block) // block +1
+	if !isNew {
+		// no need to process known blocks
+		return cachedBlock, nil
+	}
+
+	for _, parent := range block.Parents() {
+		dbStorage.StoreChild(parent, cachedBlock.Block().BlockID()).Release(true) // child +-0
+	}
+
+	if milestonePayload := milestoneManager.VerifyMilestoneBlock(block.Block()); milestonePayload != nil {
+		cachedMilestone, _ := dbStorage.StoreMilestoneIfAbsent(milestonePayload) // milestone +1
+
+		// Force release to store milestones without caching
+		cachedMilestone.Release(true) // milestone -1
+	}
+
+	return cachedBlock, nil
+}
+
+type GetBlockFunc func(blockID iotago.BlockID) (*iotago.Block, error)
+
+// ProxyStorage is used to temporarily store changes to an intermediate storage,
+// which then can be merged with the target store in a single commit.
+type ProxyStorage struct {
+	protoParams      *iotago.ProtocolParameters
+	storeTarget      *storage.Storage
+	storeProxy       *storage.Storage
+	milestoneManager *milestonemanager.MilestoneManager
+	getBlockFunc     GetBlockFunc
+}
+
+func NewProxyStorage(
+	protoParams *iotago.ProtocolParameters,
+	storeTarget *storage.Storage,
+	milestoneManager *milestonemanager.MilestoneManager,
+	getBlockFunc GetBlockFunc) (*ProxyStorage, error) {
+
+	storeProxyTangle, err := database.StoreWithDefaultSettings("", true, hivedb.EngineMapDB, hivedb.EngineMapDB)
+	if err != nil {
+		return nil, errors.Wrap(err, "proxy tangle database initialization failed")
+	}
+
+	storeProxyUTXO, err := database.StoreWithDefaultSettings("", true, hivedb.EngineMapDB, hivedb.EngineMapDB)
+	if err != nil {
+		return nil, errors.Wrap(err, "proxy utxo database initialization failed")
+	}
+
+	storeProxy, err := storage.New(storeProxyTangle, storeProxyUTXO)
+	if err != nil {
+		return nil, errors.Wrap(err, "proxy storage initialization failed")
+	}
+
+	return &ProxyStorage{
+		protoParams:      protoParams,
+		storeTarget:      storeTarget,
+		storeProxy:       storeProxy,
+		milestoneManager: milestoneManager,
+		getBlockFunc:     getBlockFunc,
+	}, nil
+}
+
+// CachedBlock returns a cached block object.
+// block +1.
+func (s *ProxyStorage) CachedBlock(blockID iotago.BlockID) (*storage.CachedBlock, error) {
+	if !s.storeTarget.ContainsBlock(blockID) {
+		if !s.storeProxy.ContainsBlock(blockID) {
+			block, err := s.getBlockFunc(blockID)
+			if err != nil {
+				return nil, err
+			}
+
+			cachedBlock, err := StoreBlock(s.protoParams, s.storeProxy, s.milestoneManager, block) // block +1
+			if err != nil {
+				return nil, err
+			}
+
+			// set the new block as solid
+			cachedBlockMeta := cachedBlock.CachedMetadata() // meta +1
+			defer cachedBlockMeta.Release(true)             // meta -1
+
+			cachedBlockMeta.Metadata().SetSolid(true)
+
+			return cachedBlock, nil
+		}
+
+		return s.storeProxy.CachedBlock(blockID) // block +1
+	}
+
+	return s.storeTarget.CachedBlock(blockID) // block +1
+}
+
+// CachedBlockMetadata returns a cached block metadata object.
+// meta +1.
+func (s *ProxyStorage) CachedBlockMetadata(blockID iotago.BlockID) (*storage.CachedMetadata, error) {
+	cachedBlock, err := s.CachedBlock(blockID) // block +1
+	if err != nil {
+		return nil, err
+	}
+	if cachedBlock == nil {
+		//nolint:nilnil // nil, nil is ok in this context, even if it is not go idiomatic
+		return nil, nil
+	}
+	defer cachedBlock.Release(true) // block -1
+
+	return cachedBlock.CachedMetadata(), nil // meta +1
+}
+
+func (s *ProxyStorage) SolidEntryPointsContain(blockID iotago.BlockID) (bool, error) {
+	return s.storeTarget.SolidEntryPointsContain(blockID)
+}
+
+func (s *ProxyStorage) SolidEntryPointsIndex(blockID iotago.BlockID) (iotago.MilestoneIndex, bool, error) {
+	return s.storeTarget.SolidEntryPointsIndex(blockID)
+}
+
+func (s *ProxyStorage) MergeStorages() error {
+
+	// first flush both storages
+	s.storeProxy.FlushStorages()
+	s.storeTarget.FlushStorages()
+
+	// copy all existing keys with values from the proxy storage to the target storage
+	return kvstore.CopyBatched(s.storeProxy.TangleStore(), s.storeTarget.TangleStore(), mergeBatchSize)
+}
+
+// Cleanup shuts down, flushes and closes the proxy store.
+func (s *ProxyStorage) Cleanup() {
+	_ = s.storeProxy.Shutdown()
+}
+
+// StoreBlockInterface
+
+func (s *ProxyStorage) StoreBlockIfAbsent(block *storage.Block) (cachedBlock *storage.CachedBlock, newlyAdded bool) {
+	return s.storeProxy.StoreBlockIfAbsent(block)
+}
+
+func (s *ProxyStorage) StoreChild(parentBlockID iotago.BlockID, childBlockID iotago.BlockID) *storage.CachedChild {
+	return s.storeProxy.StoreChild(parentBlockID, childBlockID)
+}
+
+func (s *ProxyStorage) StoreMilestoneIfAbsent(milestone *iotago.Milestone) (*storage.CachedMilestone, bool) {
+	return s.storeProxy.StoreMilestoneIfAbsent(milestone)
+}
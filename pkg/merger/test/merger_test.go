@@ -0,0 +1,139 @@
+//nolint:forcetypeassert,varnamelen,revive,exhaustruct,gosec // we don't care about these linters in test cases
+package test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/hive.go/serializer/v2"
+	"github.com/iotaledger/hornet/v2/pkg/merger"
+	"github.com/iotaledger/hornet/v2/pkg/model/milestonemanager"
+	"github.com/iotaledger/hornet/v2/pkg/model/storage"
+	"github.com/iotaledger/hornet/v2/pkg/model/utxo"
+	"github.com/iotaledger/hornet/v2/pkg/protocol"
+	"github.com/iotaledger/hornet/v2/pkg/testsuite"
+	"github.com/iotaledger/hornet/v2/pkg/testsuite/utils"
+	iotago "github.com/iotaledger/iota.go/v3"
+	"github.com/iotaledger/iota.go/v3/keymanager"
+)
+
+const (
+	ProtocolVersion = 2
+	MinPoWScore     = 1
+	BelowMaxDepth   = 15
+)
+
+var (
+	seed1, _ = hex.DecodeString("96d9ff7a79e4b0a5f3e5848ae7867064402da92a62eabb4ebbe463f12d1f3b1aace1775488f51cb1e3a80732a03ef60b111d6833ab605aa9f8faebeb33bbe3d9")
+	seed2, _ = hex.DecodeString("b15209ddc93cbdb600137ea6a8f88cdd7c5d480d5815c9352a0fb5c4e4b86f7151dcb44c2ba635657a2df5a8fd48cb9bab674a9eceea527dbbb254ef8c9f9cd7")
+)
+
+// newTargetStorage creates a storage that contains the same genesis ledger state as the test environment.
+func newTargetStorage(t *testing.T, te *testsuite.TestEnvironment) (*storage.Storage, *protocol.Manager) {
+
+	target, err := storage.New(mapdb.NewMapDB(), mapdb.NewMapDB(), testsuite.TestProfileCaches)
+	require.NoError(t, err)
+
+	target.SolidEntryPointsAddWithoutLocking(iotago.EmptyBlockID(), 0)
+	require.NoError(t, target.SetInitialSnapshotInfo(0, 0, 0, 0, time.Now()))
+
+	protoParamsBytes, err := te.ProtocolParameters().Serialize(serializer.DeSeriModeNoValidation, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, target.StoreProtocolParametersMilestoneOption(&iotago.ProtocolParamsMilestoneOpt{
+		TargetMilestoneIndex: 0,
+		ProtocolVersion:      te.ProtocolParameters().Version,
+		Params:               protoParamsBytes,
+	}))
+
+	require.NoError(t, target.UTXOManager().AddUnspentOutput(te.GenesisOutput))
+	require.NoError(t, target.UTXOManager().StoreUnspentTreasuryOutput(&utxo.TreasuryOutput{MilestoneID: [32]byte{}, Amount: 0}))
+
+	protocolManager, err := protocol.NewManager(target, 0)
+	require.NoError(t, err)
+
+	return target, protocolManager
+}
+
+func TestMergeViaStorage(t *testing.T) {
+
+	seed1Wallet := utils.NewHDWallet("Seed1", seed1, 0)
+	seed2Wallet := utils.NewHDWallet("Seed2", seed2, 0)
+
+	te := testsuite.SetupTestEnvironment(t, seed1Wallet.Address(), 2, ProtocolVersion, BelowMaxDepth, MinPoWScore, false)
+	defer te.CleanupTestEnvironment(true)
+
+	seed1Wallet.BookOutput(te.GenesisOutput)
+
+	blockA := te.NewBlockBuilder("A").
+		Parents(te.LastMilestoneParents()).
+		FromWallet(seed1Wallet).
+		Amount(te.ProtocolParameters().TokenSupply).
+		BuildTransactionToWallet(seed2Wallet).
+		Store().
+		BookOnWallets()
+
+	te.IssueAndConfirmMilestoneOnTips(iotago.BlockIDs{blockA.StoredBlockID()}, false)
+
+	target, protocolManager := newTargetStorage(t, te)
+	defer func() { require.NoError(t, target.Shutdown()) }()
+
+	for msIndex := iotago.MilestoneIndex(1); msIndex <= te.LastMilestoneIndex(); msIndex++ {
+		confStats, err := merger.MergeViaStorage(context.Background(), protocolManager, msIndex, te.Storage(), target, te.MilestoneManager())
+		require.NoError(t, err)
+		require.Equal(t, msIndex, confStats.MilestoneIndex)
+	}
+
+	// the ledger state of the target storage matches the source storage
+	ledgerIndex, err := target.UTXOManager().ReadLedgerIndex()
+	require.NoError(t, err)
+	require.Equal(t, te.LastMilestoneIndex(), ledgerIndex)
+
+	sourceHash, err := te.UTXOManager().LedgerStateSHA256Sum()
+	require.NoError(t, err)
+	targetHash, err := target.UTXOManager().LedgerStateSHA256Sum()
+	require.NoError(t, err)
+	require.Equal(t, sourceHash, targetHash)
+
+	// the milestone cone and the milestone payload were merged into the target storage
+	require.True(t, target.ContainsBlock(blockA.StoredBlockID()))
+	require.True(t, target.ContainsMilestoneIndex(te.LastMilestoneIndex()))
+
+	cachedBlockMeta := target.CachedBlockMetadataOrNil(blockA.StoredBlockID()) // meta +1
+	require.NotNil(t, cachedBlockMeta)
+	referenced, at := cachedBlockMeta.Metadata().ReferencedWithIndex()
+	cachedBlockMeta.Release(true) // meta -1
+	require.True(t, referenced)
+	require.Equal(t, te.LastMilestoneIndex(), at)
+}
+
+func TestMergeViaStorageInvalidMilestone(t *testing.T) {
+
+	seed1Wallet := utils.NewHDWallet("Seed1", seed1, 0)
+
+	te := testsuite.SetupTestEnvironment(t, seed1Wallet.Address(), 1, ProtocolVersion, BelowMaxDepth, MinPoWScore, false)
+	defer te.CleanupTestEnvironment(true)
+
+	target, protocolManager := newTargetStorage(t, te)
+	defer func() { require.NoError(t, target.Shutdown()) }()
+
+	// the milestones of the test environment are not signed with this key
+	pubKey, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	keyManager := keymanager.New()
+	keyManager.AddKeyRange(pubKey, 0, 0)
+
+	_, err = merger.MergeViaStorage(context.Background(), protocolManager, 1, te.Storage(), target, milestonemanager.New(nil, nil, keyManager, 1))
+	require.Error(t, err)
+
+	ledgerIndex, err := target.UTXOManager().ReadLedgerIndex()
+	require.NoError(t, err)
+	require.Equal(t, iotago.MilestoneIndex(0), ledgerIndex)
+}
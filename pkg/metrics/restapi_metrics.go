@@ -19,6 +19,14 @@ type RestAPIMetrics struct {
 	HTTPRequestErrorCounter atomic.Uint32
 	// The total number of completed PoW requests.
 	PoWCompletedCounter atomic.Uint32
+	// The current number of event stream subscribers.
+	EventStreamSubscribers atomic.Int32
+	// The total number of events that were dropped for slow event stream subscribers.
+	EventStreamDroppedCounter atomic.Uint32
+	// The total number of requests that were rejected by the rate limiter.
+	RateLimitedCounter atomic.Uint32
+	// The total number of requests to expensive routes that were rejected by the rate limiter.
+	RateLimitedExpensiveCounter atomic.Uint32
 
 	Events *RestAPIEvents
 }
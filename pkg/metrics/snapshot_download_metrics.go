@@ -0,0 +1,25 @@
+package metrics
+
+import (
+	"go.uber.org/atomic"
+)
+
+// SnapshotDownloadMetrics defines metrics about the snapshot file downloads over the entire runtime of the node.
+type SnapshotDownloadMetrics struct {
+	// Whether a snapshot file download is running or not.
+	DownloadRunning atomic.Bool
+	// The size of the snapshot file that is currently downloaded.
+	BytesExpected atomic.Uint64
+	// The bytes of the snapshot file that is currently downloaded that are already written to disk.
+	BytesDownloaded atomic.Uint64
+	// The total number of bytes that were reused from partially downloaded snapshot files.
+	BytesResumed atomic.Uint64
+	// The total number of successfully downloaded snapshot files.
+	FilesDownloaded atomic.Uint32
+	// The total number of retried range requests.
+	Retries atomic.Uint32
+	// The total number of failovers to another mirror.
+	Failovers atomic.Uint32
+	// The total number of downloaded snapshot files that did not match the published hash.
+	VerificationFailures atomic.Uint32
+}
@@ -16,6 +16,11 @@ const (
 	// UTXOStoreKeyPrefixTreasuryOutput defines the prefix for the Treasury Output.
 	UTXOStoreKeyPrefixTreasuryOutput byte = 5
 	UTXOStoreKeyPrefixReceipts       byte = 6
+
+	// UTXOStoreKeyPrefixOutputIndex defines the prefix for the optional index of the unspent outputs.
+	UTXOStoreKeyPrefixOutputIndex byte = 7
+	// UTXOStoreKeyPrefixOutputIndexBuilt marks that the output index was completely built.
+	UTXOStoreKeyPrefixOutputIndexBuilt byte = 8
 )
 
 /*
@@ -90,4 +95,29 @@ const (
    Value:
        Receipt (iotago.ReceiptMilestoneOpt.Serialized())
                 1 byte type + X bytes
+
+   Output Index (optional):
+   ========================
+   Key:
+       UTXOStoreKeyPrefixOutputIndex + OutputIndexType + IndexKey + iotago.OutputID
+                   1 byte            +      1 byte     + X bytes  +     34 bytes
+
+       IndexKey:
+           Address:     Address (iotago.Address.Key())   33 bytes
+           AliasID:     iotago.AliasID                   32 bytes
+           NFTID:       iotago.NFTID                     32 bytes
+           FoundryID:   iotago.FoundryID                 38 bytes
+           NativeToken: iotago.NativeTokenID             38 bytes
+
+   Value:
+       Empty
+
+   Output Index Built:
+   ===================
+   Key:
+       UTXOStoreKeyPrefixOutputIndexBuilt
+                    1 byte
+
+   Value:
+       Empty
 */
@@ -0,0 +1,333 @@
+package utxo
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+var (
+	// ErrHistoricLedgerStateUnavailable is returned if the ledger state at a milestone can't be reconstructed,
+	// because the milestone diffs were already pruned or the milestone was not confirmed yet.
+	ErrHistoricLedgerStateUnavailable = errors.New("historic ledger state unavailable")
+)
+
+// checkHistoricLedgerStateAvailableWithoutLocking checks whether the ledger state at the given milestone can be reconstructed.
+// The milestone diffs and the spent outputs are deleted during pruning, so the ledger state is only available
+// for milestones that are not older than the pruning index.
+func (u *Manager) checkHistoricLedgerStateAvailableWithoutLocking(msIndex iotago.MilestoneIndex) error {
+	ledgerIndex, err := u.ReadLedgerIndexWithoutLocking()
+	if err != nil {
+		return err
+	}
+
+	if msIndex > ledgerIndex {
+		return errors.WithMessagef(ErrHistoricLedgerStateUnavailable, "milestone index %d is newer than the ledger index %d", msIndex, ledgerIndex)
+	}
+
+	if msIndex < ledgerIndex {
+		// the diffs are pruned in ascending order, so all later diffs exist if the next diff exists.
+		contains, err := u.utxoStorage.Has(milestoneDiffKeyForIndex(msIndex + 1))
+		if err != nil {
+			return err
+		}
+
+		if !contains {
+			return errors.WithMessagef(ErrHistoricLedgerStateUnavailable, "milestone diff %d was already pruned", msIndex+1)
+		}
+	}
+
+	return nil
+}
+
+const (
+	// spentOutputsChunkSize is the amount of spent outputs that are read at once while the ledger state at a milestone is reconstructed.
+	spentOutputsChunkSize = 1000
+)
+
+// spentAfterMilestoneIterator walks the spent outputs ordered by output ID and returns the IDs of the outputs
+// that existed at the given milestone, but were spent afterwards.
+// The spent outputs are read in chunks only as far as the iteration over the unspent outputs proceeds,
+// so every spent output is read at most once per query.
+type spentAfterMilestoneIterator struct {
+	utxoManager *Manager
+	msIndex     iotago.MilestoneIndex
+	// the output ID the next chunk starts at (inclusive), all lower output IDs were already read.
+	nextOutputID *iotago.OutputID
+	// whether the next chunk was already read once.
+	started bool
+	// whether all spent outputs were read.
+	done bool
+	// the matching output IDs of the read chunks that were not returned yet.
+	outputIDs iotago.OutputIDs
+}
+
+func (u *Manager) newSpentAfterMilestoneIterator(msIndex iotago.MilestoneIndex, startOutputID *iotago.OutputID) *spentAfterMilestoneIterator {
+	return &spentAfterMilestoneIterator{
+		utxoManager:  u,
+		msIndex:      msIndex,
+		nextOutputID: startOutputID,
+		outputIDs:    make(iotago.OutputIDs, 0),
+	}
+}
+
+// readChunkWithoutLocking reads the next chunk of spent outputs.
+func (it *spentAfterMilestoneIterator) readChunkWithoutLocking() error {
+	var readCount int
+	var nextOutputID *iotago.OutputID
+
+	var innerErr error
+	if err := it.utxoManager.iterateKeysFrom([]byte{UTXOStoreKeyPrefixOutputSpent}, it.nextOutputID, func(key kvstore.Key) bool {
+		outputID, err := outputIDFromDatabaseKey(key)
+		if err != nil {
+			innerErr = err
+
+			return false
+		}
+
+		if readCount >= spentOutputsChunkSize {
+			// the next chunk starts at this output
+			nextOutputID = &outputID
+
+			return false
+		}
+		readCount++
+
+		spent, err := it.utxoManager.ReadSpentForOutputIDWithoutLocking(outputID)
+		if err != nil {
+			innerErr = err
+
+			return false
+		}
+
+		if spent.MilestoneIndexSpent() <= it.msIndex || spent.Output().MilestoneIndexBooked() > it.msIndex {
+			// the output was already spent at the milestone or it was created after the milestone
+			return true
+		}
+
+		it.outputIDs = append(it.outputIDs, outputID)
+
+		return true
+	}); err != nil {
+		return err
+	}
+
+	if innerErr != nil {
+		return innerErr
+	}
+
+	it.started = true
+	it.nextOutputID = nextOutputID
+	it.done = nextOutputID == nil
+
+	return nil
+}
+
+// nextBeforeWithoutLocking returns the next output ID that is lower than the given output ID.
+// If no output ID is given, the next output ID is returned without an upper bound.
+func (it *spentAfterMilestoneIterator) nextBeforeWithoutLocking(outputID *iotago.OutputID) (iotago.OutputID, bool, error) {
+	for {
+		if len(it.outputIDs) > 0 {
+			if outputID != nil && bytes.Compare(it.outputIDs[0][:], outputID[:]) >= 0 {
+				return iotago.OutputID{}, false, nil
+			}
+
+			next := it.outputIDs[0]
+			it.outputIDs = it.outputIDs[1:]
+
+			return next, true, nil
+		}
+
+		if it.done || (it.started && outputID != nil && bytes.Compare(it.nextOutputID[:], outputID[:]) >= 0) {
+			// all spent outputs lower than the given output ID were already read
+			return iotago.OutputID{}, false, nil
+		}
+
+		if err := it.readChunkWithoutLocking(); err != nil {
+			return iotago.OutputID{}, false, err
+		}
+	}
+}
+
+// ReadOutputAtMilestoneWithoutLocking returns the output with the given ID and its spent status at the given milestone.
+// The returned spent is nil if the output was unspent at the milestone.
+// kvstore.ErrKeyNotFound is returned if the output did not exist at the milestone.
+func (u *Manager) ReadOutputAtMilestoneWithoutLocking(outputID iotago.OutputID, msIndex iotago.MilestoneIndex) (*Output, *Spent, error) {
+
+	if err := u.checkHistoricLedgerStateAvailableWithoutLocking(msIndex); err != nil {
+		return nil, nil, err
+	}
+
+	output, err := u.ReadOutputByOutputIDWithoutLocking(outputID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if output.MilestoneIndexBooked() > msIndex {
+		// the output was created after the milestone
+		return nil, nil, kvstore.ErrKeyNotFound
+	}
+
+	spent, err := u.ReadSpentForOutputIDWithoutLocking(outputID)
+	if err != nil {
+		if errors.Is(err, kvstore.ErrKeyNotFound) {
+			return output, nil, nil
+		}
+
+		return nil, nil, err
+	}
+
+	if spent.MilestoneIndexSpent() > msIndex {
+		// the output was spent after the milestone
+		return output, nil, nil
+	}
+
+	return output, spent, nil
+}
+
+// ForEachUnspentOutputIDAtMilestone iterates over the IDs of all outputs that were unspent at the given milestone, ordered by output ID.
+// The ledger state is reconstructed from the unspent outputs that were created until the milestone
+// and the outputs that existed at the milestone, but were spent afterwards.
+// If a start output ID is given, the iteration starts at that output ID (inclusive),
+// so an iteration can be continued page by page without reading the outputs of the previous pages again.
+func (u *Manager) ForEachUnspentOutputIDAtMilestone(msIndex iotago.MilestoneIndex, startOutputID *iotago.OutputID, consumer OutputIDConsumer, options ...IterateOption) error {
+	opt := iterateOptions(options)
+
+	if opt.readLockLedger {
+		u.ReadLockLedger()
+		defer u.ReadUnlockLedger()
+	}
+
+	if err := u.checkHistoricLedgerStateAvailableWithoutLocking(msIndex); err != nil {
+		return err
+	}
+
+	// the outputs that were spent after the milestone are merged into the ordered unspent outputs
+	spentOutputIDs := u.newSpentAfterMilestoneIterator(msIndex, startOutputID)
+
+	var i int
+	consume := func(outputID iotago.OutputID) bool {
+		if (opt.maxResultCount > 0) && (i >= opt.maxResultCount) {
+			return false
+		}
+		i++
+
+		return consumer(outputID)
+	}
+
+	var innerErr error
+	var stopped bool
+	if err := u.iterateKeysFrom([]byte{UTXOStoreKeyPrefixOutputUnspent}, startOutputID, func(key kvstore.Key) bool {
+		outputID, err := outputIDFromDatabaseKey(key)
+		if err != nil {
+			innerErr = err
+
+			return false
+		}
+
+		output, err := u.ReadOutputByOutputIDWithoutLocking(outputID)
+		if err != nil {
+			innerErr = err
+
+			return false
+		}
+
+		if output.MilestoneIndexBooked() > msIndex {
+			// the output was created after the milestone
+			return true
+		}
+
+		for {
+			spentOutputID, found, err := spentOutputIDs.nextBeforeWithoutLocking(&outputID)
+			if err != nil {
+				innerErr = err
+
+				return false
+			}
+
+			if !found {
+				break
+			}
+
+			if !consume(spentOutputID) {
+				stopped = true
+
+				return false
+			}
+		}
+
+		if !consume(outputID) {
+			stopped = true
+
+			return false
+		}
+
+		return true
+	}); err != nil {
+		return err
+	}
+
+	if innerErr != nil {
+		return innerErr
+	}
+
+	if stopped {
+		return nil
+	}
+
+	for {
+		spentOutputID, found, err := spentOutputIDs.nextBeforeWithoutLocking(nil)
+		if err != nil {
+			return err
+		}
+
+		if !found || !consume(spentOutputID) {
+			return nil
+		}
+	}
+}
+
+// ForEachUnspentOutputAtMilestone iterates over all outputs that were unspent at the given milestone, ordered by output ID.
+// The ledger state is reconstructed by merging the unspent outputs that were created until the milestone
+// with the spent outputs that existed at the milestone, but were spent afterwards (see ForEachUnspentOutputIDAtMilestone).
+// If a start output ID is given, the iteration starts at that output ID (inclusive).
+func (u *Manager) ForEachUnspentOutputAtMilestone(msIndex iotago.MilestoneIndex, startOutputID *iotago.OutputID, consumer OutputConsumer, options ...IterateOption) error {
+
+	var innerErr error
+	if err := u.ForEachUnspentOutputIDAtMilestone(msIndex, startOutputID, func(outputID iotago.OutputID) bool {
+		output, err := u.ReadOutputByOutputIDWithoutLocking(outputID)
+		if err != nil {
+			innerErr = err
+
+			return false
+		}
+
+		return consumer(output)
+	}, options...); err != nil {
+		return err
+	}
+
+	return innerErr
+}
+
+// ComputeLedgerBalanceAtMilestone computes the balance and the amount of unspent outputs of the ledger at the given milestone.
+func (u *Manager) ComputeLedgerBalanceAtMilestone(msIndex iotago.MilestoneIndex, options ...IterateOption) (balance uint64, count int, err error) {
+
+	balance = 0
+	count = 0
+	consumerFunc := func(output *Output) bool {
+		balance += output.Deposit()
+		count++
+
+		return true
+	}
+
+	if err := u.ForEachUnspentOutputAtMilestone(msIndex, nil, consumerFunc, options...); err != nil {
+		return 0, 0, err
+	}
+
+	return balance, count, nil
+}
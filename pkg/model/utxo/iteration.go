@@ -1,7 +1,10 @@
 package utxo
 
 import (
+	"bytes"
+
 	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/serializer/v2/byteutils"
 	iotago "github.com/iotaledger/iota.go/v3"
 )
 
@@ -18,6 +21,13 @@ func ReadLockLedger(lockLedger bool) IterateOption {
 	}
 }
 
+// MaxResultCount limits the amount of elements that are passed to the consumer.
+func MaxResultCount(maxResultCount int) IterateOption {
+	return func(args *IterateOptions) {
+		args.maxResultCount = maxResultCount
+	}
+}
+
 func iterateOptions(optionalOptions []IterateOption) *IterateOptions {
 	result := &IterateOptions{
 		readLockLedger: true,
@@ -31,6 +41,35 @@ func iterateOptions(optionalOptions []IterateOption) *IterateOptions {
 	return result
 }
 
+// keySeeker is implemented by stores that are able to start an iteration at a given key.
+type keySeeker interface {
+	IterateKeysFrom(prefix kvstore.KeyPrefix, start kvstore.Key, consumerFunc kvstore.IteratorKeyConsumerFunc) error
+}
+
+// iterateKeysFrom iterates over all keys with the given prefix, ordered by output ID.
+// If a start output ID is given, the iteration starts at that output ID (inclusive).
+// The iteration is started directly at the output ID if the store supports it.
+func (u *Manager) iterateKeysFrom(prefix kvstore.KeyPrefix, startOutputID *iotago.OutputID, consumerFunc kvstore.IteratorKeyConsumerFunc) error {
+	if startOutputID == nil {
+		return u.utxoStorage.IterateKeys(prefix, consumerFunc)
+	}
+
+	start := byteutils.ConcatBytes(prefix, startOutputID[:])
+
+	if seeker, ok := u.utxoStorage.(keySeeker); ok {
+		return seeker.IterateKeysFrom(prefix, start, consumerFunc)
+	}
+
+	return u.utxoStorage.IterateKeys(prefix, func(key kvstore.Key) bool {
+		if bytes.Compare(key, start) < 0 {
+			// skip the keys before the start
+			return true
+		}
+
+		return consumerFunc(key)
+	})
+}
+
 func (u *Manager) ForEachOutput(consumer OutputConsumer, options ...IterateOption) error {
 	opt := iterateOptions(options)
 
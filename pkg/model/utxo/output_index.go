@@ -0,0 +1,345 @@
+package utxo
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/serializer/v2/marshalutil"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+var (
+	// ErrOutputIndexDisabled is returned if the output index is queried but it is not enabled.
+	ErrOutputIndexDisabled = errors.New("output index is disabled")
+)
+
+// OutputIndexType defines the type of the key an output is indexed with.
+type OutputIndexType byte
+
+const (
+	// OutputIndexTypeAddress indexes outputs by the addresses that are able to unlock them.
+	OutputIndexTypeAddress OutputIndexType = iota
+	// OutputIndexTypeAliasID indexes alias outputs by their alias ID.
+	OutputIndexTypeAliasID
+	// OutputIndexTypeNFTID indexes NFT outputs by their NFT ID.
+	OutputIndexTypeNFTID
+	// OutputIndexTypeFoundryID indexes foundry outputs by their foundry ID.
+	OutputIndexTypeFoundryID
+	// OutputIndexTypeNativeToken indexes outputs by the native tokens they hold.
+	OutputIndexTypeNativeToken
+)
+
+// OutputIndexKey is the key unspent outputs are indexed with.
+// It consists of the OutputIndexType and the type specific key.
+type OutputIndexKey []byte
+
+func newOutputIndexKey(indexType OutputIndexType, key []byte) OutputIndexKey {
+	ms := marshalutil.New(1 + len(key))
+	ms.WriteByte(byte(indexType)) // 1 byte
+	ms.WriteBytes(key)            // X bytes
+
+	return ms.Bytes()
+}
+
+// OutputIndexKeyAddress returns the OutputIndexKey for outputs that can be unlocked by the given address.
+func OutputIndexKeyAddress(address iotago.Address) OutputIndexKey {
+	return newOutputIndexKey(OutputIndexTypeAddress, []byte(address.Key()))
+}
+
+// OutputIndexKeyAliasID returns the OutputIndexKey for the alias output with the given alias ID.
+func OutputIndexKeyAliasID(aliasID iotago.AliasID) OutputIndexKey {
+	return newOutputIndexKey(OutputIndexTypeAliasID, aliasID[:])
+}
+
+// OutputIndexKeyNFTID returns the OutputIndexKey for the NFT output with the given NFT ID.
+func OutputIndexKeyNFTID(nftID iotago.NFTID) OutputIndexKey {
+	return newOutputIndexKey(OutputIndexTypeNFTID, nftID[:])
+}
+
+// OutputIndexKeyFoundryID returns the OutputIndexKey for the foundry output with the given foundry ID.
+func OutputIndexKeyFoundryID(foundryID iotago.FoundryID) OutputIndexKey {
+	return newOutputIndexKey(OutputIndexTypeFoundryID, foundryID[:])
+}
+
+// OutputIndexKeyNativeToken returns the OutputIndexKey for outputs that hold the given native token.
+func OutputIndexKeyNativeToken(nativeTokenID iotago.NativeTokenID) OutputIndexKey {
+	return newOutputIndexKey(OutputIndexTypeNativeToken, nativeTokenID[:])
+}
+
+// UnlockAddresses returns all addresses that are able to unlock the given output.
+func UnlockAddresses(output iotago.Output) []iotago.Address {
+	conditions := output.UnlockConditionSet()
+	if conditions == nil {
+		return nil
+	}
+
+	var addresses []iotago.Address
+	if cond := conditions.Address(); cond != nil {
+		addresses = append(addresses, cond.Address)
+	}
+	if cond := conditions.StateControllerAddress(); cond != nil {
+		addresses = append(addresses, cond.Address)
+	}
+	if cond := conditions.GovernorAddress(); cond != nil {
+		addresses = append(addresses, cond.Address)
+	}
+	if cond := conditions.ImmutableAlias(); cond != nil {
+		addresses = append(addresses, cond.Address)
+	}
+	if cond := conditions.Expiration(); cond != nil {
+		addresses = append(addresses, cond.ReturnAddress)
+	}
+
+	return addresses
+}
+
+// OwnerAddress returns the address that owns the given output at the given milestone timestamp.
+// Only outputs with an address unlock condition are owned by an address.
+// After the expiration of an expiration unlock condition, the return address is the owner.
+func OwnerAddress(output iotago.Output, msTimestamp uint32) iotago.Address {
+	conditions := output.UnlockConditionSet()
+	if conditions == nil {
+		return nil
+	}
+
+	addressCond := conditions.Address()
+	if addressCond == nil {
+		return nil
+	}
+
+	if expirationCond := conditions.Expiration(); expirationCond != nil && expirationCond.UnixTime <= msTimestamp {
+		return expirationCond.ReturnAddress
+	}
+
+	return addressCond.Address
+}
+
+// OutputIndexKeys returns all keys the output is indexed with.
+func (o *Output) OutputIndexKeys() ([]OutputIndexKey, error) {
+	output := o.Output()
+
+	var keys []OutputIndexKey
+	for _, address := range UnlockAddresses(output) {
+		keys = append(keys, OutputIndexKeyAddress(address))
+	}
+
+	switch chainOutput := output.(type) {
+	case *iotago.AliasOutput:
+		aliasID := chainOutput.AliasID
+		if aliasID.Empty() {
+			aliasID = iotago.AliasIDFromOutputID(o.outputID)
+		}
+		keys = append(keys, OutputIndexKeyAliasID(aliasID))
+
+	case *iotago.NFTOutput:
+		nftID := chainOutput.NFTID
+		if nftID.Empty() {
+			nftID = iotago.NFTIDFromOutputID(o.outputID)
+		}
+		keys = append(keys, OutputIndexKeyNFTID(nftID))
+
+	case *iotago.FoundryOutput:
+		foundryID, err := chainOutput.ID()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, OutputIndexKeyFoundryID(foundryID))
+	}
+
+	for _, nativeToken := range output.NativeTokenList() {
+		keys = append(keys, OutputIndexKeyNativeToken(nativeToken.ID))
+	}
+
+	return keys, nil
+}
+
+func outputIndexDatabaseKey(indexKey OutputIndexKey, outputID iotago.OutputID) []byte {
+	ms := marshalutil.New(1 + len(indexKey) + iotago.OutputIDLength)
+	ms.WriteByte(UTXOStoreKeyPrefixOutputIndex) // 1 byte
+	ms.WriteBytes(indexKey)                     // X bytes
+	ms.WriteBytes(outputID[:])                  // 34 bytes
+
+	return ms.Bytes()
+}
+
+func (u *Manager) storeOutputIndex(output *Output, mutations kvstore.BatchedMutations) error {
+	if !u.outputIndexEnabled {
+		return nil
+	}
+
+	indexKeys, err := output.OutputIndexKeys()
+	if err != nil {
+		return err
+	}
+
+	for _, indexKey := range indexKeys {
+		if err := mutations.Set(outputIndexDatabaseKey(indexKey, output.outputID), []byte{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (u *Manager) deleteOutputIndex(output *Output, mutations kvstore.BatchedMutations) error {
+	if !u.outputIndexEnabled {
+		return nil
+	}
+
+	indexKeys, err := output.OutputIndexKeys()
+	if err != nil {
+		return err
+	}
+
+	for _, indexKey := range indexKeys {
+		if err := mutations.Delete(outputIndexDatabaseKey(indexKey, output.outputID)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// OutputIndexEnabled returns whether the output index is enabled.
+func (u *Manager) OutputIndexEnabled() bool {
+	u.ReadLockLedger()
+	defer u.ReadUnlockLedger()
+
+	return u.outputIndexEnabled
+}
+
+// outputIndexBuilt returns whether the output index was completely built for the current ledger state.
+func (u *Manager) outputIndexBuilt() (bool, error) {
+	return u.utxoStorage.Has([]byte{UTXOStoreKeyPrefixOutputIndexBuilt})
+}
+
+// EnableOutputIndex enables the output index of the unspent outputs.
+// If the index was not built for the current ledger state yet, it is built from the unspent outputs.
+// It returns true if the index was (re)built.
+func (u *Manager) EnableOutputIndex() (bool, error) {
+	u.WriteLockLedger()
+	defer u.WriteUnlockLedger()
+
+	u.outputIndexEnabled = true
+
+	built, err := u.outputIndexBuilt()
+	if err != nil {
+		return false, err
+	}
+
+	if built {
+		return false, nil
+	}
+
+	// remove the entries of a previous incomplete build
+	if err := u.utxoStorage.DeletePrefix([]byte{UTXOStoreKeyPrefixOutputIndex}); err != nil {
+		return false, err
+	}
+
+	mutations, err := u.utxoStorage.Batched()
+	if err != nil {
+		return false, err
+	}
+
+	var innerErr error
+	if err := u.ForEachUnspentOutput(func(output *Output) bool {
+		if err := u.storeOutputIndex(output, mutations); err != nil {
+			innerErr = err
+
+			return false
+		}
+
+		return true
+	}, ReadLockLedger(false)); err != nil {
+		mutations.Cancel()
+
+		return false, err
+	}
+
+	if innerErr != nil {
+		mutations.Cancel()
+
+		return false, innerErr
+	}
+
+	if err := mutations.Set([]byte{UTXOStoreKeyPrefixOutputIndexBuilt}, []byte{}); err != nil {
+		mutations.Cancel()
+
+		return false, err
+	}
+
+	if err := mutations.Commit(); err != nil {
+		return false, err
+	}
+
+	return true, u.utxoStorage.Flush()
+}
+
+// DisableOutputIndex disables the output index and removes all index entries from the database.
+// Otherwise the index would be outdated if it gets enabled again.
+func (u *Manager) DisableOutputIndex() error {
+	u.WriteLockLedger()
+	defer u.WriteUnlockLedger()
+
+	u.outputIndexEnabled = false
+
+	if err := u.utxoStorage.DeletePrefix([]byte{UTXOStoreKeyPrefixOutputIndexBuilt}); err != nil {
+		return err
+	}
+
+	return u.utxoStorage.DeletePrefix([]byte{UTXOStoreKeyPrefixOutputIndex})
+}
+
+// ForEachUnspentOutputIDByIndexKey iterates over the IDs of all unspent outputs that are indexed with the given key, ordered by output ID.
+// If a start output ID is given, the iteration starts at that output ID (inclusive).
+func (u *Manager) ForEachUnspentOutputIDByIndexKey(indexKey OutputIndexKey, startOutputID *iotago.OutputID, consumer OutputIDConsumer, options ...IterateOption) error {
+	opt := iterateOptions(options)
+
+	if opt.readLockLedger {
+		u.ReadLockLedger()
+		defer u.ReadUnlockLedger()
+	}
+
+	if !u.outputIndexEnabled {
+		return ErrOutputIndexDisabled
+	}
+
+	prefix := make([]byte, 0, 1+len(indexKey))
+	prefix = append(prefix, UTXOStoreKeyPrefixOutputIndex)
+	prefix = append(prefix, indexKey...)
+
+	var i int
+
+	return u.iterateKeysFrom(prefix, startOutputID, func(key kvstore.Key) bool {
+		outputID := iotago.OutputID{}
+		copy(outputID[:], key[len(prefix):])
+
+		if (opt.maxResultCount > 0) && (i >= opt.maxResultCount) {
+			return false
+		}
+		i++
+
+		return consumer(outputID)
+	})
+}
+
+// ForEachUnspentOutputByIndexKey iterates over all unspent outputs that are indexed with the given key, ordered by output ID.
+// If a start output ID is given, the iteration starts at that output ID (inclusive).
+func (u *Manager) ForEachUnspentOutputByIndexKey(indexKey OutputIndexKey, startOutputID *iotago.OutputID, consumer OutputConsumer, options ...IterateOption) error {
+
+	var innerErr error
+	if err := u.ForEachUnspentOutputIDByIndexKey(indexKey, startOutputID, func(outputID iotago.OutputID) bool {
+		output, err := u.ReadOutputByOutputIDWithoutLocking(outputID)
+		if err != nil {
+			innerErr = err
+
+			return false
+		}
+
+		return consumer(output)
+	}, options...); err != nil {
+		return err
+	}
+
+	return innerErr
+}
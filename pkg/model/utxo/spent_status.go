@@ -39,16 +39,24 @@ func outputIDFromDatabaseKey(key LookupKey) (iotago.OutputID, error) {
 	return ParseOutputID(ms)
 }
 
-func markAsUnspent(output *Output, mutations kvstore.BatchedMutations) error {
-	return mutations.Set(output.UnspentLookupKey(), []byte{})
+func (u *Manager) markAsUnspent(output *Output, mutations kvstore.BatchedMutations) error {
+	if err := mutations.Set(output.UnspentLookupKey(), []byte{}); err != nil {
+		return err
+	}
+
+	return u.storeOutputIndex(output, mutations)
 }
 
-func markAsSpent(output *Output, mutations kvstore.BatchedMutations) error {
-	return deleteOutputLookups(output, mutations)
+func (u *Manager) markAsSpent(output *Output, mutations kvstore.BatchedMutations) error {
+	return u.deleteOutputLookups(output, mutations)
 }
 
-func deleteOutputLookups(output *Output, mutations kvstore.BatchedMutations) error {
-	return mutations.Delete(output.UnspentLookupKey())
+func (u *Manager) deleteOutputLookups(output *Output, mutations kvstore.BatchedMutations) error {
+	if err := mutations.Delete(output.UnspentLookupKey()); err != nil {
+		return err
+	}
+
+	return u.deleteOutputIndex(output, mutations)
 }
 
 func (u *Manager) IsOutputIDUnspentWithoutLocking(outputID iotago.OutputID) (bool, error) {
@@ -59,18 +67,18 @@ func (u *Manager) IsOutputUnspentWithoutLocking(output *Output) (bool, error) {
 	return u.utxoStorage.Has(output.UnspentLookupKey())
 }
 
-func storeSpentAndMarkOutputAsSpent(spent *Spent, mutations kvstore.BatchedMutations) error {
+func (u *Manager) storeSpentAndMarkOutputAsSpent(spent *Spent, mutations kvstore.BatchedMutations) error {
 	if err := storeSpent(spent, mutations); err != nil {
 		return err
 	}
 
-	return markAsSpent(spent.output, mutations)
+	return u.markAsSpent(spent.output, mutations)
 }
 
-func deleteSpentAndMarkOutputAsUnspent(spent *Spent, mutations kvstore.BatchedMutations) error {
+func (u *Manager) deleteSpentAndMarkOutputAsUnspent(spent *Spent, mutations kvstore.BatchedMutations) error {
 	if err := deleteSpent(spent, mutations); err != nil {
 		return err
 	}
 
-	return markAsUnspent(spent.output, mutations)
+	return u.markAsUnspent(spent.output, mutations)
 }
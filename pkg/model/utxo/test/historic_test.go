@@ -0,0 +1,191 @@
+//nolint:forcetypeassert,varnamelen,revive,exhaustruct // we don't care about these linters in test cases
+package utxo_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/hornet/v2/pkg/model/utxo"
+	"github.com/iotaledger/hornet/v2/pkg/tpkg"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+func unspentOutputIDsAtMilestone(t *testing.T, manager *utxo.Manager, msIndex iotago.MilestoneIndex, startOutputID *iotago.OutputID) iotago.OutputIDs {
+	outputIDs := iotago.OutputIDs{}
+	require.NoError(t, manager.ForEachUnspentOutputIDAtMilestone(msIndex, startOutputID, func(outputID iotago.OutputID) bool {
+		outputIDs = append(outputIDs, outputID)
+
+		return true
+	}))
+
+	return outputIDs
+}
+
+func randUTXOOutputBookedAt(msIndex iotago.MilestoneIndex) *utxo.Output {
+	return utxo.CreateOutput(tpkg.RandOutputID(), tpkg.RandBlockID(), msIndex, tpkg.RandMilestoneTimestamp(), tpkg.RandOutput(iotago.OutputBasic))
+}
+
+func TestUnspentOutputsAtMilestone(t *testing.T) {
+
+	manager := utxo.New(mapdb.NewMapDB())
+
+	msIndex := tpkg.RandMilestoneIndex()
+	msTimestamp := tpkg.RandMilestoneTimestamp()
+
+	outputs1 := utxo.Outputs{
+		randUTXOOutputBookedAt(msIndex),
+		randUTXOOutputBookedAt(msIndex),
+		randUTXOOutputBookedAt(msIndex),
+	}
+	require.NoError(t, manager.ApplyConfirmation(msIndex, outputs1, utxo.Spents{}, nil, nil))
+
+	outputs2 := utxo.Outputs{
+		randUTXOOutputBookedAt(msIndex + 1),
+		randUTXOOutputBookedAt(msIndex + 1),
+	}
+	spents2 := utxo.Spents{
+		tpkg.RandUTXOSpentWithOutput(outputs1[0], msIndex+1, msTimestamp),
+	}
+	require.NoError(t, manager.ApplyConfirmation(msIndex+1, outputs2, spents2, nil, nil))
+
+	outputs3 := utxo.Outputs{
+		randUTXOOutputBookedAt(msIndex + 2),
+	}
+	spents3 := utxo.Spents{
+		tpkg.RandUTXOSpentWithOutput(outputs1[1], msIndex+2, msTimestamp),
+		tpkg.RandUTXOSpentWithOutput(outputs2[0], msIndex+2, msTimestamp),
+	}
+	require.NoError(t, manager.ApplyConfirmation(msIndex+2, outputs3, spents3, nil, nil))
+
+	expectedAtMilestone := map[iotago.MilestoneIndex]iotago.OutputIDs{
+		msIndex:     {outputs1[0].OutputID(), outputs1[1].OutputID(), outputs1[2].OutputID()},
+		msIndex + 1: {outputs1[1].OutputID(), outputs1[2].OutputID(), outputs2[0].OutputID(), outputs2[1].OutputID()},
+		msIndex + 2: {outputs1[2].OutputID(), outputs2[1].OutputID(), outputs3[0].OutputID()},
+	}
+
+	for index, expected := range expectedAtMilestone {
+		// the iteration is ordered by output ID
+		require.Equal(t, expected.RemoveDupsAndSort(), unspentOutputIDsAtMilestone(t, manager, index, nil))
+	}
+
+	// the iteration can be started at a given output ID
+	sortedOutputIDs := expectedAtMilestone[msIndex+1].RemoveDupsAndSort()
+	require.Equal(t, sortedOutputIDs[2:], unspentOutputIDsAtMilestone(t, manager, msIndex+1, &sortedOutputIDs[2]))
+
+	// the iteration can be continued page by page, even if the ledger was changed in between
+	var page iotago.OutputIDs
+	require.NoError(t, manager.ForEachUnspentOutputIDAtMilestone(msIndex+1, nil, func(outputID iotago.OutputID) bool {
+		page = append(page, outputID)
+
+		return true
+	}, utxo.MaxResultCount(2)))
+	require.Equal(t, sortedOutputIDs[:2], page)
+
+	outputs4 := utxo.Outputs{
+		randUTXOOutputBookedAt(msIndex + 3),
+	}
+	spents4 := utxo.Spents{
+		tpkg.RandUTXOSpentWithOutput(outputs1[2], msIndex+3, msTimestamp),
+		tpkg.RandUTXOSpentWithOutput(outputs2[1], msIndex+3, msTimestamp),
+	}
+	require.NoError(t, manager.ApplyConfirmation(msIndex+3, outputs4, spents4, nil, nil))
+	require.Equal(t, sortedOutputIDs[2:], unspentOutputIDsAtMilestone(t, manager, msIndex+1, &sortedOutputIDs[2]))
+	require.NoError(t, manager.RollbackConfirmation(msIndex+3, outputs4, spents4, nil, nil))
+
+	balance, count, err := manager.ComputeLedgerBalanceAtMilestone(msIndex)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+	require.Equal(t, outputs1[0].Deposit()+outputs1[1].Deposit()+outputs1[2].Deposit(), balance)
+
+	// the output was unspent at the first milestone and spent at the second one
+	output, spent, err := manager.ReadOutputAtMilestoneWithoutLocking(outputs1[0].OutputID(), msIndex)
+	require.NoError(t, err)
+	require.Equal(t, outputs1[0].OutputID(), output.OutputID())
+	require.Nil(t, spent)
+
+	_, spent, err = manager.ReadOutputAtMilestoneWithoutLocking(outputs1[0].OutputID(), msIndex+1)
+	require.NoError(t, err)
+	require.NotNil(t, spent)
+	require.Equal(t, msIndex+1, spent.MilestoneIndexSpent())
+
+	// the output did not exist at the first milestone
+	_, _, err = manager.ReadOutputAtMilestoneWithoutLocking(outputs3[0].OutputID(), msIndex)
+	require.ErrorIs(t, err, kvstore.ErrKeyNotFound)
+
+	// the ledger state of milestones newer than the ledger index is not available
+	require.ErrorIs(t, manager.ForEachUnspentOutputIDAtMilestone(msIndex+3, nil, func(outputID iotago.OutputID) bool { return true }), utxo.ErrHistoricLedgerStateUnavailable)
+
+	// the ledger state before the pruning index is not available
+	require.NoError(t, manager.PruneMilestoneIndexWithoutLocking(msIndex+1, false))
+	require.ErrorIs(t, manager.ForEachUnspentOutputIDAtMilestone(msIndex, nil, func(outputID iotago.OutputID) bool { return true }), utxo.ErrHistoricLedgerStateUnavailable)
+	_, _, err = manager.ReadOutputAtMilestoneWithoutLocking(outputs1[2].OutputID(), msIndex)
+	require.ErrorIs(t, err, utxo.ErrHistoricLedgerStateUnavailable)
+	require.Equal(t, expectedAtMilestone[msIndex+1].RemoveDupsAndSort(), unspentOutputIDsAtMilestone(t, manager, msIndex+1, nil))
+}
+
+func TestUnspentOutputsAtMilestonePages(t *testing.T) {
+
+	manager := utxo.New(mapdb.NewMapDB())
+
+	msIndex := tpkg.RandMilestoneIndex()
+	msTimestamp := tpkg.RandMilestoneTimestamp()
+
+	// more spent outputs than read at once, so the spent outputs are read in several chunks
+	outputs1 := make(utxo.Outputs, 0, 3000)
+	for i := 0; i < 3000; i++ {
+		outputs1 = append(outputs1, randUTXOOutputBookedAt(msIndex))
+	}
+	require.NoError(t, manager.ApplyConfirmation(msIndex, outputs1, utxo.Spents{}, nil, nil))
+
+	outputs2 := make(utxo.Outputs, 0, 500)
+	for i := 0; i < 500; i++ {
+		outputs2 = append(outputs2, randUTXOOutputBookedAt(msIndex+1))
+	}
+	spents2 := make(utxo.Spents, 0, 2000)
+	for _, output := range outputs1[:2000] {
+		spents2 = append(spents2, tpkg.RandUTXOSpentWithOutput(output, msIndex+1, msTimestamp))
+	}
+	require.NoError(t, manager.ApplyConfirmation(msIndex+1, outputs2, spents2, nil, nil))
+
+	// outputs that were created after the milestone are not part of the ledger state, even if they were spent
+	spents3 := make(utxo.Spents, 0, 200)
+	for _, output := range outputs2[:200] {
+		spents3 = append(spents3, tpkg.RandUTXOSpentWithOutput(output, msIndex+2, msTimestamp))
+	}
+	require.NoError(t, manager.ApplyConfirmation(msIndex+2, utxo.Outputs{}, spents3, nil, nil))
+
+	expected := make(iotago.OutputIDs, 0, len(outputs1))
+	for _, output := range outputs1 {
+		expected = append(expected, output.OutputID())
+	}
+	expected = expected.RemoveDupsAndSort()
+
+	require.Equal(t, expected, unspentOutputIDsAtMilestone(t, manager, msIndex, nil))
+
+	// every page continues at the first output of the next page
+	const pageSize = 700
+
+	outputIDs := iotago.OutputIDs{}
+	var startOutputID *iotago.OutputID
+	for {
+		page := iotago.OutputIDs{}
+		require.NoError(t, manager.ForEachUnspentOutputIDAtMilestone(msIndex, startOutputID, func(outputID iotago.OutputID) bool {
+			page = append(page, outputID)
+
+			return true
+		}, utxo.MaxResultCount(pageSize+1)))
+
+		if len(page) <= pageSize {
+			outputIDs = append(outputIDs, page...)
+
+			break
+		}
+
+		outputIDs = append(outputIDs, page[:pageSize]...)
+		startOutputID = &page[pageSize]
+	}
+	require.Equal(t, expected, outputIDs)
+}
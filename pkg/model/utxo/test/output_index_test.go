@@ -0,0 +1,179 @@
+//nolint:forcetypeassert,varnamelen,revive,exhaustruct // we don't care about these linters in test cases
+package utxo_test
+
+import (
+	"testing"
+
+	pebbleDB "github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/hornet/v2/pkg/database"
+	"github.com/iotaledger/hornet/v2/pkg/model/utxo"
+	"github.com/iotaledger/hornet/v2/pkg/tpkg"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+func outputIDsByIndexKey(t *testing.T, manager *utxo.Manager, indexKey utxo.OutputIndexKey, startOutputID *iotago.OutputID) iotago.OutputIDs {
+	outputIDs := iotago.OutputIDs{}
+	require.NoError(t, manager.ForEachUnspentOutputIDByIndexKey(indexKey, startOutputID, func(outputID iotago.OutputID) bool {
+		outputIDs = append(outputIDs, outputID)
+
+		return true
+	}))
+
+	return outputIDs
+}
+
+func TestOutputIndexApplyAndRollback(t *testing.T) {
+
+	manager := utxo.New(mapdb.NewMapDB())
+
+	_, err := manager.EnableOutputIndex()
+	require.NoError(t, err)
+
+	address := tpkg.RandAddress(iotago.AddressEd25519)
+
+	outputs := utxo.Outputs{
+		tpkg.RandUTXOOutputOnAddress(iotago.OutputBasic, address),
+		tpkg.RandUTXOOutputOnAddress(iotago.OutputBasic, address), // spent
+		tpkg.RandUTXOOutputOnAddress(iotago.OutputNFT, address),
+		tpkg.RandUTXOOutputWithType(iotago.OutputBasic),
+	}
+
+	msIndex := tpkg.RandMilestoneIndex()
+	msTimestamp := tpkg.RandMilestoneTimestamp()
+
+	require.NoError(t, manager.ApplyConfirmation(msIndex, outputs, utxo.Spents{}, nil, nil))
+	require.ElementsMatch(t, iotago.OutputIDs{outputs[0].OutputID(), outputs[1].OutputID(), outputs[2].OutputID()}, outputIDsByIndexKey(t, manager, utxo.OutputIndexKeyAddress(address), nil))
+
+	nftID := outputs[2].Output().(*iotago.NFTOutput).NFTID
+	if nftID.Empty() {
+		nftID = iotago.NFTIDFromOutputID(outputs[2].OutputID())
+	}
+	require.Equal(t, iotago.OutputIDs{outputs[2].OutputID()}, outputIDsByIndexKey(t, manager, utxo.OutputIndexKeyNFTID(nftID), nil))
+
+	spents := utxo.Spents{
+		tpkg.RandUTXOSpentWithOutput(outputs[1], msIndex+1, msTimestamp),
+	}
+
+	require.NoError(t, manager.ApplyConfirmation(msIndex+1, utxo.Outputs{}, spents, nil, nil))
+	require.ElementsMatch(t, iotago.OutputIDs{outputs[0].OutputID(), outputs[2].OutputID()}, outputIDsByIndexKey(t, manager, utxo.OutputIndexKeyAddress(address), nil))
+
+	require.NoError(t, manager.RollbackConfirmation(msIndex+1, utxo.Outputs{}, spents, nil, nil))
+	require.ElementsMatch(t, iotago.OutputIDs{outputs[0].OutputID(), outputs[1].OutputID(), outputs[2].OutputID()}, outputIDsByIndexKey(t, manager, utxo.OutputIndexKeyAddress(address), nil))
+
+	require.NoError(t, manager.RollbackConfirmation(msIndex, outputs, utxo.Spents{}, nil, nil))
+	require.Empty(t, outputIDsByIndexKey(t, manager, utxo.OutputIndexKeyAddress(address), nil))
+}
+
+func TestOutputIndexRebuild(t *testing.T) {
+
+	manager := utxo.New(mapdb.NewMapDB())
+
+	address := tpkg.RandAddress(iotago.AddressEd25519)
+
+	outputs := utxo.Outputs{
+		tpkg.RandUTXOOutputOnAddress(iotago.OutputBasic, address),
+		tpkg.RandUTXOOutputOnAddress(iotago.OutputBasic, address),
+		tpkg.RandUTXOOutputOnAddress(iotago.OutputBasic, address),
+	}
+
+	require.NoError(t, manager.ApplyConfirmation(tpkg.RandMilestoneIndex(), outputs, utxo.Spents{}, nil, nil))
+
+	// the index can't be queried if it is disabled
+	require.ErrorIs(t, manager.ForEachUnspentOutputIDByIndexKey(utxo.OutputIndexKeyAddress(address), nil, func(outputID iotago.OutputID) bool { return true }), utxo.ErrOutputIndexDisabled)
+
+	rebuilt, err := manager.EnableOutputIndex()
+	require.NoError(t, err)
+	require.True(t, rebuilt)
+
+	outputIDs := outputIDsByIndexKey(t, manager, utxo.OutputIndexKeyAddress(address), nil)
+	require.ElementsMatch(t, iotago.OutputIDs{outputs[0].OutputID(), outputs[1].OutputID(), outputs[2].OutputID()}, outputIDs)
+
+	// the iteration is ordered by output ID and can be started at a given output ID
+	sortedOutputIDs := outputIDs.RemoveDupsAndSort()
+	require.Equal(t, sortedOutputIDs[1:], outputIDsByIndexKey(t, manager, utxo.OutputIndexKeyAddress(address), &sortedOutputIDs[1]))
+
+	// the index is not rebuilt if it is already complete
+	rebuilt, err = manager.EnableOutputIndex()
+	require.NoError(t, err)
+	require.False(t, rebuilt)
+
+	require.NoError(t, manager.DisableOutputIndex())
+	require.False(t, manager.OutputIndexEnabled())
+
+	rebuilt, err = manager.EnableOutputIndex()
+	require.NoError(t, err)
+	require.True(t, rebuilt)
+}
+
+func TestOutputIndexSeek(t *testing.T) {
+
+	db, err := pebbleDB.Open("", &pebbleDB.Options{FS: vfs.NewMem()})
+	require.NoError(t, err)
+	defer db.Close()
+
+	store, err := database.NewPebbleStore(db).WithRealm([]byte{0x01})
+	require.NoError(t, err)
+
+	manager := utxo.New(store)
+
+	_, err = manager.EnableOutputIndex()
+	require.NoError(t, err)
+
+	address := tpkg.RandAddress(iotago.AddressEd25519)
+
+	outputs := utxo.Outputs{
+		tpkg.RandUTXOOutputOnAddress(iotago.OutputBasic, address),
+		tpkg.RandUTXOOutputOnAddress(iotago.OutputBasic, address),
+		tpkg.RandUTXOOutputOnAddress(iotago.OutputBasic, address),
+		tpkg.RandUTXOOutputOnAddress(iotago.OutputBasic, tpkg.RandAddress(iotago.AddressEd25519)),
+	}
+
+	require.NoError(t, manager.ApplyConfirmation(tpkg.RandMilestoneIndex(), outputs, utxo.Spents{}, nil, nil))
+
+	outputIDs := outputIDsByIndexKey(t, manager, utxo.OutputIndexKeyAddress(address), nil)
+	require.Len(t, outputIDs, 3)
+
+	sortedOutputIDs := outputIDs.RemoveDupsAndSort()
+	require.Equal(t, sortedOutputIDs, outputIDs)
+
+	// the iteration is started directly at the given output ID
+	require.Equal(t, sortedOutputIDs[1:], outputIDsByIndexKey(t, manager, utxo.OutputIndexKeyAddress(address), &sortedOutputIDs[1]))
+	require.Equal(t, sortedOutputIDs[2:], outputIDsByIndexKey(t, manager, utxo.OutputIndexKeyAddress(address), &sortedOutputIDs[2]))
+}
+
+func TestOwnerAddress(t *testing.T) {
+
+	address := tpkg.RandAddress(iotago.AddressEd25519)
+	returnAddress := tpkg.RandAddress(iotago.AddressEd25519)
+	msTimestamp := tpkg.RandMilestoneTimestamp()
+
+	output := &iotago.BasicOutput{
+		Amount: tpkg.RandAmount(),
+		Conditions: iotago.UnlockConditions{
+			&iotago.AddressUnlockCondition{Address: address},
+		},
+	}
+	require.True(t, address.Equal(utxo.OwnerAddress(output, msTimestamp)))
+
+	output.Conditions = append(output.Conditions, &iotago.ExpirationUnlockCondition{ReturnAddress: returnAddress, UnixTime: msTimestamp + 1})
+	require.True(t, address.Equal(utxo.OwnerAddress(output, msTimestamp)))
+
+	// the output is owned by the return address after the expiration
+	output.Conditions[1].(*iotago.ExpirationUnlockCondition).UnixTime = msTimestamp
+	require.True(t, returnAddress.Equal(utxo.OwnerAddress(output, msTimestamp)))
+
+	// alias outputs are only controlled by the state controller and the governor
+	aliasOutput := &iotago.AliasOutput{
+		Amount:  tpkg.RandAmount(),
+		AliasID: tpkg.RandAliasID(),
+		Conditions: iotago.UnlockConditions{
+			&iotago.StateControllerAddressUnlockCondition{Address: address},
+			&iotago.GovernorAddressUnlockCondition{Address: address},
+		},
+	}
+	require.Nil(t, utxo.OwnerAddress(aliasOutput, msTimestamp))
+}
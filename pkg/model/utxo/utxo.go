@@ -20,6 +20,9 @@ var (
 type Manager struct {
 	utxoStorage kvstore.KVStore
 	utxoLock    sync.RWMutex
+
+	// outputIndexEnabled defines whether the unspent outputs are indexed by address, chain IDs and native tokens.
+	outputIndexEnabled bool
 }
 
 func New(store kvstore.KVStore) *Manager {
@@ -46,7 +49,11 @@ func (u *Manager) ClearLedger(pruneReceipts bool) (err error) {
 
 	if pruneReceipts {
 		// if we also prune the receipts, we can just clear everything
-		return u.utxoStorage.Clear()
+		if err = u.utxoStorage.Clear(); err != nil {
+			return err
+		}
+
+		return u.markOutputIndexBuiltAfterClear()
 	}
 
 	if err = u.utxoStorage.DeletePrefix([]byte{UTXOStoreKeyPrefixLedgerMilestoneIndex}); err != nil {
@@ -65,7 +72,24 @@ func (u *Manager) ClearLedger(pruneReceipts bool) (err error) {
 		return err
 	}
 
-	return u.utxoStorage.DeletePrefix([]byte{UTXOStoreKeyPrefixTreasuryOutput})
+	if err = u.utxoStorage.DeletePrefix([]byte{UTXOStoreKeyPrefixTreasuryOutput}); err != nil {
+		return err
+	}
+	if err = u.utxoStorage.DeletePrefix([]byte{UTXOStoreKeyPrefixOutputIndex}); err != nil {
+		return err
+	}
+
+	return u.markOutputIndexBuiltAfterClear()
+}
+
+// markOutputIndexBuiltAfterClear marks the output index as built if it is enabled,
+// because the index of an empty ledger is complete.
+func (u *Manager) markOutputIndexBuiltAfterClear() error {
+	if !u.outputIndexEnabled {
+		return u.utxoStorage.DeletePrefix([]byte{UTXOStoreKeyPrefixOutputIndexBuilt})
+	}
+
+	return u.utxoStorage.Set([]byte{UTXOStoreKeyPrefixOutputIndexBuilt}, []byte{})
 }
 
 func (u *Manager) ReadLockLedger() {
@@ -196,7 +220,7 @@ func (u *Manager) ApplyConfirmationWithoutLocking(msIndex iotago.MilestoneIndex,
 
 			return err
 		}
-		if err := markAsUnspent(output, mutations); err != nil {
+		if err := u.markAsUnspent(output, mutations); err != nil {
 			mutations.Cancel()
 
 			return err
@@ -204,7 +228,7 @@ func (u *Manager) ApplyConfirmationWithoutLocking(msIndex iotago.MilestoneIndex,
 	}
 
 	for _, spent := range newSpents {
-		if err := storeSpentAndMarkOutputAsSpent(spent, mutations); err != nil {
+		if err := u.storeSpentAndMarkOutputAsSpent(spent, mutations); err != nil {
 			mutations.Cancel()
 
 			return err
@@ -280,7 +304,7 @@ func (u *Manager) RollbackConfirmationWithoutLocking(msIndex iotago.MilestoneInd
 			return err
 		}
 
-		if err := deleteSpentAndMarkOutputAsUnspent(spent, mutations); err != nil {
+		if err := u.deleteSpentAndMarkOutputAsUnspent(spent, mutations); err != nil {
 			mutations.Cancel()
 
 			return err
@@ -294,7 +318,7 @@ func (u *Manager) RollbackConfirmationWithoutLocking(msIndex iotago.MilestoneInd
 
 			return err
 		}
-		if err := deleteOutputLookups(output, mutations); err != nil {
+		if err := u.deleteOutputLookups(output, mutations); err != nil {
 			mutations.Cancel()
 
 			return err
@@ -381,7 +405,7 @@ func (u *Manager) AddUnspentOutput(unspentOutput *Output) error {
 		return err
 	}
 
-	if err := markAsUnspent(unspentOutput, mutations); err != nil {
+	if err := u.markAsUnspent(unspentOutput, mutations); err != nil {
 		mutations.Cancel()
 
 		return err
@@ -293,6 +293,10 @@ func parseEntryNode(entryNodeMultiAddrStr string, preferIPv6 bool) (entryNode *p
 	return peer.NewPeer(identity.New(*pubKey), ip, services), nil
 }
 
+// NeighborFilterFunc is used to exclude peers from the autopeering selection (e.g. banned peers).
+// Returning false excludes the peer.
+type NeighborFilterFunc func(addrInfo *peer2.AddrInfo) bool
+
 type Manager struct {
 	// the logger used to log events.
 	*logger.WrappedLogger
@@ -347,7 +351,9 @@ func (a *Manager) Discovery() *discover.Protocol {
 	return a.discoveryProtocol
 }
 
-func (a *Manager) Init(localPeerContainer *LocalPeerContainer, initSelection bool) {
+// Init initializes the discovery and optionally the selection protocol.
+// The optional neighborFilter is used to exclude peers from the selection.
+func (a *Manager) Init(localPeerContainer *LocalPeerContainer, initSelection bool, neighborFilter NeighborFilterFunc) {
 
 	parseEntryNodes := func(entryNodesString []string, preferIPv6 bool) (result []*peer.Peer, err error) {
 		for _, entryNodeDefinition := range entryNodesString {
@@ -394,6 +400,15 @@ func (a *Manager) Init(localPeerContainer *LocalPeerContainer, initSelection boo
 			return false
 		}
 
+		if neighborFilter != nil {
+			addrInfo, err := HivePeerToAddrInfo(p, a.p2pServiceKey)
+			if err != nil {
+				return false
+			}
+
+			return neighborFilter(addrInfo)
+		}
+
 		return true
 	}
 
@@ -0,0 +1,454 @@
+package p2p
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/connmgr"
+	"github.com/libp2p/go-libp2p/core/control"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/runtime/event"
+	"github.com/iotaledger/hive.go/runtime/ioutils"
+)
+
+const (
+	// BanListFileName is the name of the file the banned peers are persisted in.
+	BanListFileName = "banned_peers.json"
+
+	// the minimum interval between two checks whether the ban list file changed on disk.
+	banListReloadInterval = 5 * time.Second
+)
+
+var (
+	// ErrInvalidBanTarget gets returned if the target of a ban is neither a peer ID, an IP address, an IP range nor a multiaddress.
+	ErrInvalidBanTarget = errors.New("invalid ban target")
+	// ErrBanNotFound gets returned if a ban for the given target does not exist.
+	ErrBanNotFound = errors.New("ban not found")
+)
+
+// Ban is an entry of the BanList.
+type Ban struct {
+	// The banned peer ID, IP address, IP range (CIDR notation) or multiaddress.
+	Target string `json:"target"`
+	// The reason why the target was banned.
+	Reason string `json:"reason,omitempty"`
+	// The time the target was banned.
+	BannedAt time.Time `json:"bannedAt"`
+	// The time the ban expires (optional).
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// IsExpired tells whether the ban is expired at the given point in time.
+func (b *Ban) IsExpired(now time.Time) bool {
+	return b.ExpiresAt != nil && !now.Before(*b.ExpiresAt)
+}
+
+// banRule is the parsed form of a ban which is used to match peers and addresses.
+type banRule struct {
+	ban *Ban
+	// the banned peer ID (optional).
+	peerID peer.ID
+	// the banned IP range (optional).
+	ipNet *net.IPNet
+	// the banned address prefix (optional).
+	addr multiaddr.Multiaddr
+}
+
+// parses the given ban target and returns the rule and the normalized target.
+// the target can either be a peer ID, an IP address, an IP range in CIDR notation or a multiaddress.
+// if a multiaddress contains a peer ID, the peer ID is banned as well.
+func parseBanTarget(target string) (*banRule, string, error) {
+	target = strings.TrimSpace(target)
+	if len(target) == 0 {
+		return nil, "", errors.WithMessage(ErrInvalidBanTarget, "target must not be empty")
+	}
+
+	switch {
+	case strings.HasPrefix(target, "/"):
+		multiAddr, err := multiaddr.NewMultiaddr(target)
+		if err != nil {
+			return nil, "", errors.WithMessagef(ErrInvalidBanTarget, "invalid multiaddress: %s", err)
+		}
+
+		transport, peerID := peer.SplitAddr(multiAddr)
+
+		return &banRule{peerID: peerID, addr: transport}, multiAddr.String(), nil
+
+	case strings.Contains(target, "/"):
+		_, ipNet, err := net.ParseCIDR(target)
+		if err != nil {
+			return nil, "", errors.WithMessagef(ErrInvalidBanTarget, "invalid IP range: %s", err)
+		}
+
+		return &banRule{ipNet: ipNet}, ipNet.String(), nil
+
+	default:
+		if ip := net.ParseIP(target); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip = ip4
+				bits = 8 * net.IPv4len
+			}
+			ipNet := &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+
+			return &banRule{ipNet: ipNet}, ip.String(), nil
+		}
+
+		peerID, err := peer.Decode(target)
+		if err != nil {
+			return nil, "", errors.WithMessagef(ErrInvalidBanTarget, "invalid peer ID: %s", err)
+		}
+
+		return &banRule{peerID: peerID}, peerID.String(), nil
+	}
+}
+
+// matchesPeer tells whether the rule bans the given peer.
+func (r *banRule) matchesPeer(peerID peer.ID) bool {
+	return len(r.peerID) > 0 && r.peerID == peerID
+}
+
+// matchesAddr tells whether the rule bans the given address.
+func (r *banRule) matchesAddr(addr multiaddr.Multiaddr) bool {
+	if addr == nil {
+		return false
+	}
+
+	if r.ipNet != nil {
+		ip, err := manet.ToIP(addr)
+		if err != nil {
+			return false
+		}
+
+		return r.ipNet.Contains(ip)
+	}
+
+	if r.addr != nil {
+		// multiaddresses are self-delimiting, so a byte prefix is always a prefix of whole components.
+		// this way "/ip4/1.2.3.4" bans all ports of the IP, while "/ip4/1.2.3.4/tcp/15600" only bans a single port.
+		return bytes.HasPrefix(addr.Bytes(), r.addr.Bytes())
+	}
+
+	return false
+}
+
+// BanListEvents are events happening around a BanList.
+type BanListEvents struct {
+	// Fired when a ban was added or updated.
+	Added *event.Event1[*Ban]
+	// Fired when a ban was removed.
+	Removed *event.Event1[*Ban]
+}
+
+// BanList is a list of banned peer IDs, IP ranges and multiaddresses that is persisted to disk.
+// Changes to the file (e.g. by the "p2p-ban" tool) are picked up by running nodes.
+// The BanList implements connmgr.ConnectionGater, so bans are enforced for inbound and outbound connections.
+type BanList struct {
+	sync.RWMutex
+	// Events happening around the BanList.
+	Events   *BanListEvents
+	filePath string
+	rules    map[string]*banRule
+	// the modification time of the file at the last load.
+	modTime time.Time
+	// the last time the file was checked for changes.
+	lastCheck time.Time
+}
+
+var _ connmgr.ConnectionGater = &BanList{}
+
+// NewBanList loads the ban list from the given directory.
+// If the file does not exist, an empty list is returned.
+func NewBanList(directory string) (*BanList, error) {
+	b := &BanList{
+		Events: &BanListEvents{
+			Added:   event.New1[*Ban](),
+			Removed: event.New1[*Ban](),
+		},
+		filePath: filepath.Join(directory, BanListFileName),
+		rules:    make(map[string]*banRule),
+	}
+
+	if err := b.load(); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (b *BanList) load() error {
+	info, err := os.Stat(b.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			b.rules = make(map[string]*banRule)
+			b.modTime = time.Time{}
+
+			return nil
+		}
+
+		return fmt.Errorf("unable to check ban list file (%s): %w", b.filePath, err)
+	}
+
+	if info.ModTime().Equal(b.modTime) {
+		return nil
+	}
+
+	var bans []*Ban
+	if err := ioutils.ReadJSONFromFile(b.filePath, &bans); err != nil {
+		return fmt.Errorf("unable to read ban list file (%s): %w", b.filePath, err)
+	}
+
+	rules := make(map[string]*banRule, len(bans))
+	for _, ban := range bans {
+		rule, target, err := parseBanTarget(ban.Target)
+		if err != nil {
+			// ignore invalid entries in the file
+			continue
+		}
+		ban.Target = target
+		rule.ban = ban
+		rules[target] = rule
+	}
+
+	b.rules = rules
+	b.modTime = info.ModTime()
+
+	return nil
+}
+
+// stores the ban list to disk. expired bans are removed from the list.
+func (b *BanList) store() error {
+	now := time.Now()
+
+	bans := make([]*Ban, 0, len(b.rules))
+	for target, rule := range b.rules {
+		if rule.ban.IsExpired(now) {
+			delete(b.rules, target)
+
+			continue
+		}
+		bans = append(bans, rule.ban)
+	}
+	sort.Slice(bans, func(i, j int) bool {
+		return bans[i].BannedAt.Before(bans[j].BannedAt)
+	})
+
+	if err := ioutils.CreateDirectory(filepath.Dir(b.filePath), 0o700); err != nil {
+		return fmt.Errorf("unable to create ban list directory: %w", err)
+	}
+
+	if err := ioutils.WriteJSONToFile(b.filePath, bans, 0o600); err != nil {
+		return fmt.Errorf("unable to write ban list file (%s): %w", b.filePath, err)
+	}
+
+	info, err := os.Stat(b.filePath)
+	if err != nil {
+		return fmt.Errorf("unable to check ban list file (%s): %w", b.filePath, err)
+	}
+	b.modTime = info.ModTime()
+
+	return nil
+}
+
+// reloadIfChanged reloads the list if the file on disk was changed.
+// The file is only checked once per reload interval.
+func (b *BanList) reloadIfChanged() {
+	b.RLock()
+	checkNeeded := time.Since(b.lastCheck) >= banListReloadInterval
+	b.RUnlock()
+
+	if !checkNeeded {
+		return
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	b.lastCheck = time.Now()
+
+	// keep the current list if the file can't be read
+	_ = b.load()
+}
+
+// Add bans the given target and persists the ban.
+// The target can either be a peer ID, an IP address, an IP range in CIDR notation or a multiaddress.
+// If the target is already banned, the reason and expiry of the ban are updated.
+// A zero duration bans the target permanently.
+func (b *BanList) Add(target string, reason string, duration time.Duration) (*Ban, error) {
+	rule, normalizedTarget, err := parseBanTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	if duration < 0 {
+		return nil, fmt.Errorf("ban duration must not be negative: %s", duration)
+	}
+
+	now := time.Now()
+	rule.ban = &Ban{
+		Target:   normalizedTarget,
+		Reason:   reason,
+		BannedAt: now,
+	}
+	if duration > 0 {
+		expiresAt := now.Add(duration)
+		rule.ban.ExpiresAt = &expiresAt
+	}
+
+	if err := func() error {
+		b.Lock()
+		defer b.Unlock()
+
+		// pick up changes done by others first
+		if err := b.load(); err != nil {
+			return err
+		}
+
+		b.rules[normalizedTarget] = rule
+
+		return b.store()
+	}(); err != nil {
+		return nil, err
+	}
+
+	ban := *rule.ban
+	b.Events.Added.Trigger(&ban)
+
+	return &ban, nil
+}
+
+// Remove lifts the ban of the given target.
+func (b *BanList) Remove(target string) error {
+	_, normalizedTarget, err := parseBanTarget(target)
+	if err != nil {
+		// allow to remove entries which were added to the file with a different notation
+		normalizedTarget = target
+	}
+
+	var removed *Ban
+	if err := func() error {
+		b.Lock()
+		defer b.Unlock()
+
+		// pick up changes done by others first
+		if err := b.load(); err != nil {
+			return err
+		}
+
+		rule, exists := b.rules[normalizedTarget]
+		if !exists || rule.ban.IsExpired(time.Now()) {
+			return errors.WithMessagef(ErrBanNotFound, "target: %s", target)
+		}
+		delete(b.rules, normalizedTarget)
+		removed = rule.ban
+
+		return b.store()
+	}(); err != nil {
+		return err
+	}
+
+	b.Events.Removed.Trigger(removed)
+
+	return nil
+}
+
+// Bans returns all active bans, sorted by the time they were added.
+func (b *BanList) Bans() []*Ban {
+	b.reloadIfChanged()
+
+	b.RLock()
+	defer b.RUnlock()
+
+	now := time.Now()
+	bans := make([]*Ban, 0, len(b.rules))
+	for _, rule := range b.rules {
+		if rule.ban.IsExpired(now) {
+			continue
+		}
+		ban := *rule.ban
+		bans = append(bans, &ban)
+	}
+	sort.Slice(bans, func(i, j int) bool {
+		return bans[i].BannedAt.Before(bans[j].BannedAt)
+	})
+
+	return bans
+}
+
+// returns the first active ban whose rule matches.
+func (b *BanList) find(matches func(rule *banRule) bool) *Ban {
+	b.reloadIfChanged()
+
+	b.RLock()
+	defer b.RUnlock()
+
+	now := time.Now()
+	for _, rule := range b.rules {
+		if rule.ban.IsExpired(now) {
+			continue
+		}
+		if matches(rule) {
+			return rule.ban
+		}
+	}
+
+	return nil
+}
+
+// IsPeerBanned tells whether the given peer ID is banned.
+func (b *BanList) IsPeerBanned(peerID peer.ID) bool {
+	return b.find(func(rule *banRule) bool {
+		return rule.matchesPeer(peerID)
+	}) != nil
+}
+
+// IsAddrBanned tells whether the given address is banned.
+func (b *BanList) IsAddrBanned(addr multiaddr.Multiaddr) bool {
+	return b.find(func(rule *banRule) bool {
+		return rule.matchesAddr(addr)
+	}) != nil
+}
+
+// IsConnBanned tells whether either the remote peer or the remote address of the given connection is banned.
+func (b *BanList) IsConnBanned(conn network.Conn) bool {
+	return b.find(func(rule *banRule) bool {
+		return rule.matchesPeer(conn.RemotePeer()) || rule.matchesAddr(conn.RemoteMultiaddr())
+	}) != nil
+}
+
+// InterceptPeerDial implements connmgr.ConnectionGater.
+func (b *BanList) InterceptPeerDial(peerID peer.ID) bool {
+	return !b.IsPeerBanned(peerID)
+}
+
+// InterceptAddrDial implements connmgr.ConnectionGater.
+func (b *BanList) InterceptAddrDial(_ peer.ID, addr multiaddr.Multiaddr) bool {
+	return !b.IsAddrBanned(addr)
+}
+
+// InterceptAccept implements connmgr.ConnectionGater.
+func (b *BanList) InterceptAccept(connAddrs network.ConnMultiaddrs) bool {
+	return !b.IsAddrBanned(connAddrs.RemoteMultiaddr())
+}
+
+// InterceptSecured implements connmgr.ConnectionGater.
+func (b *BanList) InterceptSecured(_ network.Direction, peerID peer.ID, _ network.ConnMultiaddrs) bool {
+	return !b.IsPeerBanned(peerID)
+}
+
+// InterceptUpgraded implements connmgr.ConnectionGater.
+func (b *BanList) InterceptUpgraded(_ network.Conn) (bool, control.DisconnectReason) {
+	return true, 0
+}
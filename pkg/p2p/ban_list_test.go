@@ -0,0 +1,128 @@
+//nolint:forcetypeassert,varnamelen,revive,exhaustruct // we don't care about these linters in test cases
+package p2p_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hornet/v2/pkg/p2p"
+)
+
+func TestBanListTargets(t *testing.T) {
+	banList, err := p2p.NewBanList(t.TempDir())
+	require.NoError(t, err)
+
+	peerID := randPeerID(t)
+	otherPeerID := randPeerID(t)
+
+	ip4Addr := multiaddr.StringCast("/ip4/10.1.2.3/tcp/15600")
+	ip6Addr := multiaddr.StringCast("/ip6/2001:db8::1/tcp/15600")
+	otherAddr := multiaddr.StringCast("/ip4/192.168.1.1/tcp/15600")
+
+	require.False(t, banList.IsPeerBanned(peerID))
+	require.True(t, banList.InterceptPeerDial(peerID))
+
+	// peer IDs
+	_, err = banList.Add(peerID.String(), "invalid blocks", 0)
+	require.NoError(t, err)
+	require.True(t, banList.IsPeerBanned(peerID))
+	require.False(t, banList.IsPeerBanned(otherPeerID))
+	require.False(t, banList.InterceptPeerDial(peerID))
+	require.False(t, banList.InterceptSecured(network.DirInbound, peerID, nil))
+
+	// IP ranges
+	_, err = banList.Add("10.0.0.0/8", "", 0)
+	require.NoError(t, err)
+	require.True(t, banList.IsAddrBanned(ip4Addr))
+	require.False(t, banList.IsAddrBanned(ip6Addr))
+	require.False(t, banList.IsAddrBanned(otherAddr))
+	require.False(t, banList.InterceptAddrDial(otherPeerID, ip4Addr))
+
+	// single IP addresses
+	ban, err := banList.Add("2001:db8::1", "", 0)
+	require.NoError(t, err)
+	require.Equal(t, "2001:db8::1", ban.Target)
+	require.True(t, banList.IsAddrBanned(ip6Addr))
+
+	// multiaddresses are matched by prefix and also ban a contained peer ID
+	ban, err = banList.Add("/ip4/192.168.1.1/tcp/15600/p2p/"+otherPeerID.String(), "", 0)
+	require.NoError(t, err)
+	require.True(t, banList.IsAddrBanned(otherAddr))
+	require.False(t, banList.IsAddrBanned(multiaddr.StringCast("/ip4/192.168.1.1/tcp/15601")))
+	require.True(t, banList.IsPeerBanned(otherPeerID))
+
+	require.Len(t, banList.Bans(), 4)
+
+	// invalid targets
+	for _, target := range []string{"", "foo", "10.0.0.0/33", "/ip4/foo"} {
+		_, err = banList.Add(target, "", 0)
+		require.ErrorIs(t, err, p2p.ErrInvalidBanTarget)
+	}
+
+	// lifting bans
+	require.NoError(t, banList.Remove(ban.Target))
+	require.False(t, banList.IsAddrBanned(otherAddr))
+	require.False(t, banList.IsPeerBanned(otherPeerID))
+	require.ErrorIs(t, banList.Remove(ban.Target), p2p.ErrBanNotFound)
+
+	require.Len(t, banList.Bans(), 3)
+}
+
+func TestBanListPersistence(t *testing.T) {
+	dir := t.TempDir()
+
+	banList, err := p2p.NewBanList(dir)
+	require.NoError(t, err)
+
+	var added []*p2p.Ban
+	banList.Events.Added.Hook(func(ban *p2p.Ban) {
+		added = append(added, ban)
+	})
+
+	peerID := randPeerID(t)
+
+	ban, err := banList.Add(peerID.String(), "spam", time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, "spam", ban.Reason)
+	require.NotNil(t, ban.ExpiresAt)
+	require.WithinDuration(t, time.Now().Add(time.Hour), *ban.ExpiresAt, time.Minute)
+	require.Len(t, added, 1)
+
+	// expired bans are ignored
+	_, err = banList.Add("10.0.0.0/8", "", 0)
+	require.NoError(t, err)
+	_, err = banList.Add("/ip4/10.1.2.3", "", time.Nanosecond)
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	require.Len(t, banList.Bans(), 2)
+
+	// the bans are loaded from disk
+	_, err = os.Stat(filepath.Join(dir, p2p.BanListFileName))
+	require.NoError(t, err)
+
+	loadedBanList, err := p2p.NewBanList(dir)
+	require.NoError(t, err)
+	require.True(t, loadedBanList.IsPeerBanned(peerID))
+	loadedBans := loadedBanList.Bans()
+	require.Len(t, loadedBans, 2)
+	require.Equal(t, peerID.String(), loadedBans[0].Target)
+	require.Equal(t, "spam", loadedBans[0].Reason)
+	require.Equal(t, ban.ExpiresAt.Unix(), loadedBans[0].ExpiresAt.Unix())
+
+	// changes of other instances (e.g. the tool) are picked up when the list is modified
+	require.NoError(t, loadedBanList.Remove(peerID.String()))
+	_, err = banList.Add("192.168.0.0/16", "", 0)
+	require.NoError(t, err)
+
+	bans := banList.Bans()
+	require.Len(t, bans, 2)
+	require.Equal(t, "10.0.0.0/8", bans[0].Target)
+	require.Equal(t, "192.168.0.0/16", bans[1].Target)
+	require.False(t, banList.IsPeerBanned(peerID))
+}
@@ -40,6 +40,8 @@ var (
 	ErrPeerInManagerAlreadyAllowed = errors.New("peer is already allowed in manager")
 	// ErrManagerShutdown gets returned if the manager is shutting down.
 	ErrManagerShutdown = errors.New("manager is shutting down")
+	// ErrPeerBanned gets returned if the manager is supposed to create a connection to a banned peer.
+	ErrPeerBanned = errors.New("peer is banned")
 )
 
 // PeerRelation defines the type of relation to a remote peer.
@@ -93,6 +95,8 @@ type ManagerEvents struct {
 	Reconnected *event.Event1[*Peer]
 	// Fired when the relation to a peer has been updated.
 	RelationUpdated *event.Event2[*Peer, PeerRelation]
+	// Fired when a peer got banned because of its reputation.
+	Banned *event.Event3[peer.ID, time.Time, error]
 	// Fired when the Manager's state changes.
 	StateChange *event.Event1[ManagerState]
 	// Fired when internal error happens.
@@ -112,6 +116,8 @@ type ManagerOptions struct {
 	reconnectInterval time.Duration
 	// The randomized jitter applied to the reconnect interval.
 	reconnectIntervalJitter time.Duration
+	// The options of the reputation manager.
+	reputationOpts []ReputationOption
 }
 
 // ManagerOption is a function setting a ManagerOptions option.
@@ -133,6 +139,14 @@ func WithManagerReconnectInterval(interval time.Duration, jitter time.Duration)
 	}
 }
 
+// WithManagerReputationOptions defines the options of the ReputationManager
+// which is used to rate the behavior of the peers.
+func WithManagerReputationOptions(reputationOpts ...ReputationOption) ManagerOption {
+	return func(opts *ManagerOptions) {
+		opts.reputationOpts = append(opts.reputationOpts, reputationOpts...)
+	}
+}
+
 // applies the given ManagerOption.
 func (mo *ManagerOptions) apply(opts ...ManagerOption) {
 	for _, opt := range opts {
@@ -168,6 +182,7 @@ func NewManager(host host.Host, opts ...ManagerOption) *Manager {
 			Reconnecting:       event.New1[*Peer](),
 			Reconnected:        event.New1[*Peer](),
 			RelationUpdated:    event.New2[*Peer, PeerRelation](),
+			Banned:             event.New3[peer.ID, time.Time, error](),
 			StateChange:        event.New1[ManagerState](),
 			Error:              event.New1[error](),
 		},
@@ -175,6 +190,7 @@ func NewManager(host host.Host, opts ...ManagerOption) *Manager {
 		peers:                  map[peer.ID]*Peer{},
 		allowedPeers:           map[peer.ID]struct{}{},
 		opts:                   mngOpts,
+		reputation:             NewReputationManager(host.Peerstore(), mngOpts.reputationOpts...),
 		connectPeerChan:        make(chan *connectpeermsg, 10),
 		connectPeerAttemptChan: make(chan *connectpeerattemptmsg, 10),
 		reconnectChan:          make(chan *reconnectmsg, 100),
@@ -210,6 +226,8 @@ type Manager struct {
 	allowedPeers map[peer.ID]struct{}
 	// holds the manager options.
 	opts *ManagerOptions
+	// keeps track of the reputation of the peers.
+	reputation *ReputationManager
 	// tells whether the manager was shut down.
 	stopped atomic.Bool
 	// event loop channels
@@ -341,6 +359,50 @@ func (m *Manager) DisconnectPeer(peerID peer.ID, disconnectReason ...error) erro
 	return <-back
 }
 
+// Reputation returns the ReputationManager which keeps track of the reputation of the peers.
+func (m *Manager) Reputation() *ReputationManager {
+	return m.reputation
+}
+
+// ReportPeer records the given reputation event for the given peer.
+// If the reputation score of the peer drops below the configured thresholds, the peer is disconnected,
+// or banned if its relation is not PeerRelationKnown.
+// This method must not be called from within the Manager's event handlers.
+func (m *Manager) ReportPeer(peerID peer.ID, reputationEvent ReputationEvent, reason error) {
+	if m.stopped.Load() {
+		return
+	}
+
+	action := m.reputation.Record(peerID, reputationEvent)
+	if action == ReputationActionNone {
+		return
+	}
+
+	disconnectReason := errors.Errorf("reputation score too low after %s", reputationEvent)
+	if reason != nil {
+		disconnectReason = errors.WithMessagef(reason, "reputation score too low after %s", reputationEvent)
+	}
+
+	if action == ReputationActionBan {
+		var peerRelationKnown bool
+		m.Call(peerID, func(p *Peer) {
+			peerRelationKnown = p.Relation == PeerRelationKnown
+		})
+
+		// peers which were added by the operator are never banned
+		if !peerRelationKnown {
+			bannedUntil, err := m.reputation.Ban(peerID, 0)
+			if err != nil {
+				m.Events.Error.Trigger(err)
+			} else {
+				m.Events.Banned.Trigger(peerID, bannedUntil, disconnectReason)
+			}
+		}
+	}
+
+	_ = m.DisconnectPeer(peerID, disconnectReason)
+}
+
 // IsConnected tells whether there is a connection to the given peer.
 func (m *Manager) IsConnected(peerID peer.ID) bool {
 	if m.stopped.Load() {
@@ -428,6 +490,7 @@ func (m *Manager) PeerInfoSnapshot(id peer.ID) *PeerInfoSnapshot {
 	m.Call(id, func(p *Peer) {
 		info = p.InfoSnapshot()
 		info.Connected = m.host.Network().Connectedness(p.ID) == network.Connected
+		info.Reputation = m.reputation.Snapshot(p.ID)
 	})
 
 	return info
@@ -439,6 +502,7 @@ func (m *Manager) PeerInfoSnapshots() []*PeerInfoSnapshot {
 	m.ForEach(func(p *Peer) bool {
 		info := p.InfoSnapshot()
 		info.Connected = m.host.Network().Connectedness(p.ID) == network.Connected
+		info.Reputation = m.reputation.Snapshot(p.ID)
 		infos = append(infos, info)
 
 		return true
@@ -622,6 +686,13 @@ func (m *Manager) eventLoop(ctx context.Context) {
 			isConnectedReqMsg.back <- connected
 
 		case connectedMsg := <-m.connectedChan:
+			if m.isBanned(connectedMsg.conn.RemotePeer()) {
+				// drop connections of banned peers
+				_ = connectedMsg.conn.Close()
+
+				continue
+			}
+
 			p := m.peers[connectedMsg.conn.RemotePeer()]
 			m.addPeerAsUnknownIfAbsent(connectedMsg.conn)
 			if p != nil {
@@ -690,6 +761,20 @@ func (m *Manager) connectPeer(ctx context.Context, connectPeerMsg *connectpeerms
 		return
 	}
 
+	if connectPeerMsg.peerRelation != PeerRelationKnown && m.reputation.IsBanned(connectPeerMsg.addrInfo.ID) {
+		m.connectPeerAttemptChan <- &connectpeerattemptmsg{
+			addrInfo:     connectPeerMsg.addrInfo,
+			peerRelation: connectPeerMsg.peerRelation,
+			alias:        connectPeerMsg.alias,
+			// pass the error channel of the caller to the connectPeerAttemptChan
+			back:       connectPeerMsg.back,
+			connect:    false,
+			connectErr: ErrPeerBanned,
+		}
+
+		return
+	}
+
 	p := NewPeer(connectPeerMsg.addrInfo.ID, connectPeerMsg.peerRelation, connectPeerMsg.addrInfo.Addrs, connectPeerMsg.alias)
 	if p.Relation == PeerRelationKnown || p.Relation == PeerRelationAutopeered {
 		m.host.ConnManager().Protect(connectPeerMsg.addrInfo.ID, PeerConnectivityProtectionTag)
@@ -920,6 +1005,16 @@ func (m *Manager) cleanupPeerIfNotKnown(peerID peer.ID) {
 	}
 }
 
+// checks whether the given peer is banned.
+// peers with a PeerRelationKnown are never considered banned.
+func (m *Manager) isBanned(peerID peer.ID) bool {
+	if p, has := m.peers[peerID]; has && p.Relation == PeerRelationKnown {
+		return false
+	}
+
+	return m.reputation.IsBanned(peerID)
+}
+
 // checks whether the given peer is connected.
 func (m *Manager) isConnected(peerID peer.ID) bool {
 	if _, has := m.peers[peerID]; !has {
@@ -985,6 +1080,10 @@ func (m *Manager) hookEvents() (unhook func()) {
 			m.LogInfof("updated relation of %s from '%s' to '%s'", p.ID.ShortString(), oldRel, p.Relation)
 		}).Unhook,
 
+		m.Events.Banned.Hook(func(peerID peer.ID, bannedUntil time.Time, err error) {
+			m.LogWarnf("banned %s until %s: %s", peerID.ShortString(), bannedUntil.Format(time.RFC3339), err)
+		}).Unhook,
+
 		m.Events.StateChange.Hook(func(mngState ManagerState) {
 			m.LogInfo(mngState)
 		}).Unhook,
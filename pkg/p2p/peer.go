@@ -69,4 +69,6 @@ type PeerInfoSnapshot struct {
 	Connected bool `json:"connected"`
 	// The relation to the peer.
 	Relation string `json:"relation"`
+	// The reputation of the peer.
+	Reputation *ReputationSnapshot `json:"reputation,omitempty"`
 }
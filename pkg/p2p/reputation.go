@@ -0,0 +1,390 @@
+package p2p
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/runtime/syncutils"
+)
+
+// ReputationEvent is an event which changes the reputation score of a peer.
+type ReputationEvent string
+
+const (
+	// ReputationEventInvalidData is recorded if a peer sent invalid data (e.g. an invalid block).
+	ReputationEventInvalidData ReputationEvent = "invalidData"
+	// ReputationEventInvalidRequest is recorded if a peer sent a malformed request.
+	ReputationEventInvalidRequest ReputationEvent = "invalidRequest"
+	// ReputationEventDuplicateData is recorded if a peer sent the same data more than once without being asked for it.
+	ReputationEventDuplicateData ReputationEvent = "duplicateData"
+	// ReputationEventNewData is recorded if a peer was the first to send new data.
+	ReputationEventNewData ReputationEvent = "newData"
+	// ReputationEventRequestAnswered is recorded if a peer answered a request in time.
+	ReputationEventRequestAnswered ReputationEvent = "requestAnswered"
+	// ReputationEventSlowResponse is recorded if a peer answered a request, but took too long.
+	ReputationEventSlowResponse ReputationEvent = "slowResponse"
+	// ReputationEventRequestUnanswered is recorded if a peer did not answer a request.
+	ReputationEventRequestUnanswered ReputationEvent = "requestUnanswered"
+	// ReputationEventHeartbeatStale is recorded if the latest heartbeat of a peer is outdated.
+	ReputationEventHeartbeatStale ReputationEvent = "heartbeatStale"
+)
+
+// ReputationAction is the action that should be taken after the reputation score of a peer changed.
+type ReputationAction int
+
+const (
+	// ReputationActionNone means that the peer is still in good standing.
+	ReputationActionNone ReputationAction = iota
+	// ReputationActionDisconnect means that the reputation score of the peer dropped below the disconnect threshold.
+	ReputationActionDisconnect
+	// ReputationActionBan means that the reputation score of the peer dropped below the ban threshold.
+	ReputationActionBan
+)
+
+const (
+	// the key under which the end of a ban is stored in the peer store (unix timestamp in seconds).
+	reputationBannedUntilKey = "hornet/reputation/bannedUntil"
+	// the key under which the start of a ban is stored in the peer store (unix timestamp in seconds).
+	reputationBannedAtKey = "hornet/reputation/bannedAt"
+	// the interval in which reputations which decayed to a neutral score are removed.
+	reputationCleanupInterval = 5 * time.Minute
+	// reputations with a score closer to zero than this value are considered neutral.
+	reputationNeutralScore = 1.0
+)
+
+// the default options applied to the ReputationManager.
+var defaultReputationOptions = []ReputationOption{
+	WithReputationWeights(map[ReputationEvent]float64{
+		ReputationEventInvalidData:       -25.0,
+		ReputationEventInvalidRequest:    -10.0,
+		ReputationEventDuplicateData:     -0.5,
+		ReputationEventNewData:           0.1,
+		ReputationEventRequestAnswered:   0.5,
+		ReputationEventSlowResponse:      -1.0,
+		ReputationEventRequestUnanswered: -2.0,
+		ReputationEventHeartbeatStale:    -1.0,
+	}),
+	WithReputationDecayHalfLife(30 * time.Minute),
+	WithReputationMaxScore(100.0),
+	WithReputationThresholds(-50.0, -100.0),
+	WithReputationBanDuration(1 * time.Hour),
+}
+
+// ReputationOptions define options for a ReputationManager.
+type ReputationOptions struct {
+	// The score changes applied for each reputation event.
+	weights map[ReputationEvent]float64
+	// The time after which the score of a peer is halved.
+	decayHalfLife time.Duration
+	// The maximum score a peer can reach.
+	maxScore float64
+	// The score at which a peer gets disconnected.
+	disconnectThreshold float64
+	// The score at which a peer gets banned.
+	banThreshold float64
+	// The duration a peer gets banned for.
+	banDuration time.Duration
+}
+
+// ReputationOption is a function setting a ReputationOptions option.
+type ReputationOption func(opts *ReputationOptions)
+
+// WithReputationWeights sets the score changes for the given reputation events.
+// Weights of events which are not contained in the given map are kept.
+func WithReputationWeights(weights map[ReputationEvent]float64) ReputationOption {
+	return func(opts *ReputationOptions) {
+		if opts.weights == nil {
+			opts.weights = make(map[ReputationEvent]float64, len(weights))
+		}
+		for reputationEvent, weight := range weights {
+			opts.weights[reputationEvent] = weight
+		}
+	}
+}
+
+// WithReputationDecayHalfLife defines the time after which the score of a peer is halved.
+// A zero duration disables the decay.
+func WithReputationDecayHalfLife(halfLife time.Duration) ReputationOption {
+	return func(opts *ReputationOptions) {
+		opts.decayHalfLife = halfLife
+	}
+}
+
+// WithReputationMaxScore defines the maximum score a peer can reach,
+// so peers can't build up an unlimited credit.
+func WithReputationMaxScore(maxScore float64) ReputationOption {
+	return func(opts *ReputationOptions) {
+		opts.maxScore = maxScore
+	}
+}
+
+// WithReputationThresholds defines the scores at which a peer gets disconnected or banned.
+func WithReputationThresholds(disconnectThreshold float64, banThreshold float64) ReputationOption {
+	return func(opts *ReputationOptions) {
+		opts.disconnectThreshold = disconnectThreshold
+		opts.banThreshold = banThreshold
+	}
+}
+
+// WithReputationBanDuration defines the duration a peer gets banned for.
+func WithReputationBanDuration(banDuration time.Duration) ReputationOption {
+	return func(opts *ReputationOptions) {
+		opts.banDuration = banDuration
+	}
+}
+
+// applies the given ReputationOption.
+func (ro *ReputationOptions) apply(opts ...ReputationOption) {
+	for _, opt := range opts {
+		opt(ro)
+	}
+}
+
+// ReputationSnapshot represents a snapshot of the reputation of a peer.
+type ReputationSnapshot struct {
+	// The current (decayed) reputation score of the peer.
+	Score float64 `json:"score"`
+	// The amount of recorded reputation events by type.
+	Events map[ReputationEvent]uint32 `json:"events,omitempty"`
+	// The unix timestamp until the peer is banned.
+	BannedUntil int64 `json:"bannedUntil,omitempty"`
+}
+
+// ReputationBan represents an active ban of a peer due to its reputation.
+type ReputationBan struct {
+	// The banned peer.
+	PeerID peer.ID
+	// The time the peer was banned.
+	BannedAt time.Time
+	// The time the ban expires.
+	BannedUntil time.Time
+}
+
+// peerReputation holds the reputation of a single peer.
+type peerReputation struct {
+	score   float64
+	updated time.Time
+	events  map[ReputationEvent]uint32
+}
+
+// returns the score of the reputation decayed to the given point in time.
+func (r *peerReputation) scoreAt(now time.Time, halfLife time.Duration) float64 {
+	elapsed := now.Sub(r.updated)
+	if halfLife <= 0 || elapsed <= 0 {
+		return r.score
+	}
+
+	return r.score * math.Pow(0.5, float64(elapsed)/float64(halfLife))
+}
+
+// ReputationManager keeps track of the reputation of peers.
+// The reputation score of a peer is the decaying sum of the weights of all recorded reputation events.
+// Bans are persisted in the peer store, so they survive restarts of the node.
+type ReputationManager struct {
+	// used to persist the bans.
+	peerStore peerstore.Peerstore
+	// holds the reputation options.
+	opts *ReputationOptions
+
+	// mutex to secure the reputations.
+	reputationsLock syncutils.Mutex
+	// holds the reputations of the peers.
+	reputations map[peer.ID]*peerReputation
+	// the time the neutral reputations were removed the last time.
+	lastCleanup time.Time
+}
+
+// NewReputationManager creates a new ReputationManager.
+func NewReputationManager(peerStore peerstore.Peerstore, opts ...ReputationOption) *ReputationManager {
+	repOpts := &ReputationOptions{}
+	repOpts.apply(defaultReputationOptions...)
+	repOpts.apply(opts...)
+
+	return &ReputationManager{
+		peerStore:   peerStore,
+		opts:        repOpts,
+		reputations: make(map[peer.ID]*peerReputation),
+		lastCleanup: time.Now(),
+	}
+}
+
+// Record records the given reputation event for the given peer and returns
+// the action that should be taken because of the new reputation score.
+// Only negative events can lead to a disconnect or a ban.
+func (rm *ReputationManager) Record(peerID peer.ID, reputationEvent ReputationEvent) ReputationAction {
+	rm.reputationsLock.Lock()
+	defer rm.reputationsLock.Unlock()
+
+	now := time.Now()
+	rm.cleanupNeutralReputations(now)
+
+	reputation, exists := rm.reputations[peerID]
+	if !exists {
+		reputation = &peerReputation{
+			updated: now,
+			events:  make(map[ReputationEvent]uint32),
+		}
+		rm.reputations[peerID] = reputation
+	}
+
+	weight := rm.opts.weights[reputationEvent]
+
+	reputation.score = math.Min(reputation.scoreAt(now, rm.opts.decayHalfLife)+weight, rm.opts.maxScore)
+	reputation.updated = now
+	reputation.events[reputationEvent]++
+
+	if weight >= 0 {
+		return ReputationActionNone
+	}
+
+	switch {
+	case reputation.score <= rm.opts.banThreshold:
+		return ReputationActionBan
+	case reputation.score <= rm.opts.disconnectThreshold:
+		return ReputationActionDisconnect
+	default:
+		return ReputationActionNone
+	}
+}
+
+// Score returns the current reputation score of the given peer.
+func (rm *ReputationManager) Score(peerID peer.ID) float64 {
+	rm.reputationsLock.Lock()
+	defer rm.reputationsLock.Unlock()
+
+	reputation, exists := rm.reputations[peerID]
+	if !exists {
+		return 0
+	}
+
+	return reputation.scoreAt(time.Now(), rm.opts.decayHalfLife)
+}
+
+// Snapshot returns a snapshot of the reputation of the given peer.
+func (rm *ReputationManager) Snapshot(peerID peer.ID) *ReputationSnapshot {
+	snapshot := &ReputationSnapshot{}
+
+	if bannedUntil, banned := rm.BannedUntil(peerID); banned {
+		snapshot.BannedUntil = bannedUntil.Unix()
+	}
+
+	rm.reputationsLock.Lock()
+	defer rm.reputationsLock.Unlock()
+
+	reputation, exists := rm.reputations[peerID]
+	if !exists {
+		return snapshot
+	}
+
+	snapshot.Score = reputation.scoreAt(time.Now(), rm.opts.decayHalfLife)
+	snapshot.Events = make(map[ReputationEvent]uint32, len(reputation.events))
+	for reputationEvent, count := range reputation.events {
+		snapshot.Events[reputationEvent] = count
+	}
+
+	return snapshot
+}
+
+// Ban bans the given peer for the given duration and persists the ban in the peer store.
+// If the duration is zero, the configured ban duration is used.
+func (rm *ReputationManager) Ban(peerID peer.ID, duration time.Duration) (time.Time, error) {
+	if duration == 0 {
+		duration = rm.opts.banDuration
+	}
+
+	bannedAt := time.Now()
+	bannedUntil := bannedAt.Add(duration)
+	if err := rm.peerStore.Put(peerID, reputationBannedAtKey, bannedAt.Unix()); err != nil {
+		return time.Time{}, errors.Wrapf(err, "unable to store ban of peer %s", peerID.ShortString())
+	}
+	if err := rm.peerStore.Put(peerID, reputationBannedUntilKey, bannedUntil.Unix()); err != nil {
+		return time.Time{}, errors.Wrapf(err, "unable to store ban of peer %s", peerID.ShortString())
+	}
+
+	return bannedUntil, nil
+}
+
+// Unban lifts the ban of the given peer.
+func (rm *ReputationManager) Unban(peerID peer.ID) error {
+	if err := rm.peerStore.Put(peerID, reputationBannedUntilKey, int64(0)); err != nil {
+		return errors.Wrapf(err, "unable to remove ban of peer %s", peerID.ShortString())
+	}
+
+	return nil
+}
+
+// BannedUntil returns the time until the given peer is banned.
+// Returns false if the peer is not banned.
+func (rm *ReputationManager) BannedUntil(peerID peer.ID) (time.Time, bool) {
+	value, err := rm.peerStore.Get(peerID, reputationBannedUntilKey)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	bannedUntilUnix, ok := value.(int64)
+	if !ok || bannedUntilUnix == 0 {
+		return time.Time{}, false
+	}
+
+	bannedUntil := time.Unix(bannedUntilUnix, 0)
+	if !time.Now().Before(bannedUntil) {
+		return time.Time{}, false
+	}
+
+	return bannedUntil, true
+}
+
+// IsBanned tells whether the given peer is currently banned.
+func (rm *ReputationManager) IsBanned(peerID peer.ID) bool {
+	_, banned := rm.BannedUntil(peerID)
+
+	return banned
+}
+
+// Bans returns the active bans of all peers known to the peer store sorted by the time they were banned.
+func (rm *ReputationManager) Bans() []*ReputationBan {
+	bans := make([]*ReputationBan, 0)
+	for _, peerID := range rm.peerStore.Peers() {
+		bannedUntil, banned := rm.BannedUntil(peerID)
+		if !banned {
+			continue
+		}
+
+		ban := &ReputationBan{
+			PeerID:      peerID,
+			BannedUntil: bannedUntil,
+		}
+		if value, err := rm.peerStore.Get(peerID, reputationBannedAtKey); err == nil {
+			if bannedAtUnix, ok := value.(int64); ok {
+				ban.BannedAt = time.Unix(bannedAtUnix, 0)
+			}
+		}
+		bans = append(bans, ban)
+	}
+
+	sort.Slice(bans, func(i, j int) bool {
+		return bans[i].BannedAt.Before(bans[j].BannedAt)
+	})
+
+	return bans
+}
+
+// removes the reputations which decayed to a neutral score, so the amount of tracked peers doesn't grow forever.
+// the lock must be held by the caller.
+func (rm *ReputationManager) cleanupNeutralReputations(now time.Time) {
+	if now.Sub(rm.lastCleanup) < reputationCleanupInterval {
+		return
+	}
+	rm.lastCleanup = now
+
+	for peerID, reputation := range rm.reputations {
+		if math.Abs(reputation.scoreAt(now, rm.opts.decayHalfLife)) < reputationNeutralScore {
+			delete(rm.reputations, peerID)
+		}
+	}
+}
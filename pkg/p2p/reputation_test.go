@@ -0,0 +1,231 @@
+//nolint:forcetypeassert,varnamelen,revive,exhaustruct // we don't care about these linters in test cases
+package p2p_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/host/peerstore/pstoremem"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/app/configuration"
+	appLogger "github.com/iotaledger/hive.go/app/logger"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hornet/v2/pkg/p2p"
+)
+
+func randPeerID(t *testing.T) peer.ID {
+	_, pk, err := crypto.GenerateKeyPair(crypto.Ed25519, -1)
+	require.NoError(t, err)
+
+	peerID, err := peer.IDFromPublicKey(pk)
+	require.NoError(t, err)
+
+	return peerID
+}
+
+func TestReputationThresholds(t *testing.T) {
+	peerStore, err := pstoremem.NewPeerstore()
+	require.NoError(t, err)
+	defer peerStore.Close()
+
+	reputation := p2p.NewReputationManager(peerStore,
+		p2p.WithReputationDecayHalfLife(0),
+		p2p.WithReputationMaxScore(10),
+		p2p.WithReputationThresholds(-20, -40),
+		p2p.WithReputationWeights(map[p2p.ReputationEvent]float64{
+			p2p.ReputationEventInvalidData: -15,
+			p2p.ReputationEventNewData:     1,
+		}),
+	)
+
+	peerID := randPeerID(t)
+
+	// the score is capped at the max score
+	for i := 0; i < 20; i++ {
+		require.Equal(t, p2p.ReputationActionNone, reputation.Record(peerID, p2p.ReputationEventNewData))
+	}
+	require.Equal(t, 10.0, reputation.Score(peerID))
+
+	require.Equal(t, p2p.ReputationActionNone, reputation.Record(peerID, p2p.ReputationEventInvalidData))
+	require.Equal(t, p2p.ReputationActionDisconnect, reputation.Record(peerID, p2p.ReputationEventInvalidData))
+
+	// positive events never lead to an action, even if the score is below the thresholds
+	require.Equal(t, p2p.ReputationActionNone, reputation.Record(peerID, p2p.ReputationEventNewData))
+
+	require.Equal(t, p2p.ReputationActionDisconnect, reputation.Record(peerID, p2p.ReputationEventInvalidData))
+	require.Equal(t, p2p.ReputationActionBan, reputation.Record(peerID, p2p.ReputationEventInvalidData))
+	require.Equal(t, -49.0, reputation.Score(peerID))
+
+	snapshot := reputation.Snapshot(peerID)
+	require.Equal(t, -49.0, snapshot.Score)
+	require.Equal(t, map[p2p.ReputationEvent]uint32{
+		p2p.ReputationEventNewData:     21,
+		p2p.ReputationEventInvalidData: 4,
+	}, snapshot.Events)
+	require.Zero(t, snapshot.BannedUntil)
+
+	// other peers are not affected
+	require.Equal(t, &p2p.ReputationSnapshot{}, reputation.Snapshot(randPeerID(t)))
+}
+
+func TestReputationDecay(t *testing.T) {
+	peerStore, err := pstoremem.NewPeerstore()
+	require.NoError(t, err)
+	defer peerStore.Close()
+
+	reputation := p2p.NewReputationManager(peerStore,
+		p2p.WithReputationDecayHalfLife(100*time.Millisecond),
+		p2p.WithReputationWeights(map[p2p.ReputationEvent]float64{
+			p2p.ReputationEventInvalidData: -40,
+		}),
+	)
+
+	peerID := randPeerID(t)
+	reputation.Record(peerID, p2p.ReputationEventInvalidData)
+	require.InDelta(t, -40.0, reputation.Score(peerID), 5)
+
+	// the score decays towards zero
+	require.Eventually(t, func() bool {
+		return reputation.Score(peerID) > -10.0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// a decayed score does not lead to a disconnect anymore
+	require.Equal(t, p2p.ReputationActionNone, reputation.Record(peerID, p2p.ReputationEventInvalidData))
+}
+
+func TestReputationBan(t *testing.T) {
+	peerStore, err := pstoremem.NewPeerstore()
+	require.NoError(t, err)
+	defer peerStore.Close()
+
+	peerID := randPeerID(t)
+
+	reputation := p2p.NewReputationManager(peerStore, p2p.WithReputationBanDuration(time.Hour))
+	require.False(t, reputation.IsBanned(peerID))
+
+	bannedUntil, err := reputation.Ban(peerID, 0)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(time.Hour), bannedUntil, time.Minute)
+	require.True(t, reputation.IsBanned(peerID))
+	require.Equal(t, bannedUntil.Unix(), reputation.Snapshot(peerID).BannedUntil)
+
+	// the ban is persisted in the peer store
+	reputation = p2p.NewReputationManager(peerStore)
+	bannedUntilStored, banned := reputation.BannedUntil(peerID)
+	require.True(t, banned)
+	require.Equal(t, bannedUntil.Unix(), bannedUntilStored.Unix())
+
+	require.NoError(t, reputation.Unban(peerID))
+	require.False(t, reputation.IsBanned(peerID))
+
+	// expired bans are ignored
+	_, err = reputation.Ban(peerID, -time.Second)
+	require.NoError(t, err)
+	require.False(t, reputation.IsBanned(peerID))
+}
+
+func TestReputationBans(t *testing.T) {
+	peerStore, err := pstoremem.NewPeerstore()
+	require.NoError(t, err)
+	defer peerStore.Close()
+
+	reputation := p2p.NewReputationManager(peerStore, p2p.WithReputationBanDuration(time.Hour))
+
+	// only peers known to the peer store are listed
+	peerIDs := make([]peer.ID, 3)
+	for i := range peerIDs {
+		peerIDs[i] = randPeerID(t)
+		peerStore.AddAddr(peerIDs[i], multiaddr.StringCast(fmt.Sprintf("/ip4/127.0.0.1/tcp/%d", 15600+i)), time.Hour)
+	}
+	require.Empty(t, reputation.Bans())
+
+	bannedUntil, err := reputation.Ban(peerIDs[0], 0)
+	require.NoError(t, err)
+	_, err = reputation.Ban(peerIDs[1], 0)
+	require.NoError(t, err)
+	require.NoError(t, reputation.Unban(peerIDs[1]))
+
+	bans := reputation.Bans()
+	require.Len(t, bans, 1)
+	require.Equal(t, peerIDs[0], bans[0].PeerID)
+	require.Equal(t, bannedUntil.Unix(), bans[0].BannedUntil.Unix())
+	require.WithinDuration(t, time.Now(), bans[0].BannedAt, time.Minute)
+}
+
+func TestManagerReportPeer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := configuration.New()
+	err := cfg.Set("logger.disableStacktrace", true)
+	require.NoError(t, err)
+
+	// no need to check the error, since the global logger could already be initialized
+	_ = appLogger.InitGlobalLogger(cfg)
+
+	reputationOpt := p2p.WithManagerReputationOptions(
+		p2p.WithReputationThresholds(-20, -40),
+		p2p.WithReputationWeights(map[p2p.ReputationEvent]float64{
+			p2p.ReputationEventInvalidData: -15,
+		}),
+	)
+
+	node1 := newNode(t)
+	node1Logger := logger.NewLogger(fmt.Sprintf("node1/%s", node1.ID().ShortString()))
+	node1Manager := p2p.NewManager(node1, p2p.WithManagerLogger(node1Logger), reputationOpt)
+	go node1Manager.Start(ctx)
+	node1AddrInfo := &peer.AddrInfo{ID: node1.ID(), Addrs: node1.Addrs()[:1]}
+
+	node2 := newNode(t)
+	node2Logger := logger.NewLogger(fmt.Sprintf("node2/%s", node2.ID().ShortString()))
+	node2Manager := p2p.NewManager(node2, p2p.WithManagerLogger(node2Logger), reputationOpt)
+	go node2Manager.Start(ctx)
+	node2AddrInfo := &peer.AddrInfo{ID: node2.ID(), Addrs: node2.Addrs()[:1]}
+
+	var bannedPeerID peer.ID
+	node2Manager.Events.Banned.Hook(func(peerID peer.ID, _ time.Time, _ error) {
+		bannedPeerID = peerID
+	})
+
+	require.NoError(t, node1Manager.ConnectPeer(node2AddrInfo, p2p.PeerRelationUnknown))
+	connectivity(t, node1Manager, node2.ID(), false)
+	connectivity(t, node2Manager, node1.ID(), false)
+
+	// the reputation is visible in the peer info
+	node2Manager.ReportPeer(node1.ID(), p2p.ReputationEventInvalidData, errors.New("invalid block"))
+	require.InDelta(t, -15.0, node2Manager.PeerInfoSnapshot(node1.ID()).Reputation.Score, 0.1)
+	connectivity(t, node2Manager, node1.ID(), false)
+
+	// the peer is disconnected if the score drops below the disconnect threshold
+	node2Manager.ReportPeer(node1.ID(), p2p.ReputationEventInvalidData, errors.New("invalid block"))
+	connectivity(t, node2Manager, node1.ID(), true)
+	connectivity(t, node1Manager, node2.ID(), true)
+	require.False(t, node2Manager.Reputation().IsBanned(node1.ID()))
+
+	// the score is kept after the reconnect, so the peer gets banned
+	require.NoError(t, node1Manager.ConnectPeer(node2AddrInfo, p2p.PeerRelationUnknown))
+	connectivity(t, node2Manager, node1.ID(), false)
+	node2Manager.ReportPeer(node1.ID(), p2p.ReputationEventInvalidData, errors.New("invalid block"))
+	connectivity(t, node2Manager, node1.ID(), true)
+	require.True(t, node2Manager.Reputation().IsBanned(node1.ID()))
+	require.Equal(t, node1.ID(), bannedPeerID)
+
+	// connections to banned peers are not allowed
+	require.ErrorIs(t, node2Manager.ConnectPeer(node1AddrInfo, p2p.PeerRelationUnknown), p2p.ErrPeerBanned)
+
+	// inbound connections of banned peers are dropped
+	require.NoError(t, node1Manager.ConnectPeer(node2AddrInfo, p2p.PeerRelationUnknown))
+	connectivity(t, node1Manager, node2.ID(), true)
+	require.False(t, node2Manager.IsConnected(node1.ID()))
+
+	// known peers are allowed to connect nevertheless
+	require.NoError(t, node2Manager.ConnectPeer(node1AddrInfo, p2p.PeerRelationKnown))
+	connectivity(t, node2Manager, node1.ID(), false)
+}
@@ -0,0 +1,201 @@
+package gossip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hornet/v2/pkg/protocol/protocol/tlv"
+)
+
+var (
+	// ErrHandshakeTimeout is returned if a peer didn't send its handshake in time.
+	ErrHandshakeTimeout = errors.New("handshake timeout")
+)
+
+// Features are the optional features of the gossip protocol supported by a peer.
+type Features uint32
+
+const (
+	// FeatureBatching means that the peer supports batched block requests and batched block messages.
+	FeatureBatching Features = 1 << iota
+	// FeatureCompression means that the peer supports compressed messages.
+	FeatureCompression
+	// FeatureMilestoneConeRequests means that the peer supports milestone cone requests.
+	FeatureMilestoneConeRequests
+)
+
+// SupportedFeatures are the optional features of the gossip protocol supported by this node.
+const SupportedFeatures = FeatureBatching | FeatureMilestoneConeRequests
+
+// the names of the features.
+var featureNames = []struct {
+	feature Features
+	name    string
+}{
+	{FeatureBatching, "batching"},
+	{FeatureCompression, "compression"},
+	{FeatureMilestoneConeRequests, "milestoneConeRequests"},
+}
+
+// Has tells whether the given feature is contained.
+func (f Features) Has(feature Features) bool {
+	return f&feature == feature
+}
+
+// Names returns the names of the contained features.
+// Unknown features are ignored.
+func (f Features) Names() []string {
+	names := make([]string, 0, len(featureNames))
+	for _, featureName := range featureNames {
+		if f.Has(featureName.feature) {
+			names = append(names, featureName.name)
+		}
+	}
+
+	return names
+}
+
+// MarshalJSON marshals the features as a list of their names.
+func (f Features) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.Names())
+}
+
+// Handshake contains information about the gossip protocol of a peer.
+// It is exchanged when a gossip protocol stream is opened.
+type Handshake struct {
+	// The ID of the network the peer belongs to.
+	NetworkID uint64 `json:"networkId,string"`
+	// The versions of the gossip protocol supported by the peer.
+	Versions []string `json:"versions"`
+	// The optional features of the gossip protocol supported by the peer.
+	Features Features `json:"features"`
+	// The name of the node software of the peer.
+	AppName string `json:"appName"`
+	// The version of the node software of the peer.
+	AppVersion string `json:"appVersion"`
+}
+
+// writes a string prefixed with its length.
+func writeHandshakeString(buf *bytes.Buffer, value string) error {
+	if len(value) > handshakeMaxStringLength {
+		return fmt.Errorf("handshake string exceeds max length: %d bytes", len(value))
+	}
+
+	if err := buf.WriteByte(byte(len(value))); err != nil {
+		return err
+	}
+
+	_, err := buf.WriteString(value)
+
+	return err
+}
+
+// newHandshakeMessage creates a new handshake message.
+func newHandshakeMessage(handshake *Handshake) ([]byte, error) {
+	if len(handshake.Versions) > handshakeMaxVersions {
+		return nil, fmt.Errorf("too many versions in handshake: %d", len(handshake.Versions))
+	}
+
+	payload := bytes.NewBuffer(make([]byte, 0, handshakeMessageDefinition.MaxBytesLength))
+
+	if err := binary.Write(payload, binary.LittleEndian, handshake.NetworkID); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(payload, binary.LittleEndian, uint32(handshake.Features)); err != nil {
+		return nil, err
+	}
+
+	if err := payload.WriteByte(byte(len(handshake.Versions))); err != nil {
+		return nil, err
+	}
+
+	for _, version := range handshake.Versions {
+		if err := writeHandshakeString(payload, version); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writeHandshakeString(payload, handshake.AppName); err != nil {
+		return nil, err
+	}
+
+	if err := writeHandshakeString(payload, handshake.AppVersion); err != nil {
+		return nil, err
+	}
+
+	if payload.Len() > int(handshakeMessageDefinition.MaxBytesLength) {
+		return nil, fmt.Errorf("handshake exceeds max size: %d bytes", payload.Len())
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, int(tlv.HeaderMessageDefinition.MaxBytesLength)+payload.Len()))
+	if err := tlv.WriteHeader(buf, MessageTypeHandshake, uint16(payload.Len())); err != nil {
+		return nil, err
+	}
+
+	if _, err := buf.Write(payload.Bytes()); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// reads a string prefixed with its length.
+func readHandshakeString(reader *bytes.Reader) (string, error) {
+	length, err := reader.ReadByte()
+	if err != nil {
+		return "", ErrInvalidSourceLength
+	}
+
+	value := make([]byte, length)
+	if _, err := io.ReadFull(reader, value); err != nil {
+		return "", ErrInvalidSourceLength
+	}
+
+	return string(value), nil
+}
+
+// ParseHandshake parses the given message into a handshake.
+func ParseHandshake(data []byte) (*Handshake, error) {
+	if len(data) < handshakeMinBytesLength {
+		return nil, ErrInvalidSourceLength
+	}
+
+	handshake := &Handshake{
+		NetworkID: binary.LittleEndian.Uint64(data[:8]),
+		Features:  Features(binary.LittleEndian.Uint32(data[8:12])),
+	}
+
+	reader := bytes.NewReader(data[12:])
+
+	versionsCount, err := reader.ReadByte()
+	if err != nil {
+		return nil, ErrInvalidSourceLength
+	}
+
+	handshake.Versions = make([]string, versionsCount)
+	for i := range handshake.Versions {
+		if handshake.Versions[i], err = readHandshakeString(reader); err != nil {
+			return nil, err
+		}
+	}
+
+	if handshake.AppName, err = readHandshakeString(reader); err != nil {
+		return nil, err
+	}
+
+	if handshake.AppVersion, err = readHandshakeString(reader); err != nil {
+		return nil, err
+	}
+
+	if reader.Len() != 0 {
+		return nil, ErrInvalidSourceLength
+	}
+
+	return handshake, nil
+}
@@ -17,6 +17,10 @@ func init() {
 		blockMessageDefinition,
 		blockRequestMessageDefinition,
 		heartbeatMessageDefinition,
+		blockRequestsMessageDefinition,
+		milestoneConeRequestMessageDefinition,
+		blocksMessageDefinition,
+		handshakeMessageDefinition,
 	}
 	gossipMessageRegistry = hiveproto.NewRegistry(definitions)
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
 
 	"github.com/iotaledger/hive.go/objectstorage"
 	"github.com/iotaledger/hive.go/runtime/event"
@@ -28,11 +29,19 @@ import (
 
 const (
 	WorkerCount = 64
+
+	// defines the maximum amount of blocks sent as an answer to a milestone cone request.
+	maxMilestoneConeBlocks = 10000
+	// defines the maximum size of the blocks sent as an answer to a milestone cone request.
+	maxMilestoneConeBytes = 8 * 1024 * 1024
 )
 
 var (
 	ErrBlockNotSolid      = errors.New("block is not solid")
 	ErrBlockBelowMaxDepth = errors.New("block is below max depth")
+
+	// errMilestoneConeTooLarge is returned if the cone of a requested milestone contains too many blocks.
+	errMilestoneConeTooLarge = errors.New("milestone cone too large")
 )
 
 // Broadcast defines a data which should be broadcasted.
@@ -54,6 +63,9 @@ type MessageProcessorEvents struct {
 // The Options for the MessageProcessor.
 type Options struct {
 	WorkUnitCacheOpts *profile.CacheOpts
+	// The time after which the answer of a peer to a request is considered slow.
+	// A zero duration disables the rating of slow answers.
+	SlowResponseThreshold time.Duration
 }
 
 // MessageProcessor processes submitted messages in parallel and fires appropriate completion events.
@@ -167,11 +179,17 @@ func (proc *MessageProcessor) Process(p *Protocol, msgType message.Type, data []
 	proc.wp.Submit(func() {
 		switch msgType {
 		case MessageTypeBlock:
-			proc.processBlockData(p, data)
+			proc.processBlockData(p, data, false)
 		case MessageTypeBlockRequest:
 			proc.processBlockRequest(p, data)
 		case MessageTypeMilestoneRequest:
 			proc.processMilestoneRequest(p, data)
+		case MessageTypeBlockRequests:
+			proc.processBlockRequests(p, data)
+		case MessageTypeMilestoneConeRequest:
+			proc.processMilestoneConeRequest(p, data)
+		case MessageTypeBlocks:
+			proc.processBlocks(p, data)
 		}
 	})
 }
@@ -282,8 +300,8 @@ func (proc *MessageProcessor) processMilestoneRequest(p *Protocol, data []byte)
 	if err != nil {
 		proc.serverMetrics.InvalidRequests.Inc()
 
-		// drop the connection to the peer
-		_ = proc.peeringManager.DisconnectPeer(p.PeerID, errors.WithMessage(err, "processMilestoneRequest failed"))
+		// lower the reputation of the peer
+		proc.peeringManager.ReportPeer(p.PeerID, p2p.ReputationEventInvalidRequest, errors.WithMessage(err, "processMilestoneRequest failed"))
 
 		return
 	}
@@ -356,23 +374,182 @@ func (proc *MessageProcessor) processBlockRequest(p *Protocol, data []byte) {
 	p.Enqueue(msg)
 }
 
+// processes the given batched block request by parsing it and then replying to the peer with the known blocks.
+func (proc *MessageProcessor) processBlockRequests(p *Protocol, data []byte) {
+	blockIDs, err := extractRequestedBlockIDs(data)
+	if err != nil {
+		proc.serverMetrics.InvalidRequests.Inc()
+
+		// lower the reputation of the peer
+		proc.peeringManager.ReportPeer(p.PeerID, p2p.ReputationEventInvalidRequest, errors.WithMessage(err, "processBlockRequests failed"))
+
+		return
+	}
+
+	blocksData := make([][]byte, 0, len(blockIDs))
+	for _, blockID := range blockIDs {
+		cachedBlock := proc.storage.CachedBlockOrNil(blockID) // block +1
+		if cachedBlock == nil {
+			// can't reply if we don't have the requested block
+			continue
+		}
+		blocksData = append(blocksData, cachedBlock.Block().Data())
+		cachedBlock.Release(true) // block -1
+	}
+
+	if len(blocksData) == 0 {
+		return
+	}
+
+	p.SendBlocks(0, blocksData)
+}
+
+// processes the given milestone cone request by parsing it and then replying to the peer
+// with all blocks referenced by the requested milestone.
+func (proc *MessageProcessor) processMilestoneConeRequest(p *Protocol, data []byte) {
+	msIndex, err := extractRequestedMilestoneIndex(data)
+	if err != nil || msIndex == latestMilestoneRequestIndex {
+		if err == nil {
+			err = errors.New("milestone cone of the latest milestone can't be requested")
+		}
+
+		proc.serverMetrics.InvalidRequests.Inc()
+
+		// lower the reputation of the peer
+		proc.peeringManager.ReportPeer(p.PeerID, p2p.ReputationEventInvalidRequest, errors.WithMessage(err, "processMilestoneConeRequest failed"))
+
+		return
+	}
+
+	if msIndex > proc.syncManager.ConfirmedMilestoneIndex() {
+		// can't reply if the milestone is not confirmed yet
+		return
+	}
+
+	// the request is dropped if the peer sends too many milestone cone requests,
+	// the peer requests the blocks by ID after the request timed out.
+	if !p.acquireMilestoneConeRequest() {
+		return
+	}
+	defer p.releaseMilestoneConeRequest()
+
+	cachedMilestone := proc.storage.CachedMilestoneByIndexOrNil(msIndex) // milestone +1
+	if cachedMilestone == nil {
+		// can't reply if we don't have the wanted milestone
+		return
+	}
+	parents := cachedMilestone.Milestone().Parents()
+	cachedMilestone.Release(true) // milestone -1
+
+	var blocksData [][]byte
+	var blocksBytes int
+
+	// we pass a background context here to answer the request even if the node is shutting down.
+	if err := dag.TraverseMilestoneCone(
+		context.Background(),
+		proc.storage,
+		msIndex,
+		parents,
+		// consumer
+		func(cachedBlockMeta *storage.CachedMetadata) error { // meta +1
+			defer cachedBlockMeta.Release(true) // meta -1
+
+			if len(blocksData) >= maxMilestoneConeBlocks || blocksBytes >= maxMilestoneConeBytes {
+				return errMilestoneConeTooLarge
+			}
+
+			cachedBlock := proc.storage.CachedBlockOrNil(cachedBlockMeta.Metadata().BlockID()) // block +1
+			if cachedBlock == nil {
+				return nil
+			}
+			defer cachedBlock.Release(true) // block -1
+
+			blocksData = append(blocksData, cachedBlock.Block().Data())
+			blocksBytes += len(cachedBlock.Block().Data())
+
+			return nil
+		},
+	); err != nil && !errors.Is(err, errMilestoneConeTooLarge) {
+		// can't reply if the traversal failed
+		return
+	}
+
+	if len(blocksData) == 0 {
+		return
+	}
+
+	// if the cone is too large, only a part of it is sent.
+	// the peer requests the remaining blocks by ID during solidification.
+	p.SendBlocks(msIndex, blocksData)
+}
+
+// processes the given batched block message by parsing it and then processing the contained blocks.
+func (proc *MessageProcessor) processBlocks(p *Protocol, data []byte) {
+	coneIndex, blocksData, err := extractBlocks(data)
+	if err != nil {
+		proc.serverMetrics.InvalidBlocks.Inc()
+
+		// lower the reputation of the peer
+		proc.peeringManager.ReportPeer(p.PeerID, p2p.ReputationEventInvalidData, errors.WithMessage(err, "processBlocks failed"))
+
+		return
+	}
+
+	coneRequested := coneIndex != 0 && p.isMilestoneConeRequested(coneIndex)
+
+	for _, blockData := range blocksData {
+		p.Metrics.ReceivedBlocks.Inc()
+		proc.serverMetrics.Blocks.Inc()
+
+		proc.processBlockData(p, blockData, coneRequested)
+	}
+}
+
 // gets or creates a new WorkUnit for the given block data and then processes the WorkUnit.
-func (proc *MessageProcessor) processBlockData(p *Protocol, data []byte) {
+// coneRequested tells whether the block was sent as part of a milestone cone requested from the peer.
+func (proc *MessageProcessor) processBlockData(p *Protocol, data []byte, coneRequested bool) {
 	cachedWorkUnit, newlyAdded := proc.workUnitFor(data) // workUnit +1
 
 	// force release if not newly added, so the cache time is only active the first time the block is received.
 	defer cachedWorkUnit.Release(!newlyAdded) // workUnit -1
 
 	workUnit := cachedWorkUnit.WorkUnit()
-	workUnit.addReceivedFrom(p)
-	proc.processWorkUnit(workUnit, p)
+	firstFromPeer := workUnit.addReceivedFrom(p)
+
+	// blocks of a requested milestone cone may have been requested by ID from the same peer before.
+	proc.processWorkUnit(workUnit, p, !firstFromPeer && !coneRequested, coneRequested)
+}
+
+// rates the peer that sent the given valid block.
+// peers are rewarded for answering requests and for being the first to send new blocks,
+// and penalized for slow answers and for sending the same block again without being asked for it.
+func (proc *MessageProcessor) rateBlockSender(p *Protocol, block *storage.Block, isMilestonePayload bool, firstReceived bool, duplicate bool) {
+	latency, requested := p.requestAnswered(block.BlockID())
+	if isMilestonePayload {
+		if msLatency, msRequested := p.requestAnswered(block.Milestone().Index); msRequested && !requested {
+			latency, requested = msLatency, true
+		}
+	}
+
+	switch {
+	case requested && proc.opts.SlowResponseThreshold > 0 && latency > proc.opts.SlowResponseThreshold:
+		proc.peeringManager.ReportPeer(p.PeerID, p2p.ReputationEventSlowResponse, nil)
+	case requested:
+		proc.peeringManager.ReportPeer(p.PeerID, p2p.ReputationEventRequestAnswered, nil)
+	case duplicate:
+		proc.peeringManager.ReportPeer(p.PeerID, p2p.ReputationEventDuplicateData, errors.New("peer sent the same block multiple times"))
+	case firstReceived:
+		proc.peeringManager.ReportPeer(p.PeerID, p2p.ReputationEventNewData, nil)
+	}
 }
 
 // tries to process the WorkUnit by first checking in what state it is.
 // if the WorkUnit is invalid (because the underlying block is invalid), the given peer is punished.
 // if the WorkUnit is already completed, and the block was requested, this function emits a BlockProcessed event.
+// duplicate tells whether the given peer sent the block of the WorkUnit before.
+// coneRequested tells whether the block was sent as part of a milestone cone requested from the peer.
 // it is safe to call this function for the same WorkUnit multiple times.
-func (proc *MessageProcessor) processWorkUnit(wu *WorkUnit, p *Protocol) {
+func (proc *MessageProcessor) processWorkUnit(wu *WorkUnit, p *Protocol, duplicate bool, coneRequested bool) {
 
 	processRequests := func(wu *WorkUnit, block *storage.Block, isMilestonePayload bool) Requests {
 
@@ -406,9 +583,10 @@ func (proc *MessageProcessor) processWorkUnit(wu *WorkUnit, p *Protocol) {
 		// we ignore all received blocks if we didn't request them and it's not a milestone.
 		// otherwise these blocks would get evicted from the cache, and it's heavier to load them
 		// from the storage than to request them again.
+		// blocks of requested milestone cones are processed, because they are needed to solidify the milestone.
 		// ATTENTION: we use requests.HasRequest() here instead of wu.requested because
 		// we only want to trigger the BlockProcessed event with the correct requests.
-		if !requests.HasRequest() && !proc.syncManager.IsNodeAlmostSynced() && !isMilestonePayload {
+		if !requests.HasRequest() && !coneRequested && !proc.syncManager.IsNodeAlmostSynced() && !isMilestonePayload {
 			return
 		}
 
@@ -421,6 +599,10 @@ func (proc *MessageProcessor) processWorkUnit(wu *WorkUnit, p *Protocol) {
 	case wu.Is(Hashing):
 		wu.processingLock.Unlock()
 
+		// the block is still being processed, but the request sent to the peer was answered nevertheless.
+		// the block ID is the hash of the serialized block.
+		p.requestAnswered(iotago.BlockID(blake2b.Sum256(wu.receivedBytes)))
+
 		return
 
 	case wu.Is(Invalid):
@@ -428,8 +610,8 @@ func (proc *MessageProcessor) processWorkUnit(wu *WorkUnit, p *Protocol) {
 
 		proc.serverMetrics.InvalidBlocks.Inc()
 
-		// drop the connection to the peer
-		_ = proc.peeringManager.DisconnectPeer(p.PeerID, errors.New("peer sent an invalid block"))
+		// lower the reputation of the peer
+		proc.peeringManager.ReportPeer(p.PeerID, p2p.ReputationEventInvalidData, errors.New("peer sent an invalid block"))
 
 		return
 
@@ -442,6 +624,8 @@ func (proc *MessageProcessor) processWorkUnit(wu *WorkUnit, p *Protocol) {
 		// between processing received blocks and enqueuing requests.
 		requests := processRequests(wu, wu.block, isMilestonePayload)
 
+		proc.rateBlockSender(p, wu.block, isMilestonePayload, false, duplicate)
+
 		processBlock(wu.block, isMilestonePayload, requests, p)
 
 		return
@@ -499,6 +683,8 @@ func (proc *MessageProcessor) processWorkUnit(wu *WorkUnit, p *Protocol) {
 	// increase the known block count for all other peers
 	wu.increaseKnownTxCount(p)
 
+	proc.rateBlockSender(p, block, isMilestonePayload, true, duplicate)
+
 	processBlock(block, isMilestonePayload, requests, p)
 }
 
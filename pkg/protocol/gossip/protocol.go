@@ -7,7 +7,9 @@ import (
 
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
 	"go.uber.org/atomic"
+	"golang.org/x/time/rate"
 
 	"github.com/iotaledger/hive.go/runtime/event"
 	"github.com/iotaledger/hornet/v2/pkg/metrics"
@@ -19,6 +21,25 @@ const (
 	// defines how far back a node's confirmed milestone index can be
 	// but still considered synchronized.
 	minCMISynchronizationThreshold = 2
+
+	// defines the maximum amount of requests sent to a peer which are tracked until they are answered.
+	maxTrackedRequests = 10000
+
+	// defines the weight of a new sample in the moving average of the request latency of a peer.
+	requestLatencyAvgWeight = 0.1
+
+	// defines the request latency assumed for peers which did not answer any request yet.
+	defaultRequestLatency = 100 * time.Millisecond
+
+	// defines the time in which blocks of a requested milestone cone are accepted from a peer.
+	milestoneConeRequestTimeout = 1 * time.Minute
+
+	// defines the rate of milestone cone requests per second that are served to a peer.
+	milestoneConeRequestsPerSecond = 5
+	// defines the amount of milestone cone requests of a peer that are served in a burst.
+	milestoneConeRequestsBurst = 20
+	// defines the maximum amount of milestone cone requests of a peer that are served at the same time.
+	maxMilestoneConeRequestsInFlight = 2
 )
 
 // ProtocolEvents happening on a Protocol.
@@ -54,12 +75,15 @@ func NewProtocol(peerID peer.ID, stream network.Stream, sendQueueSize int, readT
 			Sent:   sentEvents,
 			Errors: event.New1[error](),
 		},
-		Stream:         stream,
-		terminatedChan: make(chan struct{}),
-		SendQueue:      make(chan []byte, sendQueueSize),
-		readTimeout:    readTimeout,
-		writeTimeout:   writeTimeout,
-		ServerMetrics:  serverMetrics,
+		Stream:              stream,
+		terminatedChan:      make(chan struct{}),
+		SendQueue:           make(chan []byte, sendQueueSize),
+		sentRequests:        make(map[string]time.Time),
+		requestedCones:      make(map[iotago.MilestoneIndex]time.Time),
+		coneRequestsLimiter: rate.NewLimiter(milestoneConeRequestsPerSecond, milestoneConeRequestsBurst),
+		readTimeout:         readTimeout,
+		writeTimeout:        writeTimeout,
+		ServerMetrics:       serverMetrics,
 	}
 }
 
@@ -84,8 +108,24 @@ type Protocol struct {
 	// The send queue into which to enqueue messages to send.
 	SendQueue chan []byte
 	// The metrics around this protocol instance.
-	Metrics      Metrics
-	sendMu       sync.Mutex
+	Metrics Metrics
+	sendMu  sync.Mutex
+	// the requests sent to the peer which were not answered yet.
+	sentRequests map[string]time.Time
+	// the amount of answered and unanswered requests.
+	requestsAnswered   uint32
+	requestsUnanswered uint32
+	// the moving average of the latency of answered requests.
+	requestLatency time.Duration
+	// the milestone cones requested from the peer.
+	requestedCones   map[iotago.MilestoneIndex]time.Time
+	sentRequestsLock sync.Mutex
+	// limits the rate of the milestone cone requests of the peer that are served.
+	coneRequestsLimiter *rate.Limiter
+	// the amount of milestone cone requests of the peer that are currently served.
+	coneRequestsInFlight atomic.Int32
+	// the handshake received from the peer.
+	handshake    atomic.Pointer[Handshake]
 	readTimeout  time.Duration
 	writeTimeout time.Duration
 	// The shared server metrics instance.
@@ -99,12 +139,16 @@ func (p *Protocol) Terminated() <-chan struct{} {
 
 // Enqueue enqueues the given gossip protocol message to be sent to the peer.
 // If it can't because the send queue is over capacity, the message gets dropped.
-func (p *Protocol) Enqueue(data []byte) {
+// Returns false if the message was dropped.
+func (p *Protocol) Enqueue(data []byte) bool {
 	select {
 	case p.SendQueue <- data:
+		return true
 	default:
 		p.ServerMetrics.DroppedPackets.Inc()
 		p.Metrics.DroppedPackets.Inc()
+
+		return false
 	}
 }
 
@@ -180,7 +224,149 @@ func (p *Protocol) SendBlockRequest(requestedBlockID iotago.BlockID) {
 	if err != nil {
 		return
 	}
-	p.Enqueue(blockRequestMessage)
+	if p.Enqueue(blockRequestMessage) {
+		p.trackRequest(requestedBlockID)
+	}
+}
+
+// SendBlockRequests sends batched block request messages for the given block IDs to the given peer.
+// Single block request messages are sent if the peer doesn't support batching.
+func (p *Protocol) SendBlockRequests(requestedBlockIDs iotago.BlockIDs) {
+	if !p.SupportsBatching() {
+		for _, blockID := range requestedBlockIDs {
+			p.SendBlockRequest(blockID)
+		}
+
+		return
+	}
+
+	for len(requestedBlockIDs) > 0 {
+		batchSize := len(requestedBlockIDs)
+		if batchSize > maxBlockRequestsPerMessage {
+			batchSize = maxBlockRequestsPerMessage
+		}
+		batch := requestedBlockIDs[:batchSize]
+		requestedBlockIDs = requestedBlockIDs[batchSize:]
+
+		blockRequestsMessage, err := newBlockRequestsMessage(batch)
+		if err != nil {
+			return
+		}
+		if !p.Enqueue(blockRequestsMessage) {
+			return
+		}
+
+		for _, blockID := range batch {
+			p.trackRequest(blockID)
+		}
+	}
+}
+
+// SendBlocks sends the given blocks to the given peer, packed into batched block messages.
+// coneIndex is the index of the requested milestone cone, or 0 if the blocks were requested by ID.
+// Single block messages are sent if the peer doesn't support batching.
+func (p *Protocol) SendBlocks(coneIndex iotago.MilestoneIndex, blocksData [][]byte) {
+	if !p.SupportsBatching() {
+		for _, blockData := range blocksData {
+			p.SendBlock(blockData)
+		}
+
+		return
+	}
+
+	blocksMessages, err := newBlocksMessages(coneIndex, blocksData)
+	if err != nil {
+		return
+	}
+
+	for _, blocksMessage := range blocksMessages {
+		if !p.Enqueue(blocksMessage) {
+			return
+		}
+	}
+}
+
+// acquires a slot to serve a milestone cone request of the peer.
+// returns false if the peer exceeded its rate of milestone cone requests or too many of its requests are served already.
+// releaseMilestoneConeRequest must be called after a request was served.
+func (p *Protocol) acquireMilestoneConeRequest() bool {
+	if p.coneRequestsInFlight.Inc() > maxMilestoneConeRequestsInFlight {
+		p.coneRequestsInFlight.Dec()
+
+		return false
+	}
+
+	if !p.coneRequestsLimiter.Allow() {
+		p.coneRequestsInFlight.Dec()
+
+		return false
+	}
+
+	return true
+}
+
+// releases the slot of a served milestone cone request of the peer.
+func (p *Protocol) releaseMilestoneConeRequest() {
+	p.coneRequestsInFlight.Dec()
+}
+
+// SendMilestoneConeRequest sends a request for all blocks of the cone of the given milestone to the given peer.
+// Returns false if the peer doesn't support milestone cone requests or the request was dropped.
+func (p *Protocol) SendMilestoneConeRequest(index iotago.MilestoneIndex) bool {
+	if !p.SupportsMilestoneConeRequests() {
+		return false
+	}
+
+	milestoneConeRequestMessage, err := newMilestoneConeRequestMessage(index)
+	if err != nil {
+		return false
+	}
+	if !p.Enqueue(milestoneConeRequestMessage) {
+		return false
+	}
+
+	p.sentRequestsLock.Lock()
+	defer p.sentRequestsLock.Unlock()
+
+	p.requestedCones[index] = time.Now()
+
+	return true
+}
+
+// SendHandshake sends the given handshake to the given peer.
+func (p *Protocol) SendHandshake(handshake *Handshake) error {
+	handshakeMessage, err := newHandshakeMessage(handshake)
+	if err != nil {
+		return fmt.Errorf("unable to create handshake message: %w", err)
+	}
+
+	if !p.Enqueue(handshakeMessage) {
+		return errors.New("send queue is full")
+	}
+
+	return nil
+}
+
+// Handshake returns the handshake received from the peer.
+// Returns nil if no handshake was received yet.
+func (p *Protocol) Handshake() *Handshake {
+	return p.handshake.Load()
+}
+
+// SupportsBatching tells whether the peer supports batched block requests and batched block messages.
+// Returns false if no handshake was received yet.
+func (p *Protocol) SupportsBatching() bool {
+	handshake := p.handshake.Load()
+
+	return handshake != nil && handshake.Features.Has(FeatureBatching)
+}
+
+// SupportsMilestoneConeRequests tells whether the peer supports milestone cone requests.
+// Returns false if no handshake was received yet.
+func (p *Protocol) SupportsMilestoneConeRequests() bool {
+	handshake := p.handshake.Load()
+
+	return handshake != nil && handshake.Features.Has(FeatureMilestoneConeRequests)
 }
 
 // SendMilestoneRequest sends a milestone request to the given peer.
@@ -189,7 +375,10 @@ func (p *Protocol) SendMilestoneRequest(index iotago.MilestoneIndex) {
 	if err != nil {
 		return
 	}
-	p.Enqueue(milestoneRequestMessage)
+	if p.Enqueue(milestoneRequestMessage) && index != latestMilestoneRequestIndex {
+		// requests for the latest milestone can't be matched with the answer
+		p.trackRequest(index)
+	}
 }
 
 // SendLatestMilestoneRequest sends a storage.Milestone request which requests the latest known milestone from the given peer.
@@ -197,6 +386,130 @@ func (p *Protocol) SendLatestMilestoneRequest() {
 	p.SendMilestoneRequest(latestMilestoneRequestIndex)
 }
 
+// tracks a request for the given data that was sent to the peer, so the answer can be rated.
+// requests which are already tracked keep their original send time.
+func (p *Protocol) trackRequest(data interface{}) {
+	p.sentRequestsLock.Lock()
+	defer p.sentRequestsLock.Unlock()
+
+	key := getRequestMapKey(data)
+	if _, tracked := p.sentRequests[key]; tracked || len(p.sentRequests) >= maxTrackedRequests {
+		return
+	}
+	p.sentRequests[key] = time.Now()
+}
+
+// requestAnswered marks a request for the given data as answered and returns the time it took the peer to answer it.
+// Returns false if the data was not requested from the peer.
+func (p *Protocol) requestAnswered(data interface{}) (time.Duration, bool) {
+	p.sentRequestsLock.Lock()
+	defer p.sentRequestsLock.Unlock()
+
+	key := getRequestMapKey(data)
+	sentTime, tracked := p.sentRequests[key]
+	if !tracked {
+		return 0, false
+	}
+	delete(p.sentRequests, key)
+
+	latency := time.Since(sentTime)
+
+	p.requestsAnswered++
+	if p.requestsAnswered == 1 {
+		p.requestLatency = latency
+	} else {
+		p.requestLatency += time.Duration(requestLatencyAvgWeight * float64(latency-p.requestLatency))
+	}
+
+	return latency, true
+}
+
+// tells whether the cone of the given milestone was requested from the peer lately.
+func (p *Protocol) isMilestoneConeRequested(index iotago.MilestoneIndex) bool {
+	p.sentRequestsLock.Lock()
+	defer p.sentRequestsLock.Unlock()
+
+	sentTime, requested := p.requestedCones[index]
+
+	return requested && time.Since(sentTime) <= milestoneConeRequestTimeout
+}
+
+// ExpireRequests removes all requests sent to the peer which were not answered within the given timeout
+// and returns the amount of removed requests.
+func (p *Protocol) ExpireRequests(timeout time.Duration) int {
+	p.sentRequestsLock.Lock()
+	defer p.sentRequestsLock.Unlock()
+
+	for index, sentTime := range p.requestedCones {
+		if time.Since(sentTime) > milestoneConeRequestTimeout {
+			delete(p.requestedCones, index)
+		}
+	}
+
+	var expired int
+	for key, sentTime := range p.sentRequests {
+		if time.Since(sentTime) > timeout {
+			delete(p.sentRequests, key)
+			expired++
+		}
+	}
+	p.requestsUnanswered += uint32(expired)
+
+	return expired
+}
+
+// InFlightRequests returns the amount of requests sent to the peer which were not answered yet.
+func (p *Protocol) InFlightRequests() int {
+	p.sentRequestsLock.Lock()
+	defer p.sentRequestsLock.Unlock()
+
+	return len(p.sentRequests)
+}
+
+// RequestStats returns the statistics about the requests sent to the peer.
+func (p *Protocol) RequestStats() RequestStats {
+	p.sentRequestsLock.Lock()
+	defer p.sentRequestsLock.Unlock()
+
+	return RequestStats{
+		InFlight:   len(p.sentRequests),
+		Answered:   p.requestsAnswered,
+		Unanswered: p.requestsUnanswered,
+		AvgLatency: p.requestLatency.Milliseconds(),
+	}
+}
+
+// returns the average latency of the answered requests.
+// the default request latency is returned if the peer did not answer any request yet.
+func (p *Protocol) avgRequestLatency() time.Duration {
+	p.sentRequestsLock.Lock()
+	defer p.sentRequestsLock.Unlock()
+
+	if p.requestsAnswered == 0 {
+		return defaultRequestLatency
+	}
+
+	return p.requestLatency
+}
+
+// returns the expected cost of sending another request to the peer, lower is better.
+// the cost rises with the average latency, the amount of requests in flight and the share of unanswered requests.
+// pending is the amount of requests that are about to be sent to the peer.
+func (p *Protocol) requestCost(pending int) float64 {
+	p.sentRequestsLock.Lock()
+	defer p.sentRequestsLock.Unlock()
+
+	latency := p.requestLatency
+	if p.requestsAnswered == 0 {
+		latency = defaultRequestLatency
+	}
+
+	// the success rate is smoothed, so peers without statistics are neither preferred nor avoided
+	successRate := float64(p.requestsAnswered+1) / float64(p.requestsAnswered+p.requestsUnanswered+2)
+
+	return float64(latency) * float64(len(p.sentRequests)+pending+1) / successRate
+}
+
 // HasDataForMilestone tells whether the underlying peer given the latest heartbeat message, has the cone data for the given milestone.
 // Returns false if no heartbeat message was received yet.
 func (p *Protocol) HasDataForMilestone(index iotago.MilestoneIndex) bool {
@@ -243,6 +556,8 @@ func (p *Protocol) Info() *Info {
 	return &Info{
 		Heartbeat: p.LatestHeartbeat,
 		Metrics:   p.Metrics.Snapshot(),
+		Requests:  p.RequestStats(),
+		Handshake: p.handshake.Load(),
 	}
 }
 
@@ -306,8 +621,22 @@ type MetricsSnapshot struct {
 	DroppedPackets            uint32 `json:"droppedPackets"`
 }
 
+// RequestStats represents statistics about the requests sent to a peer.
+type RequestStats struct {
+	// The amount of requests which were not answered yet.
+	InFlight int `json:"inFlight"`
+	// The amount of answered requests.
+	Answered uint32 `json:"answered"`
+	// The amount of requests which were not answered in time.
+	Unanswered uint32 `json:"unanswered"`
+	// The moving average of the latency of answered requests in milliseconds.
+	AvgLatency int64 `json:"avgLatency"`
+}
+
 // Info represents information about an ongoing gossip protocol.
 type Info struct {
 	Heartbeat *Heartbeat      `json:"heartbeat"`
 	Metrics   MetricsSnapshot `json:"metrics"`
+	Requests  RequestStats    `json:"requests"`
+	Handshake *Handshake      `json:"handshake,omitempty"`
 }
@@ -0,0 +1,144 @@
+//nolint:forcetypeassert,varnamelen,revive,exhaustruct // we don't care about these linters in test cases
+package gossip_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hornet/v2/pkg/metrics"
+	"github.com/iotaledger/hornet/v2/pkg/protocol/gossip"
+	"github.com/iotaledger/hornet/v2/pkg/tpkg"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+func TestProtocolRequestStats(t *testing.T) {
+	proto := gossip.NewProtocol(peer.ID("peer"), nil, 10, time.Minute, time.Minute, &metrics.ServerMetrics{})
+
+	require.Equal(t, gossip.RequestStats{}, proto.RequestStats())
+
+	blockID := tpkg.RandBlockID()
+	proto.SendBlockRequest(blockID)
+	proto.SendMilestoneRequest(5)
+
+	// requests for the same data are only tracked once
+	proto.SendBlockRequest(blockID)
+
+	// requests for the latest milestone can't be tracked
+	proto.SendLatestMilestoneRequest()
+
+	require.Equal(t, 2, proto.InFlightRequests())
+	require.Equal(t, 0, proto.ExpireRequests(time.Minute))
+
+	time.Sleep(time.Millisecond)
+	require.Equal(t, 2, proto.ExpireRequests(time.Nanosecond))
+	require.Zero(t, proto.InFlightRequests())
+
+	require.Equal(t, gossip.RequestStats{
+		InFlight:   0,
+		Answered:   0,
+		Unanswered: 2,
+		AvgLatency: 0,
+	}, proto.RequestStats())
+	require.Equal(t, uint32(2), proto.Info().Requests.Unanswered)
+}
+
+func TestProtocolBatchingFallback(t *testing.T) {
+	proto := gossip.NewProtocol(peer.ID("peer"), nil, 10, time.Minute, time.Minute, &metrics.ServerMetrics{})
+	require.False(t, proto.SupportsBatching())
+
+	// single block request messages are sent to peers which don't support batching
+	blockIDs := iotago.BlockIDs{tpkg.RandBlockID(), tpkg.RandBlockID(), tpkg.RandBlockID()}
+	proto.SendBlockRequests(blockIDs)
+	require.Len(t, proto.SendQueue, len(blockIDs))
+	require.Equal(t, len(blockIDs), proto.InFlightRequests())
+
+	for range blockIDs {
+		msg := <-proto.SendQueue
+		require.Equal(t, byte(gossip.MessageTypeBlockRequest), msg[0])
+	}
+
+	// the same applies to blocks
+	proto.SendBlocks(0, [][]byte{{1, 2, 3}, {4, 5, 6}})
+	require.Len(t, proto.SendQueue, 2)
+
+	for i := 0; i < 2; i++ {
+		msg := <-proto.SendQueue
+		require.Equal(t, byte(gossip.MessageTypeBlock), msg[0])
+	}
+
+	// milestone cones can't be requested
+	require.False(t, proto.SendMilestoneConeRequest(5))
+	require.Empty(t, proto.SendQueue)
+}
+
+func TestProtocolHandshake(t *testing.T) {
+	service := gossip.NewService(protocolID, nil, nil, &metrics.ServerMetrics{}, gossip.WithHandshakeInfo(1, "hornet", "2.0.1"))
+
+	handshake := &gossip.Handshake{
+		NetworkID:  1,
+		Versions:   []string{"1.1.0", "1.0.0"},
+		Features:   gossip.SupportedFeatures,
+		AppName:    "hornet",
+		AppVersion: "2.0.1",
+	}
+
+	handshakeMessage := func(handshake *gossip.Handshake) []byte {
+		proto := gossip.NewProtocol(peer.ID("local"), nil, 10, time.Minute, time.Minute, &metrics.ServerMetrics{})
+		require.NoError(t, proto.SendHandshake(handshake))
+		require.Len(t, proto.SendQueue, 1)
+
+		msg := <-proto.SendQueue
+		require.Equal(t, byte(gossip.MessageTypeHandshake), msg[0])
+
+		// strip the TLV header
+		return msg[3:]
+	}
+
+	parsed, err := gossip.ParseHandshake(handshakeMessage(handshake))
+	require.NoError(t, err)
+	require.Equal(t, handshake, parsed)
+
+	proto := gossip.NewProtocol(peer.ID("peer"), nil, 10, time.Minute, time.Minute, &metrics.ServerMetrics{})
+	require.Nil(t, proto.Handshake())
+
+	// invalid handshakes are rejected
+	data := handshakeMessage(handshake)
+	require.ErrorIs(t, service.ProcessHandshake(proto, data[:len(data)-1]), gossip.ErrInvalidSourceLength)
+	require.ErrorIs(t, service.ProcessHandshake(proto, append(data, 0)), gossip.ErrInvalidSourceLength)
+	require.Nil(t, proto.Handshake())
+
+	require.NoError(t, service.ProcessHandshake(proto, data))
+	require.Equal(t, handshake, proto.Handshake())
+	require.Equal(t, handshake, proto.Info().Handshake)
+	require.True(t, proto.SupportsBatching())
+	require.True(t, proto.SupportsMilestoneConeRequests())
+
+	// the features are shown by name
+	featuresJSON, err := json.Marshal(proto.Info().Handshake.Features)
+	require.NoError(t, err)
+	require.JSONEq(t, `["batching","milestoneConeRequests"]`, string(featuresJSON))
+
+	// block requests and blocks are batched for peers which support it
+	blockIDs := iotago.BlockIDs{tpkg.RandBlockID(), tpkg.RandBlockID(), tpkg.RandBlockID()}
+	proto.SendBlockRequests(blockIDs)
+	require.Len(t, proto.SendQueue, 1)
+	require.Equal(t, len(blockIDs), proto.InFlightRequests())
+
+	msg := <-proto.SendQueue
+	require.Equal(t, byte(gossip.MessageTypeBlockRequests), msg[0])
+	require.Len(t, msg, 3+len(blockIDs)*iotago.BlockIDLength)
+
+	proto.SendBlocks(0, [][]byte{{1, 2, 3}, {4, 5, 6}})
+	require.Len(t, proto.SendQueue, 1)
+
+	msg = <-proto.SendQueue
+	require.Equal(t, byte(gossip.MessageTypeBlocks), msg[0])
+
+	require.True(t, proto.SendMilestoneConeRequest(5))
+	msg = <-proto.SendQueue
+	require.Equal(t, byte(gossip.MessageTypeMilestoneConeRequest), msg[0])
+}
@@ -4,16 +4,34 @@ import (
 	"context"
 	"time"
 
+	"github.com/libp2p/go-libp2p/core/peer"
+
 	"github.com/iotaledger/hornet/v2/pkg/model/storage"
 	iotago "github.com/iotaledger/iota.go/v3"
 )
 
+const (
+	// defines the interval in which pending requests are checked whether they should be sent to another peer.
+	fanOutCheckInterval = 250 * time.Millisecond
+
+	// defines the maximum amount of peers a pending request is sent to before it is re-enqueued.
+	maxRequestFanOut = 2
+
+	// defines the factor of the average request latency of a peer after which
+	// a pending request is sent to another peer, if it is higher than the fan-out timeout.
+	fanOutLatencyFactor = 4
+)
+
 // RequesterOptions are options around a Requester.
 type RequesterOptions struct {
 	// Defines the re-queue interval for pending requests.
 	PendingRequestReEnqueueInterval time.Duration
 	// Defines the max age for requests.
 	DiscardRequestsOlderThan time.Duration
+	// Defines the maximum amount of requests sent to a single peer which were not answered yet.
+	MaxInFlightRequestsPerPeer int
+	// Defines the minimum time after which a pending request is sent to another peer.
+	FanOutTimeout time.Duration
 }
 
 // applies the given RequesterOption.
@@ -30,6 +48,8 @@ type RequestBackPressureFunc func() bool
 var defaultRequesterOpts = []RequesterOption{
 	WithRequesterDiscardRequestsOlderThan(15 * time.Second),
 	WithRequesterPendingRequestReEnqueueInterval(5 * time.Second),
+	WithRequesterMaxInFlightRequestsPerPeer(500),
+	WithRequesterFanOutTimeout(1 * time.Second),
 }
 
 // RequesterOption is a function which sets an option on a RequesterOptions instance.
@@ -49,6 +69,31 @@ func WithRequesterPendingRequestReEnqueueInterval(dur time.Duration) RequesterOp
 	}
 }
 
+// WithRequesterMaxInFlightRequestsPerPeer sets the maximum amount of requests sent to a single peer which were not answered yet.
+func WithRequesterMaxInFlightRequestsPerPeer(maxInFlight int) RequesterOption {
+	return func(options *RequesterOptions) {
+		options.MaxInFlightRequestsPerPeer = maxInFlight
+	}
+}
+
+// WithRequesterFanOutTimeout sets the minimum time after which a pending request is sent to another peer.
+func WithRequesterFanOutTimeout(dur time.Duration) RequesterOption {
+	return func(options *RequesterOptions) {
+		options.FanOutTimeout = dur
+	}
+}
+
+// requestRoute keeps track of the peers a pending request was sent to.
+type requestRoute struct {
+	request *Request
+	// the peers the request was sent to.
+	peers []peer.ID
+	// the time the request was sent to the last peer.
+	sentTime time.Time
+	// the time after which the request is sent to another peer.
+	timeout time.Duration
+}
+
 // Requester handles requesting packets.
 type Requester struct {
 	storage *storage.Storage
@@ -59,6 +104,8 @@ type Requester struct {
 	running     bool
 	backPFuncs  []RequestBackPressureFunc
 	drainSignal chan struct{}
+	// the routes of the pending requests, only accessed by the request queue drainer.
+	routes map[string]*requestRoute
 }
 
 // NewRequester creates a new Requester.
@@ -78,66 +125,211 @@ func NewRequester(
 		rQueue:      rQueue,
 		opts:        reqOpts,
 		drainSignal: make(chan struct{}, 2),
+		routes:      make(map[string]*requestRoute),
 	}
 }
 
 // RunRequestQueueDrainer runs the RequestQueue drainer.
+// Requests are sent to the peer with the lowest request cost that has the data.
+// If the peer doesn't answer in time, the request is additionally sent to another peer.
 func (r *Requester) RunRequestQueueDrainer(ctx context.Context) {
 	r.running = true
+	fanOutTicker := time.NewTicker(fanOutCheckInterval)
+	defer fanOutTicker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-r.drainSignal:
+			r.drainRequestQueue()
+		case <-fanOutTicker.C:
+			r.fanOutPendingRequests()
 
-			// drain request queue
-			for request := r.rQueue.Next(); request != nil; request = r.rQueue.Next() {
-
-				sendRequest := func(request *Request, proto *Protocol) {
-					switch request.RequestType {
-					case RequestTypeBlockID:
-						proto.SendBlockRequest(request.BlockID)
-					case RequestTypeMilestoneIndex:
-						proto.SendMilestoneRequest(request.MilestoneIndex)
-					default:
-						panic(ErrUnknownRequestType)
-					}
-				}
+			// peers which were busy before might be able to handle requests again
+			r.drainRequestQueue()
+		}
+	}
+}
+
+// returns all protocols of the gossip service.
+func (r *Requester) protocols() []*Protocol {
+	var protos []*Protocol
+	r.service.ForEach(func(proto *Protocol) bool {
+		protos = append(protos, proto)
+
+		return true
+	})
+
+	return protos
+}
+
+// checks whether more requests can be sent to the given peer.
+// the requests which are collected in the given batches are taken into account.
+func (r *Requester) hasRequestCapacity(proto *Protocol, batches requestBatches) bool {
+	return r.opts.MaxInFlightRequestsPerPeer <= 0 || proto.InFlightRequests()+len(batches[proto]) < r.opts.MaxInFlightRequestsPerPeer
+}
+
+// returns the time after which a request sent to the given peer is sent to another peer.
+func (r *Requester) fanOutTimeout(proto *Protocol) time.Duration {
+	timeout := fanOutLatencyFactor * proto.avgRequestLatency()
+	if timeout < r.opts.FanOutTimeout {
+		return r.opts.FanOutTimeout
+	}
+
+	return timeout
+}
+
+// returns the peer with the lowest request cost that has the data for the given request
+// and is able to handle more requests. the given peers are excluded.
+func (r *Requester) bestPeer(protos []*Protocol, request *Request, exclude []peer.ID, batches requestBatches) *Protocol {
+	var best *Protocol
+	var bestCost float64
+
+protosLoop:
+	for _, proto := range protos {
+		// we only send a request to the peer if it actually has the data
+		// (r.MilestoneIndex > PrunedMilestoneIndex && r.MilestoneIndex <= SolidMilestoneIndex)
+		if !proto.HasDataForMilestone(request.MilestoneIndex) || !r.hasRequestCapacity(proto, batches) {
+			continue
+		}
+
+		for _, peerID := range exclude {
+			if proto.PeerID == peerID {
+				continue protosLoop
+			}
+		}
+
+		if cost := proto.requestCost(len(batches[proto])); best == nil || cost < bestCost {
+			best = proto
+			bestCost = cost
+		}
+	}
+
+	return best
+}
+
+// requestBatches collects the block requests per peer, so they can be sent in batched block request messages.
+type requestBatches map[*Protocol]iotago.BlockIDs
+
+// adds the given request to the batch of the given peer.
+// milestone requests are sent immediately.
+func (b requestBatches) add(request *Request, proto *Protocol) {
+	switch request.RequestType {
+	case RequestTypeBlockID:
+		b[proto] = append(b[proto], request.BlockID)
+	case RequestTypeMilestoneIndex:
+		proto.SendMilestoneRequest(request.MilestoneIndex)
+	default:
+		panic(ErrUnknownRequestType)
+	}
+}
+
+// sends the collected block requests to the peers.
+// single block request messages are sent to peers which don't support batching.
+func (b requestBatches) send() {
+	for proto, blockIDs := range b {
+		proto.SendBlockRequests(blockIDs)
+		delete(b, proto)
+	}
+}
+
+// drains the request queue and sends the requests to the best suited peers.
+func (r *Requester) drainRequestQueue() {
+	protos := r.protocols()
+
+	batches := make(requestBatches)
+	defer batches.send()
 
-				requested := false
-				r.service.ForEach(func(proto *Protocol) bool {
-					// we only send a request block if the peer actually has the data
-					// (r.MilestoneIndex > PrunedMilestoneIndex && r.MilestoneIndex <= SolidMilestoneIndex)
-					if !proto.HasDataForMilestone(request.MilestoneIndex) {
-						return true
-					}
-
-					sendRequest(request, proto)
-					requested = true
-
-					return false
-				})
-
-				if !requested {
-					// we have no neighbor that has the data for sure,
-					// so we ask all peers that could have the data
-					// (r.MilestoneIndex > PrunedMilestoneIndex && r.MilestoneIndex <= LatestMilestoneIndex)
-					r.service.ForEach(func(proto *Protocol) bool {
-						// we only send a request block if the peer could have the data
-						if !proto.CouldHaveDataForMilestone(request.MilestoneIndex) {
-							return true
-						}
-
-						sendRequest(request, proto)
-
-						return true
-					})
+	for {
+		if len(protos) > 0 {
+			hasCapacity := false
+			for _, proto := range protos {
+				if r.hasRequestCapacity(proto, batches) {
+					hasCapacity = true
+
+					break
 				}
 			}
+
+			if !hasCapacity {
+				// all peers are busy, the remaining requests stay queued until capacity is available again
+				return
+			}
+		}
+
+		request := r.rQueue.Next()
+		if request == nil {
+			return
+		}
+
+		if proto := r.bestPeer(protos, request, nil, batches); proto != nil {
+			batches.add(request, proto)
+			r.routes[request.MapKey()] = &requestRoute{
+				request:  request,
+				peers:    []peer.ID{proto.PeerID},
+				sentTime: time.Now(),
+				timeout:  r.fanOutTimeout(proto),
+			}
+
+			continue
+		}
+
+		// we have no neighbor that has the data for sure,
+		// so we ask all peers that could have the data
+		// (r.MilestoneIndex > PrunedMilestoneIndex && r.MilestoneIndex <= LatestMilestoneIndex)
+		for _, proto := range protos {
+			// we only send a request block if the peer could have the data
+			if !proto.CouldHaveDataForMilestone(request.MilestoneIndex) || !r.hasRequestCapacity(proto, batches) {
+				continue
+			}
+
+			batches.add(request, proto)
 		}
 	}
 }
 
+// sends pending requests which were not answered within the timeout of the peer to another peer,
+// instead of waiting for the pending requests to be re-enqueued.
+func (r *Requester) fanOutPendingRequests() {
+	if len(r.routes) == 0 {
+		return
+	}
+
+	var protos []*Protocol
+	now := time.Now()
+
+	batches := make(requestBatches)
+	defer batches.send()
+
+	for key, route := range r.routes {
+		if !r.rQueue.IsPending(route.request) {
+			// the request was answered, re-enqueued or discarded
+			delete(r.routes, key)
+
+			continue
+		}
+
+		if len(route.peers) >= maxRequestFanOut || now.Sub(route.sentTime) < route.timeout {
+			continue
+		}
+
+		if protos == nil {
+			protos = r.protocols()
+		}
+
+		proto := r.bestPeer(protos, route.request, route.peers, batches)
+		if proto == nil {
+			continue
+		}
+
+		batches.add(route.request, proto)
+		route.peers = append(route.peers, proto.PeerID)
+		route.sentTime = now
+		route.timeout = r.fanOutTimeout(proto)
+	}
+}
+
 // RunPendingRequestEnqueuer runs the loop to periodically re-request pending requests from the RequestQueue.
 func (r *Requester) RunPendingRequestEnqueuer(ctx context.Context) {
 	r.running = true
@@ -290,5 +482,26 @@ func (r *Requester) RequestMilestoneParents(cachedMilestone *storage.CachedMiles
 		}
 	}
 
+	if enqueued {
+		// request the whole cone at once from a peer that supports it.
+		// the requests for the parents are kept, in case the peer doesn't answer.
+		r.requestMilestoneCone(msIndex)
+	}
+
 	return enqueued
 }
+
+// sends a milestone cone request to the peer with the lowest request cost that has the data
+// for the given milestone and supports milestone cone requests.
+func (r *Requester) requestMilestoneCone(msIndex iotago.MilestoneIndex) {
+	var protos []*Protocol
+	for _, proto := range r.protocols() {
+		if proto.SupportsMilestoneConeRequests() {
+			protos = append(protos, proto)
+		}
+	}
+
+	if proto := r.bestPeer(protos, NewMilestoneIndexRequest(msIndex), nil, nil); proto != nil {
+		proto.SendMilestoneConeRequest(msIndex)
+	}
+}
@@ -3,6 +3,7 @@ package gossip
 import (
 	"context"
 	"fmt"
+	"path"
 	"time"
 
 	"github.com/libp2p/go-libp2p/core/host"
@@ -55,6 +56,7 @@ var (
 const (
 	defaultSendQueueSize        = 1000
 	defaultStreamConnectTimeout = 4 * time.Second
+	defaultHandshakeTimeout     = 10 * time.Second
 )
 
 // the default options applied to the Service.
@@ -63,6 +65,7 @@ var defaultServiceOptions = []ServiceOption{
 	WithStreamConnectTimeout(defaultStreamConnectTimeout),
 	WithStreamReadTimeout(1 * time.Minute),
 	WithStreamWriteTimeout(10 * time.Second),
+	WithHandshakeTimeout(defaultHandshakeTimeout),
 	WithUnknownPeersLimit(0),
 }
 
@@ -78,8 +81,18 @@ type ServiceOptions struct {
 	streamReadTimeout time.Duration
 	// The write timeout for a stream.
 	streamWriteTimeout time.Duration
+	// The time in which a peer has to send its handshake.
+	handshakeTimeout time.Duration
 	// The amount of unknown peers to allow to have a gossip stream with.
 	unknownPeersLimit int
+	// The IDs of older gossip protocol versions which are still supported.
+	legacyProtocols []protocol.ID
+	// The ID of the network the node belongs to.
+	networkID uint64
+	// The name of the node software.
+	appName string
+	// The version of the node software.
+	appVersion string
 }
 
 // applies the given ServiceOption.
@@ -124,6 +137,14 @@ func WithStreamWriteTimeout(dur time.Duration) ServiceOption {
 	}
 }
 
+// WithHandshakeTimeout defines the time in which a peer has to send its handshake
+// after a stream using the latest protocol version was opened.
+func WithHandshakeTimeout(dur time.Duration) ServiceOption {
+	return func(opts *ServiceOptions) {
+		opts.handshakeTimeout = dur
+	}
+}
+
 // WithUnknownPeersLimit defines how many peers with an unknown relation
 // are allowed to have an ongoing gossip protocol stream.
 func WithUnknownPeersLimit(limit int) ServiceOption {
@@ -132,6 +153,26 @@ func WithUnknownPeersLimit(limit int) ServiceOption {
 	}
 }
 
+// WithLegacyProtocols defines the IDs of older gossip protocol versions which are still supported.
+// Streams using an older protocol version don't support batched block requests,
+// milestone cone requests and batched block messages.
+func WithLegacyProtocols(protocols ...protocol.ID) ServiceOption {
+	return func(opts *ServiceOptions) {
+		opts.legacyProtocols = protocols
+	}
+}
+
+// WithHandshakeInfo defines the network ID and the node software information sent in the handshake.
+// Peers of other networks don't need to be rejected based on the handshake,
+// because the network ID is already part of the gossip protocol ID.
+func WithHandshakeInfo(networkID uint64, appName string, appVersion string) ServiceOption {
+	return func(opts *ServiceOptions) {
+		opts.networkID = networkID
+		opts.appName = appName
+		opts.appVersion = appVersion
+	}
+}
+
 // ServiceOption is a function setting a ServiceOptions option.
 type ServiceOption func(opts *ServiceOptions)
 
@@ -143,7 +184,8 @@ type Service struct {
 	// Events happening around a Service.
 	Events *ServiceEvents
 	// the libp2p host instance from which to work with.
-	host     host.Host
+	host host.Host
+	// the ID of the latest gossip protocol version.
 	protocol protocol.ID
 	// holds the set of protocols.
 	streams map[peer.ID]*Protocol
@@ -168,6 +210,8 @@ type Service struct {
 }
 
 // NewService creates a new Service.
+// The given protocol is the ID of the latest gossip protocol version,
+// older versions can be supported via WithLegacyProtocols.
 func NewService(
 	protocol protocol.ID, host host.Host,
 	peeringManager *p2p.Manager,
@@ -266,17 +310,75 @@ func (s *Service) Start(ctx context.Context) {
 	defer unhook()
 
 	// libp2p stream handler
-	s.host.SetStreamHandler(s.protocol, func(stream network.Stream) {
-		if s.stopped.Load() {
-			return
-		}
-		s.inboundStreamChan <- stream
-	})
+	for _, protocolID := range s.protocols() {
+		s.host.SetStreamHandler(protocolID, func(stream network.Stream) {
+			if s.stopped.Load() {
+				return
+			}
+			s.inboundStreamChan <- stream
+		})
+	}
 
 	s.eventLoop(ctx)
 
 	// libp2p stream handler
-	s.host.RemoveStreamHandler(s.protocol)
+	for _, protocolID := range s.protocols() {
+		s.host.RemoveStreamHandler(protocolID)
+	}
+}
+
+// returns the IDs of all supported gossip protocol versions, ordered by preference.
+func (s *Service) protocols() []protocol.ID {
+	return append([]protocol.ID{s.protocol}, s.opts.legacyProtocols...)
+}
+
+// returns the handshake of the node.
+func (s *Service) handshake() *Handshake {
+	protocols := s.protocols()
+
+	// the version is the last element of the protocol ID
+	versions := make([]string, len(protocols))
+	for i, protocolID := range protocols {
+		versions[i] = path.Base(string(protocolID))
+	}
+
+	return &Handshake{
+		NetworkID:  s.opts.networkID,
+		Versions:   versions,
+		Features:   SupportedFeatures,
+		AppName:    s.opts.appName,
+		AppVersion: s.opts.appVersion,
+	}
+}
+
+// ProcessHandshake parses the given handshake message of the peer of the given protocol and stores it in the protocol.
+// Returns an error if the message is invalid.
+func (s *Service) ProcessHandshake(proto *Protocol, data []byte) error {
+	handshake, err := ParseHandshake(data)
+	if err != nil {
+		return fmt.Errorf("invalid handshake: %w", err)
+	}
+
+	proto.handshake.Store(handshake)
+
+	return nil
+}
+
+// waits for the handshake of the peer of the given protocol.
+// if the peer doesn't send its handshake in time, an error is fired on the protocol, so the stream gets dropped.
+func (s *Service) awaitHandshake(proto *Protocol) {
+	timer := time.NewTimer(s.opts.handshakeTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-proto.Terminated():
+		return
+	case <-timer.C:
+	}
+
+	if proto.Handshake() == nil {
+		proto.Events.Errors.Trigger(fmt.Errorf("%w: no handshake received within %v", ErrHandshakeTimeout, s.opts.handshakeTimeout))
+	}
 }
 
 // shutdown sets the stopped flag and drains all outstanding requests of the event loop.
@@ -490,7 +592,8 @@ func (s *Service) openStream(ctx context.Context, peerID peer.ID) (network.Strea
 	ctxNewStream, cancelNewStream := context.WithTimeout(ctx, s.opts.streamConnectTimeout)
 	defer cancelNewStream()
 
-	stream, err := s.host.NewStream(ctxNewStream, peerID, s.protocol)
+	// the latest protocol version supported by both peers is negotiated
+	stream, err := s.host.NewStream(ctxNewStream, peerID, s.protocols()...)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create gossip stream to %s: %w", peerID, err)
 	}
@@ -509,8 +612,25 @@ func (s *Service) registerProtocol(peerID peer.ID, stream network.Stream) {
 	}
 
 	proto := NewProtocol(peerID, stream, s.opts.sendQueueSize, s.opts.streamReadTimeout, s.opts.streamWriteTimeout, s.serverMetrics)
+
+	// only the latest protocol version exchanges handshakes
+	exchangesHandshake := stream.Protocol() == s.protocol
+	if exchangesHandshake {
+		// the handshake is the first message sent on the stream
+		if err := proto.SendHandshake(s.handshake()); err != nil {
+			s.Events.Error.Trigger(fmt.Errorf("unable to send handshake to %s: %w", peerID, err))
+			delete(s.unknownPeers, peerID)
+			s.closeUnwantedStreamAndClosePeer(stream)
+
+			return
+		}
+	}
 	s.streams[peerID] = proto
 	s.Events.ProtocolStarted.Trigger(proto)
+
+	if exchangesHandshake {
+		go s.awaitHandshake(proto)
+	}
 }
 
 // deregisters ongoing gossip protocol streams and closes them for the given peer.
@@ -11,6 +11,7 @@ import (
 	"github.com/libp2p/go-libp2p/core/crypto"
 	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
 	"github.com/libp2p/go-libp2p/p2p/net/connmgr"
 	"github.com/libp2p/go-libp2p/p2p/transport/tcp"
 	"github.com/stretchr/testify/require"
@@ -24,10 +25,19 @@ import (
 	"github.com/iotaledger/hornet/v2/pkg/protocol/gossip"
 )
 
-const protocolID = "/iota/abcdf/1.0.0"
+const (
+	protocolID       = "/iota/abcdf/1.1.0"
+	legacyProtocolID = "/iota/abcdf/1.0.0"
+)
 
 func newNode(ctx context.Context, name string, t *testing.T, mngOpts []p2p.ManagerOption, srvOpts []gossip.ServiceOption, privateKey crypto.PrivKey) (
 	host.Host, *p2p.Manager, *gossip.Service, peer.AddrInfo,
+) {
+	return newNodeWithProtocol(ctx, name, t, protocolID, mngOpts, srvOpts, privateKey)
+}
+
+func newNodeWithProtocol(ctx context.Context, name string, t *testing.T, protocolID protocol.ID, mngOpts []p2p.ManagerOption, srvOpts []gossip.ServiceOption, privateKey crypto.PrivKey) (
+	host.Host, *p2p.Manager, *gossip.Service, peer.AddrInfo,
 ) {
 	connManager, err := connmgr.NewConnManager(
 		1,
@@ -58,6 +68,41 @@ func newNode(ctx context.Context, name string, t *testing.T, mngOpts []p2p.Manag
 	return n, nManager, service, peer.AddrInfo{ID: n.ID(), Addrs: n.Addrs()}
 }
 
+// runs the read and write loops of the started protocols of the given service like the gossip component, so handshakes are exchanged.
+func runProtocols(service *gossip.Service) {
+	service.Events.ProtocolStarted.Hook(func(proto *gossip.Protocol) {
+		proto.Parser.Events.Received[gossip.MessageTypeHandshake].Hook(func(data []byte) {
+			_ = service.ProcessHandshake(proto, data)
+		})
+
+		go func() {
+			buf := make([]byte, 2048)
+			for {
+				r, err := proto.Read(buf)
+				if err != nil {
+					return
+				}
+				if _, err := proto.Parser.Read(buf[:r]); err != nil {
+					return
+				}
+			}
+		}()
+
+		go func() {
+			for {
+				select {
+				case <-proto.Terminated():
+					return
+				case data := <-proto.SendQueue:
+					if err := proto.Send(data); err != nil {
+						return
+					}
+				}
+			}
+		}()
+	})
+}
+
 func TestServiceEvents(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -287,3 +332,131 @@ func TestWithUnknownPeersLimit(t *testing.T) {
 		return node3ProtocolTerminated == 2
 	}, 4*time.Second, 10*time.Millisecond)
 }
+
+func TestServiceProtocolNegotiation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := configuration.New()
+	err := cfg.Set("logger.disableStacktrace", true)
+	require.NoError(t, err)
+
+	// no need to check the error, since the global logger could already be initialized
+	_ = appLogger.InitGlobalLogger(cfg)
+
+	node1PrvKey, _, err := crypto.GenerateKeyPair(crypto.Ed25519, -1)
+	require.NoError(t, err)
+
+	node2PrvKey, _, err := crypto.GenerateKeyPair(crypto.Ed25519, -1)
+	require.NoError(t, err)
+
+	node3PrvKey, _, err := crypto.GenerateKeyPair(crypto.Ed25519, -1)
+	require.NoError(t, err)
+
+	// node 1 supports the latest and the legacy protocol
+	node1, node1Manager, node1Service, node1AddrInfo := newNode(ctx, "node1", t, nil, []gossip.ServiceOption{
+		gossip.WithLegacyProtocols(legacyProtocolID),
+	}, node1PrvKey)
+
+	// node 2 only supports the latest protocol
+	node2, node2Manager, node2Service, node2AddrInfo := newNode(ctx, "node2", t, nil, nil, node2PrvKey)
+
+	// node 3 only supports the legacy protocol
+	node3, node3Manager, node3Service, node3AddrInfo := newNodeWithProtocol(ctx, "node3", t, legacyProtocolID, nil, nil, node3PrvKey)
+
+	// node 3 simulates an older node, so its protocols are not run and it never sends a handshake
+	runProtocols(node1Service)
+	runProtocols(node2Service)
+
+	connect := func(nodeA *p2p.Manager, addrInfoA peer.AddrInfo, nodeB *p2p.Manager, addrInfoB peer.AddrInfo) {
+		go func() {
+			_ = nodeA.ConnectPeer(&addrInfoB, p2p.PeerRelationKnown)
+		}()
+		time.Sleep(100 * time.Millisecond)
+		go func() {
+			_ = nodeB.ConnectPeer(&addrInfoA, p2p.PeerRelationKnown)
+		}()
+	}
+
+	protocolEventually := func(service *gossip.Service, peerID peer.ID) *gossip.Protocol {
+		var proto *gossip.Protocol
+		require.Eventually(t, func() bool {
+			proto = service.Protocol(peerID)
+
+			return proto != nil
+		}, 10*time.Second, 10*time.Millisecond)
+
+		return proto
+	}
+
+	// the latest protocol is negotiated if both peers support it
+	connect(node1Manager, node1AddrInfo, node2Manager, node2AddrInfo)
+	proto := protocolEventually(node1Service, node2.ID())
+	require.Equal(t, protocol.ID(protocolID), proto.Stream.Protocol())
+	node2Proto := protocolEventually(node2Service, node1.ID())
+	require.Equal(t, protocol.ID(protocolID), node2Proto.Stream.Protocol())
+
+	// the peers exchanged their handshakes
+	require.Eventually(t, func() bool {
+		return proto.SupportsBatching()
+	}, 4*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		return node2Proto.SupportsBatching()
+	}, 4*time.Second, 10*time.Millisecond)
+
+	// peers which only support the legacy protocol don't exchange handshakes and don't support batching
+	connect(node1Manager, node1AddrInfo, node3Manager, node3AddrInfo)
+	proto = protocolEventually(node1Service, node3.ID())
+	require.Equal(t, protocol.ID(legacyProtocolID), proto.Stream.Protocol())
+	require.False(t, proto.SupportsBatching())
+	require.Equal(t, protocol.ID(legacyProtocolID), protocolEventually(node3Service, node1.ID()).Stream.Protocol())
+}
+
+func TestServiceHandshakeTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := configuration.New()
+	err := cfg.Set("logger.disableStacktrace", true)
+	require.NoError(t, err)
+
+	// no need to check the error, since the global logger could already be initialized
+	_ = appLogger.InitGlobalLogger(cfg)
+
+	node1PrvKey, _, err := crypto.GenerateKeyPair(crypto.Ed25519, -1)
+	require.NoError(t, err)
+
+	node2PrvKey, _, err := crypto.GenerateKeyPair(crypto.Ed25519, -1)
+	require.NoError(t, err)
+
+	_, node1Manager, node1Service, node1AddrInfo := newNode(ctx, "node1", t, nil, []gossip.ServiceOption{
+		gossip.WithHandshakeTimeout(500 * time.Millisecond),
+	}, node1PrvKey)
+
+	// node 2 never sends the handshake, because the send queue of its protocols is not processed
+	_, node2Manager, _, node2AddrInfo := newNode(ctx, "node2", t, nil, nil, node2PrvKey)
+
+	handshakeErrChan := make(chan error, 1)
+	node1Service.Events.ProtocolStarted.Hook(func(proto *gossip.Protocol) {
+		proto.Events.Errors.Hook(func(err error) {
+			select {
+			case handshakeErrChan <- err:
+			default:
+			}
+		})
+	})
+
+	go func() {
+		_ = node1Manager.ConnectPeer(&node2AddrInfo, p2p.PeerRelationKnown)
+	}()
+	go func() {
+		_ = node2Manager.ConnectPeer(&node1AddrInfo, p2p.PeerRelationKnown)
+	}()
+
+	select {
+	case err := <-handshakeErrChan:
+		require.ErrorIs(t, err, gossip.ErrHandshakeTimeout)
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "the stream without a handshake was not dropped")
+	}
+}
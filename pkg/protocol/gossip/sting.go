@@ -3,6 +3,8 @@ package gossip
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
+	"math"
 
 	"github.com/pkg/errors"
 
@@ -22,6 +24,11 @@ const (
 	MessageTypeBlock            message.Type = 2
 	MessageTypeBlockRequest     message.Type = 3
 	MessageTypeHeartbeat        message.Type = 4
+	// the following message types are only supported by peers which support batching.
+	MessageTypeBlockRequests        message.Type = 5
+	MessageTypeMilestoneConeRequest message.Type = 6
+	MessageTypeBlocks               message.Type = 7
+	MessageTypeHandshake            message.Type = 8
 )
 
 const (
@@ -36,6 +43,25 @@ const (
 
 	// latestMilestoneRequestIndex defines the index to use to request the latest milestone via a milestone request message.
 	latestMilestoneRequestIndex = 0
+
+	// maxBlockRequestsPerMessage defines the maximum amount of block IDs within a batched block request message.
+	maxBlockRequestsPerMessage = 1024
+
+	// blocksMsgConeIndexBytesLength defines the amount of bytes used for the milestone index of the requested cone within a batched block message.
+	blocksMsgConeIndexBytesLength = 4
+
+	// blocksMsgBlockLengthBytesLength defines the amount of bytes used for the length of a block within a batched block message.
+	blocksMsgBlockLengthBytesLength = 2
+
+	// handshakeMinBytesLength defines the minimum amount of bytes of a handshake packet
+	// (network ID, features, versions count, app name length and app version length).
+	handshakeMinBytesLength = 8 + 4 + 1 + 1 + 1
+
+	// handshakeMaxVersions defines the maximum amount of gossip protocol versions within a handshake packet.
+	handshakeMaxVersions = 16
+
+	// handshakeMaxStringLength defines the maximum length of a string within a handshake packet.
+	handshakeMaxStringLength = math.MaxUint8
 )
 
 var (
@@ -68,6 +94,39 @@ var (
 		MaxBytesLength: requestedMilestoneIndexMsgBytesLength,
 		VariableLength: false,
 	}
+
+	// blockRequestsMessageDefinition defines the batched block request packet.
+	// Contains a list of IDs of requested blocks.
+	blockRequestsMessageDefinition = &message.Definition{
+		ID:             MessageTypeBlockRequests,
+		MaxBytesLength: maxBlockRequestsPerMessage * requestedBlockIDMsgBytesLength,
+		VariableLength: true,
+	}
+
+	// milestoneConeRequestMessageDefinition defines the milestone cone request packet.
+	// Contains the index of the milestone of which all blocks of the cone are requested.
+	milestoneConeRequestMessageDefinition = &message.Definition{
+		ID:             MessageTypeMilestoneConeRequest,
+		MaxBytesLength: requestedMilestoneIndexMsgBytesLength,
+		VariableLength: false,
+	}
+
+	// blocksMessageDefinition defines the batched block packet.
+	// Contains the index of the requested milestone cone (0 if the blocks were requested by ID),
+	// followed by the blocks, each prefixed with its length.
+	blocksMessageDefinition = &message.Definition{
+		ID:             MessageTypeBlocks,
+		MaxBytesLength: math.MaxUint16,
+		VariableLength: true,
+	}
+
+	// handshakeMessageDefinition defines the handshake packet which is exchanged when a gossip protocol stream is opened.
+	// Contains the network ID, the supported features and gossip protocol versions and the node software version.
+	handshakeMessageDefinition = &message.Definition{
+		ID:             MessageTypeHandshake,
+		MaxBytesLength: 1024,
+		VariableLength: true,
+	}
 )
 
 // newBlockMessage creates a new block message.
@@ -144,6 +203,143 @@ func newMilestoneRequestMessage(requestedMilestoneIndex iotago.MilestoneIndex) (
 	return buf.Bytes(), nil
 }
 
+// newBlockRequestsMessage creates a batched block request message.
+func newBlockRequestsMessage(requestedBlockIDs iotago.BlockIDs) ([]byte, error) {
+	if len(requestedBlockIDs) == 0 || len(requestedBlockIDs) > maxBlockRequestsPerMessage {
+		return nil, fmt.Errorf("invalid amount of requested blocks: %d", len(requestedBlockIDs))
+	}
+
+	msgBytesLength := uint16(len(requestedBlockIDs) * requestedBlockIDMsgBytesLength)
+	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+msgBytesLength))
+	if err := tlv.WriteHeader(buf, MessageTypeBlockRequests, msgBytesLength); err != nil {
+		return nil, err
+	}
+
+	for _, blockID := range requestedBlockIDs {
+		if err := binary.Write(buf, binary.LittleEndian, blockID[:requestedBlockIDMsgBytesLength]); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// newMilestoneConeRequestMessage creates a new milestone cone request message.
+func newMilestoneConeRequestMessage(requestedMilestoneIndex iotago.MilestoneIndex) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+milestoneConeRequestMessageDefinition.MaxBytesLength))
+	if err := tlv.WriteHeader(buf, MessageTypeMilestoneConeRequest, milestoneConeRequestMessageDefinition.MaxBytesLength); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(buf, binary.LittleEndian, requestedMilestoneIndex); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// newBlocksMessages creates batched block messages containing the given blocks.
+// The blocks are packed into as few messages as possible.
+// coneIndex is the index of the requested milestone cone, or 0 if the blocks were requested by ID.
+func newBlocksMessages(coneIndex iotago.MilestoneIndex, blocksData [][]byte) ([][]byte, error) {
+	var msgs [][]byte
+	var blocksInMsg [][]byte
+	msgBytesLength := blocksMsgConeIndexBytesLength
+
+	writeMessage := func() error {
+		buf := bytes.NewBuffer(make([]byte, 0, int(tlv.HeaderMessageDefinition.MaxBytesLength)+msgBytesLength))
+		if err := tlv.WriteHeader(buf, MessageTypeBlocks, uint16(msgBytesLength)); err != nil {
+			return err
+		}
+
+		if err := binary.Write(buf, binary.LittleEndian, coneIndex); err != nil {
+			return err
+		}
+
+		for _, blockData := range blocksInMsg {
+			if err := binary.Write(buf, binary.LittleEndian, uint16(len(blockData))); err != nil {
+				return err
+			}
+
+			if err := binary.Write(buf, binary.LittleEndian, blockData); err != nil {
+				return err
+			}
+		}
+
+		msgs = append(msgs, buf.Bytes())
+		blocksInMsg = nil
+		msgBytesLength = blocksMsgConeIndexBytesLength
+
+		return nil
+	}
+
+	for _, blockData := range blocksData {
+		blockBytesLength := blocksMsgBlockLengthBytesLength + len(blockData)
+		if len(blockData) > iotago.BlockBinSerializedMaxSize {
+			return nil, fmt.Errorf("block exceeds max size: %d bytes", len(blockData))
+		}
+
+		if msgBytesLength+blockBytesLength > int(blocksMessageDefinition.MaxBytesLength) {
+			if err := writeMessage(); err != nil {
+				return nil, err
+			}
+		}
+
+		blocksInMsg = append(blocksInMsg, blockData)
+		msgBytesLength += blockBytesLength
+	}
+
+	if len(blocksInMsg) > 0 {
+		if err := writeMessage(); err != nil {
+			return nil, err
+		}
+	}
+
+	return msgs, nil
+}
+
+// extractRequestedBlockIDs extracts the requested block IDs from the given source.
+func extractRequestedBlockIDs(source []byte) (iotago.BlockIDs, error) {
+	if len(source) == 0 || len(source)%requestedBlockIDMsgBytesLength != 0 {
+		return nil, ErrInvalidSourceLength
+	}
+
+	blockIDs := make(iotago.BlockIDs, len(source)/requestedBlockIDMsgBytesLength)
+	for i := range blockIDs {
+		copy(blockIDs[i][:], source[i*requestedBlockIDMsgBytesLength:(i+1)*requestedBlockIDMsgBytesLength])
+	}
+
+	return blockIDs, nil
+}
+
+// extractBlocks extracts the index of the requested milestone cone and the blocks from the given source.
+func extractBlocks(source []byte) (iotago.MilestoneIndex, [][]byte, error) {
+	if len(source) < blocksMsgConeIndexBytesLength+blocksMsgBlockLengthBytesLength {
+		return 0, nil, ErrInvalidSourceLength
+	}
+
+	coneIndex := binary.LittleEndian.Uint32(source[:blocksMsgConeIndexBytesLength])
+
+	var blocksData [][]byte
+	for offset := blocksMsgConeIndexBytesLength; offset < len(source); {
+		if len(source)-offset < blocksMsgBlockLengthBytesLength {
+			return 0, nil, ErrInvalidSourceLength
+		}
+
+		blockBytesLength := int(binary.LittleEndian.Uint16(source[offset : offset+blocksMsgBlockLengthBytesLength]))
+		offset += blocksMsgBlockLengthBytesLength
+
+		if blockBytesLength == 0 || len(source)-offset < blockBytesLength {
+			return 0, nil, ErrInvalidSourceLength
+		}
+
+		blocksData = append(blocksData, source[offset:offset+blockBytesLength])
+		offset += blockBytesLength
+	}
+
+	return coneIndex, blocksData, nil
+}
+
 // extractRequestedMilestoneIndex extracts the requested milestone index from the given source.
 func extractRequestedMilestoneIndex(source []byte) (iotago.MilestoneIndex, error) {
 	if len(source) != serializer.UInt32ByteSize {
@@ -7,6 +7,7 @@ import (
 	"github.com/iotaledger/hive.go/objectstorage"
 	"github.com/iotaledger/hive.go/runtime/syncutils"
 	"github.com/iotaledger/hornet/v2/pkg/model/storage"
+	"github.com/iotaledger/hornet/v2/pkg/p2p"
 )
 
 // WorkUnitState defines the state which a WorkUnit is in.
@@ -92,24 +93,36 @@ func (wu *WorkUnit) Is(state WorkUnitState) bool {
 	return wu.state&state > 0
 }
 
-// adds a Request for the given peer to this WorkUnit.
-func (wu *WorkUnit) addReceivedFrom(p *Protocol) {
+// adds the given peer to the peers this WorkUnit was received from.
+// returns false if the WorkUnit was already received from the given peer.
+func (wu *WorkUnit) addReceivedFrom(p *Protocol) bool {
 	wu.receivedFromLock.Lock()
 	defer wu.receivedFromLock.Unlock()
+
+	for _, receivedFrom := range wu.receivedFrom {
+		if receivedFrom.PeerID == p.PeerID {
+			return false
+		}
+	}
 	wu.receivedFrom = append(wu.receivedFrom, p)
+
+	return true
 }
 
 // punishes, respectively increases the invalid block metric of all peers
 // which sent the given underlying block of this WorkUnit.
-// it also closes the connection to these peers.
+// it also lowers the reputation of these peers.
 func (wu *WorkUnit) punish(reason error) {
 	wu.receivedFromLock.Lock()
-	defer wu.receivedFromLock.Unlock()
-	for _, p := range wu.receivedFrom {
+	receivedFrom := make([]*Protocol, len(wu.receivedFrom))
+	copy(receivedFrom, wu.receivedFrom)
+	wu.receivedFromLock.Unlock()
+
+	for _, p := range receivedFrom {
 		wu.messageProcessor.serverMetrics.InvalidBlocks.Inc()
 
-		// drop the connection to the peer
-		_ = wu.messageProcessor.peeringManager.DisconnectPeer(p.PeerID, errors.WithMessagef(reason, "peer was punished"))
+		// lower the reputation of the peer
+		wu.messageProcessor.peeringManager.ReportPeer(p.PeerID, p2p.ReputationEventInvalidData, errors.WithMessagef(reason, "peer was punished"))
 	}
 }
 
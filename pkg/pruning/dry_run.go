@@ -0,0 +1,233 @@
+package pruning
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/runtime/contextutils"
+	"github.com/iotaledger/hornet/v2/pkg/common"
+	"github.com/iotaledger/hornet/v2/pkg/dag"
+	storagepkg "github.com/iotaledger/hornet/v2/pkg/model/storage"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+// Report holds the impact of a database pruning run that was not executed.
+type Report struct {
+	// PruningIndex is the current pruning index of the database.
+	PruningIndex iotago.MilestoneIndex
+	// TargetIndex is the index the database would be pruned to.
+	TargetIndex iotago.MilestoneIndex
+	// Milestones is the amount of milestones that would be pruned.
+	Milestones int
+	// Blocks is the amount of blocks in the pruned milestone cones.
+	Blocks int
+	// UnreferencedBlocks is the amount of unreferenced blocks that would be pruned.
+	UnreferencedBlocks int
+	// MilestoneDiffs is the amount of ledger diffs that would be pruned.
+	MilestoneDiffs int
+	// Outputs is the amount of created outputs in the pruned ledger diffs.
+	Outputs int
+	// Spents is the amount of spent outputs that would be pruned.
+	Spents int
+	// Receipts is the amount of receipts that would be pruned.
+	Receipts int
+	// EstimatedBytes is the estimated size of the raw data that would be pruned (without metadata and database overhead).
+	EstimatedBytes int64
+	// DatabaseSizeBytes is the current size of the tangle and the UTXO database.
+	DatabaseSizeBytes int64
+	// ExpectedReclaimedBytes is the amount of bytes pruning by size expects to reclaim (only set for pruning by size).
+	ExpectedReclaimedBytes int64
+	// SolidEntryPoints are the solid entry points of the database after pruning.
+	SolidEntryPoints []*storagepkg.SolidEntryPoint
+}
+
+// dryRun walks the same milestone cones as pruneDatabase and reports what would be pruned, without modifying the database.
+func (p *Manager) dryRun(ctx context.Context, targetIndex iotago.MilestoneIndex) (*Report, error) {
+
+	if err := contextutils.ReturnErrIfCtxDone(ctx, common.ErrOperationAborted); err != nil {
+		return nil, err
+	}
+
+	targetIndexMax := p.getMinimumTangleHistory()
+	if targetIndex > targetIndexMax {
+		targetIndex = targetIndexMax
+	}
+
+	// milestones that are held by extensions must not be pruned
+	targetIndex = p.heldTargetIndex(targetIndex)
+
+	snapshotInfo := p.storage.SnapshotInfo()
+	if snapshotInfo == nil {
+		return nil, common.ErrSnapshotInfoNotFound
+	}
+
+	if err := p.checkPruningTargetIndex(snapshotInfo, targetIndex); err != nil {
+		return nil, err
+	}
+
+	databaseSizeBytes, err := p.databaseSizeBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		PruningIndex:      snapshotInfo.PruningIndex(),
+		TargetIndex:       targetIndex,
+		DatabaseSizeBytes: databaseSizeBytes,
+	}
+
+	// calculate solid entry points for the new end of the tangle history
+	if err := dag.ForEachSolidEntryPoint(
+		ctx,
+		p.storage,
+		targetIndex,
+		// TODO
+		// p.solidEntryPointCheckThresholdPast,
+		15,
+		func(sep *storagepkg.SolidEntryPoint) bool {
+			report.SolidEntryPoints = append(report.SolidEntryPoints, sep)
+
+			return true
+		}); err != nil {
+		if errors.Is(err, common.ErrOperationAborted) {
+			return nil, ErrPruningAborted
+		}
+
+		return nil, err
+	}
+
+	// contains all blocks that would have been pruned by the former milestones,
+	// so they are neither traversed nor counted twice.
+	prunedBlockIDs := make(map[iotago.BlockID]struct{})
+
+	addBlocks := func(blockIDs map[iotago.BlockID]struct{}) {
+		for blockID := range blockIDs {
+			prunedBlockIDs[blockID] = struct{}{}
+
+			cachedBlock := p.storage.CachedBlockOrNil(blockID) // block +1
+			if cachedBlock == nil {
+				continue
+			}
+			report.EstimatedBytes += int64(len(cachedBlock.Block().Data()))
+			cachedBlock.Release(true) // block -1
+		}
+	}
+
+	// unreferenced blocks have to be pruned for PruningIndex as well, since this could be CMI at startup of the node
+	unreferencedBlockIDs := p.unreferencedBlockIDsToPrune(snapshotInfo.PruningIndex(), prunedBlockIDs)
+	report.UnreferencedBlocks += len(unreferencedBlockIDs)
+	addBlocks(unreferencedBlockIDs)
+
+	for milestoneIndex := snapshotInfo.PruningIndex() + 1; milestoneIndex <= targetIndex; milestoneIndex++ {
+
+		if err := contextutils.ReturnErrIfCtxDone(ctx, ErrPruningAborted); err != nil {
+			return nil, err
+		}
+
+		unreferencedBlockIDs := p.unreferencedBlockIDsToPrune(milestoneIndex, prunedBlockIDs)
+		report.UnreferencedBlocks += len(unreferencedBlockIDs)
+		addBlocks(unreferencedBlockIDs)
+
+		cachedMilestone := p.storage.CachedMilestoneByIndexOrNil(milestoneIndex) // milestone +1
+		if cachedMilestone == nil {
+			// Milestone not found, pruning would be skipped
+			continue
+		}
+		report.Milestones++
+		report.EstimatedBytes += int64(len(cachedMilestone.Milestone().Data()))
+
+		blockIDsToDeleteMap, err := p.milestoneConeBlockIDsToPrune(ctx, cachedMilestone.Milestone().Parents(), prunedBlockIDs)
+		if err != nil {
+			cachedMilestone.Release(true) // milestone -1
+			if errors.Is(err, common.ErrOperationAborted) {
+				return nil, ErrPruningAborted
+			}
+
+			return nil, errors.Wrapf(err, "traversing milestone (%d) failed", milestoneIndex)
+		}
+		report.Blocks += len(blockIDsToDeleteMap)
+		addBlocks(blockIDsToDeleteMap)
+
+		if p.pruneReceipts {
+			opts, err := cachedMilestone.Milestone().Milestone().Opts.Set()
+			if err == nil && opts != nil && opts.Receipt() != nil {
+				report.Receipts++
+			}
+		}
+		cachedMilestone.Release(true) // milestone -1
+
+		diff, err := p.storage.UTXOManager().MilestoneDiffWithoutLocking(milestoneIndex)
+		if err != nil {
+			// the ledger diff was already pruned
+			continue
+		}
+		report.MilestoneDiffs++
+		report.Outputs += len(diff.Outputs)
+		report.Spents += len(diff.Spents)
+		report.EstimatedBytes += int64(len(diff.KVStorableValue()))
+
+		// only the spents are deleted together with the diff, the created outputs are still part of the ledger
+		for _, spent := range diff.Spents {
+			report.EstimatedBytes += int64(len(spent.KVStorableValue()) + len(spent.Output().KVStorableValue()))
+		}
+	}
+
+	return report, nil
+}
+
+// DryRunByDepth reports the impact of pruning the database by depth without modifying the database.
+func (p *Manager) DryRunByDepth(ctx context.Context, depth iotago.MilestoneIndex) (*Report, error) {
+	p.snapshotLock.Lock()
+	defer p.snapshotLock.Unlock()
+
+	confirmedMilestoneIndex := p.syncManager.ConfirmedMilestoneIndex()
+
+	if confirmedMilestoneIndex <= depth {
+		// Not enough history
+		return nil, ErrNotEnoughHistory
+	}
+
+	return p.dryRun(ctx, confirmedMilestoneIndex-depth)
+}
+
+// DryRunByTargetIndex reports the impact of pruning the database up to the given target index without modifying the database.
+func (p *Manager) DryRunByTargetIndex(ctx context.Context, targetIndex iotago.MilestoneIndex) (*Report, error) {
+	p.snapshotLock.Lock()
+	defer p.snapshotLock.Unlock()
+
+	return p.dryRun(ctx, targetIndex)
+}
+
+// DryRunByRetentionPeriod reports the impact of pruning the database by retention period without modifying the database.
+func (p *Manager) DryRunByRetentionPeriod(ctx context.Context, retentionPeriod time.Duration) (*Report, error) {
+	p.snapshotLock.Lock()
+	defer p.snapshotLock.Unlock()
+
+	targetIndex, err := p.calcTargetIndexByTime(retentionPeriod)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.dryRun(ctx, targetIndex)
+}
+
+// DryRunBySize reports the impact of pruning the database by size without modifying the database.
+func (p *Manager) DryRunBySize(ctx context.Context, targetSizeBytes int64) (*Report, error) {
+	p.snapshotLock.Lock()
+	defer p.snapshotLock.Unlock()
+
+	targetIndex, expectedReclaimedBytes, err := p.calcTargetIndexBySize(targetSizeBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := p.dryRun(ctx, targetIndex)
+	if err != nil {
+		return nil, err
+	}
+	report.ExpectedReclaimedBytes = expectedReclaimedBytes
+
+	return report, nil
+}
@@ -0,0 +1,106 @@
+//nolint:forcetypeassert,varnamelen,revive,exhaustruct // we don't care about these linters in test cases
+package pruning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hornet/v2/pkg/testsuite"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+// dumpUTXOStore returns a copy of all keys and values of the UTXO store.
+// The UTXO manager writes directly to the store, so the dump contains the whole ledger state.
+func dumpUTXOStore(t *testing.T, te *testsuite.TestEnvironment) map[string]string {
+	t.Helper()
+
+	dump := make(map[string]string)
+	require.NoError(t, te.Storage().UTXOStore().Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		dump[string(key)] = string(value)
+
+		return true
+	}))
+
+	return dump
+}
+
+// databaseContent returns the milestone indexes, the block IDs and the milestone diff indexes in the database.
+func databaseContent(t *testing.T, te *testsuite.TestEnvironment) (map[iotago.MilestoneIndex]struct{}, map[iotago.BlockID]struct{}, map[iotago.MilestoneIndex]struct{}) {
+	t.Helper()
+
+	milestones := make(map[iotago.MilestoneIndex]struct{})
+	te.Storage().ForEachMilestoneIndex(func(msIndex iotago.MilestoneIndex) bool {
+		milestones[msIndex] = struct{}{}
+
+		return true
+	})
+
+	blocks := make(map[iotago.BlockID]struct{})
+	te.Storage().ForEachBlockID(func(blockID iotago.BlockID) bool {
+		blocks[blockID] = struct{}{}
+
+		return true
+	})
+
+	diffs := make(map[iotago.MilestoneIndex]struct{})
+	for msIndex := iotago.MilestoneIndex(0); msIndex <= te.SyncManager().ConfirmedMilestoneIndex(); msIndex++ {
+		if _, err := te.Storage().UTXOManager().MilestoneDiff(msIndex); err == nil {
+			diffs[msIndex] = struct{}{}
+		}
+	}
+
+	return milestones, blocks, diffs
+}
+
+func TestDryRunMatchesPruning(t *testing.T) {
+	te, manager := newTestPruningManager(t, 41)
+
+	const targetIndex = 20
+
+	milestonesBefore, blocksBefore, diffsBefore := databaseContent(t, te)
+	utxoDumpBefore := dumpUTXOStore(t, te)
+
+	report, err := manager.DryRunByTargetIndex(context.Background(), targetIndex)
+	require.NoError(t, err)
+	require.Equal(t, iotago.MilestoneIndex(0), report.PruningIndex)
+	require.Equal(t, iotago.MilestoneIndex(targetIndex), report.TargetIndex)
+	require.NotEmpty(t, report.SolidEntryPoints)
+
+	// the dry run must not modify the database
+	milestonesDryRun, blocksDryRun, diffsDryRun := databaseContent(t, te)
+	require.Equal(t, milestonesBefore, milestonesDryRun)
+	require.Equal(t, blocksBefore, blocksDryRun)
+	require.Equal(t, diffsBefore, diffsDryRun)
+	require.Equal(t, utxoDumpBefore, dumpUTXOStore(t, te))
+	require.Equal(t, iotago.MilestoneIndex(0), te.Storage().SnapshotInfo().PruningIndex())
+	require.Equal(t, iotago.MilestoneIndex(0), te.Storage().SnapshotInfo().EntryPointIndex())
+
+	prunedIndex, err := manager.PruneDatabaseByTargetIndex(context.Background(), targetIndex)
+	require.NoError(t, err)
+	require.Equal(t, report.TargetIndex, prunedIndex)
+	require.Equal(t, prunedIndex, te.Storage().SnapshotInfo().PruningIndex())
+
+	milestonesAfter, blocksAfter, diffsAfter := databaseContent(t, te)
+
+	// the report must match what was deleted by the pruning
+	require.Equal(t, len(milestonesBefore)-len(milestonesAfter), report.Milestones)
+	require.Equal(t, len(blocksBefore)-len(blocksAfter), report.Blocks+report.UnreferencedBlocks)
+	require.Equal(t, len(diffsBefore)-len(diffsAfter), report.MilestoneDiffs)
+	require.Greater(t, report.Milestones, 0)
+	require.Greater(t, report.Blocks, 0)
+	require.Greater(t, report.MilestoneDiffs, 0)
+
+	for msIndex := range milestonesAfter {
+		require.Greater(t, msIndex, prunedIndex)
+	}
+
+	// the solid entry points of the dry run are the ones of the pruned database
+	for _, sep := range report.SolidEntryPoints {
+		contains, err := te.Storage().SolidEntryPointsContain(sep.BlockID)
+		require.NoError(t, err)
+		require.True(t, contains)
+	}
+}
@@ -0,0 +1,161 @@
+package pruning
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+var (
+	ErrHoldIDEmpty            = errors.New("hold ID is empty")
+	ErrHoldNotFound           = errors.New("hold not found")
+	ErrHoldIndexAlreadyPruned = errors.New("hold index was already pruned")
+	ErrHoldIndexNotConfirmed  = errors.New("hold index is not confirmed yet")
+)
+
+// Hold pins a minimum milestone index that pruning must not pass until it is released.
+// Holds are used by extensions (e.g. indexers) that still need to process older milestones.
+type Hold struct {
+	// ID is the unique identifier of the hold, chosen by the owner.
+	ID string
+	// Index is the oldest milestone index that must be kept in the database.
+	Index iotago.MilestoneIndex
+	// CreatedAt is the time the hold was added or updated.
+	CreatedAt time.Time
+	// ExpiresAt is the time the hold is automatically released (zero = never).
+	ExpiresAt time.Time
+}
+
+func (h *Hold) expired() bool {
+	return !h.ExpiresAt.IsZero() && time.Now().After(h.ExpiresAt)
+}
+
+// AddHold adds a hold with the given ID that prevents pruning of the given milestone index and all younger milestones.
+// An existing hold with the same ID is replaced, which allows the owner to move the index forward.
+// If a TTL is given, the hold is automatically released after that duration,
+// so a crashed owner does not block pruning forever.
+func (p *Manager) AddHold(id string, index iotago.MilestoneIndex, ttl time.Duration) (*Hold, error) {
+	if id == "" {
+		return nil, ErrHoldIDEmpty
+	}
+
+	p.holdsLock.Lock()
+	defer p.holdsLock.Unlock()
+
+	prunedIndex := p.reservedPruningIndex
+	if snapshotInfo := p.storage.SnapshotInfo(); snapshotInfo != nil && snapshotInfo.PruningIndex() > prunedIndex {
+		prunedIndex = snapshotInfo.PruningIndex()
+	}
+
+	if index <= prunedIndex {
+		return nil, errors.Wrapf(ErrHoldIndexAlreadyPruned, "pruning index: %d, hold index: %d", prunedIndex, index)
+	}
+
+	if confirmedMilestoneIndex := p.syncManager.ConfirmedMilestoneIndex(); index > confirmedMilestoneIndex {
+		return nil, errors.Wrapf(ErrHoldIndexNotConfirmed, "confirmed milestone index: %d, hold index: %d", confirmedMilestoneIndex, index)
+	}
+
+	hold := &Hold{
+		ID:        id,
+		Index:     index,
+		CreatedAt: time.Now(),
+	}
+	if ttl > 0 {
+		hold.ExpiresAt = hold.CreatedAt.Add(ttl)
+	}
+
+	p.holds[id] = hold
+
+	return hold, nil
+}
+
+// RemoveHold releases the hold with the given ID.
+func (p *Manager) RemoveHold(id string) error {
+	p.holdsLock.Lock()
+	defer p.holdsLock.Unlock()
+
+	if _, exists := p.holds[id]; !exists {
+		return ErrHoldNotFound
+	}
+
+	delete(p.holds, id)
+
+	return nil
+}
+
+// Holds returns all active holds sorted by their index.
+func (p *Manager) Holds() []*Hold {
+	p.holdsLock.Lock()
+	defer p.holdsLock.Unlock()
+
+	p.removeExpiredHoldsWithoutLocking()
+
+	holds := make([]*Hold, 0, len(p.holds))
+	for _, hold := range p.holds {
+		holds = append(holds, hold)
+	}
+
+	sort.Slice(holds, func(i, j int) bool {
+		if holds[i].Index != holds[j].Index {
+			return holds[i].Index < holds[j].Index
+		}
+
+		return holds[i].ID < holds[j].ID
+	})
+
+	return holds
+}
+
+// reservePruningTargetIndex limits the given pruning target index to the milestones that are not held,
+// and reserves the range up to the resulting target index for the running pruning,
+// so no holds can be added for milestones that are about to be pruned.
+func (p *Manager) reservePruningTargetIndex(targetIndex iotago.MilestoneIndex) iotago.MilestoneIndex {
+	p.holdsLock.Lock()
+	defer p.holdsLock.Unlock()
+
+	targetIndex = p.heldTargetIndexWithoutLocking(targetIndex)
+	p.reservedPruningIndex = targetIndex
+
+	return targetIndex
+}
+
+// heldTargetIndex limits the given pruning target index to the milestones that are not held.
+func (p *Manager) heldTargetIndex(targetIndex iotago.MilestoneIndex) iotago.MilestoneIndex {
+	p.holdsLock.Lock()
+	defer p.holdsLock.Unlock()
+
+	return p.heldTargetIndexWithoutLocking(targetIndex)
+}
+
+func (p *Manager) heldTargetIndexWithoutLocking(targetIndex iotago.MilestoneIndex) iotago.MilestoneIndex {
+	p.removeExpiredHoldsWithoutLocking()
+
+	for _, hold := range p.holds {
+		if targetIndex >= hold.Index {
+			// the held milestone itself must be kept
+			targetIndex = hold.Index - 1
+		}
+	}
+
+	return targetIndex
+}
+
+// releasePruningTargetIndex releases the range that was reserved for the running pruning.
+func (p *Manager) releasePruningTargetIndex() {
+	p.holdsLock.Lock()
+	defer p.holdsLock.Unlock()
+
+	p.reservedPruningIndex = 0
+}
+
+func (p *Manager) removeExpiredHoldsWithoutLocking() {
+	for id, hold := range p.holds {
+		if hold.expired() {
+			p.LogInfof("pruning hold \"%s\" (index %d) expired", id, hold.Index)
+			delete(p.holds, id)
+		}
+	}
+}
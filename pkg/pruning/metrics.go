@@ -8,6 +8,7 @@ import (
 type Metrics struct {
 	DurationPruneUnreferencedBlocks      time.Duration
 	DurationTraverseMilestoneCone        time.Duration
+	DurationArchiveMilestoneCone         time.Duration
 	DurationPruneMilestone               time.Duration
 	DurationPruneBlocks                  time.Duration
 	DurationSetSnapshotInfo              time.Duration
@@ -10,11 +10,13 @@ import (
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/hive.go/runtime/contextutils"
 	"github.com/iotaledger/hive.go/runtime/syncutils"
+	"github.com/iotaledger/hornet/v2/pkg/archive"
 	"github.com/iotaledger/hornet/v2/pkg/common"
 	"github.com/iotaledger/hornet/v2/pkg/dag"
 	"github.com/iotaledger/hornet/v2/pkg/database"
 	storagepkg "github.com/iotaledger/hornet/v2/pkg/model/storage"
 	"github.com/iotaledger/hornet/v2/pkg/model/syncmanager"
+	"github.com/iotaledger/hornet/v2/pkg/model/utxo"
 	iotago "github.com/iotaledger/iota.go/v3"
 )
 
@@ -45,6 +47,8 @@ type Manager struct {
 	tangleDatabase          *database.Database
 	utxoDatabase            *database.Database
 	getMinimumTangleHistory getMinimumTangleHistoryFunc
+	// the archive the pruned milestone cones are moved to (optional).
+	archive *archive.Archive
 
 	additionalPruningThreshold           iotago.MilestoneIndex
 	pruningMilestonesEnabled             bool
@@ -53,6 +57,8 @@ type Manager struct {
 	pruningSizeTargetSizeBytes           int64
 	pruningSizeThresholdPercentage       float64
 	pruningSizeCooldownTime              time.Duration
+	pruningTimeEnabled                   bool
+	pruningTimeRetentionPeriod           time.Duration
 	pruneReceipts                        bool
 
 	snapshotLock          syncutils.Mutex
@@ -60,6 +66,11 @@ type Manager struct {
 	isPruning             bool
 	lastPruningBySizeTime time.Time
 
+	holdsLock syncutils.Mutex
+	holds     map[string]*Hold
+	// the pruning target index of the running pruning, no holds can be added below this index.
+	reservedPruningIndex iotago.MilestoneIndex
+
 	Events *Events
 }
 
@@ -70,6 +81,7 @@ func NewPruningManager(
 	syncManager *syncmanager.SyncManager,
 	tangleDatabase *database.Database,
 	utxoDatabase *database.Database,
+	archive *archive.Archive,
 	getMinimumTangleHistory getMinimumTangleHistoryFunc,
 	pruningMilestonesEnabled bool,
 	pruningMilestonesMaxMilestonesToKeep syncmanager.MilestoneIndexDelta,
@@ -77,6 +89,8 @@ func NewPruningManager(
 	pruningSizeTargetSizeBytes int64,
 	pruningSizeThresholdPercentage float64,
 	pruningSizeCooldownTime time.Duration,
+	pruningTimeEnabled bool,
+	pruningTimeRetentionPeriod time.Duration,
 	pruneReceipts bool) *Manager {
 
 	return &Manager{
@@ -85,6 +99,7 @@ func NewPruningManager(
 		syncManager:                          syncManager,
 		tangleDatabase:                       tangleDatabase,
 		utxoDatabase:                         utxoDatabase,
+		archive:                              archive,
 		getMinimumTangleHistory:              getMinimumTangleHistory,
 		additionalPruningThreshold:           AdditionalPruningThreshold,
 		pruningMilestonesEnabled:             pruningMilestonesEnabled,
@@ -93,7 +108,10 @@ func NewPruningManager(
 		pruningSizeTargetSizeBytes:           pruningSizeTargetSizeBytes,
 		pruningSizeThresholdPercentage:       pruningSizeThresholdPercentage,
 		pruningSizeCooldownTime:              pruningSizeCooldownTime,
+		pruningTimeEnabled:                   pruningTimeEnabled,
+		pruningTimeRetentionPeriod:           pruningTimeRetentionPeriod,
 		pruneReceipts:                        pruneReceipts,
+		holds:                                make(map[string]*Hold),
 		Events:                               newEvents(),
 	}
 }
@@ -112,33 +130,44 @@ func (p *Manager) IsPruning() bool {
 	return p.isPruning
 }
 
-func (p *Manager) calcTargetIndexBySize(targetSizeBytes ...int64) (iotago.MilestoneIndex, error) {
+// databaseSizeBytes returns the combined size of the tangle and the UTXO database.
+func (p *Manager) databaseSizeBytes() (int64, error) {
+
+	currentTangleDatabaseSizeBytes, err := p.tangleDatabase.Size()
+	if err != nil {
+		return 0, err
+	}
+
+	currentUTXODatabaseSizeBytes, err := p.utxoDatabase.Size()
+	if err != nil {
+		return 0, err
+	}
+
+	return currentTangleDatabaseSizeBytes + currentUTXODatabaseSizeBytes, nil
+}
+
+// calcTargetIndexBySize returns the target index to reach the target database size,
+// and the amount of bytes that is expected to be reclaimed by pruning up to that index.
+func (p *Manager) calcTargetIndexBySize(targetSizeBytes ...int64) (iotago.MilestoneIndex, int64, error) {
 
 	if !p.pruningSizeEnabled && len(targetSizeBytes) == 0 {
 		// pruning by size deactivated
-		return 0, ErrNoPruningNeeded
+		return 0, 0, ErrNoPruningNeeded
 	}
 
 	if !p.tangleDatabase.CompactionSupported() || !p.utxoDatabase.CompactionSupported() {
-		return 0, ErrDatabaseCompactionNotSupported
+		return 0, 0, ErrDatabaseCompactionNotSupported
 	}
 
 	if p.tangleDatabase.CompactionRunning() || p.utxoDatabase.CompactionRunning() {
-		return 0, ErrDatabaseCompactionRunning
-	}
-
-	currentTangleDatabaseSizeBytes, err := p.tangleDatabase.Size()
-	if err != nil {
-		return 0, err
+		return 0, 0, ErrDatabaseCompactionRunning
 	}
 
-	currentUTXODatabaseSizeBytes, err := p.utxoDatabase.Size()
+	currentDatabaseSizeBytes, err := p.databaseSizeBytes()
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 
-	currentDatabaseSizeBytes := currentTangleDatabaseSizeBytes + currentUTXODatabaseSizeBytes
-
 	targetDatabaseSizeBytes := p.pruningSizeTargetSizeBytes
 	if len(targetSizeBytes) > 0 {
 		targetDatabaseSizeBytes = targetSizeBytes[0]
@@ -146,16 +175,16 @@ func (p *Manager) calcTargetIndexBySize(targetSizeBytes ...int64) (iotago.Milest
 
 	if targetDatabaseSizeBytes <= 0 {
 		// pruning by size deactivated
-		return 0, ErrNoPruningNeeded
+		return 0, 0, ErrNoPruningNeeded
 	}
 
 	if currentDatabaseSizeBytes < targetDatabaseSizeBytes {
-		return 0, ErrNoPruningNeeded
+		return 0, 0, ErrNoPruningNeeded
 	}
 
 	snapshotInfo := p.storage.SnapshotInfo()
 	if snapshotInfo == nil {
-		return 0, common.ErrSnapshotInfoNotFound
+		return 0, 0, common.ErrSnapshotInfoNotFound
 	}
 
 	confirmedMilestoneIndex := p.syncManager.ConfirmedMilestoneIndex()
@@ -164,11 +193,68 @@ func (p *Manager) calcTargetIndexBySize(targetSizeBytes ...int64) (iotago.Milest
 	diffPercentage := prunedDatabaseSizeBytes / float64(currentDatabaseSizeBytes)
 	milestoneDiff := syncmanager.MilestoneIndexDelta(math.Ceil(float64(milestoneRange) * diffPercentage))
 
-	return confirmedMilestoneIndex - milestoneDiff, nil
+	return confirmedMilestoneIndex - milestoneDiff, currentDatabaseSizeBytes - int64(prunedDatabaseSizeBytes), nil
 }
 
-// pruneUnreferencedBlocks prunes all unreferenced blocks from the database for the given milestone.
-func (p *Manager) pruneUnreferencedBlocks(targetIndex iotago.MilestoneIndex) (blocksCountDeleted int, blocksCountChecked int) {
+// calcTargetIndexByTime returns the index of the youngest milestone that is older than the retention period.
+func (p *Manager) calcTargetIndexByTime(retentionPeriod ...time.Duration) (iotago.MilestoneIndex, error) {
+
+	if !p.pruningTimeEnabled && len(retentionPeriod) == 0 {
+		// pruning by time deactivated
+		return 0, ErrNoPruningNeeded
+	}
+
+	pruningRetentionPeriod := p.pruningTimeRetentionPeriod
+	if len(retentionPeriod) > 0 {
+		pruningRetentionPeriod = retentionPeriod[0]
+	}
+
+	if pruningRetentionPeriod <= 0 {
+		// pruning by time deactivated
+		return 0, ErrNoPruningNeeded
+	}
+
+	snapshotInfo := p.storage.SnapshotInfo()
+	if snapshotInfo == nil {
+		return 0, common.ErrSnapshotInfoNotFound
+	}
+
+	cutoffTime := time.Now().Add(-pruningRetentionPeriod)
+
+	// binary search for the youngest milestone that is older than the cutoff time,
+	// the milestone timestamps are strictly monotonic.
+	lowIndex := snapshotInfo.PruningIndex() + 1
+	highIndex := p.syncManager.ConfirmedMilestoneIndex()
+
+	var targetIndex iotago.MilestoneIndex
+	for lowIndex <= highIndex {
+		msIndex := lowIndex + (highIndex-lowIndex)/2
+
+		msTimestamp, err := p.storage.MilestoneTimestampByIndex(msIndex)
+		if err != nil {
+			return 0, err
+		}
+
+		if !msTimestamp.Before(cutoffTime) {
+			highIndex = msIndex - 1
+
+			continue
+		}
+
+		targetIndex = msIndex
+		lowIndex = msIndex + 1
+	}
+
+	if targetIndex == 0 {
+		return 0, ErrNoPruningNeeded
+	}
+
+	return targetIndex, nil
+}
+
+// unreferencedBlockIDsToPrune returns the IDs of all blocks that were not referenced until the given milestone.
+// Blocks contained in the given excluded map are skipped.
+func (p *Manager) unreferencedBlockIDsToPrune(targetIndex iotago.MilestoneIndex, excluded map[iotago.BlockID]struct{}) map[iotago.BlockID]struct{} {
 
 	blockIDsToDeleteMap := make(map[iotago.BlockID]struct{})
 
@@ -178,6 +264,10 @@ func (p *Manager) pruneUnreferencedBlocks(targetIndex iotago.MilestoneIndex) (bl
 			continue
 		}
 
+		if _, exists := excluded[blockID]; exists {
+			continue
+		}
+
 		cachedBlockMeta := p.storage.CachedBlockMetadataOrNil(blockID) // meta +1
 		if cachedBlockMeta == nil {
 			// block was already deleted or marked for deletion
@@ -195,12 +285,91 @@ func (p *Manager) pruneUnreferencedBlocks(targetIndex iotago.MilestoneIndex) (bl
 		blockIDsToDeleteMap[blockID] = struct{}{}
 	}
 
+	return blockIDsToDeleteMap
+}
+
+// pruneUnreferencedBlocks prunes all unreferenced blocks from the database for the given milestone.
+func (p *Manager) pruneUnreferencedBlocks(targetIndex iotago.MilestoneIndex) (blocksCountDeleted int, blocksCountChecked int) {
+
+	blockIDsToDeleteMap := p.unreferencedBlockIDsToPrune(targetIndex, nil)
+
 	blocksCountDeleted = p.pruneBlocks(blockIDsToDeleteMap)
 	p.storage.DeleteUnreferencedBlocks(targetIndex)
 
 	return blocksCountDeleted, len(blockIDsToDeleteMap)
 }
 
+// milestoneConeBlockIDsToPrune returns the IDs of all blocks in the cone of the milestone with the given parents.
+// Blocks contained in the given excluded map are not traversed, since they are treated as already pruned.
+func (p *Manager) milestoneConeBlockIDsToPrune(ctx context.Context, milestoneParents iotago.BlockIDs, excluded map[iotago.BlockID]struct{}) (map[iotago.BlockID]struct{}, error) {
+
+	blockIDsToDeleteMap := make(map[iotago.BlockID]struct{})
+
+	if err := dag.TraverseParents(
+		ctx,
+		p.storage,
+		milestoneParents,
+		// traversal stops if no more blocks pass the given condition
+		// Caution: condition func is not in DFS order
+		func(cachedBlockMeta *storagepkg.CachedMetadata) (bool, error) { // meta +1
+			defer cachedBlockMeta.Release(true) // meta -1
+			// everything that was referenced by that milestone can be pruned (even blocks of older milestones)
+			_, alreadyPruned := excluded[cachedBlockMeta.Metadata().BlockID()]
+
+			return !alreadyPruned, nil
+		},
+		// consumer
+		func(cachedBlockMeta *storagepkg.CachedMetadata) error { // meta +1
+			defer cachedBlockMeta.Release(true) // meta -1
+			blockIDsToDeleteMap[cachedBlockMeta.Metadata().BlockID()] = struct{}{}
+
+			return nil
+		},
+		// called on missing parents
+		func(parentBlockID iotago.BlockID) error { return nil },
+		// called on solid entry points
+		// Ignore solid entry points (snapshot milestone included)
+		nil,
+		// the pruning target index is also a solid entry point => traverse it anyways
+		true); err != nil {
+		return nil, err
+	}
+
+	return blockIDsToDeleteMap, nil
+}
+
+// archiveMilestoneCone moves the milestone payload, the blocks of the milestone cone,
+// the ledger diff and the receipt of the given milestone to the archive.
+func (p *Manager) archiveMilestoneCone(milestone *storagepkg.Milestone, blockIDsToDeleteMap map[iotago.BlockID]struct{}, receipt *iotago.ReceiptMilestoneOpt) error {
+
+	cone := &archive.MilestoneCone{
+		Milestone: milestone,
+		Blocks:    make([]*storagepkg.Block, 0, len(blockIDsToDeleteMap)),
+	}
+
+	for blockID := range blockIDsToDeleteMap {
+		cachedBlock := p.storage.CachedBlockOrNil(blockID) // block +1
+		if cachedBlock == nil {
+			// block was already pruned, e.g. if it was referenced by an older milestone
+			continue
+		}
+		cone.Blocks = append(cone.Blocks, cachedBlock.Block())
+		cachedBlock.Release(true) // block -1
+	}
+
+	diff, err := p.storage.UTXOManager().MilestoneDiffWithoutLocking(milestone.Index())
+	if err != nil {
+		return err
+	}
+	cone.Diff = diff
+
+	if receipt != nil {
+		cone.Receipt = &utxo.ReceiptTuple{Receipt: receipt, MilestoneIndex: milestone.Index()}
+	}
+
+	return p.archive.ArchiveMilestoneCone(cone)
+}
+
 // pruneMilestone prunes the milestone metadata and the ledger diffs from the database for the given milestone.
 func (p *Manager) pruneMilestone(milestoneIndex iotago.MilestoneIndex, receiptMigratedAtIndex ...iotago.MilestoneIndex) error {
 
@@ -240,6 +409,22 @@ func (p *Manager) pruneBlocks(blockIDsToDeleteMap map[iotago.BlockID]struct{}) i
 	return len(blockIDsToDeleteMap)
 }
 
+// checkPruningTargetIndex checks whether the database can be pruned up to the given target index.
+func (p *Manager) checkPruningTargetIndex(snapshotInfo *storagepkg.SnapshotInfo, targetIndex iotago.MilestoneIndex) error {
+
+	if snapshotInfo.PruningIndex() >= targetIndex {
+		// no pruning needed
+		return errors.Wrapf(ErrNoPruningNeeded, "pruning index: %d, target index: %d", snapshotInfo.PruningIndex(), targetIndex)
+	}
+
+	if snapshotInfo.EntryPointIndex()+p.additionalPruningThreshold+1 > targetIndex {
+		// we prune in "additionalPruningThreshold" steps to recalculate the solidEntryPoints
+		return errors.Wrapf(ErrNotEnoughHistory, "minimum index: %d, target index: %d", snapshotInfo.EntryPointIndex()+p.additionalPruningThreshold+1, targetIndex)
+	}
+
+	return nil
+}
+
 func (p *Manager) pruneDatabase(ctx context.Context, targetIndex iotago.MilestoneIndex) (iotago.MilestoneIndex, error) {
 
 	if err := contextutils.ReturnErrIfCtxDone(ctx, common.ErrOperationAborted); err != nil {
@@ -256,19 +441,20 @@ func (p *Manager) pruneDatabase(ctx context.Context, targetIndex iotago.Mileston
 		targetIndex = targetIndexMax
 	}
 
+	// milestones that are held by extensions must not be pruned
+	if heldTargetIndex := p.reservePruningTargetIndex(targetIndex); heldTargetIndex < targetIndex {
+		p.LogDebugf("pruning target index limited by holds (%d => %d)", targetIndex, heldTargetIndex)
+		targetIndex = heldTargetIndex
+	}
+	defer p.releasePruningTargetIndex()
+
 	snapshotInfo := p.storage.SnapshotInfo()
 	if snapshotInfo == nil {
 		return 0, errors.Wrap(common.ErrCritical, common.ErrSnapshotInfoNotFound.Error())
 	}
 
-	if snapshotInfo.PruningIndex() >= targetIndex {
-		// no pruning needed
-		return 0, errors.Wrapf(ErrNoPruningNeeded, "pruning index: %d, target index: %d", snapshotInfo.PruningIndex(), targetIndex)
-	}
-
-	if snapshotInfo.EntryPointIndex()+p.additionalPruningThreshold+1 > targetIndex {
-		// we prune in "additionalPruningThreshold" steps to recalculate the solidEntryPoints
-		return 0, errors.Wrapf(ErrNotEnoughHistory, "minimum index: %d, target index: %d", snapshotInfo.EntryPointIndex()+p.additionalPruningThreshold+1, targetIndex)
+	if err := p.checkPruningTargetIndex(snapshotInfo, targetIndex); err != nil {
+		return 0, err
 	}
 
 	p.setIsPruning(true)
@@ -338,33 +524,8 @@ func (p *Manager) pruneDatabase(ctx context.Context, targetIndex iotago.Mileston
 			continue
 		}
 
-		blockIDsToDeleteMap := make(map[iotago.BlockID]struct{})
-
-		if err := dag.TraverseParents(
-			ctx,
-			p.storage,
-			cachedMilestone.Milestone().Parents(),
-			// traversal stops if no more blocks pass the given condition
-			// Caution: condition func is not in DFS order
-			func(cachedBlockMeta *storagepkg.CachedMetadata) (bool, error) { // meta +1
-				defer cachedBlockMeta.Release(true) // meta -1
-				// everything that was referenced by that milestone can be pruned (even blocks of older milestones)
-				return true, nil
-			},
-			// consumer
-			func(cachedBlockMeta *storagepkg.CachedMetadata) error { // meta +1
-				defer cachedBlockMeta.Release(true) // meta -1
-				blockIDsToDeleteMap[cachedBlockMeta.Metadata().BlockID()] = struct{}{}
-
-				return nil
-			},
-			// called on missing parents
-			func(parentBlockID iotago.BlockID) error { return nil },
-			// called on solid entry points
-			// Ignore solid entry points (snapshot milestone included)
-			nil,
-			// the pruning target index is also a solid entry point => traverse it anyways
-			true); err != nil {
+		blockIDsToDeleteMap, err := p.milestoneConeBlockIDsToPrune(ctx, cachedMilestone.Milestone().Parents(), nil)
+		if err != nil {
 			cachedMilestone.Release(true) // milestone -1
 			p.LogWarnf("Pruning milestone (%d) failed! %s", milestoneIndex, err)
 
@@ -374,15 +535,28 @@ func (p *Manager) pruneDatabase(ctx context.Context, targetIndex iotago.Mileston
 
 		// check whether milestone contained receipt and delete it accordingly
 		var migratedAtIndex []iotago.MilestoneIndex
+		var receipt *iotago.ReceiptMilestoneOpt
 
 		opts, err := cachedMilestone.Milestone().Milestone().Opts.Set()
 		if err == nil && opts != nil {
 			if r := opts.Receipt(); r != nil {
 				migratedAtIndex = append(migratedAtIndex, r.MigratedAt)
+				receipt = r
+			}
+		}
+
+		if p.archive != nil {
+			// the milestone cone needs to be archived before it gets deleted.
+			// if archiving fails, we stop pruning to not lose any data.
+			if err := p.archiveMilestoneCone(cachedMilestone.Milestone(), blockIDsToDeleteMap, receipt); err != nil {
+				cachedMilestone.Release(true) // milestone -1
+
+				return 0, errors.Wrapf(err, "archiving milestone (%d) failed", milestoneIndex)
 			}
 		}
 
 		cachedMilestone.Release(true) // milestone -1
+		timeArchiveMilestoneCone := time.Now()
 
 		if err := p.pruneMilestone(milestoneIndex, migratedAtIndex...); err != nil {
 			p.LogWarnf("Pruning milestone (%d) failed! %s", milestoneIndex, err)
@@ -406,7 +580,8 @@ func (p *Manager) pruneDatabase(ctx context.Context, targetIndex iotago.Mileston
 		p.Events.PruningMetricsUpdated.Trigger(&Metrics{
 			DurationPruneUnreferencedBlocks:      timePruneUnreferencedBlocks.Sub(timeStart),
 			DurationTraverseMilestoneCone:        timeTraverseMilestoneCone.Sub(timePruneUnreferencedBlocks),
-			DurationPruneMilestone:               timePruneMilestone.Sub(timeTraverseMilestoneCone),
+			DurationArchiveMilestoneCone:         timeArchiveMilestoneCone.Sub(timeTraverseMilestoneCone),
+			DurationPruneMilestone:               timePruneMilestone.Sub(timeArchiveMilestoneCone),
 			DurationPruneBlocks:                  timePruneBlocks.Sub(timePruneMilestone),
 			DurationSetSnapshotInfo:              timeSetSnapshotInfo.Sub(timePruneBlocks),
 			DurationPruningMilestoneIndexChanged: timePruningMilestoneIndexChanged.Sub(timeSetSnapshotInfo),
@@ -453,11 +628,23 @@ func (p *Manager) PruneDatabaseByTargetIndex(ctx context.Context, targetIndex io
 	return p.pruneDatabase(ctx, targetIndex)
 }
 
+func (p *Manager) PruneDatabaseByRetentionPeriod(ctx context.Context, retentionPeriod time.Duration) (iotago.MilestoneIndex, error) {
+	p.snapshotLock.Lock()
+	defer p.snapshotLock.Unlock()
+
+	targetIndex, err := p.calcTargetIndexByTime(retentionPeriod)
+	if err != nil {
+		return 0, err
+	}
+
+	return p.pruneDatabase(ctx, targetIndex)
+}
+
 func (p *Manager) PruneDatabaseBySize(ctx context.Context, targetSizeBytes int64) (iotago.MilestoneIndex, error) {
 	p.snapshotLock.Lock()
 	defer p.snapshotLock.Unlock()
 
-	targetIndex, err := p.calcTargetIndexBySize(targetSizeBytes)
+	targetIndex, _, err := p.calcTargetIndexBySize(targetSizeBytes)
 	if err != nil {
 		return 0, err
 	}
@@ -478,9 +665,16 @@ func (p *Manager) HandleNewConfirmedMilestoneEvent(ctx context.Context, confirme
 		targetIndex = confirmedMilestoneIndex - p.pruningMilestonesMaxMilestonesToKeep
 	}
 
+	if p.pruningTimeEnabled {
+		targetIndexTime, err := p.calcTargetIndexByTime()
+		if err == nil && targetIndex < targetIndexTime {
+			targetIndex = targetIndexTime
+		}
+	}
+
 	pruningBySize := false
 	if p.pruningSizeEnabled && (p.lastPruningBySizeTime.IsZero() || time.Since(p.lastPruningBySizeTime) > p.pruningSizeCooldownTime) {
-		targetIndexSize, err := p.calcTargetIndexBySize()
+		targetIndexSize, _, err := p.calcTargetIndexBySize()
 		if err == nil && ((targetIndex == 0) || (targetIndex < targetIndexSize)) {
 			targetIndex = targetIndexSize
 			pruningBySize = true
@@ -0,0 +1,153 @@
+//nolint:forcetypeassert,varnamelen,revive,exhaustruct // we don't care about these linters in test cases
+package pruning
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	hivedb "github.com/iotaledger/hive.go/kvstore/database"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hornet/v2/pkg/database"
+	"github.com/iotaledger/hornet/v2/pkg/metrics"
+	"github.com/iotaledger/hornet/v2/pkg/testsuite"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+const (
+	ProtocolVersion = 2
+	BelowMaxDepth   = 5
+	MinPoWScore     = 10
+)
+
+func newTestPruningManager(t *testing.T, milestonesCount int) (*testsuite.TestEnvironment, *Manager) {
+	t.Helper()
+
+	te := testsuite.SetupTestEnvironment(t, &iotago.Ed25519Address{}, 0, ProtocolVersion, BelowMaxDepth, MinPoWScore, false)
+	t.Cleanup(func() { te.CleanupTestEnvironment(true) })
+
+	_, _ = te.BuildTangle(5, BelowMaxDepth, milestonesCount, 5, 10,
+		nil,
+		func(blockIDs iotago.BlockIDs, blockIDsPerMilestones []iotago.BlockIDs) iotago.BlockIDs {
+			return iotago.BlockIDs{blockIDs[len(blockIDs)-1]}
+		},
+		nil,
+	)
+
+	// the milestones count includes the genesis milestone
+	require.Equal(t, iotago.MilestoneIndex(milestonesCount-1), te.SyncManager().ConfirmedMilestoneIndex())
+
+	// the size of the in-memory databases is not known, and they don't support compactions
+	newTestDatabase := func() *database.Database {
+		return database.New("", mapdb.NewMapDB(), hivedb.EngineMapDB, &metrics.DatabaseMetrics{}, database.NewEvents(), false, nil, nil, nil)
+	}
+
+	manager := NewPruningManager(logger.NewLogger("Pruning"), te.Storage(), te.SyncManager(), newTestDatabase(), newTestDatabase(), nil,
+		te.SyncManager().ConfirmedMilestoneIndex,
+		false, 0, false, 0, 0, 0, false, 0, false)
+
+	return te, manager
+}
+
+func TestHeldTargetIndex(t *testing.T) {
+	_, manager := newTestPruningManager(t, 12)
+
+	// without holds the target index is not changed
+	require.Equal(t, iotago.MilestoneIndex(8), manager.heldTargetIndex(8))
+
+	_, err := manager.AddHold("indexer", 6, 0)
+	require.NoError(t, err)
+
+	// the held milestone itself must be kept
+	require.Equal(t, iotago.MilestoneIndex(5), manager.heldTargetIndex(8))
+	require.Equal(t, iotago.MilestoneIndex(5), manager.heldTargetIndex(6))
+	require.Equal(t, iotago.MilestoneIndex(5), manager.heldTargetIndex(5))
+	require.Equal(t, iotago.MilestoneIndex(3), manager.heldTargetIndex(3))
+
+	// the lowest hold wins, independent of the order the holds were added
+	_, err = manager.AddHold("explorer", 9, 0)
+	require.NoError(t, err)
+	_, err = manager.AddHold("chronicle", 4, 0)
+	require.NoError(t, err)
+	require.Equal(t, iotago.MilestoneIndex(3), manager.heldTargetIndex(10))
+
+	// replacing a hold with the same ID moves the index forward
+	_, err = manager.AddHold("chronicle", 7, 0)
+	require.NoError(t, err)
+	require.Equal(t, iotago.MilestoneIndex(5), manager.heldTargetIndex(10))
+
+	// expired holds are ignored and removed
+	hold, err := manager.AddHold("indexer", 2, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, iotago.MilestoneIndex(1), manager.heldTargetIndex(10))
+
+	hold.ExpiresAt = time.Now().Add(-time.Second)
+	require.Equal(t, iotago.MilestoneIndex(6), manager.heldTargetIndex(10))
+	require.Len(t, manager.Holds(), 2)
+
+	// released holds no longer limit the target index
+	require.NoError(t, manager.RemoveHold("chronicle"))
+	require.Equal(t, iotago.MilestoneIndex(8), manager.heldTargetIndex(10))
+	require.ErrorIs(t, manager.RemoveHold("chronicle"), ErrHoldNotFound)
+
+	// the reserved range of a running pruning can't be held anymore
+	require.Equal(t, iotago.MilestoneIndex(8), manager.reservePruningTargetIndex(10))
+	_, err = manager.AddHold("indexer", 8, 0)
+	require.ErrorIs(t, err, ErrHoldIndexAlreadyPruned)
+
+	manager.releasePruningTargetIndex()
+	_, err = manager.AddHold("indexer", 8, 0)
+	require.NoError(t, err)
+
+	// holds above the confirmed milestone index are rejected
+	_, err = manager.AddHold("indexer", 12, 0)
+	require.ErrorIs(t, err, ErrHoldIndexNotConfirmed)
+	_, err = manager.AddHold("", 8, 0)
+	require.ErrorIs(t, err, ErrHoldIDEmpty)
+}
+
+func TestCalcTargetIndexByTime(t *testing.T) {
+	te, manager := newTestPruningManager(t, 21)
+
+	confirmedMilestoneIndex := te.SyncManager().ConfirmedMilestoneIndex()
+
+	// returns the retention period that puts the cutoff time right after the given milestone
+	retentionPeriodAfterMilestone := func(msIndex iotago.MilestoneIndex) time.Duration {
+		msTimestamp, err := te.Storage().MilestoneTimestampByIndex(msIndex)
+		require.NoError(t, err)
+
+		// keep a safety margin to the next milestone, the cutoff time is calculated later
+		return time.Since(msTimestamp.Add(time.Second))
+	}
+
+	// every milestone in the range must be found by the binary search
+	for msIndex := iotago.MilestoneIndex(1); msIndex <= confirmedMilestoneIndex; msIndex++ {
+		targetIndex, err := manager.calcTargetIndexByTime(retentionPeriodAfterMilestone(msIndex))
+		require.NoError(t, err)
+		require.Equal(t, msIndex, targetIndex)
+	}
+
+	// no milestone is older than the cutoff time
+	firstTimestamp, err := te.Storage().MilestoneTimestampByIndex(1)
+	require.NoError(t, err)
+	_, err = manager.calcTargetIndexByTime(time.Since(firstTimestamp) + time.Hour)
+	require.ErrorIs(t, err, ErrNoPruningNeeded)
+
+	// pruning by time is disabled without a retention period
+	_, err = manager.calcTargetIndexByTime()
+	require.ErrorIs(t, err, ErrNoPruningNeeded)
+	_, err = manager.calcTargetIndexByTime(0)
+	require.ErrorIs(t, err, ErrNoPruningNeeded)
+
+	// the binary search only covers the milestones that were not pruned yet
+	require.NoError(t, te.Storage().SetPruningIndex(10))
+
+	_, err = manager.calcTargetIndexByTime(retentionPeriodAfterMilestone(10))
+	require.ErrorIs(t, err, ErrNoPruningNeeded)
+
+	targetIndex, err := manager.calcTargetIndexByTime(retentionPeriodAfterMilestone(11))
+	require.NoError(t, err)
+	require.Equal(t, iotago.MilestoneIndex(11), targetIndex)
+}
@@ -0,0 +1,194 @@
+package restapi
+
+import (
+	"sync"
+
+	"go.uber.org/atomic"
+)
+
+// Event is a serialized event that is published on a topic.
+type Event struct {
+	// The topic the event was published on.
+	Topic string
+	// The serialized payload of the event.
+	Data []byte
+}
+
+// EventSubscriber receives the events of the topics it subscribed to.
+type EventSubscriber struct {
+	broker *EventBroker
+	// the topics the subscriber is interested in.
+	// protected by the lock of the broker.
+	topics map[string]struct{}
+	events chan *Event
+	// the amount of events that were dropped because the subscriber was too slow.
+	dropped atomic.Uint64
+}
+
+// Events returns the channel the events of the subscribed topics are sent to.
+// The channel is closed if the subscriber gets unsubscribed.
+func (s *EventSubscriber) Events() <-chan *Event {
+	return s.events
+}
+
+// Dropped returns the amount of events that were dropped because the subscriber was too slow.
+func (s *EventSubscriber) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Topics returns the topics the subscriber is subscribed to.
+func (s *EventSubscriber) Topics() []string {
+	s.broker.RLock()
+	defer s.broker.RUnlock()
+
+	topics := make([]string, 0, len(s.topics))
+	for topic := range s.topics {
+		topics = append(topics, topic)
+	}
+
+	return topics
+}
+
+// Subscribe adds the given topics to the subscriber.
+func (s *EventSubscriber) Subscribe(topics ...string) {
+	s.broker.Lock()
+	defer s.broker.Unlock()
+
+	for _, topic := range topics {
+		if _, exists := s.topics[topic]; exists {
+			continue
+		}
+		s.topics[topic] = struct{}{}
+		s.broker.topicSubscribers[topic]++
+	}
+}
+
+// Unsubscribe removes the given topics from the subscriber.
+func (s *EventSubscriber) Unsubscribe(topics ...string) {
+	s.broker.Lock()
+	defer s.broker.Unlock()
+
+	for _, topic := range topics {
+		s.broker.removeTopicWithoutLocking(s, topic)
+	}
+}
+
+// Close unsubscribes the subscriber from all topics and removes it from the broker.
+func (s *EventSubscriber) Close() {
+	s.broker.Lock()
+	defer s.broker.Unlock()
+
+	if _, exists := s.broker.subscribers[s]; !exists {
+		return
+	}
+
+	for topic := range s.topics {
+		s.broker.removeTopicWithoutLocking(s, topic)
+	}
+	delete(s.broker.subscribers, s)
+	close(s.events)
+}
+
+// EventBroker distributes published events to all subscribers of a topic.
+// Slow subscribers do not block the publisher, events are dropped instead
+// if the buffer of a subscriber is full.
+type EventBroker struct {
+	sync.RWMutex
+	subscribers map[*EventSubscriber]struct{}
+	// the amount of subscribers per topic.
+	topicSubscribers map[string]int
+	// called every time an event is dropped.
+	onDropped func()
+}
+
+// NewEventBroker creates a new EventBroker.
+// onDropped is called every time an event is dropped, it can be nil.
+func NewEventBroker(onDropped func()) *EventBroker {
+	return &EventBroker{
+		subscribers:      make(map[*EventSubscriber]struct{}),
+		topicSubscribers: make(map[string]int),
+		onDropped:        onDropped,
+	}
+}
+
+func (b *EventBroker) removeTopicWithoutLocking(s *EventSubscriber, topic string) {
+	if _, exists := s.topics[topic]; !exists {
+		return
+	}
+	delete(s.topics, topic)
+
+	b.topicSubscribers[topic]--
+	if b.topicSubscribers[topic] <= 0 {
+		delete(b.topicSubscribers, topic)
+	}
+}
+
+// NewSubscriber creates a new subscriber with the given buffer size.
+func (b *EventBroker) NewSubscriber(bufferSize int) *EventSubscriber {
+	b.Lock()
+	defer b.Unlock()
+
+	s := &EventSubscriber{
+		broker: b,
+		topics: make(map[string]struct{}),
+		events: make(chan *Event, bufferSize),
+	}
+	b.subscribers[s] = struct{}{}
+
+	return s
+}
+
+// SubscribersCount returns the amount of subscribers.
+func (b *EventBroker) SubscribersCount() int {
+	b.RLock()
+	defer b.RUnlock()
+
+	return len(b.subscribers)
+}
+
+// HasSubscribers returns whether there are subscribers for the given topic.
+func (b *EventBroker) HasSubscribers(topic string) bool {
+	b.RLock()
+	defer b.RUnlock()
+
+	return b.topicSubscribers[topic] > 0
+}
+
+// Publish sends the event to all subscribers of the topic.
+// The payload is only serialized if there are subscribers for the topic.
+func (b *EventBroker) Publish(topic string, payloadFunc func() ([]byte, error)) error {
+	if !b.HasSubscribers(topic) {
+		return nil
+	}
+
+	data, err := payloadFunc()
+	if err != nil {
+		return err
+	}
+
+	event := &Event{
+		Topic: topic,
+		Data:  data,
+	}
+
+	b.RLock()
+	defer b.RUnlock()
+
+	for s := range b.subscribers {
+		if _, subscribed := s.topics[topic]; !subscribed {
+			continue
+		}
+
+		select {
+		case s.events <- event:
+		default:
+			// the subscriber is too slow, drop the event
+			s.dropped.Inc()
+			if b.onDropped != nil {
+				b.onDropped()
+			}
+		}
+	}
+
+	return nil
+}
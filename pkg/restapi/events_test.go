@@ -0,0 +1,64 @@
+//nolint:forcetypeassert,varnamelen,revive,exhaustruct // we don't care about these linters in test cases
+package restapi_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hornet/v2/pkg/restapi"
+)
+
+func TestEventBroker(t *testing.T) {
+	var droppedCount int
+	broker := restapi.NewEventBroker(func() { droppedCount++ })
+
+	subA := broker.NewSubscriber(1)
+	subB := broker.NewSubscriber(10)
+	require.Equal(t, 2, broker.SubscribersCount())
+
+	subA.Subscribe("milestone-info/latest", "blocks")
+	subB.Subscribe("blocks")
+	require.True(t, broker.HasSubscribers("milestone-info/latest"))
+	require.True(t, broker.HasSubscribers("blocks"))
+	require.False(t, broker.HasSubscribers("milestone-info/confirmed"))
+
+	// the payload must not be serialized if nobody is interested
+	require.NoError(t, broker.Publish("milestone-info/confirmed", func() ([]byte, error) {
+		t.Fatal("payload serialized without subscribers")
+
+		return nil, nil
+	}))
+
+	payload := func(data string) func() ([]byte, error) {
+		return func() ([]byte, error) { return []byte(data), nil }
+	}
+
+	require.NoError(t, broker.Publish("blocks", payload("block1")))
+	// subA's buffer is full, the event is dropped for subA only
+	require.NoError(t, broker.Publish("blocks", payload("block2")))
+	require.Equal(t, uint64(1), subA.Dropped())
+	require.Equal(t, uint64(0), subB.Dropped())
+	require.Equal(t, 1, droppedCount)
+
+	event := <-subA.Events()
+	require.Equal(t, "blocks", event.Topic)
+	require.Equal(t, []byte("block1"), event.Data)
+
+	require.Equal(t, []byte("block1"), (<-subB.Events()).Data)
+	require.Equal(t, []byte("block2"), (<-subB.Events()).Data)
+
+	subB.Unsubscribe("blocks")
+	require.True(t, broker.HasSubscribers("blocks"))
+
+	subA.Close()
+	require.False(t, broker.HasSubscribers("blocks"))
+	require.False(t, broker.HasSubscribers("milestone-info/latest"))
+	require.Equal(t, 1, broker.SubscribersCount())
+
+	_, ok := <-subA.Events()
+	require.False(t, ok)
+
+	// closing twice must not panic
+	subA.Close()
+}
@@ -0,0 +1,84 @@
+package restapi
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+type rateLimiterEntry struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// RateLimiter is a token bucket rate limiter that keeps a separate bucket per client.
+type RateLimiter struct {
+	mutex   sync.Mutex
+	limit   rate.Limit
+	burst   int
+	clients map[string]*rateLimiterEntry
+}
+
+// NewRateLimiter creates a new RateLimiter that allows requestsPerSecond requests
+// per client with bursts of up to burst requests.
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		limit:   rate.Limit(requestsPerSecond),
+		burst:   burst,
+		clients: make(map[string]*rateLimiterEntry),
+	}
+}
+
+// Allow checks if the client with the given key is allowed to issue another request.
+// If the request is not allowed, the duration the client has to wait until
+// the next request would be allowed is returned.
+func (r *RateLimiter) Allow(key string) (bool, time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+
+	entry, exists := r.clients[key]
+	if !exists {
+		entry = &rateLimiterEntry{limiter: rate.NewLimiter(r.limit, r.burst)}
+		r.clients[key] = entry
+	}
+	entry.lastSeen = now
+
+	reservation := entry.limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		// the burst is zero, requests are never allowed
+		return false, 0
+	}
+
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		// give the token back, the request is rejected
+		reservation.CancelAt(now)
+
+		return false, delay
+	}
+
+	return true, 0
+}
+
+// Cleanup removes the buckets of all clients that were not seen for the given duration.
+func (r *RateLimiter) Cleanup(maxIdle time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	deadline := time.Now().Add(-maxIdle)
+	for key, entry := range r.clients {
+		if entry.lastSeen.Before(deadline) {
+			delete(r.clients, key)
+		}
+	}
+}
+
+// ClientsCount returns the amount of clients that are currently tracked.
+func (r *RateLimiter) ClientsCount() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return len(r.clients)
+}
@@ -0,0 +1,49 @@
+//nolint:forcetypeassert,varnamelen,revive,exhaustruct // we don't care about these linters in test cases
+package restapi_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hornet/v2/pkg/restapi"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := restapi.NewRateLimiter(1, 2)
+
+	// the burst is available immediately
+	for i := 0; i < 2; i++ {
+		allowed, _ := limiter.Allow("client1")
+		require.True(t, allowed)
+	}
+
+	allowed, retryAfter := limiter.Allow("client1")
+	require.False(t, allowed)
+	require.Greater(t, retryAfter, time.Duration(0))
+	require.LessOrEqual(t, retryAfter, time.Second)
+
+	// other clients have their own bucket
+	allowed, _ = limiter.Allow("client2")
+	require.True(t, allowed)
+	require.Equal(t, 2, limiter.ClientsCount())
+
+	// rejected requests do not consume tokens
+	time.Sleep(retryAfter)
+	allowed, _ = limiter.Allow("client1")
+	require.True(t, allowed)
+
+	limiter.Cleanup(time.Hour)
+	require.Equal(t, 2, limiter.ClientsCount())
+
+	limiter.Cleanup(0)
+	require.Equal(t, 0, limiter.ClientsCount())
+}
+
+func TestRateLimiterZeroBurst(t *testing.T) {
+	limiter := restapi.NewRateLimiter(1, 0)
+
+	allowed, _ := limiter.Allow("client1")
+	require.False(t, allowed)
+}
@@ -1,11 +1,16 @@
 package restapi
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/labstack/echo/v4"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/pkg/errors"
 
 	"github.com/iotaledger/inx-app/pkg/httpserver"
+	iotago "github.com/iotaledger/iota.go/v3"
 )
 
 const (
@@ -26,6 +31,33 @@ const (
 
 	// ParameterPeerID is used to identify a peer.
 	ParameterPeerID = "peerID"
+
+	// ParameterHoldID is used to identify a pruning hold.
+	ParameterHoldID = "holdID"
+
+	// ParameterFileName is used to identify a file by its name.
+	ParameterFileName = "fileName"
+
+	// ParameterBech32Address is used to identify an address by its bech32 representation.
+	ParameterBech32Address = "bech32Address"
+
+	// QueryParameterFrom is used to define the start of a range (inclusive).
+	QueryParameterFrom = "from"
+
+	// QueryParameterTo is used to define the end of a range (inclusive).
+	QueryParameterTo = "to"
+
+	// QueryParameterCursor is used to continue a paginated request.
+	QueryParameterCursor = "cursor"
+
+	// QueryParameterPageSize is used to define the page size of a paginated request.
+	QueryParameterPageSize = "pageSize"
+
+	// QueryParameterAtMilestone is used to query the ledger state at a given milestone index.
+	QueryParameterAtMilestone = "atMilestone"
+
+	// QueryParameterTarget is used to identify the target of a peer ban.
+	QueryParameterTarget = "target"
 )
 
 type (
@@ -41,3 +73,80 @@ func ParsePeerIDParam(c echo.Context) (peer.ID, error) {
 
 	return peerID, nil
 }
+
+// ParseBech32AddressParam parses the bech32 address parameter and checks that it uses the given prefix.
+func ParseBech32AddressParam(c echo.Context, prefix iotago.NetworkPrefix) (iotago.Address, error) {
+	addressParam := strings.ToLower(c.Param(ParameterBech32Address))
+
+	hrp, address, err := iotago.ParseBech32(addressParam)
+	if err != nil {
+		return nil, errors.WithMessagef(httpserver.ErrInvalidParameter, "invalid address: %s, error: %s", addressParam, err)
+	}
+
+	if hrp != prefix {
+		return nil, errors.WithMessagef(httpserver.ErrInvalidParameter, "invalid bech32 address, expected prefix: %s", prefix)
+	}
+
+	return address, nil
+}
+
+// ParsePageSizeQueryParam parses the page size query parameter.
+// If the parameter is not given, maxResults is returned.
+// Page sizes bigger than maxResults are capped to maxResults.
+func ParsePageSizeQueryParam(c echo.Context, maxResults int) (int, error) {
+	if len(c.QueryParam(QueryParameterPageSize)) == 0 {
+		return maxResults, nil
+	}
+
+	pageSize, err := httpserver.ParseUint32QueryParam(c, QueryParameterPageSize)
+	if err != nil {
+		return 0, err
+	}
+
+	if pageSize == 0 {
+		return 0, errors.WithMessagef(httpserver.ErrInvalidParameter, "invalid value for \"%s\": must be greater than 0", QueryParameterPageSize)
+	}
+
+	if int(pageSize) > maxResults {
+		return maxResults, nil
+	}
+
+	return int(pageSize), nil
+}
+
+// EncodeCursor encodes the key of the next element and the page size into an opaque cursor.
+func EncodeCursor(key []byte, pageSize int) string {
+	return fmt.Sprintf("%s.%d", iotago.EncodeHex(key), pageSize)
+}
+
+// ParseCursorQueryParam parses the cursor query parameter.
+// It returns the key of the next element and the page size that were encoded in the cursor.
+// The page size is capped to maxResults.
+func ParseCursorQueryParam(c echo.Context, keyLength int, maxResults int) ([]byte, int, error) {
+	cursorWithPageSize := c.QueryParam(QueryParameterCursor)
+
+	cursorParts := strings.Split(cursorWithPageSize, ".")
+	if len(cursorParts) != 2 {
+		return nil, 0, errors.WithMessagef(httpserver.ErrInvalidParameter, "invalid cursor: %s", cursorWithPageSize)
+	}
+
+	key, err := iotago.DecodeHex(cursorParts[0])
+	if err != nil {
+		return nil, 0, errors.WithMessagef(httpserver.ErrInvalidParameter, "invalid cursor: %s, error: %s", cursorWithPageSize, err)
+	}
+
+	if len(key) != keyLength {
+		return nil, 0, errors.WithMessagef(httpserver.ErrInvalidParameter, "invalid cursor: %s, invalid key length: %d", cursorWithPageSize, len(key))
+	}
+
+	pageSize, err := strconv.ParseUint(cursorParts[1], 10, 32)
+	if err != nil || pageSize == 0 {
+		return nil, 0, errors.WithMessagef(httpserver.ErrInvalidParameter, "invalid cursor: %s, invalid page size", cursorWithPageSize)
+	}
+
+	if int(pageSize) > maxResults {
+		pageSize = uint64(maxResults)
+	}
+
+	return key, int(pageSize), nil
+}
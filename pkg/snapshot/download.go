@@ -5,15 +5,17 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/pkg/errors"
+	"go.uber.org/atomic"
 
 	"github.com/iotaledger/hive.go/runtime/contextutils"
+	"github.com/iotaledger/hornet/v2/pkg/metrics"
 	iotago "github.com/iotaledger/iota.go/v3"
 )
 
@@ -24,27 +26,48 @@ const (
 
 // WriteCounter counts the number of bytes written to it. It implements to the io.Writer interface
 // and we can pass this into io.TeeReader() which will report progress on each write cycle.
+// It is safe to write to the WriteCounter from several goroutines.
 type WriteCounter struct {
 	// context that is done when the node is shutting down.
 	ctx      context.Context
 	Expected uint64
 
-	total            uint64
+	// optional metrics the progress is exposed to.
+	downloadMetrics *metrics.SnapshotDownloadMetrics
+
+	total            atomic.Uint64
+	progressLock     sync.Mutex
 	last             uint64
 	lastProgressTime time.Time
 }
 
 // NewWriteCounter creates a new WriteCounter.
-func NewWriteCounter(ctx context.Context, expected uint64) *WriteCounter {
-	return &WriteCounter{
-		ctx:      ctx,
-		Expected: expected,
+// Written is the amount of bytes that were already written before, e.g. when a download is resumed.
+func NewWriteCounter(ctx context.Context, expected uint64, written uint64, downloadMetrics *metrics.SnapshotDownloadMetrics) *WriteCounter {
+	wc := &WriteCounter{
+		ctx:              ctx,
+		Expected:         expected,
+		downloadMetrics:  downloadMetrics,
+		last:             written,
+		lastProgressTime: time.Now(),
+	}
+	wc.total.Store(written)
+
+	if downloadMetrics != nil {
+		downloadMetrics.BytesExpected.Store(expected)
+		downloadMetrics.BytesDownloaded.Store(written)
 	}
+
+	return wc
 }
 
 func (wc *WriteCounter) Write(p []byte) (int, error) {
 	n := len(p)
-	wc.total += uint64(n)
+	total := wc.total.Add(uint64(n))
+
+	if wc.downloadMetrics != nil {
+		wc.downloadMetrics.BytesDownloaded.Store(total)
+	}
 
 	if err := contextutils.ReturnErrIfCtxDone(wc.ctx, ErrSnapshotDownloadWasAborted); err != nil {
 		return n, err
@@ -55,15 +78,24 @@ func (wc *WriteCounter) Write(p []byte) (int, error) {
 	return n, nil
 }
 
+// Total returns the amount of bytes written.
+func (wc *WriteCounter) Total() uint64 {
+	return wc.total.Load()
+}
+
 // PrintProgress prints the current progress.
 func (wc *WriteCounter) PrintProgress() {
+	wc.progressLock.Lock()
+	defer wc.progressLock.Unlock()
+
 	if time.Since(wc.lastProgressTime) < 1*time.Second {
 		return
 	}
 
-	bytesPerSecond := uint64(float64(wc.total-wc.last) / time.Since(wc.lastProgressTime).Seconds())
+	total := wc.total.Load()
+	bytesPerSecond := uint64(float64(total-wc.last) / time.Since(wc.lastProgressTime).Seconds())
 	wc.lastProgressTime = time.Now()
-	wc.last = wc.total
+	wc.last = total
 
 	// clear the line by using a character return to go back to the start and remove
 	// the remaining characters by filling it with spaces
@@ -71,7 +103,7 @@ func (wc *WriteCounter) PrintProgress() {
 
 	// return again and print current status of download
 	// we use the humanize package to print the bytes in a meaningful way (e.g. 10 MB)
-	fmt.Printf("\rDownloading ... %s/%s (%s/s)", humanize.Bytes(wc.total), humanize.Bytes(wc.Expected), humanize.Bytes(bytesPerSecond))
+	fmt.Printf("\rDownloading ... %s/%s (%s/s)", humanize.Bytes(total), humanize.Bytes(wc.Expected), humanize.Bytes(bytesPerSecond))
 }
 
 // DownloadTarget holds URLs to a full and delta snapshot.
@@ -80,9 +112,24 @@ type DownloadTarget struct {
 	Full string `usage:"URL of the full snapshot file" json:"full"`
 	// URL of the delta snapshot file.
 	Delta string `usage:"URL of the delta snapshot file" json:"delta"`
+	// URL of an optional hash manifest ("sha256sum" format) the downloaded snapshot files are verified against.
+	Manifest string `usage:"URL of an optional hash manifest the downloaded snapshot files are verified against" json:"manifest,omitempty"`
+	// Optional bearer token that is sent with all requests to the target (e.g. a JWT for the protected snapshot routes of another node).
+	Token string `usage:"optional bearer token that is sent with all requests to the target (e.g. a JWT for the protected snapshot routes of another node)" json:"token,omitempty"`
 }
 
-func (s *Importer) filterTargets(ctx context.Context, targetNetworkID uint64, targets []*DownloadTarget) []*DownloadTarget {
+// downloadSource is a download target whose snapshot headers were checked.
+type downloadSource struct {
+	target *DownloadTarget
+	// the ledger index after applying the snapshot files of the target.
+	index iotago.MilestoneIndex
+	// identifies the full snapshot file, targets with the same ID are mirrors of the full snapshot file.
+	fullSnapshotID string
+	// identifies the delta snapshot file, targets with the same ID are mirrors of the delta snapshot file.
+	deltaSnapshotID string
+}
+
+func (s *Importer) filterTargets(ctx context.Context, targetNetworkID uint64, targets []*DownloadTarget) []*downloadSource {
 
 	// check if the remote snapshot files fit the network ID and if delta fits the full snapshot.
 	checkTargetConsistency := func(targetNetworkID uint64, fullHeader *FullSnapshotHeader, deltaHeader *DeltaSnapshotHeader) error {
@@ -111,19 +158,14 @@ func (s *Importer) filterTargets(ctx context.Context, targetNetworkID uint64, ta
 		return nil
 	}
 
-	type downloadTargetWithIndex struct {
-		target *DownloadTarget
-		index  iotago.MilestoneIndex
-	}
-
-	filteredTargets := []*downloadTargetWithIndex{}
+	sources := []*downloadSource{}
 
 	// search the latest snapshot by scanning all target headers
 	for _, target := range targets {
 		s.LogDebugf("downloading full snapshot header from %s", target.Full)
 
 		var fullHeader *FullSnapshotHeader
-		if err := s.downloadHeader(ctx, target.Full, func(readCloser io.ReadCloser) error {
+		if err := s.downloadHeader(ctx, target.Full, target.Token, func(readCloser io.ReadCloser) error {
 			fullSnapshotHeader, err := ReadFullSnapshotHeader(readCloser)
 			if err != nil {
 				return err
@@ -142,7 +184,7 @@ func (s *Importer) filterTargets(ctx context.Context, targetNetworkID uint64, ta
 		var deltaHeader *DeltaSnapshotHeader
 		if len(target.Delta) > 0 {
 			s.LogDebugf("downloading delta snapshot header from %s", target.Delta)
-			if err := s.downloadHeader(ctx, target.Delta, func(readCloser io.ReadCloser) error {
+			if err := s.downloadHeader(ctx, target.Delta, target.Token, func(readCloser io.ReadCloser) error {
 				deltaSnapshotHeader, err := ReadDeltaSnapshotHeader(readCloser)
 				if err != nil {
 					return err
@@ -171,40 +213,94 @@ func (s *Importer) filterTargets(ctx context.Context, targetNetworkID uint64, ta
 			target.Delta = ""
 		}
 
-		filteredTargets = append(filteredTargets, &downloadTargetWithIndex{
-			target: target,
-			index:  getSnapshotFilesLedgerIndex(fullHeader, deltaHeader),
-		})
+		source := &downloadSource{
+			target:         target,
+			index:          getSnapshotFilesLedgerIndex(fullHeader, deltaHeader),
+			fullSnapshotID: fullHeader.TargetMilestoneID.ToHex(),
+		}
+		if deltaHeader != nil {
+			source.deltaSnapshotID = fmt.Sprintf("%s-%d", deltaHeader.FullSnapshotTargetMilestoneID.ToHex(), deltaHeader.TargetMilestoneIndex)
+		}
+
+		sources = append(sources, source)
 	}
 
-	// sort by snapshot index, latest index first
-	sort.Slice(filteredTargets, func(i int, j int) bool {
-		return filteredTargets[i].index > filteredTargets[j].index
+	// sort by snapshot index, latest index first.
+	// targets with the same index keep the configured order, so mirrors are tried in that order.
+	sort.SliceStable(sources, func(i int, j int) bool {
+		return sources[i].index > sources[j].index
 	})
 
-	results := make([]*DownloadTarget, len(filteredTargets))
-	for i := 0; i < len(filteredTargets); i++ {
-		results[i] = filteredTargets[i].target
-	}
-
-	return results
+	return sources
 }
 
 // DownloadSnapshotFiles tries to download snapshots files from the given targets.
+// Targets that serve the same snapshot files are used as mirrors,
+// if a download from one of them fails, the download is continued from the next mirror.
 func (s *Importer) DownloadSnapshotFiles(ctx context.Context, targetNetworkID uint64, fullPath string, deltaPath string, targets []*DownloadTarget) error {
 
-	for _, target := range s.filterTargets(ctx, targetNetworkID, targets) {
+	s.downloadMetrics.DownloadRunning.Store(true)
+	defer s.downloadMetrics.DownloadRunning.Store(false)
+
+	sources := s.filterTargets(ctx, targetNetworkID, targets)
+
+	triedFullSnapshotIDs := make(map[string]struct{})
+	for i, source := range sources {
+		if _, tried := triedFullSnapshotIDs[source.fullSnapshotID]; tried {
+			// the full snapshot was already tried on all mirrors
+			continue
+		}
+		triedFullSnapshotIDs[source.fullSnapshotID] = struct{}{}
+
+		fullMirrors := []*downloadMirror{}
+		for _, mirrorSource := range sources[i:] {
+			if mirrorSource.fullSnapshotID != source.fullSnapshotID {
+				continue
+			}
+
+			fullMirrors = append(fullMirrors, &downloadMirror{
+				url:         mirrorSource.target.Full,
+				manifestURL: mirrorSource.target.Manifest,
+				token:       mirrorSource.target.Token,
+				snapshotID:  mirrorSource.fullSnapshotID,
+			})
+		}
+
+		if err := s.downloadFileFromMirrors(ctx, snapshotNames[Full], fullPath, fullMirrors); err != nil {
+			if errors.Is(err, ErrSnapshotDownloadWasAborted) {
+				return err
+			}
 
-		s.LogInfof("downloading full snapshot file from %s", target.Full)
-		if err := s.downloadFile(ctx, fullPath, target.Full); err != nil {
 			s.LogWarn(err)
-			// as the full snapshot URL failed to download, we commence further with our targets
+			// as the full snapshot failed to download, we commence further with our targets
 			continue
 		}
 
-		if len(target.Delta) > 0 {
-			s.LogInfof("downloading delta snapshot file from %s", target.Delta)
-			if err := s.downloadFile(ctx, deltaPath, target.Delta); err != nil {
+		// the sources are sorted by index, so the first source with a delta snapshot has the latest one
+		var deltaMirrors []*downloadMirror
+		for _, mirrorSource := range sources[i:] {
+			if mirrorSource.fullSnapshotID != source.fullSnapshotID || mirrorSource.deltaSnapshotID == "" {
+				continue
+			}
+
+			if len(deltaMirrors) > 0 && mirrorSource.deltaSnapshotID != deltaMirrors[0].snapshotID {
+				continue
+			}
+
+			deltaMirrors = append(deltaMirrors, &downloadMirror{
+				url:         mirrorSource.target.Delta,
+				manifestURL: mirrorSource.target.Manifest,
+				token:       mirrorSource.target.Token,
+				snapshotID:  mirrorSource.deltaSnapshotID,
+			})
+		}
+
+		if len(deltaMirrors) > 0 {
+			if err := s.downloadFileFromMirrors(ctx, snapshotNames[Delta], deltaPath, deltaMirrors); err != nil {
+				if errors.Is(err, ErrSnapshotDownloadWasAborted) {
+					return err
+				}
+
 				// it is valid that no delta snapshot file is available on the target.
 				s.LogWarn(err)
 			}
@@ -216,35 +312,36 @@ func (s *Importer) DownloadSnapshotFiles(ctx context.Context, targetNetworkID ui
 	return ErrSnapshotDownloadNoValidSource
 }
 
-// downloads a snapshot header from the given url.
-func (s *Importer) downloadHeader(ctx context.Context, url string, headerConsumer func(readCloser io.ReadCloser) error) error {
-	ctxHeader, cancelHeader := context.WithTimeout(ctx, timeoutDownloadSnapshotHeader)
-	defer cancelHeader()
+// downloadFileFromMirrors downloads a snapshot file from the first mirror that succeeds.
+// A partially downloaded file is resumed by the next mirror.
+func (s *Importer) downloadFileFromMirrors(ctx context.Context, snapshotName string, filePath string, mirrors []*downloadMirror) error {
+	for i, mirror := range mirrors {
+		if i > 0 {
+			s.downloadMetrics.Failovers.Inc()
+		}
 
-	req, err := http.NewRequestWithContext(ctxHeader, http.MethodGet, url, nil)
-	if err != nil {
-		return fmt.Errorf("download failed: %w", err)
-	}
+		s.LogInfof("downloading %s snapshot file from %s", snapshotName, mirror.url)
+		err := s.downloadFile(ctx, filePath, mirror)
+		if err == nil {
+			return nil
+		}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("download failed: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
+		if errors.Is(err, ErrSnapshotDownloadWasAborted) {
+			return err
+		}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed, server returned status code %d", resp.StatusCode)
+		s.LogWarnf("downloading %s snapshot file from %s failed: %s", snapshotName, mirror.url, err)
 	}
 
-	return headerConsumer(resp.Body)
+	return fmt.Errorf("downloading %s snapshot file failed on all mirrors", snapshotName)
 }
 
-// downloads a snapshot file from the given url to the specified path.
-func (s *Importer) downloadFile(ctx context.Context, path string, url string) error {
-	downloadCtx, downloadCtxCancel := context.WithTimeout(ctx, timeoutDownloadSnapshotFile)
-	defer downloadCtxCancel()
+// downloads a snapshot header from the given url.
+func (s *Importer) downloadHeader(ctx context.Context, url string, token string, headerConsumer func(readCloser io.ReadCloser) error) error {
+	ctxHeader, cancelHeader := context.WithTimeout(ctx, timeoutDownloadSnapshotHeader)
+	defer cancelHeader()
 
-	req, err := http.NewRequestWithContext(downloadCtx, http.MethodGet, url, nil)
+	req, err := newDownloadRequest(ctxHeader, url, token)
 	if err != nil {
 		return fmt.Errorf("download failed: %w", err)
 	}
@@ -259,40 +356,12 @@ func (s *Importer) downloadFile(ctx context.Context, path string, url string) er
 		return fmt.Errorf("download failed, server returned status code %d", resp.StatusCode)
 	}
 
-	tempFileName := path + ".tmp"
-	out, err := os.Create(tempFileName)
+	// compressed snapshot files are decompressed on the fly, so only the header needs to be downloaded
+	reader, err := NewSnapshotStreamReader(resp.Body)
 	if err != nil {
-		return err
-	}
-
-	var ok bool
-	defer func() {
-		if !ok {
-			// we don't need to check the error, maybe the file doesn't exist
-			_ = os.Remove(tempFileName)
-		}
-	}()
-
-	// create our progress reporter and pass it to be used alongside our writer
-	counter := NewWriteCounter(ctx, uint64(resp.ContentLength))
-	if _, err = io.Copy(out, io.TeeReader(resp.Body, counter)); err != nil {
-		_ = out.Close()
-
 		return fmt.Errorf("download failed: %w", err)
 	}
+	defer func() { _ = reader.Close() }()
 
-	// the progress indicator uses the same line so print a new line once it's finished downloading
-	fmt.Print("\n")
-
-	if err = out.Close(); err != nil {
-		return fmt.Errorf("unable to close downloaded snapshot file: %w", err)
-	}
-
-	if err = os.Rename(tempFileName, path); err != nil {
-		return fmt.Errorf("unable to rename downloaded snapshot file: %w", err)
-	}
-
-	ok = true
-
-	return nil
+	return headerConsumer(reader)
 }
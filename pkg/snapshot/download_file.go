@@ -0,0 +1,610 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/pkg/errors"
+)
+
+const (
+	// the minimum size of a chunk of a parallel snapshot file download.
+	minDownloadChunkSize = 1024 * 1024
+	// the interval in which the progress of a resumable download is persisted.
+	downloadStateStoreInterval = 5 * time.Second
+	// the time to wait before a failed range request is retried.
+	downloadRetryDelay = 2 * time.Second
+
+	// the suffix of a partially downloaded snapshot file.
+	partialFileSuffix = ".part"
+	// the suffix of the file that holds the progress of a partially downloaded snapshot file.
+	downloadStateFileSuffix = ".part.json"
+)
+
+// downloadMirror is a server that provides a snapshot file.
+type downloadMirror struct {
+	// URL of the snapshot file.
+	url string
+	// URL of the optional hash manifest.
+	manifestURL string
+	// optional bearer token that is sent with all requests to the mirror.
+	token string
+	// identifies the content of the snapshot file, mirrors with the same ID serve the same snapshot.
+	snapshotID string
+}
+
+// downloadChunk is a byte range of a snapshot file that is downloaded by a single range request.
+type downloadChunk struct {
+	// Start is the offset of the first byte of the chunk.
+	Start int64 `json:"start"`
+	// End is the offset after the last byte of the chunk.
+	End int64 `json:"end"`
+	// Offset is the offset of the next byte of the chunk that needs to be downloaded.
+	Offset int64 `json:"offset"`
+}
+
+// downloadState holds the progress of a partially downloaded snapshot file.
+// It is persisted next to the partial file, so the download can be resumed from the same or another mirror.
+type downloadState struct {
+	lock sync.Mutex
+
+	// SnapshotID identifies the content of the snapshot file.
+	SnapshotID string `json:"snapshotId"`
+	// URL is the URL the file was downloaded from the last time.
+	URL string `json:"url"`
+	// Validator is the ETag or the modification time of the file on the server the file was downloaded from the last time.
+	Validator string `json:"validator,omitempty"`
+	// Hash is the published SHA256 hash of the file, if a hash manifest is available.
+	Hash string `json:"hash,omitempty"`
+	// Size is the total size of the file.
+	Size int64 `json:"size"`
+	// Chunks are the byte ranges of the file that are downloaded in parallel.
+	Chunks []*downloadChunk `json:"chunks"`
+}
+
+func newDownloadState(mirror *downloadMirror, validator string, expectedHash string, size int64, parallelism int) *downloadState {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	chunkSize := size / int64(parallelism)
+	if chunkSize < minDownloadChunkSize {
+		chunkSize = minDownloadChunkSize
+	}
+
+	state := &downloadState{
+		SnapshotID: mirror.snapshotID,
+		URL:        mirror.url,
+		Validator:  validator,
+		Hash:       expectedHash,
+		Size:       size,
+	}
+
+	for start := int64(0); start < size; start += chunkSize {
+		end := start + chunkSize
+		if end > size || size-end < chunkSize/2 {
+			// the last chunk takes the remaining bytes
+			end = size
+		}
+
+		state.Chunks = append(state.Chunks, &downloadChunk{Start: start, End: end, Offset: start})
+
+		if end == size {
+			break
+		}
+	}
+
+	return state
+}
+
+// loadDownloadState loads the progress of a partially downloaded snapshot file.
+// It returns nil if there is no valid download state.
+func loadDownloadState(filePath string) *downloadState {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil
+	}
+
+	state := &downloadState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil
+	}
+
+	var expectedStart int64
+	for _, chunk := range state.Chunks {
+		if chunk.Start != expectedStart || chunk.Offset < chunk.Start || chunk.Offset > chunk.End {
+			return nil
+		}
+		expectedStart = chunk.End
+	}
+
+	if expectedStart != state.Size {
+		return nil
+	}
+
+	return state
+}
+
+// compatible returns whether a partial file with this download state can be resumed from the given mirror.
+// Mirrors of the same snapshot may serve files in a different format (e.g. compressed),
+// so a download is only continued on another server if the published hashes of both files match.
+func (ds *downloadState) compatible(mirror *downloadMirror, validator string, expectedHash string, size int64) bool {
+	if ds.SnapshotID != mirror.snapshotID || ds.Size != size || ds.Hash != expectedHash {
+		return false
+	}
+
+	if ds.URL != mirror.url {
+		return expectedHash != ""
+	}
+
+	// the file on the same server was replaced
+	return ds.Validator == "" || validator == "" || ds.Validator == validator
+}
+
+// copy returns a copy of the download state with the current progress of the chunks.
+func (ds *downloadState) copy() *downloadState {
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+
+	stateCopy := &downloadState{
+		SnapshotID: ds.SnapshotID,
+		URL:        ds.URL,
+		Validator:  ds.Validator,
+		Hash:       ds.Hash,
+		Size:       ds.Size,
+		Chunks:     make([]*downloadChunk, 0, len(ds.Chunks)),
+	}
+
+	for _, chunk := range ds.Chunks {
+		chunkCopy := *chunk
+		stateCopy.Chunks = append(stateCopy.Chunks, &chunkCopy)
+	}
+
+	return stateCopy
+}
+
+// store persists the download state atomically.
+func (ds *downloadState) store(filePath string) error {
+	ds.lock.Lock()
+	data, err := json.Marshal(ds)
+	ds.lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tempFilePath := filePath + ".tmp"
+	if err := os.WriteFile(tempFilePath, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tempFilePath, filePath)
+}
+
+func (ds *downloadState) downloadedBytes() int64 {
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+
+	var downloaded int64
+	for _, chunk := range ds.Chunks {
+		downloaded += chunk.Offset - chunk.Start
+	}
+
+	return downloaded
+}
+
+func (ds *downloadState) chunkOffset(chunk *downloadChunk) int64 {
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+
+	return chunk.Offset
+}
+
+func (ds *downloadState) advanceChunk(chunk *downloadChunk, n int) {
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+
+	chunk.Offset += int64(n)
+}
+
+// chunkWriter writes the response of a range request to the partial file at the offset of the chunk.
+type chunkWriter struct {
+	file    *os.File
+	state   *downloadState
+	chunk   *downloadChunk
+	counter *WriteCounter
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	n, err := w.file.WriteAt(p, w.state.chunkOffset(w.chunk))
+	w.state.advanceChunk(w.chunk, n)
+	if err != nil {
+		return n, err
+	}
+
+	return w.counter.Write(p[:n])
+}
+
+// responseValidator returns the validator of the file on the server that can be used in an "If-Range" header.
+func responseValidator(resp *http.Response) string {
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+
+	return resp.Header.Get("Last-Modified")
+}
+
+// parseContentRange returns the start offset and the total size of the file from a "Content-Range" header ("bytes <start>-<end>/<size>").
+func parseContentRange(contentRange string) (int64, int64, error) {
+	rangeSpec, found := strings.CutPrefix(contentRange, "bytes ")
+	if !found {
+		return 0, 0, fmt.Errorf("invalid content range: %s", contentRange)
+	}
+
+	byteRange, sizeString, found := strings.Cut(rangeSpec, "/")
+	if !found {
+		return 0, 0, fmt.Errorf("invalid content range: %s", contentRange)
+	}
+
+	startString, _, found := strings.Cut(byteRange, "-")
+	if !found {
+		return 0, 0, fmt.Errorf("invalid content range: %s", contentRange)
+	}
+
+	start, err := strconv.ParseInt(startString, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid content range: %s", contentRange)
+	}
+
+	size, err := strconv.ParseInt(sizeString, 10, 64)
+	if err != nil || size <= 0 {
+		// the size is unknown ("*")
+		return 0, 0, fmt.Errorf("invalid content range: %s", contentRange)
+	}
+
+	return start, size, nil
+}
+
+// newDownloadRequest creates a GET request for the given URL.
+// If a token is given, it is sent as bearer token, e.g. to download from the protected snapshot routes of another node.
+func newDownloadRequest(ctx context.Context, url string, token string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return req, nil
+}
+
+// requestRange requests the given byte range of a file.
+// If a validator is given, the server returns the whole file instead if the file was changed.
+func requestRange(ctx context.Context, url string, token string, start int64, end int64, validator string) (*http.Response, error) {
+	req, err := newDownloadRequest(ctx, url, token)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	if validator != "" {
+		req.Header.Set("If-Range", validator)
+	}
+
+	//nolint:bodyclose // the body is closed by the caller
+	return http.DefaultClient.Do(req)
+}
+
+// downloadFile downloads a snapshot file from the given mirror to the specified path.
+// If the server supports range requests, the file is downloaded in parallel chunks,
+// and the download is resumed if a partial file of the same snapshot exists.
+func (s *Importer) downloadFile(ctx context.Context, filePath string, mirror *downloadMirror) error {
+	downloadCtx, downloadCtxCancel := context.WithTimeout(ctx, timeoutDownloadSnapshotFile)
+	defer downloadCtxCancel()
+
+	var expectedHash string
+	if mirror.manifestURL != "" {
+		manifest, err := downloadHashManifest(downloadCtx, mirror.manifestURL, mirror.token)
+		if err != nil {
+			return err
+		}
+
+		if expectedHash, err = manifest.hashForURL(mirror.url); err != nil {
+			return err
+		}
+	}
+
+	partialFilePath := filePath + partialFileSuffix
+	stateFilePath := filePath + downloadStateFileSuffix
+
+	// request the first byte to check whether the server supports range requests
+	resp, err := requestRange(downloadCtx, mirror.url, mirror.token, 0, 0, "")
+	if err != nil {
+		return fmt.Errorf("download failed: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		_, size, errRange := parseContentRange(resp.Header.Get("Content-Range"))
+		_ = resp.Body.Close()
+		if errRange != nil {
+			return fmt.Errorf("download failed: %w", errRange)
+		}
+
+		err = s.downloadFileRanges(downloadCtx, partialFilePath, stateFilePath, mirror, responseValidator(resp), expectedHash, size)
+
+	case http.StatusOK:
+		// the server does not support range requests, so the file is downloaded in a single stream
+		err = s.downloadFileStream(downloadCtx, partialFilePath, stateFilePath, resp.Body, resp.ContentLength)
+		_ = resp.Body.Close()
+
+	default:
+		_ = resp.Body.Close()
+
+		return fmt.Errorf("download failed, server returned status code %d", resp.StatusCode)
+	}
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return ErrSnapshotDownloadWasAborted
+		}
+
+		return fmt.Errorf("download failed: %w", err)
+	}
+
+	if expectedHash != "" {
+		if err := verifyFileHash(partialFilePath, expectedHash); err != nil {
+			s.downloadMetrics.VerificationFailures.Inc()
+
+			// the partial file is corrupted and must not be resumed
+			_ = os.Remove(partialFilePath)
+			_ = os.Remove(stateFilePath)
+
+			return fmt.Errorf("verifying downloaded snapshot file failed: %w", err)
+		}
+
+		s.LogInfof("verified hash of downloaded snapshot file %s", filePath)
+	}
+
+	if err := os.Rename(partialFilePath, filePath); err != nil {
+		return fmt.Errorf("unable to rename downloaded snapshot file: %w", err)
+	}
+
+	// we don't need to check the error, maybe the file doesn't exist
+	_ = os.Remove(stateFilePath)
+
+	s.downloadMetrics.FilesDownloaded.Inc()
+
+	return nil
+}
+
+// downloadFileRanges downloads a file with parallel range requests into the partial file.
+// The progress is persisted in the state file, so the download can be resumed from another mirror or after a restart.
+func (s *Importer) downloadFileRanges(ctx context.Context, partialFilePath string, stateFilePath string, mirror *downloadMirror, validator string, expectedHash string, size int64) error {
+
+	state := loadDownloadState(stateFilePath)
+	if state != nil {
+		if fileInfo, err := os.Stat(partialFilePath); err != nil || fileInfo.Size() != state.Size || !state.compatible(mirror, validator, expectedHash, size) {
+			state = nil
+		}
+	}
+
+	resume := state != nil
+	if !resume {
+		state = newDownloadState(mirror, validator, expectedHash, size, s.downloadParallelism)
+	}
+	state.URL = mirror.url
+	state.Validator = validator
+
+	out, err := os.OpenFile(partialFilePath, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = out.Close() }()
+
+	if !resume {
+		// discard the content of an incompatible partial file and allocate the whole file
+		if err := out.Truncate(0); err != nil {
+			return err
+		}
+		if err := out.Truncate(size); err != nil {
+			return err
+		}
+	}
+
+	resumedBytes := state.downloadedBytes()
+	if resumedBytes > 0 {
+		s.LogInfof("resuming download at %s/%s", humanize.Bytes(uint64(resumedBytes)), humanize.Bytes(uint64(size)))
+		s.downloadMetrics.BytesResumed.Add(uint64(resumedBytes))
+	}
+
+	// the state must only be persisted after the written data was synced,
+	// otherwise it could claim data that was lost in a crash.
+	// the progress is copied before the sync, because the chunks are still written while syncing.
+	storeState := func() error {
+		stateCopy := state.copy()
+
+		if err := out.Sync(); err != nil {
+			return err
+		}
+
+		return stateCopy.store(stateFilePath)
+	}
+
+	counter := NewWriteCounter(ctx, uint64(size), uint64(resumedBytes), s.downloadMetrics)
+
+	chunksCtx, chunksCtxCancel := context.WithCancel(ctx)
+	defer chunksCtxCancel()
+
+	var (
+		wg          sync.WaitGroup
+		chunkErr    error
+		chunkErrMtx sync.Mutex
+	)
+
+	for _, chunk := range state.Chunks {
+		if state.chunkOffset(chunk) >= chunk.End {
+			continue
+		}
+
+		wg.Add(1)
+		go func(chunk *downloadChunk) {
+			defer wg.Done()
+
+			if err := s.downloadChunk(chunksCtx, out, state, chunk, mirror, validator, counter); err != nil {
+				chunkErrMtx.Lock()
+				if chunkErr == nil {
+					chunkErr = err
+				}
+				chunkErrMtx.Unlock()
+
+				// abort the other chunks, the download is continued on another mirror
+				chunksCtxCancel()
+			}
+		}(chunk)
+	}
+
+	chunksDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(chunksDone)
+	}()
+
+	ticker := time.NewTicker(downloadStateStoreInterval)
+	defer ticker.Stop()
+
+	for waiting := true; waiting; {
+		select {
+		case <-ticker.C:
+			// persist the progress periodically, so the download can be resumed after a crash
+			if err := storeState(); err != nil {
+				s.LogWarnf("storing snapshot download state failed: %s", err)
+			}
+		case <-chunksDone:
+			waiting = false
+		}
+	}
+
+	// the progress indicator uses the same line so print a new line once it's finished downloading
+	fmt.Print("\n")
+
+	if chunkErr != nil {
+		// keep the progress, so the download can be resumed from another mirror
+		if err := storeState(); err != nil {
+			s.LogWarnf("storing snapshot download state failed: %s", err)
+		}
+
+		return chunkErr
+	}
+
+	if err := out.Sync(); err != nil {
+		return err
+	}
+
+	return out.Close()
+}
+
+// downloadChunk downloads the remaining bytes of a chunk and retries failed range requests.
+func (s *Importer) downloadChunk(ctx context.Context, out *os.File, state *downloadState, chunk *downloadChunk, mirror *downloadMirror, validator string, counter *WriteCounter) error {
+	for attempt := 0; ; attempt++ {
+		err := s.downloadChunkRange(ctx, out, state, chunk, mirror, validator, counter)
+		if err == nil {
+			return nil
+		}
+
+		if ctx.Err() != nil || attempt >= s.downloadMaxRetries {
+			return err
+		}
+
+		s.downloadMetrics.Retries.Inc()
+		s.LogDebugf("range request to %s failed, retrying: %s", mirror.url, err)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(downloadRetryDelay):
+		}
+	}
+}
+
+// downloadChunkRange requests the remaining bytes of a chunk and writes them to the partial file.
+func (s *Importer) downloadChunkRange(ctx context.Context, out *os.File, state *downloadState, chunk *downloadChunk, mirror *downloadMirror, validator string, counter *WriteCounter) error {
+	offset := state.chunkOffset(chunk)
+
+	resp, err := requestRange(ctx, mirror.url, mirror.token, offset, chunk.End-1, validator)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		// a full response to a range request with a validator means that the file was changed on the server
+		return fmt.Errorf("range request failed, server returned status code %d", resp.StatusCode)
+	}
+
+	start, size, err := parseContentRange(resp.Header.Get("Content-Range"))
+	if err != nil {
+		return err
+	}
+	if start != offset || size != state.Size {
+		return fmt.Errorf("range request failed, server returned wrong range: %s", resp.Header.Get("Content-Range"))
+	}
+
+	if _, err := io.Copy(&chunkWriter{
+		file:    out,
+		state:   state,
+		chunk:   chunk,
+		counter: counter,
+	}, io.LimitReader(resp.Body, chunk.End-offset)); err != nil {
+		return err
+	}
+
+	if state.chunkOffset(chunk) < chunk.End {
+		return errors.Wrap(io.ErrUnexpectedEOF, "range request failed")
+	}
+
+	return nil
+}
+
+// downloadFileStream downloads a file in a single stream into the partial file.
+func (s *Importer) downloadFileStream(ctx context.Context, partialFilePath string, stateFilePath string, body io.Reader, contentLength int64) error {
+
+	// a partial file can't be resumed without range requests
+	_ = os.Remove(stateFilePath)
+
+	out, err := os.Create(partialFilePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = out.Close() }()
+
+	var expected uint64
+	if contentLength > 0 {
+		expected = uint64(contentLength)
+	}
+
+	// create our progress reporter and pass it to be used alongside our writer
+	counter := NewWriteCounter(ctx, expected, 0, s.downloadMetrics)
+	if _, err = io.Copy(out, io.TeeReader(body, counter)); err != nil {
+		return err
+	}
+
+	// the progress indicator uses the same line so print a new line once it's finished downloading
+	fmt.Print("\n")
+
+	if err := out.Sync(); err != nil {
+		return err
+	}
+
+	return out.Close()
+}
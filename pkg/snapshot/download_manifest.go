@@ -0,0 +1,128 @@
+package snapshot
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// the maximum size of a hash manifest, manifests only contain a few lines.
+	maxHashManifestSize = 1 << 20
+)
+
+var (
+	// ErrSnapshotHashMismatch is returned when a downloaded snapshot file does not match the hash in the manifest.
+	ErrSnapshotHashMismatch = errors.New("snapshot file hash does not match the hash manifest")
+	// ErrSnapshotHashNotInManifest is returned when the hash manifest contains no hash for a snapshot file.
+	ErrSnapshotHashNotInManifest = errors.New("snapshot file not found in the hash manifest")
+)
+
+// hashManifest maps file names to the hex encoded SHA-256 hashes of the files.
+type hashManifest map[string]string
+
+// parseHashManifest parses a hash manifest in the format of "sha256sum" ("<hex hash>  <file name>" per line).
+func parseHashManifest(reader io.Reader) (hashManifest, error) {
+	manifest := make(hashManifest)
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid hash manifest line: %s", line)
+		}
+
+		hash, fileName := strings.ToLower(fields[0]), strings.TrimPrefix(fields[1], "*")
+		if hashBytes, err := hex.DecodeString(hash); err != nil || len(hashBytes) != sha256.Size {
+			return nil, fmt.Errorf("invalid SHA-256 hash in hash manifest: %s", hash)
+		}
+
+		manifest[path.Base(fileName)] = hash
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// hashForURL returns the hash of the file the given URL points to.
+func (m hashManifest) hashForURL(fileURL string) (string, error) {
+	parsedURL, err := url.Parse(fileURL)
+	if err != nil {
+		return "", err
+	}
+
+	fileName := path.Base(parsedURL.Path)
+
+	hash, exists := m[fileName]
+	if !exists {
+		return "", errors.Wrap(ErrSnapshotHashNotInManifest, fileName)
+	}
+
+	return hash, nil
+}
+
+// downloadHashManifest downloads the hash manifest from the given url.
+func downloadHashManifest(ctx context.Context, manifestURL string, token string) (hashManifest, error) {
+	ctxManifest, cancelManifest := context.WithTimeout(ctx, timeoutDownloadSnapshotHeader)
+	defer cancelManifest()
+
+	req, err := newDownloadRequest(ctxManifest, manifestURL, token)
+	if err != nil {
+		return nil, fmt.Errorf("download of hash manifest failed: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download of hash manifest failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download of hash manifest failed, server returned status code %d", resp.StatusCode)
+	}
+
+	manifest, err := parseHashManifest(io.LimitReader(resp.Body, maxHashManifestSize))
+	if err != nil {
+		return nil, fmt.Errorf("parsing hash manifest failed: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// verifyFileHash checks that the SHA-256 hash of the file at the given path matches the expected hex encoded hash.
+func verifyFileHash(filePath string, expectedHash string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return err
+	}
+
+	if actualHash := hex.EncodeToString(hasher.Sum(nil)); actualHash != expectedHash {
+		return errors.Wrapf(ErrSnapshotHashMismatch, "expected: %s, actual: %s", expectedHash, actualHash)
+	}
+
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hornet/v2/pkg/metrics"
 	"github.com/iotaledger/hornet/v2/pkg/model/storage"
 	iotago "github.com/iotaledger/iota.go/v3"
 )
@@ -24,6 +25,11 @@ type Importer struct {
 	snapshotDeltaPath string
 	targetNetworkName string
 	downloadTargets   []*DownloadTarget
+	// the amount of parallel range requests per snapshot file.
+	downloadParallelism int
+	// the amount of retries of a failed range request before the download fails over to the next mirror.
+	downloadMaxRetries int
+	downloadMetrics    *metrics.SnapshotDownloadMetrics
 }
 
 // NewSnapshotImporter creates a new snapshot manager instance.
@@ -33,15 +39,29 @@ func NewSnapshotImporter(
 	snapshotFullPath string,
 	snapshotDeltaPath string,
 	targetNetworkName string,
-	downloadTargets []*DownloadTarget) *Importer {
+	downloadTargets []*DownloadTarget,
+	downloadParallelism int,
+	downloadMaxRetries int,
+	downloadMetrics *metrics.SnapshotDownloadMetrics) *Importer {
+
+	if downloadParallelism < 1 {
+		downloadParallelism = 1
+	}
+
+	if downloadMetrics == nil {
+		downloadMetrics = &metrics.SnapshotDownloadMetrics{}
+	}
 
 	return &Importer{
-		WrappedLogger:     logger.NewWrappedLogger(log),
-		storage:           storage,
-		snapshotFullPath:  snapshotFullPath,
-		snapshotDeltaPath: snapshotDeltaPath,
-		targetNetworkName: targetNetworkName,
-		downloadTargets:   downloadTargets,
+		WrappedLogger:       logger.NewWrappedLogger(log),
+		storage:             storage,
+		snapshotFullPath:    snapshotFullPath,
+		snapshotDeltaPath:   snapshotDeltaPath,
+		targetNetworkName:   targetNetworkName,
+		downloadTargets:     downloadTargets,
+		downloadParallelism: downloadParallelism,
+		downloadMaxRetries:  downloadMaxRetries,
+		downloadMetrics:     downloadMetrics,
 	}
 }
 
@@ -154,10 +174,32 @@ func (s *Importer) downloadSnapshotFiles(ctx context.Context, targetNetworkID ui
 	return nil
 }
 
+// verifySnapshotFileIntegrity verifies the sections of the snapshot file against its integrity trailer before it is imported.
+func (s *Importer) verifySnapshotFileIntegrity(snapshotName string, filePath string) error {
+	verified, err := VerifySnapshotFileIntegrity(filePath)
+	if err != nil {
+		return fmt.Errorf("verifying integrity of %s snapshot file failed: %w", snapshotName, err)
+	}
+
+	if !verified {
+		s.LogWarnf("%s snapshot file has no integrity trailer, skipping integrity check", snapshotName)
+
+		return nil
+	}
+
+	s.LogInfof("verified integrity of %s snapshot file", snapshotName)
+
+	return nil
+}
+
 // LoadFullSnapshotFromFile loads a snapshot file from the given file path into the storage.
 func (s *Importer) LoadFullSnapshotFromFile(ctx context.Context, filePath string, targetNetworkID iotago.NetworkID) (err error) {
 	snapshotName := snapshotNames[Full]
 
+	if err := s.verifySnapshotFileIntegrity(snapshotName, filePath); err != nil {
+		return err
+	}
+
 	s.LogInfof("importing %s snapshot file ...", snapshotName)
 	ts := time.Now()
 
@@ -196,6 +238,10 @@ SnapshotInfo:
 func (s *Importer) LoadDeltaSnapshotFromFile(ctx context.Context, filePath string) (err error) {
 	snapshotName := snapshotNames[Delta]
 
+	if err := s.verifySnapshotFileIntegrity(snapshotName, filePath); err != nil {
+		return err
+	}
+
 	s.LogInfof("importing %s snapshot file ...", snapshotName)
 	ts := time.Now()
 
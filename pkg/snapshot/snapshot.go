@@ -60,12 +60,19 @@ type Manager struct {
 	snapshotCreationEnabled                bool
 	snapshotFullPath                       string
 	snapshotDeltaPath                      string
+	snapshotCompression                    bool
 	deltaSnapshotSizeThresholdPercentage   float64
 	deltaSnapshotSizeThresholdMinSizeBytes int64
 	solidEntryPointCheckThresholdPast      syncmanager.MilestoneIndexDelta
 	solidEntryPointCheckThresholdFuture    syncmanager.MilestoneIndexDelta
 	snapshotDepth                          syncmanager.MilestoneIndexDelta
 	snapshotInterval                       syncmanager.MilestoneIndexDelta
+	// optional history that keeps older snapshot files.
+	history *History
+
+	// the amount of delta snapshot files that are currently served to other nodes.
+	servedFilesLock  syncutils.Mutex
+	servedDeltaFiles int
 
 	snapshotLock         syncutils.Mutex
 	statusLock           syncutils.RWMutex
@@ -83,12 +90,14 @@ func NewSnapshotManager(
 	snapshotCreationEnabled bool,
 	snapshotFullPath string,
 	snapshotDeltaPath string,
+	snapshotCompression bool,
 	deltaSnapshotSizeThresholdPercentage float64,
 	deltaSnapshotSizeThresholdMinSizeBytes int64,
 	solidEntryPointCheckThresholdPast syncmanager.MilestoneIndexDelta,
 	solidEntryPointCheckThresholdFuture syncmanager.MilestoneIndexDelta,
 	snapshotDepth syncmanager.MilestoneIndexDelta,
 	snapshotInterval iotago.MilestoneIndex,
+	history *History,
 ) *Manager {
 
 	return &Manager{
@@ -99,12 +108,14 @@ func NewSnapshotManager(
 		snapshotCreationEnabled:                snapshotCreationEnabled,
 		snapshotFullPath:                       snapshotFullPath,
 		snapshotDeltaPath:                      snapshotDeltaPath,
+		snapshotCompression:                    snapshotCompression,
 		deltaSnapshotSizeThresholdPercentage:   deltaSnapshotSizeThresholdPercentage,
 		deltaSnapshotSizeThresholdMinSizeBytes: deltaSnapshotSizeThresholdMinSizeBytes,
 		solidEntryPointCheckThresholdPast:      solidEntryPointCheckThresholdPast,
 		solidEntryPointCheckThresholdFuture:    solidEntryPointCheckThresholdFuture,
 		snapshotDepth:                          snapshotDepth,
 		snapshotInterval:                       snapshotInterval,
+		history:                                history,
 		Events:                                 newEvents(),
 	}
 }
@@ -316,11 +327,22 @@ func (s *Manager) HandleNewConfirmedMilestoneEvent(ctx context.Context, confirme
 			}
 			s.LogWarnf("%s: %s", ErrSnapshotCreationFailed, err)
 		}
+
+		if err == nil && s.history != nil {
+			if _, err := s.history.Add(snapshotType, s.snapshotTypeFilePath(snapshotType)); err != nil {
+				s.LogWarnf("adding %s snapshot file to the snapshot history failed: %s", snapshotNames[snapshotType], err)
+			}
+		}
 	}
 
 	s.Events.HandledConfirmedMilestoneIndexChanged.Trigger(confirmedMilestoneIndex)
 }
 
+// History returns the snapshot history or nil if it is disabled.
+func (s *Manager) History() *History {
+	return s.history
+}
+
 func FormatSnapshotTimestamp(timestamp uint32) string {
 	result := "unknown"
 	if timestamp != 0 {
@@ -0,0 +1,551 @@
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/runtime/ioutils"
+	"github.com/iotaledger/hornet/v2/pkg/common"
+)
+
+// Compressed snapshot files are stored in the zstd seekable format.
+// The snapshot file is split into independent zstd frames, followed by a seek table in a skippable frame.
+// The header of the snapshot file is always stored in its own frame,
+// so it can be read without decompressing the whole file, e.g. while the file is downloaded.
+// The seek table allows to seek in the decompressed data by only decompressing a single frame.
+//
+// Seek Table:
+//	Skippable Magic Number	uint32	0x184D2A5E
+//	Frame Size	uint32	the size of the seek table entries and the footer.
+//	Entries	oneOf
+//		Compressed Size	uint32	the size of the compressed frame.
+//		Decompressed Size	uint32	the size of the decompressed frame.
+//	Number Of Frames	uint32
+//	Seek Table Descriptor	uint8	always 0, no checksums are stored.
+//	Seekable Magic Number	uint32	0x8F92EAB1
+
+const (
+	// the magic number at the start of every zstd frame.
+	zstdFrameMagic uint32 = 0xFD2FB528
+	// the magic number of the skippable frame that contains the seek table.
+	zstdSkippableFrameMagic uint32 = 0x184D2A5E
+	// the magic number at the end of the seek table.
+	zstdSeekableMagic uint32 = 0x8F92EAB1
+
+	// the size of the skippable frame header.
+	zstdSkippableFrameHeaderLength = 4 + 4
+	// the size of a seek table entry.
+	zstdSeekTableEntryLength = 4 + 4
+	// the size of the seek table footer.
+	zstdSeekTableFooterLength = 4 + 1 + 4
+
+	// compressedFrameSize is the amount of snapshot data that is compressed into a single frame.
+	compressedFrameSize = 16 * 1024 * 1024
+)
+
+var (
+	// ErrCompressedSnapshotNotSeekable is returned if a compressed snapshot file does not contain a seek table.
+	ErrCompressedSnapshotNotSeekable = errors.New("compressed snapshot file does not contain a seek table")
+)
+
+// compressedFrame is an entry of the seek table of a compressed snapshot file.
+type compressedFrame struct {
+	compressedOffset   int64
+	compressedSize     int64
+	decompressedOffset int64
+	decompressedSize   int64
+}
+
+// isCompressedSnapshot checks if the given data starts with a zstd frame.
+func isCompressedSnapshot(data []byte) bool {
+	return len(data) >= 4 && binary.LittleEndian.Uint32(data) == zstdFrameMagic
+}
+
+// IsCompressedSnapshotFile checks if the given snapshot file is compressed.
+func IsCompressedSnapshotFile(filePath string) (bool, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return false, fmt.Errorf("unable to open snapshot file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(file, magic); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("unable to read snapshot file: %w", err)
+	}
+
+	return isCompressedSnapshot(magic), nil
+}
+
+// NewSnapshotStreamReader returns a reader that decompresses the given snapshot data if it is compressed.
+// The data is decompressed on the fly, so only the consumed part of the snapshot is decompressed.
+// This is used to read snapshot headers from streams that can't be seeked, e.g. downloads.
+func NewSnapshotStreamReader(reader io.Reader) (io.ReadCloser, error) {
+	bufferedReader := bufio.NewReader(reader)
+
+	magic, err := bufferedReader.Peek(4)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("unable to read snapshot data: %w", err)
+	}
+
+	if !isCompressedSnapshot(magic) {
+		return io.NopCloser(bufferedReader), nil
+	}
+
+	decoder, err := zstd.NewReader(bufferedReader, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create zstd decoder: %w", err)
+	}
+
+	return decoder.IOReadCloser(), nil
+}
+
+// compressedSnapshotReader decompresses a compressed snapshot file and allows to seek in the decompressed data.
+type compressedSnapshotReader struct {
+	file    *os.File
+	decoder *zstd.Decoder
+	frames  []*compressedFrame
+	// the total size of the decompressed data.
+	size int64
+	// the current position in the decompressed data.
+	position int64
+}
+
+func newCompressedSnapshotReader(file *os.File) (*compressedSnapshotReader, error) {
+	frames, err := readSeekTable(file)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create zstd decoder: %w", err)
+	}
+
+	reader := &compressedSnapshotReader{
+		file:    file,
+		decoder: decoder,
+		frames:  frames,
+	}
+
+	if len(frames) > 0 {
+		lastFrame := frames[len(frames)-1]
+		reader.size = lastFrame.decompressedOffset + lastFrame.decompressedSize
+	}
+
+	if err := reader.resetToFrame(0); err != nil {
+		decoder.Close()
+
+		return nil, err
+	}
+
+	return reader, nil
+}
+
+// resetToFrame starts decoding at the beginning of the frame with the given index.
+func (r *compressedSnapshotReader) resetToFrame(index int) error {
+	if index >= len(r.frames) {
+		// the end of the data was reached
+		r.position = r.size
+
+		return r.decoder.Reset(bytes.NewReader(nil))
+	}
+
+	frame := r.frames[index]
+	lastFrame := r.frames[len(r.frames)-1]
+
+	// the decoder continues with the following frames, but stops before the seek table
+	compressedEnd := lastFrame.compressedOffset + lastFrame.compressedSize
+	if err := r.decoder.Reset(bufio.NewReader(io.NewSectionReader(r.file, frame.compressedOffset, compressedEnd-frame.compressedOffset))); err != nil {
+		return fmt.Errorf("unable to reset zstd decoder: %w", err)
+	}
+
+	r.position = frame.decompressedOffset
+
+	return nil
+}
+
+func (r *compressedSnapshotReader) Read(p []byte) (int, error) {
+	n, err := r.decoder.Read(p)
+	r.position += int64(n)
+
+	return n, err
+}
+
+func (r *compressedSnapshotReader) Seek(offset int64, whence int) (int64, error) {
+	var target int64
+	switch whence {
+	case io.SeekStart:
+		target = offset
+	case io.SeekCurrent:
+		target = r.position + offset
+	case io.SeekEnd:
+		target = r.size + offset
+	default:
+		return 0, fmt.Errorf("invalid whence: %d", whence)
+	}
+
+	if target < 0 || target > r.size {
+		return 0, fmt.Errorf("unable to seek to position %d of compressed snapshot with size %d", target, r.size)
+	}
+
+	// search the frame that contains the target position
+	frameIndex := sort.Search(len(r.frames), func(i int) bool {
+		return r.frames[i].decompressedOffset+r.frames[i].decompressedSize > target
+	})
+
+	// the start of the frame that contains the target position
+	frameStart := r.size
+	if frameIndex < len(r.frames) {
+		frameStart = r.frames[frameIndex].decompressedOffset
+	}
+
+	// the decoder only needs to be reset if the target is before the current position or in one of the following frames,
+	// otherwise the remaining data up to the target position in the current frame is skipped.
+	if target < r.position || frameStart > r.position {
+		if err := r.resetToFrame(frameIndex); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := io.CopyN(io.Discard, r, target-r.position); err != nil {
+		return 0, fmt.Errorf("unable to seek in compressed snapshot: %w", err)
+	}
+
+	return r.position, nil
+}
+
+func (r *compressedSnapshotReader) Close() error {
+	r.decoder.Close()
+
+	return r.file.Close()
+}
+
+// readSeekTable reads the seek table at the end of a compressed snapshot file.
+func readSeekTable(readSeeker io.ReadSeeker) ([]*compressedFrame, error) {
+
+	fileSize, err := readSeeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("unable to seek to the end of the compressed snapshot: %w", err)
+	}
+
+	if fileSize < zstdSkippableFrameHeaderLength+zstdSeekTableFooterLength {
+		return nil, ErrCompressedSnapshotNotSeekable
+	}
+
+	if _, err := readSeeker.Seek(fileSize-zstdSeekTableFooterLength, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("unable to seek to the seek table footer: %w", err)
+	}
+
+	var framesCount uint32
+	var descriptor uint8
+	var seekableMagic uint32
+	for _, value := range []any{&framesCount, &descriptor, &seekableMagic} {
+		if err := binary.Read(readSeeker, binary.LittleEndian, value); err != nil {
+			return nil, fmt.Errorf("unable to read the seek table footer: %w", err)
+		}
+	}
+
+	if seekableMagic != zstdSeekableMagic {
+		return nil, ErrCompressedSnapshotNotSeekable
+	}
+
+	if descriptor != 0 {
+		return nil, fmt.Errorf("unsupported seek table descriptor: %d", descriptor)
+	}
+
+	seekTableLength := zstdSkippableFrameHeaderLength + int64(framesCount)*zstdSeekTableEntryLength + zstdSeekTableFooterLength
+	if fileSize < seekTableLength {
+		return nil, errors.Wrap(common.ErrCritical, "compressed snapshot file is too small to contain the seek table")
+	}
+
+	if _, err := readSeeker.Seek(fileSize-seekTableLength, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("unable to seek to the seek table: %w", err)
+	}
+
+	var skippableMagic uint32
+	var skippableFrameSize uint32
+	for _, value := range []any{&skippableMagic, &skippableFrameSize} {
+		if err := binary.Read(readSeeker, binary.LittleEndian, value); err != nil {
+			return nil, fmt.Errorf("unable to read the seek table header: %w", err)
+		}
+	}
+
+	if skippableMagic != zstdSkippableFrameMagic || int64(skippableFrameSize) != seekTableLength-zstdSkippableFrameHeaderLength {
+		return nil, ErrCompressedSnapshotNotSeekable
+	}
+
+	frames := make([]*compressedFrame, framesCount)
+
+	var compressedOffset, decompressedOffset int64
+	for i := range frames {
+		var compressedSize, decompressedSize uint32
+		if err := binary.Read(readSeeker, binary.LittleEndian, &compressedSize); err != nil {
+			return nil, fmt.Errorf("unable to read the seek table: %w", err)
+		}
+		if err := binary.Read(readSeeker, binary.LittleEndian, &decompressedSize); err != nil {
+			return nil, fmt.Errorf("unable to read the seek table: %w", err)
+		}
+
+		frames[i] = &compressedFrame{
+			compressedOffset:   compressedOffset,
+			compressedSize:     int64(compressedSize),
+			decompressedOffset: decompressedOffset,
+			decompressedSize:   int64(decompressedSize),
+		}
+
+		compressedOffset += int64(compressedSize)
+		decompressedOffset += int64(decompressedSize)
+	}
+
+	if compressedOffset != fileSize-seekTableLength {
+		return nil, errors.Wrap(common.ErrCritical, "seek table of the compressed snapshot file does not match the file size")
+	}
+
+	return frames, nil
+}
+
+// writeSeekTable writes the seek table for the given frames.
+func writeSeekTable(writer io.Writer, frames []*compressedFrame) error {
+
+	var seekTable bytes.Buffer
+	for _, frame := range frames {
+		if err := writeFunc(&seekTable, "seek table compressed size", uint32(frame.compressedSize)); err != nil {
+			return err
+		}
+		if err := writeFunc(&seekTable, "seek table decompressed size", uint32(frame.decompressedSize)); err != nil {
+			return err
+		}
+	}
+
+	if err := writeFunc(&seekTable, "seek table number of frames", uint32(len(frames))); err != nil {
+		return err
+	}
+	if err := writeFunc(&seekTable, "seek table descriptor", uint8(0)); err != nil {
+		return err
+	}
+	if err := writeFunc(&seekTable, "seekable magic number", zstdSeekableMagic); err != nil {
+		return err
+	}
+
+	if err := writeFunc(writer, "skippable frame magic number", zstdSkippableFrameMagic); err != nil {
+		return err
+	}
+	if err := writeFunc(writer, "skippable frame size", uint32(seekTable.Len())); err != nil {
+		return err
+	}
+
+	if _, err := writer.Write(seekTable.Bytes()); err != nil {
+		return fmt.Errorf("unable to write LS seek table: %w", err)
+	}
+
+	return nil
+}
+
+// snapshotHeaderLength returns the length of the header of the given uncompressed snapshot.
+func snapshotHeaderLength(readSeeker io.ReadSeeker) (int64, error) {
+
+	snapshotType, err := ReadSnapshotType(readSeeker)
+	if err != nil {
+		return 0, err
+	}
+
+	switch snapshotType {
+	case Full:
+		_, err = ReadFullSnapshotHeader(readSeeker)
+	case Delta:
+		_, err = ReadDeltaSnapshotHeader(readSeeker)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// the header is read without buffering, so the current position is the end of the header
+	headerLength, err := readSeeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := readSeeker.Seek(0, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("unable to seek to the start of the snapshot header: %w", err)
+	}
+
+	return headerLength, nil
+}
+
+// CompressSnapshot compresses the given uncompressed snapshot into the seekable zstd format.
+func CompressSnapshot(readSeeker io.ReadSeeker, writer io.Writer) error {
+
+	headerLength, err := snapshotHeaderLength(readSeeker)
+	if err != nil {
+		return err
+	}
+
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return fmt.Errorf("unable to create zstd encoder: %w", err)
+	}
+	defer func() { _ = encoder.Close() }()
+
+	var frames []*compressedFrame
+	var compressedOffset, decompressedOffset int64
+
+	buffer := make([]byte, compressedFrameSize)
+	var compressed []byte
+
+	// the header is stored in its own frame
+	frameSize := headerLength
+	for {
+		n, err := io.ReadFull(readSeeker, buffer[:frameSize])
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("unable to read snapshot data: %w", err)
+		}
+
+		if n == 0 {
+			break
+		}
+
+		compressed = encoder.EncodeAll(buffer[:n], compressed[:0])
+		if _, err := writer.Write(compressed); err != nil {
+			return fmt.Errorf("unable to write compressed snapshot data: %w", err)
+		}
+
+		frames = append(frames, &compressedFrame{
+			compressedOffset:   compressedOffset,
+			compressedSize:     int64(len(compressed)),
+			decompressedOffset: decompressedOffset,
+			decompressedSize:   int64(n),
+		})
+		compressedOffset += int64(len(compressed))
+		decompressedOffset += int64(n)
+
+		frameSize = compressedFrameSize
+	}
+
+	return writeSeekTable(writer, frames)
+}
+
+// CompressSnapshotFile compresses the given uncompressed snapshot file into the target file path.
+func CompressSnapshotFile(filePath string, targetFilePath string) (err error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("unable to open snapshot file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	targetFile, err := os.OpenFile(targetFilePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return fmt.Errorf("unable to create compressed snapshot file: %w", err)
+	}
+	defer func() {
+		if errClose := targetFile.Close(); err == nil && errClose != nil {
+			err = errClose
+		}
+	}()
+
+	bufferedWriter := bufio.NewWriter(targetFile)
+	if err := CompressSnapshot(file, bufferedWriter); err != nil {
+		return err
+	}
+
+	return bufferedWriter.Flush()
+}
+
+// DecompressSnapshotFile decompresses the given compressed snapshot file into the target file path.
+func DecompressSnapshotFile(filePath string, targetFilePath string) (err error) {
+	reader, err := OpenSnapshotFile(filePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = reader.Close() }()
+
+	targetFile, err := os.OpenFile(targetFilePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return fmt.Errorf("unable to create decompressed snapshot file: %w", err)
+	}
+	defer func() {
+		if errClose := targetFile.Close(); err == nil && errClose != nil {
+			err = errClose
+		}
+	}()
+
+	if _, err := io.Copy(targetFile, reader); err != nil {
+		return fmt.Errorf("unable to decompress snapshot file: %w", err)
+	}
+
+	return nil
+}
+
+// closeAndFinalizeSnapshotFile closes the written snapshot file and moves it to the target file path.
+// If compress is true, the snapshot file is compressed instead of renamed.
+func closeAndFinalizeSnapshotFile(snapshotFile *os.File, tempFilePath string, filePath string, compress bool) error {
+	if !compress {
+		return ioutils.CloseFileAndRename(snapshotFile, tempFilePath, filePath)
+	}
+
+	if err := snapshotFile.Close(); err != nil {
+		return fmt.Errorf("unable to close file: %w", err)
+	}
+	defer func() { _ = os.Remove(tempFilePath) }()
+
+	compressedTempFilePath := tempFilePath + "_compressed"
+	if err := CompressSnapshotFile(tempFilePath, compressedTempFilePath); err != nil {
+		_ = os.Remove(compressedTempFilePath)
+
+		return fmt.Errorf("unable to compress snapshot file: %w", err)
+	}
+
+	if err := os.Rename(compressedTempFilePath, filePath); err != nil {
+		return fmt.Errorf("unable to rename file: %w", err)
+	}
+
+	return nil
+}
+
+// OpenSnapshotFile opens the given snapshot file for reading.
+// Compressed snapshot files are detected automatically and decompressed while they are read.
+func OpenSnapshotFile(filePath string) (io.ReadSeekCloser, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	magic := make([]byte, 4)
+	n, err := io.ReadFull(file, magic)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		_ = file.Close()
+
+		return nil, fmt.Errorf("unable to read snapshot file: %w", err)
+	}
+
+	if !isCompressedSnapshot(magic[:n]) {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			_ = file.Close()
+
+			return nil, fmt.Errorf("unable to seek to the start of the snapshot file: %w", err)
+		}
+
+		return file, nil
+	}
+
+	reader, err := newCompressedSnapshotReader(file)
+	if err != nil {
+		_ = file.Close()
+
+		return nil, err
+	}
+
+	return reader, nil
+}
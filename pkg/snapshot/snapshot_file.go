@@ -21,8 +21,12 @@ import (
 )
 
 const (
-	// SupportedFormatVersion defines the supported snapshot file version.
-	SupportedFormatVersion byte = 2
+	// SupportedFormatVersion defines the snapshot file version that is written.
+	SupportedFormatVersion byte = 3
+	// MinSupportedFormatVersion defines the oldest snapshot file version that can still be read.
+	MinSupportedFormatVersion byte = 2
+	// IntegrityFormatVersion defines the first snapshot file version that contains an integrity trailer.
+	IntegrityFormatVersion byte = 3
 )
 
 var (
@@ -71,19 +75,24 @@ type ReadWriteTruncateSeeker interface {
 	Truncate(size int64) error
 }
 
+// isSupportedFormatVersion returns true if snapshot files of the given version can be read.
+func isSupportedFormatVersion(version byte) bool {
+	return version >= MinSupportedFormatVersion && version <= SupportedFormatVersion
+}
+
 func increaseOffsets(amount int64, offsets ...*int64) {
 	for _, offset := range offsets {
 		*offset += amount
 	}
 }
 
-func writeFunc(writeSeeker io.WriteSeeker, variableName string, value any, offsetsToIncrease ...*int64) error {
+func writeFunc(writer io.Writer, variableName string, value any, offsetsToIncrease ...*int64) error {
 	length := binary.Size(value)
 	if length == -1 {
 		return fmt.Errorf("unable to determine length of %s", variableName)
 	}
 
-	if err := binary.Write(writeSeeker, binary.LittleEndian, value); err != nil {
+	if err := binary.Write(writer, binary.LittleEndian, value); err != nil {
 		return fmt.Errorf("unable to write LS %s: %w", variableName, err)
 	}
 
@@ -386,93 +395,95 @@ func (h *FullSnapshotHeader) ProtocolParameters() (*iotago.ProtocolParameters, e
 	return protoParams, nil
 }
 
-func writeFullSnapshotHeader(writeSeeker io.WriteSeeker, header *FullSnapshotHeader) (int64, error) {
+func writeFullSnapshotHeader(writer io.Writer, header *FullSnapshotHeader) (int64, int64, error) {
 
 	if header.Type != Full {
-		return 0, ErrWrongSnapshotType
+		return 0, 0, ErrWrongSnapshotType
 	}
 	if header.ProtocolParamsMilestoneOpt == nil {
-		return 0, iotago.ErrMissingProtocolParas
+		return 0, 0, iotago.ErrMissingProtocolParas
 	}
 	if header.TreasuryOutput == nil {
-		return 0, ErrTreasuryOutputNotProvided
+		return 0, 0, ErrTreasuryOutputNotProvided
 	}
 
 	writeFunc := func(name string, value any, offsetsToIncrease ...*int64) error {
-		return writeFunc(writeSeeker, name, value, offsetsToIncrease...)
+		return writeFunc(writer, name, value, offsetsToIncrease...)
 	}
 
+	// this is the offset of the ProtocolParamsMilestoneOpt field in the header
+	var protoParamsPosition int64
 	// this is the offset of the OutputCount field in the header
 	var countersPosition int64
 
 	// Version
 	// Denotes the version of this file format.
-	if err := writeFunc("version", header.Version, &countersPosition); err != nil {
-		return 0, err
+	if err := writeFunc("version", header.Version, &protoParamsPosition, &countersPosition); err != nil {
+		return 0, 0, err
 	}
 
 	// Type
 	// Denotes the type of this file format. Value 0 denotes a full snapshot.
-	if err := writeFunc("type", Full, &countersPosition); err != nil {
-		return 0, err
+	if err := writeFunc("type", Full, &protoParamsPosition, &countersPosition); err != nil {
+		return 0, 0, err
 	}
 
 	// Genesis Milestone Index
 	// The index of the genesis milestone of the network.
-	if err := writeFunc("genesis milestone index", header.GenesisMilestoneIndex, &countersPosition); err != nil {
-		return 0, err
+	if err := writeFunc("genesis milestone index", header.GenesisMilestoneIndex, &protoParamsPosition, &countersPosition); err != nil {
+		return 0, 0, err
 	}
 
 	// Target Milestone Index
 	// The index of the milestone of which the SEPs within the snapshot are from.
-	if err := writeFunc("target milestone index", header.TargetMilestoneIndex, &countersPosition); err != nil {
-		return 0, err
+	if err := writeFunc("target milestone index", header.TargetMilestoneIndex, &protoParamsPosition, &countersPosition); err != nil {
+		return 0, 0, err
 	}
 
 	// Target Milestone Timestamp
 	// The timestamp of the milestone of which the SEPs within the snapshot are from.
-	if err := writeFunc("target milestone timestamp", header.TargetMilestoneTimestamp, &countersPosition); err != nil {
-		return 0, err
+	if err := writeFunc("target milestone timestamp", header.TargetMilestoneTimestamp, &protoParamsPosition, &countersPosition); err != nil {
+		return 0, 0, err
 	}
 
 	// Target Milestone ID
 	// The ID of the milestone of which the SEPs within the snapshot are from.
-	if err := writeFunc("target milestone ID", header.TargetMilestoneID[:], &countersPosition); err != nil {
-		return 0, err
+	if err := writeFunc("target milestone ID", header.TargetMilestoneID[:], &protoParamsPosition, &countersPosition); err != nil {
+		return 0, 0, err
 	}
 
 	// Ledger Milestone Index
 	// The index of the milestone of which the UTXOs within the snapshot are from.
-	if err := writeFunc("ledger milestone index", header.LedgerMilestoneIndex, &countersPosition); err != nil {
-		return 0, err
+	if err := writeFunc("ledger milestone index", header.LedgerMilestoneIndex, &protoParamsPosition, &countersPosition); err != nil {
+		return 0, 0, err
 	}
 
 	// Treasury Output Milestone ID
 	// The milestone ID of the milestone which generated the treasury output.
-	if err := writeFunc("treasury output milestone ID", header.TreasuryOutput.MilestoneID[:], &countersPosition); err != nil {
-		return 0, err
+	if err := writeFunc("treasury output milestone ID", header.TreasuryOutput.MilestoneID[:], &protoParamsPosition, &countersPosition); err != nil {
+		return 0, 0, err
 	}
 
 	// Treasury Output Amount
 	// The amount of funds residing on the treasury output.
-	if err := writeFunc("treasury output amount", header.TreasuryOutput.Amount, &countersPosition); err != nil {
-		return 0, err
+	if err := writeFunc("treasury output amount", header.TreasuryOutput.Amount, &protoParamsPosition, &countersPosition); err != nil {
+		return 0, 0, err
 	}
 
 	// ProtocolParamsMilestoneOpt Length
 	// Denotes the length of the ProtocolParamsMilestoneOpt.
 	protoParamsMsOptionBytes, err := header.ProtocolParamsMilestoneOpt.Serialize(serializer.DeSeriModeNoValidation, nil)
 	if err != nil {
-		return 0, fmt.Errorf("unable to serialize LS protocol parameters milestone option: %w", err)
+		return 0, 0, fmt.Errorf("unable to serialize LS protocol parameters milestone option: %w", err)
 	}
-	if err := writeFunc("protocol parameters milestone option length", uint16(len(protoParamsMsOptionBytes)), &countersPosition); err != nil {
-		return 0, err
+	if err := writeFunc("protocol parameters milestone option length", uint16(len(protoParamsMsOptionBytes)), &protoParamsPosition, &countersPosition); err != nil {
+		return 0, 0, err
 	}
 
 	// ProtocolParamsMilestoneOpt
 	// Active ProtocolParamsMilestoneOpt of the ledger milestone
 	if err := writeFunc("protocol parameters milestone option", protoParamsMsOptionBytes, &countersPosition); err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 
 	var outputCount uint64
@@ -482,22 +493,22 @@ func writeFullSnapshotHeader(writeSeeker io.WriteSeeker, header *FullSnapshotHea
 	// Outputs Count
 	// The amount of UTXOs contained within this snapshot.
 	if err := writeFunc("outputs count", outputCount); err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 
 	// Milestone Diffs Count
 	// The amount of milestone diffs contained within this snapshot.
 	if err := writeFunc("milestone diffs count", msDiffCount); err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 
 	// SEPs Count
 	// The amount of SEPs contained within this snapshot.
 	if err := writeFunc("solid entry points count", sepsCount); err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 
-	return countersPosition, nil
+	return protoParamsPosition, countersPosition, nil
 }
 
 // ReadFullSnapshotHeader reads the full snapshot header from the given reader.
@@ -508,7 +519,7 @@ func ReadFullSnapshotHeader(reader io.Reader) (*FullSnapshotHeader, error) {
 		return nil, fmt.Errorf("unable to read LS version: %w", err)
 	}
 
-	if readHeader.Version != SupportedFormatVersion {
+	if !isSupportedFormatVersion(readHeader.Version) {
 		return nil, ErrUnsupportedSnapshot
 	}
 
@@ -598,13 +609,13 @@ type DeltaSnapshotHeader struct {
 	SEPCount uint16
 }
 
-func writeDeltaSnapshotHeader(writeSeeker io.WriteSeeker, header *DeltaSnapshotHeader) (int64, int64, error) {
+func writeDeltaSnapshotHeader(writer io.Writer, header *DeltaSnapshotHeader) (int64, int64, error) {
 	if header.Type != Delta {
 		return 0, 0, ErrWrongSnapshotType
 	}
 
 	writeFunc := func(name string, value any, offsetsToIncrease ...*int64) error {
-		return writeFunc(writeSeeker, name, value, offsetsToIncrease...)
+		return writeFunc(writer, name, value, offsetsToIncrease...)
 	}
 
 	// this is the offset of the SEPFileOffset field in the header
@@ -674,7 +685,7 @@ func ReadDeltaSnapshotHeader(reader io.Reader) (*DeltaSnapshotHeader, error) {
 		return nil, fmt.Errorf("unable to read LS version: %w", err)
 	}
 
-	if deltaHeader.Version != SupportedFormatVersion {
+	if !isSupportedFormatVersion(deltaHeader.Version) {
 		return nil, ErrUnsupportedSnapshot
 	}
 
@@ -748,15 +759,20 @@ func StreamFullSnapshotDataTo(
 
 	timeStart := time.Now()
 
-	countersPosition, err := writeFullSnapshotHeader(writeSeeker, header)
+	// the header is buffered, so the hash of the header can be computed after the counters were updated
+	var headerBuffer bytes.Buffer
+	protoParamsPosition, countersPosition, err := writeFullSnapshotHeader(&headerBuffer, header)
 	if err != nil {
 		return nil, err
 	}
 
-	writeFunc := func(name string, value any, offsetsToIncrease ...*int64) error {
-		return writeFunc(writeSeeker, name, value, offsetsToIncrease...)
+	if _, err := writeSeeker.Write(headerBuffer.Bytes()); err != nil {
+		return nil, fmt.Errorf("unable to write LS header: %w", err)
 	}
 
+	// every section is hashed while it is written
+	outputsWriter := newIntegritySectionWriter(writeSeeker, IntegritySectionOutputs, int64(headerBuffer.Len()))
+
 	var outputCount uint64
 	var msDiffCount uint32
 	var sepsCount uint16
@@ -775,12 +791,14 @@ func StreamFullSnapshotDataTo(
 		}
 
 		outputCount++
-		if err := writeFunc(fmt.Sprintf("output #%d", outputCount), output.SnapshotBytes()); err != nil {
+		if err := writeFunc(outputsWriter, fmt.Sprintf("output #%d", outputCount), output.SnapshotBytes()); err != nil {
 			return nil, err
 		}
 	}
 	timeOutputs := time.Now()
 
+	msDiffsWriter := newIntegritySectionWriter(writeSeeker, IntegritySectionMilestoneDiffs, outputsWriter.section.Offset+outputsWriter.section.Length)
+
 	// Milestone Diffs
 	for {
 		msDiff, err := msDiffProd()
@@ -797,12 +815,14 @@ func StreamFullSnapshotDataTo(
 		if err != nil {
 			return nil, fmt.Errorf("unable to serialize LS milestone diff #%d: %w", msDiffCount, err)
 		}
-		if err := writeFunc(fmt.Sprintf("milestone diff #%d", msDiffCount), msDiffBytes); err != nil {
+		if err := writeFunc(msDiffsWriter, fmt.Sprintf("milestone diff #%d", msDiffCount), msDiffBytes); err != nil {
 			return nil, err
 		}
 	}
 	timeMilestoneDiffs := time.Now()
 
+	sepsWriter := newIntegritySectionWriter(writeSeeker, IntegritySectionSolidEntryPoints, msDiffsWriter.section.Offset+msDiffsWriter.section.Length)
+
 	// SEPs
 	for {
 		sep, err := sepProd()
@@ -815,35 +835,56 @@ func StreamFullSnapshotDataTo(
 		}
 
 		sepsCount++
-		if err := writeFunc(fmt.Sprintf("SEP #%d", sepsCount), sep[:]); err != nil {
+		if err := writeFunc(sepsWriter, fmt.Sprintf("SEP #%d", sepsCount), sep[:]); err != nil {
 			return nil, err
 		}
 	}
 	timeSolidEntryPoints := time.Now()
 
-	// seek back to the file position of the counters
-	if _, err := writeSeeker.Seek(countersPosition, io.SeekStart); err != nil {
-		return nil, fmt.Errorf("unable to seek to LS counter placeholders: %w", err)
-	}
+	var counters bytes.Buffer
 
 	// Outputs Count
 	// The amount of UTXOs contained within this snapshot.
-	if err := writeFunc("outputs count", outputCount); err != nil {
+	if err := writeFunc(&counters, "outputs count", outputCount); err != nil {
 		return nil, err
 	}
 
 	// Milestone Diffs Count
 	// The amount of milestone diffs contained within this snapshot.
-	if err := writeFunc("milestone diffs count", msDiffCount); err != nil {
+	if err := writeFunc(&counters, "milestone diffs count", msDiffCount); err != nil {
 		return nil, err
 	}
 
 	// SEPs Count
 	// The amount of SEPs contained within this snapshot.
-	if err := writeFunc("solid entry points count", sepsCount); err != nil {
+	if err := writeFunc(&counters, "solid entry points count", sepsCount); err != nil {
 		return nil, err
 	}
 
+	// seek back to the file position of the counters
+	if _, err := writeSeeker.Seek(countersPosition, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("unable to seek to LS counter placeholders: %w", err)
+	}
+
+	if _, err := writeSeeker.Write(counters.Bytes()); err != nil {
+		return nil, fmt.Errorf("unable to write LS counters: %w", err)
+	}
+
+	if header.Version >= IntegrityFormatVersion {
+		headerBytes := headerBuffer.Bytes()
+		copy(headerBytes[countersPosition:], counters.Bytes())
+
+		if err := writeIntegrityTrailer(writeSeeker,
+			integritySectionFromBytes(IntegritySectionHeader, 0, headerBytes),
+			integritySectionFromBytes(IntegritySectionProtocolParameters, protoParamsPosition, headerBytes[protoParamsPosition:countersPosition]),
+			outputsWriter.finalize(),
+			msDiffsWriter.finalize(),
+			sepsWriter.finalize(),
+		); err != nil {
+			return nil, err
+		}
+	}
+
 	// update the values in the header
 	header.OutputCount = outputCount
 	header.MilestoneDiffCount = msDiffCount
@@ -873,15 +914,20 @@ func StreamDeltaSnapshotDataTo(
 
 	timeStart := time.Now()
 
-	sepFileOffsetPosition, sepPosition, err := writeDeltaSnapshotHeader(writeSeeker, header)
+	// the header is buffered, so the hash of the header can be computed after the counters were updated
+	var headerBuffer bytes.Buffer
+	sepFileOffsetPosition, sepPosition, err := writeDeltaSnapshotHeader(&headerBuffer, header)
 	if err != nil {
 		return nil, err
 	}
 
-	writeFunc := func(name string, value any, offsetsToIncrease ...*int64) error {
-		return writeFunc(writeSeeker, name, value, offsetsToIncrease...)
+	if _, err := writeSeeker.Write(headerBuffer.Bytes()); err != nil {
+		return nil, fmt.Errorf("unable to write LS header: %w", err)
 	}
 
+	// every section is hashed while it is written
+	msDiffsWriter := newIntegritySectionWriter(writeSeeker, IntegritySectionMilestoneDiffs, int64(headerBuffer.Len()))
+
 	timeHeader := time.Now()
 
 	var msDiffCount uint32
@@ -903,12 +949,14 @@ func StreamDeltaSnapshotDataTo(
 		if err != nil {
 			return nil, fmt.Errorf("unable to serialize LS milestone diff #%d: %w", msDiffCount, err)
 		}
-		if err := writeFunc(fmt.Sprintf("milestone diff #%d", msDiffCount), msDiffBytes, &sepPosition); err != nil {
+		if err := writeFunc(msDiffsWriter, fmt.Sprintf("milestone diff #%d", msDiffCount), msDiffBytes, &sepPosition); err != nil {
 			return nil, err
 		}
 	}
 	timeMilestoneDiffs := time.Now()
 
+	sepsWriter := newIntegritySectionWriter(writeSeeker, IntegritySectionSolidEntryPoints, sepPosition)
+
 	// SEPs
 	for {
 		sep, err := sepProd()
@@ -921,35 +969,54 @@ func StreamDeltaSnapshotDataTo(
 		}
 
 		sepsCount++
-		if err := writeFunc(fmt.Sprintf("SEP #%d", sepsCount), sep[:]); err != nil {
+		if err := writeFunc(sepsWriter, fmt.Sprintf("SEP #%d", sepsCount), sep[:]); err != nil {
 			return nil, err
 		}
 	}
 	timeSolidEntryPoints := time.Now()
 
-	// seek back to the file position of the SEPFileOffset
-	if _, err := writeSeeker.Seek(sepFileOffsetPosition, io.SeekStart); err != nil {
-		return nil, fmt.Errorf("unable to seek to LS counter placeholders: %w", err)
-	}
+	var counters bytes.Buffer
 
 	// SEP File Offset
 	// The file offset of the SEPs field. This is used to easily update an existing delta snapshot without parsing its content.
-	if err := writeFunc("solid entry points file offset", sepPosition); err != nil {
+	if err := writeFunc(&counters, "solid entry points file offset", sepPosition); err != nil {
 		return nil, err
 	}
 
 	// Milestone Diffs Count
 	// The amount of milestone diffs contained within this snapshot.
-	if err := writeFunc("milestone diffs count", msDiffCount); err != nil {
+	if err := writeFunc(&counters, "milestone diffs count", msDiffCount); err != nil {
 		return nil, err
 	}
 
 	// SEPs Count
 	// The amount of SEPs contained within this snapshot.
-	if err := writeFunc("solid entry points count", sepsCount); err != nil {
+	if err := writeFunc(&counters, "solid entry points count", sepsCount); err != nil {
 		return nil, err
 	}
 
+	// seek back to the file position of the SEPFileOffset
+	if _, err := writeSeeker.Seek(sepFileOffsetPosition, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("unable to seek to LS counter placeholders: %w", err)
+	}
+
+	if _, err := writeSeeker.Write(counters.Bytes()); err != nil {
+		return nil, fmt.Errorf("unable to write LS counters: %w", err)
+	}
+
+	if header.Version >= IntegrityFormatVersion {
+		headerBytes := headerBuffer.Bytes()
+		copy(headerBytes[sepFileOffsetPosition:], counters.Bytes())
+
+		if err := writeIntegrityTrailer(writeSeeker,
+			integritySectionFromBytes(IntegritySectionHeader, 0, headerBytes),
+			msDiffsWriter.finalize(),
+			sepsWriter.finalize(),
+		); err != nil {
+			return nil, err
+		}
+	}
+
 	// update the values in the header
 	header.SEPFileOffset = sepPosition
 	header.MilestoneDiffCount = msDiffCount
@@ -999,8 +1066,15 @@ func StreamDeltaSnapshotDataToExisting(
 
 	timeStart := time.Now()
 
-	writeFunc := func(name string, value any, offsetsToIncrease ...*int64) error {
-		return writeFunc(fileHandle, name, value, offsetsToIncrease...)
+	// the existing milestone diffs are part of the milestone diffs section.
+	// they are verified against the existing integrity trailer before anything is changed,
+	// otherwise the new integrity trailer would cover corrupted milestone diffs.
+	var msDiffsWriter *integritySectionWriter
+	if header.Version >= IntegrityFormatVersion {
+		msDiffsWriter, err = newVerifiedMilestoneDiffsSectionWriter(fileHandle, oldDeltaHeader.SEPFileOffset)
+		if err != nil {
+			return nil, fmt.Errorf("unable to update existing delta snapshot: %w", err)
+		}
 	}
 
 	// this is the current position of the cursor in the file
@@ -1023,13 +1097,13 @@ func StreamDeltaSnapshotDataToExisting(
 
 	// Target Milestone Index
 	// The index of the milestone of which the SEPs within the snapshot are from.
-	if err := writeFunc("target milestone index", header.TargetMilestoneIndex, &cursorPosition, &sepFileOffsetPosition); err != nil {
+	if err := writeFunc(fileHandle, "target milestone index", header.TargetMilestoneIndex, &cursorPosition, &sepFileOffsetPosition); err != nil {
 		return nil, err
 	}
 
 	// Target Milestone Timestamp
 	// The timestamp of the milestone of which the SEPs within the snapshot are from.
-	if err := writeFunc("target milestone timestamp", header.TargetMilestoneTimestamp, &cursorPosition, &sepFileOffsetPosition); err != nil {
+	if err := writeFunc(fileHandle, "target milestone timestamp", header.TargetMilestoneTimestamp, &cursorPosition, &sepFileOffsetPosition); err != nil {
 		return nil, err
 	}
 
@@ -1039,18 +1113,26 @@ func StreamDeltaSnapshotDataToExisting(
 
 	timeHeader := time.Now()
 
+	// SEP File Offset, Milestone Diffs Count and SEPs Count
+	headerLength := sepFileOffsetPosition + serializer.UInt64ByteSize + serializer.UInt32ByteSize + serializer.UInt16ByteSize
+
 	// this is the offset of the first SEP in the snapshot file
 	sepPosition := oldDeltaHeader.SEPFileOffset
 
 	msDiffCount := oldDeltaHeader.MilestoneDiffCount
 	var sepsCount uint16
 
+	if msDiffsWriter == nil {
+		// older snapshot files do not contain an integrity trailer
+		msDiffsWriter = newIntegritySectionWriter(fileHandle, IntegritySectionMilestoneDiffs, headerLength)
+	}
+
 	// Seek to the position of the solid entry points file offset
 	if _, err := fileHandle.Seek(oldDeltaHeader.SEPFileOffset, io.SeekStart); err != nil {
 		return nil, fmt.Errorf("unable to seek to solid entry points file offset: %w", err)
 	}
 
-	// Truncate the old SEPs
+	// Truncate the old SEPs and the old integrity trailer
 	if err := fileHandle.Truncate(oldDeltaHeader.SEPFileOffset); err != nil {
 		return nil, fmt.Errorf("unable to truncate old solid entry points: %w", err)
 	}
@@ -1075,12 +1157,14 @@ func StreamDeltaSnapshotDataToExisting(
 		if err != nil {
 			return nil, fmt.Errorf("unable to serialize LS milestone diff #%d: %w", msDiffCount, err)
 		}
-		if err := writeFunc(fmt.Sprintf("milestone diff #%d", msDiffCount), msDiffBytes, &sepPosition); err != nil {
+		if err := writeFunc(msDiffsWriter, fmt.Sprintf("milestone diff #%d", msDiffCount), msDiffBytes, &sepPosition); err != nil {
 			return nil, err
 		}
 	}
 	timeMilestoneDiffs := time.Now()
 
+	sepsWriter := newIntegritySectionWriter(fileHandle, IntegritySectionSolidEntryPoints, sepPosition)
+
 	// SEPs
 	for {
 		sep, err := sepProd()
@@ -1093,7 +1177,7 @@ func StreamDeltaSnapshotDataToExisting(
 		}
 
 		sepsCount++
-		if err := writeFunc(fmt.Sprintf("SEP #%d", sepsCount), sep[:]); err != nil {
+		if err := writeFunc(sepsWriter, fmt.Sprintf("SEP #%d", sepsCount), sep[:]); err != nil {
 			return nil, err
 		}
 	}
@@ -1106,22 +1190,42 @@ func StreamDeltaSnapshotDataToExisting(
 
 	// SEP File Offset
 	// The file offset of the SEPs field. This is used to easily update an existing delta snapshot without parsing its content.
-	if err := writeFunc("solid entry points file offset", sepPosition); err != nil {
+	if err := writeFunc(fileHandle, "solid entry points file offset", sepPosition); err != nil {
 		return nil, err
 	}
 
 	// Milestone Diffs Count
 	// The amount of milestone diffs contained within this snapshot.
-	if err := writeFunc("milestone diffs count", msDiffCount); err != nil {
+	if err := writeFunc(fileHandle, "milestone diffs count", msDiffCount); err != nil {
 		return nil, err
 	}
 
 	// SEPs Count
 	// The amount of SEPs contained within this snapshot.
-	if err := writeFunc("solid entry points count", sepsCount); err != nil {
+	if err := writeFunc(fileHandle, "solid entry points count", sepsCount); err != nil {
 		return nil, err
 	}
 
+	if header.Version >= IntegrityFormatVersion {
+		// read back the updated header
+		if _, err := fileHandle.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("unable to seek to start of delta snapshot header: %w", err)
+		}
+
+		headerBytes := make([]byte, headerLength)
+		if _, err := io.ReadFull(fileHandle, headerBytes); err != nil {
+			return nil, fmt.Errorf("unable to read updated delta snapshot header: %w", err)
+		}
+
+		if err := writeIntegrityTrailer(fileHandle,
+			integritySectionFromBytes(IntegritySectionHeader, 0, headerBytes),
+			msDiffsWriter.finalize(),
+			sepsWriter.finalize(),
+		); err != nil {
+			return nil, err
+		}
+	}
+
 	// update the values in the header
 	header.SEPFileOffset = sepPosition
 	header.MilestoneDiffCount = msDiffCount
@@ -1141,7 +1245,7 @@ func ReadSnapshotType(readSeeker io.ReadSeeker) (Type, error) {
 		return Full, fmt.Errorf("unable to read LS version: %w", err)
 	}
 
-	if version != SupportedFormatVersion {
+	if !isSupportedFormatVersion(version) {
 		return Full, ErrUnsupportedSnapshot
 	}
 
@@ -1167,7 +1271,7 @@ func ReadSnapshotType(readSeeker io.ReadSeeker) (Type, error) {
 
 // ReadSnapshotTypeFromFile reads the snapshot type of the given snapshot file.
 func ReadSnapshotTypeFromFile(filePath string) (Type, error) {
-	file, err := os.Open(filePath)
+	file, err := OpenSnapshotFile(filePath)
 	if err != nil {
 		return Full, fmt.Errorf("unable to open snapshot file to read type: %w", err)
 	}
@@ -1423,6 +1527,7 @@ func readSpent(reader io.ReadSeeker, protoParams *iotago.ProtocolParameters, msI
 }
 
 // ReadSnapshotHeaderFromFile reads the header of the given snapshot file.
+// Compressed snapshot files are detected automatically, only the header is decompressed.
 func ReadSnapshotHeaderFromFile(filePath string, headerConsumer func(readCloser io.ReadCloser) error) error {
 	file, err := os.Open(filePath)
 	if err != nil {
@@ -1430,7 +1535,13 @@ func ReadSnapshotHeaderFromFile(filePath string, headerConsumer func(readCloser
 	}
 	defer func() { _ = file.Close() }()
 
-	return headerConsumer(file)
+	reader, err := NewSnapshotStreamReader(file)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = reader.Close() }()
+
+	return headerConsumer(reader)
 }
 
 // ReadFullSnapshotHeaderFromFile reads the header of the given full snapshot file.
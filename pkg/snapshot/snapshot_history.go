@@ -0,0 +1,420 @@
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/runtime/ioutils"
+	"github.com/iotaledger/hive.go/runtime/syncutils"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+const (
+	// HistoryManifestFileName is the name of the file that describes all snapshot files in the history directory.
+	HistoryManifestFileName = "snapshots.json"
+	// HistoryHashManifestFileName is the name of the hash manifest ("sha256sum" format) of all snapshot files in the history directory.
+	HistoryHashManifestFileName = "sha256sums.txt"
+	// HistoryLatestFullFileName is the name of the symlink to the latest full snapshot file in the history directory.
+	HistoryLatestFullFileName = "latest-full_snapshot.bin"
+	// HistoryLatestDeltaFileName is the name of the symlink to the latest delta snapshot file in the history directory.
+	HistoryLatestDeltaFileName = "latest-delta_snapshot.bin"
+)
+
+var (
+	// ErrHistoryFileNotFound is returned if a snapshot file is not part of the snapshot history.
+	ErrHistoryFileNotFound = errors.New("snapshot file not found in snapshot history")
+)
+
+// HistoryFile describes a snapshot file in the snapshot history.
+type HistoryFile struct {
+	// FileName is the name of the file in the history directory.
+	FileName string `json:"fileName"`
+	// Type is the type of the snapshot ("full" or "delta").
+	Type string `json:"type"`
+	// TargetMilestoneIndex is the index of the milestone of which the SEPs within the snapshot are from.
+	TargetMilestoneIndex iotago.MilestoneIndex `json:"targetMilestoneIndex"`
+	// TargetMilestoneTimestamp is the timestamp of the milestone of which the SEPs within the snapshot are from.
+	TargetMilestoneTimestamp uint32 `json:"targetMilestoneTimestamp"`
+	// TargetMilestoneID is the ID of the milestone of which the SEPs within the full snapshot are from.
+	TargetMilestoneID string `json:"targetMilestoneId,omitempty"`
+	// LedgerMilestoneIndex is the index of the milestone of which the ledger within the full snapshot is from.
+	LedgerMilestoneIndex iotago.MilestoneIndex `json:"ledgerMilestoneIndex,omitempty"`
+	// FullSnapshotTargetMilestoneID is the target milestone ID of the full snapshot the delta snapshot builds up from.
+	FullSnapshotTargetMilestoneID string `json:"fullSnapshotTargetMilestoneId,omitempty"`
+	// Size is the size of the file in bytes.
+	Size int64 `json:"size"`
+	// SHA256 is the hex encoded SHA-256 hash of the file.
+	SHA256 string `json:"sha256"`
+	// CreatedAt is the unix timestamp the file was added to the history.
+	CreatedAt int64 `json:"createdAt"`
+}
+
+// fullSnapshotID returns the target milestone ID of the full snapshot the file belongs to.
+func (f *HistoryFile) fullSnapshotID() string {
+	if f.Type == snapshotNames[Full] {
+		return f.TargetMilestoneID
+	}
+
+	return f.FullSnapshotTargetMilestoneID
+}
+
+// HistoryManifest describes all snapshot files in the snapshot history.
+type HistoryManifest struct {
+	// LatestFull is the file name of the latest full snapshot file.
+	LatestFull string `json:"latestFull,omitempty"`
+	// LatestDelta is the file name of the latest delta snapshot file that fits the latest full snapshot file.
+	LatestDelta string `json:"latestDelta,omitempty"`
+	// Files are the snapshot files in the history, sorted by target milestone index.
+	Files []*HistoryFile `json:"files"`
+}
+
+// History keeps a rolling set of timestamped snapshot files in a directory,
+// so older snapshots are still available after the node created new ones.
+type History struct {
+	directory       string
+	maxFilesPerType int
+
+	lock     syncutils.RWMutex
+	manifest *HistoryManifest
+}
+
+// NewHistory creates a new snapshot history in the given directory.
+// MaxFilesPerType defines the amount of full and delta snapshot files each that are kept.
+func NewHistory(directory string, maxFilesPerType int) (*History, error) {
+	if maxFilesPerType < 1 {
+		return nil, fmt.Errorf("the snapshot history needs to keep at least one file per type, got %d", maxFilesPerType)
+	}
+
+	if err := os.MkdirAll(directory, 0700); err != nil {
+		return nil, fmt.Errorf("could not create snapshot history dir '%s': %w", directory, err)
+	}
+
+	manifest := &HistoryManifest{Files: []*HistoryFile{}}
+	if err := ioutils.ReadJSONFromFile(filepath.Join(directory, HistoryManifestFileName), manifest); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("unable to read snapshot history manifest: %w", err)
+	}
+
+	// files that were removed manually are dropped from the history
+	files := make([]*HistoryFile, 0, len(manifest.Files))
+	for _, file := range manifest.Files {
+		if _, err := os.Stat(filepath.Join(directory, file.FileName)); err == nil {
+			files = append(files, file)
+		}
+	}
+	manifest.Files = files
+
+	return &History{
+		directory:       directory,
+		maxFilesPerType: maxFilesPerType,
+		manifest:        manifest,
+	}, nil
+}
+
+// Directory returns the directory of the snapshot history.
+func (h *History) Directory() string {
+	return h.directory
+}
+
+// Manifest returns a copy of the manifest of the snapshot history.
+func (h *History) Manifest() *HistoryManifest {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	files := make([]*HistoryFile, len(h.manifest.Files))
+	for i, file := range h.manifest.Files {
+		fileCopy := *file
+		files[i] = &fileCopy
+	}
+
+	return &HistoryManifest{
+		LatestFull:  h.manifest.LatestFull,
+		LatestDelta: h.manifest.LatestDelta,
+		Files:       files,
+	}
+}
+
+// FilePath returns the path of the snapshot file with the given name in the history.
+// The names of the latest snapshot files are resolved to the actual files.
+// Only files that are part of the history are returned, so the name can't be used to access other files.
+func (h *History) FilePath(fileName string) (string, error) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	switch fileName {
+	case HistoryLatestFullFileName:
+		fileName = h.manifest.LatestFull
+	case HistoryLatestDeltaFileName:
+		fileName = h.manifest.LatestDelta
+	}
+
+	for _, file := range h.manifest.Files {
+		if fileName != "" && file.FileName == fileName {
+			return filepath.Join(h.directory, file.FileName), nil
+		}
+	}
+
+	return "", ErrHistoryFileNotFound
+}
+
+// Add adds the snapshot file at the given path to the history and removes the files that exceed the retention.
+func (h *History) Add(snapshotType Type, filePath string) (*HistoryFile, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	historyFile := &HistoryFile{
+		Type:      snapshotNames[snapshotType],
+		CreatedAt: time.Now().Unix(),
+	}
+
+	switch snapshotType {
+	case Full:
+		fullHeader, err := ReadFullSnapshotHeaderFromFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		historyFile.TargetMilestoneIndex = fullHeader.TargetMilestoneIndex
+		historyFile.TargetMilestoneTimestamp = fullHeader.TargetMilestoneTimestamp
+		historyFile.TargetMilestoneID = fullHeader.TargetMilestoneID.ToHex()
+		historyFile.LedgerMilestoneIndex = fullHeader.LedgerMilestoneIndex
+
+	case Delta:
+		deltaHeader, err := ReadDeltaSnapshotHeaderFromFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		historyFile.TargetMilestoneIndex = deltaHeader.TargetMilestoneIndex
+		historyFile.TargetMilestoneTimestamp = deltaHeader.TargetMilestoneTimestamp
+		historyFile.FullSnapshotTargetMilestoneID = deltaHeader.FullSnapshotTargetMilestoneID.ToHex()
+
+	default:
+		return nil, fmt.Errorf("unknown snapshot type: %d", snapshotType)
+	}
+
+	historyFile.FileName = fmt.Sprintf("%s_snapshot_%d_%s.bin", historyFile.Type, historyFile.TargetMilestoneIndex, formatSnapshotTimestampForFileName(historyFile.TargetMilestoneTimestamp))
+	historyFilePath := filepath.Join(h.directory, historyFile.FileName)
+
+	// full snapshot files are replaced by new files on creation, so they can be linked.
+	// delta snapshot files are extended in place, so they need to be copied.
+	if err := linkOrCopyFile(filePath, historyFilePath, snapshotType == Full); err != nil {
+		return nil, fmt.Errorf("unable to store %s snapshot file in snapshot history: %w", historyFile.Type, err)
+	}
+
+	size, hash, err := hashHistoryFile(historyFilePath)
+	if err != nil {
+		return nil, err
+	}
+	historyFile.Size = size
+	historyFile.SHA256 = hash
+
+	files := make([]*HistoryFile, 0, len(h.manifest.Files)+1)
+	for _, file := range h.manifest.Files {
+		if file.FileName != historyFile.FileName {
+			files = append(files, file)
+		}
+	}
+	h.manifest.Files = append(files, historyFile)
+
+	for _, removedFile := range h.applyRetentionWithoutLocking() {
+		if err := os.Remove(filepath.Join(h.directory, removedFile.FileName)); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("unable to remove %s snapshot file from snapshot history: %w", removedFile.Type, err)
+		}
+	}
+
+	if err := h.storeManifestWithoutLocking(); err != nil {
+		return nil, err
+	}
+
+	return historyFile, nil
+}
+
+// applyRetentionWithoutLocking keeps the latest full and delta snapshot files and returns the removed files.
+// Delta snapshot files whose full snapshot file was removed are removed as well, since they can't be applied anymore.
+func (h *History) applyRetentionWithoutLocking() []*HistoryFile {
+	sort.SliceStable(h.manifest.Files, func(i int, j int) bool {
+		return h.manifest.Files[i].TargetMilestoneIndex < h.manifest.Files[j].TargetMilestoneIndex
+	})
+
+	keep := make(map[*HistoryFile]struct{})
+	keptFullSnapshotIDs := make(map[string]struct{})
+
+	keepLatest := func(snapshotType Type) {
+		kept := 0
+		for i := len(h.manifest.Files) - 1; i >= 0 && kept < h.maxFilesPerType; i-- {
+			file := h.manifest.Files[i]
+			if file.Type != snapshotNames[snapshotType] {
+				continue
+			}
+
+			if _, exists := keptFullSnapshotIDs[file.fullSnapshotID()]; snapshotType == Delta && !exists {
+				continue
+			}
+
+			keep[file] = struct{}{}
+			keptFullSnapshotIDs[file.fullSnapshotID()] = struct{}{}
+			kept++
+		}
+	}
+	keepLatest(Full)
+	keepLatest(Delta)
+
+	var removed []*HistoryFile
+	files := make([]*HistoryFile, 0, len(keep))
+	for _, file := range h.manifest.Files {
+		if _, exists := keep[file]; !exists {
+			removed = append(removed, file)
+
+			continue
+		}
+		files = append(files, file)
+	}
+	h.manifest.Files = files
+
+	h.manifest.LatestFull = ""
+	h.manifest.LatestDelta = ""
+
+	var latestFull *HistoryFile
+	for _, file := range h.manifest.Files {
+		if file.Type == snapshotNames[Full] {
+			latestFull = file
+		}
+	}
+
+	if latestFull != nil {
+		h.manifest.LatestFull = latestFull.FileName
+
+		for _, file := range h.manifest.Files {
+			if file.Type == snapshotNames[Delta] && file.FullSnapshotTargetMilestoneID == latestFull.TargetMilestoneID && file.TargetMilestoneIndex > latestFull.TargetMilestoneIndex {
+				h.manifest.LatestDelta = file.FileName
+			}
+		}
+	}
+
+	return removed
+}
+
+// storeManifestWithoutLocking writes the manifest, the hash manifest and the symlinks to the latest snapshot files.
+func (h *History) storeManifestWithoutLocking() error {
+	manifestFilePath := filepath.Join(h.directory, HistoryManifestFileName)
+	if err := ioutils.WriteJSONToFile(manifestFilePath+".tmp", h.manifest, 0600); err != nil {
+		return fmt.Errorf("unable to write snapshot history manifest: %w", err)
+	}
+	if err := os.Rename(manifestFilePath+".tmp", manifestFilePath); err != nil {
+		return fmt.Errorf("unable to write snapshot history manifest: %w", err)
+	}
+
+	// the hash manifest allows other nodes to verify the snapshot files they download from the history
+	var hashManifest strings.Builder
+	for _, file := range h.manifest.Files {
+		hashManifest.WriteString(fmt.Sprintf("%s  %s\n", file.SHA256, file.FileName))
+
+		switch file.FileName {
+		case h.manifest.LatestFull:
+			hashManifest.WriteString(fmt.Sprintf("%s  %s\n", file.SHA256, HistoryLatestFullFileName))
+		case h.manifest.LatestDelta:
+			hashManifest.WriteString(fmt.Sprintf("%s  %s\n", file.SHA256, HistoryLatestDeltaFileName))
+		}
+	}
+
+	hashManifestFilePath := filepath.Join(h.directory, HistoryHashManifestFileName)
+	if err := os.WriteFile(hashManifestFilePath+".tmp", []byte(hashManifest.String()), 0600); err != nil {
+		return fmt.Errorf("unable to write snapshot history hash manifest: %w", err)
+	}
+	if err := os.Rename(hashManifestFilePath+".tmp", hashManifestFilePath); err != nil {
+		return fmt.Errorf("unable to write snapshot history hash manifest: %w", err)
+	}
+
+	if err := updateHistorySymlink(filepath.Join(h.directory, HistoryLatestFullFileName), h.manifest.LatestFull); err != nil {
+		return err
+	}
+
+	return updateHistorySymlink(filepath.Join(h.directory, HistoryLatestDeltaFileName), h.manifest.LatestDelta)
+}
+
+// updateHistorySymlink points the symlink at the given path to the target file name in the same directory.
+// The symlink is removed if the target file name is empty.
+func updateHistorySymlink(symlinkPath string, targetFileName string) error {
+	if err := os.Remove(symlinkPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("unable to remove snapshot history symlink: %w", err)
+	}
+
+	if targetFileName == "" {
+		return nil
+	}
+
+	if err := os.Symlink(targetFileName, symlinkPath); err != nil {
+		return fmt.Errorf("unable to create snapshot history symlink: %w", err)
+	}
+
+	return nil
+}
+
+// linkOrCopyFile stores the source file at the target path.
+// If linking is allowed, a hard link is created, otherwise or if linking fails, the file is copied.
+func linkOrCopyFile(sourcePath string, targetPath string, allowLink bool) error {
+	tempFilePath := targetPath + ".tmp"
+	_ = os.Remove(tempFilePath)
+
+	if allowLink {
+		if err := os.Link(sourcePath, tempFilePath); err == nil {
+			return os.Rename(tempFilePath, targetPath)
+		}
+	}
+
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = source.Close() }()
+
+	target, err := os.Create(tempFilePath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(target, source); err != nil {
+		_ = target.Close()
+		_ = os.Remove(tempFilePath)
+
+		return err
+	}
+
+	return ioutils.CloseFileAndRename(target, tempFilePath, targetPath)
+}
+
+// hashHistoryFile returns the size and the hex encoded SHA-256 hash of the given file.
+func hashHistoryFile(filePath string) (int64, string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return 0, "", err
+	}
+	defer func() { _ = file.Close() }()
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, file)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return size, hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// formatSnapshotTimestampForFileName formats the timestamp like FormatSnapshotTimestamp,
+// but in UTC and without characters that are not allowed in file names on all platforms.
+func formatSnapshotTimestampForFileName(timestamp uint32) string {
+	if timestamp == 0 {
+		return "unknown"
+	}
+
+	return time.Unix(int64(timestamp), 0).UTC().Format("2006-01-02_15-04-05")
+}
@@ -0,0 +1,406 @@
+package snapshot
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Snapshot files of format version 3 end with an integrity trailer,
+// which contains the hashes of all sections of the snapshot file.
+// The sections are verified before a snapshot is imported,
+// so a truncated or corrupted file is detected before any data is written to the database.
+//
+// Integrity Trailer:
+//	Sections	oneOf
+//		Section Type	uint8
+//		Offset	int64	the file offset of the section.
+//		Length	int64	the length of the section in bytes.
+//		Hash	Array<byte>[32]	the sha256 hash of the section.
+//	Sections Count	uint8
+//	Trailer Hash	Array<byte>[32]	the sha256 hash of all section entries and the sections count.
+
+const (
+	// integritySectionEntryLength is the length of a serialized section entry in the integrity trailer.
+	integritySectionEntryLength = 1 + 8 + 8 + sha256.Size
+	// integrityTrailerFooterLength is the length of the sections count and the trailer hash.
+	integrityTrailerFooterLength = 1 + sha256.Size
+)
+
+var (
+	// ErrSnapshotIntegrityCheckFailed is returned if the content of a snapshot file does not match its integrity trailer.
+	ErrSnapshotIntegrityCheckFailed = errors.New("snapshot integrity check failed")
+)
+
+// IntegritySectionType defines the type of section of a snapshot file that is covered by the integrity trailer.
+type IntegritySectionType byte
+
+const (
+	// IntegritySectionHeader is the header of the snapshot file, including the counters.
+	IntegritySectionHeader IntegritySectionType = iota
+	// IntegritySectionProtocolParameters is the protocol parameters milestone option within the header of a full snapshot.
+	IntegritySectionProtocolParameters
+	// IntegritySectionOutputs contains the unspent outputs of a full snapshot.
+	IntegritySectionOutputs
+	// IntegritySectionMilestoneDiffs contains the milestone diffs.
+	IntegritySectionMilestoneDiffs
+	// IntegritySectionSolidEntryPoints contains the solid entry points.
+	IntegritySectionSolidEntryPoints
+)
+
+// maps the integrity section type to its name.
+var integritySectionNames = map[IntegritySectionType]string{
+	IntegritySectionHeader:             "header",
+	IntegritySectionProtocolParameters: "protocol parameters",
+	IntegritySectionOutputs:            "outputs",
+	IntegritySectionMilestoneDiffs:     "milestone diffs",
+	IntegritySectionSolidEntryPoints:   "solid entry points",
+}
+
+func (t IntegritySectionType) String() string {
+	if name, exists := integritySectionNames[t]; exists {
+		return name
+	}
+
+	return fmt.Sprintf("unknown section type: %d", t)
+}
+
+// IntegritySection is a section of a snapshot file that is covered by the integrity trailer.
+type IntegritySection struct {
+	// Type is the type of the section.
+	Type IntegritySectionType
+	// Offset is the file offset of the section.
+	Offset int64
+	// Length is the length of the section in bytes.
+	Length int64
+	// Hash is the sha256 hash of the section.
+	Hash [sha256.Size]byte
+}
+
+// integritySectionWriter passes all writes of a section to the underlying writer and hashes the written data.
+type integritySectionWriter struct {
+	writer  io.Writer
+	section *IntegritySection
+	hasher  hash.Hash
+}
+
+func newIntegritySectionWriter(writer io.Writer, sectionType IntegritySectionType, offset int64) *integritySectionWriter {
+	return &integritySectionWriter{
+		writer:  writer,
+		section: &IntegritySection{Type: sectionType, Offset: offset},
+		hasher:  sha256.New(),
+	}
+}
+
+func (w *integritySectionWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.section.Length += int64(n)
+	_, _ = w.hasher.Write(p[:n])
+
+	return n, err
+}
+
+// hashExisting adds data of the section that already exists in the file to the hash.
+func (w *integritySectionWriter) hashExisting(reader io.Reader, length int64) error {
+	n, err := io.CopyN(w.hasher, reader, length)
+	w.section.Length += n
+
+	return err
+}
+
+// finalize computes the hash of the section.
+func (w *integritySectionWriter) finalize() *IntegritySection {
+	copy(w.section.Hash[:], w.hasher.Sum(nil))
+
+	return w.section
+}
+
+// integritySectionFromBytes returns the integrity section for the given data.
+func integritySectionFromBytes(sectionType IntegritySectionType, offset int64, data []byte) *IntegritySection {
+	return &IntegritySection{
+		Type:   sectionType,
+		Offset: offset,
+		Length: int64(len(data)),
+		Hash:   sha256.Sum256(data),
+	}
+}
+
+// writeIntegrityTrailer appends the integrity trailer with the given sections at the end of the snapshot file.
+func writeIntegrityTrailer(writeSeeker io.WriteSeeker, sections ...*IntegritySection) error {
+
+	if _, err := writeSeeker.Seek(0, io.SeekEnd); err != nil {
+		return fmt.Errorf("unable to seek to the end of the snapshot file: %w", err)
+	}
+
+	var trailer bytes.Buffer
+	for _, section := range sections {
+		if err := writeFunc(&trailer, "integrity section type", section.Type); err != nil {
+			return err
+		}
+		if err := writeFunc(&trailer, "integrity section offset", section.Offset); err != nil {
+			return err
+		}
+		if err := writeFunc(&trailer, "integrity section length", section.Length); err != nil {
+			return err
+		}
+		if err := writeFunc(&trailer, "integrity section hash", section.Hash[:]); err != nil {
+			return err
+		}
+	}
+
+	if err := writeFunc(&trailer, "integrity sections count", uint8(len(sections))); err != nil {
+		return err
+	}
+
+	trailerHash := sha256.Sum256(trailer.Bytes())
+	if err := writeFunc(&trailer, "integrity trailer hash", trailerHash[:]); err != nil {
+		return err
+	}
+
+	if _, err := writeSeeker.Write(trailer.Bytes()); err != nil {
+		return fmt.Errorf("unable to write LS integrity trailer: %w", err)
+	}
+
+	return nil
+}
+
+// ReadIntegrityTrailer reads the integrity trailer at the end of the given snapshot file.
+// It returns the sections and the offset of the trailer in the file.
+func ReadIntegrityTrailer(readSeeker io.ReadSeeker) ([]*IntegritySection, int64, error) {
+
+	fileSize, err := readSeeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to seek to the end of the snapshot file: %w", err)
+	}
+
+	if fileSize < integrityTrailerFooterLength {
+		return nil, 0, errors.WithMessage(ErrSnapshotIntegrityCheckFailed, "snapshot file is too small to contain an integrity trailer")
+	}
+
+	if _, err := readSeeker.Seek(fileSize-integrityTrailerFooterLength, io.SeekStart); err != nil {
+		return nil, 0, fmt.Errorf("unable to seek to the integrity trailer: %w", err)
+	}
+
+	var sectionsCount uint8
+	if err := binary.Read(readSeeker, binary.LittleEndian, &sectionsCount); err != nil {
+		return nil, 0, fmt.Errorf("unable to read LS integrity sections count: %w", err)
+	}
+
+	var trailerHash [sha256.Size]byte
+	if _, err := io.ReadFull(readSeeker, trailerHash[:]); err != nil {
+		return nil, 0, fmt.Errorf("unable to read LS integrity trailer hash: %w", err)
+	}
+
+	trailerLength := int64(sectionsCount)*integritySectionEntryLength + integrityTrailerFooterLength
+	if fileSize < trailerLength {
+		return nil, 0, errors.WithMessage(ErrSnapshotIntegrityCheckFailed, "snapshot file is too small to contain the integrity trailer")
+	}
+	trailerOffset := fileSize - trailerLength
+
+	if _, err := readSeeker.Seek(trailerOffset, io.SeekStart); err != nil {
+		return nil, 0, fmt.Errorf("unable to seek to the integrity trailer: %w", err)
+	}
+
+	// the trailer hash covers the section entries and the sections count
+	trailerBytes := make([]byte, trailerLength-sha256.Size)
+	if _, err := io.ReadFull(readSeeker, trailerBytes); err != nil {
+		return nil, 0, fmt.Errorf("unable to read LS integrity trailer: %w", err)
+	}
+
+	if sha256.Sum256(trailerBytes) != trailerHash {
+		return nil, 0, errors.WithMessage(ErrSnapshotIntegrityCheckFailed, "integrity trailer hash mismatch")
+	}
+
+	reader := bytes.NewReader(trailerBytes)
+	sections := make([]*IntegritySection, sectionsCount)
+	for i := range sections {
+		section := &IntegritySection{}
+		if err := binary.Read(reader, binary.LittleEndian, &section.Type); err != nil {
+			return nil, 0, fmt.Errorf("unable to read LS integrity section type: %w", err)
+		}
+		if err := binary.Read(reader, binary.LittleEndian, &section.Offset); err != nil {
+			return nil, 0, fmt.Errorf("unable to read LS integrity section offset: %w", err)
+		}
+		if err := binary.Read(reader, binary.LittleEndian, &section.Length); err != nil {
+			return nil, 0, fmt.Errorf("unable to read LS integrity section length: %w", err)
+		}
+		if _, err := io.ReadFull(reader, section.Hash[:]); err != nil {
+			return nil, 0, fmt.Errorf("unable to read LS integrity section hash: %w", err)
+		}
+		sections[i] = section
+	}
+
+	return sections, trailerOffset, nil
+}
+
+// verifyIntegritySectionsLayout checks that the sections cover the whole snapshot file without gaps.
+func verifyIntegritySectionsLayout(snapshotType Type, sections []*IntegritySection, trailerOffset int64) error {
+
+	expectedSectionTypes := []IntegritySectionType{IntegritySectionHeader, IntegritySectionMilestoneDiffs, IntegritySectionSolidEntryPoints}
+	if snapshotType == Full {
+		expectedSectionTypes = []IntegritySectionType{IntegritySectionHeader, IntegritySectionProtocolParameters, IntegritySectionOutputs, IntegritySectionMilestoneDiffs, IntegritySectionSolidEntryPoints}
+	}
+
+	if len(sections) != len(expectedSectionTypes) {
+		return errors.WithMessagef(ErrSnapshotIntegrityCheckFailed, "expected %d integrity sections, got %d", len(expectedSectionTypes), len(sections))
+	}
+
+	// the position up to which the file is covered by the sections.
+	var coveredPosition int64
+	for i, section := range sections {
+		if section.Type != expectedSectionTypes[i] {
+			return errors.WithMessagef(ErrSnapshotIntegrityCheckFailed, "expected integrity section %s at position %d, got %s", expectedSectionTypes[i], i, section.Type)
+		}
+
+		if section.Offset < 0 || section.Length < 0 || section.Offset+section.Length > trailerOffset {
+			return errors.WithMessagef(ErrSnapshotIntegrityCheckFailed, "%s section exceeds the snapshot file", section.Type)
+		}
+
+		// the protocol parameters are part of the header, all other sections follow each other
+		if section.Type != IntegritySectionProtocolParameters {
+			if section.Offset != coveredPosition {
+				return errors.WithMessagef(ErrSnapshotIntegrityCheckFailed, "%s section starts at %d, expected %d", section.Type, section.Offset, coveredPosition)
+			}
+			coveredPosition += section.Length
+		}
+	}
+
+	if coveredPosition != trailerOffset {
+		return errors.WithMessagef(ErrSnapshotIntegrityCheckFailed, "sections end at %d, but the integrity trailer starts at %d", coveredPosition, trailerOffset)
+	}
+
+	return nil
+}
+
+// verifyIntegritySection checks that the content of the section matches its hash.
+func verifyIntegritySection(readSeeker io.ReadSeeker, section *IntegritySection) error {
+
+	if _, err := readSeeker.Seek(section.Offset, io.SeekStart); err != nil {
+		return fmt.Errorf("unable to seek to the %s section: %w", section.Type, err)
+	}
+
+	hasher := sha256.New()
+	if _, err := io.CopyN(hasher, readSeeker, section.Length); err != nil {
+		return errors.WithMessagef(ErrSnapshotIntegrityCheckFailed, "unable to read the %s section: %s", section.Type, err)
+	}
+
+	if !bytes.Equal(hasher.Sum(nil), section.Hash[:]) {
+		return errors.WithMessagef(ErrSnapshotIntegrityCheckFailed, "%s section hash mismatch", section.Type)
+	}
+
+	return nil
+}
+
+// verifyIntegritySections checks that the sections cover the whole snapshot file without gaps
+// and that the content of every section matches its hash.
+func verifyIntegritySections(readSeeker io.ReadSeeker, snapshotType Type, sections []*IntegritySection, trailerOffset int64) error {
+
+	if err := verifyIntegritySectionsLayout(snapshotType, sections, trailerOffset); err != nil {
+		return err
+	}
+
+	for _, section := range sections {
+		if err := verifyIntegritySection(readSeeker, section); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newVerifiedMilestoneDiffsSectionWriter verifies an existing delta snapshot against its integrity trailer
+// and returns the writer of the milestone diffs section that already contains the existing milestone diffs.
+// The existing milestone diffs are only read once, they are hashed for the verification and the updated section at the same time.
+func newVerifiedMilestoneDiffsSectionWriter(readWriteSeeker io.ReadWriteSeeker, sepFileOffset int64) (*integritySectionWriter, error) {
+
+	sections, trailerOffset, err := ReadIntegrityTrailer(readWriteSeeker)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyIntegritySectionsLayout(Delta, sections, trailerOffset); err != nil {
+		return nil, err
+	}
+
+	headerSection, msDiffsSection, sepsSection := sections[0], sections[1], sections[2]
+	if msDiffsSection.Offset+msDiffsSection.Length != sepFileOffset {
+		return nil, errors.WithMessagef(ErrSnapshotIntegrityCheckFailed, "%s section ends at %d, but the solid entry points start at %d", msDiffsSection.Type, msDiffsSection.Offset+msDiffsSection.Length, sepFileOffset)
+	}
+
+	for _, section := range []*IntegritySection{headerSection, sepsSection} {
+		if err := verifyIntegritySection(readWriteSeeker, section); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := readWriteSeeker.Seek(msDiffsSection.Offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("unable to seek to the existing milestone diffs: %w", err)
+	}
+
+	msDiffsWriter := newIntegritySectionWriter(readWriteSeeker, IntegritySectionMilestoneDiffs, msDiffsSection.Offset)
+	if err := msDiffsWriter.hashExisting(readWriteSeeker, msDiffsSection.Length); err != nil {
+		return nil, errors.WithMessagef(ErrSnapshotIntegrityCheckFailed, "unable to read the existing milestone diffs: %s", err)
+	}
+
+	if !bytes.Equal(msDiffsWriter.hasher.Sum(nil), msDiffsSection.Hash[:]) {
+		return nil, errors.WithMessagef(ErrSnapshotIntegrityCheckFailed, "%s section hash mismatch", msDiffsSection.Type)
+	}
+
+	return msDiffsWriter, nil
+}
+
+// VerifySnapshotIntegrity verifies the content of the given snapshot against its integrity trailer.
+// It returns false if the snapshot format version does not contain an integrity trailer.
+func VerifySnapshotIntegrity(readSeeker io.ReadSeeker) (bool, error) {
+
+	var version byte
+	if err := binary.Read(readSeeker, binary.LittleEndian, &version); err != nil {
+		return false, fmt.Errorf("unable to read LS version: %w", err)
+	}
+
+	if !isSupportedFormatVersion(version) {
+		return false, ErrUnsupportedSnapshot
+	}
+
+	if version < IntegrityFormatVersion {
+		// older snapshot files do not contain an integrity trailer
+		return false, nil
+	}
+
+	var snapshotType Type
+	if err := binary.Read(readSeeker, binary.LittleEndian, &snapshotType); err != nil {
+		return false, fmt.Errorf("unable to read LS type: %w", err)
+	}
+
+	if snapshotType != Full && snapshotType != Delta {
+		return false, ErrUnsupportedSnapshot
+	}
+
+	sections, trailerOffset, err := ReadIntegrityTrailer(readSeeker)
+	if err != nil {
+		return false, err
+	}
+
+	if err := verifyIntegritySections(readSeeker, snapshotType, sections, trailerOffset); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// VerifySnapshotFileIntegrity verifies the content of the given snapshot file against its integrity trailer.
+// It returns false if the snapshot format version does not contain an integrity trailer.
+func VerifySnapshotFileIntegrity(filePath string) (bool, error) {
+	file, err := OpenSnapshotFile(filePath)
+	if err != nil {
+		return false, fmt.Errorf("unable to open snapshot file to verify integrity: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	return VerifySnapshotIntegrity(file)
+}
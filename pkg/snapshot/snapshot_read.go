@@ -3,7 +3,7 @@ package snapshot
 import (
 	"context"
 	"fmt"
-	"os"
+	"io"
 	"time"
 
 	"github.com/pkg/errors"
@@ -45,8 +45,8 @@ func newProtocolStorageGetterFunc(dbStorage *storage.Storage) ProtocolStorageGet
 // the given targetHeader is populated with the value of the read file header.
 func newFullHeaderConsumer(targetFullHeader *FullSnapshotHeader, utxoManager *utxo.Manager, targetNetworkID ...uint64) FullHeaderConsumerFunc {
 	return func(header *FullSnapshotHeader) error {
-		if header.Version != SupportedFormatVersion {
-			return errors.Wrapf(ErrUnsupportedSnapshot, "snapshot file version is %d but this HORNET version only supports %v", header.Version, SupportedFormatVersion)
+		if !isSupportedFormatVersion(header.Version) {
+			return errors.Wrapf(ErrUnsupportedSnapshot, "snapshot file version is %d but this HORNET version only supports %d-%d", header.Version, MinSupportedFormatVersion, SupportedFormatVersion)
 		}
 
 		if header.Type != Full {
@@ -74,8 +74,8 @@ func newFullHeaderConsumer(targetFullHeader *FullSnapshotHeader, utxoManager *ut
 // the given targetHeader is populated with the value of the read file header.
 func newDeltaHeaderConsumer(targetHeader *DeltaSnapshotHeader) DeltaHeaderConsumerFunc {
 	return func(header *DeltaSnapshotHeader) error {
-		if header.Version != SupportedFormatVersion {
-			return errors.Wrapf(ErrUnsupportedSnapshot, "snapshot file version is %d but this HORNET version only supports %v", header.Version, SupportedFormatVersion)
+		if !isSupportedFormatVersion(header.Version) {
+			return errors.Wrapf(ErrUnsupportedSnapshot, "snapshot file version is %d but this HORNET version only supports %d-%d", header.Version, MinSupportedFormatVersion, SupportedFormatVersion)
 		}
 
 		if header.Type != Delta {
@@ -181,8 +181,8 @@ func loadFullSnapshotFileToStorage(
 		dbStorage.WriteUnlockSolidEntryPoints()
 	}()
 
-	var lsFile *os.File
-	lsFile, err = os.Open(filePath)
+	var lsFile io.ReadSeekCloser
+	lsFile, err = OpenSnapshotFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("unable to open %s snapshot file for import: %w", snapshotNames[Full], err)
 	}
@@ -256,8 +256,8 @@ func loadDeltaSnapshotFileToStorage(
 		dbStorage.WriteUnlockSolidEntryPoints()
 	}()
 
-	var lsFile *os.File
-	lsFile, err = os.Open(filePath)
+	var lsFile io.ReadSeekCloser
+	lsFile, err = OpenSnapshotFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("unable to open %s snapshot file for import: %w", snapshotNames[Delta], err)
 	}
@@ -0,0 +1,166 @@
+package snapshot
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrSnapshotFileNotAvailable is returned if the requested snapshot file does not exist or is currently being updated.
+	ErrSnapshotFileNotAvailable = errors.New("snapshot file not available")
+)
+
+// ServedFile is a snapshot file that is opened to be served to other nodes.
+// The content of the file does not change until it is closed, even if new snapshot files are created in the meantime.
+type ServedFile struct {
+	*os.File
+
+	manager      *Manager
+	snapshotType Type
+	info         os.FileInfo
+	closeOnce    sync.Once
+}
+
+// Type returns the type of the snapshot file.
+func (f *ServedFile) Type() Type {
+	return f.snapshotType
+}
+
+// Info returns the file info of the snapshot file at the time it was opened.
+func (f *ServedFile) Info() os.FileInfo {
+	return f.info
+}
+
+// IsCompressed checks if the snapshot file is compressed.
+func (f *ServedFile) IsCompressed() (bool, error) {
+	magic := make([]byte, 4)
+	if _, err := f.ReadAt(magic, 0); err != nil {
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("unable to read snapshot file: %w", err)
+	}
+
+	return isCompressedSnapshot(magic), nil
+}
+
+// headerReader returns a reader for the (decompressed) header of the snapshot file.
+// it doesn't change the offset of the file, so the file can still be served afterwards.
+func (f *ServedFile) headerReader() (io.ReadCloser, error) {
+	return NewSnapshotStreamReader(io.NewSectionReader(f.File, 0, f.info.Size()))
+}
+
+// FullHeader reads the header of the full snapshot file.
+func (f *ServedFile) FullHeader() (*FullSnapshotHeader, error) {
+	if f.snapshotType != Full {
+		return nil, fmt.Errorf("snapshot file is not a %s snapshot file", snapshotNames[Full])
+	}
+
+	reader, err := f.headerReader()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = reader.Close() }()
+
+	return ReadFullSnapshotHeader(reader)
+}
+
+// DeltaHeader reads the header of the delta snapshot file.
+func (f *ServedFile) DeltaHeader() (*DeltaSnapshotHeader, error) {
+	if f.snapshotType != Delta {
+		return nil, fmt.Errorf("snapshot file is not a %s snapshot file", snapshotNames[Delta])
+	}
+
+	reader, err := f.headerReader()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = reader.Close() }()
+
+	return ReadDeltaSnapshotHeader(reader)
+}
+
+// Close closes the snapshot file, so the node is allowed to update it in place again.
+func (f *ServedFile) Close() error {
+	var err error
+	f.closeOnce.Do(func() {
+		err = f.File.Close()
+
+		if f.snapshotType == Delta {
+			f.manager.servedFilesLock.Lock()
+			f.manager.servedDeltaFiles--
+			f.manager.servedFilesLock.Unlock()
+		}
+	})
+
+	return err
+}
+
+// OpenSnapshotFile opens the current snapshot file of the given type to serve it to other nodes.
+// The returned file needs to be closed after it was served.
+func (s *Manager) OpenSnapshotFile(snapshotType Type) (*ServedFile, error) {
+	if snapshotType != Full && snapshotType != Delta {
+		return nil, fmt.Errorf("unknown snapshot type: %d", snapshotType)
+	}
+	filePath := s.snapshotTypeFilePath(snapshotType)
+
+	s.servedFilesLock.Lock()
+	defer s.servedFilesLock.Unlock()
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// the file doesn't exist yet or the delta snapshot file is currently extended
+			return nil, errors.Wrap(ErrSnapshotFileNotAvailable, snapshotNames[snapshotType])
+		}
+
+		return nil, fmt.Errorf("unable to open %s snapshot file: %w", snapshotNames[snapshotType], err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+
+		return nil, fmt.Errorf("unable to open %s snapshot file: %w", snapshotNames[snapshotType], err)
+	}
+
+	if snapshotType == Delta {
+		s.servedDeltaFiles++
+	}
+
+	return &ServedFile{
+		File:         file,
+		manager:      s,
+		snapshotType: snapshotType,
+		info:         info,
+	}, nil
+}
+
+// detachDeltaSnapshotFile moves the delta snapshot file to the given path, so it can be extended in place.
+// if the delta snapshot file is currently served to other nodes, it is copied instead,
+// so the content that is served does not change.
+func (s *Manager) detachDeltaSnapshotFile(targetPath string) error {
+	s.servedFilesLock.Lock()
+	if s.servedDeltaFiles == 0 {
+		defer s.servedFilesLock.Unlock()
+
+		if err := os.Rename(s.snapshotDeltaPath, targetPath); err != nil {
+			return fmt.Errorf("unable to rename file: %w", err)
+		}
+
+		return nil
+	}
+	s.servedFilesLock.Unlock()
+
+	// the delta snapshot file is only modified while the snapshot lock is held, so it is safe to copy it without locking.
+	if err := linkOrCopyFile(s.snapshotDeltaPath, targetPath, false); err != nil {
+		return fmt.Errorf("unable to copy file: %w", err)
+	}
+
+	return nil
+}
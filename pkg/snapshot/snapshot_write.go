@@ -456,7 +456,7 @@ func (s *Manager) createFullSnapshotWithoutLocking(
 	timeStreamSnapshotData := time.Now()
 
 	// finalize file
-	if err := ioutils.CloseFileAndRename(snapshotFile, tempFilePath, filePath); err != nil {
+	if err := closeAndFinalizeSnapshotFile(snapshotFile, tempFilePath, filePath, s.snapshotCompression); err != nil {
 		return err
 	}
 
@@ -574,21 +574,40 @@ func (s *Manager) createDeltaSnapshotWithoutLocking(ctx context.Context, targetI
 	var snapshotFile *os.File
 	var tempFilePath string
 
-	// a delta snapshot contains the milestone diffs from a full snapshot's target index onwards.
-	// if the delta snapshot already exists, we can reuse the existing file and just append to it.
+	var oldDeltaHeader *DeltaSnapshotHeader
 	if deltaSnapshotFileExists {
-		var oldDeltaHeader *DeltaSnapshotHeader
 		oldDeltaHeader, err = ReadDeltaSnapshotHeaderFromFile(s.snapshotDeltaPath)
 		if err != nil {
 			return fmt.Errorf("unable to read delta snapshot header: %w", err)
 		}
 
+		if oldDeltaHeader.Version != SupportedFormatVersion {
+			// delta snapshot files of older format versions can't be extended, they are recreated instead.
+			s.LogInfof("recreating %s snapshot file with format version %d (was %d)", snapshotNames[Delta], SupportedFormatVersion, oldDeltaHeader.Version)
+			oldDeltaHeader = nil
+		}
+	}
+
+	// a delta snapshot contains the milestone diffs from a full snapshot's target index onwards.
+	// if the delta snapshot already exists, we can reuse the existing file and just append to it.
+	if oldDeltaHeader != nil {
 		// we stream the diff from the old delta header target index to the new target index
 		milestoneDiffProducer := NewMsDiffsProducer(MilestoneRetrieverFromStorage(s.storage), s.utxoManager, MsDiffDirectionOnwards, oldDeltaHeader.TargetMilestoneIndex, targetIndex)
 
 		tempFilePath = s.snapshotDeltaPath + "_tmp"
-		if err := os.Rename(s.snapshotDeltaPath, tempFilePath); err != nil {
-			return fmt.Errorf("unable to rename file: %w", err)
+
+		compressed, err := IsCompressedSnapshotFile(s.snapshotDeltaPath)
+		if err != nil {
+			return err
+		}
+
+		if compressed {
+			// compressed delta snapshot files can't be extended in place, they are decompressed first.
+			if err := DecompressSnapshotFile(s.snapshotDeltaPath, tempFilePath); err != nil {
+				return err
+			}
+		} else if err := s.detachDeltaSnapshotFile(tempFilePath); err != nil {
+			return err
 		}
 
 		snapshotFile, err = os.OpenFile(tempFilePath, os.O_RDWR, 0666)
@@ -619,7 +638,7 @@ func (s *Manager) createDeltaSnapshotWithoutLocking(ctx context.Context, targetI
 	timeStreamSnapshotData := time.Now()
 
 	// finalize file
-	if err := ioutils.CloseFileAndRename(snapshotFile, tempFilePath, s.snapshotDeltaPath); err != nil {
+	if err := closeAndFinalizeSnapshotFile(snapshotFile, tempFilePath, s.snapshotDeltaPath, s.snapshotCompression); err != nil {
 		return err
 	}
 
@@ -0,0 +1,181 @@
+//nolint:forcetypeassert,varnamelen,revive,exhaustruct,gosec // we don't care about these linters in test cases
+package snapshot_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hornet/v2/pkg/model/storage"
+	"github.com/iotaledger/hornet/v2/pkg/snapshot"
+	"github.com/iotaledger/hornet/v2/pkg/tpkg"
+)
+
+func TestCompressedFullSnapshot(t *testing.T) {
+	if testing.Short() {
+		return
+	}
+
+	// enough outputs to split the compressed snapshot into several frames
+	fullHeader := randFullSnapshotHeader(200000, 50, 150)
+
+	outputIterFunc, outputGenRetriever := newOutputsGenerator(fullHeader.OutputCount)
+	outputConsumerFunc, outputCollRetriever := newOutputCollector()
+
+	msDiffIterFunc, msDiffGenRetriever := newMsDiffGenerator(fullHeader.TargetMilestoneIndex+10, fullHeader.MilestoneDiffCount, snapshot.MsDiffDirectionBackwards)
+	msDiffConsumerFunc, msDiffCollRetriever := newMsDiffCollector()
+
+	sepIterFunc, sepGenRetriever := newSEPGenerator(fullHeader.SEPCount)
+	sepConsumerFunc, sepsCollRetriever := newSEPCollector()
+
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "full_snapshot.bin")
+	compressedFilePath := filepath.Join(tempDir, "full_snapshot.bin.zst")
+
+	snapshotFileWrite, err := os.OpenFile(filePath, os.O_CREATE|os.O_RDWR, 0666)
+	require.NoError(t, err)
+	_, err = snapshot.StreamFullSnapshotDataTo(snapshotFileWrite, fullHeader, outputIterFunc, msDiffIterFunc, sepIterFunc)
+	require.NoError(t, err)
+	require.NoError(t, snapshotFileWrite.Close())
+
+	require.NoError(t, snapshot.CompressSnapshotFile(filePath, compressedFilePath))
+
+	compressed, err := snapshot.IsCompressedSnapshotFile(compressedFilePath)
+	require.NoError(t, err)
+	require.True(t, compressed)
+
+	compressed, err = snapshot.IsCompressedSnapshotFile(filePath)
+	require.NoError(t, err)
+	require.False(t, compressed)
+
+	fileInfo, err := os.Stat(filePath)
+	require.NoError(t, err)
+	compressedFileInfo, err := os.Stat(compressedFilePath)
+	require.NoError(t, err)
+	require.Less(t, compressedFileInfo.Size(), fileInfo.Size())
+
+	// the header and the snapshot type can be read from the compressed file
+	snapshotType, err := snapshot.ReadSnapshotTypeFromFile(compressedFilePath)
+	require.NoError(t, err)
+	require.Equal(t, snapshot.Full, snapshotType)
+
+	readHeader, err := snapshot.ReadFullSnapshotHeaderFromFile(compressedFilePath)
+	require.NoError(t, err)
+	require.Equal(t, fullHeader.TargetMilestoneID, readHeader.TargetMilestoneID)
+	require.Equal(t, fullHeader.OutputCount, readHeader.OutputCount)
+
+	// the integrity of the decompressed data is verified
+	verified, err := snapshot.VerifySnapshotFileIntegrity(compressedFilePath)
+	require.NoError(t, err)
+	require.True(t, verified)
+
+	// the whole compressed snapshot can be streamed
+	snapshotFileRead, err := snapshot.OpenSnapshotFile(compressedFilePath)
+	require.NoError(t, err)
+	defer func() { _ = snapshotFileRead.Close() }()
+
+	require.NoError(t, snapshot.StreamFullSnapshotDataFrom(
+		context.Background(),
+		snapshotFileRead,
+		fullHeaderEqualFunc(t, fullHeader),
+		unspentTreasuryOutputEqualFunc(t, fullHeader.TreasuryOutput),
+		outputConsumerFunc,
+		msDiffConsumerFunc,
+		sepConsumerFunc,
+		newProtocolParamsMilestoneOptConsumerFunc(),
+	))
+
+	tpkg.EqualOutputs(t, outputGenRetriever(), outputCollRetriever())
+
+	msDiffGen := msDiffGenRetriever()
+	msDiffCon := msDiffCollRetriever()
+	require.Len(t, msDiffCon, 10)
+	for i := range msDiffCon {
+		equalMilestoneDiff(t, msDiffGen[i], msDiffCon[i])
+	}
+	require.EqualValues(t, sepGenRetriever(), sepsCollRetriever())
+
+	// decompressing restores the original file
+	decompressedFilePath := filepath.Join(tempDir, "full_snapshot_decompressed.bin")
+	require.NoError(t, snapshot.DecompressSnapshotFile(compressedFilePath, decompressedFilePath))
+
+	originalBytes, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	decompressedBytes, err := os.ReadFile(decompressedFilePath)
+	require.NoError(t, err)
+	require.Equal(t, originalBytes, decompressedBytes)
+}
+
+func TestCompressedDeltaSnapshot(t *testing.T) {
+
+	deltaHeader := randDeltaSnapshotHeader(50, 150)
+
+	msDiffIterFunc, msDiffGenRetriever := newMsDiffGenerator(deltaHeader.TargetMilestoneIndex, deltaHeader.MilestoneDiffCount, snapshot.MsDiffDirectionOnwards)
+	msDiffConsumerFunc, msDiffCollRetriever := newMsDiffCollector()
+
+	sepIterFunc, sepGenRetriever := newSEPGenerator(deltaHeader.SEPCount)
+	sepConsumerFunc, sepsCollRetriever := newSEPCollector()
+
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "delta_snapshot.bin")
+	compressedFilePath := filepath.Join(tempDir, "delta_snapshot.bin.zst")
+
+	snapshotFileWrite, err := os.OpenFile(filePath, os.O_CREATE|os.O_RDWR, 0666)
+	require.NoError(t, err)
+	_, err = snapshot.StreamDeltaSnapshotDataTo(snapshotFileWrite, deltaHeader, msDiffIterFunc, sepIterFunc)
+	require.NoError(t, err)
+	require.NoError(t, snapshotFileWrite.Close())
+
+	require.NoError(t, snapshot.CompressSnapshotFile(filePath, compressedFilePath))
+
+	// the header can be read from a stream without a seek table, e.g. during a download
+	compressedFile, err := os.Open(compressedFilePath)
+	require.NoError(t, err)
+	defer func() { _ = compressedFile.Close() }()
+
+	streamReader, err := snapshot.NewSnapshotStreamReader(compressedFile)
+	require.NoError(t, err)
+	readHeader, err := snapshot.ReadDeltaSnapshotHeader(streamReader)
+	require.NoError(t, err)
+	require.NoError(t, streamReader.Close())
+	require.Equal(t, deltaHeader.SEPFileOffset, readHeader.SEPFileOffset)
+
+	verified, err := snapshot.VerifySnapshotFileIntegrity(compressedFilePath)
+	require.NoError(t, err)
+	require.True(t, verified)
+
+	snapshotFileRead, err := snapshot.OpenSnapshotFile(compressedFilePath)
+	require.NoError(t, err)
+	defer func() { _ = snapshotFileRead.Close() }()
+
+	protocolStorageGetter := func() (*storage.ProtocolStorage, error) {
+		return getProtocolStorage(protoParams), nil
+	}
+
+	require.NoError(t, snapshot.StreamDeltaSnapshotDataFrom(context.Background(), snapshotFileRead, protocolStorageGetter, deltaHeaderEqualFunc(t, deltaHeader), msDiffConsumerFunc, sepConsumerFunc, newProtocolParamsMilestoneOptConsumerFunc()))
+
+	msDiffGen := msDiffGenRetriever()
+	msDiffCon := msDiffCollRetriever()
+	require.Len(t, msDiffCon, len(msDiffGen))
+	for i := range msDiffGen {
+		equalMilestoneDiff(t, msDiffGen[i], msDiffCon[i])
+	}
+	require.EqualValues(t, sepGenRetriever(), sepsCollRetriever())
+
+	// the compressed snapshot can be seeked in both directions
+	_, err = snapshotFileRead.Seek(deltaHeader.SEPFileOffset, io.SeekStart)
+	require.NoError(t, err)
+	sep := make([]byte, 32)
+	_, err = io.ReadFull(snapshotFileRead, sep)
+	require.NoError(t, err)
+	require.Equal(t, sepGenRetriever()[0][:], sep)
+
+	_, err = snapshotFileRead.Seek(0, io.SeekEnd)
+	require.NoError(t, err)
+	_, err = snapshotFileRead.Read(sep)
+	require.ErrorIs(t, err, io.EOF)
+}
@@ -0,0 +1,266 @@
+//nolint:forcetypeassert,varnamelen,revive,exhaustruct,gosec // we don't care about these linters in test cases
+package snapshot_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/app/configuration"
+	appLogger "github.com/iotaledger/hive.go/app/logger"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hornet/v2/pkg/metrics"
+	"github.com/iotaledger/hornet/v2/pkg/snapshot"
+)
+
+const (
+	fullSnapshotFileName = "full_snapshot.bin"
+	manifestFileName     = "sha256sums.txt"
+)
+
+// abortingResponseWriter aborts the response after the given amount of body bytes was written.
+type abortingResponseWriter struct {
+	http.ResponseWriter
+	remaining int
+}
+
+func (w *abortingResponseWriter) Write(p []byte) (int, error) {
+	if len(p) > w.remaining {
+		_, _ = w.ResponseWriter.Write(p[:w.remaining])
+		if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+			flusher.Flush()
+		}
+
+		// abort the connection to simulate a network failure
+		panic(http.ErrAbortHandler)
+	}
+	w.remaining -= len(p)
+
+	return w.ResponseWriter.Write(p)
+}
+
+// newSnapshotServer serves the given snapshot file and a hash manifest with the given hash.
+// If abortAfterBytes is bigger than 0, every response is aborted after that amount of bytes.
+func newSnapshotServer(t *testing.T, data []byte, manifestHash string, abortAfterBytes int) *httptest.Server {
+	modTime := time.Now()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + manifestFileName:
+			_, _ = fmt.Fprintf(w, "%s  %s\n", manifestHash, fullSnapshotFileName)
+		case "/" + fullSnapshotFileName:
+			if abortAfterBytes > 0 {
+				w = &abortingResponseWriter{ResponseWriter: w, remaining: abortAfterBytes}
+			}
+			http.ServeContent(w, r, fullSnapshotFileName, modTime, bytes.NewReader(data))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newDownloadTarget(server *httptest.Server) *snapshot.DownloadTarget {
+	return &snapshot.DownloadTarget{
+		Full:     server.URL + "/" + fullSnapshotFileName,
+		Manifest: server.URL + "/" + manifestFileName,
+	}
+}
+
+// writeFullSnapshot creates a full snapshot file and returns its content and the network ID.
+func writeFullSnapshot(t *testing.T) ([]byte, uint64) {
+	fullHeader := randFullSnapshotHeader(25000, 5, 10)
+
+	outputIterFunc, _ := newOutputsGenerator(fullHeader.OutputCount)
+	msDiffIterFunc, _ := newMsDiffGenerator(fullHeader.TargetMilestoneIndex, fullHeader.MilestoneDiffCount, snapshot.MsDiffDirectionBackwards)
+	sepIterFunc, _ := newSEPGenerator(fullHeader.SEPCount)
+
+	filePath := filepath.Join(t.TempDir(), fullSnapshotFileName)
+	snapshotFile, err := os.Create(filePath)
+	require.NoError(t, err)
+
+	_, err = snapshot.StreamFullSnapshotDataTo(snapshotFile, fullHeader, outputIterFunc, msDiffIterFunc, sepIterFunc)
+	require.NoError(t, err)
+	require.NoError(t, snapshotFile.Close())
+
+	data, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+
+	protoParams, err := fullHeader.ProtocolParameters()
+	require.NoError(t, err)
+
+	return data, protoParams.NetworkID()
+}
+
+func newDownloadImporter(t *testing.T, downloadMetrics *metrics.SnapshotDownloadMetrics) *snapshot.Importer {
+	cfg := configuration.New()
+	require.NoError(t, cfg.Set("logger.disableStacktrace", true))
+
+	// no need to check the error, since the global logger could already be initialized
+	_ = appLogger.InitGlobalLogger(cfg)
+
+	return snapshot.NewSnapshotImporter(logger.NewLogger("Snapshot"), nil, "", "", "", nil, 4, 1, downloadMetrics)
+}
+
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+
+	return hex.EncodeToString(hash[:])
+}
+
+func TestDownloadSnapshotFilesResume(t *testing.T) {
+	data, networkID := writeFullSnapshot(t)
+	require.Greater(t, len(data), 2*1024*1024, "the snapshot file should be split into several chunks")
+
+	fullPath := filepath.Join(t.TempDir(), fullSnapshotFileName)
+
+	flakyServer := newSnapshotServer(t, data, sha256Hex(data), 64*1024)
+	goodServer := newSnapshotServer(t, data, sha256Hex(data), 0)
+
+	// the flaky server aborts every transfer, so the download fails, but the progress is kept
+	downloadMetrics := &metrics.SnapshotDownloadMetrics{}
+	importer := newDownloadImporter(t, downloadMetrics)
+	err := importer.DownloadSnapshotFiles(context.Background(), networkID, fullPath, "", []*snapshot.DownloadTarget{newDownloadTarget(flakyServer)})
+	require.ErrorIs(t, err, snapshot.ErrSnapshotDownloadNoValidSource)
+	require.NoFileExists(t, fullPath)
+	require.FileExists(t, fullPath+".part")
+	require.FileExists(t, fullPath+".part.json")
+	require.Greater(t, downloadMetrics.Retries.Load(), uint32(0))
+
+	// the download is resumed from the partial file
+	downloadMetrics = &metrics.SnapshotDownloadMetrics{}
+	importer = newDownloadImporter(t, downloadMetrics)
+	require.NoError(t, importer.DownloadSnapshotFiles(context.Background(), networkID, fullPath, "", []*snapshot.DownloadTarget{newDownloadTarget(goodServer)}))
+	require.Greater(t, downloadMetrics.BytesResumed.Load(), uint64(0))
+	require.Less(t, downloadMetrics.BytesResumed.Load(), uint64(len(data)))
+	require.Equal(t, uint64(len(data)), downloadMetrics.BytesExpected.Load())
+	require.Equal(t, uint64(len(data)), downloadMetrics.BytesDownloaded.Load())
+	require.Equal(t, uint32(1), downloadMetrics.FilesDownloaded.Load())
+	require.False(t, downloadMetrics.DownloadRunning.Load())
+
+	downloadedData, err := os.ReadFile(fullPath)
+	require.NoError(t, err)
+	require.Equal(t, data, downloadedData)
+	require.NoFileExists(t, fullPath+".part")
+	require.NoFileExists(t, fullPath+".part.json")
+}
+
+func TestDownloadSnapshotFilesFailover(t *testing.T) {
+	data, networkID := writeFullSnapshot(t)
+
+	fullPath := filepath.Join(t.TempDir(), fullSnapshotFileName)
+
+	flakyServer := newSnapshotServer(t, data, sha256Hex(data), 64*1024)
+	goodServer := newSnapshotServer(t, data, sha256Hex(data), 0)
+
+	downloadMetrics := &metrics.SnapshotDownloadMetrics{}
+	importer := newDownloadImporter(t, downloadMetrics)
+
+	// both targets serve the same snapshot, so the download fails over to the second mirror mid-transfer
+	require.NoError(t, importer.DownloadSnapshotFiles(context.Background(), networkID, fullPath, "", []*snapshot.DownloadTarget{
+		newDownloadTarget(flakyServer),
+		newDownloadTarget(goodServer),
+	}))
+	require.Equal(t, uint32(1), downloadMetrics.Failovers.Load())
+	require.Greater(t, downloadMetrics.BytesResumed.Load(), uint64(0))
+
+	downloadedData, err := os.ReadFile(fullPath)
+	require.NoError(t, err)
+	require.Equal(t, data, downloadedData)
+}
+
+func TestDownloadSnapshotFilesNoResumeWithoutHash(t *testing.T) {
+	data, networkID := writeFullSnapshot(t)
+
+	fullPath := filepath.Join(t.TempDir(), fullSnapshotFileName)
+
+	flakyServer := newSnapshotServer(t, data, sha256Hex(data), 64*1024)
+	goodServer := newSnapshotServer(t, data, sha256Hex(data), 0)
+
+	// the targets don't publish a hash manifest
+	newTargetWithoutManifest := func(server *httptest.Server) *snapshot.DownloadTarget {
+		return &snapshot.DownloadTarget{Full: server.URL + "/" + fullSnapshotFileName}
+	}
+
+	importer := newDownloadImporter(t, &metrics.SnapshotDownloadMetrics{})
+	err := importer.DownloadSnapshotFiles(context.Background(), networkID, fullPath, "", []*snapshot.DownloadTarget{newTargetWithoutManifest(flakyServer)})
+	require.ErrorIs(t, err, snapshot.ErrSnapshotDownloadNoValidSource)
+	require.FileExists(t, fullPath+".part.json")
+
+	// the content of the file on another server can't be verified to be the same, so the download is restarted
+	downloadMetrics := &metrics.SnapshotDownloadMetrics{}
+	importer = newDownloadImporter(t, downloadMetrics)
+	require.NoError(t, importer.DownloadSnapshotFiles(context.Background(), networkID, fullPath, "", []*snapshot.DownloadTarget{newTargetWithoutManifest(goodServer)}))
+	require.Equal(t, uint64(0), downloadMetrics.BytesResumed.Load())
+
+	downloadedData, err := os.ReadFile(fullPath)
+	require.NoError(t, err)
+	require.Equal(t, data, downloadedData)
+}
+
+func TestDownloadSnapshotFilesHashMismatch(t *testing.T) {
+	data, networkID := writeFullSnapshot(t)
+
+	fullPath := filepath.Join(t.TempDir(), fullSnapshotFileName)
+
+	server := newSnapshotServer(t, data, strings.Repeat("00", sha256.Size), 0)
+
+	downloadMetrics := &metrics.SnapshotDownloadMetrics{}
+	importer := newDownloadImporter(t, downloadMetrics)
+
+	err := importer.DownloadSnapshotFiles(context.Background(), networkID, fullPath, "", []*snapshot.DownloadTarget{newDownloadTarget(server)})
+	require.ErrorIs(t, err, snapshot.ErrSnapshotDownloadNoValidSource)
+	require.Equal(t, uint32(1), downloadMetrics.VerificationFailures.Load())
+
+	// the corrupted file must neither be used nor resumed
+	require.NoFileExists(t, fullPath)
+	require.NoFileExists(t, fullPath+".part")
+	require.NoFileExists(t, fullPath+".part.json")
+}
+
+func TestDownloadSnapshotFilesToken(t *testing.T) {
+	data, networkID := writeFullSnapshot(t)
+
+	fullPath := filepath.Join(t.TempDir(), fullSnapshotFileName)
+
+	const token = "secret"
+
+	snapshotServer := newSnapshotServer(t, data, sha256Hex(data), 0)
+
+	// the server only serves requests with a valid bearer token, like the protected snapshot routes of a node
+	protectedServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+		snapshotServer.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(protectedServer.Close)
+
+	importer := newDownloadImporter(t, &metrics.SnapshotDownloadMetrics{})
+	err := importer.DownloadSnapshotFiles(context.Background(), networkID, fullPath, "", []*snapshot.DownloadTarget{newDownloadTarget(protectedServer)})
+	require.ErrorIs(t, err, snapshot.ErrSnapshotDownloadNoValidSource)
+	require.NoFileExists(t, fullPath)
+
+	target := newDownloadTarget(protectedServer)
+	target.Token = token
+	require.NoError(t, importer.DownloadSnapshotFiles(context.Background(), networkID, fullPath, "", []*snapshot.DownloadTarget{target}))
+
+	downloadedData, err := os.ReadFile(fullPath)
+	require.NoError(t, err)
+	require.Equal(t, data, downloadedData)
+}
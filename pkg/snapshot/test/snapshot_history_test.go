@@ -0,0 +1,216 @@
+//nolint:forcetypeassert,varnamelen,revive,exhaustruct,gosec // we don't care about these linters in test cases
+package snapshot_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hornet/v2/pkg/snapshot"
+	"github.com/iotaledger/hornet/v2/pkg/tpkg"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+// writeHistoryTestFullSnapshot writes a small full snapshot file with the given target milestone index to the given path.
+func writeHistoryTestFullSnapshot(t *testing.T, filePath string, targetIndex iotago.MilestoneIndex) iotago.MilestoneID {
+	header := randFullSnapshotHeader(10, 5, 5)
+	header.TargetMilestoneIndex = targetIndex
+	header.TargetMilestoneTimestamp = 1600000000 + targetIndex
+	header.ProtocolParamsMilestoneOpt = tpkg.RandProtocolParamsMilestoneOpt(targetIndex)
+
+	outputIterFunc, _ := newOutputsGenerator(header.OutputCount)
+	msDiffIterFunc, _ := newMsDiffGenerator(targetIndex, header.MilestoneDiffCount, snapshot.MsDiffDirectionBackwards)
+	sepIterFunc, _ := newSEPGenerator(header.SEPCount)
+
+	// full snapshot files are always replaced by new files
+	require.NoError(t, os.RemoveAll(filePath))
+	snapshotFile, err := os.Create(filePath)
+	require.NoError(t, err)
+
+	_, err = snapshot.StreamFullSnapshotDataTo(snapshotFile, header, outputIterFunc, msDiffIterFunc, sepIterFunc)
+	require.NoError(t, err)
+	require.NoError(t, snapshotFile.Close())
+
+	return header.TargetMilestoneID
+}
+
+// writeHistoryTestDeltaSnapshot writes a small delta snapshot file with the given target milestone index to the given path.
+func writeHistoryTestDeltaSnapshot(t *testing.T, filePath string, targetIndex iotago.MilestoneIndex, fullSnapshotTargetMilestoneID iotago.MilestoneID) {
+	header := randDeltaSnapshotHeader(5, 5)
+	header.TargetMilestoneIndex = targetIndex
+	header.TargetMilestoneTimestamp = 1600000000 + targetIndex
+	header.FullSnapshotTargetMilestoneID = fullSnapshotTargetMilestoneID
+
+	msDiffIterFunc, _ := newMsDiffGenerator(targetIndex-header.MilestoneDiffCount, header.MilestoneDiffCount, snapshot.MsDiffDirectionOnwards)
+	sepIterFunc, _ := newSEPGenerator(header.SEPCount)
+
+	snapshotFile, err := os.Create(filePath)
+	require.NoError(t, err)
+
+	_, err = snapshot.StreamDeltaSnapshotDataTo(snapshotFile, header, msDiffIterFunc, sepIterFunc)
+	require.NoError(t, err)
+	require.NoError(t, snapshotFile.Close())
+}
+
+func historyFileNames(manifest *snapshot.HistoryManifest) []string {
+	fileNames := make([]string, 0, len(manifest.Files))
+	for _, file := range manifest.Files {
+		fileNames = append(fileNames, file.FileName)
+	}
+
+	return fileNames
+}
+
+func historyTestFileName(snapshotType string, targetIndex iotago.MilestoneIndex) string {
+	return fmt.Sprintf("%s_snapshot_%d_%s.bin", snapshotType, targetIndex, time.Unix(int64(1600000000+targetIndex), 0).UTC().Format("2006-01-02_15-04-05"))
+}
+
+func TestSnapshotHistoryRetention(t *testing.T) {
+	dir := t.TempDir()
+	fullPath := filepath.Join(dir, "full_snapshot.bin")
+	deltaPath := filepath.Join(dir, "delta_snapshot.bin")
+	historyDir := filepath.Join(dir, "history")
+
+	history, err := snapshot.NewHistory(historyDir, 2)
+	require.NoError(t, err)
+
+	addFull := func(targetIndex iotago.MilestoneIndex) iotago.MilestoneID {
+		fullID := writeHistoryTestFullSnapshot(t, fullPath, targetIndex)
+		_, err := history.Add(snapshot.Full, fullPath)
+		require.NoError(t, err)
+
+		return fullID
+	}
+
+	addDelta := func(targetIndex iotago.MilestoneIndex, fullID iotago.MilestoneID) {
+		writeHistoryTestDeltaSnapshot(t, deltaPath, targetIndex, fullID)
+		_, err := history.Add(snapshot.Delta, deltaPath)
+		require.NoError(t, err)
+	}
+
+	fullID1 := addFull(1000)
+	addDelta(1010, fullID1)
+
+	manifest := history.Manifest()
+	require.Equal(t, []string{historyTestFileName("full", 1000), historyTestFileName("delta", 1010)}, historyFileNames(manifest))
+	require.Equal(t, historyTestFileName("full", 1000), manifest.LatestFull)
+	require.Equal(t, historyTestFileName("delta", 1010), manifest.LatestDelta)
+
+	fullID2 := addFull(2000)
+	addDelta(2010, fullID2)
+	addDelta(2020, fullID2)
+
+	// the new full snapshot does not have a delta snapshot yet
+	fullID3 := addFull(3000)
+
+	manifest = history.Manifest()
+	require.Equal(t, []string{
+		historyTestFileName("full", 2000),
+		historyTestFileName("delta", 2010),
+		historyTestFileName("delta", 2020),
+		historyTestFileName("full", 3000),
+	}, historyFileNames(manifest))
+	require.Equal(t, historyTestFileName("full", 3000), manifest.LatestFull)
+	require.Empty(t, manifest.LatestDelta)
+
+	// files that exceed the retention and deltas of removed full snapshots are deleted
+	require.NoFileExists(t, filepath.Join(historyDir, historyTestFileName("full", 1000)))
+	require.NoFileExists(t, filepath.Join(historyDir, historyTestFileName("delta", 1010)))
+	require.NoFileExists(t, filepath.Join(historyDir, snapshot.HistoryLatestDeltaFileName))
+
+	addDelta(3010, fullID3)
+
+	manifest = history.Manifest()
+	require.Equal(t, []string{
+		historyTestFileName("full", 2000),
+		historyTestFileName("delta", 2020),
+		historyTestFileName("full", 3000),
+		historyTestFileName("delta", 3010),
+	}, historyFileNames(manifest))
+	require.Equal(t, historyTestFileName("delta", 3010), manifest.LatestDelta)
+
+	// the symlinks point to the latest files
+	target, err := os.Readlink(filepath.Join(historyDir, snapshot.HistoryLatestFullFileName))
+	require.NoError(t, err)
+	require.Equal(t, manifest.LatestFull, target)
+
+	target, err = os.Readlink(filepath.Join(historyDir, snapshot.HistoryLatestDeltaFileName))
+	require.NoError(t, err)
+	require.Equal(t, manifest.LatestDelta, target)
+
+	// the history is restored from the manifest
+	restoredHistory, err := snapshot.NewHistory(historyDir, 2)
+	require.NoError(t, err)
+	require.Equal(t, manifest, restoredHistory.Manifest())
+}
+
+func TestSnapshotHistoryFiles(t *testing.T) {
+	dir := t.TempDir()
+	fullPath := filepath.Join(dir, "full_snapshot.bin")
+	deltaPath := filepath.Join(dir, "delta_snapshot.bin")
+	historyDir := filepath.Join(dir, "history")
+
+	history, err := snapshot.NewHistory(historyDir, 5)
+	require.NoError(t, err)
+
+	_, err = history.FilePath(snapshot.HistoryLatestFullFileName)
+	require.ErrorIs(t, err, snapshot.ErrHistoryFileNotFound)
+
+	fullID := writeHistoryTestFullSnapshot(t, fullPath, 1000)
+	fullFile, err := history.Add(snapshot.Full, fullPath)
+	require.NoError(t, err)
+	require.Equal(t, "full", fullFile.Type)
+	require.Equal(t, iotago.MilestoneIndex(1000), fullFile.TargetMilestoneIndex)
+	require.Equal(t, fullID.ToHex(), fullFile.TargetMilestoneID)
+
+	writeHistoryTestDeltaSnapshot(t, deltaPath, 1010, fullID)
+	deltaFile, err := history.Add(snapshot.Delta, deltaPath)
+	require.NoError(t, err)
+	require.Equal(t, fullID.ToHex(), deltaFile.FullSnapshotTargetMilestoneID)
+
+	// the delta snapshot file is extended in place by the node, the history keeps a copy
+	writeHistoryTestDeltaSnapshot(t, deltaPath, 1020, fullID)
+
+	for _, file := range []*snapshot.HistoryFile{fullFile, deltaFile} {
+		filePath, err := history.FilePath(file.FileName)
+		require.NoError(t, err)
+
+		fileBytes, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+
+		hash := sha256.Sum256(fileBytes)
+		require.Equal(t, hex.EncodeToString(hash[:]), file.SHA256)
+		require.Equal(t, int64(len(fileBytes)), file.Size)
+	}
+
+	// the latest file names are resolved to the actual files
+	filePath, err := history.FilePath(snapshot.HistoryLatestFullFileName)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(historyDir, fullFile.FileName), filePath)
+
+	filePath, err = history.FilePath(snapshot.HistoryLatestDeltaFileName)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(historyDir, deltaFile.FileName), filePath)
+
+	// only files of the history can be accessed
+	_, err = history.FilePath(snapshot.HistoryManifestFileName)
+	require.ErrorIs(t, err, snapshot.ErrHistoryFileNotFound)
+	_, err = history.FilePath("../full_snapshot.bin")
+	require.ErrorIs(t, err, snapshot.ErrHistoryFileNotFound)
+
+	// the hash manifest contains the files and the latest file names
+	hashManifestBytes, err := os.ReadFile(filepath.Join(historyDir, snapshot.HistoryHashManifestFileName))
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("%s  %s\n%s  %s\n%s  %s\n%s  %s\n",
+		fullFile.SHA256, fullFile.FileName,
+		fullFile.SHA256, snapshot.HistoryLatestFullFileName,
+		deltaFile.SHA256, deltaFile.FileName,
+		deltaFile.SHA256, snapshot.HistoryLatestDeltaFileName,
+	), string(hashManifestBytes))
+}
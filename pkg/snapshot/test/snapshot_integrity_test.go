@@ -0,0 +1,214 @@
+//nolint:forcetypeassert,varnamelen,revive,exhaustruct,gosec // we don't care about these linters in test cases
+package snapshot_test
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/blang/vfs"
+	"github.com/blang/vfs/memfs"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hornet/v2/pkg/snapshot"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+func writeFullSnapshotFile(t *testing.T, fs vfs.Filesystem, filePath string, header *snapshot.FullSnapshotHeader) {
+	outputIterFunc, _ := newOutputsGenerator(header.OutputCount)
+	msDiffIterFunc, _ := newMsDiffGenerator(header.TargetMilestoneIndex, header.MilestoneDiffCount, snapshot.MsDiffDirectionBackwards)
+	sepIterFunc, _ := newSEPGenerator(header.SEPCount)
+
+	snapshotFileWrite, err := fs.OpenFile(filePath, os.O_CREATE|os.O_RDWR, 0666)
+	require.NoError(t, err)
+
+	_, err = snapshot.StreamFullSnapshotDataTo(snapshotFileWrite, header, outputIterFunc, msDiffIterFunc, sepIterFunc)
+	require.NoError(t, err)
+	require.NoError(t, snapshotFileWrite.Close())
+}
+
+func verifySnapshotFileIntegrity(t *testing.T, fs vfs.Filesystem, filePath string) (bool, error) {
+	snapshotFileRead, err := fs.OpenFile(filePath, os.O_RDONLY, 0666)
+	require.NoError(t, err)
+	defer func() { _ = snapshotFileRead.Close() }()
+
+	return snapshot.VerifySnapshotIntegrity(snapshotFileRead)
+}
+
+func modifySnapshotFile(t *testing.T, fs vfs.Filesystem, filePath string, modify func(file vfs.File, size int64)) {
+	fileInfo, err := fs.Stat(filePath)
+	require.NoError(t, err)
+
+	snapshotFile, err := fs.OpenFile(filePath, os.O_RDWR, 0666)
+	require.NoError(t, err)
+
+	modify(snapshotFile, fileInfo.Size())
+	require.NoError(t, snapshotFile.Close())
+}
+
+func flipByte(t *testing.T, offset int64) func(file vfs.File, size int64) {
+	return func(file vfs.File, _ int64) {
+		value := make([]byte, 1)
+		_, err := file.Seek(offset, io.SeekStart)
+		require.NoError(t, err)
+		_, err = io.ReadFull(file, value)
+		require.NoError(t, err)
+
+		value[0] ^= 0xFF
+		_, err = file.Seek(offset, io.SeekStart)
+		require.NoError(t, err)
+		_, err = file.Write(value)
+		require.NoError(t, err)
+	}
+}
+
+func TestFullSnapshotIntegrity(t *testing.T) {
+
+	fullHeader := randFullSnapshotHeader(100, 5, 10)
+
+	fs := memfs.Create()
+	writeFullSnapshotFile(t, fs, "full_snapshot.bin", fullHeader)
+
+	verified, err := verifySnapshotFileIntegrity(t, fs, "full_snapshot.bin")
+	require.NoError(t, err)
+	require.True(t, verified)
+
+	snapshotFileRead, err := fs.OpenFile("full_snapshot.bin", os.O_RDONLY, 0666)
+	require.NoError(t, err)
+	sections, trailerOffset, err := snapshot.ReadIntegrityTrailer(snapshotFileRead)
+	require.NoError(t, err)
+	require.NoError(t, snapshotFileRead.Close())
+
+	require.Len(t, sections, 5)
+	require.Equal(t, snapshot.IntegritySectionHeader, sections[0].Type)
+	require.Equal(t, snapshot.IntegritySectionProtocolParameters, sections[1].Type)
+	require.Equal(t, snapshot.IntegritySectionOutputs, sections[2].Type)
+	require.Equal(t, snapshot.IntegritySectionMilestoneDiffs, sections[3].Type)
+	require.Equal(t, snapshot.IntegritySectionSolidEntryPoints, sections[4].Type)
+	require.Equal(t, trailerOffset, sections[4].Offset+sections[4].Length)
+
+	snapshotBytes, err := vfs.ReadFile(fs, "full_snapshot.bin")
+	require.NoError(t, err)
+
+	// every check works on an unmodified copy of the snapshot file
+	copySnapshotFile := func() *memfs.MemFS {
+		fs := memfs.Create()
+		require.NoError(t, vfs.WriteFile(fs, "full_snapshot.bin", snapshotBytes, 0666))
+
+		return fs
+	}
+
+	for _, section := range sections {
+		fs := copySnapshotFile()
+
+		// a modified byte in any section is detected
+		modifySnapshotFile(t, fs, "full_snapshot.bin", flipByte(t, section.Offset+section.Length-1))
+
+		_, err := verifySnapshotFileIntegrity(t, fs, "full_snapshot.bin")
+		require.ErrorIs(t, err, snapshot.ErrSnapshotIntegrityCheckFailed, "section: %s", section.Type)
+	}
+
+	// a modified trailer is detected
+	fs = copySnapshotFile()
+	modifySnapshotFile(t, fs, "full_snapshot.bin", flipByte(t, trailerOffset))
+	_, err = verifySnapshotFileIntegrity(t, fs, "full_snapshot.bin")
+	require.ErrorIs(t, err, snapshot.ErrSnapshotIntegrityCheckFailed)
+
+	// a truncated file is detected
+	fs = copySnapshotFile()
+	modifySnapshotFile(t, fs, "full_snapshot.bin", func(file vfs.File, size int64) {
+		require.NoError(t, file.Truncate(size-1))
+	})
+	_, err = verifySnapshotFileIntegrity(t, fs, "full_snapshot.bin")
+	require.ErrorIs(t, err, snapshot.ErrSnapshotIntegrityCheckFailed)
+}
+
+func TestDeltaSnapshotIntegrity(t *testing.T) {
+
+	deltaHeader := randDeltaSnapshotHeader(5, 10)
+	snapshotExtensionGenerator, _ := newDeltaSnapshotExtensionGenerator(deltaHeader, 3, 5, 10)
+
+	fs := memfs.Create()
+
+	msDiffGen, sepGen := snapshotExtensionGenerator()
+	snapshotFileWrite, err := fs.OpenFile("delta_snapshot.bin", os.O_CREATE|os.O_RDWR, 0666)
+	require.NoError(t, err)
+	_, err = snapshot.StreamDeltaSnapshotDataTo(snapshotFileWrite, deltaHeader, msDiffGen, sepGen)
+	require.NoError(t, err)
+	require.NoError(t, snapshotFileWrite.Close())
+
+	verified, err := verifySnapshotFileIntegrity(t, fs, "delta_snapshot.bin")
+	require.NoError(t, err)
+	require.True(t, verified)
+
+	// the integrity trailer is updated if an existing delta snapshot is extended
+	for {
+		msDiffGen, sepGen := snapshotExtensionGenerator()
+		if msDiffGen == nil || sepGen == nil {
+			break
+		}
+
+		snapshotFileWrite, err := fs.OpenFile("delta_snapshot.bin", os.O_RDWR, 0666)
+		require.NoError(t, err)
+
+		deltaHeader.TargetMilestoneIndex++
+		deltaHeader.TargetMilestoneTimestamp++
+
+		_, err = snapshot.StreamDeltaSnapshotDataToExisting(snapshotFileWrite, deltaHeader, msDiffGen, sepGen)
+		require.NoError(t, err)
+		require.NoError(t, snapshotFileWrite.Close())
+
+		verified, err := verifySnapshotFileIntegrity(t, fs, "delta_snapshot.bin")
+		require.NoError(t, err)
+		require.True(t, verified)
+	}
+
+	// a modified milestone diff is detected
+	modifySnapshotFile(t, fs, "delta_snapshot.bin", flipByte(t, deltaHeader.SEPFileOffset-1))
+	_, err = verifySnapshotFileIntegrity(t, fs, "delta_snapshot.bin")
+	require.ErrorIs(t, err, snapshot.ErrSnapshotIntegrityCheckFailed)
+
+	corruptedSnapshotBytes, err := vfs.ReadFile(fs, "delta_snapshot.bin")
+	require.NoError(t, err)
+
+	// a corrupted delta snapshot is not extended, so the corruption is not covered by a new integrity trailer
+	snapshotFileWrite, err = fs.OpenFile("delta_snapshot.bin", os.O_RDWR, 0666)
+	require.NoError(t, err)
+
+	deltaHeader.TargetMilestoneIndex++
+	deltaHeader.TargetMilestoneTimestamp++
+
+	_, err = snapshot.StreamDeltaSnapshotDataToExisting(snapshotFileWrite, deltaHeader,
+		func() (*snapshot.MilestoneDiff, error) { return nil, nil },
+		func() (iotago.BlockID, error) { return iotago.EmptyBlockID(), snapshot.ErrNoMoreSEPToProduce },
+	)
+	require.ErrorIs(t, err, snapshot.ErrSnapshotIntegrityCheckFailed)
+	require.NoError(t, snapshotFileWrite.Close())
+
+	snapshotBytes, err := vfs.ReadFile(fs, "delta_snapshot.bin")
+	require.NoError(t, err)
+	require.Equal(t, corruptedSnapshotBytes, snapshotBytes)
+}
+
+func TestSnapshotIntegrityPreviousFormatVersion(t *testing.T) {
+
+	fullHeader := randFullSnapshotHeader(100, 5, 10)
+	fullHeader.Version = snapshot.MinSupportedFormatVersion
+
+	fs := memfs.Create()
+	writeFullSnapshotFile(t, fs, "full_snapshot.bin", fullHeader)
+
+	// snapshot files without an integrity trailer can still be read, but not be verified
+	verified, err := verifySnapshotFileIntegrity(t, fs, "full_snapshot.bin")
+	require.NoError(t, err)
+	require.False(t, verified)
+
+	snapshotFileRead, err := fs.OpenFile("full_snapshot.bin", os.O_RDONLY, 0666)
+	require.NoError(t, err)
+	defer func() { _ = snapshotFileRead.Close() }()
+
+	readHeader, err := snapshot.ReadFullSnapshotHeader(snapshotFileRead)
+	require.NoError(t, err)
+	require.Equal(t, snapshot.MinSupportedFormatVersion, readHeader.Version)
+	require.Equal(t, fullHeader.OutputCount, readHeader.OutputCount)
+}
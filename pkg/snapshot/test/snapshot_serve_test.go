@@ -0,0 +1,85 @@
+//nolint:forcetypeassert,varnamelen,revive,exhaustruct,gosec // we don't care about these linters in test cases
+package snapshot_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/app/configuration"
+	appLogger "github.com/iotaledger/hive.go/app/logger"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hornet/v2/pkg/snapshot"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+func newServeManager(t *testing.T, fullPath string, deltaPath string) *snapshot.Manager {
+	cfg := configuration.New()
+	require.NoError(t, cfg.Set("logger.disableStacktrace", true))
+
+	// no need to check the error, since the global logger could already be initialized
+	_ = appLogger.InitGlobalLogger(cfg)
+
+	return snapshot.NewSnapshotManager(logger.NewLogger("Snapshot"), nil, nil, nil, false, fullPath, deltaPath, false, 0, 0, 0, 0, 0, 0, nil)
+}
+
+func TestSnapshotServedFiles(t *testing.T) {
+	dir := t.TempDir()
+	fullPath := filepath.Join(dir, "full_snapshot.bin")
+	deltaPath := filepath.Join(dir, "delta_snapshot.bin")
+
+	manager := newServeManager(t, fullPath, deltaPath)
+
+	_, err := manager.OpenSnapshotFile(snapshot.Full)
+	require.ErrorIs(t, err, snapshot.ErrSnapshotFileNotAvailable)
+
+	fullID := writeHistoryTestFullSnapshot(t, fullPath, 1000)
+	writeHistoryTestDeltaSnapshot(t, deltaPath, 1010, fullID)
+
+	fullFile, err := manager.OpenSnapshotFile(snapshot.Full)
+	require.NoError(t, err)
+	defer func() { _ = fullFile.Close() }()
+
+	fullHeader, err := fullFile.FullHeader()
+	require.NoError(t, err)
+	require.Equal(t, iotago.MilestoneIndex(1000), fullHeader.TargetMilestoneIndex)
+	require.Equal(t, fullID, fullHeader.TargetMilestoneID)
+
+	_, err = fullFile.DeltaHeader()
+	require.Error(t, err)
+
+	compressed, err := fullFile.IsCompressed()
+	require.NoError(t, err)
+	require.False(t, compressed)
+
+	// reading the header doesn't change the offset of the served file
+	fullBytes, err := io.ReadAll(fullFile)
+	require.NoError(t, err)
+
+	expectedFullBytes, err := os.ReadFile(fullPath)
+	require.NoError(t, err)
+	require.Equal(t, expectedFullBytes, fullBytes)
+	require.Equal(t, int64(len(expectedFullBytes)), fullFile.Info().Size())
+
+	deltaFile, err := manager.OpenSnapshotFile(snapshot.Delta)
+	require.NoError(t, err)
+
+	deltaHeader, err := deltaFile.DeltaHeader()
+	require.NoError(t, err)
+	require.Equal(t, iotago.MilestoneIndex(1010), deltaHeader.TargetMilestoneIndex)
+	require.Equal(t, fullID, deltaHeader.FullSnapshotTargetMilestoneID)
+
+	// closing the file multiple times is allowed
+	require.NoError(t, deltaFile.Close())
+	require.NoError(t, deltaFile.Close())
+
+	// the served file is not affected if the full snapshot file is replaced
+	writeHistoryTestFullSnapshot(t, fullPath, 2000)
+
+	fullHeader, err = fullFile.FullHeader()
+	require.NoError(t, err)
+	require.Equal(t, iotago.MilestoneIndex(1000), fullHeader.TargetMilestoneIndex)
+}
@@ -0,0 +1,153 @@
+package tangle
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/serializer/v2"
+	"github.com/iotaledger/hornet/v2/pkg/model/storage"
+	"github.com/iotaledger/hornet/v2/pkg/whiteflag"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+// BlockValidationCheck is the name of the check that failed during block validation.
+type BlockValidationCheck string
+
+const (
+	// BlockValidationCheckProtocolVersion checks that the block uses the current protocol version.
+	BlockValidationCheckProtocolVersion BlockValidationCheck = "protocolVersion"
+	// BlockValidationCheckParents checks that the block has parents or that the node is able to select them.
+	BlockValidationCheckParents BlockValidationCheck = "parents"
+	// BlockValidationCheckNetworkID checks that the transaction of the block uses the network ID of the node.
+	BlockValidationCheckNetworkID BlockValidationCheck = "networkID"
+	// BlockValidationCheckPoW checks that the block fulfills the minimum PoW score or that the node is able to do the PoW.
+	BlockValidationCheckPoW BlockValidationCheck = "pow"
+	// BlockValidationCheckSyntactic checks that the block is syntactically valid.
+	BlockValidationCheckSyntactic BlockValidationCheck = "syntactic"
+	// BlockValidationCheckLedger checks that the transaction of the block is valid against the current ledger state.
+	BlockValidationCheckLedger BlockValidationCheck = "ledger"
+)
+
+// BlockValidationFailure describes a single reason why a block is invalid.
+type BlockValidationFailure struct {
+	// Check is the name of the check that failed.
+	Check BlockValidationCheck
+	// Conflict is the conflict reason the white flag confirmation would assign to the transaction of the block.
+	// It is only set for failures of the ledger check.
+	Conflict storage.Conflict
+	// Err is the underlying error.
+	Err error
+}
+
+// ValidateBlock runs the same checks on the block that would be applied if it was attached
+// and confirmed by the next milestone, but the block is neither stored nor gossiped.
+// Instead of stopping at the first error, all failed checks are returned.
+// If no parents are given, the parents are selected by the tip selection of the node.
+// The given block is not modified.
+func (a *BlockAttacher) ValidateBlock(ctx context.Context, block *iotago.Block) ([]*BlockValidationFailure, error) {
+
+	// work on a copy, so that selecting the parents has no side effects on the given block
+	iotaBlock := &iotago.Block{
+		ProtocolVersion: block.ProtocolVersion,
+		Parents:         block.Parents,
+		Payload:         block.Payload,
+		Nonce:           block.Nonce,
+	}
+
+	failures := make([]*BlockValidationFailure, 0)
+	addFailure := func(check BlockValidationCheck, err error) {
+		failures = append(failures, &BlockValidationFailure{
+			Check:    check,
+			Conflict: storage.ConflictNone,
+			Err:      err,
+		})
+	}
+
+	protoParams := a.tangle.protocolManager.Current()
+
+	if iotaBlock.ProtocolVersion != protoParams.Version {
+		addFailure(BlockValidationCheckProtocolVersion, errors.Errorf("protocolVersion invalid: %d, expected: %d", iotaBlock.ProtocolVersion, protoParams.Version))
+	}
+
+	if len(iotaBlock.Parents) == 0 {
+		switch {
+		case iotaBlock.Nonce != 0:
+			addFailure(BlockValidationCheckParents, errors.New("no parents were given but nonce was != 0"))
+
+		case a.opts.tipSelFunc == nil:
+			addFailure(BlockValidationCheckParents, errors.New("no parents given and node tipselection disabled"))
+
+		default:
+			tips, err := a.opts.tipSelFunc()
+			if err != nil {
+				return nil, errors.WithMessagef(ErrBlockAttacherAttachingNotPossible, "tipselection failed, error: %s", err.Error())
+			}
+
+			iotaBlock.Parents = tips
+		}
+	}
+
+	if transaction, ok := iotaBlock.Payload.(*iotago.Transaction); ok && transaction.Essence != nil {
+		if transaction.Essence.NetworkID != protoParams.NetworkID() {
+			addFailure(BlockValidationCheckNetworkID, errors.Errorf("wrong networkID: %d, expected: %d", transaction.Essence.NetworkID, protoParams.NetworkID()))
+		}
+	}
+
+	// milestones are not required to fulfill the minimum PoW score
+	if _, isMilestone := iotaBlock.Payload.(*iotago.Milestone); !isMilestone && protoParams.MinPoWScore != 0 {
+		score, err := iotaBlock.POW()
+		switch {
+		case err != nil:
+			addFailure(BlockValidationCheckPoW, err)
+
+		case score >= float64(protoParams.MinPoWScore):
+			// enough PoW was done
+
+		case iotaBlock.Nonce != 0:
+			addFailure(BlockValidationCheckPoW, errors.Errorf("insufficient PoW score: %0.2f, expected: %d", score, protoParams.MinPoWScore))
+
+		case a.opts.powHandler == nil:
+			addFailure(BlockValidationCheckPoW, ErrBlockAttacherPoWNotAvailable)
+		}
+	}
+
+	if _, err := iotaBlock.Serialize(serializer.DeSeriModePerformValidation, protoParams); err != nil {
+		addFailure(BlockValidationCheckSyntactic, err)
+
+		// the semantic validation relies on a syntactically valid block
+		return failures, nil
+	}
+
+	transaction, ok := iotaBlock.Payload.(*iotago.Transaction)
+	if !ok {
+		return failures, nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	utxoManager := a.tangle.storage.UTXOManager()
+
+	utxoManager.ReadLockLedger()
+	defer utxoManager.ReadUnlockLedger()
+
+	// the transaction would be confirmed by one of the next milestones,
+	// so we use the current time as the timestamp of the confirming milestone.
+	conflicts, err := whiteflag.ValidateTransaction(utxoManager, transaction, uint32(time.Now().Unix()))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, conflict := range conflicts {
+		failures = append(failures, &BlockValidationFailure{
+			Check:    BlockValidationCheckLedger,
+			Conflict: conflict.Conflict,
+			Err:      conflict.Err,
+		})
+	}
+
+	return failures, nil
+}
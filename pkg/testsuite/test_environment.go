@@ -233,6 +233,10 @@ func (te *TestEnvironment) ProtocolManager() *protocol.Manager {
 	return te.protocolManager
 }
 
+func (te *TestEnvironment) MilestoneManager() *milestonemanager.MilestoneManager {
+	return te.milestoneManager
+}
+
 func (te *TestEnvironment) BelowMaxDepth() iotago.MilestoneIndex {
 	return te.belowMaxDepth
 }
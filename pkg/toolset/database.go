@@ -10,7 +10,6 @@ import (
 
 	hivedb "github.com/iotaledger/hive.go/kvstore/database"
 	"github.com/iotaledger/hive.go/runtime/ioutils"
-	"github.com/iotaledger/hive.go/serializer/v2"
 	databasecore "github.com/iotaledger/hornet/v2/components/database"
 	"github.com/iotaledger/hornet/v2/components/protocfg"
 	"github.com/iotaledger/hornet/v2/pkg/common"
@@ -18,7 +17,6 @@ import (
 	"github.com/iotaledger/hornet/v2/pkg/model/milestonemanager"
 	"github.com/iotaledger/hornet/v2/pkg/model/storage"
 	"github.com/iotaledger/hornet/v2/pkg/snapshot"
-	"github.com/iotaledger/inx-app/pkg/httpserver"
 	iotago "github.com/iotaledger/iota.go/v3"
 )
 
@@ -108,43 +106,6 @@ func getStorageMilestoneRange(tangleStore *storage.Storage) (iotago.MilestoneInd
 	return msIndexStart, msIndexEnd
 }
 
-type StoreBlockInterface interface {
-	StoreBlockIfAbsent(block *storage.Block) (cachedBlock *storage.CachedBlock, newlyAdded bool)
-	StoreChild(parentBlockID iotago.BlockID, childBlockID iotago.BlockID) *storage.CachedChild
-	StoreMilestoneIfAbsent(milestonePayload *iotago.Milestone) (*storage.CachedMilestone, bool)
-}
-
-// storeBlock adds a new block to the storage,
-// including all additional information like
-// metadata, children, indexation and milestone entries.
-// block +1.
-func storeBlock(protoParams *iotago.ProtocolParameters, dbStorage StoreBlockInterface, milestoneManager *milestonemanager.MilestoneManager, blk *iotago.Block) (*storage.CachedBlock, error) {
-
-	block, err := storage.NewBlock(blk, serializer.DeSeriModePerformValidation, protoParams)
-	if err != nil {
-		return nil, errors.WithMessagef(httpserver.ErrInvalidParameter, "invalid block, error: %s", err)
-	}
-
-	cachedBlock, isNew := dbStorage.StoreBlockIfAbsent(block) // block +1
-	if !isNew {
-		// no need to process known blocks
-		return cachedBlock, nil
-	}
-
-	for _, parent := range block.Parents() {
-		dbStorage.StoreChild(parent, cachedBlock.Block().BlockID()).Release(true) // child +-0
-	}
-
-	if milestonePayload := milestoneManager.VerifyMilestoneBlock(block.Block()); milestonePayload != nil {
-		cachedMilestone, _ := dbStorage.StoreMilestoneIfAbsent(milestonePayload) // milestone +1
-
-		// Force release to store milestones without caching
-		cachedMilestone.Release(true) // milestone -1
-	}
-
-	return cachedBlock, nil
-}
-
 // getTangleStorage returns a tangle storage. If specified, it checks if the database exists,
 // splits old databases and checks for database health or marks it as tainted if not healthy.
 func getTangleStorage(path string,
@@ -0,0 +1,134 @@
+package toolset
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/iotaledger/hive.go/app/configuration"
+	hivedb "github.com/iotaledger/hive.go/kvstore/database"
+	"github.com/iotaledger/hive.go/runtime/ioutils"
+	coreDatabase "github.com/iotaledger/hornet/v2/components/database"
+	"github.com/iotaledger/hornet/v2/pkg/backup"
+	"github.com/iotaledger/hornet/v2/pkg/database"
+	"github.com/iotaledger/hornet/v2/pkg/model/storage"
+)
+
+func databaseBackup(args []string) error {
+
+	fs := configuration.NewUnsortedFlagSet("", flag.ContinueOnError)
+	databasePathFlag := fs.String(FlagToolDatabasePath, DefaultValueMainnetDatabasePath, "the path to the database")
+	databaseEngineFlag := fs.String(FlagToolDatabaseEngine, string(hivedb.EngineAuto), "the engine of the database (optional, values: pebble, rocksdb, auto)")
+	outputPathFlag := fs.String(FlagToolOutputPath, "backups", "the path to the folder in which the backups are stored")
+	maxBackupsFlag := fs.Int(FlagToolDatabaseMaxBackups, 0, "the maximum amount of backups to keep (optional, 0 = keep all backups)")
+	outputJSONFlag := fs.Bool(FlagToolOutputJSON, false, FlagToolDescriptionOutputJSON)
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s:\n", ToolDatabaseBackup)
+		fs.PrintDefaults()
+		println(fmt.Sprintf("\nexample: %s --%s %s --%s %s --%s %d",
+			ToolDatabaseBackup,
+			FlagToolDatabasePath,
+			DefaultValueMainnetDatabasePath,
+			FlagToolOutputPath,
+			"backups",
+			FlagToolDatabaseMaxBackups,
+			3))
+	}
+
+	if err := parseFlagSet(fs, args); err != nil {
+		return err
+	}
+
+	if len(*databasePathFlag) == 0 {
+		return fmt.Errorf("'%s' not specified", FlagToolDatabasePath)
+	}
+	if len(*outputPathFlag) == 0 {
+		return fmt.Errorf("'%s' not specified", FlagToolOutputPath)
+	}
+
+	dbEngine, err := hivedb.EngineFromStringAllowed(*databaseEngineFlag, database.AllowedEnginesStorageAuto)
+	if err != nil {
+		return err
+	}
+
+	databasePath := *databasePathFlag
+	databaseExists, err := ioutils.DirExistsAndIsNotEmpty(databasePath)
+	if err != nil {
+		return err
+	}
+	if !databaseExists {
+		return fmt.Errorf("database does not exist (%s)", databasePath)
+	}
+
+	tangleDatabase, err := database.DatabaseWithDefaultSettings(filepath.Join(databasePath, coreDatabase.TangleDatabaseDirectoryName), false, dbEngine, database.AllowedEnginesStorageAuto...)
+	if err != nil {
+		return fmt.Errorf("tangle database initialization failed: %w", err)
+	}
+
+	utxoDatabase, err := database.DatabaseWithDefaultSettings(filepath.Join(databasePath, coreDatabase.UTXODatabaseDirectoryName), false, dbEngine, database.AllowedEnginesStorageAuto...)
+	if err != nil {
+		return fmt.Errorf("utxo database initialization failed: %w", err)
+	}
+
+	dbStorage, err := storage.New(tangleDatabase.KVStore(), utxoDatabase.KVStore())
+	if err != nil {
+		return fmt.Errorf("storage initialization failed: %w", err)
+	}
+	defer func() { _ = dbStorage.Shutdown() }()
+
+	if err := checkSnapshotInfo(dbStorage); err != nil {
+		return err
+	}
+
+	backupManager := backup.NewBackupManager(
+		dbStorage,
+		map[string]*database.Database{
+			coreDatabase.TangleDatabaseDirectoryName: tangleDatabase,
+			coreDatabase.UTXODatabaseDirectoryName:   utxoDatabase,
+		},
+		*outputPathFlag,
+		*maxBackupsFlag,
+		// the databases are opened exclusively by the tool, so they are not in use by a running node.
+		true,
+	)
+
+	ts := time.Now()
+
+	if !*outputJSONFlag {
+		fmt.Printf("creating backup of the database ... (source: \"%s\", target: \"%s\")\n", databasePath, *outputPathFlag)
+	}
+
+	info, err := backupManager.CreateBackup()
+	if err != nil {
+		return err
+	}
+
+	if *outputJSONFlag {
+		return printJSON(info)
+	}
+
+	fmt.Printf(`    >
+        - Name:           %s
+        - Path:           %s
+        - Engine:         %s
+        - Ledger index:   %d
+        - Snapshot index: %d
+        - Pruning index:  %d
+        - Quiesced:       %s`+"\n\n",
+		info.Name,
+		info.Path,
+		info.Engine,
+		info.LedgerIndex,
+		info.SnapshotIndex,
+		info.PruningIndex,
+		yesOrNo(info.Quiesced),
+	)
+
+	fmt.Printf("creating backup of the database ... done. took: %v\n", time.Since(ts).Truncate(time.Millisecond))
+
+	return nil
+}
@@ -10,16 +10,11 @@ import (
 	flag "github.com/spf13/pflag"
 
 	"github.com/iotaledger/hive.go/app/configuration"
-	"github.com/iotaledger/hive.go/kvstore"
 	hivedb "github.com/iotaledger/hive.go/kvstore/database"
-	"github.com/iotaledger/hive.go/runtime/contextutils"
-	"github.com/iotaledger/hornet/v2/pkg/common"
-	"github.com/iotaledger/hornet/v2/pkg/dag"
+	"github.com/iotaledger/hornet/v2/pkg/merger"
 	"github.com/iotaledger/hornet/v2/pkg/model/milestonemanager"
 	"github.com/iotaledger/hornet/v2/pkg/model/storage"
-	"github.com/iotaledger/hornet/v2/pkg/model/utxo"
 	"github.com/iotaledger/hornet/v2/pkg/protocol"
-	"github.com/iotaledger/hornet/v2/pkg/whiteflag"
 	iotago "github.com/iotaledger/iota.go/v3"
 	"github.com/iotaledger/iota.go/v3/nodeclient"
 )
@@ -171,190 +166,6 @@ func databaseMerge(args []string) error {
 	return nil
 }
 
-// copyMilestoneCone copies all blocks of a milestone cone to the target storage.
-func copyMilestoneCone(
-	ctx context.Context,
-	protoParams *iotago.ProtocolParameters,
-	msIndex iotago.MilestoneIndex,
-	milestonePayload *iotago.Milestone,
-	parentsTraverserInterface dag.ParentsTraverserInterface,
-	cachedBlockFuncSource storage.CachedBlockFunc,
-	storeBlockTarget StoreBlockInterface,
-	milestoneManager *milestonemanager.MilestoneManager) error {
-
-	// traversal stops if no more blocks pass the given condition
-	// Caution: condition func is not in DFS order
-	condition := func(cachedBlockMeta *storage.CachedMetadata) (bool, error) { // meta +1
-		defer cachedBlockMeta.Release(true) // meta -1
-
-		// collect all blocks that were referenced by that milestone
-		referenced, at := cachedBlockMeta.Metadata().ReferencedWithIndex()
-
-		if referenced {
-			if at > msIndex {
-				return false, fmt.Errorf("milestone cone inconsistent (msIndex: %d, referencedAt: %d)", msIndex, at)
-			}
-
-			if at < msIndex {
-				// do not traverse blocks that were referenced by an older milestonee
-				return false, nil
-			}
-		}
-		blockID := cachedBlockMeta.Metadata().BlockID()
-		cachedBlock, err := cachedBlockFuncSource(blockID) // block +1
-		if err != nil {
-			return false, err
-		}
-		if cachedBlock == nil {
-			return false, fmt.Errorf("block not found: %s", blockID.ToHex())
-		}
-		defer cachedBlock.Release(true) // block -1
-
-		// store the block in the target storage
-		cachedBlockNew, err := storeBlock(protoParams, storeBlockTarget, milestoneManager, cachedBlock.Block().Block()) // block +1
-		if err != nil {
-			return false, err
-		}
-		defer cachedBlockNew.Release(true) // block -1
-
-		cachedBlockMetaNew := cachedBlockNew.CachedMetadata() // meta +1
-		defer cachedBlockMetaNew.Release(true)                // meta -1
-
-		// we need to mark all blocks that contain a milestone payload,
-		// but we can not trust the metadata of the parentsTraverserInterface for correct info about milestones
-		// because it could be a proxystorage, which doesn't know the correct milestones yet.
-		if cachedBlockNew.Block().IsMilestone() {
-			milestonePayload := milestoneManager.VerifyMilestonePayload(cachedBlockNew.Block().Milestone())
-			if milestonePayload != nil {
-				cachedBlockMetaNew.Metadata().SetMilestone(true)
-			}
-		}
-
-		// set the new block as solid
-		cachedBlockMetaNew.Metadata().SetSolid(true)
-
-		return true, nil
-	}
-
-	// traverse the milestone and collect all blocks that were referenced by this milestone or newer
-	if err := parentsTraverserInterface.Traverse(
-		ctx,
-		milestonePayload.Parents,
-		condition,
-		nil,
-		// called on missing parents
-		// return error on missing parents
-		nil,
-		// called on solid entry points
-		// Ignore solid entry points (snapshot milestone included)
-		nil,
-		false); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-type confStats struct {
-	msIndex              iotago.MilestoneIndex
-	blocksReferenced     int
-	durationCopy         time.Duration
-	durationConfirmation time.Duration
-}
-
-// copyAndVerifyMilestoneCone verifies the milestone, copies the milestone cone to the
-// target storage, confirms the milestone and applies the ledger changes.
-func copyAndVerifyMilestoneCone(
-	ctx context.Context,
-	protocolManager *protocol.Manager,
-	genesisMilestoneIndex iotago.MilestoneIndex,
-	msIndex iotago.MilestoneIndex,
-	getMilestonePayload func(msIndex iotago.MilestoneIndex) (*iotago.Milestone, error),
-	parentsTraverserInterfaceSource dag.ParentsTraverserInterface,
-	cachedBlockFuncSource storage.CachedBlockFunc,
-	cachedBlockFuncTarget storage.CachedBlockFunc,
-	utxoManagerTarget *utxo.Manager,
-	storeBlockTarget StoreBlockInterface,
-	parentsTraverserStorageTarget dag.ParentsTraverserStorage,
-	milestoneManager *milestonemanager.MilestoneManager) (*confStats, error) {
-
-	if err := contextutils.ReturnErrIfCtxDone(ctx, common.ErrOperationAborted); err != nil {
-		return nil, err
-	}
-
-	milestonePayloadUnverified, err := getMilestonePayload(msIndex)
-	if err != nil {
-		return nil, err
-	}
-
-	milestonePayload := milestoneManager.VerifyMilestonePayload(milestonePayloadUnverified)
-	if milestonePayload == nil {
-		return nil, fmt.Errorf("source milestone not valid! %d", msIndex)
-	}
-
-	// we need to store every milestone payload here as well,
-	// otherwise the ledger changes might be applied,
-	// but the corresponding milestone payload is unknown in the database.
-	cachedMilestone, _ := storeBlockTarget.StoreMilestoneIfAbsent(milestonePayload) // milestone +1
-	cachedMilestone.Release(true)                                                   // milestone -1
-
-	ts := time.Now()
-
-	//nolint:contextcheck // we don't want abort the copying of the blocks itself
-	if err := copyMilestoneCone(
-		context.Background(),
-		protocolManager.Current(),
-		msIndex,
-		milestonePayload,
-		parentsTraverserInterfaceSource,
-		cachedBlockFuncSource,
-		storeBlockTarget,
-		milestoneManager); err != nil {
-		return nil, err
-	}
-
-	timeCopyMilestoneCone := time.Now()
-
-	//nolint:contextcheck // we don't pass a context here to not cancel the whiteflag computation!
-	confirmedMilestoneStats, _, err := whiteflag.ConfirmMilestone(
-		utxoManagerTarget,
-		parentsTraverserStorageTarget,
-		cachedBlockFuncTarget,
-		protocolManager.Current(),
-		genesisMilestoneIndex,
-		milestonePayload,
-		whiteflag.DefaultWhiteFlagTraversalCondition,
-		whiteflag.DefaultCheckBlockReferencedFunc,
-		whiteflag.DefaultSetBlockReferencedFunc,
-		nil,
-		// Hint: Ledger is write locked
-		nil,
-		// Hint: Ledger is write locked
-		nil,
-		// Hint: Ledger is not locked
-		nil,
-		// Hint: Ledger is not locked
-		nil,
-		// Hint: Ledger is not locked
-		nil,
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	timeConfirmMilestone := time.Now()
-
-	// handle protocol parameter updates
-	protocolManager.HandleConfirmedMilestone(milestonePayload)
-
-	return &confStats{
-		msIndex:              confirmedMilestoneStats.Index,
-		blocksReferenced:     confirmedMilestoneStats.BlocksReferenced,
-		durationCopy:         timeCopyMilestoneCone.Sub(ts).Truncate(time.Millisecond),
-		durationConfirmation: timeConfirmMilestone.Sub(timeCopyMilestoneCone).Truncate(time.Millisecond),
-	}, nil
-}
-
 // mergeViaAPI copies a milestone from a remote node to the target database via API.
 func mergeViaAPI(
 	ctx context.Context,
@@ -365,78 +176,15 @@ func mergeViaAPI(
 	client *nodeclient.Client,
 	apiParallelism int) error {
 
-	getBlockViaAPI := func(blockID iotago.BlockID) (*iotago.Block, error) {
-		ctxBlock, cancelBlock := context.WithTimeout(ctx, 5*time.Second)
-		defer cancelBlock()
-
-		block, err := client.BlockByBlockID(ctxBlock, blockID, protocolManager.Current())
-		if err != nil {
-			return nil, err
-		}
-
-		return block, nil
-	}
-
-	getMilestonePayloadViaAPI := func(client *nodeclient.Client, msIndex iotago.MilestoneIndex) (*iotago.Milestone, error) {
-		ctxMilestone, cancelMilestone := context.WithTimeout(ctx, 5*time.Second)
-		defer cancelMilestone()
-
-		ms, err := client.MilestoneByIndex(ctxMilestone, msIndex)
-		if err != nil {
-			return nil, err
-		}
-
-		return ms, nil
-	}
-
 	if err := checkSnapshotInfo(storeTarget); err != nil {
 		return err
 	}
-	snapshotInfoTarget := storeTarget.SnapshotInfo()
-
-	//nolint:contextcheck // false positive
-	proxyStorage, err := NewProxyStorage(protocolManager.Current(), storeTarget, milestoneManager, getBlockViaAPI)
-	if err != nil {
-		return err
-	}
-	defer proxyStorage.Cleanup()
-
-	ts := time.Now()
 
-	confStats, err := copyAndVerifyMilestoneCone(
-		ctx,
-		protocolManager,
-		snapshotInfoTarget.GenesisMilestoneIndex(),
-		msIndex,
-		func(msIndex iotago.MilestoneIndex) (*iotago.Milestone, error) {
-			return getMilestonePayloadViaAPI(client, msIndex)
-		},
-		dag.NewConcurrentParentsTraverser(proxyStorage, apiParallelism),
-		proxyStorage.CachedBlock,
-		proxyStorage.CachedBlock,
-		storeTarget.UTXOManager(),
-		proxyStorage,
-		proxyStorage,
-		milestoneManager)
+	confStats, err := merger.MergeViaAPI(ctx, protocolManager, msIndex, storeTarget, milestoneManager, client, apiParallelism)
 	if err != nil {
 		return err
 	}
-
-	timeMergeStoragesStart := time.Now()
-
-	if err := proxyStorage.MergeStorages(); err != nil {
-		return fmt.Errorf("merge storages failed: %w", err)
-	}
-
-	te := time.Now()
-
-	println(fmt.Sprintf("confirmed milestone %d, blocks: %d, duration copy: %v, duration conf.: %v, duration merge: %v, total: %v",
-		confStats.msIndex,
-		confStats.blocksReferenced,
-		confStats.durationCopy,
-		confStats.durationConfirmation,
-		te.Sub(timeMergeStoragesStart).Truncate(time.Millisecond),
-		te.Sub(ts).Truncate(time.Millisecond)))
+	printConfirmationStats(confStats)
 
 	return nil
 }
@@ -453,53 +201,24 @@ func mergeViaSourceDatabase(
 	if err := checkSnapshotInfo(storeTarget); err != nil {
 		return err
 	}
-	snapshotInfoTarget := storeTarget.SnapshotInfo()
 
-	//nolint:contextcheck // false positive
-	proxyStorage, err := NewProxyStorage(protocolManager.Current(), storeTarget, milestoneManager, storeSource.Block)
+	confStats, err := merger.MergeViaStorage(ctx, protocolManager, msIndex, storeSource, storeTarget, milestoneManager)
 	if err != nil {
 		return err
 	}
-	defer proxyStorage.Cleanup()
-
-	ts := time.Now()
-
-	confStats, err := copyAndVerifyMilestoneCone(
-		ctx,
-		protocolManager,
-		snapshotInfoTarget.GenesisMilestoneIndex(),
-		msIndex,
-		func(msIndex iotago.MilestoneIndex) (*iotago.Milestone, error) {
-			return getMilestonePayloadFromStorage(storeSource, msIndex)
-		},
-		dag.NewConcurrentParentsTraverser(storeSource),
-		storeSource.CachedBlock,
-		proxyStorage.CachedBlock,
-		storeTarget.UTXOManager(),
-		proxyStorage,
-		proxyStorage,
-		milestoneManager)
-	if err != nil {
-		return err
-	}
-
-	timeMergeStoragesStart := time.Now()
-
-	if err := proxyStorage.MergeStorages(); err != nil {
-		return fmt.Errorf("merge storages failed: %w", err)
-	}
+	printConfirmationStats(confStats)
 
-	te := time.Now()
+	return nil
+}
 
+func printConfirmationStats(confStats *merger.ConfirmationStats) {
 	println(fmt.Sprintf("confirmed milestone %d, blocks: %d, duration copy: %v, duration conf.: %v, duration merge: %v, total: %v",
-		confStats.msIndex,
-		confStats.blocksReferenced,
-		confStats.durationCopy,
-		confStats.durationConfirmation,
-		te.Sub(timeMergeStoragesStart).Truncate(time.Millisecond),
-		te.Sub(ts).Truncate(time.Millisecond)))
-
-	return nil
+		confStats.MilestoneIndex,
+		confStats.BlocksReferenced,
+		confStats.DurationCopy,
+		confStats.DurationConfirmation,
+		confStats.DurationMerge,
+		confStats.DurationTotal))
 }
 
 // mergeDatabase copies milestone after milestone from source to target database.
@@ -641,118 +360,3 @@ func getNodeHTTPAPIClient(nodeURL string) *nodeclient.Client {
 
 	return client
 }
-
-type GetBlockFunc func(blockID iotago.BlockID) (*iotago.Block, error)
-
-// ProxyStorage is used to temporarily store changes to an intermediate storage,
-// which then can be merged with the target store in a single commit.
-type ProxyStorage struct {
-	protoParams      *iotago.ProtocolParameters
-	storeTarget      *storage.Storage
-	storeProxy       *storage.Storage
-	milestoneManager *milestonemanager.MilestoneManager
-	getBlockFunc     GetBlockFunc
-}
-
-func NewProxyStorage(
-	protoParams *iotago.ProtocolParameters,
-	storeTarget *storage.Storage,
-	milestoneManager *milestonemanager.MilestoneManager,
-	getBlockFunc GetBlockFunc) (*ProxyStorage, error) {
-
-	storeProxy, err := createTangleStorage("proxy", "", "", hivedb.EngineMapDB)
-	if err != nil {
-		return nil, err
-	}
-
-	return &ProxyStorage{
-		protoParams:      protoParams,
-		storeTarget:      storeTarget,
-		storeProxy:       storeProxy,
-		milestoneManager: milestoneManager,
-		getBlockFunc:     getBlockFunc,
-	}, nil
-}
-
-// CachedBlock returns a cached block object.
-// block +1.
-func (s *ProxyStorage) CachedBlock(blockID iotago.BlockID) (*storage.CachedBlock, error) {
-	if !s.storeTarget.ContainsBlock(blockID) {
-		if !s.storeProxy.ContainsBlock(blockID) {
-			block, err := s.getBlockFunc(blockID)
-			if err != nil {
-				return nil, err
-			}
-
-			cachedBlock, err := storeBlock(s.protoParams, s.storeProxy, s.milestoneManager, block) // block +1
-			if err != nil {
-				return nil, err
-			}
-
-			// set the new block as solid
-			cachedBlockMeta := cachedBlock.CachedMetadata() // meta +1
-			defer cachedBlockMeta.Release(true)             // meta -1
-
-			cachedBlockMeta.Metadata().SetSolid(true)
-
-			return cachedBlock, nil
-		}
-
-		return s.storeProxy.CachedBlock(blockID) // block +1
-	}
-
-	return s.storeTarget.CachedBlock(blockID) // block +1
-}
-
-// CachedBlockMetadata returns a cached block metadata object.
-// meta +1.
-func (s *ProxyStorage) CachedBlockMetadata(blockID iotago.BlockID) (*storage.CachedMetadata, error) {
-	cachedBlock, err := s.CachedBlock(blockID) // block +1
-	if err != nil {
-		return nil, err
-	}
-	if cachedBlock == nil {
-		//nolint:nilnil // nil, nil is ok in this context, even if it is not go idiomatic
-		return nil, nil
-	}
-	defer cachedBlock.Release(true) // block -1
-
-	return cachedBlock.CachedMetadata(), nil // meta +1
-}
-
-func (s *ProxyStorage) SolidEntryPointsContain(blockID iotago.BlockID) (bool, error) {
-	return s.storeTarget.SolidEntryPointsContain(blockID)
-}
-
-func (s *ProxyStorage) SolidEntryPointsIndex(blockID iotago.BlockID) (iotago.MilestoneIndex, bool, error) {
-	return s.storeTarget.SolidEntryPointsIndex(blockID)
-}
-
-func (s *ProxyStorage) MergeStorages() error {
-
-	// first flush both storages
-	s.storeProxy.FlushStorages()
-	s.storeTarget.FlushStorages()
-
-	// copy all existing keys with values from the proxy storage to the target storage
-	return kvstore.CopyBatched(s.storeProxy.TangleStore(), s.storeTarget.TangleStore(), 10000)
-}
-
-// Cleanup shuts down, flushes and closes the proxy store.
-func (s *ProxyStorage) Cleanup() {
-	_ = s.storeProxy.Shutdown()
-}
-
-// StoreBlockInterface
-
-func (s *ProxyStorage) StoreBlockIfAbsent(block *storage.Block) (cachedBlock *storage.CachedBlock, newlyAdded bool) {
-	return s.storeProxy.StoreBlockIfAbsent(block)
-}
-
-func (s *ProxyStorage) StoreChild(parentBlockID iotago.BlockID, childBlockID iotago.BlockID) *storage.CachedChild {
-	return s.storeProxy.StoreChild(parentBlockID, childBlockID)
-}
-
-func (s *ProxyStorage) StoreMilestoneIfAbsent(milestone *iotago.Milestone) (*storage.CachedMilestone, bool) {
-	return s.storeProxy.StoreMilestoneIfAbsent(milestone)
-}
@@ -0,0 +1,434 @@
+package toolset
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/labstack/gommon/bytes"
+	flag "github.com/spf13/pflag"
+
+	"github.com/iotaledger/hive.go/app/configuration"
+	hivedb "github.com/iotaledger/hive.go/kvstore/database"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hive.go/runtime/ioutils"
+	coreDatabase "github.com/iotaledger/hornet/v2/components/database"
+	snapCore "github.com/iotaledger/hornet/v2/components/snapshot"
+	"github.com/iotaledger/hornet/v2/pkg/database"
+	"github.com/iotaledger/hornet/v2/pkg/model/storage"
+	"github.com/iotaledger/hornet/v2/pkg/model/syncmanager"
+	"github.com/iotaledger/hornet/v2/pkg/protocol"
+	"github.com/iotaledger/hornet/v2/pkg/pruning"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+func databasePrune(args []string) error {
+
+	fs := configuration.NewUnsortedFlagSet("", flag.ContinueOnError)
+	databasePathFlag := fs.String(FlagToolDatabasePath, DefaultValueMainnetDatabasePath, "the path to the database")
+	databaseEngineFlag := fs.String(FlagToolDatabaseEngine, string(hivedb.EngineAuto), "the engine of the database (optional, values: pebble, rocksdb, auto)")
+	targetIndexFlag := fs.Uint32(FlagToolDatabaseTargetIndex, 0, "the pruning target index")
+	depthFlag := fs.Uint32(FlagToolDatabasePruneDepth, 0, "the pruning depth")
+	targetSizeFlag := fs.String(FlagToolDatabasePruneTargetSize, "", "the target size of the database (e.g. 30GB)")
+	thresholdPercentageFlag := fs.Float64(FlagToolDatabasePruneThresholdPercentage, 10.0, "the percentage the database size gets reduced below the target size if pruning by size")
+	pruneReceiptsFlag := fs.Bool(FlagToolDatabasePruneReceipts, false, "whether to delete old receipts data from the database")
+	dryRunFlag := fs.Bool(FlagToolDatabasePruneDryRun, false, "only report the impact of the pruning without deleting any data")
+	outputJSONFlag := fs.Bool(FlagToolOutputJSON, false, FlagToolDescriptionOutputJSON)
+
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage of %s:\n", ToolDatabasePrune)
+		fs.PrintDefaults()
+		println(fmt.Sprintf("\nexample: %s --%s %s --%s %d",
+			ToolDatabasePrune,
+			FlagToolDatabasePath,
+			DefaultValueMainnetDatabasePath,
+			FlagToolDatabasePruneDepth,
+			60480,
+		))
+	}
+
+	if err := parseFlagSet(fs, args); err != nil {
+		return err
+	}
+
+	if len(*databasePathFlag) == 0 {
+		return fmt.Errorf("'%s' not specified", FlagToolDatabasePath)
+	}
+
+	specified := 0
+	for _, isSet := range []bool{*targetIndexFlag != 0, *depthFlag != 0, len(*targetSizeFlag) != 0} {
+		if isSet {
+			specified++
+		}
+	}
+	if specified != 1 {
+		return fmt.Errorf("either '%s', '%s' or '%s' has to be specified", FlagToolDatabaseTargetIndex, FlagToolDatabasePruneDepth, FlagToolDatabasePruneTargetSize)
+	}
+
+	dbEngine, err := hivedb.EngineFromStringAllowed(*databaseEngineFlag, database.AllowedEnginesStorageAuto)
+	if err != nil {
+		return err
+	}
+
+	databasePath := *databasePathFlag
+	databaseExists, err := ioutils.DirExistsAndIsNotEmpty(databasePath)
+	if err != nil {
+		return err
+	}
+	if !databaseExists {
+		return fmt.Errorf("database does not exist (%s)", databasePath)
+	}
+
+	tangleDatabase, err := database.DatabaseWithDefaultSettings(filepath.Join(databasePath, coreDatabase.TangleDatabaseDirectoryName), false, dbEngine, database.AllowedEnginesStorageAuto...)
+	if err != nil {
+		return fmt.Errorf("tangle database initialization failed: %w", err)
+	}
+
+	utxoDatabase, err := database.DatabaseWithDefaultSettings(filepath.Join(databasePath, coreDatabase.UTXODatabaseDirectoryName), false, dbEngine, database.AllowedEnginesStorageAuto...)
+	if err != nil {
+		return fmt.Errorf("utxo database initialization failed: %w", err)
+	}
+
+	// the databases are compacted manually after the pruning,
+	// so the size of the database can be used to calculate the pruning target index.
+	for _, db := range []*database.Database{tangleDatabase, utxoDatabase} {
+		db.SetCompactionSupported(db.ManualCompactionSupported())
+	}
+
+	dbStorage, err := storage.New(tangleDatabase.KVStore(), utxoDatabase.KVStore())
+	if err != nil {
+		return fmt.Errorf("storage initialization failed: %w", err)
+	}
+	storageShutdown := false
+	shutdownStorage := func() error {
+		if storageShutdown {
+			return nil
+		}
+		storageShutdown = true
+
+		return dbStorage.Shutdown()
+	}
+	defer func() { _ = shutdownStorage() }()
+
+	if err := checkDatabaseHealth(dbStorage, false); err != nil {
+		return err
+	}
+
+	if err := checkSnapshotInfo(dbStorage); err != nil {
+		return err
+	}
+
+	ledgerIndex, err := dbStorage.UTXOManager().ReadLedgerIndex()
+	if err != nil {
+		return err
+	}
+
+	protocolManager, err := protocol.NewManager(dbStorage, ledgerIndex)
+	if err != nil {
+		return err
+	}
+
+	syncManager, err := syncmanager.New(ledgerIndex, protocolManager)
+	if err != nil {
+		return err
+	}
+
+	// the solid entry points of the new pruning index need to be calculated from the blocks below max depth
+	solidEntryPointCheckThresholdPast := belowMaxDepth + snapCore.SolidEntryPointCheckAdditionalThresholdPast
+	getMinimumTangleHistory := func() iotago.MilestoneIndex {
+		confirmedMilestoneIndex := syncManager.ConfirmedMilestoneIndex()
+		if confirmedMilestoneIndex < solidEntryPointCheckThresholdPast {
+			return 0
+		}
+
+		return confirmedMilestoneIndex - solidEntryPointCheckThresholdPast
+	}
+
+	pruningManager := pruning.NewPruningManager(
+		// errors are returned to the caller, critical errors still panic
+		logger.NewNopLogger(),
+		dbStorage,
+		syncManager,
+		tangleDatabase,
+		utxoDatabase,
+		nil,
+		getMinimumTangleHistory,
+		false,
+		0,
+		false,
+		0,
+		*thresholdPercentageFlag,
+		0,
+		false,
+		0,
+		*pruneReceiptsFlag,
+	)
+
+	var targetSizeBytes int64
+	if len(*targetSizeFlag) != 0 {
+		targetSizeBytes, err = bytes.Parse(*targetSizeFlag)
+		if err != nil {
+			return fmt.Errorf("parsing '%s' failed: %w", FlagToolDatabasePruneTargetSize, err)
+		}
+	}
+
+	if *dryRunFlag {
+		var report *pruning.Report
+		switch {
+		case *targetIndexFlag != 0:
+			report, err = pruningManager.DryRunByTargetIndex(getGracefulStopContext(), *targetIndexFlag)
+		case *depthFlag != 0:
+			report, err = pruningManager.DryRunByDepth(getGracefulStopContext(), *depthFlag)
+		default:
+			report, err = pruningManager.DryRunBySize(getGracefulStopContext(), targetSizeBytes)
+		}
+		if err != nil {
+			return err
+		}
+
+		return printPruningReport(report, *outputJSONFlag)
+	}
+
+	databaseSizeBytesBefore, err := getDatabaseSize(tangleDatabase, utxoDatabase)
+	if err != nil {
+		return err
+	}
+	pruningIndexBefore := dbStorage.SnapshotInfo().PruningIndex()
+
+	// mark the database as corrupted.
+	// this flag will be cleared after the operation finished successfully.
+	if err := dbStorage.MarkStoresCorrupted(); err != nil {
+		return err
+	}
+
+	ts := time.Now()
+	lastStatusTime := time.Now()
+
+	if !*outputJSONFlag {
+		fmt.Printf("pruning database ... (path: \"%s\")\n", databasePath)
+
+		unhook := pruningManager.Events.PruningMilestoneIndexChanged.Hook(func(msIndex iotago.MilestoneIndex) {
+			if time.Since(lastStatusTime) >= printStatusInterval {
+				lastStatusTime = time.Now()
+				fmt.Printf("pruned milestone %d, %v elapsed ...\n", msIndex, time.Since(ts).Truncate(time.Second))
+			}
+		}).Unhook
+		defer unhook()
+	}
+
+	var targetIndex iotago.MilestoneIndex
+	var errPrune error
+	switch {
+	case *targetIndexFlag != 0:
+		targetIndex, errPrune = pruningManager.PruneDatabaseByTargetIndex(getGracefulStopContext(), *targetIndexFlag)
+	case *depthFlag != 0:
+		targetIndex, errPrune = pruningManager.PruneDatabaseByDepth(getGracefulStopContext(), *depthFlag)
+	default:
+		targetIndex, errPrune = pruningManager.PruneDatabaseBySize(getGracefulStopContext(), targetSizeBytes)
+	}
+
+	// the deleted entries need to be written to the stores before the compaction
+	dbStorage.FlushStorages()
+
+	// the pruning index is only moved forward after a milestone was completely pruned,
+	// so the database is consistent even if the pruning failed or was aborted.
+	if err := dbStorage.MarkStoresHealthy(); err != nil {
+		return err
+	}
+
+	if errPrune != nil {
+		return errPrune
+	}
+
+	if !*outputJSONFlag {
+		fmt.Printf("pruning database ... done. took: %v\n", time.Since(ts).Truncate(time.Millisecond))
+	}
+
+	tsCompaction := time.Now()
+	compacted := false
+	if tangleDatabase.ManualCompactionSupported() && utxoDatabase.ManualCompactionSupported() {
+		if !*outputJSONFlag {
+			fmt.Println("compacting database ...")
+		}
+
+		for _, db := range []*database.Database{tangleDatabase, utxoDatabase} {
+			if err := db.KVStore().Flush(); err != nil {
+				return err
+			}
+
+			if err := db.Compact(); err != nil {
+				return err
+			}
+		}
+		compacted = true
+
+		if !*outputJSONFlag {
+			fmt.Printf("compacting database ... done. took: %v\n", time.Since(tsCompaction).Truncate(time.Millisecond))
+		}
+	} else if !*outputJSONFlag {
+		fmt.Printf("skipped compaction, not supported by the database engine (%s)\n", tangleDatabase.Engine())
+	}
+
+	snapshotInfo := dbStorage.SnapshotInfo()
+
+	solidEntryPointsCount := 0
+	dbStorage.ForEachSolidEntryPointWithoutLocking(func(sep *storage.SolidEntryPoint) bool {
+		solidEntryPointsCount++
+
+		return true
+	})
+
+	// obsolete database files are removed when the database is closed
+	if err := shutdownStorage(); err != nil {
+		return err
+	}
+
+	databaseSizeBytesAfter, err := getDatabaseSize(tangleDatabase, utxoDatabase)
+	if err != nil {
+		return err
+	}
+
+	if *outputJSONFlag {
+		result := struct {
+			PreviousPruningIndex    iotago.MilestoneIndex `json:"previousPruningIndex"`
+			PruningIndex            iotago.MilestoneIndex `json:"pruningIndex"`
+			EntryPointIndex         iotago.MilestoneIndex `json:"entryPointIndex"`
+			SolidEntryPointsCount   int                   `json:"solidEntryPointsCount"`
+			DatabaseSizeBytesBefore int64                 `json:"databaseSizeBytesBefore"`
+			DatabaseSizeBytesAfter  int64                 `json:"databaseSizeBytesAfter"`
+			Compacted               bool                  `json:"compacted"`
+		}{
+			PreviousPruningIndex:    pruningIndexBefore,
+			PruningIndex:            targetIndex,
+			EntryPointIndex:         snapshotInfo.EntryPointIndex(),
+			SolidEntryPointsCount:   solidEntryPointsCount,
+			DatabaseSizeBytesBefore: databaseSizeBytesBefore,
+			DatabaseSizeBytesAfter:  databaseSizeBytesAfter,
+			Compacted:               compacted,
+		}
+
+		return printJSON(result)
+	}
+
+	fmt.Printf(`    >
+        - Previous pruning index: %d
+        - Pruning index:          %d
+        - Entry point index:      %d
+        - Solid entry points:     %d
+        - Database size before:   %s
+        - Database size after:    %s
+        - Compacted:              %s`+"\n",
+		pruningIndexBefore,
+		targetIndex,
+		snapshotInfo.EntryPointIndex(),
+		solidEntryPointsCount,
+		humanize.Bytes(uint64(databaseSizeBytesBefore)),
+		humanize.Bytes(uint64(databaseSizeBytesAfter)),
+		yesOrNo(compacted),
+	)
+
+	return nil
+}
+
+// getDatabaseSize returns the combined size of the given databases.
+func getDatabaseSize(databases ...*database.Database) (int64, error) {
+
+	var sizeBytes int64
+	for _, db := range databases {
+		size, err := db.Size()
+		if err != nil {
+			return 0, err
+		}
+		sizeBytes += size
+	}
+
+	return sizeBytes, nil
+}
+
+func printPruningReport(report *pruning.Report, outputJSON bool) error {
+
+	if outputJSON {
+
+		type solidEntryPointStruct struct {
+			BlockID string                `json:"blockId"`
+			Index   iotago.MilestoneIndex `json:"index"`
+		}
+
+		solidEntryPoints := make([]*solidEntryPointStruct, 0, len(report.SolidEntryPoints))
+		for _, sep := range report.SolidEntryPoints {
+			solidEntryPoints = append(solidEntryPoints, &solidEntryPointStruct{
+				BlockID: sep.BlockID.ToHex(),
+				Index:   sep.Index,
+			})
+		}
+
+		result := struct {
+			PruningIndex           iotago.MilestoneIndex    `json:"pruningIndex"`
+			TargetIndex            iotago.MilestoneIndex    `json:"targetIndex"`
+			Milestones             int                      `json:"milestones"`
+			Blocks                 int                      `json:"blocks"`
+			UnreferencedBlocks     int                      `json:"unreferencedBlocks"`
+			MilestoneDiffs         int                      `json:"milestoneDiffs"`
+			Outputs                int                      `json:"outputs"`
+			Spents                 int                      `json:"spents"`
+			Receipts               int                      `json:"receipts"`
+			EstimatedBytes         int64                    `json:"estimatedBytes"`
+			DatabaseSizeBytes      int64                    `json:"databaseSizeBytes"`
+			ExpectedReclaimedBytes int64                    `json:"expectedReclaimedBytes,omitempty"`
+			SolidEntryPoints       []*solidEntryPointStruct `json:"solidEntryPoints"`
+		}{
+			PruningIndex:           report.PruningIndex,
+			TargetIndex:            report.TargetIndex,
+			Milestones:             report.Milestones,
+			Blocks:                 report.Blocks,
+			UnreferencedBlocks:     report.UnreferencedBlocks,
+			MilestoneDiffs:         report.MilestoneDiffs,
+			Outputs:                report.Outputs,
+			Spents:                 report.Spents,
+			Receipts:               report.Receipts,
+			EstimatedBytes:         report.EstimatedBytes,
+			DatabaseSizeBytes:      report.DatabaseSizeBytes,
+			ExpectedReclaimedBytes: report.ExpectedReclaimedBytes,
+			SolidEntryPoints:       solidEntryPoints,
+		}
+
+		return printJSON(result)
+	}
+
+	fmt.Printf(`    >
+        - Pruning index:       %d
+        - Target index:        %d
+        - Milestones:          %d
+        - Blocks:              %d
+        - Unreferenced blocks: %d
+        - Milestone diffs:     %d
+        - Created outputs:     %d
+        - Spent outputs:       %d
+        - Receipts:            %d
+        - Estimated size:      %s
+        - Database size:       %s
+        - Expected reclaimed:  %s
+        - Solid entry points:  %d`+"\n",
+		report.PruningIndex,
+		report.TargetIndex,
+		report.Milestones,
+		report.Blocks,
+		report.UnreferencedBlocks,
+		report.MilestoneDiffs,
+		report.Outputs,
+		report.Spents,
+		report.Receipts,
+		humanize.Bytes(uint64(report.EstimatedBytes)),
+		humanize.Bytes(uint64(report.DatabaseSizeBytes)),
+		humanize.Bytes(uint64(report.ExpectedReclaimedBytes)),
+		len(report.SolidEntryPoints),
+	)
+
+	for _, sep := range report.SolidEntryPoints {
+		fmt.Printf("            - %s (%d)\n", sep.BlockID.ToHex(), sep.Index)
+	}
+
+	return nil
+}
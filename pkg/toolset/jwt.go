@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/libp2p/go-libp2p/core/peer"
 	flag "github.com/spf13/pflag"
@@ -15,22 +17,72 @@ import (
 	"github.com/iotaledger/hornet/v2/pkg/p2p"
 )
 
+// loadJWTAuth loads the p2p identity of the node from the database path and creates the JWT auth with it.
+func loadJWTAuth(databasePath string, salt string, sessionTimeout time.Duration) (*jwt.Auth, error) {
+	privKeyFilePath := filepath.Join(databasePath, p2p.PrivKeyFileName)
+
+	_, err := os.Stat(privKeyFilePath)
+	switch {
+	case os.IsNotExist(err):
+		// private key does not exist
+		return nil, fmt.Errorf("private key file (%s) does not exist", privKeyFilePath)
+
+	case err == nil || os.IsExist(err):
+		// private key file exists
+
+	default:
+		return nil, fmt.Errorf("unable to check private key file (%s): %w", privKeyFilePath, err)
+	}
+
+	privKey, err := pem.ReadEd25519PrivateKeyFromPEMFile(privKeyFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading private key file for peer identity failed: %w", err)
+	}
+
+	libp2pPrivKey, err := hivep2p.Ed25519PrivateKeyToLibp2pPrivateKey(privKey)
+	if err != nil {
+		return nil, fmt.Errorf("reading private key file for peer identity failed: %w", err)
+	}
+
+	peerID, err := peer.IDFromPublicKey(libp2pPrivKey.GetPublic())
+	if err != nil {
+		return nil, fmt.Errorf("unable to get peer identity from public key: %w", err)
+	}
+
+	jwtAuth, err := jwt.NewAuth(salt,
+		sessionTimeout,
+		peerID.String(),
+		libp2pPrivKey,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("JWT auth initialization failed: %w", err)
+	}
+
+	return jwtAuth, nil
+}
+
 func generateJWTApiToken(args []string) error {
 
 	fs := configuration.NewUnsortedFlagSet("", flag.ContinueOnError)
 	databasePathFlag := fs.String(FlagToolDatabasePath, DefaultValueP2PDatabasePath, "the path to the p2p database folder")
 	apiJWTSaltFlag := fs.String(FlagToolSalt, DefaultValueAPIJWTTokenSalt, "salt used inside the JWT tokens for the REST API")
+	scopesFlag := fs.StringSlice(FlagToolJWTScope, nil, fmt.Sprintf("the scopes the token grants access to, e.g. \"%s\", \"%s\", \"%s\" or \"%s\" (all routes if not specified)", jwt.ScopeReadCore, jwt.ScopeWriteBlocks, jwt.ScopeAdminPeers, jwt.ScopeAdminControl))
+	ttlFlag := fs.Duration(FlagToolJWTTTL, 0, "the duration the token is valid for (token does not expire if not specified)")
 	outputJSONFlag := fs.Bool(FlagToolOutputJSON, false, FlagToolDescriptionOutputJSON)
 
 	fs.Usage = func() {
 		_, _ = fmt.Fprintf(os.Stderr, "Usage of %s:\n", ToolJWTApi)
 		fs.PrintDefaults()
-		println(fmt.Sprintf("\nexample: %s --%s %s --%s %s",
+		println(fmt.Sprintf("\nexample: %s --%s %s --%s %s --%s %s --%s %s",
 			ToolJWTApi,
 			FlagToolDatabasePath,
 			DefaultValueP2PDatabasePath,
 			FlagToolSalt,
-			DefaultValueAPIJWTTokenSalt))
+			DefaultValueAPIJWTTokenSalt,
+			FlagToolJWTScope,
+			strings.Join([]string{jwt.ScopeReadCore, jwt.ScopeWriteBlocks}, ","),
+			FlagToolJWTTTL,
+			"720h"))
 	}
 
 	if err := parseFlagSet(fs, args); err != nil {
@@ -43,67 +95,133 @@ func generateJWTApiToken(args []string) error {
 	if len(*apiJWTSaltFlag) == 0 {
 		return fmt.Errorf("'%s' not specified", FlagToolSalt)
 	}
+	if *ttlFlag < 0 {
+		return fmt.Errorf("'%s' must not be negative", FlagToolJWTTTL)
+	}
 
-	databasePath := *databasePathFlag
-	privKeyFilePath := filepath.Join(databasePath, p2p.PrivKeyFileName)
+	jwtAuth, err := loadJWTAuth(*databasePathFlag, *apiJWTSaltFlag, *ttlFlag)
+	if err != nil {
+		return err
+	}
 
-	salt := *apiJWTSaltFlag
+	jwtToken, err := jwtAuth.IssueJWT(*scopesFlag...)
+	if err != nil {
+		return fmt.Errorf("issuing JWT token failed: %w", err)
+	}
 
-	_, err := os.Stat(privKeyFilePath)
-	switch {
-	case os.IsNotExist(err):
-		// private key does not exist
-		return fmt.Errorf("private key file (%s) does not exist", privKeyFilePath)
+	claims, err := jwtAuth.ParseJWT(jwtToken)
+	if err != nil {
+		return fmt.Errorf("parsing issued JWT token failed: %w", err)
+	}
 
-	case err == nil || os.IsExist(err):
-		// private key file exists
+	var expiresAt *time.Time
+	if claims.ExpiresAt != 0 {
+		expiry := time.Unix(claims.ExpiresAt, 0)
+		expiresAt = &expiry
+	}
 
-	default:
-		return fmt.Errorf("unable to check private key file (%s): %w", privKeyFilePath, err)
+	if *outputJSONFlag {
+
+		result := struct {
+			JWT       string     `json:"jwt"`
+			ID        string     `json:"id"`
+			Scopes    []string   `json:"scopes,omitempty"`
+			ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+		}{
+			JWT:       jwtToken,
+			ID:        claims.Id,
+			Scopes:    claims.Scopes,
+			ExpiresAt: expiresAt,
+		}
+
+		return printJSON(result)
 	}
 
-	privKey, err := pem.ReadEd25519PrivateKeyFromPEMFile(privKeyFilePath)
-	if err != nil {
-		return fmt.Errorf("reading private key file for peer identity failed: %w", err)
+	fmt.Println("Your API JWT token: ", jwtToken)
+	fmt.Println("Token ID:           ", claims.Id)
+	if len(claims.Scopes) > 0 {
+		fmt.Println("Scopes:             ", strings.Join(claims.Scopes, ", "))
+	} else {
+		fmt.Println("Scopes:              all routes")
+	}
+	if expiresAt != nil {
+		fmt.Println("Expires at:         ", expiresAt.Format(time.RFC3339))
 	}
 
-	libp2pPrivKey, err := hivep2p.Ed25519PrivateKeyToLibp2pPrivateKey(privKey)
-	if err != nil {
-		return fmt.Errorf("reading private key file for peer identity failed: %w", err)
+	return nil
+}
+
+func revokeJWTApiToken(args []string) error {
+
+	fs := configuration.NewUnsortedFlagSet("", flag.ContinueOnError)
+	databasePathFlag := fs.String(FlagToolDatabasePath, DefaultValueP2PDatabasePath, "the path to the p2p database folder")
+	apiJWTSaltFlag := fs.String(FlagToolSalt, DefaultValueAPIJWTTokenSalt, "salt used inside the JWT tokens for the REST API")
+	tokenFlag := fs.String(FlagToolJWTToken, "", "the JWT token to revoke")
+	tokenIDFlag := fs.String(FlagToolJWTTokenID, "", "the ID of the JWT token to revoke (alternative to the token itself)")
+	reasonFlag := fs.String(FlagToolJWTReason, "", "the reason why the token is revoked (optional)")
+	outputJSONFlag := fs.Bool(FlagToolOutputJSON, false, FlagToolDescriptionOutputJSON)
+
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage of %s:\n", ToolJWTRevoke)
+		fs.PrintDefaults()
+		println(fmt.Sprintf("\nexample: %s --%s %s --%s %s",
+			ToolJWTRevoke,
+			FlagToolDatabasePath,
+			DefaultValueP2PDatabasePath,
+			FlagToolJWTTokenID,
+			"8a1b2c3d4e5f60718293a4b5c6d7e8f9"))
 	}
 
-	peerID, err := peer.IDFromPublicKey(libp2pPrivKey.GetPublic())
-	if err != nil {
-		return fmt.Errorf("unable to get peer identity from public key: %w", err)
+	if err := parseFlagSet(fs, args); err != nil {
+		return err
 	}
 
-	// API tokens do not expire.
-	jwtAuth, err := jwt.NewAuth(salt,
-		0,
-		peerID.String(),
-		libp2pPrivKey,
-	)
-	if err != nil {
-		return fmt.Errorf("JWT auth initialization failed: %w", err)
+	if len(*databasePathFlag) == 0 {
+		return fmt.Errorf("'%s' not specified", FlagToolDatabasePath)
 	}
+	if (len(*tokenFlag) == 0) == (len(*tokenIDFlag) == 0) {
+		return fmt.Errorf("either '%s' or '%s' must be specified", FlagToolJWTToken, FlagToolJWTTokenID)
+	}
+
+	tokenID := *tokenIDFlag
+	if len(*tokenFlag) > 0 {
+		if len(*apiJWTSaltFlag) == 0 {
+			return fmt.Errorf("'%s' not specified", FlagToolSalt)
+		}
 
-	jwtToken, err := jwtAuth.IssueJWT()
+		jwtAuth, err := loadJWTAuth(*databasePathFlag, *apiJWTSaltFlag, 0)
+		if err != nil {
+			return err
+		}
+
+		claims, err := jwtAuth.ParseJWT(*tokenFlag)
+		if err != nil {
+			return fmt.Errorf("parsing JWT token failed: %w", err)
+		}
+		tokenID = claims.Id
+	}
+
+	revocationList, err := jwt.NewRevocationList(*databasePathFlag)
 	if err != nil {
-		return fmt.Errorf("issuing JWT token failed: %w", err)
+		return err
+	}
+
+	if err := revocationList.Revoke(tokenID, *reasonFlag); err != nil {
+		return fmt.Errorf("revoking JWT token failed: %w", err)
 	}
 
 	if *outputJSONFlag {
 
 		result := struct {
-			JWT string `json:"jwt"`
+			ID string `json:"id"`
 		}{
-			JWT: jwtToken,
+			ID: tokenID,
 		}
 
 		return printJSON(result)
 	}
 
-	fmt.Println("Your API JWT token: ", jwtToken)
+	fmt.Println("Revoked API JWT token with ID: ", tokenID)
 
 	return nil
 }
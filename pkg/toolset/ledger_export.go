@@ -0,0 +1,554 @@
+package toolset
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/iotaledger/hive.go/app/configuration"
+	hivedb "github.com/iotaledger/hive.go/kvstore/database"
+	"github.com/iotaledger/hornet/v2/pkg/model/utxo"
+	"github.com/iotaledger/hornet/v2/pkg/snapshot"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+const (
+	// ledgerExportFormatCSV exports the ledger as comma separated values with a header line.
+	ledgerExportFormatCSV = "csv"
+	// ledgerExportFormatJSONLines exports the ledger as one JSON object per line.
+	ledgerExportFormatJSONLines = "jsonl"
+
+	ledgerExportColumnOutputID                 = "outputId"
+	ledgerExportColumnType                     = "type"
+	ledgerExportColumnAmount                   = "amount"
+	ledgerExportColumnAddress                  = "address"
+	ledgerExportColumnNativeTokens             = "nativeTokens"
+	ledgerExportColumnMilestoneIndexBooked     = "milestoneIndexBooked"
+	ledgerExportColumnMilestoneTimestampBooked = "milestoneTimestampBooked"
+	ledgerExportColumnSpent                    = "spent"
+	ledgerExportColumnMilestoneIndexSpent      = "milestoneIndexSpent"
+	ledgerExportColumnTransactionIDSpent       = "transactionIdSpent"
+
+	ledgerExportTypeTreasury = "treasury"
+)
+
+var (
+	ledgerExportColumns = []string{
+		ledgerExportColumnOutputID,
+		ledgerExportColumnType,
+		ledgerExportColumnAmount,
+		ledgerExportColumnAddress,
+		ledgerExportColumnNativeTokens,
+		ledgerExportColumnMilestoneIndexBooked,
+		ledgerExportColumnMilestoneTimestampBooked,
+		ledgerExportColumnSpent,
+		ledgerExportColumnMilestoneIndexSpent,
+		ledgerExportColumnTransactionIDSpent,
+	}
+
+	ledgerExportOutputTypes = map[string]iotago.OutputType{
+		"basic":   iotago.OutputBasic,
+		"alias":   iotago.OutputAlias,
+		"foundry": iotago.OutputFoundry,
+		"nft":     iotago.OutputNFT,
+	}
+)
+
+// ledgerExportRecord is a single exported output of the ledger.
+type ledgerExportRecord struct {
+	outputID                 string
+	outputType               string
+	amount                   uint64
+	address                  string
+	nativeTokens             iotago.NativeTokens
+	milestoneIndexBooked     iotago.MilestoneIndex
+	milestoneTimestampBooked uint32
+	spent                    bool
+	milestoneIndexSpent      iotago.MilestoneIndex
+	transactionIDSpent       string
+}
+
+// value returns the value of the given column for the JSON Lines export.
+func (r *ledgerExportRecord) value(column string) any {
+	switch column {
+	case ledgerExportColumnOutputID:
+		return r.outputID
+	case ledgerExportColumnType:
+		return r.outputType
+	case ledgerExportColumnAmount:
+		return r.amount
+	case ledgerExportColumnAddress:
+		return r.address
+	case ledgerExportColumnNativeTokens:
+		nativeTokens := make(map[string]string, len(r.nativeTokens))
+		for _, nativeToken := range r.nativeTokens {
+			nativeTokens[nativeToken.ID.ToHex()] = nativeToken.Amount.String()
+		}
+
+		return nativeTokens
+	case ledgerExportColumnMilestoneIndexBooked:
+		return r.milestoneIndexBooked
+	case ledgerExportColumnMilestoneTimestampBooked:
+		return r.milestoneTimestampBooked
+	case ledgerExportColumnSpent:
+		return r.spent
+	case ledgerExportColumnMilestoneIndexSpent:
+		return r.milestoneIndexSpent
+	case ledgerExportColumnTransactionIDSpent:
+		return r.transactionIDSpent
+	default:
+		return nil
+	}
+}
+
+// stringValue returns the value of the given column for the CSV export.
+func (r *ledgerExportRecord) stringValue(column string) string {
+	switch column {
+	case ledgerExportColumnNativeTokens:
+		nativeTokens := make([]string, 0, len(r.nativeTokens))
+		for _, nativeToken := range r.nativeTokens {
+			nativeTokens = append(nativeTokens, fmt.Sprintf("%s:%s", nativeToken.ID.ToHex(), nativeToken.Amount.String()))
+		}
+
+		return strings.Join(nativeTokens, ";")
+	case ledgerExportColumnSpent:
+		return strconv.FormatBool(r.spent)
+	default:
+		return fmt.Sprint(r.value(column))
+	}
+}
+
+// ledgerExportWriter writes the exported records in a specific format.
+type ledgerExportWriter interface {
+	Write(record *ledgerExportRecord) error
+	Flush() error
+}
+
+type csvLedgerExportWriter struct {
+	writer        *csv.Writer
+	columns       []string
+	headerWritten bool
+}
+
+func (w *csvLedgerExportWriter) writeHeader() error {
+	if w.headerWritten {
+		return nil
+	}
+	w.headerWritten = true
+
+	return w.writer.Write(w.columns)
+}
+
+func (w *csvLedgerExportWriter) Write(record *ledgerExportRecord) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+
+	values := make([]string, len(w.columns))
+	for i, column := range w.columns {
+		values[i] = record.stringValue(column)
+	}
+
+	return w.writer.Write(values)
+}
+
+func (w *csvLedgerExportWriter) Flush() error {
+	// the header is also written if no outputs were exported
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.writer.Flush()
+
+	return w.writer.Error()
+}
+
+type jsonLinesLedgerExportWriter struct {
+	writer  *bufio.Writer
+	encoder *json.Encoder
+	columns []string
+}
+
+func (w *jsonLinesLedgerExportWriter) Write(record *ledgerExportRecord) error {
+	values := make(map[string]any, len(w.columns))
+	for _, column := range w.columns {
+		values[column] = record.value(column)
+	}
+
+	return w.encoder.Encode(values)
+}
+
+func (w *jsonLinesLedgerExportWriter) Flush() error {
+	return w.writer.Flush()
+}
+
+func newLedgerExportWriter(writer io.Writer, format string, columns []string) (ledgerExportWriter, error) {
+	switch format {
+	case ledgerExportFormatCSV:
+		return &csvLedgerExportWriter{writer: csv.NewWriter(writer), columns: columns}, nil
+
+	case ledgerExportFormatJSONLines:
+		bufferedWriter := bufio.NewWriter(writer)
+
+		return &jsonLinesLedgerExportWriter{writer: bufferedWriter, encoder: json.NewEncoder(bufferedWriter), columns: columns}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown export format: %s, allowed formats: %s, %s", format, ledgerExportFormatCSV, ledgerExportFormatJSONLines)
+	}
+}
+
+// ledgerExportFilter filters the exported outputs by type and address.
+type ledgerExportFilter struct {
+	outputTypes map[iotago.OutputType]struct{}
+	addresses   map[string]struct{}
+}
+
+func newLedgerExportFilter(outputTypes []string, bech32Addresses []string) (*ledgerExportFilter, error) {
+	filter := &ledgerExportFilter{
+		outputTypes: make(map[iotago.OutputType]struct{}),
+		addresses:   make(map[string]struct{}),
+	}
+
+	for _, name := range outputTypes {
+		outputType, exists := ledgerExportOutputTypes[strings.ToLower(name)]
+		if !exists {
+			return nil, fmt.Errorf("unknown output type: %s", name)
+		}
+		filter.outputTypes[outputType] = struct{}{}
+	}
+
+	for _, bech32Address := range bech32Addresses {
+		_, address, err := iotago.ParseBech32(bech32Address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address: %s, error: %w", bech32Address, err)
+		}
+		filter.addresses[address.Key()] = struct{}{}
+	}
+
+	return filter, nil
+}
+
+// matches returns whether the output passes the filter.
+func (f *ledgerExportFilter) matches(output *utxo.Output) bool {
+	if len(f.outputTypes) > 0 {
+		if _, exists := f.outputTypes[output.OutputType()]; !exists {
+			return false
+		}
+	}
+
+	if len(f.addresses) == 0 {
+		return true
+	}
+
+	for _, address := range utxo.UnlockAddresses(output.Output()) {
+		if _, exists := f.addresses[address.Key()]; exists {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesTreasury returns whether the treasury output passes the filter.
+// The treasury output has no address, so it is excluded by any filter.
+func (f *ledgerExportFilter) matchesTreasury() bool {
+	return len(f.outputTypes) == 0 && len(f.addresses) == 0
+}
+
+// ledgerExporter converts the outputs of the ledger to records and writes them.
+type ledgerExporter struct {
+	writer        ledgerExportWriter
+	filter        *ledgerExportFilter
+	hrp           iotago.NetworkPrefix
+	exportedCount int
+}
+
+func newLedgerExportRecord(output *utxo.Output, hrp iotago.NetworkPrefix) *ledgerExportRecord {
+	var address string
+	if addresses := utxo.UnlockAddresses(output.Output()); len(addresses) > 0 {
+		// the first address is the one that owns the output
+		address = addresses[0].Bech32(hrp)
+	}
+
+	return &ledgerExportRecord{
+		outputID:                 output.OutputID().ToHex(),
+		outputType:               strings.TrimSuffix(strings.ToLower(output.OutputType().String()), "output"),
+		amount:                   output.Deposit(),
+		address:                  address,
+		nativeTokens:             output.Output().NativeTokenList(),
+		milestoneIndexBooked:     output.MilestoneIndexBooked(),
+		milestoneTimestampBooked: output.MilestoneTimestampBooked(),
+	}
+}
+
+func (e *ledgerExporter) exportOutput(output *utxo.Output) error {
+	if !e.filter.matches(output) {
+		return nil
+	}
+	e.exportedCount++
+
+	return e.writer.Write(newLedgerExportRecord(output, e.hrp))
+}
+
+func (e *ledgerExporter) exportSpent(spent *utxo.Spent) error {
+	if !e.filter.matches(spent.Output()) {
+		return nil
+	}
+	e.exportedCount++
+
+	record := newLedgerExportRecord(spent.Output(), e.hrp)
+	record.spent = true
+	record.milestoneIndexSpent = spent.MilestoneIndexSpent()
+	record.transactionIDSpent = spent.TransactionIDSpent().ToHex()
+
+	return e.writer.Write(record)
+}
+
+func (e *ledgerExporter) exportTreasury(treasuryOutput *utxo.TreasuryOutput) error {
+	if treasuryOutput == nil || !e.filter.matchesTreasury() {
+		return nil
+	}
+	e.exportedCount++
+
+	// the treasury output is identified by the ID of the milestone that created it
+	return e.writer.Write(&ledgerExportRecord{
+		outputID:   iotago.EncodeHex(treasuryOutput.MilestoneID[:]),
+		outputType: ledgerExportTypeTreasury,
+		amount:     treasuryOutput.Amount,
+	})
+}
+
+func (e *ledgerExporter) exportFromDatabase(databasePath string, includeSpent bool, includeTreasury bool) error {
+
+	tangleStore, err := getTangleStorage(databasePath, "source", string(hivedb.EngineAuto), true, true, false, true)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := tangleStore.Shutdown(); err != nil {
+			panic(err)
+		}
+	}()
+
+	utxoManager := tangleStore.UTXOManager()
+
+	// lock the ledger to export a consistent state
+	utxoManager.ReadLockLedger()
+	defer utxoManager.ReadUnlockLedger()
+
+	ledgerIndex, err := utxoManager.ReadLedgerIndexWithoutLocking()
+	if err != nil {
+		return err
+	}
+
+	protoParams, err := tangleStore.ProtocolParameters(ledgerIndex)
+	if err != nil {
+		return fmt.Errorf("loading protocol parameters failed: %w", err)
+	}
+	e.hrp = protoParams.Bech32HRP
+
+	if includeTreasury {
+		treasuryOutput, err := utxoManager.UnspentTreasuryOutputWithoutLocking()
+		if err != nil {
+			return fmt.Errorf("unable to get unspent treasury output: %w", err)
+		}
+
+		if err := e.exportTreasury(treasuryOutput); err != nil {
+			return err
+		}
+	}
+
+	var innerErr error
+	if err := utxoManager.ForEachUnspentOutput(func(output *utxo.Output) bool {
+		if err := e.exportOutput(output); err != nil {
+			innerErr = err
+
+			return false
+		}
+
+		return true
+	}, utxo.ReadLockLedger(false)); err != nil {
+		return err
+	}
+	if innerErr != nil {
+		return innerErr
+	}
+
+	if !includeSpent {
+		return nil
+	}
+
+	if err := utxoManager.ForEachSpentOutput(func(spent *utxo.Spent) bool {
+		if err := e.exportSpent(spent); err != nil {
+			innerErr = err
+
+			return false
+		}
+
+		return true
+	}, utxo.ReadLockLedger(false)); err != nil {
+		return err
+	}
+
+	return innerErr
+}
+
+// exportFromSnapshot exports the ledger of a full snapshot file.
+// The spent outputs are the ones consumed by the milestone diffs of the snapshot.
+func (e *ledgerExporter) exportFromSnapshot(ctx context.Context, snapshotPath string, includeSpent bool, includeTreasury bool) error {
+
+	snapshotFile, err := snapshot.OpenSnapshotFile(snapshotPath)
+	if err != nil {
+		return fmt.Errorf("unable to open snapshot file: %w", err)
+	}
+	defer func() { _ = snapshotFile.Close() }()
+
+	// the treasury output is consumed before the header, but the export needs the protocol parameters of the header
+	var treasuryOutput *utxo.TreasuryOutput
+
+	return snapshot.StreamFullSnapshotDataFrom(
+		ctx,
+		snapshotFile,
+		func(header *snapshot.FullSnapshotHeader) error {
+			protoParams, err := header.ProtocolParameters()
+			if err != nil {
+				return err
+			}
+			e.hrp = protoParams.Bech32HRP
+
+			if !includeTreasury {
+				return nil
+			}
+
+			return e.exportTreasury(treasuryOutput)
+		},
+		func(output *utxo.TreasuryOutput) error {
+			treasuryOutput = output
+
+			return nil
+		},
+		e.exportOutput,
+		func(milestoneDiff *snapshot.MilestoneDiff) error {
+			if !includeSpent {
+				return nil
+			}
+
+			for _, spent := range milestoneDiff.Consumed {
+				if err := e.exportSpent(spent); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+		func(iotago.BlockID, iotago.MilestoneIndex) error { return nil },
+		func(*iotago.ProtocolParamsMilestoneOpt) error { return nil },
+	)
+}
+
+func ledgerExport(args []string) error {
+
+	fs := configuration.NewUnsortedFlagSet("", flag.ContinueOnError)
+	databasePathFlag := fs.String(FlagToolDatabasePath, "", "the path to the database to export the ledger from")
+	snapshotPathFlag := fs.String(FlagToolSnapshotPath, "", "the path to the full snapshot file to export the ledger from")
+	outputPathFlag := fs.String(FlagToolOutputPath, "", "the path to the export file (optional, the export is written to stdout if not set)")
+	formatFlag := fs.String(FlagToolLedgerExportFormat, ledgerExportFormatCSV, fmt.Sprintf("the format of the export (%s, %s)", ledgerExportFormatCSV, ledgerExportFormatJSONLines))
+	columnsFlag := fs.StringSlice(FlagToolLedgerExportColumns, ledgerExportColumns, "the columns of the export")
+	outputTypesFlag := fs.StringSlice(FlagToolLedgerExportOutputTypes, nil, "only export outputs of the given types (basic, alias, foundry, nft)")
+	addressesFlag := fs.StringSlice(FlagToolLedgerExportAddresses, nil, "only export outputs that can be unlocked by the given bech32 addresses")
+	spentFlag := fs.Bool(FlagToolLedgerExportSpent, false, "whether to export the spent outputs as well")
+	treasuryFlag := fs.Bool(FlagToolLedgerExportTreasury, false, "whether to export the treasury output as well")
+
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage of %s:\n", ToolLedgerExport)
+		fs.PrintDefaults()
+		println(fmt.Sprintf("\nexample: %s --%s %s --%s %s --%s %s --%s %s",
+			ToolLedgerExport,
+			FlagToolDatabasePath,
+			DefaultValueMainnetDatabasePath,
+			FlagToolOutputPath,
+			"ledger.csv",
+			FlagToolLedgerExportFormat,
+			ledgerExportFormatCSV,
+			FlagToolLedgerExportColumns,
+			"outputId,amount,address"))
+	}
+
+	if err := parseFlagSet(fs, args); err != nil {
+		return err
+	}
+
+	if (len(*databasePathFlag) == 0) == (len(*snapshotPathFlag) == 0) {
+		return fmt.Errorf("either '%s' or '%s' must be specified", FlagToolDatabasePath, FlagToolSnapshotPath)
+	}
+
+	for _, column := range *columnsFlag {
+		var known bool
+		for _, exportColumn := range ledgerExportColumns {
+			if column == exportColumn {
+				known = true
+
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown column: %s, allowed columns: %s", column, strings.Join(ledgerExportColumns, ", "))
+		}
+	}
+
+	filter, err := newLedgerExportFilter(*outputTypesFlag, *addressesFlag)
+	if err != nil {
+		return err
+	}
+
+	output := os.Stdout
+	if len(*outputPathFlag) > 0 {
+		exportFile, err := os.OpenFile(*outputPathFlag, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+		if err != nil {
+			return fmt.Errorf("unable to create export file: %w", err)
+		}
+		defer func() { _ = exportFile.Close() }()
+
+		output = exportFile
+	}
+
+	writer, err := newLedgerExportWriter(output, *formatFlag, *columnsFlag)
+	if err != nil {
+		return err
+	}
+
+	exporter := &ledgerExporter{
+		writer: writer,
+		filter: filter,
+	}
+
+	ts := time.Now()
+
+	if len(*databasePathFlag) > 0 {
+		err = exporter.exportFromDatabase(*databasePathFlag, *spentFlag, *treasuryFlag)
+	} else {
+		err = exporter.exportFromSnapshot(getGracefulStopContext(), *snapshotPathFlag, *spentFlag, *treasuryFlag)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("unable to write export: %w", err)
+	}
+
+	if len(*outputPathFlag) > 0 {
+		fmt.Printf("successfully exported %d outputs to %s, took %v\n", exporter.exportedCount, *outputPathFlag, time.Since(ts).Truncate(time.Millisecond))
+	}
+
+	return nil
+}
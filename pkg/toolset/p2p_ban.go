@@ -0,0 +1,121 @@
+package toolset
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/iotaledger/hive.go/app/configuration"
+	"github.com/iotaledger/hornet/v2/pkg/p2p"
+)
+
+func banP2PPeer(args []string) error {
+
+	fs := configuration.NewUnsortedFlagSet("", flag.ContinueOnError)
+	databasePathFlag := fs.String(FlagToolDatabasePath, DefaultValueP2PDatabasePath, "the path to the p2p database folder")
+	targetFlag := fs.String(FlagToolP2PBanTarget, "", "the peer ID, IP address, IP range (CIDR notation) or multiaddress to ban (lists all bans if not set)")
+	reasonFlag := fs.String(FlagToolP2PBanReason, "", "the reason why the target is banned (optional)")
+	durationFlag := fs.Duration(FlagToolP2PBanDuration, 0, "the duration after which the ban expires (optional, the ban is permanent if not set)")
+	removeFlag := fs.Bool(FlagToolP2PBanRemove, false, "lift the ban of the target instead of adding it")
+	outputJSONFlag := fs.Bool(FlagToolOutputJSON, false, FlagToolDescriptionOutputJSON)
+
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage of %s:\n", ToolP2PBan)
+		fs.PrintDefaults()
+		println(fmt.Sprintf("\nexample: %s --%s %s --%s %s --%s %s",
+			ToolP2PBan,
+			FlagToolDatabasePath,
+			DefaultValueP2PDatabasePath,
+			FlagToolP2PBanTarget,
+			"192.168.1.0/24",
+			FlagToolP2PBanDuration,
+			"24h"))
+	}
+
+	if err := parseFlagSet(fs, args); err != nil {
+		return err
+	}
+
+	if len(*databasePathFlag) == 0 {
+		return fmt.Errorf("'%s' not specified", FlagToolDatabasePath)
+	}
+	if *removeFlag && len(*targetFlag) == 0 {
+		return fmt.Errorf("'%s' not specified", FlagToolP2PBanTarget)
+	}
+
+	// running nodes pick up the changes of the ban list file
+	banList, err := p2p.NewBanList(*databasePathFlag)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(*targetFlag) == 0:
+		bans := banList.Bans()
+
+		if *outputJSONFlag {
+			return printJSON(bans)
+		}
+
+		if len(bans) == 0 {
+			fmt.Println("No active bans")
+
+			return nil
+		}
+
+		for _, ban := range bans {
+			printBan(ban)
+		}
+
+		return nil
+
+	case *removeFlag:
+		if err := banList.Remove(*targetFlag); err != nil {
+			return fmt.Errorf("lifting ban failed: %w", err)
+		}
+
+		if *outputJSONFlag {
+			result := struct {
+				Target string `json:"target"`
+			}{
+				Target: *targetFlag,
+			}
+
+			return printJSON(result)
+		}
+
+		fmt.Println("Lifted ban of:", *targetFlag)
+
+		return nil
+
+	default:
+		ban, err := banList.Add(*targetFlag, *reasonFlag, *durationFlag)
+		if err != nil {
+			return fmt.Errorf("adding ban failed: %w", err)
+		}
+
+		if *outputJSONFlag {
+			return printJSON(ban)
+		}
+
+		printBan(ban)
+
+		return nil
+	}
+}
+
+func printBan(ban *p2p.Ban) {
+	expiresAt := "never"
+	if ban.ExpiresAt != nil {
+		expiresAt = ban.ExpiresAt.Format(time.RFC3339)
+	}
+
+	fmt.Printf(`    >
+        - Target:         %s
+        - Reason:         %s
+        - Banned at:      %s
+        - Expires:        %s
+`, ban.Target, ban.Reason, ban.BannedAt.Format(time.RFC3339), expiresAt)
+}
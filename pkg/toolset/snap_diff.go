@@ -0,0 +1,672 @@
+package toolset
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/iotaledger/hive.go/app/configuration"
+	hivedb "github.com/iotaledger/hive.go/kvstore/database"
+	"github.com/iotaledger/hive.go/serializer/v2"
+	"github.com/iotaledger/hornet/v2/pkg/common"
+	"github.com/iotaledger/hornet/v2/pkg/model/storage"
+	"github.com/iotaledger/hornet/v2/pkg/model/utxo"
+	"github.com/iotaledger/hornet/v2/pkg/snapshot"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+const (
+	// the default amount of reported output differences per kind.
+	snapDiffDefaultLimit = 100
+)
+
+// snapDiffLedger is a ledger state that is compared by the snap-diff tool.
+// The outputs are streamed in lexical order of their output IDs,
+// the remaining data is only available after the stream was fully consumed.
+type snapDiffLedger struct {
+	name    string
+	outputs chan *utxo.Output
+	done    chan struct{}
+	err     error
+
+	ledgerIndex          iotago.MilestoneIndex
+	treasuryOutput       *utxo.TreasuryOutput
+	solidEntryPoints     map[iotago.BlockID]struct{}
+	protoParamsMsOptions map[iotago.MilestoneIndex]*iotago.ProtocolParamsMilestoneOpt
+}
+
+func newSnapDiffLedger(name string) *snapDiffLedger {
+	return &snapDiffLedger{
+		name:                 name,
+		outputs:              make(chan *utxo.Output, 1000),
+		done:                 make(chan struct{}),
+		solidEntryPoints:     make(map[iotago.BlockID]struct{}),
+		protoParamsMsOptions: make(map[iotago.MilestoneIndex]*iotago.ProtocolParamsMilestoneOpt),
+	}
+}
+
+// consumeOutput passes the output to the comparison.
+func (l *snapDiffLedger) consumeOutput(ctx context.Context, output *utxo.Output) error {
+	select {
+	case l.outputs <- output:
+		return nil
+	case <-ctx.Done():
+		return common.ErrOperationAborted
+	}
+}
+
+// run streams the ledger in the background.
+func (l *snapDiffLedger) run(producer func() error) {
+	go func() {
+		defer close(l.done)
+		defer close(l.outputs)
+
+		l.err = producer()
+	}()
+}
+
+// wait waits until the ledger was streamed completely and returns the error of the stream.
+func (l *snapDiffLedger) wait() error {
+	<-l.done
+
+	if l.err != nil {
+		return fmt.Errorf("reading %s failed: %w", l.name, l.err)
+	}
+
+	return nil
+}
+
+// snapDiffLedgerFromSnapshot streams the ledger of a full snapshot file.
+func snapDiffLedgerFromSnapshot(ctx context.Context, snapshotPath string) (*snapDiffLedger, error) {
+	snapshotFile, err := snapshot.OpenSnapshotFile(snapshotPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open snapshot file: %w", err)
+	}
+
+	ledger := newSnapDiffLedger(snapshotPath)
+	ledger.run(func() error {
+		defer func() { _ = snapshotFile.Close() }()
+
+		return snapshot.StreamFullSnapshotDataFrom(
+			ctx,
+			snapshotFile,
+			func(header *snapshot.FullSnapshotHeader) error {
+				ledger.ledgerIndex = header.LedgerMilestoneIndex
+
+				return nil
+			},
+			func(output *utxo.TreasuryOutput) error {
+				ledger.treasuryOutput = output
+
+				return nil
+			},
+			func(output *utxo.Output) error {
+				return ledger.consumeOutput(ctx, output)
+			},
+			func(*snapshot.MilestoneDiff) error { return nil },
+			func(blockID iotago.BlockID, _ iotago.MilestoneIndex) error {
+				ledger.solidEntryPoints[blockID] = struct{}{}
+
+				return nil
+			},
+			func(protoParamsMsOption *iotago.ProtocolParamsMilestoneOpt) error {
+				ledger.protoParamsMsOptions[protoParamsMsOption.TargetMilestoneIndex] = protoParamsMsOption
+
+				return nil
+			},
+		)
+	})
+
+	return ledger, nil
+}
+
+// snapDiffLedgerFromDatabase streams the ledger of a database.
+func snapDiffLedgerFromDatabase(ctx context.Context, dbStorage *storage.Storage, databasePath string) *snapDiffLedger {
+	ledger := newSnapDiffLedger(databasePath)
+	ledger.run(func() error {
+		utxoManager := dbStorage.UTXOManager()
+
+		// lock the ledger to compare a consistent state
+		utxoManager.ReadLockLedger()
+		defer utxoManager.ReadUnlockLedger()
+
+		ledgerIndex, err := utxoManager.ReadLedgerIndexWithoutLocking()
+		if err != nil {
+			return err
+		}
+		ledger.ledgerIndex = ledgerIndex
+
+		ledger.treasuryOutput, err = utxoManager.UnspentTreasuryOutputWithoutLocking()
+		if err != nil {
+			return fmt.Errorf("unable to get unspent treasury output: %w", err)
+		}
+
+		// the unspent outputs are stored with their output ID as key, so they are iterated in lexical order
+		var innerErr error
+		if err := utxoManager.ForEachUnspentOutput(func(output *utxo.Output) bool {
+			if err := ledger.consumeOutput(ctx, output); err != nil {
+				innerErr = err
+
+				return false
+			}
+
+			return true
+		}, utxo.ReadLockLedger(false)); err != nil {
+			return err
+		}
+		if innerErr != nil {
+			return innerErr
+		}
+
+		dbStorage.ForEachSolidEntryPointWithoutLocking(func(sep *storage.SolidEntryPoint) bool {
+			ledger.solidEntryPoints[sep.BlockID] = struct{}{}
+
+			return true
+		})
+
+		return dbStorage.ForEachProtocolParameterMilestoneOption(func(protoParamsMsOption *iotago.ProtocolParamsMilestoneOpt) bool {
+			ledger.protoParamsMsOptions[protoParamsMsOption.TargetMilestoneIndex] = protoParamsMsOption
+
+			return true
+		})
+	})
+
+	return ledger
+}
+
+// snapDiffOutputReader reads the outputs of a ledger and checks that they are in lexical order.
+type snapDiffOutputReader struct {
+	ledger  *snapDiffLedger
+	current *utxo.Output
+	count   int
+}
+
+// next reads the next output. The current output is nil after the last output was read.
+func (r *snapDiffOutputReader) next() error {
+	output, ok := <-r.ledger.outputs
+	if !ok {
+		r.current = nil
+
+		return nil
+	}
+
+	if r.current != nil {
+		previousOutputID, outputID := r.current.OutputID(), output.OutputID()
+		if bytes.Compare(previousOutputID[:], outputID[:]) >= 0 {
+			return fmt.Errorf("outputs of %s are not in lexical order (%s after %s)", r.ledger.name, outputID.ToHex(), previousOutputID.ToHex())
+		}
+	}
+
+	r.current = output
+	r.count++
+
+	return nil
+}
+
+type snapDiffTreasury struct {
+	MilestoneID string `json:"milestoneId"`
+	Tokens      uint64 `json:"tokens"`
+}
+
+type snapDiffTreasuryDifference struct {
+	Source *snapDiffTreasury `json:"source"`
+	Target *snapDiffTreasury `json:"target"`
+}
+
+type snapDiffOutputDifference struct {
+	OutputID string `json:"outputId"`
+	// the fields of the output that differ.
+	Fields []string `json:"fields"`
+}
+
+type snapDiffProtocolParametersDifference struct {
+	TargetMilestoneIndex iotago.MilestoneIndex `json:"targetMilestoneIndex"`
+	// whether the protocol parameters exist in the source and target ledger.
+	InSource bool `json:"inSource"`
+	InTarget bool `json:"inTarget"`
+	// the fields of the protocol parameters that differ.
+	Fields []string `json:"fields,omitempty"`
+}
+
+type snapDiffResult struct {
+	Source                    string                                  `json:"source"`
+	Target                    string                                  `json:"target"`
+	Equal                     bool                                    `json:"equal"`
+	SourceLedgerIndex         iotago.MilestoneIndex                   `json:"sourceLedgerIndex"`
+	TargetLedgerIndex         iotago.MilestoneIndex                   `json:"targetLedgerIndex"`
+	SourceOutputsCount        int                                     `json:"sourceOutputsCount"`
+	TargetOutputsCount        int                                     `json:"targetOutputsCount"`
+	MissingOutputsCount       int                                     `json:"missingOutputsCount"`
+	ExtraOutputsCount         int                                     `json:"extraOutputsCount"`
+	DifferingOutputsCount     int                                     `json:"differingOutputsCount"`
+	MissingOutputs            []string                                `json:"missingOutputs"`
+	ExtraOutputs              []string                                `json:"extraOutputs"`
+	DifferingOutputs          []*snapDiffOutputDifference             `json:"differingOutputs"`
+	Treasury                  *snapDiffTreasuryDifference             `json:"treasury,omitempty"`
+	MissingSolidEntryPoints   []string                                `json:"missingSolidEntryPoints"`
+	ExtraSolidEntryPoints     []string                                `json:"extraSolidEntryPoints"`
+	ProtocolParametersChanges []*snapDiffProtocolParametersDifference `json:"protocolParameters"`
+}
+
+// snapDiffOutputFields returns the fields that differ between the two versions of an output.
+func snapDiffOutputFields(source *utxo.Output, target *utxo.Output) []string {
+	var fields []string
+
+	if source.BlockID() != target.BlockID() {
+		fields = append(fields, "blockId")
+	}
+	if source.MilestoneIndexBooked() != target.MilestoneIndexBooked() {
+		fields = append(fields, "milestoneIndexBooked")
+	}
+	if source.MilestoneTimestampBooked() != target.MilestoneTimestampBooked() {
+		fields = append(fields, "milestoneTimestampBooked")
+	}
+	if !bytes.Equal(source.Bytes(), target.Bytes()) {
+		if source.Deposit() != target.Deposit() {
+			fields = append(fields, "amount")
+		}
+		fields = append(fields, "output")
+	}
+
+	return fields
+}
+
+// snapDiffProtocolParametersFields returns the fields that differ between the two protocol parameters milestone options.
+func snapDiffProtocolParametersFields(source *iotago.ProtocolParamsMilestoneOpt, target *iotago.ProtocolParamsMilestoneOpt) []string {
+	var fields []string
+
+	if source.ProtocolVersion != target.ProtocolVersion {
+		fields = append(fields, "protocolVersion")
+	}
+
+	if bytes.Equal(source.Params, target.Params) {
+		return fields
+	}
+
+	sourceParams := &iotago.ProtocolParameters{}
+	targetParams := &iotago.ProtocolParameters{}
+	if _, err := sourceParams.Deserialize(source.Params, serializer.DeSeriModeNoValidation, nil); err != nil {
+		return append(fields, "params")
+	}
+	if _, err := targetParams.Deserialize(target.Params, serializer.DeSeriModeNoValidation, nil); err != nil {
+		return append(fields, "params")
+	}
+
+	if sourceParams.Version != targetParams.Version {
+		fields = append(fields, "version")
+	}
+	if sourceParams.NetworkName != targetParams.NetworkName {
+		fields = append(fields, "networkName")
+	}
+	if sourceParams.Bech32HRP != targetParams.Bech32HRP {
+		fields = append(fields, "bech32Hrp")
+	}
+	if sourceParams.MinPoWScore != targetParams.MinPoWScore {
+		fields = append(fields, "minPowScore")
+	}
+	if sourceParams.BelowMaxDepth != targetParams.BelowMaxDepth {
+		fields = append(fields, "belowMaxDepth")
+	}
+	if sourceParams.RentStructure != targetParams.RentStructure {
+		fields = append(fields, "rentStructure")
+	}
+	if sourceParams.TokenSupply != targetParams.TokenSupply {
+		fields = append(fields, "tokenSupply")
+	}
+
+	return fields
+}
+
+func snapDiffTreasuryFromOutput(output *utxo.TreasuryOutput) *snapDiffTreasury {
+	if output == nil {
+		return nil
+	}
+
+	return &snapDiffTreasury{
+		MilestoneID: iotago.EncodeHex(output.MilestoneID[:]),
+		Tokens:      output.Amount,
+	}
+}
+
+// compareSnapDiffLedgers compares the source with the target ledger.
+// Output differences are reported up to the given limit per kind (0 = unlimited), but are always counted.
+func compareSnapDiffLedgers(cancel context.CancelFunc, source *snapDiffLedger, target *snapDiffLedger, limit int) (*snapDiffResult, error) {
+	result := &snapDiffResult{
+		Source:                    source.name,
+		Target:                    target.name,
+		MissingOutputs:            []string{},
+		ExtraOutputs:              []string{},
+		DifferingOutputs:          []*snapDiffOutputDifference{},
+		MissingSolidEntryPoints:   []string{},
+		ExtraSolidEntryPoints:     []string{},
+		ProtocolParametersChanges: []*snapDiffProtocolParametersDifference{},
+	}
+
+	withinLimit := func(count int) bool {
+		return limit == 0 || count < limit
+	}
+
+	sourceReader := &snapDiffOutputReader{ledger: source}
+	targetReader := &snapDiffOutputReader{ledger: target}
+
+	compareOutputs := func() error {
+		if err := sourceReader.next(); err != nil {
+			return err
+		}
+		if err := targetReader.next(); err != nil {
+			return err
+		}
+
+		for sourceReader.current != nil || targetReader.current != nil {
+			var cmp int
+			switch {
+			case sourceReader.current == nil:
+				cmp = 1
+			case targetReader.current == nil:
+				cmp = -1
+			default:
+				sourceOutputID, targetOutputID := sourceReader.current.OutputID(), targetReader.current.OutputID()
+				cmp = bytes.Compare(sourceOutputID[:], targetOutputID[:])
+			}
+
+			switch {
+			case cmp < 0:
+				// the output only exists in the source ledger
+				if withinLimit(result.MissingOutputsCount) {
+					result.MissingOutputs = append(result.MissingOutputs, sourceReader.current.OutputID().ToHex())
+				}
+				result.MissingOutputsCount++
+
+				if err := sourceReader.next(); err != nil {
+					return err
+				}
+
+			case cmp > 0:
+				// the output only exists in the target ledger
+				if withinLimit(result.ExtraOutputsCount) {
+					result.ExtraOutputs = append(result.ExtraOutputs, targetReader.current.OutputID().ToHex())
+				}
+				result.ExtraOutputsCount++
+
+				if err := targetReader.next(); err != nil {
+					return err
+				}
+
+			default:
+				if fields := snapDiffOutputFields(sourceReader.current, targetReader.current); len(fields) > 0 {
+					if withinLimit(result.DifferingOutputsCount) {
+						result.DifferingOutputs = append(result.DifferingOutputs, &snapDiffOutputDifference{
+							OutputID: sourceReader.current.OutputID().ToHex(),
+							Fields:   fields,
+						})
+					}
+					result.DifferingOutputsCount++
+				}
+
+				if err := sourceReader.next(); err != nil {
+					return err
+				}
+				if err := targetReader.next(); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	if err := compareOutputs(); err != nil {
+		// stop streaming the ledgers, the streams only fail because they were aborted
+		cancel()
+		_ = source.wait()
+		_ = target.wait()
+
+		return nil, err
+	}
+
+	// errors while streaming the ledgers result in incomplete output streams, so the comparison is not valid
+	if err := source.wait(); err != nil {
+		return nil, err
+	}
+	if err := target.wait(); err != nil {
+		return nil, err
+	}
+
+	result.SourceLedgerIndex = source.ledgerIndex
+	result.TargetLedgerIndex = target.ledgerIndex
+	result.SourceOutputsCount = sourceReader.count
+	result.TargetOutputsCount = targetReader.count
+
+	sourceTreasury := snapDiffTreasuryFromOutput(source.treasuryOutput)
+	targetTreasury := snapDiffTreasuryFromOutput(target.treasuryOutput)
+	if (sourceTreasury == nil) != (targetTreasury == nil) || (sourceTreasury != nil && *sourceTreasury != *targetTreasury) {
+		result.Treasury = &snapDiffTreasuryDifference{
+			Source: sourceTreasury,
+			Target: targetTreasury,
+		}
+	}
+
+	for blockID := range source.solidEntryPoints {
+		if _, exists := target.solidEntryPoints[blockID]; !exists {
+			result.MissingSolidEntryPoints = append(result.MissingSolidEntryPoints, blockID.ToHex())
+		}
+	}
+	for blockID := range target.solidEntryPoints {
+		if _, exists := source.solidEntryPoints[blockID]; !exists {
+			result.ExtraSolidEntryPoints = append(result.ExtraSolidEntryPoints, blockID.ToHex())
+		}
+	}
+	sort.Strings(result.MissingSolidEntryPoints)
+	sort.Strings(result.ExtraSolidEntryPoints)
+
+	targetIndexes := make(map[iotago.MilestoneIndex]struct{})
+	for targetIndex := range source.protoParamsMsOptions {
+		targetIndexes[targetIndex] = struct{}{}
+	}
+	for targetIndex := range target.protoParamsMsOptions {
+		targetIndexes[targetIndex] = struct{}{}
+	}
+
+	for targetIndex := range targetIndexes {
+		sourceOption, inSource := source.protoParamsMsOptions[targetIndex]
+		targetOption, inTarget := target.protoParamsMsOptions[targetIndex]
+
+		var fields []string
+		if inSource && inTarget {
+			if fields = snapDiffProtocolParametersFields(sourceOption, targetOption); len(fields) == 0 {
+				continue
+			}
+		}
+
+		result.ProtocolParametersChanges = append(result.ProtocolParametersChanges, &snapDiffProtocolParametersDifference{
+			TargetMilestoneIndex: targetIndex,
+			InSource:             inSource,
+			InTarget:             inTarget,
+			Fields:               fields,
+		})
+	}
+	sort.Slice(result.ProtocolParametersChanges, func(i int, j int) bool {
+		return result.ProtocolParametersChanges[i].TargetMilestoneIndex < result.ProtocolParametersChanges[j].TargetMilestoneIndex
+	})
+
+	result.Equal = result.SourceLedgerIndex == result.TargetLedgerIndex &&
+		result.MissingOutputsCount == 0 &&
+		result.ExtraOutputsCount == 0 &&
+		result.DifferingOutputsCount == 0 &&
+		result.Treasury == nil &&
+		len(result.MissingSolidEntryPoints) == 0 &&
+		len(result.ExtraSolidEntryPoints) == 0 &&
+		len(result.ProtocolParametersChanges) == 0
+
+	return result, nil
+}
+
+func printSnapDiffResult(result *snapDiffResult) {
+	fmt.Printf(`    >
+        - Source:            %s
+        - Target:            %s
+        - Ledger index:      %d / %d
+        - UTXOs count:       %d / %d
+        - Missing outputs:   %d
+        - Extra outputs:     %d
+        - Differing outputs: %d`+"\n\n",
+		result.Source,
+		result.Target,
+		result.SourceLedgerIndex, result.TargetLedgerIndex,
+		result.SourceOutputsCount, result.TargetOutputsCount,
+		result.MissingOutputsCount,
+		result.ExtraOutputsCount,
+		result.DifferingOutputsCount,
+	)
+
+	for _, outputID := range result.MissingOutputs {
+		fmt.Printf("missing output:   %s\n", outputID)
+	}
+	if len(result.MissingOutputs) < result.MissingOutputsCount {
+		fmt.Printf("... %d more missing outputs\n", result.MissingOutputsCount-len(result.MissingOutputs))
+	}
+
+	for _, outputID := range result.ExtraOutputs {
+		fmt.Printf("extra output:     %s\n", outputID)
+	}
+	if len(result.ExtraOutputs) < result.ExtraOutputsCount {
+		fmt.Printf("... %d more extra outputs\n", result.ExtraOutputsCount-len(result.ExtraOutputs))
+	}
+
+	for _, output := range result.DifferingOutputs {
+		fmt.Printf("differing output: %s %v\n", output.OutputID, output.Fields)
+	}
+	if len(result.DifferingOutputs) < result.DifferingOutputsCount {
+		fmt.Printf("... %d more differing outputs\n", result.DifferingOutputsCount-len(result.DifferingOutputs))
+	}
+
+	if result.Treasury != nil {
+		treasuryString := func(treasury *snapDiffTreasury) string {
+			if treasury == nil {
+				return "no treasury output found"
+			}
+
+			return fmt.Sprintf("milestone ID %s, tokens %d", treasury.MilestoneID, treasury.Tokens)
+		}
+		fmt.Printf("differing treasury: %s / %s\n", treasuryString(result.Treasury.Source), treasuryString(result.Treasury.Target))
+	}
+
+	for _, blockID := range result.MissingSolidEntryPoints {
+		fmt.Printf("missing solid entry point: %s\n", blockID)
+	}
+	for _, blockID := range result.ExtraSolidEntryPoints {
+		fmt.Printf("extra solid entry point:   %s\n", blockID)
+	}
+
+	for _, protoParams := range result.ProtocolParametersChanges {
+		switch {
+		case !protoParams.InTarget:
+			fmt.Printf("missing protocol parameters: target milestone index %d\n", protoParams.TargetMilestoneIndex)
+		case !protoParams.InSource:
+			fmt.Printf("extra protocol parameters:   target milestone index %d\n", protoParams.TargetMilestoneIndex)
+		default:
+			fmt.Printf("differing protocol parameters: target milestone index %d %v\n", protoParams.TargetMilestoneIndex, protoParams.Fields)
+		}
+	}
+}
+
+func snapshotDiff(args []string) error {
+
+	fs := configuration.NewUnsortedFlagSet("", flag.ContinueOnError)
+	snapshotPathFlag := fs.String(FlagToolSnapshotPath, "", "the path to the full snapshot file that is used as reference")
+	targetSnapshotPathFlag := fs.String(FlagToolSnapshotPathTarget, "", "the path to the full snapshot file that is compared with the reference")
+	databasePathFlag := fs.String(FlagToolDatabasePath, "", "the path to the database that is compared with the reference")
+	limitFlag := fs.Int(FlagToolSnapDiffLimit, snapDiffDefaultLimit, "the maximum amount of reported outputs per kind of difference (0 = unlimited)")
+	outputJSONFlag := fs.Bool(FlagToolOutputJSON, false, FlagToolDescriptionOutputJSON)
+
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage of %s:\n", ToolSnapDiff)
+		fs.PrintDefaults()
+		println(fmt.Sprintf("\nexample: %s --%s %s --%s %s",
+			ToolSnapDiff,
+			FlagToolSnapshotPath,
+			"snapshots/mainnet/full_snapshot.bin",
+			FlagToolDatabasePath,
+			DefaultValueMainnetDatabasePath))
+	}
+
+	if err := parseFlagSet(fs, args); err != nil {
+		return err
+	}
+
+	if len(*snapshotPathFlag) == 0 {
+		return fmt.Errorf("'%s' not specified", FlagToolSnapshotPath)
+	}
+
+	if (len(*targetSnapshotPathFlag) == 0) == (len(*databasePathFlag) == 0) {
+		return fmt.Errorf("either '%s' or '%s' must be specified", FlagToolSnapshotPathTarget, FlagToolDatabasePath)
+	}
+
+	if *limitFlag < 0 {
+		return fmt.Errorf("'%s' must not be negative", FlagToolSnapDiffLimit)
+	}
+
+	ctx, cancel := context.WithCancel(getGracefulStopContext())
+	defer cancel()
+
+	var target *snapDiffLedger
+	if len(*databasePathFlag) > 0 {
+		tangleStore, err := getTangleStorage(*databasePathFlag, "target", string(hivedb.EngineAuto), true, true, false, true)
+		if err != nil {
+			return err
+		}
+
+		defer func() {
+			if err := tangleStore.Shutdown(); err != nil {
+				panic(err)
+			}
+		}()
+
+		target = snapDiffLedgerFromDatabase(ctx, tangleStore, *databasePathFlag)
+	} else {
+		var err error
+		target, err = snapDiffLedgerFromSnapshot(ctx, *targetSnapshotPathFlag)
+		if err != nil {
+			return err
+		}
+	}
+
+	source, err := snapDiffLedgerFromSnapshot(ctx, *snapshotPathFlag)
+	if err != nil {
+		// stop streaming the target ledger
+		cancel()
+		_ = target.wait()
+
+		return err
+	}
+
+	ts := time.Now()
+
+	if !*outputJSONFlag {
+		fmt.Printf("comparing %s with %s ...\n", source.name, target.name)
+	}
+
+	result, err := compareSnapDiffLedgers(cancel, source, target, *limitFlag)
+	if err != nil {
+		return err
+	}
+
+	if *outputJSONFlag {
+		return printJSON(result)
+	}
+
+	printSnapDiffResult(result)
+
+	if result.Equal {
+		fmt.Printf("\nthe ledger states are equal, took %v\n", time.Since(ts).Truncate(time.Millisecond))
+	} else {
+		fmt.Printf("\nthe ledger states differ, took %v\n", time.Since(ts).Truncate(time.Millisecond))
+	}
+
+	return nil
+}
@@ -0,0 +1,378 @@
+//nolint:forcetypeassert,varnamelen,revive,exhaustruct // we don't care about these linters in test cases
+package toolset
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/serializer/v2"
+	"github.com/iotaledger/hornet/v2/pkg/model/utxo"
+	"github.com/iotaledger/hornet/v2/pkg/snapshot"
+	"github.com/iotaledger/hornet/v2/pkg/tpkg"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+// snapDiffTestSnapshot is the content of a generated full snapshot file.
+type snapDiffTestSnapshot struct {
+	ledgerIndex         iotago.MilestoneIndex
+	treasuryOutput      *utxo.TreasuryOutput
+	outputs             utxo.Outputs
+	solidEntryPoints    iotago.BlockIDs
+	protoParamsMsOption *iotago.ProtocolParamsMilestoneOpt
+}
+
+func randSnapDiffTestSnapshot(outputCount int, sepCount int) *snapDiffTestSnapshot {
+	// the ledger index is changed by the test cases, so keep a distance to the bounds
+	ledgerIndex := tpkg.RandMilestoneIndex()/2 + 1
+
+	outputs := make(utxo.Outputs, 0, outputCount)
+	for i := 0; i < outputCount; i++ {
+		outputs = append(outputs, tpkg.RandUTXOOutputWithType(iotago.OutputBasic))
+	}
+
+	// the outputs of a full snapshot are written in lexical order of their output IDs
+	sort.Slice(outputs, func(i int, j int) bool {
+		outputIDI, outputIDJ := outputs[i].OutputID(), outputs[j].OutputID()
+
+		return bytes.Compare(outputIDI[:], outputIDJ[:]) < 0
+	})
+
+	solidEntryPoints := make(iotago.BlockIDs, 0, sepCount)
+	for i := 0; i < sepCount; i++ {
+		solidEntryPoints = append(solidEntryPoints, tpkg.RandBlockID())
+	}
+
+	return &snapDiffTestSnapshot{
+		ledgerIndex:         ledgerIndex,
+		treasuryOutput:      tpkg.RandTreasuryOutput(),
+		outputs:             outputs,
+		solidEntryPoints:    solidEntryPoints,
+		protoParamsMsOption: tpkg.RandProtocolParamsMilestoneOpt(ledgerIndex),
+	}
+}
+
+func (s *snapDiffTestSnapshot) clone() *snapDiffTestSnapshot {
+	treasuryOutput := *s.treasuryOutput
+	protoParamsMsOption := *s.protoParamsMsOption
+
+	return &snapDiffTestSnapshot{
+		ledgerIndex:         s.ledgerIndex,
+		treasuryOutput:      &treasuryOutput,
+		outputs:             append(utxo.Outputs{}, s.outputs...),
+		solidEntryPoints:    append(iotago.BlockIDs{}, s.solidEntryPoints...),
+		protoParamsMsOption: &protoParamsMsOption,
+	}
+}
+
+// write writes the snapshot as a full snapshot file to the given path.
+func (s *snapDiffTestSnapshot) write(t *testing.T, filePath string) {
+	t.Helper()
+
+	header := &snapshot.FullSnapshotHeader{
+		Type:                       snapshot.Full,
+		Version:                    snapshot.SupportedFormatVersion,
+		GenesisMilestoneIndex:      0,
+		TargetMilestoneIndex:       s.ledgerIndex,
+		TargetMilestoneTimestamp:   tpkg.RandMilestoneTimestamp(),
+		TargetMilestoneID:          tpkg.RandMilestoneID(),
+		LedgerMilestoneIndex:       s.ledgerIndex,
+		TreasuryOutput:             s.treasuryOutput,
+		ProtocolParamsMilestoneOpt: s.protoParamsMsOption,
+	}
+
+	outputs := s.outputs
+	outputProducer := func() (*utxo.Output, error) {
+		if len(outputs) == 0 {
+			return nil, nil
+		}
+		output := outputs[0]
+		outputs = outputs[1:]
+
+		return output, nil
+	}
+
+	solidEntryPoints := s.solidEntryPoints
+	sepProducer := func() (iotago.BlockID, error) {
+		if len(solidEntryPoints) == 0 {
+			return iotago.EmptyBlockID(), snapshot.ErrNoMoreSEPToProduce
+		}
+		blockID := solidEntryPoints[0]
+		solidEntryPoints = solidEntryPoints[1:]
+
+		return blockID, nil
+	}
+
+	snapshotFile, err := os.Create(filePath)
+	require.NoError(t, err)
+
+	_, err = snapshot.StreamFullSnapshotDataTo(snapshotFile, header, outputProducer, func() (*snapshot.MilestoneDiff, error) { return nil, nil }, sepProducer)
+	require.NoError(t, err)
+	require.NoError(t, snapshotFile.Close())
+}
+
+// changeProtocolParameters returns a copy of the protocol parameters milestone option with the changed protocol parameters.
+func changeProtocolParameters(t *testing.T, protoParamsMsOption *iotago.ProtocolParamsMilestoneOpt, change func(protoParams *iotago.ProtocolParameters)) *iotago.ProtocolParamsMilestoneOpt {
+	t.Helper()
+
+	protoParams := &iotago.ProtocolParameters{}
+	_, err := protoParams.Deserialize(protoParamsMsOption.Params, serializer.DeSeriModeNoValidation, nil)
+	require.NoError(t, err)
+
+	change(protoParams)
+
+	protoParamsBytes, err := protoParams.Serialize(serializer.DeSeriModeNoValidation, nil)
+	require.NoError(t, err)
+
+	return &iotago.ProtocolParamsMilestoneOpt{
+		TargetMilestoneIndex: protoParamsMsOption.TargetMilestoneIndex,
+		ProtocolVersion:      protoParamsMsOption.ProtocolVersion,
+		Params:               protoParamsBytes,
+	}
+}
+
+func outputIDsHex(outputs ...*utxo.Output) []string {
+	outputIDs := make([]string, 0, len(outputs))
+	for _, output := range outputs {
+		outputIDs = append(outputIDs, output.OutputID().ToHex())
+	}
+
+	return outputIDs
+}
+
+func TestCompareSnapDiffLedgers(t *testing.T) {
+
+	type test struct {
+		name string
+		// modify changes the generated source and target snapshots, which are equal initially.
+		modify func(t *testing.T, source *snapDiffTestSnapshot, target *snapDiffTestSnapshot)
+		limit  int
+		// check verifies the result, source and target are the snapshots after they were modified.
+		check func(t *testing.T, result *snapDiffResult, source *snapDiffTestSnapshot, target *snapDiffTestSnapshot)
+		// the ledger whose outputs are expected to be rejected.
+		invalidLedger string
+	}
+
+	testCases := []test{
+		{
+			name:   "equal ledgers",
+			modify: func(*testing.T, *snapDiffTestSnapshot, *snapDiffTestSnapshot) {},
+			check: func(t *testing.T, result *snapDiffResult, source *snapDiffTestSnapshot, target *snapDiffTestSnapshot) {
+				require.True(t, result.Equal)
+				require.Equal(t, source.ledgerIndex, result.SourceLedgerIndex)
+				require.Equal(t, target.ledgerIndex, result.TargetLedgerIndex)
+				require.Equal(t, len(source.outputs), result.SourceOutputsCount)
+				require.Equal(t, len(target.outputs), result.TargetOutputsCount)
+				require.Empty(t, result.MissingOutputs)
+				require.Empty(t, result.ExtraOutputs)
+				require.Empty(t, result.DifferingOutputs)
+				require.Nil(t, result.Treasury)
+				require.Empty(t, result.MissingSolidEntryPoints)
+				require.Empty(t, result.ExtraSolidEntryPoints)
+				require.Empty(t, result.ProtocolParametersChanges)
+			},
+		},
+		{
+			name: "different ledger index",
+			modify: func(_ *testing.T, _ *snapDiffTestSnapshot, target *snapDiffTestSnapshot) {
+				target.ledgerIndex++
+			},
+			check: func(t *testing.T, result *snapDiffResult, source *snapDiffTestSnapshot, target *snapDiffTestSnapshot) {
+				require.False(t, result.Equal)
+				require.Equal(t, source.ledgerIndex, result.SourceLedgerIndex)
+				require.Equal(t, target.ledgerIndex, result.TargetLedgerIndex)
+				require.Zero(t, result.MissingOutputsCount+result.ExtraOutputsCount+result.DifferingOutputsCount)
+			},
+		},
+		{
+			name: "missing outputs",
+			modify: func(_ *testing.T, _ *snapDiffTestSnapshot, target *snapDiffTestSnapshot) {
+				// remove the first, a middle and the last output to cover the bounds of the merge
+				outputs := target.outputs
+				target.outputs = append(append(utxo.Outputs{}, outputs[1:4]...), outputs[5:9]...)
+			},
+			check: func(t *testing.T, result *snapDiffResult, source *snapDiffTestSnapshot, target *snapDiffTestSnapshot) {
+				require.False(t, result.Equal)
+				require.Equal(t, 10, result.SourceOutputsCount)
+				require.Equal(t, 7, result.TargetOutputsCount)
+				require.Equal(t, 3, result.MissingOutputsCount)
+				require.Equal(t, outputIDsHex(source.outputs[0], source.outputs[4], source.outputs[9]), result.MissingOutputs)
+				require.Zero(t, result.ExtraOutputsCount)
+				require.Zero(t, result.DifferingOutputsCount)
+			},
+		},
+		{
+			name: "extra outputs",
+			modify: func(_ *testing.T, source *snapDiffTestSnapshot, _ *snapDiffTestSnapshot) {
+				source.outputs = append(utxo.Outputs{}, source.outputs[1:9]...)
+			},
+			check: func(t *testing.T, result *snapDiffResult, source *snapDiffTestSnapshot, target *snapDiffTestSnapshot) {
+				require.False(t, result.Equal)
+				require.Equal(t, 8, result.SourceOutputsCount)
+				require.Equal(t, 10, result.TargetOutputsCount)
+				require.Zero(t, result.MissingOutputsCount)
+				require.Equal(t, 2, result.ExtraOutputsCount)
+				require.Equal(t, outputIDsHex(target.outputs[0], target.outputs[9]), result.ExtraOutputs)
+				require.Zero(t, result.DifferingOutputsCount)
+			},
+		},
+		{
+			name: "changed outputs",
+			modify: func(_ *testing.T, _ *snapDiffTestSnapshot, target *snapDiffTestSnapshot) {
+				// the output was booked in a different block
+				output := target.outputs[2]
+				target.outputs[2] = utxo.CreateOutput(output.OutputID(), tpkg.RandBlockID(), output.MilestoneIndexBooked(), output.MilestoneTimestampBooked(), output.Output())
+
+				// the output holds a different amount
+				output = target.outputs[6]
+				target.outputs[6] = utxo.CreateOutput(output.OutputID(), output.BlockID(), output.MilestoneIndexBooked(), output.MilestoneTimestampBooked(),
+					tpkg.RandOutputOnAddressWithAmount(iotago.OutputBasic, tpkg.RandAddress(iotago.AddressEd25519), output.Deposit()+1))
+			},
+			check: func(t *testing.T, result *snapDiffResult, source *snapDiffTestSnapshot, _ *snapDiffTestSnapshot) {
+				require.False(t, result.Equal)
+				require.Zero(t, result.MissingOutputsCount)
+				require.Zero(t, result.ExtraOutputsCount)
+				require.Equal(t, 2, result.DifferingOutputsCount)
+				require.Equal(t, []*snapDiffOutputDifference{
+					{OutputID: source.outputs[2].OutputID().ToHex(), Fields: []string{"blockId"}},
+					{OutputID: source.outputs[6].OutputID().ToHex(), Fields: []string{"amount", "output"}},
+				}, result.DifferingOutputs)
+			},
+		},
+		{
+			name: "reported outputs are limited",
+			modify: func(_ *testing.T, _ *snapDiffTestSnapshot, target *snapDiffTestSnapshot) {
+				target.outputs = append(utxo.Outputs{}, target.outputs[3:]...)
+			},
+			limit: 2,
+			check: func(t *testing.T, result *snapDiffResult, source *snapDiffTestSnapshot, _ *snapDiffTestSnapshot) {
+				require.False(t, result.Equal)
+				require.Equal(t, 3, result.MissingOutputsCount)
+				require.Equal(t, outputIDsHex(source.outputs[0], source.outputs[1]), result.MissingOutputs)
+			},
+		},
+		{
+			name: "different treasury",
+			modify: func(_ *testing.T, _ *snapDiffTestSnapshot, target *snapDiffTestSnapshot) {
+				target.treasuryOutput.Amount++
+			},
+			check: func(t *testing.T, result *snapDiffResult, source *snapDiffTestSnapshot, target *snapDiffTestSnapshot) {
+				require.False(t, result.Equal)
+				require.Equal(t, &snapDiffTreasuryDifference{
+					Source: snapDiffTreasuryFromOutput(source.treasuryOutput),
+					Target: snapDiffTreasuryFromOutput(target.treasuryOutput),
+				}, result.Treasury)
+				require.Zero(t, result.MissingOutputsCount+result.ExtraOutputsCount+result.DifferingOutputsCount)
+			},
+		},
+		{
+			name: "different solid entry points",
+			modify: func(_ *testing.T, _ *snapDiffTestSnapshot, target *snapDiffTestSnapshot) {
+				target.solidEntryPoints = append(iotago.BlockIDs{tpkg.RandBlockID()}, target.solidEntryPoints[1:]...)
+			},
+			check: func(t *testing.T, result *snapDiffResult, source *snapDiffTestSnapshot, target *snapDiffTestSnapshot) {
+				require.False(t, result.Equal)
+				require.Equal(t, []string{source.solidEntryPoints[0].ToHex()}, result.MissingSolidEntryPoints)
+				require.Equal(t, []string{target.solidEntryPoints[0].ToHex()}, result.ExtraSolidEntryPoints)
+				require.Nil(t, result.Treasury)
+				require.Empty(t, result.ProtocolParametersChanges)
+			},
+		},
+		{
+			name: "changed protocol parameters",
+			modify: func(t *testing.T, _ *snapDiffTestSnapshot, target *snapDiffTestSnapshot) {
+				target.protoParamsMsOption = changeProtocolParameters(t, target.protoParamsMsOption, func(protoParams *iotago.ProtocolParameters) {
+					protoParams.NetworkName = "changed-network"
+					protoParams.MinPoWScore++
+				})
+			},
+			check: func(t *testing.T, result *snapDiffResult, source *snapDiffTestSnapshot, _ *snapDiffTestSnapshot) {
+				require.False(t, result.Equal)
+				require.Equal(t, []*snapDiffProtocolParametersDifference{
+					{
+						TargetMilestoneIndex: source.protoParamsMsOption.TargetMilestoneIndex,
+						InSource:             true,
+						InTarget:             true,
+						Fields:               []string{"networkName", "minPowScore"},
+					},
+				}, result.ProtocolParametersChanges)
+			},
+		},
+		{
+			name: "missing and extra protocol parameters",
+			modify: func(_ *testing.T, _ *snapDiffTestSnapshot, target *snapDiffTestSnapshot) {
+				target.protoParamsMsOption.TargetMilestoneIndex--
+			},
+			check: func(t *testing.T, result *snapDiffResult, source *snapDiffTestSnapshot, target *snapDiffTestSnapshot) {
+				require.False(t, result.Equal)
+				require.Equal(t, []*snapDiffProtocolParametersDifference{
+					{TargetMilestoneIndex: target.protoParamsMsOption.TargetMilestoneIndex, InSource: false, InTarget: true},
+					{TargetMilestoneIndex: source.protoParamsMsOption.TargetMilestoneIndex, InSource: true, InTarget: false},
+				}, result.ProtocolParametersChanges)
+			},
+		},
+		{
+			name: "source outputs not in lexical order",
+			modify: func(_ *testing.T, source *snapDiffTestSnapshot, _ *snapDiffTestSnapshot) {
+				source.outputs[4], source.outputs[5] = source.outputs[5], source.outputs[4]
+			},
+			invalidLedger: "source",
+		},
+		{
+			name: "target outputs not in lexical order",
+			modify: func(_ *testing.T, _ *snapDiffTestSnapshot, target *snapDiffTestSnapshot) {
+				target.outputs[8], target.outputs[9] = target.outputs[9], target.outputs[8]
+			},
+			invalidLedger: "target",
+		},
+		{
+			name: "duplicated target outputs",
+			modify: func(_ *testing.T, _ *snapDiffTestSnapshot, target *snapDiffTestSnapshot) {
+				target.outputs[1] = target.outputs[0]
+			},
+			invalidLedger: "target",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			source := randSnapDiffTestSnapshot(10, 5)
+			target := source.clone()
+			tt.modify(t, source, target)
+
+			testDir := t.TempDir()
+			snapshotPaths := map[string]string{
+				"source": filepath.Join(testDir, "source.bin"),
+				"target": filepath.Join(testDir, "target.bin"),
+			}
+			source.write(t, snapshotPaths["source"])
+			target.write(t, snapshotPaths["target"])
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			targetLedger, err := snapDiffLedgerFromSnapshot(ctx, snapshotPaths["target"])
+			require.NoError(t, err)
+			sourceLedger, err := snapDiffLedgerFromSnapshot(ctx, snapshotPaths["source"])
+			require.NoError(t, err)
+
+			result, err := compareSnapDiffLedgers(cancel, sourceLedger, targetLedger, tt.limit)
+			if tt.invalidLedger != "" {
+				require.ErrorContains(t, err, fmt.Sprintf("outputs of %s are not in lexical order", snapshotPaths[tt.invalidLedger]))
+
+				return
+			}
+			require.NoError(t, err)
+
+			require.Equal(t, snapshotPaths["source"], result.Source)
+			require.Equal(t, snapshotPaths["target"], result.Target)
+			tt.check(t, result, source, target)
+		})
+	}
+}
@@ -46,7 +46,9 @@ func snapshotInfo(args []string) error {
 			return err
 		}
 
-		return printFullSnapshotHeaderInfo("", filePath, fullHeader)
+		if err := printFullSnapshotHeaderInfo("", filePath, fullHeader); err != nil {
+			return err
+		}
 
 	case snapshot.Delta:
 		deltaHeader, err := snapshot.ReadDeltaSnapshotHeaderFromFile(filePath)
@@ -54,9 +56,50 @@ func snapshotInfo(args []string) error {
 			return err
 		}
 
-		return printDeltaSnapshotHeaderInfo("", filePath, deltaHeader)
+		if err := printDeltaSnapshotHeaderInfo("", filePath, deltaHeader); err != nil {
+			return err
+		}
 
 	default:
 		return fmt.Errorf("unknown snapshot type: %d", snapshotType)
 	}
+
+	return printSnapshotFileInfo(filePath)
+}
+
+// prints whether the given snapshot file is compressed and the result of its integrity check.
+func printSnapshotFileInfo(filePath string) error {
+
+	compressed, err := snapshot.IsCompressedSnapshotFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	verified, verifyErr := snapshot.VerifySnapshotFileIntegrity(filePath)
+
+	integrity := "verified"
+	switch {
+	case verifyErr != nil:
+		integrity = "failed"
+	case !verified:
+		integrity = "unavailable"
+	}
+
+	result := struct {
+		Compressed bool   `json:"compressed"`
+		Integrity  string `json:"integrity"`
+		Error      string `json:"error,omitempty"`
+	}{
+		Compressed: compressed,
+		Integrity:  integrity,
+	}
+	if verifyErr != nil {
+		result.Error = verifyErr.Error()
+	}
+
+	if err := printJSON(result); err != nil {
+		return err
+	}
+
+	return verifyErr
 }
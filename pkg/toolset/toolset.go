@@ -50,8 +50,19 @@ const (
 	FlagToolPassword  = "password"
 	FlagToolSalt      = "salt"
 
+	FlagToolJWTScope   = "scope"
+	FlagToolJWTTTL     = "ttl"
+	FlagToolJWTToken   = "token"
+	FlagToolJWTTokenID = "tokenID"
+	FlagToolJWTReason  = "reason"
+
 	FlagToolNodeURL = "nodeURL"
 
+	FlagToolP2PBanTarget   = "target"
+	FlagToolP2PBanReason   = "reason"
+	FlagToolP2PBanDuration = "duration"
+	FlagToolP2PBanRemove   = "remove"
+
 	FlagToolOutputJSON            = "json"
 	FlagToolDescriptionOutputJSON = "format output as JSON"
 
@@ -63,28 +74,50 @@ const (
 	FlagToolSnapGenMintAddress        = "mintAddress"
 	FlagToolSnapGenTreasuryAllocation = "treasuryAllocation"
 
+	FlagToolSnapDiffLimit = "limit"
+
 	FlagToolDatabaseTargetIndex = "targetIndex"
+	FlagToolDatabaseMaxBackups  = "maxBackups"
+
+	FlagToolDatabasePruneDepth               = "depth"
+	FlagToolDatabasePruneTargetSize          = "targetDatabaseSize"
+	FlagToolDatabasePruneThresholdPercentage = "thresholdPercentage"
+	FlagToolDatabasePruneReceipts            = "pruneReceipts"
+	FlagToolDatabasePruneDryRun              = "dryRun"
+
+	FlagToolLedgerExportFormat      = "format"
+	FlagToolLedgerExportColumns     = "columns"
+	FlagToolLedgerExportOutputTypes = "outputTypes"
+	FlagToolLedgerExportAddresses   = "addresses"
+	FlagToolLedgerExportSpent       = "spent"
+	FlagToolLedgerExportTreasury    = "treasury"
 )
 
 const (
 	ToolPwdHash            = "pwd-hash"
 	ToolP2PIdentityGen     = "p2pidentity-gen"
 	ToolP2PExtractIdentity = "p2pidentity-extract"
+	ToolP2PBan             = "p2p-ban"
 	ToolEd25519Key         = "ed25519-key"
 	ToolEd25519Addr        = "ed25519-addr"
 	ToolJWTApi             = "jwt-api"
+	ToolJWTRevoke          = "jwt-revoke"
 	ToolSnapGen            = "snap-gen"
 	ToolSnapMerge          = "snap-merge"
 	ToolSnapInfo           = "snap-info"
 	ToolSnapHash           = "snap-hash"
+	ToolSnapDiff           = "snap-diff"
 	ToolBenchmarkIO        = "bench-io"
 	ToolBenchmarkCPU       = "bench-cpu"
+	ToolDatabaseBackup     = "db-backup"
 	ToolDatabaseLedgerHash = "db-hash"
 	ToolDatabaseHealth     = "db-health"
 	ToolDatabaseMerge      = "db-merge"
 	ToolDatabaseMigration  = "db-migration"
+	ToolDatabasePrune      = "db-prune"
 	ToolDatabaseSnapshot   = "db-snapshot"
 	ToolDatabaseVerify     = "db-verify"
+	ToolLedgerExport       = "ledger-export"
 	//nolint:gosec
 	ToolBootstrapPrivateTangle = "bootstrap-private-tangle"
 	ToolNodeInfo               = "node-info"
@@ -130,21 +163,27 @@ func HandleTools() {
 		ToolPwdHash:                hashPasswordAndSalt,
 		ToolP2PIdentityGen:         generateP2PIdentity,
 		ToolP2PExtractIdentity:     extractP2PIdentity,
+		ToolP2PBan:                 banP2PPeer,
 		ToolEd25519Key:             generateEd25519Key,
 		ToolEd25519Addr:            generateEd25519Address,
 		ToolJWTApi:                 generateJWTApiToken,
+		ToolJWTRevoke:              revokeJWTApiToken,
 		ToolSnapGen:                snapshotGen,
 		ToolSnapMerge:              snapshotMerge,
 		ToolSnapInfo:               snapshotInfo,
 		ToolSnapHash:               snapshotHash,
+		ToolSnapDiff:               snapshotDiff,
 		ToolBenchmarkIO:            benchmarkIO,
 		ToolBenchmarkCPU:           benchmarkCPU,
+		ToolDatabaseBackup:         databaseBackup,
 		ToolDatabaseLedgerHash:     databaseLedgerHash,
 		ToolDatabaseHealth:         databaseHealth,
 		ToolDatabaseMerge:          databaseMerge,
 		ToolDatabaseMigration:      databaseMigration,
+		ToolDatabasePrune:          databasePrune,
 		ToolDatabaseSnapshot:       databaseSnapshot,
 		ToolDatabaseVerify:         databaseVerify,
+		ToolLedgerExport:           ledgerExport,
 		ToolBootstrapPrivateTangle: networkBootstrap,
 		ToolNodeInfo:               nodeInfo,
 	}
@@ -173,21 +212,27 @@ func listTools() {
 	fmt.Printf("%-20s generates a scrypt hash from your password and salt\n", fmt.Sprintf("%s:", ToolPwdHash))
 	fmt.Printf("%-20s generates a p2p identity private key file\n", fmt.Sprintf("%s:", ToolP2PIdentityGen))
 	fmt.Printf("%-20s extracts the p2p identity from the private key file\n", fmt.Sprintf("%s:", ToolP2PExtractIdentity))
+	fmt.Printf("%-20s lists, adds or lifts bans of peer IDs, IP ranges and multiaddresses\n", fmt.Sprintf("%s:", ToolP2PBan))
 	fmt.Printf("%-20s generates an ed25519 key pair\n", fmt.Sprintf("%s:", ToolEd25519Key))
 	fmt.Printf("%-20s generates an ed25519 address from a public key\n", fmt.Sprintf("%s:", ToolEd25519Addr))
 	fmt.Printf("%-20s generates a JWT token for REST-API access\n", fmt.Sprintf("%s:", ToolJWTApi))
+	fmt.Printf("%-20s revokes a JWT token for REST-API access\n", fmt.Sprintf("%s:", ToolJWTRevoke))
 	fmt.Printf("%-20s generates an initial snapshot for a private network\n", fmt.Sprintf("%s:", ToolSnapGen))
 	fmt.Printf("%-20s merges a full and delta snapshot into an updated full snapshot\n", fmt.Sprintf("%s:", ToolSnapMerge))
 	fmt.Printf("%-20s outputs information about a snapshot file\n", fmt.Sprintf("%s:", ToolSnapInfo))
 	fmt.Printf("%-20s calculates the sha256 hash of the ledger state inside a snapshot file\n", fmt.Sprintf("%s:", ToolSnapHash))
+	fmt.Printf("%-20s compares the ledger state of a snapshot file with another snapshot file or a database\n", fmt.Sprintf("%s:", ToolSnapDiff))
 	fmt.Printf("%-20s benchmarks the IO throughput\n", fmt.Sprintf("%s:", ToolBenchmarkIO))
 	fmt.Printf("%-20s benchmarks the CPU performance\n", fmt.Sprintf("%s:", ToolBenchmarkCPU))
+	fmt.Printf("%-20s creates a backup of a database\n", fmt.Sprintf("%s:", ToolDatabaseBackup))
 	fmt.Printf("%-20s calculates the sha256 hash of the ledger state of a database\n", fmt.Sprintf("%s:", ToolDatabaseLedgerHash))
 	fmt.Printf("%-20s checks the health status of the database\n", fmt.Sprintf("%s:", ToolDatabaseHealth))
 	fmt.Printf("%-20s merges missing tangle data from a database to another one\n", fmt.Sprintf("%s:", ToolDatabaseMerge))
 	fmt.Printf("%-20s migrates the database to another engine\n", fmt.Sprintf("%s:", ToolDatabaseMigration))
+	fmt.Printf("%-20s prunes a database of a stopped node\n", fmt.Sprintf("%s:", ToolDatabasePrune))
 	fmt.Printf("%-20s creates a full snapshot from a database\n", fmt.Sprintf("%s:", ToolDatabaseSnapshot))
 	fmt.Printf("%-20s verifies a valid ledger state and the existence of all blocks\n", fmt.Sprintf("%s:", ToolDatabaseVerify))
+	fmt.Printf("%-20s exports the ledger state of a database or snapshot file to CSV or JSON Lines\n", fmt.Sprintf("%s:", ToolLedgerExport))
 	fmt.Printf("%-20s bootstraps a private tangle by creating a snapshot, database and coordinator state file\n", fmt.Sprintf("%s:", ToolBootstrapPrivateTangle))
 	fmt.Printf("%-20s queries the info endpoint of a node\n", fmt.Sprintf("%s:", ToolNodeInfo))
 }
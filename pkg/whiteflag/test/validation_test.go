@@ -0,0 +1,68 @@
+//nolint:forcetypeassert,varnamelen,revive,exhaustruct // we don't care about these linters in test cases
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hornet/v2/pkg/model/storage"
+	"github.com/iotaledger/hornet/v2/pkg/testsuite"
+	"github.com/iotaledger/hornet/v2/pkg/testsuite/utils"
+	"github.com/iotaledger/hornet/v2/pkg/whiteflag"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+func TestValidateTransaction(t *testing.T) {
+
+	seed1Wallet := utils.NewHDWallet("Seed1", seed1, 0)
+	seed2Wallet := utils.NewHDWallet("Seed2", seed2, 0)
+
+	genesisAddress := seed1Wallet.Address()
+
+	te := testsuite.SetupTestEnvironment(t, genesisAddress, 2, ProtocolVersion, BelowMaxDepth, MinPoWScore, ShowConfirmationGraphs)
+	defer te.CleanupTestEnvironment(!ShowConfirmationGraphs)
+
+	seed1Wallet.BookOutput(te.GenesisOutput)
+
+	msTimestamp := uint32(time.Now().Unix())
+
+	validateTransaction := func(block *testsuite.Block) []*whiteflag.TransactionConflict {
+		conflicts, err := whiteflag.ValidateTransaction(te.UTXOManager(), block.IotaBlock().Payload.(*iotago.Transaction), msTimestamp)
+		require.NoError(t, err)
+
+		return conflicts
+	}
+
+	// valid transfer from seed1 to seed2
+	blockA := te.NewBlockBuilder("A").
+		Parents(te.LastMilestoneParents()).
+		FromWallet(seed1Wallet).
+		Amount(1_000_000).
+		BuildTransactionToWallet(seed2Wallet)
+	require.Empty(t, validateTransaction(blockA))
+
+	// transfer with inputs that do not exist in the ledger
+	blockB := te.NewBlockBuilder("B").
+		Parents(te.LastMilestoneParents()).
+		FromWallet(seed1Wallet).
+		Amount(1_000_000).
+		FakeInputs().
+		BuildTransactionToWallet(seed2Wallet)
+
+	conflicts := validateTransaction(blockB)
+	require.NotEmpty(t, conflicts)
+	for _, conflict := range conflicts {
+		require.Equal(t, storage.Conflict(storage.ConflictInputUTXONotFound), conflict.Conflict)
+	}
+
+	// confirm block A, afterwards the inputs of block A are spent
+	blockA.Store().BookOnWallets()
+	_, confStats := te.IssueAndConfirmMilestoneOnTips(iotago.BlockIDs{blockA.StoredBlockID()}, true)
+	require.Equal(t, 1, confStats.BlocksIncludedWithTransactions)
+
+	conflicts = validateTransaction(blockA)
+	require.Len(t, conflicts, 1)
+	require.Equal(t, storage.Conflict(storage.ConflictInputUTXOAlreadySpent), conflicts[0].Conflict)
+}
@@ -0,0 +1,129 @@
+package whiteflag
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hornet/v2/pkg/model/storage"
+	"github.com/iotaledger/hornet/v2/pkg/model/utxo"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+// TransactionConflict describes a single reason why a transaction would be marked as conflicting.
+type TransactionConflict struct {
+	// Conflict is the conflict reason the white flag confirmation would assign to the transaction.
+	Conflict storage.Conflict
+	// Err is the underlying error.
+	Err error
+}
+
+// semanticValidation is a single semantic validation step of a transaction.
+type semanticValidation struct {
+	check iotago.TxSemanticValidationFunc
+	// unlocksIdents is true if the check unlocks the identities of the inputs.
+	unlocksIdents bool
+	// needsUnlockedIdents is true if the check depends on the identities unlocked by the input unlocks.
+	needsUnlockedIdents bool
+}
+
+// semanticValidations are the same semantic validation steps that are applied during white flag confirmation.
+// Do not change the order of these checks as some of them depend on mutations on the SemanticValidationContext.
+var semanticValidations = []semanticValidation{
+	{check: iotago.TxSemanticTimelock()},
+	{check: iotago.TxSemanticInputUnlocks(), unlocksIdents: true},
+	{check: iotago.TxSemanticOutputsSender(), needsUnlockedIdents: true},
+	{check: iotago.TxSemanticDeposit()},
+	{check: iotago.TxSemanticNativeTokens()},
+	{check: iotago.TxSemanticSTVFOnChains(), needsUnlockedIdents: true},
+}
+
+// ValidateTransaction validates the transaction against the current ledger state the same way
+// the white flag confirmation would do it, but it does not stop at the first conflict.
+// Instead all conflicts of the transaction are returned. msTimestamp is the timestamp
+// of the milestone that is assumed to confirm the transaction.
+// The ledger state must be read locked while this function is getting called in order to ensure consistency.
+func ValidateTransaction(utxoManager *utxo.Manager, transaction *iotago.Transaction, msTimestamp uint32) ([]*TransactionConflict, error) {
+
+	conflicts := make([]*TransactionConflict, 0)
+
+	// go through all the inputs and validate that they exist and are still unspent
+	inputOutputs := utxo.Outputs{}
+	for _, input := range transaction.Essence.Inputs {
+		utxoInput, ok := input.(*iotago.UTXOInput)
+		if !ok {
+			return nil, errors.Errorf("unsupported input type: %T", input)
+		}
+		inputID := utxoInput.ID()
+
+		output, err := utxoManager.ReadOutputByOutputIDWithoutLocking(inputID)
+		if err != nil {
+			if errors.Is(err, kvstore.ErrKeyNotFound) {
+				conflicts = append(conflicts, &TransactionConflict{
+					Conflict: storage.ConflictInputUTXONotFound,
+					Err:      errors.Errorf("input %s not found", inputID.ToHex()),
+				})
+
+				continue
+			}
+
+			return nil, err
+		}
+
+		unspent, err := utxoManager.IsOutputUnspentWithoutLocking(output)
+		if err != nil {
+			return nil, err
+		}
+
+		if !unspent {
+			conflicts = append(conflicts, &TransactionConflict{
+				Conflict: storage.ConflictInputUTXOAlreadySpent,
+				Err:      errors.Errorf("input %s already spent", inputID.ToHex()),
+			})
+
+			continue
+		}
+
+		inputOutputs = append(inputOutputs, output)
+	}
+
+	if len(conflicts) > 0 {
+		// the semantic validation needs all inputs to be available
+		return conflicts, nil
+	}
+
+	semValCtx := &iotago.SemanticValidationContext{
+		ExtParas: &iotago.ExternalUnlockParameters{
+			ConfUnix: msTimestamp,
+		},
+	}
+
+	var err error
+	semValCtx.WorkingSet, err = iotago.NewSemValiContextWorkingSet(transaction, inputOutputs.ToOutputSet())
+	if err != nil {
+		return append(conflicts, &TransactionConflict{
+			Conflict: storage.ConflictFromSemanticValidationError(err),
+			Err:      err,
+		}), nil
+	}
+
+	inputUnlocksFailed := false
+	for _, validation := range semanticValidations {
+		if validation.needsUnlockedIdents && inputUnlocksFailed {
+			// the result of this check would be misleading if not all identities were unlocked
+			continue
+		}
+
+		if err := validation.check(semValCtx); err != nil {
+			if validation.unlocksIdents {
+				inputUnlocksFailed = true
+			}
+
+			conflicts = append(conflicts, &TransactionConflict{
+				Conflict: storage.ConflictFromSemanticValidationError(err),
+				Err:      err,
+			})
+		}
+	}
+
+	return conflicts, nil
+}